@@ -0,0 +1,71 @@
+// Package clock 提供框架使用的时间来源。会话、缓存过期、ORM 时间戳和请求日志都通过 Clock 获取当前时间，
+// 测试中可以替换为 Mock，冻结或手动推进时间，不需要等待真实的时间流逝：
+//
+//	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+//	cache := orm.NewMemoryCache(orm.WithMemoryCacheClock(clk))
+//	cache.Set(ctx, "k", v, time.Minute)
+//	clk.Advance(2 * time.Minute) // 缓存已经过期
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间来源
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// System 返回使用系统时间的 Clock
+func System() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// OrSystem 在 c 为 nil 时返回系统时间，便于组件在未设置 Clock 时使用默认值
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
+
+// Mock 手动控制的时间，只有调用 Set 或 Advance 时才会变化，可以在多个协程中使用
+type Mock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewMock 创建从 now 开始的 Mock
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+// Now 返回当前的模拟时间
+func (m *Mock) Now() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.now
+}
+
+// Set 将模拟时间设置为 t
+func (m *Mock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+}
+
+// Advance 将模拟时间向后推进 d，返回推进后的时间
+func (m *Mock) Advance(d time.Duration) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	return m.now
+}
@@ -0,0 +1,43 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMock(start)
+	assert.Equal(t, start, m.Now())
+	assert.Equal(t, start, m.Now(), "time does not move on its own")
+
+	assert.Equal(t, start.Add(time.Minute), m.Advance(time.Minute))
+	assert.Equal(t, start.Add(time.Minute), m.Now())
+
+	later := start.Add(24 * time.Hour)
+	m.Set(later)
+	assert.Equal(t, later, m.Now())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Advance(time.Second)
+			_ = m.Now()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, later.Add(10*time.Second), m.Now())
+}
+
+func TestSystem(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), System().Now(), time.Second)
+	assert.WithinDuration(t, time.Now(), OrSystem(nil).Now(), time.Second)
+
+	m := NewMock(time.Time{})
+	assert.Equal(t, Clock(m), OrSystem(m))
+}
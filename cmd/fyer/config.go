@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/fyerfyer/fyer-webframe/scaffold"
+)
+
+// project 当前目录所在的项目
+type project struct {
+	*scaffold.ProjectConfig
+	root string // fyer.yaml 所在的目录，不在项目中时为空
+}
+
+// findProject 从当前目录开始向上查找 fyer.yaml，不在项目中时返回空配置，
+// 子命令把配置中的值作为参数的默认值，命令行参数优先
+func findProject() (*project, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	cfg, root, err := scaffold.FindProjectConfig(wd)
+	if errors.Is(err, scaffold.ErrConfigNotFound) {
+		return &project{ProjectConfig: &scaffold.ProjectConfig{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &project{ProjectConfig: cfg, root: root}, nil
+}
+
+// path 返回配置中的路径，相对路径相对于项目根目录
+func (p *project) path(path string) string {
+	if path == "" || p.root == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.root, path)
+}
+
+// orDefault 配置中的值为空时返回默认值
+func orDefault(val, def string) string {
+	if val == "" {
+		return def
+	}
+	return val
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/scaffold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chdir 切换工作目录，测试结束后恢复
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestFindProject(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	cfg := &scaffold.ProjectConfig{
+		Name:       "blog",
+		Module:     "example.com/blog",
+		Database:   scaffold.DatabaseConfig{Driver: "postgresql", DSN: "postgres://localhost/blog"},
+		Migrations: scaffold.MigrationsConfig{Dir: "db/migrations", Table: "schema_versions"},
+		Features:   []string{"recovery", "session"},
+	}
+	require.NoError(t, scaffold.WriteProjectConfig(root, cfg))
+
+	// 在子目录中同样能找到项目，写入的配置原样读出
+	sub := filepath.Join(root, "internal", "models")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	chdir(t, sub)
+	p, err := findProject()
+	require.NoError(t, err)
+	assert.Equal(t, root, p.root)
+	assert.Equal(t, cfg, p.ProjectConfig)
+
+	// 相对路径相对于项目根目录
+	assert.Equal(t, filepath.Join(root, "db/migrations"), p.path(p.Migrations.Dir))
+	assert.Equal(t, "/var/migrations", p.path("/var/migrations"))
+	assert.Empty(t, p.path(""))
+}
+
+func TestFindProject_OmitEmpty(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, scaffold.WriteProjectConfig(root, &scaffold.ProjectConfig{
+		Name:     "blog",
+		Module:   "github.com/blog",
+		Database: scaffold.DatabaseConfig{Driver: "mysql"},
+	}))
+
+	// 没有填写的可选项不写入文件
+	data, err := os.ReadFile(filepath.Join(root, scaffold.ConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "name: blog\nmodule: github.com/blog\ndatabase:\n    driver: mysql\n", string(data))
+
+	chdir(t, root)
+	p, err := findProject()
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", p.Database.Driver)
+	assert.Empty(t, p.Features)
+}
+
+func TestFindProject_NotFound(t *testing.T) {
+	// 不在项目中时返回空配置，路径保持不变
+	chdir(t, t.TempDir())
+	p, err := findProject()
+	require.NoError(t, err)
+	assert.Empty(t, p.root)
+	assert.Equal(t, &scaffold.ProjectConfig{}, p.ProjectConfig)
+	assert.Equal(t, "migrations", p.path("migrations"))
+}
+
+func TestFindProject_Invalid(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, scaffold.ConfigFileName), []byte("name: [blog"), 0o644))
+	chdir(t, root)
+	_, err := findProject()
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestOrDefault(t *testing.T) {
+	assert.Equal(t, "mysql", orDefault("", "mysql"))
+	assert.Equal(t, "sqlite", orDefault("sqlite", "mysql"))
+}
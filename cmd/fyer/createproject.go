@@ -17,6 +17,8 @@ type ProjectCreator struct {
 	projectName string
 	modulePath  string
 	outputPath  string
+	dbDriver    string
+	features    []string
 	templates   []scaffold.Template
 }
 
@@ -35,6 +37,7 @@ func NewProjectCreator(projectName string) (*ProjectCreator, error) {
 		projectName: projectName,
 		modulePath:  modulePath,
 		outputPath:  outputPath,
+		dbDriver:    "mysql",
 		templates:   registerTemplates(),
 	}, nil
 }
@@ -49,6 +52,26 @@ func (p *ProjectCreator) SetOutputPath(outputPath string) {
 	p.outputPath = outputPath
 }
 
+// SetDatabaseDriver 设置项目使用的数据库驱动
+func (p *ProjectCreator) SetDatabaseDriver(driver string) {
+	p.dbDriver = driver
+}
+
+// SetFeatures 设置项目启用的可选功能
+func (p *ProjectCreator) SetFeatures(features []string) {
+	p.features = features
+}
+
+// Config 返回描述项目的配置，即写入 fyer.yaml 的内容
+func (p *ProjectCreator) Config() *scaffold.ProjectConfig {
+	return &scaffold.ProjectConfig{
+		Name:     p.projectName,
+		Module:   p.modulePath,
+		Database: scaffold.DatabaseConfig{Driver: p.dbDriver},
+		Features: p.features,
+	}
+}
+
 // Create 执行项目创建流程
 func (p *ProjectCreator) Create() error {
 	fmt.Printf("Creating project '%s'...\n", p.projectName)
@@ -71,6 +94,8 @@ func (p *ProjectCreator) Create() error {
 	// 4. 准备模板数据
 	data := prepareTemplateData(p.projectName)
 	data.ModulePath = p.modulePath // 使用自定义模块路径
+	data.DBDriver = p.dbDriver
+	data.DBPort = scaffold.DefaultDatabasePort(p.dbDriver)
 
 	// 5. 生成项目文件
 	if err := p.generateFiles(data); err != nil {
@@ -79,12 +104,19 @@ func (p *ProjectCreator) Create() error {
 		return err
 	}
 
-	// 6. 初始化 Git 仓库
+	// 6. 写入项目配置，供之后的生成命令读取
+	if err := scaffold.WriteProjectConfig(p.outputPath, p.Config()); err != nil {
+		cleanUpOnFailure(p.outputPath)
+		return fmt.Errorf("failed to write %s: %w", scaffold.ConfigFileName, err)
+	}
+	fmt.Printf("  Created: %s\n", scaffold.ConfigFileName)
+
+	// 7. 初始化 Git 仓库
 	if err := initGitRepository(p.outputPath); err != nil {
 		fmt.Printf("Warning: Failed to initialize git repository: %v\n", err)
 	}
 
-	// 7. 初始化 Go 模块
+	// 8. 初始化 Go 模块
 	if err := initGoModule(p.outputPath, p.modulePath); err != nil {
 		return err
 	}
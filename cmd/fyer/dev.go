@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// devUsage 显示 dev 的帮助信息
+func devUsage() {
+	fmt.Printf("Run the application with ephemeral databases\n\n")
+	fmt.Println("Usage:")
+	fmt.Printf("  fyer dev [options] [-- app arguments]\n")
+	fmt.Println("\nInside a project the application is run from the directory containing fyer.yaml.")
+	fmt.Println("Defaults are read from fyer.dev.yaml in that directory (or the current one),")
+	fmt.Println("options given on the command line override the file.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fyer dev -mysql -redis")
+	fmt.Println("  fyer dev -app ./cmd/api -postgres -- -port 8080")
+	fmt.Println("  fyer dev -config ./dev/fyer.dev.yaml -env LOG_LEVEL=debug")
+	fmt.Println("  fyer dev -watch -proxy :3000")
+	fmt.Println("  fyer dev -watch -watch-ext css,js,html -ignore \"web/dist/\"")
+	fmt.Println("  fyer dev -watch -generate \"go generate ./...\"")
+	fmt.Println("  fyer dev -test -cover -mysql")
+}
+
+// devOptions dev 子命令的参数
+type devOptions struct {
+	app           string
+	dir           string
+	mysql         bool
+	postgres      bool
+	redis         bool
+	mysqlImage    string
+	postgresImage string
+	redisImage    string
+	wait          time.Duration
+	buildArgs     []string
+	env           []string
+	appArgs       []string
+	watch         bool
+	watchExts     []string
+	ignore        []string
+	debounce      time.Duration
+	generators    []generator
+	proxy         string
+	test          bool
+	cover         bool
+}
+
+// runDev 启动依赖的服务和应用
+func runDev(args []string) error {
+	p, err := findProject()
+	if err != nil {
+		return err
+	}
+
+	opts := devOptions{dir: p.root}
+	var configPath, buildArgs, watchExts string
+	var env envFlag
+	var ignore, generate listFlag
+	fs := newFlagSet("dev", devUsage)
+	fs.StringVar(&configPath, "config", "", "Configuration file (default: "+devConfigFileName+" in the project directory)")
+	fs.StringVar(&opts.app, "app", ".", "Go package of the application to run")
+	fs.BoolVar(&opts.mysql, "mysql", false, "Start an ephemeral MySQL container and export MYSQL_DSN")
+	fs.BoolVar(&opts.postgres, "postgres", false, "Start an ephemeral PostgreSQL container and export POSTGRES_DSN")
+	fs.BoolVar(&opts.redis, "redis", false, "Start an ephemeral Redis container and export REDIS_ADDR")
+	fs.StringVar(&opts.mysqlImage, "mysql-image", "mysql:8.0", "MySQL image")
+	fs.StringVar(&opts.postgresImage, "postgres-image", "postgres:16-alpine", "PostgreSQL image")
+	fs.StringVar(&opts.redisImage, "redis-image", "redis:7-alpine", "Redis image")
+	fs.DurationVar(&opts.wait, "wait", 90*time.Second, "Maximum time to wait for each container to become ready")
+	fs.StringVar(&buildArgs, "build-args", "", "Space separated arguments for go run, e.g. \"-race -tags dev\"")
+	fs.Var(&env, "env", "Environment variable KEY=VALUE for the application, may be repeated")
+	fs.BoolVar(&opts.watch, "watch", false, "Rebuild and restart the application when Go source files change")
+	fs.StringVar(&watchExts, "watch-ext", "", "Comma separated extensions to watch besides Go sources, e.g. \"css,js,sql,yaml\"")
+	fs.Var(&ignore, "ignore", "Glob of files or directories not to watch, e.g. \"*_gen.go\" or \"web/dist/\", may be repeated")
+	fs.DurationVar(&opts.debounce, "debounce", defaultDebounce, "Time to wait after the last change before rebuilding")
+	fs.Var(&generate, "generate", "Command to run before each rebuild, e.g. \"go generate ./...\", may be repeated")
+	fs.StringVar(&opts.proxy, "proxy", "", "Listen address of a proxy that holds requests while the application rebuilds, implies -watch")
+	fs.BoolVar(&opts.test, "test", false, "Run the tests of changed packages instead of the application")
+	fs.BoolVar(&opts.cover, "cover", false, "Report test coverage, used with -test")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadDevConfig(configPath, orDefault(p.root, "."))
+	if err != nil {
+		return err
+	}
+	opts.applyConfig(cfg, fs)
+	if isFlagSet(fs, "build-args") {
+		opts.buildArgs = strings.Fields(buildArgs)
+	}
+	opts.env = append(cfg.environ(), env...)
+	if isFlagSet(fs, "watch-ext") {
+		opts.watchExts = splitList(watchExts)
+	}
+	opts.ignore = append(opts.ignore, ignore...)
+	for _, run := range generate {
+		g := generator{Run: run}
+		if err := g.validate(); err != nil {
+			return err
+		}
+		opts.generators = append(opts.generators, g)
+	}
+	if err := checkGlobs(opts.ignore); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		opts.appArgs = fs.Args()
+	}
+	if opts.proxy != "" {
+		opts.watch = true
+	}
+
+	if code := dev(opts); code != 0 {
+		return exitCode(code)
+	}
+	return nil
+}
+
+// applyConfig 使用配置文件中的值，命令行中指定了的参数保持不变
+func (o *devOptions) applyConfig(cfg *devConfig, fs *flag.FlagSet) {
+	if cfg.App != "" && !isFlagSet(fs, "app") {
+		o.app = cfg.App
+	}
+	if !isFlagSet(fs, "mysql") {
+		o.mysql = cfg.hasService("mysql")
+	}
+	if !isFlagSet(fs, "postgres") {
+		o.postgres = cfg.hasService("postgres")
+	}
+	if !isFlagSet(fs, "redis") {
+		o.redis = cfg.hasService("redis")
+	}
+	if img := cfg.Images["mysql"]; img != "" && !isFlagSet(fs, "mysql-image") {
+		o.mysqlImage = img
+	}
+	if img := cfg.Images["postgres"]; img != "" && !isFlagSet(fs, "postgres-image") {
+		o.postgresImage = img
+	}
+	if img := cfg.Images["redis"]; img != "" && !isFlagSet(fs, "redis-image") {
+		o.redisImage = img
+	}
+	if cfg.Wait > 0 && !isFlagSet(fs, "wait") {
+		o.wait = cfg.Wait
+	}
+	if !isFlagSet(fs, "watch") {
+		o.watch = cfg.Watch
+	}
+	if cfg.Debounce > 0 && !isFlagSet(fs, "debounce") {
+		o.debounce = cfg.Debounce
+	}
+	if !isFlagSet(fs, "cover") {
+		o.cover = cfg.Cover
+	}
+	if cfg.Proxy != "" && !isFlagSet(fs, "proxy") {
+		o.proxy = cfg.Proxy
+	}
+	o.watchExts = cfg.WatchExt
+	o.ignore = cfg.Ignore
+	o.generators = cfg.Generate
+	o.buildArgs = cfg.BuildArgs
+	o.appArgs = cfg.Args
+}
+
+// isFlagSet 判断参数是否在命令行中指定
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// dev 启动依赖的服务和应用，应用退出或收到中断信号后删除容器，返回应用的退出码
+func dev(opts devOptions) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var services []service
+	if opts.mysql {
+		services = append(services, newMySQL(opts.mysqlImage))
+	}
+	if opts.postgres {
+		services = append(services, newPostgres(opts.postgresImage))
+	}
+	if opts.redis {
+		services = append(services, newRedis(opts.redisImage))
+	}
+
+	// 后出现的同名变量优先，服务的连接地址覆盖配置中的同名变量
+	env := append(os.Environ(), opts.env...)
+	if len(services) > 0 {
+		docker, err := newDockerClient()
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+
+		var running []*runningService
+		defer func() {
+			// 使用独立的上下文，保证收到中断信号后仍然能删除容器
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			for _, svc := range running {
+				if svc.id == "" {
+					continue
+				}
+				if err := docker.remove(cleanupCtx, svc.id); err != nil {
+					fmt.Printf("Warning: failed to remove %s container: %s\n", svc.name, err)
+				} else {
+					fmt.Printf("Removed %s container\n", svc.name)
+				}
+			}
+		}()
+
+		for _, svc := range services {
+			fmt.Printf("Starting %s (%s)...\n", svc.name, svc.image)
+			r, err := startService(ctx, docker, svc, opts.wait)
+			running = append(running, r)
+			if err != nil {
+				fmt.Printf("Error: %s\n", err)
+				return 1
+			}
+			fmt.Printf("  %s=%s\n", svc.envVar, r.dsn)
+			env = append(env, svc.envVar+"="+r.dsn)
+		}
+	}
+
+	if opts.test {
+		return watchTests(ctx, opts, env)
+	}
+	if opts.watch {
+		return watchApp(ctx, opts, env)
+	}
+	return runApp(ctx, opts, env)
+}
+
+// runApp 使用 go run 运行应用，收到中断信号时转发给应用并等待其退出
+func runApp(ctx context.Context, opts devOptions, env []string) int {
+	args := append([]string{"run"}, opts.buildArgs...)
+	args = append(args, opts.app)
+	args = append(args, opts.appArgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = opts.dir
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("Running go %v\n", args)
+	if err := cmd.Start(); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		if cmd.Process.Signal(os.Interrupt) != nil {
+			_ = cmd.Process.Kill()
+		}
+		err = <-done
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
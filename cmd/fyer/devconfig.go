@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// devConfigFileName dev 子命令的配置文件名，放在项目根目录中
+const devConfigFileName = "fyer.dev.yaml"
+
+// devConfig fyer.dev.yaml 的内容，每一项都可以被命令行参数覆盖：
+//
+//	app: ./cmd/api
+//	args: ["-port", "8080"]
+//	build_args: ["-tags", "dev", "-race"]
+//	env:
+//	  LOG_LEVEL: debug
+//	services: [mysql, redis]
+//	images:
+//	  mysql: mysql:8.4
+//	wait: 2m
+//	watch: true
+//	watch_ext: [css, js, sql, yaml]
+//	ignore: ["*_gen.go", "web/dist/"]
+//	debounce: 500ms
+//	generate:
+//	  - name: predicates
+//	    run: fyer gen predicates -i models/user.go
+//	    files: ["models/*.go"]
+//	proxy: :3000
+//	cover: true
+type devConfig struct {
+	App       string            `yaml:"app"`        // 应用所在的包
+	Args      []string          `yaml:"args"`       // 传给应用的参数
+	BuildArgs []string          `yaml:"build_args"` // 传给 go run、go build 或 go test 的构建参数
+	Env       map[string]string `yaml:"env"`        // 应用的环境变量
+	Services  []string          `yaml:"services"`   // 启动的服务：mysql、postgres、redis
+	Images    map[string]string `yaml:"images"`     // 服务使用的镜像
+	Wait      time.Duration     `yaml:"wait"`       // 等待每个服务就绪的最长时间
+	Watch     bool              `yaml:"watch"`      // 源文件变化时重新构建并重启应用
+	WatchExt  []string          `yaml:"watch_ext"`  // 除 Go 源文件之外监控的扩展名
+	Ignore    []string          `yaml:"ignore"`     // 不监控的文件和目录
+	Debounce  time.Duration     `yaml:"debounce"`   // 合并连续变化的等待时间
+	Generate  []generator       `yaml:"generate"`   // 每次重新构建之前运行的生成命令
+	Proxy     string            `yaml:"proxy"`      // 代理监听的地址，重新构建期间请求会等待应用就绪
+	Cover     bool              `yaml:"cover"`      // 测试模式下统计覆盖率
+}
+
+// devServices 支持的服务名称，与 -mysql 等参数对应
+var devServices = []string{"mysql", "postgres", "redis"}
+
+// loadDevConfig 读取配置文件，path 为空时查找 dir 中的 fyer.dev.yaml，不存在时返回空配置
+func loadDevConfig(path, dir string) (*devConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(dir, devConfigFileName)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return &devConfig{}, nil
+		}
+		return nil, err
+	}
+
+	cfg := &devConfig{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// 拼错的配置项直接报错，而不是悄悄被忽略
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate 检查服务名称和镜像的配置
+func (c *devConfig) validate() error {
+	for _, name := range c.Services {
+		if !slices.Contains(devServices, name) {
+			return fmt.Errorf("unknown service %q, supported: %v", name, devServices)
+		}
+	}
+	for name := range c.Images {
+		if !slices.Contains(devServices, name) {
+			return fmt.Errorf("unknown service %q in images, supported: %v", name, devServices)
+		}
+	}
+	if c.Wait < 0 {
+		return fmt.Errorf("wait must not be negative")
+	}
+	if c.Debounce < 0 {
+		return fmt.Errorf("debounce must not be negative")
+	}
+	for _, g := range c.Generate {
+		if err := g.validate(); err != nil {
+			return err
+		}
+	}
+	return checkGlobs(c.Ignore)
+}
+
+// hasService 判断配置中是否启用了服务
+func (c *devConfig) hasService(name string) bool {
+	return slices.Contains(c.Services, name)
+}
+
+// environ 返回配置中的环境变量，按名称排序以保证输出稳定
+func (c *devConfig) environ() []string {
+	env := make([]string, 0, len(c.Env))
+	for k, v := range c.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// envFlag 可以重复指定的 KEY=VALUE 参数
+type envFlag []string
+
+func (e *envFlag) String() string {
+	return strings.Join(*e, ",")
+}
+
+func (e *envFlag) Set(s string) error {
+	if k, _, ok := strings.Cut(s, "="); !ok || k == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", s)
+	}
+	*e = append(*e, s)
+	return nil
+}
+
+// listFlag 可以重复指定的参数
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
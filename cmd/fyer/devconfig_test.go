@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDevConfig 在临时目录中写入 fyer.dev.yaml，返回目录
+func writeDevConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, devConfigFileName), []byte(content), 0o644))
+	return dir
+}
+
+// parseDevFlags 按照 runDev 的默认值解析命令行参数
+func parseDevFlags(t *testing.T, args ...string) (*devOptions, *flag.FlagSet) {
+	t.Helper()
+	opts := &devOptions{}
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	fs.StringVar(&opts.app, "app", ".", "")
+	fs.BoolVar(&opts.mysql, "mysql", false, "")
+	fs.BoolVar(&opts.postgres, "postgres", false, "")
+	fs.BoolVar(&opts.redis, "redis", false, "")
+	fs.StringVar(&opts.mysqlImage, "mysql-image", "mysql:8.0", "")
+	fs.StringVar(&opts.postgresImage, "postgres-image", "postgres:16-alpine", "")
+	fs.StringVar(&opts.redisImage, "redis-image", "redis:7-alpine", "")
+	fs.DurationVar(&opts.wait, "wait", 90*time.Second, "")
+	fs.BoolVar(&opts.watch, "watch", false, "")
+	fs.DurationVar(&opts.debounce, "debounce", defaultDebounce, "")
+	fs.StringVar(&opts.proxy, "proxy", "", "")
+	fs.BoolVar(&opts.cover, "cover", false, "")
+	require.NoError(t, fs.Parse(args))
+	return opts, fs
+}
+
+const fullDevConfig = `
+app: ./cmd/api
+args: ["-port", "8080"]
+build_args: ["-tags", "dev"]
+env:
+  LOG_LEVEL: debug
+  APP_ENV: dev
+services: [mysql, redis]
+images:
+  mysql: mysql:8.4
+  redis: redis:6
+wait: 2m
+watch: true
+watch_ext: [css, sql]
+ignore: ["*_gen.go", "web/dist/"]
+debounce: 500ms
+generate:
+  - name: predicates
+    run: fyer gen predicates -i models/user.go
+    files: ["models/*.go"]
+proxy: :3000
+cover: true
+`
+
+func TestLoadDevConfig(t *testing.T) {
+	dir := writeDevConfig(t, fullDevConfig)
+
+	cfg, err := loadDevConfig("", dir)
+	require.NoError(t, err)
+	assert.Equal(t, &devConfig{
+		App:       "./cmd/api",
+		Args:      []string{"-port", "8080"},
+		BuildArgs: []string{"-tags", "dev"},
+		Env:       map[string]string{"LOG_LEVEL": "debug", "APP_ENV": "dev"},
+		Services:  []string{"mysql", "redis"},
+		Images:    map[string]string{"mysql": "mysql:8.4", "redis": "redis:6"},
+		Wait:      2 * time.Minute,
+		Watch:     true,
+		WatchExt:  []string{"css", "sql"},
+		Ignore:    []string{"*_gen.go", "web/dist/"},
+		Debounce:  500 * time.Millisecond,
+		Generate: []generator{
+			{Name: "predicates", Run: "fyer gen predicates -i models/user.go", Files: []string{"models/*.go"}},
+		},
+		Proxy: ":3000",
+		Cover: true,
+	}, cfg)
+	assert.True(t, cfg.hasService("mysql"))
+	assert.False(t, cfg.hasService("postgres"))
+	// 环境变量按名称排序
+	assert.Equal(t, []string{"APP_ENV=dev", "LOG_LEVEL=debug"}, cfg.environ())
+
+	// 显式指定的配置文件
+	cfg, err = loadDevConfig(filepath.Join(dir, devConfigFileName), t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "./cmd/api", cfg.App)
+}
+
+func TestLoadDevConfig_Missing(t *testing.T) {
+	// 默认位置没有配置文件时返回空配置
+	cfg, err := loadDevConfig("", t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, &devConfig{}, cfg)
+
+	// 空文件同样返回空配置
+	cfg, err = loadDevConfig("", writeDevConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, &devConfig{}, cfg)
+
+	// 显式指定的文件不存在时报错
+	_, err = loadDevConfig(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadDevConfig_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown field", content: "servics: [mysql]", wantErr: "field servics not found"},
+		{name: "bad duration", content: "wait: soon", wantErr: "failed to parse"},
+		{name: "unknown service", content: "services: [mongo]", wantErr: `unknown service "mongo"`},
+		{name: "unknown image", content: "images:\n  mongo: mongo:7", wantErr: `unknown service "mongo" in images`},
+		{name: "negative wait", content: "wait: -1s", wantErr: "wait must not be negative"},
+		{name: "negative debounce", content: "debounce: -1s", wantErr: "debounce must not be negative"},
+		{name: "empty generator", content: "generate:\n  - name: gen", wantErr: `generator "gen": run must not be empty`},
+		{name: "bad ignore", content: `ignore: ["[a-"]`, wantErr: `invalid pattern "[a-"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeDevConfig(t, tc.content)
+			_, err := loadDevConfig("", dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), filepath.Join(dir, devConfigFileName))
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestDevOptions_ApplyConfig(t *testing.T) {
+	cfg, err := loadDevConfig("", writeDevConfig(t, fullDevConfig))
+	require.NoError(t, err)
+
+	// 没有指定命令行参数时使用配置文件中的值
+	opts, fs := parseDevFlags(t)
+	opts.applyConfig(cfg, fs)
+	assert.Equal(t, "./cmd/api", opts.app)
+	assert.True(t, opts.mysql)
+	assert.False(t, opts.postgres)
+	assert.True(t, opts.redis)
+	assert.Equal(t, "mysql:8.4", opts.mysqlImage)
+	assert.Equal(t, "postgres:16-alpine", opts.postgresImage)
+	assert.Equal(t, "redis:6", opts.redisImage)
+	assert.Equal(t, 2*time.Minute, opts.wait)
+	assert.True(t, opts.watch)
+	assert.Equal(t, 500*time.Millisecond, opts.debounce)
+	assert.Equal(t, ":3000", opts.proxy)
+	assert.True(t, opts.cover)
+	assert.Equal(t, []string{"css", "sql"}, opts.watchExts)
+	assert.Equal(t, []string{"*_gen.go", "web/dist/"}, opts.ignore)
+	assert.Equal(t, []string{"-tags", "dev"}, opts.buildArgs)
+	assert.Equal(t, []string{"-port", "8080"}, opts.appArgs)
+	assert.Len(t, opts.generators, 1)
+
+	// 命令行中指定的参数优先，包括显式关闭的开关
+	opts, fs = parseDevFlags(t,
+		"-app", "./cmd/worker",
+		"-mysql=false",
+		"-postgres",
+		"-redis-image", "redis:7",
+		"-wait", "10s",
+		"-watch=false",
+		"-debounce", "1s",
+		"-proxy", ":4000",
+		"-cover=false",
+	)
+	opts.applyConfig(cfg, fs)
+	assert.Equal(t, "./cmd/worker", opts.app)
+	assert.False(t, opts.mysql)
+	assert.True(t, opts.postgres)
+	assert.True(t, opts.redis)
+	assert.Equal(t, "mysql:8.4", opts.mysqlImage)
+	assert.Equal(t, "redis:7", opts.redisImage)
+	assert.Equal(t, 10*time.Second, opts.wait)
+	assert.False(t, opts.watch)
+	assert.Equal(t, time.Second, opts.debounce)
+	assert.Equal(t, ":4000", opts.proxy)
+	assert.False(t, opts.cover)
+}
+
+func TestDevOptions_ApplyEmptyConfig(t *testing.T) {
+	// 空配置不改变命令行参数的默认值
+	opts, fs := parseDevFlags(t, "-redis")
+	opts.applyConfig(&devConfig{}, fs)
+	assert.Equal(t, ".", opts.app)
+	assert.False(t, opts.mysql)
+	assert.True(t, opts.redis)
+	assert.Equal(t, "mysql:8.0", opts.mysqlImage)
+	assert.Equal(t, 90*time.Second, opts.wait)
+	assert.Equal(t, defaultDebounce, opts.debounce)
+	assert.Empty(t, opts.proxy)
+}
+
+func TestEnvFlag(t *testing.T) {
+	var env envFlag
+	require.NoError(t, env.Set("A=1"))
+	require.NoError(t, env.Set("B="))
+	assert.EqualError(t, env.Set("C"), `expected KEY=VALUE, got "C"`)
+	assert.EqualError(t, env.Set("=1"), `expected KEY=VALUE, got "=1"`)
+	assert.Equal(t, "A=1,B=", env.String())
+}
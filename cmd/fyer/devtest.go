@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// testEvent go test -json 输出的事件，参见 go doc test2json
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// packageResult 一个包的测试结果
+type packageResult struct {
+	name     string
+	action   string // pass、fail 或 skip（没有测试文件）
+	elapsed  float64
+	passed   int
+	failed   int
+	skipped  int
+	coverage string
+	output   []string            // 不属于任何测试的输出，例如 panic 和构建错误
+	tests    map[string][]string // 每个测试的输出，只在测试失败时显示
+	failures []string            // 失败的测试，按失败的顺序
+}
+
+// coverageRe 匹配 go test -cover 输出的覆盖率
+var coverageRe = regexp.MustCompile(`coverage: ([0-9.]+% of statements)`)
+
+// testRunner 在文件变化时运行受影响的包的测试
+type testRunner struct {
+	opts  devOptions
+	env   []string
+	color bool
+}
+
+// watchTests 首先运行所有测试，之后每次文件变化时只运行变化的文件所在的包的测试，收到中断信号后返回
+func watchTests(ctx context.Context, opts devOptions, env []string) int {
+	t := &testRunner{
+		opts:  opts,
+		env:   env,
+		color: isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "",
+	}
+	events := newDevWatcher(opts).watch(ctx)
+	t.run(ctx, []string{"./..."})
+	for {
+		select {
+		case changed, ok := <-events:
+			if !ok {
+				return 0
+			}
+			pkgs := t.affected(changed)
+			if len(pkgs) == 0 {
+				continue
+			}
+			fmt.Printf("\nDetected changes in %s, testing %s\n", describeChanges(changed), strings.Join(pkgs, " "))
+			t.run(ctx, pkgs)
+		case <-ctx.Done():
+			return 0
+		}
+	}
+}
+
+// affected 返回变化的 Go 文件所在的包，go.mod、go.sum 或其他类型的文件变化时测试所有包
+func (t *testRunner) affected(changed []string) []string {
+	root := orDefault(t.opts.dir, ".")
+	var pkgs []string
+	for _, path := range changed {
+		if filepath.Ext(path) != ".go" {
+			return []string{"./..."}
+		}
+		dir := filepath.Dir(path)
+		// 删除整个包时不再测试这个包
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			continue
+		}
+		pkg := "./" + filepath.ToSlash(rel)
+		if rel == "." {
+			pkg = "."
+		}
+		if !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs
+}
+
+// run 运行测试并打印每个包的结果和汇总
+func (t *testRunner) run(ctx context.Context, pkgs []string) {
+	args := []string{"test", "-json"}
+	if t.opts.cover {
+		args = append(args, "-cover")
+	}
+	args = append(args, t.opts.buildArgs...)
+	args = append(args, pkgs...)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = t.opts.dir
+	cmd.Env = t.env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
+	}
+	results, buildOutput := parseTestEvents(stdout)
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	for _, r := range results {
+		t.printPackage(r)
+	}
+	// 编译错误在新版本的 go 中是 build-output 事件，旧版本中输出到 stderr
+	for _, line := range buildOutput {
+		fmt.Print(line)
+	}
+	if stderr.Len() > 0 {
+		fmt.Print(stderr.String())
+	}
+	t.printSummary(results, waitErr, time.Since(start))
+}
+
+// parseTestEvents 读取 go test -json 的输出，按包出现的顺序返回结果，以及编译错误的输出
+func parseTestEvents(r io.Reader) ([]*packageResult, []string) {
+	var results []*packageResult
+	var buildOutput []string
+	byName := make(map[string]*packageResult)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			// 不是 JSON 的输出原样保留，例如旧版本 go 的构建错误
+			fmt.Println(scanner.Text())
+			continue
+		}
+		if ev.Action == "build-output" {
+			buildOutput = append(buildOutput, ev.Output)
+			continue
+		}
+		if ev.Package == "" {
+			continue
+		}
+		pr, ok := byName[ev.Package]
+		if !ok {
+			pr = &packageResult{name: ev.Package, tests: make(map[string][]string)}
+			byName[ev.Package] = pr
+			results = append(results, pr)
+		}
+		pr.handle(ev)
+	}
+	return results, buildOutput
+}
+
+// handle 记录一个事件
+func (p *packageResult) handle(ev testEvent) {
+	if ev.Test == "" {
+		switch ev.Action {
+		case "output":
+			if m := coverageRe.FindStringSubmatch(ev.Output); m != nil {
+				p.coverage = m[1]
+			}
+			p.output = append(p.output, ev.Output)
+		case "pass", "fail", "skip":
+			p.action = ev.Action
+			p.elapsed = ev.Elapsed
+		}
+		return
+	}
+
+	switch ev.Action {
+	case "output":
+		p.tests[ev.Test] = append(p.tests[ev.Test], ev.Output)
+	case "pass":
+		p.passed++
+		delete(p.tests, ev.Test)
+	case "skip":
+		p.skipped++
+		delete(p.tests, ev.Test)
+	case "fail":
+		p.failed++
+		p.failures = append(p.failures, ev.Test)
+	}
+}
+
+// printPackage 打印包的结果，失败时打印失败的测试的输出
+func (t *testRunner) printPackage(p *packageResult) {
+	switch p.action {
+	case "skip":
+		fmt.Printf("%s %s [no test files]\n", t.paint(colorGray, "?   "), p.name)
+		return
+	case "pass":
+		line := fmt.Sprintf("%s %s %.2fs", t.paint(colorGreen, "ok  "), p.name, p.elapsed)
+		if p.coverage != "" {
+			line += "  coverage: " + p.coverage
+		}
+		fmt.Println(line)
+		return
+	}
+
+	fmt.Printf("%s %s\n", t.paint(colorRed, "FAIL"), p.name)
+	for _, name := range p.failures {
+		// 子测试失败时父测试也会失败，输出已经包含在子测试中
+		if slices.ContainsFunc(p.failures, func(sub string) bool { return strings.HasPrefix(sub, name+"/") }) {
+			continue
+		}
+		fmt.Printf("    %s %s\n", t.paint(colorRed, "---"), name)
+		for _, line := range p.tests[name] {
+			// 跳过 === RUN 和 --- FAIL 等状态行，只保留测试自己的输出
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+				continue
+			}
+			fmt.Print("        " + line)
+		}
+	}
+	if len(p.failures) == 0 {
+		// 没有失败的测试说明是 panic、构建错误或 TestMain 失败，打印包的全部输出
+		for _, line := range p.output {
+			fmt.Print("    " + line)
+		}
+	}
+}
+
+// printSummary 打印本次运行的汇总
+func (t *testRunner) printSummary(results []*packageResult, err error, elapsed time.Duration) {
+	var passed, failed, skipped int
+	for _, r := range results {
+		passed += r.passed
+		failed += r.failed
+		skipped += r.skipped
+	}
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped in %s", passed, failed, skipped, elapsed.Round(time.Millisecond))
+	if err != nil {
+		fmt.Printf("%s %s\n", t.paint(colorRed, "FAIL"), summary)
+		return
+	}
+	fmt.Printf("%s %s\n", t.paint(colorGreen, "PASS"), summary)
+}
+
+const (
+	colorRed   = "31"
+	colorGreen = "32"
+	colorGray  = "90"
+)
+
+// paint 在终端中为文字着色
+func (t *testRunner) paint(color, s string) string {
+	if !t.color {
+		return s
+	}
+	return "\033[" + color + "m" + s + "\033[0m"
+}
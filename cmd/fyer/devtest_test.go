@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestRunner_Affected(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":             "package main",
+		"models/user.go":      "package models",
+		"models/user_test.go": "package models",
+		"web/api/handler.go":  "package api",
+	})
+	path := func(rel string) string {
+		return filepath.Join(root, filepath.FromSlash(rel))
+	}
+	tr := &testRunner{opts: devOptions{dir: root}}
+
+	testCases := []struct {
+		name    string
+		changed []string
+		want    []string
+	}{
+		{name: "root package", changed: []string{path("main.go")}, want: []string{"."}},
+		{
+			name:    "deduplicated and sorted",
+			changed: []string{path("web/api/handler.go"), path("models/user_test.go"), path("models/user.go")},
+			want:    []string{"./models", "./web/api"},
+		},
+		// 删除的文件所在的包仍然存在时照常测试
+		{name: "removed file", changed: []string{path("models/order.go")}, want: []string{"./models"}},
+		// 整个包被删除时不再测试
+		{name: "removed package", changed: []string{path("legacy/old.go"), path("main.go")}, want: []string{"."}},
+		// 其他类型的文件变化时测试所有包
+		{name: "go.mod", changed: []string{path("models/user.go"), path("go.mod")}, want: []string{"./..."}},
+		{name: "template", changed: []string{path("web/index.html")}, want: []string{"./..."}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tr.affected(tc.changed))
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// defaultDockerHost Docker守护进程的默认地址
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+// dockerClient 通过 Docker Engine API 管理容器的最小客户端，只实现开发服务器用到的接口
+type dockerClient struct {
+	client *http.Client
+	base   string
+}
+
+// newDockerClient 根据 DOCKER_HOST 环境变量创建客户端，支持 unix:// 和 tcp:// 地址
+func newDockerClient() (*dockerClient, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCKER_HOST %q: %w", host, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		return &dockerClient{
+			client: &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var d net.Dialer
+						return d.DialContext(ctx, "unix", socket)
+					},
+				},
+			},
+			base: "http://docker",
+		}, nil
+	case "tcp":
+		return &dockerClient{
+			client: &http.Client{},
+			base:   "http://" + u.Host,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported DOCKER_HOST scheme %q", u.Scheme)
+	}
+}
+
+// dockerError Docker API返回的错误
+type dockerError struct {
+	StatusCode int
+	Message    string `json:"message"`
+}
+
+func (e *dockerError) Error() string {
+	return fmt.Sprintf("docker: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// do 发送请求，out不为空时将响应解析为JSON
+func (c *dockerClient) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		derr := &dockerError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(derr)
+		return derr
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	// 拉取镜像等接口以流的形式返回进度，读完才算完成
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+// containerSpec 创建容器的参数
+type containerSpec struct {
+	Name  string
+	Image string
+	Env   []string
+	Port  string // 容器端口，如 3306/tcp
+}
+
+// pull 拉取镜像
+func (c *dockerClient) pull(ctx context.Context, image string) error {
+	name, tag := image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i+1:]
+	}
+	query := url.Values{"fromImage": {name}, "tag": {tag}}
+	return c.do(ctx, http.MethodPost, "/images/create?"+query.Encode(), nil, nil)
+}
+
+// run 创建并启动容器，镜像不存在时先拉取，返回容器ID
+func (c *dockerClient) run(ctx context.Context, spec containerSpec) (string, error) {
+	body := map[string]any{
+		"Image":        spec.Image,
+		"Env":          spec.Env,
+		"ExposedPorts": map[string]any{spec.Port: struct{}{}},
+		"Labels":       map[string]string{"fyer-webframe.devserver": "true"},
+		"HostConfig": map[string]any{
+			// 只绑定本机地址，宿主机端口由Docker随机分配，避免与本地已有的数据库冲突
+			"PortBindings": map[string]any{
+				spec.Port: []map[string]string{{"HostIp": "127.0.0.1", "HostPort": ""}},
+			},
+		},
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	path := "/containers/create?" + url.Values{"name": {spec.Name}}.Encode()
+	err := c.do(ctx, http.MethodPost, path, body, &created)
+	if derr, ok := err.(*dockerError); ok && derr.StatusCode == http.StatusNotFound {
+		fmt.Printf("Pulling image %s...\n", spec.Image)
+		if err = c.pull(ctx, spec.Image); err != nil {
+			return "", err
+		}
+		err = c.do(ctx, http.MethodPost, path, body, &created)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = c.do(ctx, http.MethodPost, "/containers/"+created.ID+"/start", nil, nil); err != nil {
+		return created.ID, err
+	}
+	return created.ID, nil
+}
+
+// hostPort 返回容器端口映射到宿主机的端口
+func (c *dockerClient) hostPort(ctx context.Context, id, port string) (string, error) {
+	var info struct {
+		NetworkSettings struct {
+			Ports map[string][]struct {
+				HostPort string `json:"HostPort"`
+			} `json:"Ports"`
+		} `json:"NetworkSettings"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/json", nil, &info); err != nil {
+		return "", err
+	}
+
+	bindings := info.NetworkSettings.Ports[port]
+	if len(bindings) == 0 || bindings[0].HostPort == "" {
+		return "", fmt.Errorf("port %s of container %s is not published", port, id)
+	}
+	return bindings[0].HostPort, nil
+}
+
+// remove 强制删除容器及其匿名数据卷
+func (c *dockerClient) remove(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/containers/"+id+"?force=true&v=true", nil, nil)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/fyerfyer/fyer-webframe/codegen/modelgen"
+	"github.com/fyerfyer/fyer-webframe/codegen/predicate_gen"
+	"github.com/fyerfyer/fyer-webframe/orm"
+)
+
+// genUsage 显示 gen 的帮助信息
+func genUsage() {
+	fmt.Printf("Generate code\n\n")
+	fmt.Println("Usage:")
+	fmt.Printf("  fyer gen <generator> [options]\n\n")
+	fmt.Println("Generators:")
+	fmt.Println("  predicates  Predicates, repositories and metadata from model structs")
+	fmt.Println("  models      Model structs from an existing database")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fyer gen predicates -i ./model/user.go -o ./model -repo")
+	fmt.Println("  fyer gen models -o ./model -predicates")
+}
+
+// runGen 根据第一个参数选择生成器
+func runGen(args []string) error {
+	if len(args) == 0 {
+		genUsage()
+		return errUsage
+	}
+	switch args[0] {
+	case "predicates":
+		return runGenPredicates(args[1:])
+	case "models":
+		return runGenModels(args[1:])
+	case "-h", "-help", "--help", "help":
+		genUsage()
+		return nil
+	default:
+		fmt.Printf("Error: unknown generator %q\n\n", args[0])
+		genUsage()
+		return errUsage
+	}
+}
+
+// runGenPredicates 根据模型定义生成谓词、仓储和模型元数据
+func runGenPredicates(args []string) error {
+	fs := newFlagSet("gen predicates", func() {
+		fmt.Println("Usage: fyer gen predicates -i <input_file> -o <output_dir> [-repo] [-meta]")
+		fmt.Println("Example: fyer gen predicates -i ./test/user.go -o ./test")
+	})
+	input := fs.String("i", "", "input file path (e.g., ./test/user.go)")
+	output := fs.String("o", "", "output directory (default: directory of the input file)")
+	repo := fs.Bool("repo", false, "also generate repositories, methods are derived from the <Model>Repository interface if declared")
+	meta := fs.Bool("meta", false, "also generate model metadata, the ORM uses it instead of reflection")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *input == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	// 确保文件存在
+	if _, err := os.Stat(*input); os.IsNotExist(err) {
+		return fmt.Errorf("input file does not exist: %s", *input)
+	}
+
+	outputDir := *output
+	if outputDir == "" {
+		outputDir = filepath.Dir(*input)
+	}
+	outputDir = filepath.Clean(outputDir)
+
+	if err := predicate_gen.Generate(*input, outputDir); err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+	if *repo {
+		if err := predicate_gen.GenerateRepository(*input, outputDir); err != nil {
+			return fmt.Errorf("failed to generate repository: %w", err)
+		}
+	}
+	if *meta {
+		if err := predicate_gen.GenerateMeta(*input, outputDir); err != nil {
+			return fmt.Errorf("failed to generate model metadata: %w", err)
+		}
+	}
+
+	fmt.Printf("Code generation completed successfully!\nOutput directory: %s\n", outputDir)
+	return nil
+}
+
+// runGenModels 读取数据库的表结构生成模型，连接信息默认取自 fyer.yaml
+func runGenModels(args []string) error {
+	p, err := findProject()
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("gen models", func() {
+		fmt.Println("Usage: fyer gen models -o <output_dir> [-dsn dsn] [-driver mysql] [-tables t1,t2] [-predicates] [-meta]")
+		fmt.Println("Example: fyer gen models -dsn 'root:root@tcp(localhost:3306)/app?parseTime=true' -o ./model -predicates")
+	})
+	driver := fs.String("driver", "mysql", "database/sql driver name")
+	dsn := fs.String("dsn", orDefault(os.Getenv("DATABASE_DSN"), p.Database.DSN), "data source name, $DATABASE_DSN or database.dsn in fyer.yaml if omitted")
+	dialect := fs.String("dialect", p.Database.Driver, "SQL dialect: mysql, postgresql or sqlite, database.driver in fyer.yaml or the driver name if omitted")
+	schema := fs.String("schema", "", "database (MySQL) or schema (PostgreSQL) to read, default is the current one")
+	tables := fs.String("tables", "", "comma separated tables to generate, default is all tables")
+	exclude := fs.String("exclude", "orm_migration_log,schema_migrations,schema_migrations_lock", "comma separated tables to skip")
+	output := fs.String("o", "", "output directory (e.g., ./model)")
+	pkg := fs.String("pkg", "", "package name of the generated code, default is the output directory name")
+	predicates := fs.Bool("predicates", false, "also generate predicates and selectors for the models")
+	meta := fs.Bool("meta", false, "also generate model metadata, the ORM uses it instead of reflection")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *dsn == "" || *output == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	dialectName := orDefault(*dialect, *driver)
+
+	sqlDB, err := sql.Open(*driver, *dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer sqlDB.Close()
+
+	db, err := orm.Open(sqlDB, dialectName)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	outputDir := filepath.Clean(*output)
+	files, err := modelgen.Generate(context.Background(), db, modelgen.Config{
+		Dialect:    dialectName,
+		Schema:     *schema,
+		Tables:     splitList(*tables),
+		Exclude:    splitList(*exclude),
+		OutputDir:  outputDir,
+		Package:    *pkg,
+		Predicates: *predicates,
+		Meta:       *meta,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate models: %w", err)
+	}
+
+	for _, f := range files {
+		fmt.Println(f)
+	}
+	fmt.Printf("Code generation completed successfully!\nOutput directory: %s\n", outputDir)
+	return nil
+}
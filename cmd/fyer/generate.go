@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// generator 每次重新构建之前运行的代码生成命令，例如 go generate、fyer gen predicates 或 templ generate
+type generator struct {
+	Name  string   `yaml:"name"`  // 输出中显示的名称，默认为命令本身
+	Run   string   `yaml:"run"`   // 以空格分隔的命令和参数
+	Files []string `yaml:"files"` // 触发生成的文件，使用与 ignore 相同的匹配规则，为空时任何变化都会触发
+}
+
+// name 返回输出中显示的名称
+func (g generator) name() string {
+	return orDefault(g.Name, g.Run)
+}
+
+// validate 检查命令和文件模式
+func (g generator) validate() error {
+	if len(strings.Fields(g.Run)) == 0 {
+		return fmt.Errorf("generator %q: run must not be empty", g.Name)
+	}
+	return checkGlobs(g.Files)
+}
+
+// affected 判断变化的文件中是否有需要重新生成的文件，changed 为空表示首次构建，总是需要生成
+func (g generator) affected(root string, changed []string) bool {
+	if len(changed) == 0 || len(g.Files) == 0 {
+		return true
+	}
+	for _, path := range changed {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range g.Files {
+			if matchPath(pattern, filepath.ToSlash(rel), false) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runGenerators 依次运行受变化影响的生成命令，任何一个失败都会停止并返回包含命令输出的错误
+func (r *appRunner) runGenerators(ctx context.Context, changed []string) error {
+	root := orDefault(r.opts.dir, ".")
+	var before map[string]fileState
+	ran := false
+	for _, g := range r.opts.generators {
+		if !g.affected(root, changed) {
+			continue
+		}
+		if !ran && r.watcher != nil {
+			before = r.watcher.scan()
+		}
+		ran = true
+
+		args := strings.Fields(g.Run)
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = r.opts.dir
+		cmd.Env = r.env
+		fmt.Printf("Generating %s\n", g.name())
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("generator %s: %w\n%s", g.name(), err, out)
+		}
+		if len(out) > 0 {
+			_, _ = os.Stdout.Write(out)
+		}
+	}
+
+	// 记录生成命令写入的文件，watcher 随后报告这些变化时不再重复构建
+	if ran && r.watcher != nil {
+		after := r.watcher.scan()
+		for _, path := range diffStates(before, after) {
+			r.generated[path] = after[path]
+		}
+	}
+	return nil
+}
+
+// dropGenerated 去掉由生成命令写入、之后没有再被修改的文件，避免生成的文件触发新一轮生成和构建
+func (r *appRunner) dropGenerated(changed []string) []string {
+	if len(r.generated) == 0 {
+		return changed
+	}
+	var rest []string
+	for _, path := range changed {
+		st, ok := r.generated[path]
+		delete(r.generated, path)
+		if ok {
+			info, err := os.Stat(path)
+			if err == nil && info.Size() == st.size && info.ModTime().Equal(st.modTime) {
+				continue
+			}
+		}
+		rest = append(rest, path)
+	}
+	return rest
+}
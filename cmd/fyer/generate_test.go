@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Affected(t *testing.T) {
+	root := t.TempDir()
+	path := func(rel string) string {
+		return filepath.Join(root, filepath.FromSlash(rel))
+	}
+	g := generator{Run: "fyer gen predicates", Files: []string{"models/*.go", "**/*.sql"}}
+
+	// 首次构建总是运行
+	assert.True(t, g.affected(root, nil))
+	// 只有匹配 files 的变化才会触发
+	assert.True(t, g.affected(root, []string{path("main.go"), path("models/user.go")}))
+	assert.True(t, g.affected(root, []string{path("db/migrations/001.sql")}))
+	assert.False(t, g.affected(root, []string{path("main.go"), path("models/sub/user.go")}))
+
+	// 没有 files 时任何变化都会触发
+	assert.True(t, generator{Run: "go generate ./..."}.affected(root, []string{path("main.go")}))
+}
+
+func TestGenerator_Validate(t *testing.T) {
+	assert.NoError(t, generator{Run: "go generate ./..."}.validate())
+	assert.EqualError(t, generator{Name: "gen", Run: "  "}.validate(), `generator "gen": run must not be empty`)
+	assert.ErrorContains(t, generator{Run: "go generate", Files: []string{"[a-"}}.validate(), `invalid pattern "[a-"`)
+
+	assert.Equal(t, "go generate ./...", generator{Run: "go generate ./..."}.name())
+	assert.Equal(t, "predicates", generator{Name: "predicates", Run: "fyer gen predicates"}.name())
+}
+
+// newGenRunner 创建在 dir 中运行生成命令的 runner
+func newGenRunner(dir string, generators ...generator) *appRunner {
+	return &appRunner{
+		opts:      devOptions{dir: dir, generators: generators},
+		env:       os.Environ(),
+		watcher:   newWatcher(dir),
+		generated: make(map[string]fileState),
+	}
+}
+
+func TestAppRunner_RunGenerators(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"models/user.go": "package models"})
+	src, dst := filepath.Join(dir, "models", "user.go"), filepath.Join(dir, "models", "user_gen.go")
+	r := newGenRunner(dir,
+		generator{Name: "copy", Run: "cp models/user.go models/user_gen.go", Files: []string{"models/*.go"}},
+		generator{Name: "fail", Run: "false", Files: []string{"web/*.css"}},
+	)
+
+	// 只运行受变化影响的生成命令
+	require.NoError(t, r.runGenerators(context.Background(), []string{src}))
+	assert.FileExists(t, dst)
+	assert.Contains(t, r.generated, dst)
+
+	err := r.runGenerators(context.Background(), []string{filepath.Join(dir, "web", "app.css")})
+	assert.ErrorContains(t, err, "generator fail: exit status 1")
+
+	// 首次构建时运行所有生成命令
+	assert.ErrorContains(t, r.runGenerators(context.Background(), nil), "generator fail")
+}
+
+func TestAppRunner_DropGenerated(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"models/user.go": "package models"})
+	src, dst := filepath.Join(dir, "models", "user.go"), filepath.Join(dir, "models", "user_gen.go")
+	r := newGenRunner(dir, generator{Run: "cp models/user.go models/user_gen.go"})
+	require.NoError(t, r.runGenerators(context.Background(), nil))
+
+	// 生成的文件不会再次触发构建，其余的变化保留
+	assert.Equal(t, []string{src}, r.dropGenerated([]string{src, dst}))
+	assert.Empty(t, r.generated)
+
+	// 生成之后又被修改的文件仍然触发构建
+	require.NoError(t, r.runGenerators(context.Background(), nil))
+	require.NoError(t, os.WriteFile(dst, []byte("package models\n\n// edited"), 0o644))
+	require.NoError(t, os.Chtimes(dst, time.Now(), time.Now().Add(time.Second)))
+	assert.Equal(t, []string{dst}, r.dropGenerated([]string{dst}))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command fyer 的子命令
+type command struct {
+	name    string                    // 子命令名称
+	summary string                    // 一行说明
+	run     func(args []string) error // 执行子命令，args 不包含子命令名称
+}
+
+// exitCode 让子命令以指定的退出码结束，例如 dev 返回应用的退出码
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// errUsage 参数错误，子命令已经输出了帮助信息
+var errUsage = errors.New("invalid usage")
+
+var commands = []*command{
+	{name: "new", summary: "Create a new project", run: runNew},
+	{name: "dev", summary: "Run the application with ephemeral databases", run: runDev},
+	{name: "gen", summary: "Generate code (predicates, models)", run: runGen},
+	{name: "migrate", summary: "Apply, revert and create database migrations", run: runMigrate},
+}
+
+// usage 显示使用帮助信息
+func usage() {
+	fmt.Printf("Fyer Web Framework CLI\n\n")
+	fmt.Println("Usage:")
+	fmt.Printf("  fyer <command> [options] [arguments]\n\n")
+	fmt.Println("Commands:")
+	for _, c := range commands {
+		fmt.Printf("  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Println("\nRun 'fyer <command> -h' for the options of a command.")
+	fmt.Println("\nCommands run inside a project read defaults from the nearest fyer.yaml")
+	fmt.Println("in the current directory or its parents; flags override the file.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fyer new")
+	fmt.Println("  fyer dev -mysql")
+	fmt.Println("  fyer gen predicates -i ./model/user.go -o ./model")
+	fmt.Println("  fyer migrate up")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		return
+	}
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(os.Args[2:])
+		var code exitCode
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return
+		case errors.As(err, &code):
+			os.Exit(int(code))
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		default:
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Error: unknown command %q\n\n", name)
+	usage()
+	os.Exit(1)
+}
+
+// newFlagSet 创建子命令的参数集合，-h 时输出 usage 和参数说明
+func newFlagSet(name string, usage func()) *flag.FlagSet {
+	fs := flag.NewFlagSet("fyer "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		usage()
+		fmt.Println("\nOptions:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析子命令的参数，解析失败时 flag 包已经输出了错误和帮助信息
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
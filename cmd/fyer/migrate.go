@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm/migrate"
+)
+
+// migrateUsage 显示 migrate 的帮助信息
+func migrateUsage() {
+	fmt.Printf("Database migrations\n\n")
+	fmt.Println("Usage:")
+	fmt.Printf("  fyer migrate [options] <command> [arguments]\n\n")
+	fmt.Println("Commands:")
+	fmt.Println("  up [N]            Apply pending migrations, at most N if given")
+	fmt.Println("  down [N]          Revert the last N applied migrations (default 1)")
+	fmt.Println("  redo              Revert and re-apply the last applied migration")
+	fmt.Println("  status            Show applied and pending migrations")
+	fmt.Println("  unlock            Release a lock left behind by a crashed migration")
+	fmt.Println("  create NAME [-go] Create a new SQL (or Go) migration in -dir")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fyer migrate create add_user_status")
+	fmt.Println("  fyer migrate -dsn 'root:root@tcp(localhost:3306)/app?parseTime=true' up")
+	fmt.Println("  fyer migrate down 2")
+	fmt.Println("  fyer migrate status")
+}
+
+// runMigrate 执行迁移命令，连接信息和迁移目录默认取自 fyer.yaml
+func runMigrate(args []string) error {
+	p, err := findProject()
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("migrate", migrateUsage)
+	driver := fs.String("driver", "mysql", "database/sql driver name")
+	dsn := fs.String("dsn", orDefault(os.Getenv("DATABASE_DSN"), p.Database.DSN), "Data source name, $DATABASE_DSN or database.dsn in fyer.yaml if omitted")
+	dialect := fs.String("dialect", p.Database.Driver, "SQL dialect: mysql, postgresql or sqlite, database.driver in fyer.yaml or the driver name if omitted")
+	dir := fs.String("dir", p.path(orDefault(p.Migrations.Dir, "migrations")), "Directory containing migration files")
+	table := fs.String("table", orDefault(p.Migrations.Table, "schema_migrations"), "Table recording applied migrations")
+	lockTimeout := fs.Duration("lock-timeout", time.Minute, "Maximum time to wait for another migration to finish")
+	schemaLog := fs.Bool("schema-log", true, "Also record applied migrations in orm_migration_log")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fmt.Println("Error: command is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if args[0] == "create" {
+		return migrate.RunCreate(*dir, args[1:], os.Stdout)
+	}
+
+	if *dsn == "" {
+		fmt.Println("Error: -dsn is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	db, err := sql.Open(*driver, *dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, orDefault(*dialect, *driver),
+		migrate.WithDir(*dir),
+		migrate.WithTable(*table),
+		migrate.WithLockTimeout(*lockTimeout),
+		migrate.WithSchemaLog(*schemaLog),
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return migrate.Run(ctx, m, args, os.Stdout)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/scaffold"
+)
+
+// newUsage 显示 new 的帮助信息
+func newUsage() {
+	fmt.Printf("Create a new Fyer Web Framework project\n\n")
+	fmt.Println("Usage:")
+	fmt.Printf("  fyer new [options] [name]\n")
+	fmt.Println("\nOptional features:")
+	for _, f := range scaffold.SupportedFeatures {
+		fmt.Printf("  %-12s %s\n", f.Name, f.Description)
+	}
+	fmt.Println("\nExamples:")
+	fmt.Println("  fyer new")
+	fmt.Println("  fyer new myproject")
+	fmt.Println("  fyer new -module example.com/myproject myproject")
+	fmt.Println("  fyer new -output ./projects/myproject myproject")
+	fmt.Println("  fyer new -db postgresql -features accesslog,session myproject")
+	fmt.Println("  fyer new -run myproject")
+}
+
+// runNew 创建新项目，没有指定项目名称且标准输入是终端时进入交互模式
+func runNew(args []string) error {
+	fs := newFlagSet("new", newUsage)
+	projectName := fs.String("name", "", "Project name, may also be given as the argument (prompted for when omitted in a terminal)")
+	modulePath := fs.String("module", "", "Go module path (default: github.com/{project-name})")
+	outputPath := fs.String("output", "", "Output directory (default: ./{project-name})")
+	runFlag := fs.Bool("run", false, "Run the project after creation")
+	dbDriver := fs.String("db", "mysql", "Database driver: mysql, postgresql or sqlite")
+	features := fs.String("features", "", "Comma separated optional features, e.g. accesslog,session")
+	interactive := fs.Bool("i", false, "Prompt for project settings (default when the name is omitted in a terminal)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	name := *projectName
+	if name == "" {
+		name = fs.Arg(0)
+	}
+	cfg := &scaffold.ProjectConfig{
+		Name:     name,
+		Module:   *modulePath,
+		Database: scaffold.DatabaseConfig{Driver: *dbDriver},
+		Features: splitList(*features),
+	}
+
+	// 未指定项目名称时，在终端中进入交互模式
+	if *interactive || (cfg.Name == "" && isTerminal(os.Stdin)) {
+		if err := runWizard(newPrompter(os.Stdin, os.Stdout), cfg); err != nil {
+			fmt.Println()
+			return err
+		}
+		fmt.Println()
+	}
+
+	// 验证必须的项目名称参数
+	if cfg.Name == "" {
+		fmt.Println("Error: Project name is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	// 设置默认的模块路径和输出路径
+	if cfg.Module == "" {
+		cfg.Module = "github.com/" + cfg.Name
+	}
+
+	// 验证项目名、数据库驱动和功能是否合法
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	outPath := *outputPath
+	if outPath == "" {
+		outPath = cfg.Name
+	}
+
+	// 清理输出路径
+	outPath = filepath.Clean(outPath)
+
+	fmt.Printf("Creating new project '%s'...\n\n", cfg.Name)
+
+	// 创建项目
+	creator, err := NewProjectCreator(cfg.Name)
+	if err != nil {
+		return err
+	}
+	creator.SetModulePath(cfg.Module)
+	creator.SetOutputPath(outPath)
+	creator.SetDatabaseDriver(cfg.Database.Driver)
+	creator.SetFeatures(cfg.Features)
+
+	startTime := time.Now()
+
+	// 执行项目创建
+	if err := creator.Create(); err != nil {
+		return err
+	}
+
+	// 显示项目信息
+	showProjectInfo(cfg.Name, outPath, cfg.Module, time.Since(startTime))
+
+	// 如果设置了运行标志，则运行项目
+	if *runFlag {
+		fmt.Printf("\nRunning project %s...\n", cfg.Name)
+		if err := RunProject(outPath); err != nil {
+			return fmt.Errorf("running project: %w", err)
+		}
+	}
+	return nil
+}
+
+// showProjectInfo 显示项目创建信息
+func showProjectInfo(name, path, module string, duration time.Duration) {
+	fmt.Println("\n✅ Project created successfully!")
+	fmt.Println(strings.Repeat("─", 50))
+	fmt.Printf("Project name:      %s\n", name)
+	fmt.Printf("Location:          %s\n", path)
+	fmt.Printf("Go module:         %s\n", module)
+	fmt.Printf("Creation time:     %v\n", duration.Round(time.Millisecond))
+	fmt.Println(strings.Repeat("─", 50))
+	fmt.Println("\nTo run your new project:")
+	fmt.Printf("  cd %s\n", path)
+	fmt.Println("  fyer dev")
+	fmt.Println()
+	fmt.Printf("Happy coding with Fyer Web Framework!\n\n")
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// reloadTimeout 代理等待应用重新构建并启动的最长时间，超时后返回 503
+const reloadTimeout = 60 * time.Second
+
+// stopTimeout 发送中断信号后等待应用退出的时间，超时后强制结束
+const stopTimeout = 5 * time.Second
+
+// watchApp 构建并运行应用，源文件变化时重新构建并重启，收到中断信号后停止应用并返回。
+// 设置了代理地址时，应用监听随机端口，代理在重新构建期间挂起请求，应用就绪后再转发
+func watchApp(ctx context.Context, opts devOptions, env []string) int {
+	r, err := newAppRunner(opts, env)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return 1
+	}
+	defer r.cleanup()
+
+	if opts.proxy != "" {
+		if r.port, err = freePort(); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+		l, err := net.Listen("tcp", opts.proxy)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			return 1
+		}
+		srv := &http.Server{Handler: newDevProxy(r.port, r.gate)}
+		go func() {
+			if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Printf("Error: proxy: %s\n", err)
+			}
+		}()
+		defer srv.Close()
+		fmt.Printf("Proxying %s to the application on port %s\n", l.Addr(), r.port)
+	}
+
+	w := newDevWatcher(opts)
+	r.watcher = w
+	events := w.watch(ctx)
+	r.restart(ctx, nil)
+	for {
+		select {
+		case changed, ok := <-events:
+			if !ok {
+				r.stop()
+				return 0
+			}
+			if changed = r.dropGenerated(changed); len(changed) == 0 {
+				continue
+			}
+			fmt.Printf("Detected changes in %s, rebuilding...\n", describeChanges(changed))
+			r.restart(ctx, changed)
+		case <-r.exited():
+			if err := r.proc.err; err != nil {
+				fmt.Printf("Application exited: %s, waiting for changes...\n", err)
+			} else {
+				fmt.Println("Application exited, waiting for changes...")
+			}
+			r.proc = nil
+		case <-ctx.Done():
+			r.stop()
+			return 0
+		}
+	}
+}
+
+// newDevWatcher 按照 dev 的参数创建监控项目目录的 watcher
+func newDevWatcher(opts devOptions) *watcher {
+	w := newWatcher(orDefault(opts.dir, "."))
+	w.addExts(opts.watchExts...)
+	w.ignore = opts.ignore
+	w.debounce = opts.debounce
+	return w
+}
+
+// describeChanges 返回变化的文件的简短描述
+func describeChanges(changed []string) string {
+	if len(changed) == 1 {
+		return changed[0]
+	}
+	return fmt.Sprintf("%s and %d other files", changed[0], len(changed)-1)
+}
+
+// appRunner 构建并运行应用，文件变化时重新构建并重启
+type appRunner struct {
+	opts devOptions
+	env  []string
+	bin  string // 构建出的可执行文件
+	port string // 代理模式下应用监听的端口，通过 PORT 环境变量传给应用
+	gate *readyGate
+	proc *appProcess // 正在运行的应用，没有运行时为 nil
+
+	watcher   *watcher             // 用于找出生成命令写入的文件
+	generated map[string]fileState // 生成命令写入的文件
+}
+
+// appProcess 运行中的应用进程
+type appProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{} // 进程退出后关闭
+	err  error         // Wait 的结果，done 关闭后才能读取
+}
+
+// newAppRunner 创建 runner，可执行文件放在临时目录中
+func newAppRunner(opts devOptions, env []string) (*appRunner, error) {
+	dir, err := os.MkdirTemp("", "fyer-dev-")
+	if err != nil {
+		return nil, err
+	}
+	bin := filepath.Join(dir, "app")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	return &appRunner{
+		opts:      opts,
+		env:       env,
+		bin:       bin,
+		gate:      newReadyGate(),
+		generated: make(map[string]fileState),
+	}, nil
+}
+
+// cleanup 删除构建出的可执行文件
+func (r *appRunner) cleanup() {
+	_ = os.RemoveAll(filepath.Dir(r.bin))
+}
+
+// exited 返回当前进程退出时关闭的通道，没有运行的进程时返回 nil
+func (r *appRunner) exited() <-chan struct{} {
+	if r.proc == nil {
+		return nil
+	}
+	return r.proc.done
+}
+
+// restart 停止正在运行的应用，运行受 changed 影响的生成命令后重新构建并启动，changed 为空时运行所有生成命令。
+// 生成或构建失败时保留错误，代理会把错误返回给浏览器
+func (r *appRunner) restart(ctx context.Context, changed []string) {
+	r.gate.hold()
+	r.stop()
+
+	if err := r.runGenerators(ctx, changed); err != nil {
+		fmt.Printf("Generate failed: %s\n", err)
+		r.gate.open(err)
+		return
+	}
+	if err := r.build(ctx); err != nil {
+		fmt.Printf("Build failed: %s\n", err)
+		r.gate.open(err)
+		return
+	}
+	proc, err := r.start()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		r.gate.open(err)
+		return
+	}
+	r.proc = proc
+
+	if r.port == "" {
+		r.gate.open(nil)
+		return
+	}
+	// 应用开始监听端口之前，代理继续挂起请求
+	go func() {
+		waitCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ready := make(chan error, 1)
+		go func() {
+			ready <- waitForPort(waitCtx, "127.0.0.1:"+r.port, reloadTimeout)
+		}()
+		select {
+		case err := <-ready:
+			r.gate.open(err)
+		case <-proc.done:
+			r.gate.open(fmt.Errorf("app exited before listening on port %s: %v", r.port, proc.err))
+		}
+	}()
+}
+
+// build 使用 go build 构建应用，失败时返回编译器的输出
+func (r *appRunner) build(ctx context.Context) error {
+	args := append([]string{"build", "-o", r.bin}, r.opts.buildArgs...)
+	args = append(args, r.opts.app)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = r.opts.dir
+	cmd.Env = r.env
+	fmt.Printf("Building go %v\n", args)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, out)
+	}
+	return nil
+}
+
+// start 启动构建出的应用
+func (r *appRunner) start() (*appProcess, error) {
+	cmd := exec.Command(r.bin, r.opts.appArgs...)
+	cmd.Dir = r.opts.dir
+	cmd.Env = r.env
+	if r.port != "" {
+		cmd.Env = append(cmd.Env, "PORT="+r.port)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	proc := &appProcess{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		proc.err = cmd.Wait()
+		close(proc.done)
+	}()
+	return proc, nil
+}
+
+// stop 中断正在运行的应用并等待退出，超时后强制结束
+func (r *appRunner) stop() {
+	proc := r.proc
+	if proc == nil {
+		return
+	}
+	r.proc = nil
+
+	if proc.cmd.Process.Signal(os.Interrupt) != nil {
+		_ = proc.cmd.Process.Kill()
+	}
+	select {
+	case <-proc.done:
+	case <-time.After(stopTimeout):
+		_ = proc.cmd.Process.Kill()
+		<-proc.done
+	}
+}
+
+// readyGate 应用重新构建期间挂起代理的请求，应用就绪或构建失败后放行
+type readyGate struct {
+	mu    sync.Mutex
+	ready chan struct{}
+	err   error
+}
+
+func newReadyGate() *readyGate {
+	return &readyGate{ready: make(chan struct{})}
+}
+
+// hold 开始挂起之后的请求
+func (g *readyGate) hold() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.ready:
+		g.ready = make(chan struct{})
+	default:
+	}
+	g.err = nil
+}
+
+// open 放行挂起的请求，err 不为空时请求会收到这个错误
+func (g *readyGate) open(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = err
+	select {
+	case <-g.ready:
+	default:
+		close(g.ready)
+	}
+}
+
+// wait 等待应用就绪，返回构建或启动的错误
+func (g *readyGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	ready := g.ready
+	g.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// freePort 返回一个当前空闲的本机端口
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// newDevProxy 返回转发到应用的反向代理，应用重新构建期间请求会等待而不是连接失败
+func newDevProxy(port string, gate *readyGate) http.Handler {
+	target := &url.URL{Scheme: "http", Host: "127.0.0.1:" + port}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		http.Error(w, "fyer dev: app is not reachable: "+err.Error(), http.StatusBadGateway)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), reloadTimeout)
+		err := gate.wait(ctx)
+		cancel()
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			http.Error(w, "fyer dev: timed out waiting for the app to restart", http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			http.Error(w, "fyer dev: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		proxy.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBackend 启动模拟应用的服务器，返回它监听的端口
+func newBackend(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return u.Port()
+}
+
+// proxyGet 通过代理发起请求，返回接收响应的通道
+func proxyGet(proxy http.Handler, path string) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		done <- rec
+	}()
+	return done
+}
+
+func TestReadyGate(t *testing.T) {
+	g := newReadyGate()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// 第一次构建完成之前请求等待
+	assert.ErrorIs(t, g.wait(ctx), context.DeadlineExceeded)
+
+	g.open(nil)
+	assert.NoError(t, g.wait(context.Background()))
+	// 重复放行不会panic
+	g.open(nil)
+
+	// 构建失败时放行并返回错误，下一次重新构建时清除
+	g.hold()
+	g.open(errors.New("build failed"))
+	assert.EqualError(t, g.wait(context.Background()), "build failed")
+	g.hold()
+	g.open(nil)
+	assert.NoError(t, g.wait(context.Background()))
+}
+
+func TestDevProxy_HoldsDuringRebuild(t *testing.T) {
+	port := newBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "app:"+r.URL.Path)
+	})
+	gate := newReadyGate()
+	proxy := newDevProxy(port, gate)
+
+	// 应用就绪之前请求被挂起
+	done := proxyGet(proxy, "/users")
+	select {
+	case <-done:
+		t.Fatal("request should wait for the app to be ready")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	gate.open(nil)
+	select {
+	case rec := <-done:
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "app:/users", rec.Body.String())
+	case <-time.After(time.Second):
+		t.Fatal("request should be forwarded once the app is ready")
+	}
+
+	// 应用就绪后直接转发
+	rec := <-proxyGet(proxy, "/orders")
+	assert.Equal(t, "app:/orders", rec.Body.String())
+
+	// 重新构建期间再次挂起
+	gate.hold()
+	done = proxyGet(proxy, "/again")
+	select {
+	case <-done:
+		t.Fatal("request should wait while the app rebuilds")
+	case <-time.After(20 * time.Millisecond):
+	}
+	gate.open(nil)
+	assert.Equal(t, "app:/again", (<-done).Body.String())
+}
+
+func TestDevProxy_BuildError(t *testing.T) {
+	port := newBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the app")
+	})
+	gate := newReadyGate()
+	gate.open(errors.New("build failed: undefined: foo"))
+
+	// 构建失败时把错误返回给浏览器
+	rec := <-proxyGet(newDevProxy(port, gate), "/")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Contains(t, rec.Body.String(), "fyer dev: build failed: undefined: foo")
+}
+
+func TestDevProxy_Unreachable(t *testing.T) {
+	port, err := freePort()
+	require.NoError(t, err)
+	gate := newReadyGate()
+	gate.open(nil)
+
+	rec := <-proxyGet(newDevProxy(port, gate), "/")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Contains(t, rec.Body.String(), "fyer dev: app is not reachable")
+}
+
+func TestDevProxy_ClientGone(t *testing.T) {
+	port := newBackend(t, func(w http.ResponseWriter, r *http.Request) {})
+	proxy := newDevProxy(port, newReadyGate())
+
+	// 客户端断开时不再等待应用
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, req)
+		done <- rec
+	}()
+	cancel()
+	select {
+	case rec := <-done:
+		assert.Equal(t, http.StatusBadGateway, rec.Code)
+	case <-time.After(time.Second):
+		t.Fatal("proxy should stop waiting when the client goes away")
+	}
+}
+
+func TestFreePort(t *testing.T) {
+	port, err := freePort()
+	require.NoError(t, err)
+	n, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	assert.Positive(t, n)
+
+	// 端口可以被应用监听
+	l, err := net.Listen("tcp", "127.0.0.1:"+port)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestDescribeChanges(t *testing.T) {
+	assert.Equal(t, "main.go", describeChanges([]string{"main.go"}))
+	assert.Equal(t, "main.go and 2 other files", describeChanges([]string{"main.go", "a.go", "b.go"}))
+}
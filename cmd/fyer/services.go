@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// devPassword 开发数据库的密码，容器只绑定本机地址
+const devPassword = "devpass"
+
+// service 开发环境依赖的服务
+type service struct {
+	name   string                       // 服务名称
+	image  string                       // 使用的镜像
+	port   string                       // 容器端口
+	env    []string                     // 容器环境变量
+	envVar string                       // 导出给应用的环境变量名
+	dsn    func(hostPort string) string // 根据宿主机端口生成连接地址
+}
+
+// newMySQL MySQL服务，时间按UTC解析以配合ORM的时区策略
+func newMySQL(image string) service {
+	return service{
+		name:   "mysql",
+		image:  image,
+		port:   "3306/tcp",
+		env:    []string{"MYSQL_ROOT_PASSWORD=" + devPassword, "MYSQL_DATABASE=app"},
+		envVar: "MYSQL_DSN",
+		dsn: func(hostPort string) string {
+			return fmt.Sprintf("root:%s@tcp(127.0.0.1:%s)/app?parseTime=true&loc=UTC", devPassword, hostPort)
+		},
+	}
+}
+
+// newPostgres PostgreSQL服务
+func newPostgres(image string) service {
+	return service{
+		name:   "postgres",
+		image:  image,
+		port:   "5432/tcp",
+		env:    []string{"POSTGRES_PASSWORD=" + devPassword, "POSTGRES_DB=app"},
+		envVar: "POSTGRES_DSN",
+		dsn: func(hostPort string) string {
+			return fmt.Sprintf("postgres://postgres:%s@127.0.0.1:%s/app?sslmode=disable", devPassword, hostPort)
+		},
+	}
+}
+
+// newRedis Redis服务
+func newRedis(image string) service {
+	return service{
+		name:   "redis",
+		image:  image,
+		port:   "6379/tcp",
+		envVar: "REDIS_ADDR",
+		dsn: func(hostPort string) string {
+			return "127.0.0.1:" + hostPort
+		},
+	}
+}
+
+// runningService 已启动的服务
+type runningService struct {
+	service
+	id  string
+	dsn string
+}
+
+// startService 启动服务容器并等待端口可以连接
+func startService(ctx context.Context, docker *dockerClient, svc service, wait time.Duration) (*runningService, error) {
+	name := fmt.Sprintf("fyer-devserver-%s-%d", svc.name, os.Getpid())
+	id, err := docker.run(ctx, containerSpec{
+		Name:  name,
+		Image: svc.image,
+		Env:   svc.env,
+		Port:  svc.port,
+	})
+	running := &runningService{service: svc, id: id}
+	if err != nil {
+		return running, fmt.Errorf("start %s: %w", svc.name, err)
+	}
+
+	hostPort, err := docker.hostPort(ctx, id, svc.port)
+	if err != nil {
+		return running, err
+	}
+	// 数据库镜像初始化期间不监听TCP端口，端口可以连接时服务已经就绪
+	if err = waitForPort(ctx, "127.0.0.1:"+hostPort, wait); err != nil {
+		return running, fmt.Errorf("%s is not ready: %w", svc.name, err)
+	}
+
+	running.dsn = svc.dsn(hostPort)
+	return running, nil
+}
+
+// waitForPort 等待端口可以连接
+func waitForPort(ctx context.Context, addr string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", addr, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
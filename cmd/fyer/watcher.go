@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultWatchExts 默认监控的文件扩展名
+var defaultWatchExts = []string{".go", ".mod", ".sum"}
+
+// defaultDebounce 默认的防抖时间
+const defaultDebounce = 300 * time.Millisecond
+
+// watcher 定期扫描目录中的源文件，文件新增、删除或修改时发出通知
+type watcher struct {
+	root     string        // 扫描的根目录
+	interval time.Duration // 扫描间隔
+	debounce time.Duration // 最后一次变化之后等待的时间，期间的变化合并为一次通知
+	exts     []string      // 关注的文件扩展名
+	skipDirs []string      // 跳过的目录名
+	ignore   []string      // 忽略的文件和目录，使用 matchGlob 的匹配规则
+}
+
+// newWatcher 创建监控 Go 源文件的 watcher
+func newWatcher(root string) *watcher {
+	return &watcher{
+		root:     root,
+		interval: 500 * time.Millisecond,
+		debounce: defaultDebounce,
+		exts:     slices.Clone(defaultWatchExts),
+		skipDirs: []string{"vendor", "node_modules", "testdata"},
+	}
+}
+
+// addExts 增加关注的扩展名，可以省略开头的点，例如 css 和 .css 是相同的
+func (w *watcher) addExts(exts ...string) {
+	for _, ext := range exts {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !slices.Contains(w.exts, ext) {
+			w.exts = append(w.exts, ext)
+		}
+	}
+}
+
+// fileState 文件的大小和修改时间
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watch 在 ctx 结束前持续扫描，每次发现变化时向返回的通道发送发生变化的文件。
+// 一段时间内连续发生的变化（例如 git checkout）会在最后一次变化之后合并发送
+func (w *watcher) watch(ctx context.Context) <-chan []string {
+	events := make(chan []string, 1)
+	go func() {
+		defer close(events)
+		last := w.scan()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		// pending 记录尚未发送的变化，flush 在防抖时间结束后触发
+		pending := make(map[string]struct{})
+		flush := time.NewTimer(w.debounce)
+		flush.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cur := w.scan()
+				changed := diffStates(last, cur)
+				if len(changed) == 0 {
+					continue
+				}
+				last = cur
+				for _, path := range changed {
+					pending[path] = struct{}{}
+				}
+				flush.Reset(w.debounce)
+			case <-flush.C:
+				changed := make([]string, 0, len(pending))
+				for path := range pending {
+					changed = append(changed, path)
+				}
+				slices.Sort(changed)
+				clear(pending)
+				select {
+				case events <- changed:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				flush.Stop()
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// scan 返回所有关注的文件的状态，以 . 或 _ 开头的目录、skipDirs 中的目录和匹配 ignore 的路径会被跳过
+func (w *watcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+	_ = filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == w.root {
+			return nil
+		}
+		rel, _ := filepath.Rel(w.root, path)
+		if d.IsDir() {
+			if w.skipDir(d.Name()) || w.ignored(filepath.ToSlash(rel), true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !w.match(d.Name()) || w.ignored(filepath.ToSlash(rel), false) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return files
+}
+
+func (w *watcher) skipDir(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+	return slices.Contains(w.skipDirs, name)
+}
+
+func (w *watcher) match(name string) bool {
+	return slices.Contains(w.exts, filepath.Ext(name))
+}
+
+// ignored 判断相对于根目录的路径是否被忽略。与 .gitignore 类似：
+// 不含 / 的模式匹配文件名或目录名，含 / 的模式匹配完整的相对路径，以 / 结尾的模式只匹配目录
+func (w *watcher) ignored(rel string, isDir bool) bool {
+	for _, pattern := range w.ignore {
+		if matchPath(pattern, rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath 使用 ignored 的规则匹配以 / 分隔的相对路径
+func matchPath(pattern, rel string, isDir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	name := rel
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(rel)
+	}
+	return matchGlob(strings.TrimPrefix(pattern, "/"), name)
+}
+
+// matchGlob 匹配以 / 分隔的路径，** 匹配任意层目录，其余部分使用 path.Match 的规则
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// checkGlobs 检查文件模式的语法，避免错误的模式悄悄匹配失败
+func checkGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+			if _, err := path.Match(seg, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// diffStates 返回新增、删除或修改的文件
+func diffStates(old, cur map[string]fileState) []string {
+	var changed []string
+	for path, st := range cur {
+		prev, ok := old[path]
+		if !ok || prev.size != st.size || !prev.modTime.Equal(st.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range old {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
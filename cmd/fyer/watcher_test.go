@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles 在 dir 中创建文件，自动创建上级目录
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+// scanned 返回扫描到的文件相对于 dir 的路径
+func scanned(t *testing.T, w *watcher) []string {
+	t.Helper()
+	var names []string
+	for path := range w.scan() {
+		rel, err := filepath.Rel(w.root, path)
+		require.NoError(t, err)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestMatchPath(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		rel     string
+		isDir   bool
+		want    bool
+	}{
+		// 不含 / 的模式匹配文件名或目录名
+		{pattern: "*_gen.go", rel: "models/user_gen.go", want: true},
+		{pattern: "*_gen.go", rel: "models/user.go", want: false},
+		{pattern: "tmp", rel: "a/tmp", isDir: true, want: true},
+		// 含 / 的模式匹配完整的相对路径
+		{pattern: "web/dist", rel: "web/dist", isDir: true, want: true},
+		{pattern: "web/dist", rel: "other/web/dist", isDir: true, want: false},
+		{pattern: "/main.go", rel: "main.go", want: true},
+		{pattern: "/main.go", rel: "cmd/main.go", want: false},
+		// 以 / 结尾的模式只匹配目录
+		{pattern: "web/dist/", rel: "web/dist", isDir: true, want: true},
+		{pattern: "build/", rel: "build", isDir: false, want: false},
+		// ** 匹配任意层目录
+		{pattern: "**/fixtures/*.json", rel: "a/b/fixtures/user.json", want: true},
+		{pattern: "**/fixtures/*.json", rel: "fixtures/user.json", want: true},
+		{pattern: "docs/**", rel: "docs/api/index.md", want: true},
+		{pattern: "docs/**", rel: "src/docs/index.md", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.rel, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchPath(tc.pattern, tc.rel, tc.isDir))
+		})
+	}
+}
+
+func TestCheckGlobs(t *testing.T) {
+	assert.NoError(t, checkGlobs([]string{"*_gen.go", "web/dist/", "**/*.tmp"}))
+	assert.ErrorContains(t, checkGlobs([]string{"*.go", "[a-"}), `invalid pattern "[a-"`)
+}
+
+func TestWatcher_AddExts(t *testing.T) {
+	w := newWatcher(".")
+	w.addExts("css", ".sql", ".go", "css")
+	assert.Equal(t, []string{".go", ".mod", ".sum", ".css", ".sql"}, w.exts)
+}
+
+func TestWatcher_Scan(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":             "package main",
+		"go.mod":              "module app",
+		"README.md":           "readme",
+		"web/style.css":       "body {}",
+		"web/dist/app.css":    "built",
+		"models/user_gen.go":  "package models",
+		"models/user.go":      "package models",
+		"db/schema.sql":       "create table",
+		".git/hooks/x.go":     "package hooks",
+		"_scratch/a.go":       "package scratch",
+		"vendor/lib/lib.go":   "package lib",
+		"node_modules/x/x.go": "package x",
+		"testdata/fixture.go": "package testdata",
+	})
+
+	w := newWatcher(dir)
+	assert.Equal(t, []string{"go.mod", "main.go", "models/user.go", "models/user_gen.go"}, scanned(t, w))
+
+	// 额外的扩展名和忽略的文件
+	w.addExts("css", "sql")
+	w.ignore = []string{"*_gen.go", "web/dist/"}
+	assert.Equal(t, []string{"db/schema.sql", "go.mod", "main.go", "models/user.go", "web/style.css"}, scanned(t, w))
+}
+
+func TestDiffStates(t *testing.T) {
+	now := time.Now()
+	old := map[string]fileState{
+		"same.go":    {size: 1, modTime: now},
+		"resized.go": {size: 1, modTime: now},
+		"touched.go": {size: 1, modTime: now},
+		"removed.go": {size: 1, modTime: now},
+	}
+	cur := map[string]fileState{
+		"same.go":    {size: 1, modTime: now},
+		"resized.go": {size: 2, modTime: now},
+		"touched.go": {size: 1, modTime: now.Add(time.Second)},
+		"added.go":   {size: 1, modTime: now},
+	}
+
+	changed := diffStates(old, cur)
+	slices.Sort(changed)
+	assert.Equal(t, []string{"added.go", "removed.go", "resized.go", "touched.go"}, changed)
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.go": "package main"})
+
+	w := newWatcher(dir)
+	w.interval = 5 * time.Millisecond
+	w.debounce = 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	events := w.watch(ctx)
+
+	// 防抖时间内连续的变化合并为一次通知
+	time.Sleep(20 * time.Millisecond)
+	writeFiles(t, dir, map[string]string{"main.go": "package main\n\nfunc main() {}"})
+	time.Sleep(30 * time.Millisecond)
+	writeFiles(t, dir, map[string]string{"handler.go": "package main"})
+
+	select {
+	case changed := <-events:
+		assert.Equal(t, []string{filepath.Join(dir, "handler.go"), filepath.Join(dir, "main.go")}, changed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+
+	select {
+	case changed := <-events:
+		t.Fatalf("unexpected notification: %v", changed)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// ctx 结束后关闭通道
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("events should be closed after ctx is done")
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/scaffold"
+)
+
+// prompter 在终端中逐项询问用户输入，直接回车使用默认值
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine 读取一行输入，输入结束时返回 io.ErrUnexpectedEOF
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// input 询问文本输入，validate 不为空时会重复询问直到输入合法
+func (p *prompter) input(label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "? %s (%s): ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "? %s: ", label)
+		}
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(p.out, "  %s\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// choose 询问单选，可以输入序号或选项本身
+func (p *prompter) choose(label string, options []string, def int) (string, error) {
+	fmt.Fprintf(p.out, "? %s\n", label)
+	for i, opt := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, opt)
+	}
+	for {
+		fmt.Fprintf(p.out, "  Choose (%s): ", options[def])
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return options[def], nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		for _, opt := range options {
+			if strings.EqualFold(answer, opt) {
+				return opt, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  Please enter a number between 1 and %d\n", len(options))
+	}
+}
+
+// multiSelect 询问多选，输入以逗号分隔的序号，直接回车表示不选
+func (p *prompter) multiSelect(label string, features []scaffold.Feature) ([]string, error) {
+	fmt.Fprintf(p.out, "? %s\n", label)
+	for i, f := range features {
+		fmt.Fprintf(p.out, "  %d) %-12s %s\n", i+1, f.Name, f.Description)
+	}
+	for {
+		fmt.Fprint(p.out, "  Choose (comma separated, empty for none): ")
+		answer, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		selected, err := parseSelection(answer, features)
+		if err != nil {
+			fmt.Fprintf(p.out, "  %s\n", err)
+			continue
+		}
+		return selected, nil
+	}
+}
+
+// confirm 询问是否继续
+func (p *prompter) confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "? %s (%s): ", label, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// parseSelection 解析多选的输入，序号和功能名称都可以使用，重复的选项只保留一个
+func parseSelection(answer string, features []scaffold.Feature) ([]string, error) {
+	var selected []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name := item
+		if n, err := strconv.Atoi(item); err == nil {
+			if n < 1 || n > len(features) {
+				return nil, fmt.Errorf("invalid choice %d", n)
+			}
+			name = features[n-1].Name
+		} else if !scaffold.IsSupportedFeature(name) {
+			return nil, fmt.Errorf("unknown feature %q", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+// errWizardAborted 用户在确认时选择了取消
+var errWizardAborted = errors.New("project creation aborted")
+
+// runWizard 通过交互式提示收集项目配置，cfg 中已有的值作为默认值
+func runWizard(p *prompter, cfg *scaffold.ProjectConfig) error {
+	fmt.Fprintln(p.out, "Fyer Web Framework Project Scaffold")
+	fmt.Fprintln(p.out)
+
+	name, err := p.input("Project name", cfg.Name, scaffold.ValidateProjectName)
+	if err != nil {
+		return err
+	}
+	cfg.Name = name
+
+	module := cfg.Module
+	if module == "" {
+		module = "github.com/" + name
+	}
+	if cfg.Module, err = p.input("Go module path", module, func(s string) error {
+		if s == "" || strings.ContainsAny(s, " \t") {
+			return fmt.Errorf("invalid module path %q", s)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	def := 0
+	for i, d := range scaffold.SupportedDrivers {
+		if d == cfg.Database.Driver {
+			def = i
+		}
+	}
+	if cfg.Database.Driver, err = p.choose("Database driver", scaffold.SupportedDrivers, def); err != nil {
+		return err
+	}
+
+	if cfg.Features, err = p.multiSelect("Optional features", scaffold.SupportedFeatures); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.out)
+	fmt.Fprintf(p.out, "  Project:  %s\n", cfg.Name)
+	fmt.Fprintf(p.out, "  Module:   %s\n", cfg.Module)
+	fmt.Fprintf(p.out, "  Database: %s\n", cfg.Database.Driver)
+	fmt.Fprintf(p.out, "  Features: %s\n", strings.Join(cfg.Features, ", "))
+	ok, err := p.confirm("Create project", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errWizardAborted
+	}
+	return nil
+}
+
+// isTerminal 判断标准输入是否为终端，管道或重定向时不进入交互模式
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/scaffold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wizard 用给定的每一行输入运行向导，返回向导的输出
+func wizard(t *testing.T, cfg *scaffold.ProjectConfig, lines ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(lines, "\n") + "\n")
+	err := runWizard(newPrompter(in, &out), cfg)
+	return out.String(), err
+}
+
+func TestRunWizard_Defaults(t *testing.T) {
+	// 命令行参数作为默认值，直接回车全部使用默认值
+	cfg := &scaffold.ProjectConfig{
+		Name:     "blog",
+		Database: scaffold.DatabaseConfig{Driver: "postgresql"},
+	}
+	out, err := wizard(t, cfg, "", "", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, &scaffold.ProjectConfig{
+		Name:     "blog",
+		Module:   "github.com/blog",
+		Database: scaffold.DatabaseConfig{Driver: "postgresql"},
+	}, cfg)
+	assert.Contains(t, out, "? Project name (blog): ")
+	assert.Contains(t, out, "? Go module path (github.com/blog): ")
+	assert.Contains(t, out, "  Choose (postgresql): ")
+	assert.Contains(t, out, "? Create project (Y/n): ")
+
+	// 没有指定驱动时默认使用第一个
+	cfg = &scaffold.ProjectConfig{Name: "shop", Module: "example.com/shop"}
+	_, err = wizard(t, cfg, "", "", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/shop", cfg.Module)
+	assert.Equal(t, scaffold.SupportedDrivers[0], cfg.Database.Driver)
+	assert.Empty(t, cfg.Features)
+}
+
+func TestRunWizard_Answers(t *testing.T) {
+	cfg := &scaffold.ProjectConfig{}
+	out, err := wizard(t, cfg,
+		"",        // 项目名称没有默认值，重新询问
+		"my blog", // 非法名称，重新询问
+		"blog",
+		"example.com/a b", // 模块路径包含空格，重新询问
+		"example.com/blog",
+		"9",             // 超出范围，重新询问
+		"SQLite",        // 可以输入选项本身
+		"2, session, 2", // 序号和名称都可以使用，重复的只保留一个
+		"maybe",         // 无法识别的回答，重新询问
+		"y",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &scaffold.ProjectConfig{
+		Name:     "blog",
+		Module:   "example.com/blog",
+		Database: scaffold.DatabaseConfig{Driver: "sqlite"},
+		Features: []string{"recovery", "session"},
+	}, cfg)
+	assert.Contains(t, out, "project name cannot be empty")
+	assert.Contains(t, out, "project name contains invalid characters")
+	assert.Contains(t, out, `invalid module path "example.com/a b"`)
+	assert.Contains(t, out, "Please enter a number between 1 and 3")
+	assert.Contains(t, out, "  Features: recovery, session\n")
+}
+
+func TestRunWizard_Abort(t *testing.T) {
+	cfg := &scaffold.ProjectConfig{Name: "blog"}
+	_, err := wizard(t, cfg, "", "", "1", "", "n")
+	assert.ErrorIs(t, err, errWizardAborted)
+
+	// 输入提前结束
+	_, err = wizard(t, &scaffold.ProjectConfig{Name: "blog"}, "", "")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestParseSelection(t *testing.T) {
+	features := scaffold.SupportedFeatures
+
+	selected, err := parseSelection(" 1 ,prometheus,, 1", features)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"accesslog", "prometheus"}, selected)
+
+	selected, err = parseSelection("", features)
+	require.NoError(t, err)
+	assert.Empty(t, selected)
+
+	_, err = parseSelection("0", features)
+	assert.EqualError(t, err, "invalid choice 0")
+	_, err = parseSelection("graphql", features)
+	assert.EqualError(t, err, `unknown feature "graphql"`)
+}
+
+func TestPrompter_ReadLine(t *testing.T) {
+	// 最后一行没有换行符时仍然可以读取
+	p := newPrompter(strings.NewReader("first\r\nlast"), io.Discard)
+	line, err := p.readLine()
+	require.NoError(t, err)
+	assert.Equal(t, "first", line)
+	line, err = p.readLine()
+	require.NoError(t, err)
+	assert.Equal(t, "last", line)
+	_, err = p.readLine()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
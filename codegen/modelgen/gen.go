@@ -0,0 +1,438 @@
+package modelgen
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/codegen/predicate_gen"
+	"github.com/fyerfyer/fyer-webframe/orm"
+)
+
+// Config 生成模型的配置
+type Config struct {
+	Dialect    string   // SQL 方言：mysql、postgresql 或 sqlite
+	Schema     string   // MySQL 的数据库名或 PostgreSQL 的 schema，为空时使用当前数据库
+	Tables     []string // 只生成这些表，为空时生成所有表
+	Exclude    []string // 跳过的表，如迁移日志表
+	OutputDir  string   // 输出目录
+	Package    string   // 生成代码的包名，为空时使用输出目录名
+	Predicates bool     // 同时为模型生成谓词和查询构建器
+	Meta       bool     // 同时生成模型元数据，ORM 使用它代替反射
+}
+
+// Field 生成的结构体字段
+type Field struct {
+	Name    string
+	Type    string
+	Tag     string // orm 标签的内容
+	Comment string
+}
+
+// ModelInfo 一张表生成的模型
+type ModelInfo struct {
+	Pkg       string
+	Table     string
+	Name      string
+	Fields    []Field
+	Imports   []string
+	Notes     []string // 无法用标签表示的索引和外键
+	TableName bool     // 结构体名转换后与表名不同时生成 TableName 方法
+}
+
+// Generate 读取数据库中的表结构，为每张表生成一个包含模型结构体的文件，返回生成的文件
+func Generate(ctx context.Context, db *orm.DB, cfg Config) ([]string, error) {
+	d := orm.Get(cfg.Dialect)
+	if d == nil {
+		return nil, fmt.Errorf("unknown dialect: %s", cfg.Dialect)
+	}
+	if cfg.Package == "" {
+		abs, err := filepath.Abs(cfg.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Package = packageName(filepath.Base(abs))
+	}
+
+	tables := cfg.Tables
+	if len(tables) == 0 {
+		all, err := db.Tables(ctx, cfg.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("list tables error: %w", err)
+		}
+		tables = all
+	}
+
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, table := range tables {
+		if containsString(cfg.Exclude, table) {
+			continue
+		}
+		schema, err := db.TableSchema(ctx, cfg.Schema, table)
+		if err != nil {
+			return nil, fmt.Errorf("read table %s error: %w", table, err)
+		}
+		info, err := BuildModel(d, schema, cfg.Package)
+		if err != nil {
+			return nil, fmt.Errorf("build model for table %s error: %w", table, err)
+		}
+		src, err := Render(info)
+		if err != nil {
+			return nil, fmt.Errorf("render model for table %s error: %w", table, err)
+		}
+
+		filePath := filepath.Join(cfg.OutputDir, fileName(table)+".go")
+		if err := os.WriteFile(filePath, src, 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, filePath)
+
+		if cfg.Predicates {
+			if err := predicate_gen.Generate(filePath, cfg.OutputDir); err != nil {
+				return nil, fmt.Errorf("generate predicates for table %s error: %w", table, err)
+			}
+		}
+		if cfg.Meta {
+			if err := predicate_gen.GenerateMeta(filePath, cfg.OutputDir); err != nil {
+				return nil, fmt.Errorf("generate model metadata for table %s error: %w", table, err)
+			}
+		}
+	}
+	return files, nil
+}
+
+// Render 生成模型文件的源码
+func Render(info *ModelInfo) ([]byte, error) {
+	tmpl, err := template.New("model").Parse(modelTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, info); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// BuildModel 把表结构转换为模型：列转换为字段，列的类型、约束、索引和外键写入 orm 标签。
+// 字段类型使用方言默认映射不到列的类型时，通过 type 标签指定，
+// 保证用生成的模型执行迁移时不会产生变更
+func BuildModel(d orm.Dialect, t *orm.TableSchema, pkg string) (*ModelInfo, error) {
+	info := &ModelInfo{Pkg: pkg, Table: t.Name, Name: goName(t.Name, "T")}
+	info.TableName = camelToSnake(info.Name) != t.Name
+
+	tags := make(map[string][]string, len(t.Columns))
+	info.Notes = append(info.Notes, indexTags(t, tags)...)
+	info.Notes = append(info.Notes, foreignKeyTags(t, tags)...)
+
+	imports := make(map[string]bool)
+	used := make(map[string]bool, len(t.Columns))
+	for _, c := range t.Columns {
+		f, err := buildField(d, c, tags[c.Name])
+		if err != nil {
+			return nil, err
+		}
+		// 不同的列转换后可能同名，如 user_id 和 userid
+		name := f.Name
+		for i := 2; used[f.Name]; i++ {
+			f.Name = name + strconv.Itoa(i)
+		}
+		used[f.Name] = true
+		if camelToSnake(f.Name) != c.Name {
+			f.Tag = joinTag(append([]string{"column_name:" + c.Name}, f.Tag)...)
+		}
+
+		switch {
+		case strings.HasPrefix(f.Type, "sql."):
+			imports["database/sql"] = true
+		case f.Type == "time.Time":
+			imports["time"] = true
+		}
+		info.Fields = append(info.Fields, f)
+	}
+
+	for imp := range imports {
+		info.Imports = append(info.Imports, imp)
+	}
+	sort.Strings(info.Imports)
+	return info, nil
+}
+
+// buildField 根据列的类型选择字段类型并生成标签，extra 为索引和外键的标签
+func buildField(d orm.Dialect, c *orm.ColumnSchema, extra []string) (Field, error) {
+	f := Field{Name: goName(c.Name, "F")}
+	goType, typ, attrs := fieldType(c)
+	// SQLite 中自增的整数主键是 64 位的 rowid
+	if _, ok := d.(*orm.Sqlite); ok && c.PrimaryKey && c.AutoIncr && goType == "int" {
+		goType, typ = "int64", goTypes["int64"]
+	}
+	f.Type = goType
+
+	var parts []string
+	if c.PrimaryKey {
+		parts = append(parts, "primary_key")
+	}
+	if c.AutoIncr {
+		parts = append(parts, "auto_increment")
+	}
+
+	// 方言默认映射的类型与列的类型不同时用 type 标签指定，不再需要 size 等标签
+	mapped, err := orm.ColumnType(d, typ, joinTag(attrs...))
+	if err != nil {
+		return f, err
+	}
+	if mapped == c.Type {
+		parts = append(parts, attrs...)
+	} else {
+		if strings.ContainsAny(c.Type, ";:\"`") {
+			return f, fmt.Errorf("column %s: type %s cannot be written in a tag", c.Name, c.Type)
+		}
+		parts = append(parts, "type:"+c.Type)
+	}
+
+	if !c.Nullable && !c.PrimaryKey {
+		parts = append(parts, "nullable:false")
+	}
+	if c.Default != "" {
+		// 标签以 ; 和 : 分隔，包含它们的默认值只能写在注释中
+		if strings.ContainsAny(c.Default, ";:\"`") {
+			f.Comment = "默认值 " + c.Default
+		} else {
+			parts = append(parts, "default:"+c.Default)
+		}
+	}
+	parts = append(parts, extra...)
+
+	f.Tag = joinTag(parts...)
+	return f, nil
+}
+
+var (
+	nullTypes = map[string]string{
+		"string":    "sql.NullString",
+		"bool":      "sql.NullBool",
+		"float64":   "sql.NullFloat64",
+		"time.Time": "sql.NullTime",
+	}
+	goTypes = map[string]reflect.Type{
+		"bool":            reflect.TypeOf(false),
+		"int":             reflect.TypeOf(int(0)),
+		"int8":            reflect.TypeOf(int8(0)),
+		"int16":           reflect.TypeOf(int16(0)),
+		"int64":           reflect.TypeOf(int64(0)),
+		"uint":            reflect.TypeOf(uint(0)),
+		"uint8":           reflect.TypeOf(uint8(0)),
+		"uint16":          reflect.TypeOf(uint16(0)),
+		"uint64":          reflect.TypeOf(uint64(0)),
+		"float32":         reflect.TypeOf(float32(0)),
+		"float64":         reflect.TypeOf(float64(0)),
+		"string":          reflect.TypeOf(""),
+		"[]byte":          reflect.TypeOf([]byte(nil)),
+		"time.Time":       reflect.TypeOf(time.Time{}),
+		"sql.NullString":  reflect.TypeOf(sql.NullString{}),
+		"sql.NullInt64":   reflect.TypeOf(sql.NullInt64{}),
+		"sql.NullBool":    reflect.TypeOf(sql.NullBool{}),
+		"sql.NullFloat64": reflect.TypeOf(sql.NullFloat64{}),
+		"sql.NullTime":    reflect.TypeOf(sql.NullTime{}),
+	}
+	typeRe = regexp.MustCompile(`^([A-Z][A-Z ]*?)(?:\(([0-9, ]*)\))?((?: [A-Z]+)*)$`)
+)
+
+// fieldType 根据规范化后的列类型选择 Go 类型，返回类型名、对应的反射类型和 size、precision 等标签。
+// 允许 NULL 的列使用 sql.NullXXX 类型，无法识别的类型使用 string
+func fieldType(c *orm.ColumnSchema) (string, reflect.Type, []string) {
+	base, args, suffix := c.Type, []string(nil), ""
+	if m := typeRe.FindStringSubmatch(c.Type); m != nil {
+		base, suffix = m[1], strings.TrimSpace(m[3])
+		if m[2] != "" {
+			args = strings.Split(strings.ReplaceAll(m[2], " ", ""), ",")
+		}
+	}
+	unsigned := suffix == "UNSIGNED"
+
+	var goType string
+	var attrs []string
+	switch base {
+	case "BOOL", "BOOLEAN":
+		goType = "bool"
+	case "TINYINT":
+		switch {
+		case len(args) == 1 && args[0] == "1":
+			goType = "bool"
+		case unsigned:
+			goType = "uint8"
+		default:
+			goType = "int8"
+		}
+	case "SMALLINT":
+		goType = "int16"
+		if unsigned {
+			goType = "uint16"
+		}
+	case "MEDIUMINT", "INT", "INTEGER":
+		goType = "int"
+		if unsigned {
+			goType = "uint"
+		}
+	case "BIGINT":
+		goType = "int64"
+		if unsigned {
+			goType = "uint64"
+		}
+	case "FLOAT":
+		goType = "float32"
+	case "REAL", "DOUBLE", "DOUBLE PRECISION":
+		goType = "float64"
+	case "DECIMAL", "NUMERIC":
+		goType = "float64"
+		if len(args) == 2 {
+			attrs = append(attrs, "precision:"+args[0], "scale:"+args[1])
+		}
+	case "VARCHAR", "CHARACTER VARYING", "CHAR", "CHARACTER", "TEXT":
+		goType = "string"
+		if len(args) == 1 && base != "CHAR" && base != "CHARACTER" {
+			attrs = append(attrs, "size:"+args[0])
+		}
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE":
+		goType = "time.Time"
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA":
+		goType = "[]byte"
+	default:
+		goType = "string"
+	}
+
+	if c.Nullable && !c.PrimaryKey {
+		if nt, ok := nullTypes[goType]; ok {
+			goType = nt
+		} else if goType != "[]byte" {
+			goType = "sql.NullInt64"
+		}
+	}
+	return goType, goTypes[goType], attrs
+}
+
+// indexTags 为索引中的列生成 index 和 unique 标签。
+// 每个字段只能有一个 index 和一个 unique 标签，联合索引的列按字段顺序排列，
+// 无法用标签表示的索引返回说明，需要在迁移中手动维护
+func indexTags(t *orm.TableSchema, tags map[string][]string) []string {
+	var notes []string
+	hasIndex := make(map[string]bool)
+	hasUnique := make(map[string]bool)
+	for _, idx := range t.Indexes {
+		key, used := "index", hasIndex
+		if idx.Unique {
+			key, used = "unique", hasUnique
+		}
+
+		ok := inColumnOrder(t, idx.Columns)
+		for _, col := range idx.Columns {
+			ok = ok && !used[col]
+		}
+		if !ok {
+			notes = append(notes, fmt.Sprintf("索引 %s %s 无法用标签表示", idx.Name, idx.String()))
+			continue
+		}
+
+		prefix := "idx_"
+		if idx.Unique {
+			prefix = "uk_"
+		}
+		tag := key + ":" + idx.Name
+		if len(idx.Columns) == 1 && idx.Name == prefix+t.Name+"_"+idx.Columns[0] {
+			tag = key
+		}
+		for _, col := range idx.Columns {
+			used[col] = true
+			tags[col] = append(tags[col], tag)
+		}
+	}
+	return notes
+}
+
+// foreignKeyTags 为单列外键生成 fk 标签，联合外键返回说明
+func foreignKeyTags(t *orm.TableSchema, tags map[string][]string) []string {
+	var notes []string
+	has := make(map[string]bool)
+	for _, fk := range t.ForeignKeys {
+		if len(fk.Columns) != 1 || has[fk.Columns[0]] {
+			notes = append(notes, fmt.Sprintf("外键 %s %s 无法用标签表示", fk.Name, fk.String()))
+			continue
+		}
+		col := fk.Columns[0]
+		has[col] = true
+
+		tag := "fk:" + fk.RefTable
+		if len(fk.RefColumns) != 1 || fk.RefColumns[0] != "id" {
+			tag += "(" + strings.Join(fk.RefColumns, ",") + ")"
+		}
+		if rule := tagRule(fk.OnDelete); rule != "" {
+			tag += " on_delete:" + rule
+		}
+		if rule := tagRule(fk.OnUpdate); rule != "" {
+			tag += " on_update:" + rule
+		}
+		tags[col] = append(tags[col], tag)
+	}
+	return notes
+}
+
+// tagRule 把外键规则转换为标签中的写法，如 SET NULL 转换为 set_null
+func tagRule(rule string) string {
+	if rule == "" || rule == "NO ACTION" {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(rule, " ", "_"))
+}
+
+// inColumnOrder 判断联合索引的列是否与表中列的顺序一致
+func inColumnOrder(t *orm.TableSchema, cols []string) bool {
+	last := -1
+	for _, col := range cols {
+		pos := -1
+		for i, c := range t.Columns {
+			if c.Name == col {
+				pos = i
+				break
+			}
+		}
+		if pos <= last {
+			return false
+		}
+		last = pos
+	}
+	return true
+}
+
+func joinTag(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ";")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
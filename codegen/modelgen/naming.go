@@ -0,0 +1,110 @@
+package modelgen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// initialisms 转换为 Go 名称时全部大写的单词，ORM 的命名规则可以把它们还原为原来的列名
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "uid": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goName 把表名或列名转换为导出的 Go 名称，如 user_id 转换为 UserID。
+// 以数字开头的名称加上前缀 prefix
+func goName(name, prefix string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if initialisms[lower] {
+			sb.WriteString(strings.ToUpper(w))
+			continue
+		}
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+
+	s := sb.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		return prefix + s
+	}
+	return s
+}
+
+// camelToSnake 将驼峰式命名转换为下划线命名，与 ORM 默认的命名规则一致
+func camelToSnake(camelStr string) string {
+	var result strings.Builder
+	runes := []rune(camelStr)
+	length := len(runes)
+
+	for i := 0; i < length; i++ {
+		current := runes[i]
+
+		var nextIsLower, nextIsUpper, prevIsUpper bool
+		if i < length-1 {
+			nextIsLower = unicode.IsLower(runes[i+1])
+			nextIsUpper = unicode.IsUpper(runes[i+1])
+		}
+		if i > 0 {
+			prevIsUpper = unicode.IsUpper(runes[i-1])
+		}
+		lastIsUnderscore := result.Len() > 0 && result.String()[result.Len()-1] == '_'
+
+		if unicode.IsUpper(current) {
+			// 前一个是小写字母，或者下一个是小写字母时添加下划线
+			if i > 0 && (nextIsLower || !prevIsUpper) && result.Len() > 0 && !lastIsUnderscore {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(current))
+		} else if unicode.IsLower(current) {
+			if i > 0 && prevIsUpper && nextIsUpper && result.Len() > 0 && !lastIsUnderscore {
+				result.WriteRune('_')
+			}
+			result.WriteRune(current)
+		}
+	}
+
+	return result.String()
+}
+
+// buildSuffixes 文件名以这些后缀结尾时会被 go 命令当作测试文件或带有构建约束的文件
+var buildSuffixes = []string{
+	"_test", "_linux", "_windows", "_darwin", "_freebsd", "_js", "_wasip1",
+	"_386", "_amd64", "_arm", "_arm64", "_wasm",
+}
+
+// fileName 根据表名生成文件名，不包含 .go 扩展名
+func fileName(table string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, table)
+	for _, suffix := range buildSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return name + "_model"
+		}
+	}
+	return name
+}
+
+// packageName 根据目录名生成包名
+func packageName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, dir)
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		return "model"
+	}
+	return name
+}
@@ -0,0 +1,29 @@
+package modelgen
+
+const modelTemplate = `// Code generated by orm model generator. DO NOT EDIT.
+// Source: table {{.Table}}
+package {{.Pkg}}
+
+{{if .Imports}}
+import (
+{{- range .Imports}}
+    "{{.}}"
+{{- end}}
+)
+{{end}}
+
+// {{.Name}} 对应数据表 {{.Table}}
+{{- range .Notes}}
+// {{.}}，需要在迁移中手动维护
+{{- end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+    {{.Name}} {{.Type}}{{if .Tag}} ` + "`" + `orm:"{{.Tag}}"` + "`" + `{{end}}{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+}
+{{if .TableName}}
+// TableName 返回数据表名
+func ({{.Name}}) TableName() string {
+    return "{{.Table}}"
+}
+{{end}}`
@@ -7,6 +7,8 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -14,6 +16,7 @@ import (
 type Field struct {
 	Name string
 	Type string
+	Tag  string // orm 标签的内容
 }
 
 type ImportInfo struct {
@@ -29,11 +32,36 @@ type StructInfo struct {
 }
 
 func Generate(inputFile string, outputDir string) error {
+	src, err := parseSource(inputFile)
+	if err != nil {
+		return err
+	}
+
+	// 生成代码
+	for _, st := range src.structs {
+		if err := generateForStruct(st, outputDir); err != nil {
+			return fmt.Errorf("generate code error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// source 解析后的源文件
+type source struct {
+	file      *ast.File
+	pkg       string
+	importMap map[string]ImportInfo
+	structs   []StructInfo
+}
+
+// parseSource 解析源文件中的导入和结构体
+func parseSource(inputFile string) (*source, error) {
 	// 解析Go源文件
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, inputFile, nil, parser.ParseComments)
 	if err != nil {
-		return fmt.Errorf("parse file error: %w", err)
+		return nil, fmt.Errorf("parse file error: %w", err)
 	}
 
 	// 创建导入包映射
@@ -89,9 +117,17 @@ func Generate(inputFile string, outputDir string) error {
 						}
 					}
 
+					var tag string
+					if field.Tag != nil {
+						if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+							tag = reflect.StructTag(raw).Get("orm")
+						}
+					}
+
 					info.Fields = append(info.Fields, Field{
 						Name: field.Names[0].Name,
 						Type: typeStr,
+						Tag:  tag,
 					})
 				}
 				structs = append(structs, info)
@@ -100,14 +136,12 @@ func Generate(inputFile string, outputDir string) error {
 		return true
 	})
 
-	// 生成代码
-	for _, st := range structs {
-		if err := generateForStruct(st, outputDir); err != nil {
-			return fmt.Errorf("generate code error: %w", err)
-		}
-	}
-
-	return nil
+	return &source{
+		file:      node,
+		pkg:       pkg,
+		importMap: importMap,
+		structs:   structs,
+	}, nil
 }
 
 // 修改 extractTypeInfo 函数，移除特殊处理
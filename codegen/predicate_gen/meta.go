@@ -0,0 +1,123 @@
+package predicate_gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type MetaInfo struct {
+	Name   string
+	Pkg    string
+	Table  string
+	Fields []MetaField
+}
+
+type MetaField struct {
+	Name   string
+	Column string
+	Tag    string
+}
+
+// GenerateMeta 为源文件中的每个结构体生成模型元数据，包括表名、列名、标签和字段的访问函数。
+// 生成的代码在 init 中调用 orm.RegisterModelMeta 注册，ORM 解析模型和扫描结果时不再使用反射，
+// 需要生成到模型所在的包中，结构体修改后需要重新生成
+func GenerateMeta(inputFile string, outputDir string) error {
+	src, err := parseSource(inputFile)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range src.structs {
+		info := MetaInfo{
+			Name:  st.Name,
+			Pkg:   st.Pkg,
+			Table: camelToSnake(st.Name),
+		}
+		for _, f := range st.Fields {
+			info.Fields = append(info.Fields, MetaField{
+				Name:   f.Name,
+				Column: columnName(f),
+				Tag:    f.Tag,
+			})
+		}
+		if err = generateMeta(info, outputDir); err != nil {
+			return fmt.Errorf("generate code error: %w", err)
+		}
+	}
+	return nil
+}
+
+// columnName 字段对应的列名，与 ORM 解析标签的规则一致：优先使用 column_name 标签，否则使用字段名的下划线形式
+func columnName(f Field) string {
+	for _, part := range strings.Split(f.Tag, ";") {
+		kvs := strings.Split(part, ":")
+		if len(kvs) == 2 && kvs[0] == "column_name" {
+			return kvs[1]
+		}
+	}
+	return camelToSnake(f.Name)
+}
+
+// camelToSnake 将驼峰式命名转换为下划线命名，与 ORM 默认的命名规则一致
+func camelToSnake(camelStr string) string {
+	var result strings.Builder
+	runes := []rune(camelStr)
+	length := len(runes)
+
+	for i := 0; i < length; i++ {
+		current := runes[i]
+
+		var nextIsLower, nextIsUpper, prevIsUpper bool
+		if i < length-1 {
+			nextIsLower = unicode.IsLower(runes[i+1])
+			nextIsUpper = unicode.IsUpper(runes[i+1])
+		}
+		if i > 0 {
+			prevIsUpper = unicode.IsUpper(runes[i-1])
+		}
+		lastIsUnderscore := result.Len() > 0 && result.String()[result.Len()-1] == '_'
+
+		if unicode.IsUpper(current) {
+			// 前一个是小写字母，或者下一个是小写字母时添加下划线
+			if i > 0 && (nextIsLower || !prevIsUpper) && result.Len() > 0 && !lastIsUnderscore {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(current))
+		} else if unicode.IsLower(current) {
+			if i > 0 && prevIsUpper && nextIsUpper && result.Len() > 0 && !lastIsUnderscore {
+				result.WriteRune('_')
+			}
+			result.WriteRune(current)
+		}
+	}
+
+	return result.String()
+}
+
+func generateMeta(info MetaInfo, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("meta").Parse(metaTemplate)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, info); err != nil {
+		return err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+
+	fileName := strings.ToLower(info.Name) + "_meta.gen.go"
+	return os.WriteFile(filepath.Join(outputDir, fileName), code, 0644)
+}
@@ -0,0 +1,417 @@
+package predicate_gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const ormImportPath = "github.com/fyerfyer/fyer-webframe/orm"
+
+// RepoInfo 一个模型的仓储
+type RepoInfo struct {
+	Name       string // 模型名
+	Pkg        string
+	StdImports []ImportInfo // 标准库
+	Imports    []ImportInfo
+	Interface  string // 声明了方法的接口名，没有声明时为空
+	Methods    []RepoMethod
+}
+
+// RepoMethod 根据方法名生成的仓储方法
+type RepoMethod struct {
+	Name    string
+	Params  string
+	Results string
+	Body    string
+}
+
+// GenerateRepository 为源文件中的每个结构体生成仓储，仓储包含基础的增删查方法。
+// 源文件中声明了名为 <结构体名>Repository 的接口时，根据接口中的方法名生成查询，
+// 例如 FindByEmail、ListByStatusOrderByIDDesc、CountByAgeGTAndStatus，生成的仓储实现该接口。
+// 生成的查询使用 Generate 生成的谓词函数，两者需要生成到同一个包中
+func GenerateRepository(inputFile string, outputDir string) error {
+	src, err := parseSource(inputFile)
+	if err != nil {
+		return err
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	ast.Inspect(src.file, func(n ast.Node) bool {
+		if t, ok := n.(*ast.TypeSpec); ok {
+			if it, ok := t.Type.(*ast.InterfaceType); ok {
+				interfaces[t.Name.Name] = it
+			}
+		}
+		return true
+	})
+
+	for _, st := range src.structs {
+		info, err := buildRepoInfo(src, st, interfaces[st.Name+"Repository"])
+		if err != nil {
+			return fmt.Errorf("generate repository for %s: %w", st.Name, err)
+		}
+		if err = generateRepository(info, outputDir); err != nil {
+			return fmt.Errorf("generate code error: %w", err)
+		}
+	}
+	return nil
+}
+
+func buildRepoInfo(src *source, st StructInfo, it *ast.InterfaceType) (RepoInfo, error) {
+	info := RepoInfo{Name: st.Name, Pkg: st.Pkg}
+	if it == nil {
+		return info, nil
+	}
+	info.Interface = st.Name + "Repository"
+
+	fields := make([]string, 0, len(st.Fields))
+	for _, f := range st.Fields {
+		fields = append(fields, f.Name)
+	}
+	// 优先匹配较长的字段名，避免 Name 截断 NameEN 这样的字段
+	sort.Slice(fields, func(i, j int) bool {
+		return len(fields[i]) > len(fields[j])
+	})
+
+	imports := make(map[string]ImportInfo)
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return info, fmt.Errorf("interface %s can only declare methods", info.Interface)
+		}
+		name := m.Names[0].Name
+		if isBaseMethod(name) {
+			continue
+		}
+		q, err := parseMethodName(name, fields)
+		if err != nil {
+			return info, err
+		}
+		method, err := buildMethod(st.Name, name, q, ft)
+		if err != nil {
+			return info, err
+		}
+		info.Methods = append(info.Methods, method)
+
+		// 收集参数和返回值中使用的包
+		ast.Inspect(ft, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if imp, ok := src.importMap[ident.Name]; ok && imp.Path != "context" && imp.Path != ormImportPath {
+					imports[imp.Path] = imp
+				}
+			}
+			return true
+		})
+	}
+
+	for _, imp := range imports {
+		// 标准库的导入路径第一段不包含域名
+		if strings.Contains(strings.Split(imp.Path, "/")[0], ".") {
+			info.Imports = append(info.Imports, imp)
+		} else {
+			info.StdImports = append(info.StdImports, imp)
+		}
+	}
+	for _, list := range [][]ImportInfo{info.StdImports, info.Imports} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	}
+	return info, nil
+}
+
+// isBaseMethod 判断是否是仓储默认生成的方法，接口中可以声明这些方法
+func isBaseMethod(name string) bool {
+	switch name {
+	case "Create", "Find", "List", "Count", "Delete":
+		return true
+	}
+	return false
+}
+
+// methodQuery 从方法名中解析出的查询
+type methodQuery struct {
+	action string // Find、List、Count、Exists、Delete
+	conds  []methodCond
+	or     bool
+	orders []methodOrder
+}
+
+type methodCond struct {
+	field string
+	op    string // 与谓词函数的后缀相同，例如 EQ、GT、In
+}
+
+type methodOrder struct {
+	field string
+	desc  bool
+}
+
+// operators 字段名后可以跟的操作符和需要的参数个数，较长的操作符在前
+var operators = []struct {
+	name  string
+	op    string
+	arity int
+}{
+	{"NotBetween", "NotBetween", 2},
+	{"NotNull", "NotNull", 0},
+	{"NotLike", "NotLike", 1},
+	{"Between", "Between", 2},
+	{"IsNull", "IsNull", 0},
+	{"NotIn", "NotIn", 1},
+	{"Like", "Like", 1},
+	{"Not", "NEQ", 1},
+	{"GTE", "GTE", 1},
+	{"LTE", "LTE", 1},
+	{"GT", "GT", 1},
+	{"LT", "LT", 1},
+	{"In", "In", 1},
+}
+
+var actions = []struct {
+	prefix string
+	action string
+}{
+	{"FindBy", "Find"},
+	{"ListBy", "List"},
+	{"CountBy", "Count"},
+	{"ExistsBy", "Exists"},
+	{"DeleteBy", "Delete"},
+}
+
+// parseMethodName 解析 <动作>By<字段><操作符>[And|Or<字段><操作符>...][OrderBy<字段>[Asc|Desc]...]
+func parseMethodName(name string, fields []string) (*methodQuery, error) {
+	q := &methodQuery{}
+	rest := ""
+	for _, a := range actions {
+		if strings.HasPrefix(name, a.prefix) {
+			q.action, rest = a.action, name[len(a.prefix):]
+			break
+		}
+	}
+	if q.action == "" {
+		return nil, fmt.Errorf("method %s: name must start with FindBy, ListBy, CountBy, ExistsBy or DeleteBy", name)
+	}
+
+	matchField := func() (string, bool) {
+		for _, f := range fields {
+			if strings.HasPrefix(rest, f) {
+				rest = rest[len(f):]
+				return f, true
+			}
+		}
+		return "", false
+	}
+
+	connector := ""
+	for {
+		field, ok := matchField()
+		if !ok {
+			return nil, fmt.Errorf("method %s: unknown field at %q", name, rest)
+		}
+		cond := methodCond{field: field, op: "EQ"}
+		for _, op := range operators {
+			// 操作符之后只能是连接词、OrderBy 或者结束
+			if after, found := strings.CutPrefix(rest, op.name); found && (after == "" || startsWithUpper(after)) {
+				cond.op, rest = op.op, after
+				break
+			}
+		}
+		q.conds = append(q.conds, cond)
+
+		if rest == "" || strings.HasPrefix(rest, "OrderBy") {
+			break
+		}
+		next := ""
+		switch {
+		case strings.HasPrefix(rest, "And"):
+			next = "And"
+		case strings.HasPrefix(rest, "Or"):
+			next = "Or"
+		default:
+			return nil, fmt.Errorf("method %s: expect And, Or or OrderBy at %q", name, rest)
+		}
+		if connector != "" && connector != next {
+			return nil, fmt.Errorf("method %s: cannot mix And and Or", name)
+		}
+		connector = next
+		rest = rest[len(next):]
+	}
+	q.or = connector == "Or"
+
+	if rest == "" {
+		return q, nil
+	}
+	if q.action != "Find" && q.action != "List" {
+		return nil, fmt.Errorf("method %s: OrderBy is only supported by FindBy and ListBy", name)
+	}
+	rest = strings.TrimPrefix(rest, "OrderBy")
+	for rest != "" {
+		field, ok := matchField()
+		if !ok {
+			return nil, fmt.Errorf("method %s: unknown order field at %q", name, rest)
+		}
+		order := methodOrder{field: field}
+		if after, found := strings.CutPrefix(rest, "Desc"); found {
+			order.desc, rest = true, after
+		} else {
+			rest = strings.TrimPrefix(rest, "Asc")
+		}
+		q.orders = append(q.orders, order)
+	}
+	return q, nil
+}
+
+func startsWithUpper(s string) bool {
+	return s[0] >= 'A' && s[0] <= 'Z'
+}
+
+// buildMethod 根据接口中的方法声明和解析出的查询生成方法
+func buildMethod(model, name string, q *methodQuery, ft *ast.FuncType) (RepoMethod, error) {
+	method := RepoMethod{Name: name}
+
+	type param struct {
+		name string
+		typ  string
+	}
+	var params []param
+	for i, f := range ft.Params.List {
+		typ := exprString(f.Type)
+		if len(f.Names) == 0 {
+			params = append(params, param{name: fmt.Sprintf("p%d", i), typ: typ})
+			continue
+		}
+		for _, n := range f.Names {
+			params = append(params, param{name: n.Name, typ: typ})
+		}
+	}
+	if len(params) == 0 || params[0].typ != "context.Context" {
+		return method, fmt.Errorf("method %s: first parameter must be context.Context", name)
+	}
+	if ft.Results == nil || ft.Results.NumFields() != 2 {
+		return method, fmt.Errorf("method %s: must return a value and an error", name)
+	}
+
+	args := params[1:]
+	want := 0
+	for _, c := range q.conds {
+		want += arity(c.op)
+	}
+	if len(args) != want {
+		return method, fmt.Errorf("method %s: need %d parameters after ctx, got %d", name, want, len(args))
+	}
+
+	paramStrs := make([]string, 0, len(params))
+	for _, p := range params {
+		paramStrs = append(paramStrs, p.name+" "+p.typ)
+	}
+	method.Params = strings.Join(paramStrs, ", ")
+	results := make([]string, 0, 2)
+	for _, f := range ft.Results.List {
+		results = append(results, exprString(f.Type))
+	}
+	method.Results = "(" + strings.Join(results, ", ") + ")"
+
+	// 条件
+	preds := make([]string, 0, len(q.conds))
+	for _, c := range q.conds {
+		fn := model + c.field + c.op
+		var call string
+		switch arity(c.op) {
+		case 0:
+			call = fn + "()"
+		case 2:
+			call = fn + "(" + args[0].name + ", " + args[1].name + ")"
+		default:
+			if c.op == "In" || c.op == "NotIn" {
+				call = fn + "(" + args[0].name + "...)"
+			} else {
+				call = fn + "(" + args[0].name + ")"
+			}
+		}
+		args = args[arity(c.op):]
+		preds = append(preds, call)
+	}
+	where := strings.Join(preds, ", ")
+	if q.or && len(preds) > 1 {
+		where = "orm.Or(" + where + ")"
+	}
+
+	var body strings.Builder
+	switch q.action {
+	case "Delete":
+		fmt.Fprintf(&body, "return orm.RegisterDeleter[%s](r.layer).Delete().\n\tWhere(%s).\n\tExec(ctx)", model, where)
+	default:
+		fmt.Fprintf(&body, "s := orm.RegisterSelector[%s](r.layer).Select().\n\tWhere(%s)", model, where)
+		if len(q.orders) > 0 {
+			orders := make([]string, 0, len(q.orders))
+			for _, o := range q.orders {
+				orders = append(orders, fmt.Sprintf("%s%sOrderBy(%t)", model, o.field, o.desc))
+			}
+			fmt.Fprintf(&body, ".\n\tOrderBy(%s)", strings.Join(orders, ", "))
+		}
+		switch q.action {
+		case "Find":
+			body.WriteString("\nreturn s.Limit(1).Get(ctx)")
+		case "List":
+			body.WriteString("\nreturn s.GetMulti(ctx)")
+		case "Count":
+			body.WriteString("\nreturn s.Count(ctx)")
+		case "Exists":
+			body.WriteString("\nn, err := s.Count(ctx)\nreturn n > 0, err")
+		}
+	}
+	method.Body = body.String()
+	return method, nil
+}
+
+func arity(op string) int {
+	for _, o := range operators {
+		if o.op == op {
+			return o.arity
+		}
+	}
+	// EQ
+	return 1
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func generateRepository(info RepoInfo, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("repository").Parse(repositoryTemplate)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, info); err != nil {
+		return err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+
+	fileName := strings.ToLower(info.Name) + "_repo.gen.go"
+	return os.WriteFile(filepath.Join(outputDir, fileName), code, 0644)
+}
@@ -128,3 +128,103 @@ func {{$.Name}}{{.Name}}OrderBy(desc bool) orm.OrderBy {
 }
 {{end}}
 `
+
+const repositoryTemplate = `// Code generated by orm repository generator. DO NOT EDIT.
+package {{.Pkg}}
+
+import (
+	"context"
+	"errors"
+	{{- range .StdImports}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+	{{- end}}
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	{{- range .Imports}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+	{{- end}}
+)
+
+// {{.Name}}Repo {{.Name}} 的仓储，layer 可以是 *orm.DB 或者 *orm.Tx
+type {{.Name}}Repo struct {
+	layer orm.Layer
+}
+{{if .Interface}}
+var _ {{.Interface}} = (*{{.Name}}Repo)(nil)
+{{end}}
+// New{{.Name}}Repo 创建 {{.Name}} 的仓储
+func New{{.Name}}Repo(layer orm.Layer) *{{.Name}}Repo {
+	return &{{.Name}}Repo{layer: layer}
+}
+
+// Create 插入一行或多行
+func (r *{{.Name}}Repo) Create(ctx context.Context, vals ...*{{.Name}}) (orm.Result, error) {
+	return orm.RegisterInserter[{{.Name}}](r.layer).Insert(nil, vals...).Exec(ctx)
+}
+
+// Find 查询满足条件的第一行，没有数据时返回 sql.ErrNoRows
+func (r *{{.Name}}Repo) Find(ctx context.Context, conds ...orm.Condition) (*{{.Name}}, error) {
+	s := orm.RegisterSelector[{{.Name}}](r.layer).Select()
+	if len(conds) > 0 {
+		s = s.Where(conds...)
+	}
+	return s.Limit(1).Get(ctx)
+}
+
+// List 查询满足条件的所有行
+func (r *{{.Name}}Repo) List(ctx context.Context, conds ...orm.Condition) ([]*{{.Name}}, error) {
+	s := orm.RegisterSelector[{{.Name}}](r.layer).Select()
+	if len(conds) > 0 {
+		s = s.Where(conds...)
+	}
+	return s.GetMulti(ctx)
+}
+
+// Count 返回满足条件的行数
+func (r *{{.Name}}Repo) Count(ctx context.Context, conds ...orm.Condition) (int64, error) {
+	s := orm.RegisterSelector[{{.Name}}](r.layer).Select()
+	if len(conds) > 0 {
+		s = s.Where(conds...)
+	}
+	return s.Count(ctx)
+}
+
+// Delete 删除满足条件的行，为了避免误删整张表，至少需要一个条件
+func (r *{{.Name}}Repo) Delete(ctx context.Context, conds ...orm.Condition) (orm.Result, error) {
+	if len(conds) == 0 {
+		return orm.Result{}, errors.New("{{.Name}}Repo.Delete requires at least one condition")
+	}
+	return orm.RegisterDeleter[{{.Name}}](r.layer).Delete().Where(conds...).Exec(ctx)
+}
+{{range .Methods}}
+// {{.Name}} 根据方法名生成
+func (r *{{$.Name}}Repo) {{.Name}}({{.Params}}) {{.Results}} {
+	{{.Body}}
+}
+{{end}}`
+
+const metaTemplate = `// Code generated by orm meta generator. DO NOT EDIT.
+package {{.Pkg}}
+
+import "github.com/fyerfyer/fyer-webframe/orm"
+
+func init() {
+	orm.RegisterModelMeta(orm.ModelMeta[{{.Name}}]{
+		Table: {{printf "%q" .Table}},
+		Fields: []orm.FieldMeta{
+			{{- range .Fields}}
+			{Name: {{printf "%q" .Name}}, Column: {{printf "%q" .Column}}, Tag: {{printf "%q" .Tag}}},
+			{{- end}}
+		},
+		Addr: func(t *{{.Name}}, i int) any {
+			switch i {
+			{{- range $i, $f := .Fields}}
+			case {{$i}}:
+				return &t.{{$f.Name}}
+			{{- end}}
+			}
+			return nil
+		},
+	})
+}
+`
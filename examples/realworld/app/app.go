@@ -0,0 +1,131 @@
+// Package app 是 fyer-webframe 的 realworld 示例应用，演示认证、CRUD、分页、
+// 查询缓存、分表和实时通知的组合用法，同一套代码可以运行在 SQLite 和 MySQL 上
+//
+// 接口：
+//
+//	POST   /api/users                注册
+//	POST   /api/users/login          登录
+//	GET    /api/user                 当前用户（需要登录）
+//	GET    /api/articles             文章列表，支持分页、过滤和排序
+//	POST   /api/articles             创建文章（需要登录）
+//	GET    /api/articles/:id         文章详情，结果会被缓存，并记录一次阅读
+//	PUT    /api/articles/:id         更新文章（需要登录，只有作者可以操作）
+//	DELETE /api/articles/:id         删除文章（需要登录，只有作者可以操作）
+//	GET    /api/articles/:id/views   文章阅读数，数据存放在按文章ID取模的分表中
+//	GET    /api/notifications        文章变更通知，通过 Server-Sent Events 推送
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+)
+
+// App 示例应用
+type App struct {
+	server  *web.HTTPServer
+	db      *orm.DB
+	views   *orm.ShardingClient
+	hub     *hub
+	dialect string
+}
+
+// New 创建示例应用，dialect 为 sqlite 或 mysql，表需要提前通过 Migrate 创建
+// opts 会传给HTTP服务器
+func New(sqlDB *sql.DB, dialect string, opts ...web.ServerOption) (*App, error) {
+	if _, ok := viewUpserts[dialect]; !ok {
+		return nil, fmt.Errorf("realworld: unsupported dialect %q", dialect)
+	}
+
+	db, err := orm.Open(sqlDB, dialect)
+	if err != nil {
+		return nil, err
+	}
+	db.SetCacheManager(newCacheManager())
+
+	// 分片会改变DB的路由行为，阅读数分表使用单独的DB对象
+	shardDB, err := orm.Open(sqlDB, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &App{
+		server:  web.NewHTTPServer(opts...),
+		db:      db,
+		views:   newViewSharding(shardDB),
+		hub:     newHub(),
+		dialect: dialect,
+	}
+	a.routes()
+	return a, nil
+}
+
+// newCacheManager 创建文章查询的缓存
+// 默认的缓存键只包含SQL，按ID查询时需要把参数也放进缓存键
+func newCacheManager() *orm.CacheManager {
+	cm := orm.NewCacheManager(orm.NewMemoryCache())
+	cm.SetModelCacheConfig("article", &orm.ModelCacheConfig{
+		Enabled: true,
+		TTL:     time.Minute,
+		Tags:    []string{"article"},
+		KeyGenerator: func(operation string, query *orm.Query) string {
+			return fmt.Sprintf("article:%s:%s:%v", operation, query.SQL, query.Args)
+		},
+	})
+	return cm
+}
+
+// routes 注册路由，带 auth 标签的路由需要登录
+func (a *App) routes() {
+	s := a.server
+	s.Middleware().ForTag(authTag).Add(a.authenticate)
+
+	s.Post("/api/users", web.HandleError(a.register))
+	s.Post("/api/users/login", web.HandleError(a.login))
+	s.Get("/api/user", web.HandleError(a.me)).Tags(authTag)
+
+	s.Get("/api/articles", web.HandleError(a.listArticles))
+	s.Post("/api/articles", web.HandleError(a.createArticle)).Tags(authTag)
+	s.Get("/api/articles/:id", web.HandleError(a.getArticle))
+	s.Put("/api/articles/:id", web.HandleError(a.updateArticle)).Tags(authTag)
+	s.Delete("/api/articles/:id", web.HandleError(a.deleteArticle)).Tags(authTag)
+	s.Get("/api/articles/:id/views", web.HandleError(a.articleViews))
+
+	s.Get("/api/notifications", a.notifications)
+}
+
+// Server 返回应用的HTTP服务器
+func (a *App) Server() *web.HTTPServer {
+	return a.server
+}
+
+// ServeHTTP 实现 http.Handler
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.server.ServeHTTP(w, r)
+}
+
+// notifications 通过 Server-Sent Events 推送文章变更通知，直到客户端断开连接
+// 框架目前没有内置 WebSocket，单向推送的场景使用 SSE 即可满足
+func (a *App) notifications(ctx *web.Context) {
+	events, cancel := a.hub.subscribe()
+	defer cancel()
+
+	// 先发送一个事件，让客户端确认订阅已经建立
+	if err := ctx.StreamEvent("ready", "ok"); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Context.Done():
+			return
+		case n := <-events:
+			if err := ctx.StreamEvent(n.Type, n); err != nil {
+				return
+			}
+		}
+	}
+}
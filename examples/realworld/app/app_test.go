@@ -0,0 +1,356 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend 集成测试使用的数据库
+type backend struct {
+	driver string
+	dsn    string
+}
+
+// backends 返回要测试的数据库，SQLite 总是会测试，
+// 设置 REALWORLD_MYSQL_DSN 后同时测试 MySQL（DSN 需要带 parseTime=true），
+// 可以配合 fyer dev 启动临时的 MySQL 容器
+func backends(t *testing.T) []backend {
+	bs := []backend{{
+		driver: "sqlite",
+		dsn:    filepath.Join(t.TempDir(), "realworld.db"),
+	}}
+	if dsn := os.Getenv("REALWORLD_MYSQL_DSN"); dsn != "" {
+		bs = append(bs, backend{driver: "mysql", dsn: dsn})
+	}
+	return bs
+}
+
+func TestRealworld(t *testing.T) {
+	for _, b := range backends(t) {
+		t.Run(b.driver, func(t *testing.T) {
+			h := newHarness(t, b)
+			t.Run("auth", h.testAuth)
+			t.Run("articles", h.testArticles)
+			t.Run("pagination", h.testPagination)
+			t.Run("cache", h.testCache)
+			t.Run("sharded views", h.testViews)
+			t.Run("notifications", h.testNotifications)
+		})
+	}
+	if os.Getenv("REALWORLD_MYSQL_DSN") == "" {
+		t.Log("REALWORLD_MYSQL_DSN is not set, MySQL backend skipped")
+	}
+}
+
+// harness 一个数据库上的测试环境
+type harness struct {
+	db     *sql.DB
+	app    *App
+	server *httptest.Server
+}
+
+func newHarness(t *testing.T, b backend) *harness {
+	db, err := OpenDB(b.driver, b.dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, Migrate(ctx, db, b.driver))
+	// 共享的 MySQL 库中可能有上次运行留下的数据
+	tables := []string{"user", "article"}
+	for i := 0; i < viewShards; i++ {
+		tables = append(tables, fmt.Sprintf("article_view_%d", i))
+	}
+	for _, table := range tables {
+		_, err = db.ExecContext(ctx, "DELETE FROM `"+table+"`")
+		require.NoError(t, err)
+	}
+
+	a, err := New(db, b.driver, web.WithLogger(logger.NewLogger(logger.WithOutput(io.Discard))))
+	require.NoError(t, err)
+	server := httptest.NewServer(a)
+	t.Cleanup(server.Close)
+
+	return &harness{db: db, app: a, server: server}
+}
+
+// do 发送请求，body 不为空时编码为JSON，out 不为空时解析响应体
+func (h *harness) do(t *testing.T, method, path, token string, body, out any) *http.Response {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, h.server.URL+path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	resp, err := h.server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+// signup 注册用户并返回令牌
+func (h *harness) signup(t *testing.T, username string) string {
+	t.Helper()
+	var res authResponse
+	resp := h.do(t, http.MethodPost, "/api/users", "", credentials{Username: username, Password: "password123"}, &res)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.NotEmpty(t, res.Token)
+	return res.Token
+}
+
+// post 创建文章
+func (h *harness) post(t *testing.T, token, title string) *Article {
+	t.Helper()
+	body := "body of " + title
+	var article Article
+	resp := h.do(t, http.MethodPost, "/api/articles", token, articleInput{Title: &title, Body: &body}, &article)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	return &article
+}
+
+func (h *harness) testAuth(t *testing.T) {
+	token := h.signup(t, "alice")
+
+	resp := h.do(t, http.MethodPost, "/api/users", "", credentials{Username: "alice", Password: "password123"}, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp = h.do(t, http.MethodPost, "/api/users", "", credentials{Username: "bob", Password: "short"}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var me User
+	resp = h.do(t, http.MethodGet, "/api/user", token, nil, &me)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "alice", me.Username)
+
+	resp = h.do(t, http.MethodGet, "/api/user", "", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = h.do(t, http.MethodGet, "/api/user", "bogus", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = h.do(t, http.MethodPost, "/api/users/login", "", credentials{Username: "alice", Password: "wrong-password"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 重新登录后旧令牌失效
+	var res authResponse
+	resp = h.do(t, http.MethodPost, "/api/users/login", "", credentials{Username: "alice", Password: "password123"}, &res)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, token, res.Token)
+	resp = h.do(t, http.MethodGet, "/api/user", token, nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = h.do(t, http.MethodGet, "/api/user", res.Token, nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func (h *harness) testArticles(t *testing.T) {
+	author := h.signup(t, "author")
+	other := h.signup(t, "other")
+
+	title := "hello"
+	resp := h.do(t, http.MethodPost, "/api/articles", "", articleInput{Title: &title}, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = h.do(t, http.MethodPost, "/api/articles", author, articleInput{}, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	article := h.post(t, author, "hello")
+	assert.NotZero(t, article.ID)
+	path := fmt.Sprintf("/api/articles/%d", article.ID)
+
+	var got Article
+	resp = h.do(t, http.MethodGet, path, "", nil, &got)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", got.Title)
+	assert.Equal(t, "body of hello", got.Body)
+	assert.WithinDuration(t, article.CreatedAt, got.CreatedAt, time.Second)
+
+	updated := "hello again"
+	resp = h.do(t, http.MethodPut, path, other, articleInput{Title: &updated}, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = h.do(t, http.MethodPut, path, author, articleInput{Title: &updated}, &got)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello again", got.Title)
+	assert.Equal(t, "body of hello", got.Body)
+
+	resp = h.do(t, http.MethodDelete, path, other, nil, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = h.do(t, http.MethodDelete, path, author, nil, nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = h.do(t, http.MethodGet, path, "", nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = h.do(t, http.MethodGet, "/api/articles/abc", "", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func (h *harness) testPagination(t *testing.T) {
+	writer := h.signup(t, "writer")
+	ids := make([]int64, 0, 12)
+	for i := 0; i < 12; i++ {
+		ids = append(ids, h.post(t, writer, fmt.Sprintf("page article %02d", i)).ID)
+	}
+
+	var me User
+	h.do(t, http.MethodGet, "/api/user", writer, nil, &me)
+	query := fmt.Sprintf("/api/articles?filter[author]=%d&per_page=5", me.ID)
+
+	var page articlePage
+	resp := h.do(t, http.MethodGet, query, "", nil, &page)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(12), page.Meta.Total)
+	assert.Equal(t, 3, page.Meta.TotalPages)
+	require.Len(t, page.Data, 5)
+	// 默认按ID倒序
+	assert.Equal(t, ids[11], page.Data[0].ID)
+
+	resp = h.do(t, http.MethodGet, query+"&page=3&sort=id", "", nil, &page)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, ids[10], page.Data[0].ID)
+	assert.Equal(t, ids[11], page.Data[1].ID)
+
+	resp = h.do(t, http.MethodGet, query+"&filter[title][contains]=article%200", "", nil, &page)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(10), page.Meta.Total)
+
+	resp = h.do(t, http.MethodGet, "/api/articles?sort=body", "", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func (h *harness) testCache(t *testing.T) {
+	token := h.signup(t, "cacher")
+	article := h.post(t, token, "cached")
+	path := fmt.Sprintf("/api/articles/%d", article.ID)
+
+	var got Article
+	h.do(t, http.MethodGet, path, "", nil, &got)
+	require.Equal(t, "cached", got.Title)
+
+	// 绕过ORM直接修改数据库，缓存的结果不受影响
+	_, err := h.db.Exec("UPDATE `article` SET `title` = ? WHERE `id` = ?", "changed behind the cache", article.ID)
+	require.NoError(t, err)
+	h.do(t, http.MethodGet, path, "", nil, &got)
+	assert.Equal(t, "cached", got.Title)
+
+	// 不同ID的查询不会命中同一个缓存
+	other := h.post(t, token, "another")
+	h.do(t, http.MethodGet, fmt.Sprintf("/api/articles/%d", other.ID), "", nil, &got)
+	assert.Equal(t, "another", got.Title)
+
+	// 通过接口更新会使缓存失效
+	title := "updated"
+	h.do(t, http.MethodPut, path, token, articleInput{Title: &title}, nil)
+	h.do(t, http.MethodGet, path, "", nil, &got)
+	assert.Equal(t, "updated", got.Title)
+}
+
+func (h *harness) testViews(t *testing.T) {
+	token := h.signup(t, "reader")
+	articles := make([]*Article, 0, viewShards)
+	for i := 0; i < viewShards; i++ {
+		articles = append(articles, h.post(t, token, fmt.Sprintf("viewed %d", i)))
+	}
+
+	for i, article := range articles {
+		for n := 0; n <= i; n++ {
+			resp := h.do(t, http.MethodGet, fmt.Sprintf("/api/articles/%d", article.ID), "", nil, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	for i, article := range articles {
+		var res map[string]int64
+		resp := h.do(t, http.MethodGet, fmt.Sprintf("/api/articles/%d/views", article.ID), "", nil, &res)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(i+1), res["views"])
+
+		// 阅读数存放在按文章ID取模的分表中
+		table := fmt.Sprintf("article_view_%d", article.ID%viewShards)
+		var views int64
+		err := h.db.QueryRow("SELECT `views` FROM `"+table+"` WHERE `article_id` = ?", article.ID).Scan(&views)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), views)
+	}
+}
+
+func (h *harness) testNotifications(t *testing.T) {
+	token := h.signup(t, "notifier")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.server.URL+"/api/notifications", nil)
+	require.NoError(t, err)
+	resp, err := h.server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"))
+
+	events := make(chan [2]string, 8)
+	go func() {
+		defer close(events)
+		var event string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				events <- [2]string{event, strings.TrimPrefix(line, "data: ")}
+			}
+		}
+	}()
+
+	next := func() [2]string {
+		select {
+		case e, ok := <-events:
+			require.True(t, ok, "event stream closed")
+			return e
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for notification")
+			return [2]string{}
+		}
+	}
+
+	assert.Equal(t, "ready", next()[0])
+
+	article := h.post(t, token, "breaking news")
+	e := next()
+	assert.Equal(t, "article.created", e[0])
+	var n Notification
+	require.NoError(t, json.Unmarshal([]byte(e[1]), &n))
+	assert.Equal(t, article.ID, n.Article.ID)
+	assert.Equal(t, "breaking news", n.Article.Title)
+
+	h.do(t, http.MethodDelete, fmt.Sprintf("/api/articles/%d", article.ID), token, nil, nil)
+	assert.Equal(t, "article.deleted", next()[0])
+}
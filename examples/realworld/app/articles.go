@@ -0,0 +1,201 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/listing"
+)
+
+// articleListing 文章列表的查询协议
+var articleListing = listing.New(
+	listing.WithPerPage(10, 50),
+	listing.WithSort("id", "ID"),
+	listing.WithSort("created_at", "CreatedAt"),
+	listing.WithDefaultSort("-id"),
+	listing.WithFilter("author", "AuthorID"),
+	listing.WithFilter("title", "Title", listing.OpEq, listing.OpContains),
+)
+
+// articleInput 创建和更新文章的请求体，更新时为空的字段保持不变
+type articleInput struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
+
+// articlePage 文章列表的响应体
+type articlePage struct {
+	Data []*Article   `json:"data"`
+	Meta listing.Meta `json:"meta"`
+}
+
+// listArticles 分页查询文章
+func (a *App) listArticles(ctx *web.Context) error {
+	spec, err := articleListing.Parse(ctx)
+	if err != nil {
+		return err
+	}
+
+	articles, err := listing.Apply(orm.RegisterSelector[Article](a.db).Select(), spec).GetMulti(ctx.Context)
+	if err != nil {
+		return err
+	}
+	total, err := orm.New(a.db).Count(ctx.Context, &Article{}, spec.Conditions()...)
+	if err != nil {
+		return err
+	}
+
+	if articles == nil {
+		articles = []*Article{}
+	}
+	return ctx.JSON(http.StatusOK, articlePage{Data: articles, Meta: spec.Meta(total)})
+}
+
+// createArticle 创建文章并通知订阅者
+func (a *App) createArticle(ctx *web.Context) error {
+	var req articleInput
+	if err := ctx.BindJSON(&req); err != nil {
+		return web.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Title == nil || *req.Title == "" || req.Body == nil {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, "title and body are required")
+	}
+
+	now := time.Now().UTC()
+	article := &Article{
+		AuthorID:  currentUser(ctx).ID,
+		Title:     *req.Title,
+		Body:      *req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	res, err := orm.RegisterInserter[Article](a.db).
+		Insert([]string{"AuthorID", "Title", "Body", "CreatedAt", "UpdatedAt"}, article).
+		Exec(ctx.Context)
+	if err != nil {
+		return err
+	}
+	if article.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	a.hub.publish(Notification{Type: "article.created", Article: article})
+	return ctx.Created(fmt.Sprintf("/api/articles/%d", article.ID), article)
+}
+
+// getArticle 查询文章并记录一次阅读
+func (a *App) getArticle(ctx *web.Context) error {
+	article, err := a.findArticle(ctx)
+	if err != nil {
+		return err
+	}
+	if err = a.recordView(ctx.Context, article.ID); err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, article)
+}
+
+// updateArticle 更新文章，只有作者可以更新
+func (a *App) updateArticle(ctx *web.Context) error {
+	article, err := a.findOwnArticle(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req articleInput
+	if err = ctx.BindJSON(&req); err != nil {
+		return web.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Title != nil && *req.Title == "" {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, "title must not be empty")
+	}
+
+	if req.Title != nil {
+		article.Title = *req.Title
+	}
+	if req.Body != nil {
+		article.Body = *req.Body
+	}
+	article.UpdatedAt = time.Now().UTC()
+
+	// 更新后使文章的查询缓存失效，下次查询会读到新数据
+	_, err = orm.RegisterUpdater[Article](a.db).Update().
+		Set(orm.Col("Title"), article.Title).
+		Set(orm.Col("Body"), article.Body).
+		Set(orm.Col("UpdatedAt"), article.UpdatedAt).
+		Where(orm.Col("ID").Eq(article.ID)).
+		WithInvalidateCache().
+		Exec(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	a.hub.publish(Notification{Type: "article.updated", Article: article})
+	return ctx.JSON(http.StatusOK, article)
+}
+
+// deleteArticle 删除文章，只有作者可以删除
+func (a *App) deleteArticle(ctx *web.Context) error {
+	article, err := a.findOwnArticle(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = orm.RegisterDeleter[Article](a.db).Delete().
+		Where(orm.Col("ID").Eq(article.ID)).
+		WithInvalidateCache().
+		Exec(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	a.hub.publish(Notification{Type: "article.deleted", Article: article})
+	return ctx.NoContent()
+}
+
+// articleViews 返回文章的阅读数
+func (a *App) articleViews(ctx *web.Context) error {
+	article, err := a.findArticle(ctx)
+	if err != nil {
+		return err
+	}
+	views, err := a.countViews(ctx.Context, article.ID)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, map[string]int64{"article_id": article.ID, "views": views})
+}
+
+// findArticle 根据路径参数查询文章，结果会被缓存
+func (a *App) findArticle(ctx *web.Context) (*Article, error) {
+	id := ctx.PathInt64("id")
+	if id.Error != nil {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+
+	article, err := orm.RegisterSelector[Article](a.db).Select().
+		Where(orm.Col("ID").Eq(id.Value)).
+		WithCache().
+		Get(ctx.Context)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "article not found")
+	}
+	return article, err
+}
+
+// findOwnArticle 查询当前用户自己的文章
+func (a *App) findOwnArticle(ctx *web.Context) (*Article, error) {
+	article, err := a.findArticle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if article.AuthorID != currentUser(ctx).ID {
+		return nil, web.NewHTTPError(http.StatusForbidden, "not the author of this article")
+	}
+	return article, nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+)
+
+// authTag 需要登录的路由标签
+const authTag = "auth"
+
+// credentials 注册和登录的请求体
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// authResponse 注册和登录的响应体
+type authResponse struct {
+	User  *User  `json:"user"`
+	Token string `json:"token"`
+}
+
+// register 注册用户
+func (a *App) register(ctx *web.Context) error {
+	var req credentials
+	if err := ctx.BindJSON(&req); err != nil {
+		return web.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Username == "" || len(req.Password) < 8 {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, "username is required and password must be at least 8 characters")
+	}
+
+	_, err := orm.RegisterSelector[User](a.db).Select().
+		Where(orm.Col("Username").Eq(req.Username)).
+		Get(ctx.Context)
+	if err == nil {
+		return web.NewHTTPError(http.StatusConflict, "username already taken")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	user := &User{
+		Username:     req.Username,
+		PasswordHash: hashPassword(req.Password),
+		Token:        newToken(),
+		CreatedAt:    time.Now().UTC(),
+	}
+	res, err := orm.RegisterInserter[User](a.db).
+		Insert([]string{"Username", "PasswordHash", "Token", "CreatedAt"}, user).
+		Exec(ctx.Context)
+	if err != nil {
+		return err
+	}
+	if user.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	return ctx.Created("/api/user", authResponse{User: user, Token: user.Token})
+}
+
+// login 登录，每次登录都会签发新的令牌
+func (a *App) login(ctx *web.Context) error {
+	var req credentials
+	if err := ctx.BindJSON(&req); err != nil {
+		return web.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	user, err := orm.RegisterSelector[User](a.db).Select().
+		Where(orm.Col("Username").Eq(req.Username)).
+		Get(ctx.Context)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !checkPassword(user.PasswordHash, req.Password)) {
+		return web.NewHTTPError(http.StatusUnauthorized, "invalid username or password")
+	}
+	if err != nil {
+		return err
+	}
+
+	user.Token = newToken()
+	_, err = orm.RegisterUpdater[User](a.db).Update().
+		Set(orm.Col("Token"), user.Token).
+		Where(orm.Col("ID").Eq(user.ID)).
+		Exec(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, authResponse{User: user, Token: user.Token})
+}
+
+// me 返回当前登录的用户
+func (a *App) me(ctx *web.Context) error {
+	return ctx.JSON(http.StatusOK, currentUser(ctx))
+}
+
+// authenticate 认证中间件，从 Authorization: Token <token> 请求头中解析当前用户
+func (a *App) authenticate(next web.HandlerFunc) web.HandlerFunc {
+	return func(ctx *web.Context) {
+		token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Token ")
+		if !ok || token == "" {
+			ctx.Error(web.NewHTTPError(http.StatusUnauthorized, "missing token"))
+			return
+		}
+
+		user, err := orm.RegisterSelector[User](a.db).Select().
+			Where(orm.Col("Token").Eq(token)).
+			Get(ctx.Context)
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(web.NewHTTPError(http.StatusUnauthorized, "invalid token"))
+			return
+		}
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		ctx.SetCurrentUser(user)
+		next(ctx)
+	}
+}
+
+// currentUser 返回认证中间件设置的当前用户
+func currentUser(ctx *web.Context) *User {
+	user, _ := ctx.CurrentUser().(*User)
+	return user
+}
+
+// hashPassword 生成加盐的密码摘要，格式为 salt$hash
+// 示例只使用标准库，生产环境应使用 bcrypt 或 argon2 等慢哈希算法
+func hashPassword(password string) string {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	return hex.EncodeToString(salt) + "$" + digest(salt, password)
+}
+
+// checkPassword 校验密码
+func checkPassword(stored, password string) bool {
+	saltHex, hash, ok := strings.Cut(stored, "$")
+	if !ok {
+		return false
+	}
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(digest(salt, password))) == 1
+}
+
+func digest(salt []byte, password string) string {
+	sum := sha256.Sum256(append(salt, password...))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken 生成随机令牌
+func newToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+// OpenDB 打开数据库连接，driver 同时作为ORM的方言名
+// SQLite 同一时间只允许一个写连接，连接池限制为一个连接以避免 SQLITE_BUSY
+func OpenDB(driver, dsn string) (*sql.DB, error) {
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch driver {
+	case "sqlite":
+		db, err = sql.Open("sqlite", dsn)
+		if err == nil {
+			db.SetMaxOpenConns(1)
+		}
+	case "mysql":
+		db, err = sql.Open("mysql", dsn)
+	default:
+		return nil, fmt.Errorf("realworld: unsupported driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
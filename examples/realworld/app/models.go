@@ -0,0 +1,22 @@
+package app
+
+import "time"
+
+// User 用户
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Token        string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Article 文章
+type Article struct {
+	ID        int64     `json:"id"`
+	AuthorID  int64     `json:"author_id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package app
+
+import "sync"
+
+// Notification 推送给订阅者的通知
+type Notification struct {
+	Type    string   `json:"type"`
+	Article *Article `json:"article"`
+}
+
+// hub 通知的发布订阅中心，每个订阅者持有一个带缓冲的通道
+// 订阅者处理不过来时丢弃通知，避免慢连接阻塞写请求
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[chan Notification]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[chan Notification]struct{})}
+}
+
+// subscribe 注册订阅者，返回接收通知的通道和取消订阅的函数
+func (h *hub) subscribe() (<-chan Notification, func()) {
+	ch := make(chan Notification, 16)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish 向所有订阅者发送通知
+func (h *hub) publish(n Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// viewShards 文章阅读数分表的数量
+const viewShards = 4
+
+// schemas 各方言的建表语句，表名和列名与ORM模型的默认映射一致
+var schemas = map[string]func() []string{
+	"mysql": func() []string {
+		stmts := []string{
+			"CREATE TABLE IF NOT EXISTS `user` (" +
+				"`id` BIGINT AUTO_INCREMENT PRIMARY KEY, " +
+				"`username` VARCHAR(64) NOT NULL UNIQUE, " +
+				"`password_hash` VARCHAR(128) NOT NULL, " +
+				"`token` VARCHAR(64) NOT NULL, " +
+				"`created_at` DATETIME(6) NOT NULL, " +
+				"KEY `idx_user_token` (`token`))",
+			"CREATE TABLE IF NOT EXISTS `article` (" +
+				"`id` BIGINT AUTO_INCREMENT PRIMARY KEY, " +
+				"`author_id` BIGINT NOT NULL, " +
+				"`title` VARCHAR(255) NOT NULL, " +
+				"`body` TEXT NOT NULL, " +
+				"`created_at` DATETIME(6) NOT NULL, " +
+				"`updated_at` DATETIME(6) NOT NULL, " +
+				"KEY `idx_article_author_id` (`author_id`))",
+		}
+		for i := 0; i < viewShards; i++ {
+			stmts = append(stmts, fmt.Sprintf("CREATE TABLE IF NOT EXISTS `article_view_%d` ("+
+				"`article_id` BIGINT PRIMARY KEY, "+
+				"`views` BIGINT NOT NULL DEFAULT 0)", i))
+		}
+		return stmts
+	},
+	"sqlite": func() []string {
+		stmts := []string{
+			"CREATE TABLE IF NOT EXISTS `user` (" +
+				"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+				"`username` TEXT NOT NULL UNIQUE, " +
+				"`password_hash` TEXT NOT NULL, " +
+				"`token` TEXT NOT NULL, " +
+				"`created_at` DATETIME NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS `idx_user_token` ON `user` (`token`)",
+			"CREATE TABLE IF NOT EXISTS `article` (" +
+				"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+				"`author_id` INTEGER NOT NULL, " +
+				"`title` TEXT NOT NULL, " +
+				"`body` TEXT NOT NULL, " +
+				"`created_at` DATETIME NOT NULL, " +
+				"`updated_at` DATETIME NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS `idx_article_author_id` ON `article` (`author_id`)",
+		}
+		for i := 0; i < viewShards; i++ {
+			stmts = append(stmts, fmt.Sprintf("CREATE TABLE IF NOT EXISTS `article_view_%d` ("+
+				"`article_id` INTEGER PRIMARY KEY, "+
+				"`views` INTEGER NOT NULL DEFAULT 0)", i))
+		}
+		return stmts
+	},
+}
+
+// Migrate 创建示例应用需要的表，已存在的表会被跳过
+func Migrate(ctx context.Context, db *sql.DB, dialect string) error {
+	schema, ok := schemas[dialect]
+	if !ok {
+		return fmt.Errorf("realworld: unsupported dialect %q", dialect)
+	}
+	for _, stmt := range schema() {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("realworld: migrate: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+)
+
+const (
+	// viewModel 阅读数分表在分片管理器中注册的模型名
+	viewModel = "article_view"
+	// viewShardDB 阅读数分表所在的分片库，示例中只有一个库
+	viewShardDB = "realworld_0"
+)
+
+// viewUpserts 各方言累加阅读数的语句
+var viewUpserts = map[string]string{
+	"mysql":  "INSERT INTO `%s` (`article_id`, `views`) VALUES (?, 1) ON DUPLICATE KEY UPDATE `views` = `views` + 1",
+	"sqlite": "INSERT INTO `%s` (`article_id`, `views`) VALUES (?, 1) ON CONFLICT (`article_id`) DO UPDATE SET `views` = `views` + 1",
+}
+
+// newViewSharding 创建阅读数分表的分片客户端
+// 阅读数按文章ID取模分布在 article_view_0 到 article_view_3 四张表中
+func newViewSharding(db *orm.DB) *orm.ShardingClient {
+	client := orm.NewShardingClient(db)
+	client.RegisterShardStrategy(viewModel,
+		orm.WithModStrategy("realworld_", 1, "article_view_", viewShards, "article_id"), viewShardDB)
+	db.GetShardingManager().RegisterShard(viewShardDB, db)
+	return client
+}
+
+// routeView 返回文章阅读数所在的分片和表名
+func (a *App) routeView(ctx context.Context, articleID int64) (*orm.Client, string, error) {
+	db, table, err := a.views.RouteWithKey(ctx, viewModel, "article_id", articleID)
+	if err != nil {
+		return nil, "", err
+	}
+	if table == "" {
+		return nil, "", fmt.Errorf("realworld: no shard table for article %d", articleID)
+	}
+	return orm.New(db), table, nil
+}
+
+// recordView 累加文章的阅读数
+func (a *App) recordView(ctx context.Context, articleID int64) error {
+	client, table, err := a.routeView(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	_, err = client.Exec(ctx, fmt.Sprintf(viewUpserts[a.dialect], table), articleID)
+	return err
+}
+
+// countViews 查询文章的阅读数
+func (a *App) countViews(ctx context.Context, articleID int64) (int64, error) {
+	client, table, err := a.routeView(ctx, articleID)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := client.Raw(ctx, fmt.Sprintf("SELECT `views` FROM `%s` WHERE `article_id` = ?", table), articleID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	var views int64
+	if err = rows.Scan(&views); err != nil {
+		return 0, err
+	}
+	return views, nil
+}
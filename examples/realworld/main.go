@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/examples/realworld/app"
+)
+
+var (
+	// 命令行参数
+	driver = flag.String("driver", "sqlite", "Database driver: sqlite or mysql")
+	dsn    = flag.String("dsn", "", "Database DSN, defaults to realworld.db for sqlite and $MYSQL_DSN for mysql")
+	addr   = flag.String("addr", ":8080", "Address to listen on")
+)
+
+func main() {
+	flag.Parse()
+
+	if *dsn == "" {
+		switch *driver {
+		case "sqlite":
+			*dsn = "realworld.db"
+		case "mysql":
+			*dsn = os.Getenv("MYSQL_DSN")
+		}
+	}
+
+	db, err := app.OpenDB(*driver, *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = app.Migrate(context.Background(), db, *driver); err != nil {
+		log.Fatal(err)
+	}
+
+	a, err := app.New(db, *driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+		<-ch
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = a.Server().Shutdown(ctx)
+	}()
+
+	log.Printf("realworld example listening on %s (%s)", *addr, *driver)
+	if err = a.Server().Start(*addr); err != nil {
+		log.Println(err)
+	}
+}
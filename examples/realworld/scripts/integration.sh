@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+# 运行 realworld 示例的集成测试
+# 始终测试 SQLite；未设置 REALWORLD_MYSQL_DSN 且本机有 docker 时，启动一个临时的 MySQL 容器一起测试
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+container=""
+cleanup() {
+	if [[ -n "$container" ]]; then
+		docker rm -f -v "$container" >/dev/null
+	fi
+}
+trap cleanup EXIT
+
+if [[ -z "${REALWORLD_MYSQL_DSN:-}" ]] && command -v docker >/dev/null; then
+	container=$(docker run -d --rm -p 127.0.0.1::3306 \
+		-e MYSQL_ROOT_PASSWORD=realworld -e MYSQL_DATABASE=realworld \
+		--label fyer-webframe.realworld=true "${MYSQL_IMAGE:-mysql:8.0}")
+	port=$(docker port "$container" 3306/tcp | head -n1 | awk -F: '{print $NF}')
+	export REALWORLD_MYSQL_DSN="root:realworld@tcp(127.0.0.1:${port})/realworld?parseTime=true&loc=UTC"
+
+	echo "waiting for mysql on port ${port}..."
+	for _ in $(seq 1 90); do
+		if docker exec "$container" mysqladmin ping -h 127.0.0.1 -prealworld --silent >/dev/null 2>&1; then
+			break
+		fi
+		sleep 1
+	done
+fi
+
+go test -race -count=1 ./... "$@"
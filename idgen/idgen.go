@@ -0,0 +1,59 @@
+// Package idgen 提供框架使用的 ID 生成器，会话 ID 和请求 ID 都通过 IDGenerator 生成，
+// 测试中可以替换为 Sequence，得到可预测的 ID：
+//
+//	gen := idgen.Sequence("req")
+//	gen.NewID() // req-1
+//	gen.NewID() // req-2
+package idgen
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator ID 生成器，需要可以在多个协程中使用
+type IDGenerator interface {
+	// NewID 返回新的唯一 ID
+	NewID() string
+}
+
+// Func 将函数转换为 IDGenerator
+type Func func() string
+
+// NewID 实现 IDGenerator 接口
+func (f Func) NewID() string {
+	return f()
+}
+
+// UUID 返回生成随机 UUID（版本4）的 IDGenerator
+func UUID() IDGenerator {
+	return Func(uuid.NewString)
+}
+
+// OrUUID 在 g 为 nil 时返回 UUID()，便于组件在未设置 IDGenerator 时使用默认值
+func OrUUID(g IDGenerator) IDGenerator {
+	if g == nil {
+		return UUID()
+	}
+	return g
+}
+
+// Sequence 返回生成 prefix-1、prefix-2 等递增 ID 的 IDGenerator，prefix 为空时只有数字
+func Sequence(prefix string) IDGenerator {
+	return &sequence{prefix: prefix}
+}
+
+type sequence struct {
+	prefix string
+	n      atomic.Uint64
+}
+
+func (s *sequence) NewID() string {
+	id := strconv.FormatUint(s.n.Add(1), 10)
+	if s.prefix == "" {
+		return id
+	}
+	return s.prefix + "-" + id
+}
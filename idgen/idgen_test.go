@@ -0,0 +1,49 @@
+package idgen
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequence(t *testing.T) {
+	gen := Sequence("sess")
+	assert.Equal(t, "sess-1", gen.NewID())
+	assert.Equal(t, "sess-2", gen.NewID())
+
+	plain := Sequence("")
+	assert.Equal(t, "1", plain.NewID())
+
+	concurrent := Sequence("")
+	var mu sync.Mutex
+	seen := make(map[string]struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := concurrent.NewID()
+			mu.Lock()
+			seen[id] = struct{}{}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 100)
+}
+
+func TestUUID(t *testing.T) {
+	a, b := UUID().NewID(), OrUUID(nil).NewID()
+	assert.NotEqual(t, a, b)
+	for _, id := range []string{a, b} {
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(4), parsed.Version())
+	}
+
+	fixed := Func(func() string { return "fixed" })
+	assert.Equal(t, "fixed", OrUUID(fixed).NewID())
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/fyerfyer/fyer-webframe/logexample/handlers"
@@ -25,10 +29,23 @@ func main() {
 	// 启动服务器
 	addr := ":8080"
 	fmt.Printf("Server starting on %s\n", addr)
-	err := server.Start(addr)
-	if err != nil {
-		fmt.Printf("Server failed to start: %v\n", err)
-		os.Exit(1)
+	go func() {
+		err := server.Start(addr)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Server failed to start: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	// 收到退出信号后优雅关闭，Shutdown 最后会写入异步输出中缓冲的日志
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		fmt.Printf("Server shutdown failed: %v\n", err)
 	}
 }
 
@@ -39,11 +56,11 @@ func setupLogger() {
 		logger.WithOutput(os.Stdout),         // 输出到标准输出
 	)
 
-	// 创建文件日志（同时保留控制台输出）
-	logFile, err := os.OpenFile("example.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	// 创建文件日志（同时保留控制台输出），单个文件最大10MB，保留5个备份，备份最多保留7天
+	logFile, err := logger.NewRotatingWriter("logs/example.log", 10<<20, 5, 7*24*time.Hour)
 	if err == nil {
-		// 创建多输出日志，同时写入控制台和文件
-		multiWriter := io.MultiWriter(os.Stdout, logFile)
+		// 创建多输出日志，同时写入控制台和文件，在后台协程中写入，请求处理不需要等待磁盘 I/O
+		multiWriter := logger.NewAsyncWriter(io.MultiWriter(os.Stdout, logFile))
 		fileLogger := logger.NewLogger(
 			logger.WithLevel(logger.InfoLevel),
 			logger.WithOutput(multiWriter),
@@ -0,0 +1,335 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrArrayNotSupported 方言不支持数组类型和数组条件
+var ErrArrayNotSupported = errors.New("orm: array is only supported by postgresql")
+
+// isArrayElem 判断切片的元素类型能否映射为 PostgreSQL 数组的元素
+func isArrayElem(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return typ == timeType
+}
+
+// isArrayField 判断字段是否映射为 PostgreSQL 数组：元素为基础类型或 time.Time 的切片（[]byte 除外），
+// 没有指定类型或者指定的是数组类型，如 type:text[]
+func isArrayField(sqlType string, typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 || !isArrayElem(typ.Elem()) {
+		return false
+	}
+	if sqlType != "" && !strings.HasSuffix(sqlType, "[]") {
+		return false
+	}
+	return !typ.Implements(valuerType) && !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// arrayArg 数组字段的值编码为 PostgreSQL 的数组字面量，已经编码的值不处理
+func arrayArg(f *field, val any) any {
+	if f == nil || !f.array {
+		return val
+	}
+	if reflect.ValueOf(val).Kind() != reflect.Slice {
+		return val
+	}
+	return arrayValue{val: val}
+}
+
+// arrayValue 写入 PostgreSQL 数组列的值，编码为 {a,b} 形式的一维数组字面量，nil 切片写入 NULL
+type arrayValue struct {
+	val any
+}
+
+// Value 实现 driver.Valuer
+func (a arrayValue) Value() (driver.Value, error) {
+	v := reflect.ValueOf(a.val)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("orm: cannot encode %T as array", a.val)
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				b.WriteString("NULL")
+				continue
+			}
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.String:
+			writeArrayString(&b, elem.String())
+		case reflect.Bool:
+			if elem.Bool() {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte('f')
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b.WriteString(strconv.FormatInt(elem.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.WriteString(strconv.FormatUint(elem.Uint(), 10))
+		case reflect.Float32, reflect.Float64:
+			b.WriteString(strconv.FormatFloat(elem.Float(), 'g', -1, elem.Type().Bits()))
+		default:
+			t, ok := elem.Interface().(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("orm: cannot encode %s as array element", elem.Type())
+			}
+			writeArrayString(&b, t.Format(time.RFC3339Nano))
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// writeArrayString 写入加上双引号的数组元素，转义其中的双引号和反斜杠
+func writeArrayString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+}
+
+// arrayScanner 把 PostgreSQL 返回的数组文本解析到切片字段中，NULL 时字段置为 nil
+type arrayScanner struct {
+	dst any
+}
+
+// Scan 实现 sql.Scanner
+func (a *arrayScanner) Scan(src any) error {
+	dst := reflect.ValueOf(a.dst).Elem()
+	var text string
+	switch s := src.(type) {
+	case nil:
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	case []byte:
+		text = string(s)
+	case string:
+		text = s
+	default:
+		return fmt.Errorf("orm: cannot scan %T into array column", src)
+	}
+
+	elems, err := parseArray(text)
+	if err != nil {
+		return err
+	}
+	res := reflect.MakeSlice(dst.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if err = setArrayElem(res.Index(i), elem); err != nil {
+			return err
+		}
+	}
+	dst.Set(res)
+	return nil
+}
+
+// parseArray 解析一维数组的文本表示，如 {a,"b c",NULL}，NULL 元素为 nil
+func parseArray(text string) ([]*string, error) {
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return nil, fmt.Errorf("orm: invalid array %q", text)
+	}
+	body := text[1 : len(text)-1]
+	if body == "" {
+		return []*string{}, nil
+	}
+
+	var elems []*string
+	for i := 0; i <= len(body); {
+		var (
+			b      strings.Builder
+			quoted bool
+		)
+		if i < len(body) && body[i] == '"' {
+			quoted = true
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				b.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("orm: invalid array %q", text)
+			}
+			i++
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				if body[i] == '{' {
+					return nil, fmt.Errorf("orm: multidimensional array %q is not supported", text)
+				}
+				b.WriteByte(body[i])
+			}
+		}
+		if i < len(body) && body[i] != ',' {
+			return nil, fmt.Errorf("orm: invalid array %q", text)
+		}
+		i++
+
+		s := b.String()
+		if !quoted && strings.EqualFold(s, "NULL") {
+			elems = append(elems, nil)
+			continue
+		}
+		elems = append(elems, &s)
+	}
+	return elems, nil
+}
+
+// setArrayElem 把数组元素的文本转换为切片元素的类型
+func setArrayElem(v reflect.Value, s *string) error {
+	if v.Kind() == reflect.Ptr {
+		if s == nil {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if s == nil {
+		return fmt.Errorf("orm: cannot scan NULL array element into %s", v.Type())
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(*s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(*s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(*s, 10, v.Type().Bits())
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(*s, 10, v.Type().Bits())
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(*s, v.Type().Bits())
+		v.SetFloat(f)
+	default:
+		if v.Type() != timeType {
+			return fmt.Errorf("orm: cannot scan array element into %s", v.Type())
+		}
+		var t time.Time
+		t, err = parseArrayTime(*s)
+		v.Set(reflect.ValueOf(t))
+	}
+	if err != nil {
+		return fmt.Errorf("orm: scan array element %q: %w", *s, err)
+	}
+	return nil
+}
+
+// arrayTimeLayouts PostgreSQL 输出的时间格式和写入时使用的 RFC3339 格式
+var arrayTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func parseArrayTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range arrayTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// arrayExpr PostgreSQL 的数组表达式：ANY(列)、ANY(数组参数) 或 ARRAY[...]
+type arrayExpr struct {
+	col  *Column // ANY(列)
+	arg  any     // ANY(数组参数)
+	vals []any   // ARRAY[...]
+}
+
+func (a *arrayExpr) expr() {}
+
+func (a *arrayExpr) build(builder *strings.Builder, m *model, args *[]any) {
+	if _, ok := m.dialect.(*Postgresql); !ok {
+		panic(ErrArrayNotSupported)
+	}
+	switch {
+	case a.col != nil:
+		a.col.model = m
+		builder.WriteString("ANY(")
+		a.col.Build(builder)
+		builder.WriteByte(')')
+	case a.arg != nil:
+		builder.WriteString("ANY(" + m.dialect.Placeholder(m.index) + ")")
+		m.index++
+		*args = append(*args, a.arg)
+	default:
+		builder.WriteString("ARRAY[")
+		for i, val := range a.vals {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(m.dialect.Placeholder(m.index))
+			m.index++
+			*args = append(*args, val)
+		}
+		builder.WriteByte(']')
+	}
+}
+
+// Has 数组列包含元素 val：$1 = ANY("tags")，只支持 PostgreSQL
+func (c *Column) Has(val any) *Predicate {
+	return &Predicate{left: valueOf(val), op: opEQ, right: &arrayExpr{col: c}}
+}
+
+// Contains 数组列包含 vals 中的所有元素："tags" @> ARRAY[$1, $2]，只支持 PostgreSQL
+func (c *Column) Contains(vals ...any) *Predicate {
+	return &Predicate{left: c, op: opContains, right: &arrayExpr{vals: vals}}
+}
+
+// Overlaps 数组列包含 vals 中的任意元素："tags" && ARRAY[$1, $2]，只支持 PostgreSQL
+func (c *Column) Overlaps(vals ...any) *Predicate {
+	return &Predicate{left: c, op: opOverlaps, right: &arrayExpr{vals: vals}}
+}
+
+// EqAny 列等于切片 vals 中的任意值："id" = ANY($1)，整个切片作为一个数组参数，
+// 与 In 相比语句不随元素个数变化，可以复用预编译语句，只支持 PostgreSQL
+func (c *Column) EqAny(vals any) *Predicate {
+	if reflect.ValueOf(vals).Kind() != reflect.Slice {
+		panic(fmt.Errorf("orm: EqAny requires a slice, got %T", vals))
+	}
+	return &Predicate{left: c, op: opEQ, right: &arrayExpr{arg: arrayValue{val: vals}}}
+}
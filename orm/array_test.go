@@ -0,0 +1,179 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ArrayPost struct {
+	ID      int64 `orm:"primary_key;auto_increment"`
+	Tags    []string
+	Scores  []int `orm:"type:integer[]"`
+	Ratings []*float64
+	Data    []byte
+	Labels  []string       `orm:"type:json"`
+	Meta    map[string]int `orm:"type:json"`
+}
+
+func TestArray_Field(t *testing.T) {
+	m, err := parseModel(&ArrayPost{})
+	require.NoError(t, err)
+
+	assert.True(t, m.fieldsMap["Tags"].array)
+	assert.True(t, m.fieldsMap["Scores"].array)
+	assert.True(t, m.fieldsMap["Ratings"].array)
+	assert.False(t, m.fieldsMap["Data"].array)
+	// 指定了其他类型的切片按指定的类型读写
+	assert.False(t, m.fieldsMap["Labels"].array)
+	assert.True(t, m.fieldsMap["Labels"].json)
+
+	assert.Equal(t, "TEXT[]", Get("postgresql").ColumnType(m.fieldsMap["Tags"]))
+	assert.Equal(t, "integer[]", Get("postgresql").ColumnType(m.fieldsMap["Scores"]))
+	assert.Equal(t, "DOUBLE PRECISION[]", Get("postgresql").ColumnType(m.fieldsMap["Ratings"]))
+}
+
+func TestArray_InsertAndScan(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "array_post" ("id", "tags", "scores") VALUES ($1, $2, $3);`)).
+		WithArgs(1, `{"go","a \"b\""}`, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = RegisterInserter[ArrayPost](db).Insert([]string{"ID", "Tags", "Scores"}, &ArrayPost{
+		ID:   1,
+		Tags: []string{"go", `a "b"`},
+	}).Exec(context.Background())
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "scores", "ratings"}).
+		AddRow(1, []byte(`{go,"a b",""}`), "{1,2,3}", "{1.5,NULL}"))
+	post, err := RegisterSelector[ArrayPost](db).Select().Where(Col("ID").Eq(1)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "a b", ""}, post.Tags)
+	assert.Equal(t, []int{1, 2, 3}, post.Scores)
+	require.Len(t, post.Ratings, 2)
+	assert.Equal(t, 1.5, *post.Ratings[0])
+	assert.Nil(t, post.Ratings[1])
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "scores"}).AddRow(1, "{1,x}"))
+	_, err = RegisterSelector[ArrayPost](db).Select().Get(context.Background())
+	assert.ErrorContains(t, err, "scan array element")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFieldArg(t *testing.T) {
+	m, err := parseModel(&ArrayPost{})
+	require.NoError(t, err)
+
+	tags := []string{"go"}
+	assert.Equal(t, arrayValue{val: tags}, fieldArg(Get("postgresql"), m.fieldsMap["Tags"], tags))
+	// 其他方言不编码数组，由驱动处理
+	assert.Equal(t, tags, fieldArg(Get("mysql"), m.fieldsMap["Tags"], tags))
+	// 已经编码的值原样写入
+	assert.Equal(t, "{go}", fieldArg(Get("postgresql"), m.fieldsMap["Tags"], "{go}"))
+	assert.Equal(t, jsonValue{val: tags}, fieldArg(Get("postgresql"), m.fieldsMap["Labels"], tags))
+}
+
+func TestArrayValue(t *testing.T) {
+	f := 2.5
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		name string
+		val  any
+		want driver.Value
+	}{
+		{name: "nil", val: []string(nil), want: nil},
+		{name: "empty", val: []string{}, want: "{}"},
+		{name: "strings", val: []string{"a", `b"c`, `d\e`, "NULL"}, want: `{"a","b\"c","d\\e","NULL"}`},
+		{name: "ints", val: []int64{1, -2}, want: "{1,-2}"},
+		{name: "uints", val: []uint{3}, want: "{3}"},
+		{name: "bools", val: []bool{true, false}, want: "{t,f}"},
+		{name: "pointers", val: []*float64{&f, nil}, want: "{2.5,NULL}"},
+		{name: "times", val: []time.Time{ts}, want: `{"2024-01-02T03:04:05Z"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := arrayValue{val: tc.val}.Value()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestArrayScanner(t *testing.T) {
+	var strs []string
+	require.NoError(t, (&arrayScanner{dst: &strs}).Scan(`{a,"b,c","d\"e","f\\g","NULL"}`))
+	assert.Equal(t, []string{"a", "b,c", `d"e`, `f\g`, "NULL"}, strs)
+
+	require.NoError(t, (&arrayScanner{dst: &strs}).Scan(nil))
+	assert.Nil(t, strs)
+
+	require.NoError(t, (&arrayScanner{dst: &strs}).Scan("{}"))
+	assert.Equal(t, []string{}, strs)
+
+	var bools []bool
+	require.NoError(t, (&arrayScanner{dst: &bools}).Scan("{t,f}"))
+	assert.Equal(t, []bool{true, false}, bools)
+
+	var times []time.Time
+	require.NoError(t, (&arrayScanner{dst: &times}).Scan(`{"2024-01-02 03:04:05+00"}`))
+	require.Len(t, times, 1)
+	assert.True(t, times[0].Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	var ptrs []*int
+	require.NoError(t, (&arrayScanner{dst: &ptrs}).Scan("{1,NULL}"))
+	require.Len(t, ptrs, 2)
+	assert.Equal(t, 1, *ptrs[0])
+	assert.Nil(t, ptrs[1])
+
+	var ints []int
+	assert.ErrorContains(t, (&arrayScanner{dst: &ints}).Scan("{1,NULL}"), "NULL array element")
+	assert.ErrorContains(t, (&arrayScanner{dst: &ints}).Scan("{{1,2},{3,4}}"), "multidimensional")
+	assert.ErrorContains(t, (&arrayScanner{dst: &strs}).Scan(`{"a}`), "invalid array")
+	assert.ErrorContains(t, (&arrayScanner{dst: &strs}).Scan("a,b"), "invalid array")
+	assert.ErrorContains(t, (&arrayScanner{dst: &strs}).Scan(1), "cannot scan")
+}
+
+func TestArray_Predicates(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	q, err := RegisterSelector[ArrayPost](db).Select().Where(
+		Col("Tags").Has("go"),
+		Col("Tags").Contains("a", "b"),
+		Col("Scores").Overlaps(1, 2),
+		Col("ID").EqAny([]int64{1, 2, 3}),
+	).Build()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "array_post" WHERE $1 = ANY("tags") AND "tags" @> ARRAY[$2, $3] `+
+		`AND "scores" && ARRAY[$4, $5] AND "id" = ANY($6);`, q.SQL)
+	assert.Equal(t, []any{"go", "a", "b", 1, 2, arrayValue{val: []int64{1, 2, 3}}}, q.Args)
+
+	val, err := q.Args[5].(driver.Valuer).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "{1,2,3}", val)
+
+	mysqlDB, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	assert.PanicsWithValue(t, ErrArrayNotSupported, func() {
+		_, _ = RegisterSelector[ArrayPost](mysqlDB).Select().Where(Col("Tags").Has("go")).Build()
+	})
+	assert.Panics(t, func() { Col("ID").EqAny(1) })
+}
@@ -0,0 +1,103 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueryBudgetExceeded 严格模式下超出查询预算时返回
+var ErrQueryBudgetExceeded = errors.New("orm: query budget exceeded")
+
+// QueryBudget 单个请求的数据库查询预算，限制查询次数和累计的数据库耗时
+// 预算通过context传递，同一个预算可以被并发的查询共享
+type QueryBudget struct {
+	MaxQueries  int           // 最大查询次数，0表示不限制
+	MaxDuration time.Duration // 最大累计耗时，0表示不限制
+	Strict      bool          // 严格模式下超出预算后的查询直接返回 ErrQueryBudgetExceeded
+
+	queries  atomic.Int64
+	duration atomic.Int64
+	exceeded atomic.Bool
+}
+
+// NewQueryBudget 创建查询预算
+func NewQueryBudget(maxQueries int, maxDuration time.Duration) *QueryBudget {
+	return &QueryBudget{
+		MaxQueries:  maxQueries,
+		MaxDuration: maxDuration,
+	}
+}
+
+// Queries 返回已执行的查询次数
+func (b *QueryBudget) Queries() int {
+	return int(b.queries.Load())
+}
+
+// Duration 返回累计的数据库耗时
+func (b *QueryBudget) Duration() time.Duration {
+	return time.Duration(b.duration.Load())
+}
+
+// Exceeded 判断是否超出预算
+func (b *QueryBudget) Exceeded() bool {
+	return b.exceeded.Load()
+}
+
+// acquire 在执行查询前检查并占用预算
+func (b *QueryBudget) acquire() error {
+	n := b.queries.Add(1)
+	if b.MaxQueries > 0 && n > int64(b.MaxQueries) {
+		b.exceeded.Store(true)
+	}
+	if b.MaxDuration > 0 && b.Duration() >= b.MaxDuration {
+		b.exceeded.Store(true)
+	}
+
+	if b.Strict && b.Exceeded() {
+		return ErrQueryBudgetExceeded
+	}
+	return nil
+}
+
+// record 记录查询耗时
+func (b *QueryBudget) record(elapsed time.Duration) {
+	total := b.duration.Add(int64(elapsed))
+	if b.MaxDuration > 0 && time.Duration(total) > b.MaxDuration {
+		b.exceeded.Store(true)
+	}
+}
+
+// queryBudgetKey 查询预算在context中的键
+type queryBudgetKey struct{}
+
+// WithQueryBudget 在context中设置查询预算
+func WithQueryBudget(ctx context.Context, budget *QueryBudget) context.Context {
+	return context.WithValue(ctx, queryBudgetKey{}, budget)
+}
+
+// QueryBudgetFromContext 获取context中的查询预算
+func QueryBudgetFromContext(ctx context.Context) *QueryBudget {
+	if ctx == nil {
+		return nil
+	}
+	budget, _ := ctx.Value(queryBudgetKey{}).(*QueryBudget)
+	return budget
+}
+
+// acquireBudget 在执行数据库操作前检查context中的查询预算
+func acquireBudget(ctx context.Context) error {
+	if budget := QueryBudgetFromContext(ctx); budget != nil {
+		return budget.acquire()
+	}
+	return nil
+}
+
+// recordBudget 记录从start开始的数据库耗时，通常配合defer使用
+// 耗时只统计语句执行到返回结果为止，不包含调用方遍历结果集的时间
+func recordBudget(ctx context.Context, start time.Time) {
+	if budget := QueryBudgetFromContext(ctx); budget != nil {
+		budget.record(time.Since(start))
+	}
+}
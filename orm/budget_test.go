@@ -0,0 +1,80 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBudget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strict   bool
+		wantErr  error
+		wantRows int
+	}{
+		{
+			name:     "non strict only marks exceeded",
+			wantRows: 3,
+		},
+		{
+			name:     "strict rejects queries over budget",
+			strict:   true,
+			wantErr:  ErrQueryBudgetExceeded,
+			wantRows: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+
+			db, err := Open(mockDB, "mysql")
+			require.NoError(t, err)
+
+			for i := 0; i < tc.wantRows; i++ {
+				mock.ExpectQuery("SELECT \\* FROM `test_model` WHERE `id` = \\?;").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom"))
+			}
+
+			budget := NewQueryBudget(2, 0)
+			budget.Strict = tc.strict
+			ctx := WithQueryBudget(context.Background(), budget)
+
+			for i := 0; i < 2; i++ {
+				_, err = RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(1)).Get(ctx)
+				require.NoError(t, err)
+			}
+			assert.False(t, budget.Exceeded())
+
+			_, err = RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(1)).Get(ctx)
+			assert.Equal(t, tc.wantErr, err)
+			assert.True(t, budget.Exceeded())
+			assert.Equal(t, 3, budget.Queries())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestQueryBudget_Duration(t *testing.T) {
+	budget := NewQueryBudget(0, 10*time.Millisecond)
+	budget.Strict = true
+
+	require.NoError(t, budget.acquire())
+	budget.record(5 * time.Millisecond)
+	assert.False(t, budget.Exceeded())
+
+	require.NoError(t, budget.acquire())
+	budget.record(6 * time.Millisecond)
+	assert.True(t, budget.Exceeded())
+	assert.Equal(t, 11*time.Millisecond, budget.Duration())
+
+	assert.Equal(t, ErrQueryBudgetExceeded, budget.acquire())
+}
@@ -0,0 +1,204 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BulkLoadDialect 支持批量导入模式的方言
+// 方法返回导入前执行的语句和导入后恢复的语句，方言不支持时返回空
+type BulkLoadDialect interface {
+	// DeferConstraintsSQL 推迟或关闭约束检查，语句在导入事务内执行
+	DeferConstraintsSQL() (before, after []string)
+	// DisableIndexesSQL 停用表的索引维护，语句在导入事务外执行
+	DisableIndexesSQL(table string) (before, after []string)
+}
+
+// BulkLoadStats 批量导入的统计信息
+type BulkLoadStats struct {
+	Rows                int64         // 已写入的行数
+	Batches             int           // 已执行的批次数
+	Duration            time.Duration // 已耗费的时间
+	ConstraintsDeferred bool          // 是否推迟了约束检查
+	IndexesDisabled     bool          // 是否停用了索引维护
+}
+
+// RowsPerSecond 每秒写入的行数
+func (s BulkLoadStats) RowsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / s.Duration.Seconds()
+}
+
+type bulkLoadConfig struct {
+	batchSize        int
+	cols             []string
+	deferConstraints bool
+	disableIndexes   bool
+	progress         func(BulkLoadStats)
+}
+
+// BulkLoadOption 批量导入的配置选项
+type BulkLoadOption func(*bulkLoadConfig)
+
+// WithBulkBatchSize 设置每个INSERT语句包含的行数，默认为1000
+func WithBulkBatchSize(size int) BulkLoadOption {
+	return func(c *bulkLoadConfig) {
+		if size > 0 {
+			c.batchSize = size
+		}
+	}
+}
+
+// WithBulkColumns 只写入指定的列，参数为结构体字段名
+func WithBulkColumns(cols ...string) BulkLoadOption {
+	return func(c *bulkLoadConfig) {
+		c.cols = cols
+	}
+}
+
+// WithDeferConstraints 导入期间推迟约束检查
+// MySQL关闭外键和唯一性检查，PostgreSQL推迟可延迟的约束，SQLite推迟外键检查到提交时
+func WithDeferConstraints() BulkLoadOption {
+	return func(c *bulkLoadConfig) {
+		c.deferConstraints = true
+	}
+}
+
+// WithDisableIndexes 导入期间停用非唯一索引的维护，导入结束后统一重建
+// 目前只有MySQL支持（ALTER TABLE ... DISABLE KEYS，仅对MyISAM表生效），其他方言会忽略该选项
+func WithDisableIndexes() BulkLoadOption {
+	return func(c *bulkLoadConfig) {
+		c.disableIndexes = true
+	}
+}
+
+// WithBulkProgress 设置每个批次完成后的回调，用于报告导入进度
+func WithBulkProgress(fn func(BulkLoadStats)) BulkLoadOption {
+	return func(c *bulkLoadConfig) {
+		c.progress = fn
+	}
+}
+
+// BulkLoader 批量导入器，用于初始化数据等大批量写入场景
+// 所有批次在同一个事务中写入，任一批次失败时整体回滚
+type BulkLoader[T any] struct {
+	db     *DB
+	config *bulkLoadConfig
+}
+
+// NewBulkLoader 创建批量导入器
+func NewBulkLoader[T any](db *DB, opts ...BulkLoadOption) *BulkLoader[T] {
+	config := &bulkLoadConfig{batchSize: 1000}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &BulkLoader[T]{
+		db:     db,
+		config: config,
+	}
+}
+
+// Load 分批写入数据并返回统计信息，失败时返回的统计信息为回滚前的进度
+func (l *BulkLoader[T]) Load(ctx context.Context, rows []*T) (stats BulkLoadStats, err error) {
+	if len(rows) == 0 {
+		return stats, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		stats.Duration = time.Since(start)
+	}()
+
+	dialect, _ := l.db.dialect.(BulkLoadDialect)
+
+	// 停用索引的语句可能导致隐式提交，因此在事务外执行
+	if l.config.disableIndexes && dialect != nil {
+		table := RegisterInserter[T](l.db).model.table
+		before, after := dialect.DisableIndexesSQL(table)
+		if len(before) > 0 {
+			if err := l.execAll(ctx, l.db, before); err != nil {
+				return stats, err
+			}
+			stats.IndexesDisabled = true
+			defer func() {
+				// 使用独立的上下文，保证导入被取消时也能恢复索引
+				if restoreErr := l.execAll(context.WithoutCancel(ctx), l.db, after); restoreErr != nil {
+					err = errors.Join(err, restoreErr)
+				}
+			}()
+		}
+	}
+
+	err = l.db.Tx(ctx, func(tx *Tx) (err error) {
+		if l.config.deferConstraints && dialect != nil {
+			before, after := dialect.DeferConstraintsSQL()
+			if err = l.execAll(ctx, tx, before); err != nil {
+				return err
+			}
+			stats.ConstraintsDeferred = len(before) > 0
+			// 会话级别的设置需要在事务结束前恢复，避免影响连接归还后的其他查询
+			defer func() {
+				if restoreErr := l.execAll(context.WithoutCancel(ctx), tx, after); restoreErr != nil {
+					err = errors.Join(err, restoreErr)
+				}
+			}()
+		}
+
+		for i := 0; i < len(rows); i += l.config.batchSize {
+			batch := rows[i:min(i+l.config.batchSize, len(rows))]
+			if _, err = RegisterInserter[T](tx).Insert(l.config.cols, batch...).Exec(ctx); err != nil {
+				return fmt.Errorf("orm: bulk load batch %d: %w", stats.Batches+1, err)
+			}
+			stats.Rows += int64(len(batch))
+			stats.Batches++
+			if l.config.progress != nil {
+				stats.Duration = time.Since(start)
+				l.config.progress(stats)
+			}
+		}
+		return nil
+	}, nil)
+	return stats, err
+}
+
+// execAll 依次执行语句
+func (l *BulkLoader[T]) execAll(ctx context.Context, layer Layer, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := layer.execContext(ctx, stmt); err != nil {
+			return fmt.Errorf("orm: bulk load %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+func (m Mysql) DeferConstraintsSQL() (before, after []string) {
+	return []string{"SET FOREIGN_KEY_CHECKS = 0", "SET UNIQUE_CHECKS = 0"},
+		[]string{"SET UNIQUE_CHECKS = 1", "SET FOREIGN_KEY_CHECKS = 1"}
+}
+
+func (m Mysql) DisableIndexesSQL(table string) (before, after []string) {
+	return []string{"ALTER TABLE " + m.Quote(table) + " DISABLE KEYS"},
+		[]string{"ALTER TABLE " + m.Quote(table) + " ENABLE KEYS"}
+}
+
+func (p Postgresql) DeferConstraintsSQL() (before, after []string) {
+	// 只对声明为 DEFERRABLE 的约束生效，事务结束时自动恢复
+	return []string{"SET CONSTRAINTS ALL DEFERRED"}, nil
+}
+
+func (p Postgresql) DisableIndexesSQL(table string) (before, after []string) {
+	return nil, nil
+}
+
+func (s Sqlite) DeferConstraintsSQL() (before, after []string) {
+	// 事务结束时自动恢复
+	return []string{"PRAGMA defer_foreign_keys = ON"}, nil
+}
+
+func (s Sqlite) DisableIndexesSQL(table string) (before, after []string) {
+	return nil, nil
+}
@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BulkUser struct {
+	ID   int
+	Name string
+}
+
+func TestBulkLoader_Load(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectExec("ALTER TABLE `bulk_user` DISABLE KEYS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET UNIQUE_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?), (?, ?);").
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?);").
+		WithArgs(3, "c").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("SET UNIQUE_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectExec("ALTER TABLE `bulk_user` ENABLE KEYS").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var progress []int64
+	stats, err := NewBulkLoader[BulkUser](db,
+		WithBulkBatchSize(2),
+		WithDeferConstraints(),
+		WithDisableIndexes(),
+		WithBulkProgress(func(s BulkLoadStats) {
+			progress = append(progress, s.Rows)
+		}),
+	).Load(context.Background(), []*BulkUser{{1, "a"}, {2, "b"}, {3, "c"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), stats.Rows)
+	assert.Equal(t, 2, stats.Batches)
+	assert.True(t, stats.ConstraintsDeferred)
+	assert.True(t, stats.IndexesDisabled)
+	assert.Greater(t, stats.Duration.Nanoseconds(), int64(0))
+	assert.Equal(t, []int64{2, 3}, progress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkLoader_LoadError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET UNIQUE_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `bulk_user` (`name`) VALUES (?);").
+		WithArgs("a").
+		WillReturnError(errors.New("duplicate entry"))
+	// 失败时同样恢复会话设置
+	mock.ExpectExec("SET UNIQUE_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	stats, err := NewBulkLoader[BulkUser](db, WithDeferConstraints(), WithBulkColumns("Name")).
+		Load(context.Background(), []*BulkUser{{Name: "a"}})
+	assert.ErrorContains(t, err, "duplicate entry")
+	assert.Equal(t, int64(0), stats.Rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkLoadStats_RowsPerSecond(t *testing.T) {
+	assert.Equal(t, float64(0), BulkLoadStats{Rows: 10}.RowsPerSecond())
+	assert.Equal(t, float64(500), BulkLoadStats{Rows: 1000, Duration: 2e9}.RowsPerSecond())
+}
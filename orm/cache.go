@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/orm/internal/cache"
 )
 
 var (
@@ -52,6 +55,15 @@ type ModelCacheConfig struct {
 
 	// Conditions 缓存条件，决定哪些查询应该被缓存
 	Conditions []CacheCondition
+
+	// DisableAutoInvalidate 写操作成功后不自动使该模型的缓存失效
+	DisableAutoInvalidate bool
+
+	// StaleTTL 缓存过期后仍然返回旧值的时间，期间在后台刷新缓存，为0时使用 CacheManager 的设置
+	StaleTTL time.Duration
+
+	// Layer 使用 LayeredCache 时查询结果写入哪些缓存层，默认同时使用本地缓存和远程缓存
+	Layer CacheLayer
 }
 
 // CacheCondition 缓存条件函数，决定是否应该缓存查询结果
@@ -65,6 +77,11 @@ type CacheManager struct {
 	enabled          bool                                                      // 是否全局启用缓存
 	keyGenerator     func(model string, operation string, query *Query) string // 默认缓存键生成器
 	prefix           string                                                    // 缓存键前缀
+	autoInvalidate   bool                                                      // 写操作成功后是否自动使模型的缓存失效
+	singleflight     bool                                                      // 是否合并相同键的并发未命中查询
+	staleTTL         time.Duration                                             // 缓存过期后仍然返回旧值的时间
+	group            cache.Group                                               // 合并并发查询和后台刷新
+	clock            clock.Clock                                               // 判断旧值是否新鲜的时间来源，nil 时使用系统时间
 }
 
 // NewCacheManager 创建一个新的缓存管理器
@@ -75,6 +92,8 @@ func NewCacheManager(cache Cache) *CacheManager {
 		defaultTTL:       5 * time.Minute, // 默认5分钟过期
 		enabled:          true,
 		keyGenerator:     defaultKeyGenerator,
+		autoInvalidate:   true,
+		singleflight:     true,
 	}
 }
 
@@ -90,6 +109,26 @@ func (cm *CacheManager) WithKeyGenerator(generator func(model string, operation
 	return cm
 }
 
+// WithAutoInvalidate 设置写操作成功后是否自动使模型的缓存失效，默认开启
+func (cm *CacheManager) WithAutoInvalidate(enabled bool) *CacheManager {
+	cm.autoInvalidate = enabled
+	return cm
+}
+
+// WithSingleflight 设置是否合并相同缓存键的并发未命中查询，默认开启。
+// 开启后同时未命中的查询只有一个访问数据库，其他查询等待并从缓存读取结果
+func (cm *CacheManager) WithSingleflight(enabled bool) *CacheManager {
+	cm.singleflight = enabled
+	return cm
+}
+
+// WithStaleWhileRevalidate 设置缓存过期后仍然返回旧值的时间，期间的查询直接返回旧值，
+// 同时在后台只用一个查询刷新缓存。为0时关闭，默认关闭
+func (cm *CacheManager) WithStaleWhileRevalidate(staleTTL time.Duration) *CacheManager {
+	cm.staleTTL = staleTTL
+	return cm
+}
+
 // SetModelCacheConfig 为特定模型设置缓存配置
 func (cm *CacheManager) SetModelCacheConfig(modelName string, config *ModelCacheConfig) {
 	cm.modelCacheConfig[modelName] = config
@@ -202,6 +241,24 @@ func (cm *CacheManager) GetTTL(modelName string) time.Duration {
 	return cm.defaultTTL
 }
 
+// GetStaleTTL 获取缓存过期后仍然返回旧值的时间
+func (cm *CacheManager) GetStaleTTL(modelName string) time.Duration {
+	config, ok := cm.modelCacheConfig[modelName]
+	if ok && config.StaleTTL > 0 {
+		return config.StaleTTL
+	}
+	return cm.staleTTL
+}
+
+// GetLayer 获取模型使用的缓存层
+func (cm *CacheManager) GetLayer(modelName string) CacheLayer {
+	config, ok := cm.modelCacheConfig[modelName]
+	if ok {
+		return config.Layer
+	}
+	return CacheLayerAll
+}
+
 // GetTags 获取缓存标签
 func (cm *CacheManager) GetTags(modelName string) []string {
 	config, ok := cm.modelCacheConfig[modelName]
@@ -247,4 +304,52 @@ func (cm *CacheManager) InvalidateCache(ctx context.Context, modelName string, t
 	// return cm.cache.Clear(ctx)
 
 	return fmt.Errorf("cannot invalidate cache: no tags provided or defined for model %s", modelName)
-}
\ No newline at end of file
+}
+// cacheInvalidation 写操作执行成功后使缓存失效的方式
+type cacheInvalidation int
+
+const (
+	invalidateAuto   cacheInvalidation = iota // 模型启用了缓存且没有关闭自动失效时失效
+	invalidateAlways                          // 通过 WithInvalidateCache 等显式要求失效
+	invalidateNever                           // 通过 WithoutInvalidateCache 关闭失效
+)
+
+// shouldInvalidate 判断写操作成功后是否需要使模型的缓存失效
+func (cm *CacheManager) shouldInvalidate(modelName string, mode cacheInvalidation) bool {
+	if cm == nil || !cm.enabled || cm.cache == nil {
+		return false
+	}
+	switch mode {
+	case invalidateAlways:
+		return true
+	case invalidateNever:
+		return false
+	}
+	if !cm.autoInvalidate {
+		return false
+	}
+	// 没有启用缓存的模型不会产生缓存，不需要失效
+	config, ok := cm.modelCacheConfig[modelName]
+	return ok && config.Enabled && !config.DisableAutoInvalidate
+}
+
+// invalidateAfterWrite 写操作成功后使模型的缓存失效。在事务中执行时推迟到事务提交之后，
+// 避免提交前的查询把旧数据重新写入缓存，事务回滚时不失效。失效失败不影响写操作的结果
+func invalidateAfterWrite(ctx context.Context, layer Layer, modelName string, mode cacheInvalidation, tags []string) {
+	cm := layer.getDB().cacheManager
+	if !cm.shouldInvalidate(modelName, mode) {
+		return
+	}
+	invalidate := func(ctx context.Context) {
+		if err := cm.InvalidateCache(ctx, modelName, tags...); err != nil {
+			debugLog("Cache invalidation for model %s failed: %v", modelName, err)
+		}
+	}
+	if tx, ok := layer.(*Tx); ok {
+		// 提交时写操作的 ctx 可能已经结束
+		ctx = context.WithoutCancel(ctx)
+		tx.afterCommit = append(tx.afterCommit, func() { invalidate(ctx) })
+		return
+	}
+	invalidate(ctx)
+}
@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// staleEntry 开启过期后返回旧值时写入缓存的数据。FreshUntil 之后数据已经过期，
+// 但在缓存中保留到 StaleTTL 结束，期间仍然返回给调用方
+type staleEntry[V any] struct {
+	Value      V
+	FreshUntil int64 // UnixNano
+}
+
+// cacheLoad 一次通过缓存读取的参数
+type cacheLoad struct {
+	key   string
+	model string
+	ttl   time.Duration
+	tags  []string
+	layer CacheLayer
+}
+
+// scope 带上模型的缓存层和标签，供 LayeredCache 使用
+func (l cacheLoad) scope(ctx context.Context) context.Context {
+	return withCacheScope(ctx, l.layer, l.tags)
+}
+
+// loadThroughCache 从缓存读取 l.key 的值，未命中时调用 load 查询并写入缓存。
+// hit 表示返回的值是从缓存反序列化得到的，调用方需要执行反序列化后的处理。
+//
+// 开启 singleflight 时相同键的并发未命中只有一个调用方执行 load，其他调用方等待后从缓存读取各自的副本；
+// 开启过期返回旧值时，过期的数据直接返回，同时在后台刷新
+func loadThroughCache[V any](ctx context.Context, cm *CacheManager, l cacheLoad, load func(ctx context.Context) (V, error)) (v V, hit bool, err error) {
+	stale := cm.GetStaleTTL(l.model)
+	if l.ttl <= 0 {
+		// 永不过期的缓存不需要返回旧值
+		stale = 0
+	}
+
+	v, expired, err := readCache[V](ctx, cm, l, stale > 0)
+	if err == nil {
+		if expired {
+			cm.group.TryGo(l.key, func() {
+				ctx := context.WithoutCancel(ctx)
+				if v, err := load(ctx); err == nil {
+					storeCache(ctx, cm, l, stale, v)
+				} else {
+					debugLog("Cache refresh for key %s failed: %v\n", l.key, err)
+				}
+			})
+		}
+		return v, true, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		// 如果是其他错误而非缓存未命中，记录但继续执行查询
+		debugLog("Cache error: %v\n", err)
+	}
+
+	loadAndStore := func(ctx context.Context) (V, error) {
+		v, err := load(ctx)
+		if err == nil {
+			storeCache(ctx, cm, l, stale, v)
+		}
+		return v, err
+	}
+	if !cm.singleflight {
+		v, err = loadAndStore(ctx)
+		return v, false, err
+	}
+
+	leader := false
+	res, err, _ := cm.group.Do(l.key, func() (interface{}, error) {
+		leader = true
+		return loadAndStore(ctx)
+	})
+	if leader {
+		if err != nil {
+			return v, false, err
+		}
+		return res.(V), false, nil
+	}
+
+	// 共享的结果可能正在被执行查询的调用方修改，从缓存读取自己的副本
+	if err == nil {
+		if v, _, err = readCache[V](ctx, cm, l, stale > 0); err == nil {
+			return v, true, nil
+		}
+	} else if !isContextErr(err) || ctx.Err() != nil {
+		return v, false, err
+	}
+	// 执行查询的调用方被取消，或者结果没有写入缓存，自己查询
+	v, err = loadAndStore(ctx)
+	return v, false, err
+}
+
+// readCache 读取缓存，开启过期返回旧值时 expired 表示数据已经过期
+func readCache[V any](ctx context.Context, cm *CacheManager, l cacheLoad, stale bool) (v V, expired bool, err error) {
+	ctx = l.scope(ctx)
+	if !stale {
+		err = cm.cache.Get(ctx, l.key, &v)
+		return v, false, err
+	}
+	var entry staleEntry[V]
+	if err = cm.cache.Get(ctx, l.key, &entry); err != nil {
+		return v, false, err
+	}
+	if entry.FreshUntil == 0 {
+		// 开启过期返回旧值之前写入的数据
+		return v, false, ErrCacheMiss
+	}
+	return entry.Value, cm.now().UnixNano() >= entry.FreshUntil, nil
+}
+
+// storeCache 写入缓存，缓存实现支持标签时关联标签
+func storeCache[V any](ctx context.Context, cm *CacheManager, l cacheLoad, stale time.Duration, v V) {
+	ctx = l.scope(ctx)
+	var value interface{} = v
+	ttl := l.ttl
+	if stale > 0 {
+		value = staleEntry[V]{Value: v, FreshUntil: cm.now().Add(ttl).UnixNano()}
+		ttl += stale
+	}
+
+	var err error
+	if tagCache, ok := cm.cache.(interface {
+		SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+	}); ok && len(l.tags) > 0 {
+		err = tagCache.SetWithTags(ctx, l.key, value, ttl, l.tags...)
+	} else {
+		err = cm.cache.Set(ctx, l.key, value, ttl)
+	}
+	if err != nil {
+		debugLog("Error setting cache: %v\n", err)
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -3,10 +3,12 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fyerfyer/fyer-webframe/clock"
 	"github.com/fyerfyer/fyer-webframe/orm/internal/cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -295,7 +297,8 @@ func TestCacheTTL(t *testing.T) {
 	require.NoError(t, err)
 	defer ormDB.Close()
 
-	memCache := NewMemoryCache()
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	memCache := NewMemoryCache(WithMemoryCacheClock(clk))
 	ormDB.SetCacheManager(NewCacheManager(memCache))
 
 	// 配置User模型的缓存，设置非常短的TTL
@@ -316,8 +319,8 @@ func TestCacheTTL(t *testing.T) {
 	_, err = selector.Get(ctx)
 	require.NoError(t, err)
 
-	// 等待缓存过期
-	time.Sleep(100 * time.Millisecond)
+	// 推进时间使缓存过期
+	clk.Advance(100 * time.Millisecond)
 
 	// 再次执行相同的查询，应该再次访问数据库
 	_, err = selector.Get(ctx)
@@ -464,3 +467,213 @@ func TestClientCacheInterface(t *testing.T) {
 	noCacheClient := client.WithoutCache()
 	assert.NotNil(t, noCacheClient)
 }
+
+// TestAutoInvalidateCache 测试写操作成功后自动使缓存失效
+func TestAutoInvalidateCache(t *testing.T) {
+	ctx := context.Background()
+
+	newDB := func(t *testing.T, config *ModelCacheConfig) (*DB, sqlmock.Sqlmock, *MemoryCache) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mockDB.Close() })
+		db, err := Open(mockDB, "mysql")
+		require.NoError(t, err)
+
+		memCache := NewMemoryCache()
+		db.SetCacheManager(NewCacheManager(memCache))
+		db.SetModelCacheConfig("test_model", config)
+		return db, mock, memCache
+	}
+	// seed 写入一条带模型标签的缓存，模拟之前缓存过的查询
+	seed := func(t *testing.T, c *MemoryCache, tags ...string) {
+		require.NoError(t, c.SetWithTags(ctx, "test_model:query:SELECT", "cached", time.Minute, tags...))
+	}
+	cached := func(c *MemoryCache) bool {
+		var v string
+		return c.Get(ctx, "test_model:query:SELECT", &v) == nil
+	}
+	tagged := &ModelCacheConfig{Enabled: true, Tags: []string{"test"}}
+
+	t.Run("updater", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		seed(t, c, "test")
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := RegisterUpdater[TestModel](db).Update().Set(Col("Name"), "Tom").Where(Col("ID").Eq(1)).Exec(ctx)
+		require.NoError(t, err)
+		assert.False(t, cached(c))
+	})
+
+	t.Run("failed exec", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		seed(t, c, "test")
+		mock.ExpectExec("DELETE").WillReturnError(sql.ErrConnDone)
+
+		_, err := RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+		require.Error(t, err)
+		assert.True(t, cached(c))
+	})
+
+	t.Run("opt out", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		seed(t, c, "test")
+		mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err := RegisterInserter[TestModel](db).Insert(nil, &TestModel{ID: 1, Name: "Tom"}).WithoutInvalidateCache().Exec(ctx)
+		require.NoError(t, err)
+		assert.True(t, cached(c))
+	})
+
+	t.Run("disabled by manager", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		db.GetCacheManager().WithAutoInvalidate(false)
+		seed(t, c, "test")
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+		require.NoError(t, err)
+		assert.True(t, cached(c))
+
+		// 显式要求失效时不受自动失效开关影响
+		_, err = RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Eq(1)).WithInvalidateCache().Exec(ctx)
+		require.NoError(t, err)
+		assert.False(t, cached(c))
+	})
+
+	t.Run("model without tags", func(t *testing.T) {
+		db, mock, c := newDB(t, &ModelCacheConfig{Enabled: true})
+		seed(t, c)
+		require.NoError(t, c.Set(ctx, "other_model:query:SELECT", "cached", time.Minute))
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+		require.NoError(t, err)
+		assert.False(t, cached(c))
+		var v string
+		assert.NoError(t, c.Get(ctx, "other_model:query:SELECT", &v))
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		seed(t, c, "test")
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := db.Tx(ctx, func(tx *Tx) error {
+			_, err := RegisterDeleter[TestModel](tx).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+			// 提交之前不失效
+			assert.True(t, cached(c))
+			return err
+		}, nil)
+		require.NoError(t, err)
+		assert.False(t, cached(c))
+	})
+
+	t.Run("transaction rollback", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		seed(t, c, "test")
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := db.Tx(ctx, func(tx *Tx) error {
+			if _, err := RegisterDeleter[TestModel](tx).Delete().Where(Col("ID").Eq(1)).Exec(ctx); err != nil {
+				return err
+			}
+			return sql.ErrTxDone
+		}, nil)
+		require.ErrorIs(t, err, sql.ErrTxDone)
+		assert.True(t, cached(c))
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		db, mock, c := newDB(t, tagged)
+		coll := New(db).Collection(&TestModel{})
+		seed(t, c, "test")
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := coll.WithoutInvalidateCache().Update(ctx, map[string]interface{}{"Name": "Tom"}, Col("ID").Eq(1))
+		require.NoError(t, err)
+		assert.True(t, cached(c))
+
+		_, err = coll.Delete(ctx, Col("ID").Eq(1))
+		require.NoError(t, err)
+		assert.False(t, cached(c))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestCacheStampede 测试并发未命中时只查询一次数据库
+func TestCacheStampede(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	// 只期望查询一次，其他并发查询等待并从缓存读取结果
+	mock.ExpectQuery("SELECT .*").
+		WithArgs(1).
+		WillDelayFor(100 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Test User", nil))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	db.SetCacheManager(NewCacheManager(NewMemoryCache()))
+	db.SetModelCacheConfig("test_model", &ModelCacheConfig{Enabled: true, TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(1)).WithCache().Get(context.Background())
+			if assert.NoError(t, err) {
+				assert.Equal(t, "Test User", res.Name)
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCacheStaleWhileRevalidate 测试缓存过期后返回旧值并在后台刷新
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil).AddRow(2, "Jerry", nil))
+	mock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	db.SetCacheManager(NewCacheManager(NewMemoryCache(WithMemoryCacheClock(clk))).
+		WithStaleWhileRevalidate(time.Minute).
+		WithClock(clk))
+	db.SetModelCacheConfig("test_model", &ModelCacheConfig{Enabled: true, TTL: 50 * time.Millisecond})
+
+	ctx := context.Background()
+	getAll := func() []*TestModel {
+		res, err := RegisterSelector[TestModel](db).Select().WithCache().GetMulti(ctx)
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Len(t, getAll(), 2)
+	assert.Len(t, getAll(), 2)
+
+	// 过期后返回旧值，同时在后台刷新
+	clk.Advance(60 * time.Millisecond)
+	assert.Len(t, getAll(), 2)
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return len(getAll()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,195 @@
+package orm
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// QueryKillConfig 查询因 context 取消而失败后，在数据库中终止仍在执行的查询
+//
+// 驱动在 context 取消后会立即返回，但 MySQL 的查询在服务端会继续执行直到结束，
+// 一个被用户放弃的慢查询可能仍然占用数据库几分钟。启用后查询会附加唯一标记，
+// 取消时按标记找到查询所在的会话，MySQL 执行 KILL QUERY，PostgreSQL 执行 pg_cancel_backend
+type QueryKillConfig struct {
+	// Threshold 查询执行超过该时长后被取消才终止，避免为很快就会结束的查询额外执行终止语句，为0时取消即终止
+	Threshold time.Duration
+	// Timeout 执行终止语句的超时时间，默认为1秒
+	Timeout time.Duration
+	// OnError 执行终止语句失败时调用，可以为空
+	OnError func(err error)
+}
+
+// WithQueryKill 启用查询取消的统计，并在查询被取消时尽力终止数据库中的查询
+// 只支持 MySQL 和 PostgreSQL，其他方言只统计不终止；预编译语句无法附加标记，同样只统计不终止
+func WithQueryKill(cfg QueryKillConfig) DBOption {
+	return func(db *DB) error {
+		if cfg.Threshold < 0 || cfg.Timeout < 0 {
+			return errors.New("orm: query kill threshold and timeout must not be negative")
+		}
+		if cfg.Timeout == 0 {
+			cfg.Timeout = time.Second
+		}
+		prefix := make([]byte, 6)
+		if _, err := rand.Read(prefix); err != nil {
+			return err
+		}
+		db.queryKill = &queryKiller{cfg: cfg, prefix: hex.EncodeToString(prefix)}
+		return nil
+	}
+}
+
+// QueryCancelStats 查询取消的统计信息
+type QueryCancelStats struct {
+	Canceled   int64 // 因 context 取消或超时而失败的查询数
+	Killed     int64 // 在数据库中被终止的查询数
+	KillFailed int64 // 终止语句执行失败的次数
+}
+
+// QueryCancelStats 返回查询取消的统计信息，未启用 WithQueryKill 时返回零值
+func (db *DB) QueryCancelStats() QueryCancelStats {
+	if db.queryKill == nil {
+		return QueryCancelStats{}
+	}
+	return QueryCancelStats{
+		Canceled:   db.queryKill.canceled.Load(),
+		Killed:     db.queryKill.killed.Load(),
+		KillFailed: db.queryKill.killFailed.Load(),
+	}
+}
+
+// queryKiller 为查询附加标记，并在查询被取消后终止数据库中的查询
+type queryKiller struct {
+	cfg    QueryKillConfig
+	prefix string // 进程内唯一的前缀，避免多个进程的标记冲突
+	seq    atomic.Uint64
+
+	canceled, killed, killFailed atomic.Int64
+}
+
+// killable 判断查询能否被终止：方言支持，且 context 可以被取消
+func (k *queryKiller) killable(ctx context.Context, d Dialect) bool {
+	if ctx.Done() == nil {
+		return false
+	}
+	switch d.(type) {
+	case *Mysql, *Postgresql:
+		return true
+	}
+	return false
+}
+
+// tag 在SQL末尾的分号之前附加唯一标记，返回附加后的SQL和标记
+func (k *queryKiller) tag(query string) (string, string) {
+	tag := "orm_query_id='" + k.prefix + "-" + strconv.FormatUint(k.seq.Add(1), 10) + "'"
+	comment := "/*" + tag + "*/"
+	trimmed := strings.TrimRight(query, " \t\n;")
+	if trimmed != query {
+		return trimmed + " " + comment + ";", tag
+	}
+	return query + " " + comment, tag
+}
+
+// done 在查询结束后调用，查询因 context 取消而失败时计数，执行超过阈值时在后台终止数据库中的查询
+// tag 为空表示查询没有附加标记，只计数
+func (k *queryKiller) done(ctx context.Context, db *DB, tag string, start time.Time, err error) {
+	if err == nil || ctx.Err() == nil {
+		return
+	}
+	k.canceled.Add(1)
+	if tag == "" || time.Since(start) < k.cfg.Threshold {
+		return
+	}
+	go k.kill(db, tag)
+}
+
+// kill 按标记找到执行查询的会话并终止查询，不使用调用方已经取消的 context
+func (k *queryKiller) kill(db *DB, tag string) {
+	ctx, cancel := context.WithTimeout(context.Background(), k.cfg.Timeout)
+	defer cancel()
+
+	n, err := killQuery(ctx, db.sqlDB, db.dialect, tag)
+	if err != nil {
+		k.killFailed.Add(1)
+		if k.cfg.OnError != nil {
+			k.cfg.OnError(err)
+		}
+		return
+	}
+	k.killed.Add(n)
+}
+
+// killQuery 终止SQL中包含 tag 的查询，返回终止的查询数
+// 查找会话的语句本身也包含标记，需要排除当前会话
+func killQuery(ctx context.Context, sqlDB *sql.DB, d Dialect, tag string) (int64, error) {
+	pattern := "%" + tag + "%"
+	switch d.(type) {
+	case *Mysql:
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+
+		rows, err := conn.QueryContext(ctx, "SELECT ID FROM information_schema.PROCESSLIST WHERE INFO LIKE ? AND ID <> CONNECTION_ID()", pattern)
+		if err != nil {
+			return 0, err
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+
+		var killed int64
+		for _, id := range ids {
+			// KILL 不支持占位符，id 是从数据库读取的整数
+			if _, err := conn.ExecContext(ctx, "KILL QUERY "+strconv.FormatInt(id, 10)); err != nil {
+				return killed, err
+			}
+			killed++
+		}
+		return killed, nil
+	case *Postgresql:
+		// pg_cancel_backend 放在选择列表中，只对满足条件的会话执行
+		rows, err := sqlDB.QueryContext(ctx, "SELECT pg_cancel_backend(pid) FROM pg_stat_activity WHERE query LIKE $1 AND pid <> pg_backend_pid()", pattern)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+		var killed int64
+		for rows.Next() {
+			var ok bool
+			if err := rows.Scan(&ok); err != nil {
+				return killed, err
+			}
+			if ok {
+				killed++
+			}
+		}
+		return killed, rows.Err()
+	}
+	return 0, nil
+}
+
+// killTag 启用 WithQueryKill 且查询可以被终止时为SQL附加标记，否则原样返回SQL和空标记
+func (db *DB) killTag(ctx context.Context, query string) (string, string) {
+	if db.queryKill == nil || !db.queryKill.killable(ctx, db.dialect) {
+		return query, ""
+	}
+	return db.queryKill.tag(query)
+}
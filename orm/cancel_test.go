@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryKill_Tag(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql", WithQueryKill(QueryKillConfig{}))
+	require.NoError(t, err)
+
+	// 可以取消的 context 附加标记
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `bulk_user` WHERE `id` = ? /*orm_query_id='") + `[0-9a-f]{12}-1'\*/;$`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = RegisterDeleter[BulkUser](db).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+	require.NoError(t, err)
+
+	// 不会被取消的 context 不附加标记
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `bulk_user` WHERE `id` = ?;")).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = RegisterDeleter[BulkUser](db).Delete().Where(Col("ID").Eq(1)).Exec(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, QueryCancelStats{}, db.QueryCancelStats())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// 不支持终止的方言不附加标记
+	sqliteDB, err := Open(mockDB, "sqlite", WithQueryKill(QueryKillConfig{}))
+	require.NoError(t, err)
+	query, tag := sqliteDB.killTag(ctx, "SELECT 1;")
+	assert.Equal(t, "SELECT 1;", query)
+	assert.Empty(t, tag)
+
+	_, err = Open(mockDB, "mysql", WithQueryKill(QueryKillConfig{Threshold: -time.Second}))
+	assert.Error(t, err)
+}
+
+func TestQueryKill_MySQL(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql", WithQueryKill(QueryKillConfig{}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	mock.ExpectQuery("SELECT .* /\\*orm_query_id=.*").WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ID FROM information_schema.PROCESSLIST WHERE INFO LIKE ? AND ID <> CONNECTION_ID()")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"ID"}).AddRow(42))
+	mock.ExpectExec("KILL QUERY 42").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = RegisterSelector[BulkUser](db).Select().GetMulti(ctx)
+	assert.Error(t, err)
+
+	assert.Eventually(t, func() bool { return db.QueryCancelStats().Killed == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, QueryCancelStats{Canceled: 1, Killed: 1}, db.QueryCancelStats())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryKill_Postgres(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	killErr := make(chan error, 1)
+	db, err := Open(mockDB, "postgresql", WithQueryKill(QueryKillConfig{
+		OnError: func(err error) { killErr <- err },
+	}))
+	require.NoError(t, err)
+
+	// 终止成功
+	ctx, cancel := context.WithCancel(context.Background())
+	mock.ExpectExec("UPDATE .* /\\*orm_query_id=.*").WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_cancel_backend(pid) FROM pg_stat_activity WHERE query LIKE $1 AND pid <> pg_backend_pid()")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_cancel_backend"}).AddRow(true))
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = RegisterUpdater[BulkUser](db).Update().Set(Col("Name"), "a").Exec(ctx)
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool { return db.QueryCancelStats().Killed == 1 }, time.Second, 5*time.Millisecond)
+
+	// 终止失败
+	ctx, cancel = context.WithCancel(context.Background())
+	mock.ExpectExec("UPDATE .*").WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT pg_cancel_backend.*").WillReturnError(errors.New("permission denied"))
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = RegisterUpdater[BulkUser](db).Update().Set(Col("Name"), "a").Exec(ctx)
+	assert.Error(t, err)
+
+	select {
+	case err := <-killErr:
+		assert.EqualError(t, err, "permission denied")
+	case <-time.After(time.Second):
+		t.Fatal("kill error not reported")
+	}
+	assert.Equal(t, QueryCancelStats{Canceled: 2, Killed: 1, KillFailed: 1}, db.QueryCancelStats())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryKill_Threshold(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql", WithQueryKill(QueryKillConfig{Threshold: time.Minute}))
+	require.NoError(t, err)
+
+	// 执行时间未超过阈值的查询只计数不终止
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mock.ExpectQuery("SELECT .*").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = RegisterSelector[BulkUser](db).Select().GetMulti(ctx)
+	assert.Error(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, QueryCancelStats{Canceled: 1}, db.QueryCancelStats())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
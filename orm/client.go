@@ -7,12 +7,12 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"sync"
 )
 
 // Client 是对底层ORM框架的简洁封装，提供更方便的CRUD操作
 type Client struct {
 	db *DB
+	tx *Tx // 事务中的客户端在事务上执行查询
 }
 
 // New 创建一个新的ORM客户端
@@ -49,13 +49,25 @@ func getModelName(model interface{}) string {
 	return fmt.Sprintf("%T", model)
 }
 
-// Transaction 执行事务
+// Transaction 执行事务，fn 中的客户端在事务上执行查询。在事务中的客户端上再次调用时开启嵌套事务，
+// 嵌套的 fn 返回错误时只回滚到嵌套事务开始的位置，外层事务可以继续执行
 func (c *Client) Transaction(ctx context.Context, fn func(tc *Client) error) error {
-	return c.db.Tx(ctx, func(tx *Tx) error {
+	run := func(tx *Tx) error {
 		// 创建一个基于事务的客户端
-		txClient := &Client{db: tx.getDB()}
-		return fn(txClient)
-	}, nil)
+		return fn(&Client{db: c.db, tx: tx})
+	}
+	if c.tx != nil {
+		return c.tx.Tx(ctx, run, nil)
+	}
+	return c.db.Tx(ctx, run, nil)
+}
+
+// layer 客户端执行查询的对象，事务中的客户端为事务，否则为数据库
+func (c *Client) layer() Layer {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db
 }
 
 // Close 关闭客户端连接
@@ -65,12 +77,12 @@ func (c *Client) Close() error {
 
 // Raw 执行原始SQL查询
 func (c *Client) Raw(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
-	return c.db.queryContext(ctx, sql, args...)
+	return c.layer().queryContext(ctx, sql, args...)
 }
 
 // Exec 执行原始SQL命令
 func (c *Client) Exec(ctx context.Context, sql string, args ...interface{}) (Result, error) {
-	result, err := c.db.execContext(ctx, sql, args...)
+	result, err := c.layer().execContext(ctx, sql, args...)
 	if err != nil {
 		return Result{err: err}, err
 	}
@@ -111,7 +123,7 @@ func (c *Client) Count(ctx context.Context, model interface{}, where ...Conditio
 	query := builder.String()
 
 	// 执行查询
-	rows, err := db.queryContext(ctx, query, args...)
+	rows, err := c.layer().queryContext(ctx, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -227,39 +239,9 @@ func (c *ShardingClient) ExecuteOnShard(ctx context.Context, shardName string, f
 	return fn(db)
 }
 
-// ExecuteOnAllShards 在所有分片上执行操作
-func (c *ShardingClient) ExecuteOnAllShards(ctx context.Context, fn func(db *DB) error) []error {
-	var errors []error
-
-	c.shardingManager.mu.RLock()
-	shards := make(map[string]*DB)
-	for name, db := range c.shardingManager.shards {
-		shards[name] = db
-	}
-	c.shardingManager.mu.RUnlock()
-
-	// 并发执行
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	for name, db := range shards {
-		wg.Add(1)
-		go func(name string, db *DB) {
-			defer wg.Done()
-
-			err := fn(db)
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("shard %s: %w", name, err))
-				mu.Unlock()
-			}
-		}(name, db)
-	}
-
-	// 等待所有操作完成
-	wg.Wait()
-
-	return errors
+// ExecuteOnAllShards 在所有分片上并发执行操作，返回每个分片的执行结果
+func (c *ShardingClient) ExecuteOnAllShards(ctx context.Context, fn func(ctx context.Context, db *DB) error, opts ...ShardExecOption) ShardResults {
+	return c.shardingManager.executeOnAllShards(ctx, fn, opts)
 }
 
 // WithShardKey 创建一个带有分片键信息的查询上下文
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -679,4 +680,43 @@ func TestClient_CountWithTag(t *testing.T) {
 
 	// 验证所有预期的SQL语句都已执行
 	assert.NoError(t, mock.ExpectationsWereMet())
-}
\ No newline at end of file
+}
+func TestClient_NestedTransaction(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	// 事务中的客户端在事务的连接上执行查询
+	mockDB.SetMaxOpenConns(1)
+
+	mock.ExpectBegin()
+	// Collection 插入的列顺序不固定，不检查参数
+	mock.ExpectExec("INSERT INTO `test_model`").
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("SAVEPOINT `sp_1`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `test_model`").
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT `sp_1`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	defer db.Close()
+
+	client := New(db)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = client.Transaction(ctx, func(tc *Client) error {
+		if _, err := tc.Collection(&TestModel{}).Insert(ctx, &TestModel{ID: 6, Name: "Outer"}); err != nil {
+			return err
+		}
+		// 内层事务失败不影响外层事务提交
+		err := tc.Transaction(ctx, func(inner *Client) error {
+			_, err := inner.Collection(&TestModel{}).Insert(ctx, &TestModel{ID: 7, Name: "Inner"})
+			return err
+		})
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+)
+
+// WithClock 设置 DB 使用的时间来源，迁移记录的时间和 db.Now 都使用它，默认为系统时间。
+// 测试中可以传入 clock.Mock 固定写入的时间戳
+func WithClock(c clock.Clock) DBOption {
+	return func(db *DB) error {
+		if c == nil {
+			return errors.New("orm: clock cannot be nil")
+		}
+		db.clock = c
+		return nil
+	}
+}
+
+// Now 返回 DB 的时间来源的当前时间，钩子中设置创建时间和更新时间时应当使用它，而不是 time.Now
+func (db *DB) Now() time.Time {
+	return clock.OrSystem(db.clock).Now()
+}
+
+// WithClock 设置判断缓存是否过期时使用的时间来源，只影响过期返回旧值时的新鲜期，
+// 缓存条目本身的过期时间由缓存实现决定，例如 WithMemoryCacheClock
+func (cm *CacheManager) WithClock(c clock.Clock) *CacheManager {
+	cm.clock = c
+	return cm
+}
+
+// now 返回缓存管理器的当前时间
+func (cm *CacheManager) now() time.Time {
+	return clock.OrSystem(cm.clock).Now()
+}
+
+// WithMemoryCacheClock 设置 MemoryCache 计算过期时间使用的时间来源，默认为系统时间
+func WithMemoryCacheClock(c clock.Clock) MemoryCacheOption {
+	return func(mc *MemoryCache) {
+		mc.clock = clock.OrSystem(c)
+	}
+}
+
+// WithLRUCacheClock 设置 LRUCache 计算过期时间使用的时间来源，默认为系统时间
+func WithLRUCacheClock(c clock.Clock) LRUCacheOption {
+	return func(lc *LRUCache) {
+		lc.clock = clock.OrSystem(c)
+	}
+}
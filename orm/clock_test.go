@@ -0,0 +1,54 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_WithClock(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	db, err := Open(mockDB, "mysql", WithClock(clock.NewMock(now)))
+	require.NoError(t, err)
+	assert.Equal(t, now, db.Now())
+
+	_, err = Open(mockDB, "mysql", WithClock(nil))
+	assert.Error(t, err)
+
+	db, err = Open(mockDB, "mysql")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), db.Now(), time.Second)
+}
+
+func TestCacheClock(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(WithMemoryCacheClock(clk)),
+		"lru":    NewLRUCache(10, WithLRUCacheClock(clk)),
+	}
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+			require.NoError(t, c.Set(ctx, "forever", "value", 0))
+
+			var v string
+			clk.Advance(59 * time.Second)
+			require.NoError(t, c.Get(ctx, "key", &v))
+			assert.Equal(t, "value", v)
+
+			clk.Advance(2 * time.Second)
+			assert.ErrorIs(t, c.Get(ctx, "key", &v), ErrCacheMiss)
+			assert.NoError(t, c.Get(ctx, "forever", &v))
+		})
+	}
+}
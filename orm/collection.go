@@ -16,13 +16,49 @@ type Collection struct {
 	client    *Client
 	modelType interface{}
 	modelName string
+
+	invalidation   cacheInvalidation // 写操作成功后使缓存失效的方式
+	invalidateTags []string          // 要失效的缓存标签
+}
+
+// WithInvalidateCache 返回写操作成功后总是使缓存失效的集合，即使模型关闭了自动失效
+func (c *Collection) WithInvalidateCache() *Collection {
+	nc := *c
+	nc.invalidation = invalidateAlways
+	return &nc
+}
+
+// WithInvalidateTags 返回写操作成功后按指定标签使缓存失效的集合
+func (c *Collection) WithInvalidateTags(tags ...string) *Collection {
+	nc := *c
+	nc.invalidation = invalidateAlways
+	nc.invalidateTags = tags
+	return &nc
+}
+
+// WithoutInvalidateCache 返回写操作成功后不使缓存失效的集合
+func (c *Collection) WithoutInvalidateCache() *Collection {
+	nc := *c
+	nc.invalidation = invalidateNever
+	return &nc
+}
+
+// getModel 获取集合模型的副本，构建条件时会修改其中的占位符序号
+func (c *Collection) getModel(db *DB) (*model, error) {
+	m, err := db.getModel(c.modelType)
+	if err != nil {
+		return nil, err
+	}
+	m = m.clone()
+	m.index = 1
+	return m, nil
 }
 
 // Find 查找单个记录
 func (c *Collection) Find(ctx context.Context, where ...Condition) (interface{}, error) {
 	// 获取数据库和模型信息
 	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
+	m, err := c.getModel(db)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +87,7 @@ func (c *Collection) Find(ctx context.Context, where ...Condition) (interface{},
 	query := builder.String()
 
 	// 执行查询
-	rows, err := db.queryContext(ctx, query, args...)
+	rows, err := c.client.layer().queryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +116,7 @@ func (c *Collection) Find(ctx context.Context, where ...Condition) (interface{},
 		if fieldName, ok := m.colNameMap[col]; ok {
 			field := resultVal.FieldByName(fieldName)
 			if field.IsValid() && field.CanAddr() {
-				values[i] = field.Addr().Interface()
+				values[i] = m.scanDest(col, field.Addr().Interface())
 			} else {
 				// 如果找不到对应字段，使用一个占位符
 				var placeholder interface{}
@@ -96,103 +132,24 @@ func (c *Collection) Find(ctx context.Context, where ...Condition) (interface{},
 	if err := rows.Scan(values...); err != nil {
 		return nil, err
 	}
+	db.localizeTimes(resultVal)
 
+	if err := db.runRowHook(ctx, AfterFindEvent, m.table, result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // FindAll 查找所有匹配的记录
 func (c *Collection) FindAll(ctx context.Context, where ...Condition) ([]interface{}, error) {
-	// 获取数据库和模型信息
-	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
-	if err != nil {
-		return nil, err
-	}
-
-	// 手动构建SQL
-	builder := &strings.Builder{}
-	args := make([]any, 0)
-
-	builder.WriteString("SELECT * FROM ")
-	builder.WriteString(db.dialect.Quote(m.table))
-
-	if len(where) > 0 {
-		builder.WriteString(" WHERE ")
-		for i, cond := range where {
-			if pred, ok := cond.(*Predicate); ok {
-				pred.model = m
-			}
-			cond.Build(builder, &args)
-			if i < len(where)-1 {
-				builder.WriteString(" AND ")
-			}
-		}
-	}
-
-	builder.WriteString(";")
-	query := builder.String()
-
-	// 执行查询
-	rows, err := db.queryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	// 获取列信息
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
-
-	// 准备结果集
-	var results []interface{}
-	modelType := reflect.TypeOf(c.modelType).Elem()
-
-	// 处理每一行数据
-	for rows.Next() {
-		// 创建新的结构体实例
-		result := reflect.New(modelType).Interface()
-		resultVal := reflect.ValueOf(result).Elem()
-
-		// 准备扫描目标
-		values := make([]interface{}, len(cols))
-		for i, col := range cols {
-			if fieldName, ok := m.colNameMap[col]; ok {
-				field := resultVal.FieldByName(fieldName)
-				if field.IsValid() && field.CanAddr() {
-					values[i] = field.Addr().Interface()
-				} else {
-					var placeholder interface{}
-					values[i] = &placeholder
-				}
-			} else {
-				var placeholder interface{}
-				values[i] = &placeholder
-			}
-		}
-
-		// 扫描数据
-		if err := rows.Scan(values...); err != nil {
-			return nil, err
-		}
-
-		results = append(results, result)
-	}
-
-	// 检查行处理错误
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return c.FindWithOptions(ctx, FindOptions{}, where...)
 }
 
 // Insert 插入记录
 func (c *Collection) Insert(ctx context.Context, model interface{}) (Result, error) {
 	// 获取数据库和模型信息
 	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
+	m, err := c.getModel(db)
 	if err != nil {
 		return Result{}, err
 	}
@@ -207,6 +164,11 @@ func (c *Collection) Insert(ctx context.Context, model interface{}) (Result, err
 		return Result{}, fmt.Errorf("model type mismatch: expected %s, got %s", modelType.Name(), inputType.Name())
 	}
 
+	// 钩子在生成参数之前执行，修改的字段会被写入
+	if err := db.runRowHook(ctx, BeforeInsertEvent, m.table, model); err != nil {
+		return Result{}, err
+	}
+
 	// 构建插入SQL
 	builder := &strings.Builder{}
 	args := make([]any, 0)
@@ -242,7 +204,7 @@ func (c *Collection) Insert(ctx context.Context, model interface{}) (Result, err
 		// 获取字段值
 		fieldVal := modelVal.FieldByName(fieldName)
 		if fieldVal.IsValid() {
-			args = append(args, fieldVal.Interface())
+			args = append(args, fieldArg(db.dialect, m.fieldsMap[fieldName], fieldVal.Interface()))
 		} else {
 			args = append(args, nil)
 		}
@@ -250,15 +212,22 @@ func (c *Collection) Insert(ctx context.Context, model interface{}) (Result, err
 	builder.WriteString(");")
 
 	// 执行插入
-	result, err := db.execContext(ctx, builder.String(), args...)
-	return Result{res: result}, err
+	result, err := c.client.layer().execContext(ctx, builder.String(), args...)
+	if err != nil {
+		return Result{res: result}, err
+	}
+	invalidateAfterWrite(ctx, c.client.layer(), m.table, c.invalidation, c.invalidateTags)
+	if err = db.runRowHook(ctx, AfterInsertEvent, m.table, model); err != nil {
+		return Result{res: result}, err
+	}
+	return Result{res: result}, nil
 }
 
 // Update 更新记录
 func (c *Collection) Update(ctx context.Context, update map[string]interface{}, where ...Condition) (Result, error) {
 	// 获取数据库和模型信息
 	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
+	m, err := c.getModel(db)
 	if err != nil {
 		return Result{}, err
 	}
@@ -293,7 +262,7 @@ func (c *Collection) Update(ctx context.Context, update map[string]interface{},
 		builder.WriteString(db.dialect.Quote(field.colName))
 		builder.WriteString(" = ")
 		builder.WriteString(db.dialect.Placeholder(i + 1))
-		args = append(args, value)
+		args = append(args, fieldArg(db.dialect, field, value))
 
 		if i < len(update)-1 {
 			builder.WriteString(", ")
@@ -301,7 +270,8 @@ func (c *Collection) Update(ctx context.Context, update map[string]interface{},
 		i++
 	}
 
-	// 构建WHERE部分
+	// 构建WHERE部分，条件的占位符接在SET之后编号
+	m.index = len(args) + 1
 	if len(where) > 0 {
 		builder.WriteString(" WHERE ")
 		for i, cond := range where {
@@ -316,17 +286,28 @@ func (c *Collection) Update(ctx context.Context, update map[string]interface{},
 	}
 
 	builder.WriteString(";")
+	q := &Query{SQL: builder.String(), Args: args}
+	if err := db.runHooks(ctx, &HookContext{Event: BeforeUpdateEvent, Table: m.table, Query: q}); err != nil {
+		return Result{}, err
+	}
 
 	// 执行更新
-	result, err := db.execContext(ctx, builder.String(), args...)
-	return Result{res: result}, err
+	result, err := c.client.layer().execContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return Result{res: result}, err
+	}
+	invalidateAfterWrite(ctx, c.client.layer(), m.table, c.invalidation, c.invalidateTags)
+	if err = db.runHooks(ctx, &HookContext{Event: AfterUpdateEvent, Table: m.table, Query: q}); err != nil {
+		return Result{res: result}, err
+	}
+	return Result{res: result}, nil
 }
 
 // Delete 删除记录
 func (c *Collection) Delete(ctx context.Context, where ...Condition) (Result, error) {
 	// 获取数据库和模型信息
 	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
+	m, err := c.getModel(db)
 	if err != nil {
 		return Result{}, err
 	}
@@ -353,21 +334,58 @@ func (c *Collection) Delete(ctx context.Context, where ...Condition) (Result, er
 	}
 
 	builder.WriteString(";")
+	q := &Query{SQL: builder.String(), Args: args}
+	if err := db.runHooks(ctx, &HookContext{Event: BeforeDeleteEvent, Table: m.table, Query: q}); err != nil {
+		return Result{}, err
+	}
 
 	// 执行删除
-	result, err := db.execContext(ctx, builder.String(), args...)
-	return Result{res: result}, err
+	result, err := c.client.layer().execContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return Result{res: result}, err
+	}
+	invalidateAfterWrite(ctx, c.client.layer(), m.table, c.invalidation, c.invalidateTags)
+	if err = db.runHooks(ctx, &HookContext{Event: AfterDeleteEvent, Table: m.table, Query: q}); err != nil {
+		return Result{res: result}, err
+	}
+	return Result{res: result}, nil
 }
 
 // FindWithOptions 使用选项查找记录
 func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, where ...Condition) ([]interface{}, error) {
 	// 获取数据库和模型信息
 	db := c.client.GetDB()
-	m, err := db.getModel(c.modelType)
+	m, err := c.getModel(db)
 	if err != nil {
 		return nil, err
 	}
 
+	q, err := c.buildFind(db, m, opts, where)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryAll(ctx, db, m, q)
+}
+
+// buildWhere 构建WHERE部分
+func buildWhere(builder *strings.Builder, args *[]any, m *model, where []Condition) {
+	if len(where) == 0 {
+		return
+	}
+	builder.WriteString(" WHERE ")
+	for i, cond := range where {
+		if pred, ok := cond.(*Predicate); ok {
+			pred.model = m
+		}
+		cond.Build(builder, args)
+		if i < len(where)-1 {
+			builder.WriteString(" AND ")
+		}
+	}
+}
+
+// buildFind 构建查询语句
+func (c *Collection) buildFind(db *DB, m *model, opts FindOptions, where []Condition) (*Query, error) {
 	// 手动构建SQL
 	builder := &strings.Builder{}
 	args := make([]any, 0)
@@ -376,18 +394,7 @@ func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, wher
 	builder.WriteString(db.dialect.Quote(m.table))
 
 	// 构建WHERE部分
-	if len(where) > 0 {
-		builder.WriteString(" WHERE ")
-		for i, cond := range where {
-			if pred, ok := cond.(*Predicate); ok {
-				pred.model = m
-			}
-			cond.Build(builder, &args)
-			if i < len(where)-1 {
-				builder.WriteString(" AND ")
-			}
-		}
-	}
+	buildWhere(builder, &args, m, where)
 
 	// 添加ORDER BY
 	if len(opts.OrderBy) > 0 {
@@ -426,10 +433,13 @@ func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, wher
 	}
 
 	builder.WriteString(";")
-	query := builder.String()
+	return &Query{SQL: builder.String(), Args: args}, nil
+}
 
+// queryAll 执行查询并扫描所有行
+func (c *Collection) queryAll(ctx context.Context, db *DB, m *model, q *Query) ([]interface{}, error) {
 	// 执行查询
-	rows, err := db.queryContext(ctx, query, args...)
+	rows, err := c.client.layer().queryContext(ctx, q.SQL, q.Args...)
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +467,7 @@ func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, wher
 			if fieldName, ok := m.colNameMap[col]; ok {
 				field := resultVal.FieldByName(fieldName)
 				if field.IsValid() && field.CanAddr() {
-					values[i] = field.Addr().Interface()
+					values[i] = m.scanDest(col, field.Addr().Interface())
 				} else {
 					var placeholder interface{}
 					values[i] = &placeholder
@@ -472,6 +482,10 @@ func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, wher
 		if err := rows.Scan(values...); err != nil {
 			return nil, err
 		}
+		db.localizeTimes(resultVal)
+		if err := db.runRowHook(ctx, AfterFindEvent, m.table, result); err != nil {
+			return nil, err
+		}
 
 		results = append(results, result)
 	}
@@ -482,4 +496,74 @@ func (c *Collection) FindWithOptions(ctx context.Context, opts FindOptions, wher
 	}
 
 	return results, nil
-}
\ No newline at end of file
+}
+
+// Count 统计匹配的记录数
+func (c *Collection) Count(ctx context.Context, where ...Condition) (int64, error) {
+	db := c.client.GetDB()
+	m, err := c.getModel(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	err = c.queryAggregate(ctx, db, c.buildAggregate(db, m, "COUNT(*)", where), &n)
+	return n, err
+}
+
+// Sum 对字段求和，没有匹配的记录时返回0
+func (c *Collection) Sum(ctx context.Context, field string, where ...Condition) (float64, error) {
+	db := c.client.GetDB()
+	m, err := c.getModel(db)
+	if err != nil {
+		return 0, err
+	}
+
+	expr, err := sumExpr(db, m, field)
+	if err != nil {
+		return 0, err
+	}
+	var sum sql.NullFloat64
+	err = c.queryAggregate(ctx, db, c.buildAggregate(db, m, expr, where), &sum)
+	return sum.Float64, err
+}
+
+// sumExpr 字段求和的表达式
+func sumExpr(db *DB, m *model, field string) (string, error) {
+	f, ok := m.fieldsMap[field]
+	if !ok {
+		return "", fmt.Errorf("unknown field: %s", field)
+	}
+	return "SUM(" + db.dialect.Quote(f.colName) + ")", nil
+}
+
+// buildAggregate 构建聚合查询语句
+func (c *Collection) buildAggregate(db *DB, m *model, expr string, where []Condition) *Query {
+	builder := &strings.Builder{}
+	args := make([]any, 0)
+
+	builder.WriteString("SELECT ")
+	builder.WriteString(expr)
+	builder.WriteString(" FROM ")
+	builder.WriteString(db.dialect.Quote(m.table))
+	buildWhere(builder, &args, m, where)
+	builder.WriteString(";")
+	return &Query{SQL: builder.String(), Args: args}
+}
+
+// queryAggregate 执行聚合查询，结果扫描到 dst
+func (c *Collection) queryAggregate(ctx context.Context, db *DB, q *Query, dst any) error {
+	rows, err := c.client.layer().queryContext(ctx, q.SQL, q.Args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dst)
+}
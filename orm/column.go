@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
@@ -169,6 +170,11 @@ func (c *Column) NotNull() *Predicate {
 	}
 }
 
+// IsNotNull 与 NotNull 相同
+func (c *Column) IsNotNull() *Predicate {
+	return c.NotNull()
+}
+
 func (c *Column) Gte(arg any) *Predicate {
 	return &Predicate{
 		left:  c,
@@ -209,20 +215,48 @@ func (c *Column) NotLike(pattern string) *Predicate {
 	}
 }
 
+// In 生成 IN 条件，参数可以是多个值、一个切片或者一个子查询：
+//
+//	Col("ID").In(1, 2, 3)
+//	Col("ID").In(ids)
+//	Col("ID").In(RegisterSelector[Order](db).Select(Col("UserID")).Where(Col("Amount").Gt(100)))
+//
+// 值为空时生成恒为假的条件
 func (c *Column) In(vals ...any) *Predicate {
 	return &Predicate{
 		left:  c,
 		op:    opIN,
-		right: valueOf(vals),
+		right: inValues(vals),
 	}
 }
 
+// NotIn 生成 NOT IN 条件，参数与 In 相同，值为空时生成恒为真的条件
 func (c *Column) NotIn(vals ...any) *Predicate {
 	return &Predicate{
 		left:  c,
 		op:    opNOTIN,
-		right: valueOf(vals),
+		right: inValues(vals),
+	}
+}
+
+// inValues 将 In 的参数转换为右表达式，唯一的参数是子查询时直接使用，是切片时展开
+func inValues(vals []any) Expression {
+	if len(vals) != 1 {
+		return valueOf(vals)
 	}
+	if sq, ok := vals[0].(subQuery); ok {
+		return &subQueryExpr{build: sq.subQuery}
+	}
+	rv := reflect.ValueOf(vals[0])
+	// []byte 是单个值而不是值的列表
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return valueOf(vals)
+	}
+	flat := make([]any, rv.Len())
+	for i := range flat {
+		flat[i] = rv.Index(i).Interface()
+	}
+	return valueOf(flat)
 }
 
 func (c *Column) Between(start, end any) *Predicate {
@@ -246,4 +280,35 @@ func NOT(pred *Predicate) *Predicate {
 		op:    opNOT,
 		right: pred,
 	}
-}
\ No newline at end of file
+}
+
+// And 使用 AND 组合多个条件，只有一个条件时直接返回该条件
+//
+//	And(Col("Age").Gt(18), Or(Col("Role").Eq("admin"), Col("Role").Eq("owner")))
+//	// `age` > ? AND (`role` = ? OR `role` = ?)
+func And(preds ...*Predicate) *Predicate {
+	return combine(opAND, preds)
+}
+
+// Or 使用 OR 组合多个条件，生成的条件带有括号，可以直接与其他条件一起传给 Where
+//
+//	Where(Col("Status").Eq("active"), Or(Col("Age").Lt(18), Col("Age").Gt(60)))
+//	// WHERE `status` = ? AND (`age` < ? OR `age` > ?)
+func Or(preds ...*Predicate) *Predicate {
+	return combine(opOR, preds)
+}
+
+func combine(op Op, preds []*Predicate) *Predicate {
+	if len(preds) == 0 {
+		panic("orm: " + op.Keyword + " requires at least one predicate")
+	}
+	p := preds[0]
+	for _, right := range preds[1:] {
+		p = &Predicate{
+			left:  p,
+			op:    op,
+			right: right,
+		}
+	}
+	return p
+}
@@ -0,0 +1,103 @@
+package orm
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryCommentFunc 根据context返回需要附加到SQL上的注释标签，例如 route、request_id
+type QueryCommentFunc func(ctx context.Context) map[string]string
+
+// WithQueryComment 为生成的SQL附加sqlcommenter格式的注释，
+// 例如 SELECT * FROM `user` /*request_id='abc',route='%2Fusers'*/;
+// 便于DBA通过慢查询日志追溯到具体的路由和请求
+func WithQueryComment(fn QueryCommentFunc) DBOption {
+	return func(db *DB) error {
+		db.queryComment = fn
+		return nil
+	}
+}
+
+// commentTagsKey 注释标签在context中的键
+type commentTagsKey struct{}
+
+// WithCommentTags 在context中附加注释标签，已存在的同名标签会被覆盖
+func WithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, len(tags))
+	for k, v := range CommentTagsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, commentTagsKey{}, merged)
+}
+
+// CommentTagsFromContext 获取context中的注释标签
+// 可直接作为 WithQueryComment 的参数使用
+func CommentTagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(commentTagsKey{}).(map[string]string)
+	return tags
+}
+
+// commentSQL 为SQL附加注释，已包含注释的SQL保持不变
+func (db *DB) commentSQL(ctx context.Context, query string) string {
+	if db.queryComment == nil || strings.Contains(query, "/*") || strings.Contains(query, "--") {
+		return query
+	}
+	tags := db.queryComment(ctx)
+	if len(tags) == 0 {
+		return query
+	}
+
+	comment := formatSQLComment(tags)
+	if comment == "" {
+		return query
+	}
+
+	// 注释放在语句末尾的分号之前
+	trimmed := strings.TrimRight(query, " \t\n;")
+	if trimmed != query {
+		return trimmed + " " + comment + ";"
+	}
+	return query + " " + comment
+}
+
+// formatSQLComment 按sqlcommenter规范生成注释：键按字典序排列，键和值经过URL编码，值使用单引号包裹
+func formatSQLComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if k == "" || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(encodeCommentValue(k))
+		sb.WriteString("='")
+		sb.WriteString(encodeCommentValue(tags[k]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+	return sb.String()
+}
+
+// encodeCommentValue URL编码，空格编码为%20，单引号也会被编码因此无需额外转义
+func encodeCommentValue(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSQLComment(t *testing.T) {
+	testCases := []struct {
+		name string
+		tags map[string]string
+		want string
+	}{
+		{
+			name: "sorted and encoded",
+			tags: map[string]string{"route": "/users/:id", "request_id": "abc 123", "action": "it's"},
+			want: "/*action='it%27s',request_id='abc%20123',route='%2Fusers%2F%3Aid'*/",
+		},
+		{
+			name: "empty values skipped",
+			tags: map[string]string{"route": "", "app": "shop"},
+			want: "/*app='shop'*/",
+		},
+		{
+			name: "empty",
+			tags: map[string]string{"route": ""},
+			want: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatSQLComment(tc.tags))
+		})
+	}
+}
+
+func TestDB_CommentSQL(t *testing.T) {
+	db := &DB{queryComment: CommentTagsFromContext}
+	ctx := WithCommentTags(context.Background(), map[string]string{"route": "/users"})
+	ctx = WithCommentTags(ctx, map[string]string{"request_id": "1"})
+
+	assert.Equal(t, "SELECT 1 /*request_id='1',route='%2Fusers'*/;", db.commentSQL(ctx, "SELECT 1;"))
+	assert.Equal(t, "SELECT 1 /*request_id='1',route='%2Fusers'*/", db.commentSQL(ctx, "SELECT 1"))
+	assert.Equal(t, "SELECT 1 /* existing */;", db.commentSQL(ctx, "SELECT 1 /* existing */;"))
+	assert.Equal(t, "SELECT 1;", db.commentSQL(context.Background(), "SELECT 1;"))
+	assert.Equal(t, "SELECT 1;", (&DB{}).commentSQL(ctx, "SELECT 1;"))
+}
+
+func TestSelector_QueryComment(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql", WithQueryComment(func(ctx context.Context) map[string]string {
+		return map[string]string{"route": "/users/:id", "request_id": "req-1"}
+	}))
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT \\* FROM `test_model` WHERE `id` = \\? /\\*request_id='req-1',route='%2Fusers%2F%3Aid'\\*/;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom"))
+
+	res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(1)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Tom", res.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,9 +1,14 @@
 package orm
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+// placeholderPattern 匹配 PostgreSQL 风格的 $n 占位符
+var placeholderPattern = regexp.MustCompile(`\$[0-9]+`)
+
 type Condition interface {
 	Build(builder *strings.Builder, args *[]any)
 }
@@ -33,8 +38,33 @@ func (p *Predicate) buildExpr(expr Expression, builder *strings.Builder, args *[
 		builder.WriteString(p.model.dialect.Placeholder(p.model.index))
 		p.model.index++
 		*args = append(*args, e.val)
+	case *subQueryExpr:
+		q, err := e.build()
+		if err != nil {
+			panic(err)
+		}
+		builder.WriteByte('(')
+		builder.WriteString(rebasePlaceholders(p.model.dialect, strings.TrimSuffix(q.SQL, ";"), p.model.index-1))
+		builder.WriteByte(')')
+		p.model.index += len(q.Args)
+		*args = append(*args, q.Args...)
+	case RawExpr:
+		buildRaw(builder, p.model, e, args)
+	case *JSONPath:
+		e.build(builder, p.model, args)
+	case *arrayExpr:
+		e.build(builder, p.model, args)
+	case *MathExpr:
+		e.build(builder, p.model, args)
+	case *FuncExpr:
+		e.build(builder, p.model, args)
 	case *Predicate:
 		e.model = p.model
+		// OR 条件自带括号
+		if e.op == opOR {
+			e.Build(builder, args)
+			return
+		}
 		builder.WriteByte('(')
 		e.Build(builder, args)
 		builder.WriteByte(')')
@@ -47,6 +77,16 @@ func (p *Predicate) buildExpr(expr Expression, builder *strings.Builder, args *[
 
 func (p *Predicate) expr() {}
 
+// And 返回 p AND other
+func (p *Predicate) And(other *Predicate) *Predicate {
+	return And(p, other)
+}
+
+// Or 返回 (p OR other)
+func (p *Predicate) Or(other *Predicate) *Predicate {
+	return Or(p, other)
+}
+
 func (p *Predicate) Build(builder *strings.Builder, args *[]any) {
 	switch p.op.Type {
 	case OpUnary:
@@ -65,11 +105,21 @@ func (p *Predicate) Build(builder *strings.Builder, args *[]any) {
 		}
 
 	case OpBinary:
+		if p.op == opAND || p.op == opOR {
+			p.buildLogical(builder, args)
+			return
+		}
+
 		// 二元运算符: =, >, < 等
 		if p.left == nil {
 			panic("left expression cannot be nil for binary operator")
 		}
 
+		if p.op == opIN || p.op == opNOTIN {
+			p.buildIn(builder, args)
+			return
+		}
+
 		// 处理左表达式
 		p.buildExpr(p.left, builder, args)
 
@@ -78,25 +128,6 @@ func (p *Predicate) Build(builder *strings.Builder, args *[]any) {
 		builder.WriteString(p.op.Keyword)
 		builder.WriteByte(' ')
 
-		// 特殊处理 IN/NOT IN
-		if p.op == opIN || p.op == opNOTIN {
-			builder.WriteByte('(')
-			if val, ok := p.right.(*Value); ok {
-				if vals, ok := val.val.([]any); ok {
-					for i, v := range vals {
-						builder.WriteString(p.model.dialect.Placeholder(p.model.index))
-						p.model.index++
-						*args = append(*args, v)
-						if i < len(vals)-1 {
-							builder.WriteString(", ")
-						}
-					}
-				}
-			}
-			builder.WriteByte(')')
-			return
-		}
-
 		// 处理右表达式
 		p.buildExpr(p.right, builder, args)
 
@@ -125,3 +156,93 @@ func (p *Predicate) Build(builder *strings.Builder, args *[]any) {
 		panic("invalid operator type")
 	}
 }
+
+// buildIn 构建 IN/NOT IN，右侧为值列表时每个值一个占位符，为子查询时内嵌子查询
+func (p *Predicate) buildIn(builder *strings.Builder, args *[]any) {
+	if _, ok := p.right.(*subQueryExpr); ok {
+		p.buildExpr(p.left, builder, args)
+		builder.WriteString(" " + p.op.Keyword + " ")
+		p.buildExpr(p.right, builder, args)
+		return
+	}
+
+	var vals []any
+	if val, ok := p.right.(*Value); ok {
+		vals, _ = val.val.([]any)
+	}
+	// IN () 不是合法的SQL，空列表的 IN 恒为假，NOT IN 恒为真
+	if len(vals) == 0 {
+		if p.op == opIN {
+			builder.WriteString("1 = 0")
+		} else {
+			builder.WriteString("1 = 1")
+		}
+		return
+	}
+
+	p.buildExpr(p.left, builder, args)
+	builder.WriteString(" " + p.op.Keyword + " (")
+	for i, v := range vals {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(p.model.dialect.Placeholder(p.model.index))
+		p.model.index++
+		*args = append(*args, v)
+	}
+	builder.WriteByte(')')
+}
+
+// rebasePlaceholders 子查询的占位符从1开始编号，对于 $n 这样带序号的占位符，
+// 需要加上外层查询中已有的参数个数 offset
+func rebasePlaceholders(dialect Dialect, sql string, offset int) string {
+	if offset == 0 || dialect.Placeholder(1) == dialect.Placeholder(2) {
+		return sql
+	}
+	return placeholderPattern.ReplaceAllStringFunc(sql, func(ph string) string {
+		idx, err := strconv.Atoi(ph[1:])
+		if err != nil {
+			return ph
+		}
+		return dialect.Placeholder(idx + offset)
+	})
+}
+
+// buildLogical 构建 AND/OR，相同运算符的嵌套条件展开为一层；
+// OR 整体加上括号，这样与 Where 中的其他条件用 AND 连接时优先级正确
+func (p *Predicate) buildLogical(builder *strings.Builder, args *[]any) {
+	if p.op == opOR {
+		builder.WriteByte('(')
+	}
+	p.buildOperands(builder, args)
+	if p.op == opOR {
+		builder.WriteByte(')')
+	}
+}
+
+func (p *Predicate) buildOperands(builder *strings.Builder, args *[]any) {
+	p.buildOperand(p.left, builder, args)
+	builder.WriteString(" " + p.op.Keyword + " ")
+	p.buildOperand(p.right, builder, args)
+}
+
+func (p *Predicate) buildOperand(expr Expression, builder *strings.Builder, args *[]any) {
+	sub, ok := expr.(*Predicate)
+	if !ok {
+		p.buildExpr(expr, builder, args)
+		return
+	}
+	sub.model = p.model
+	if sub.op == p.op {
+		sub.buildOperands(builder, args)
+		return
+	}
+	// OR 中的 AND 条件加上括号，便于阅读
+	if sub.op == opAND {
+		builder.WriteByte('(')
+		sub.Build(builder, args)
+		builder.WriteByte(')')
+		return
+	}
+	sub.Build(builder, args)
+}
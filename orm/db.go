@@ -4,27 +4,53 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/clock"
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 )
 
 // DB 是orm用来管理数据库连接和缓存之类持久化内容的结构体
 type DB struct {
-	model           *modelCache      // 元数据缓存
-	sqlDB           *sql.DB          // 数据库连接
-	dialect         Dialect          // 数据库方言
-	handler         Handler          // 处理器
-	middlewares     []Middleware     // 中间件
-	pooledDB        *PooledDB        // 连接池封装
-	schemaManager   *SchemaManager   // 架构管理器
-	shardingManager *ShardingManager // 分片管理器
-	isSharded       bool             // 是否启用分片
-	cacheManager    *CacheManager    // 缓存管理器
+	model           *modelCache              // 元数据缓存
+	sqlDB           *sql.DB                  // 数据库连接
+	dialect         Dialect                  // 数据库方言
+	handler         Handler                  // 处理器
+	middlewares     []Middleware             // 中间件
+	pooledDB        *PooledDB                // 连接池封装
+	schemaManager   *SchemaManager           // 架构管理器
+	shardingManager *ShardingManager         // 分片管理器
+	isSharded       bool                     // 是否启用分片
+	cacheManager    *CacheManager            // 缓存管理器
+	masker          *Masker                  // 读取结果脱敏管道
+	queryComment    QueryCommentFunc         // SQL注释标签生成函数
+	timeLoc         *time.Location           // 读取时间字段时使用的时区，设置后写入的时间统一为UTC
+	stmtCache       *stmtCache               // 预编译语句缓存
+	hooks           map[HookEvent][]HookFunc // 全局生命周期钩子
+	queryLog        *queryLog                // SQL日志
+	queryKill       *queryKiller             // 查询取消的统计和终止
+	clock           clock.Clock              // 时间来源，nil 时使用系统时间
 }
 
 // queryContext 查询
-func (db *DB) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+func (db *DB) queryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { err = translateError(err) }()
+	query = db.commentSQL(ctx, query)
+	query, tag := db.killTag(ctx, query)
+	args = db.normalizeArgs(args)
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	if db.queryKill != nil {
+		defer func(start time.Time) { db.queryKill.done(ctx, db, tag, start, err) }(time.Now())
+	}
+	if db.queryLog != nil {
+		start := time.Now()
+		defer func() { db.logQuery(ctx, query, args, start, nil, err) }()
+	}
+
 	if db.pooledDB != nil && db.pooledDB.IsPooled() {
 		// 从池中获取连接
 		sqlDB, conn, err := db.getConn(ctx)
@@ -44,7 +70,23 @@ func (db *DB) queryContext(ctx context.Context, query string, args ...interface{
 	return db.sqlDB.QueryContext(ctx, query, args...)
 }
 
-func (db *DB) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+func (db *DB) execContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	defer func() { err = translateError(err) }()
+	query = db.commentSQL(ctx, query)
+	query, tag := db.killTag(ctx, query)
+	args = db.normalizeArgs(args)
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	if db.queryKill != nil {
+		defer func(start time.Time) { db.queryKill.done(ctx, db, tag, start, err) }(time.Now())
+	}
+	if db.queryLog != nil {
+		start := time.Now()
+		defer func() { db.logQuery(ctx, query, args, start, res, err) }()
+	}
+
 	if db.pooledDB != nil && db.pooledDB.IsPooled() {
 		// 从池中获取连接
 		sqlDB, conn, err := db.getConn(ctx)
@@ -64,18 +106,79 @@ func (db *DB) execContext(ctx context.Context, query string, args ...interface{}
 	return db.sqlDB.ExecContext(ctx, query, args...)
 }
 
+// prepareContext 预编译语句，连接池中的连接共用同一个 sql.DB，语句可以在任意连接上执行
+func (db *DB) prepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return db.sqlDB.PrepareContext(ctx, db.commentSQL(ctx, query))
+}
+
+// queryStmtContext 使用预编译语句查询，query 为语句的SQL，用于记录日志
+func (db *DB) queryStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { err = translateError(err) }()
+	args = db.normalizeArgs(args)
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	if db.queryKill != nil {
+		// 预编译语句的SQL无法附加标记，只统计
+		defer func(start time.Time) { db.queryKill.done(ctx, db, "", start, err) }(time.Now())
+	}
+	if db.queryLog != nil {
+		start := time.Now()
+		defer func() { db.logQuery(ctx, query, args, start, nil, err) }()
+	}
+
+	if db.pooledDB != nil && db.pooledDB.IsPooled() {
+		// 占用连接池中的连接，保证连接池的统计和限流生效
+		_, conn, err := db.getConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := stmt.QueryContext(ctx, args...)
+		db.putConn(conn, err)
+		return rows, err
+	}
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+// execStmtContext 使用预编译语句执行命令，query 为语句的SQL，用于记录日志
+func (db *DB) execStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (res sql.Result, err error) {
+	defer func() { err = translateError(err) }()
+	args = db.normalizeArgs(args)
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	if db.queryKill != nil {
+		// 预编译语句的SQL无法附加标记，只统计
+		defer func(start time.Time) { db.queryKill.done(ctx, db, "", start, err) }(time.Now())
+	}
+	if db.queryLog != nil {
+		start := time.Now()
+		defer func() { db.logQuery(ctx, query, args, start, res, err) }()
+	}
+
+	if db.pooledDB != nil && db.pooledDB.IsPooled() {
+		_, conn, err := db.getConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res, err := stmt.ExecContext(ctx, args...)
+		db.putConn(conn, err)
+		return res, err
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
 // DBOption 定义配置项
 type DBOption func(*DB) error
 
 // getModel 获取元数据
 func (db *DB) getModel(val any) (*model, error) {
-	m, err := db.model.get(val)
-	if err != nil {
-		return nil, err
-	}
-	// 设置方言
-	m.SetDialect(db.dialect)
-	return m, nil
+	// 方言在解析模型时设置，缓存的模型会被多个构造器并发读取，这里不能修改
+	return db.model.get(val)
 }
 
 // getDB 获取db对象
@@ -95,6 +198,7 @@ func Open(db *sql.DB, dialectName string, opts ...DBOption) (*DB, error) {
 		sqlDB:   db,
 		dialect: dialect,
 	}
+	d.model.dialect = dialect
 
 	// 初始化核心处理器
 	d.handler = &CoreHandler{db: d}
@@ -148,6 +252,11 @@ func (db *DB) Close() error {
 		}
 	}
 
+	// 关闭缓存的预编译语句
+	if db.stmtCache != nil {
+		db.stmtCache.close()
+	}
+
 	// 默认情况，直接关闭 sqlDB
 	if db.sqlDB != nil {
 		if err := db.sqlDB.Close(); err != nil {
@@ -208,8 +317,12 @@ func (db *DB) BeginTx(ctx context.Context, opt *sql.TxOptions) (*Tx, error) {
 	}, nil
 }
 
-// Tx 事务闭包处理
+// Tx 事务闭包处理。ctx 通过 WithTx 携带了该 DB 上的事务时，在该事务中通过保存点开启嵌套事务
 func (db *DB) Tx(ctx context.Context, fn func(tx *Tx) error, opt *sql.TxOptions) (err error) {
+	if outer := txFromContext(ctx, db); outer != nil {
+		return outer.Tx(ctx, fn, opt)
+	}
+
 	var tx *Tx
 	tx, err = db.BeginTx(ctx, opt)
 	if err != nil {
@@ -347,6 +460,21 @@ func (db *DB) MigrateModel(ctx context.Context, model interface{}, opts ...Migra
 	return db.schemaManager.MigrateModel(ctx, model, opts...)
 }
 
+// PlanMigration 返回迁移单个模型的计划，不执行任何变更
+func (db *DB) PlanMigration(ctx context.Context, model interface{}, opts ...MigrateOption) (*SchemaPlan, error) {
+	return db.schemaManager.PlanModel(ctx, model, opts...)
+}
+
+// Tables 返回数据库中所有表的名称，schema 为空时使用当前数据库
+func (db *DB) Tables(ctx context.Context, schema string) ([]string, error) {
+	return db.schemaManager.Tables(ctx, schema)
+}
+
+// TableSchema 读取数据库中已存在表的列、索引和外键
+func (db *DB) TableSchema(ctx context.Context, schema, table string) (*TableSchema, error) {
+	return db.schemaManager.TableSchema(ctx, schema, table)
+}
+
 // RegisterModel 注册模型的同时提供自动迁移选项
 func (db *DB) RegisterModel(name string, model interface{}, autoMigrate bool, opts ...MigrateOption) error {
 	// 注册模型
@@ -414,16 +542,13 @@ func WithSharding(router ShardingRouter) DBOption {
 	}
 }
 
-// ExecuteOnAllShards 在所有分片上执行操作
-func (db *DB) ExecuteOnAllShards(ctx context.Context, fn func(db *DB) error) []error {
+// ExecuteOnAllShards 在所有分片上执行操作，没有启用分片时在当前数据库上执行
+func (db *DB) ExecuteOnAllShards(ctx context.Context, fn func(ctx context.Context, db *DB) error, opts ...ShardExecOption) ShardResults {
 	if !db.IsSharded() {
-		err := fn(db)
-		if err != nil {
-			return []error{err}
-		}
-		return nil
+		start := time.Now()
+		err := fn(ctx, db)
+		return ShardResults{{Shard: "default", Err: err, Duration: time.Since(start)}}
 	}
 
-	shardingDB := NewShardingDB(db, db.shardingManager.GetRouter())
-	return shardingDB.ExecuteOnAllShards(ctx, fn)
+	return db.shardingManager.executeOnAllShards(ctx, fn, opts)
 }
\ No newline at end of file
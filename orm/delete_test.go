@@ -2,6 +2,10 @@ package orm
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"time"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -166,4 +170,54 @@ func TestDeleterWithTag(t *testing.T) {
 			assert.Equal(t, tc.wantQuery, query)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestDeleter_ExecInBatches(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	query := regexp.QuoteMeta("DELETE FROM `test_model` WHERE `name` = ? ORDER BY `id` LIMIT 2;")
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var progress []int64
+	n, err := RegisterDeleter[TestModel](db).Delete().
+		Where(Col("Name").Eq("tmp")).
+		OrderBy(Asc(Col("ID"))).
+		ExecInBatches(context.Background(), 2,
+			WithBatchPause(time.Millisecond),
+			WithBatchProgress(func(deleted int64) { progress = append(progress, deleted) }))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.Equal(t, []int64{2, 4, 5}, progress)
+
+	// 出错时返回已经删除的行数
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnError(errors.New("lock wait timeout"))
+	n, err = RegisterDeleter[TestModel](db).Delete().
+		Where(Col("Name").Eq("tmp")).
+		OrderBy(Asc(Col("ID"))).
+		ExecInBatches(context.Background(), 2)
+	assert.EqualError(t, err, "lock wait timeout")
+	assert.Equal(t, int64(2), n)
+
+	// 等待下一批时 context 被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	mock.ExpectExec(query).WithArgs("tmp").WillReturnResult(sqlmock.NewResult(0, 2))
+	n, err = RegisterDeleter[TestModel](db).Delete().
+		Where(Col("Name").Eq("tmp")).
+		OrderBy(Asc(Col("ID"))).
+		ExecInBatches(ctx, 2, WithBatchProgress(func(int64) { cancel() }), WithBatchPause(time.Hour))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = RegisterDeleter[TestModel](db).Delete().ExecInBatches(context.Background(), 0)
+	assert.Error(t, err)
+	_, err = RegisterDeleter[TestModel](db).Delete().Offset(1).ExecInBatches(context.Background(), 10)
+	assert.ErrorIs(t, err, ErrBatchOffset)
+}
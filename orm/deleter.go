@@ -2,9 +2,10 @@ package orm
 
 import (
 	"context"
+	"errors"
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
-	"strconv"
 	"strings"
+	"time"
 )
 
 type Deleter[T any] struct {
@@ -14,24 +15,38 @@ type Deleter[T any] struct {
 	layer   Layer
 	dialect Dialect
 
+	whereAt int // WHERE 子句开始的位置
+	limit   int // 删除的最大行数，小于0表示不限制
+	offset  int
+	ordered bool // 是否包含 ORDER BY 子句
+
+	returning    []string // RETURNING 的字段名
+	hasReturning bool
+
 	// 缓存相关字段
-	invalidateCache bool     // 是否使缓存失效
-	invalidateTags  []string // 要失效的缓存标签
+	invalidation   cacheInvalidation // 执行成功后使缓存失效的方式，默认在模型启用缓存时自动失效
+	invalidateTags []string          // 要失效的缓存标签
 }
 
-// WithInvalidateCache 设置是否使相关缓存失效
+// WithInvalidateCache 执行成功后总是使相关缓存失效，即使模型关闭了自动失效
 func (d *Deleter[T]) WithInvalidateCache() *Deleter[T] {
-	d.invalidateCache = true
+	d.invalidation = invalidateAlways
 	return d
 }
 
 // WithInvalidateTags 设置要使失效的缓存标签
 func (d *Deleter[T]) WithInvalidateTags(tags ...string) *Deleter[T] {
-	d.invalidateCache = true
+	d.invalidation = invalidateAlways
 	d.invalidateTags = tags
 	return d
 }
 
+// WithoutInvalidateCache 执行成功后不使缓存失效
+func (d *Deleter[T]) WithoutInvalidateCache() *Deleter[T] {
+	d.invalidation = invalidateNever
+	return d
+}
+
 func RegisterDeleter[T any](layer Layer) *Deleter[T] {
 	var val T
 
@@ -51,6 +66,17 @@ func RegisterDeleter[T any](layer Layer) *Deleter[T] {
 		}
 	}
 
+	// 缓存的模型被多个构造器共享，每个构造器使用自己的副本
+	m = m.clone()
+
+	// 处理表名
+	if tablename, ok := any(val).(TableNamer); ok {
+		m.table = tablename.TableName()
+	}
+	if tablename, ok := any(&val).(TableNamer); ok {
+		m.table = tablename.TableName()
+	}
+
 	dialect := layer.getDB().dialect
 	m.dialect = dialect
 	m.index = 1
@@ -60,6 +86,7 @@ func RegisterDeleter[T any](layer Layer) *Deleter[T] {
 		model:   m,
 		dialect: dialect,
 		layer:   layer,
+		limit:   -1,
 	}
 }
 
@@ -88,9 +115,8 @@ func (d *Deleter[T]) Delete(cols ...Selectable) *Deleter[T] {
 			}
 			d.builder.WriteByte(' ')
 		case RawExpr:
-			col.Build(d.builder)
+			buildRaw(d.builder, d.model, col, &d.args)
 			d.builder.WriteByte(' ')
-			d.args = append(d.args, col.args...)
 		default:
 			panic(ferr.ErrInvalidSelectable(col))
 		}
@@ -102,6 +128,7 @@ func (d *Deleter[T]) Delete(cols ...Selectable) *Deleter[T] {
 }
 
 func (d *Deleter[T]) Where(conditions ...Condition) *Deleter[T] {
+	d.whereAt = d.builder.Len()
 	d.builder.WriteString(" WHERE ")
 	for i := 0; i < len(conditions); i++ {
 		if pred, ok := conditions[i].(*Predicate); ok {
@@ -115,17 +142,56 @@ func (d *Deleter[T]) Where(conditions ...Condition) *Deleter[T] {
 	return d
 }
 
+// OrderBy 指定删除的顺序，通常与 Limit 一起使用，例如只删除最早的 n 行
+// PostgreSQL 和 SQLite 不支持 DELETE ... ORDER BY，与 Limit 一样改写为按行标识限定的子查询
+func (d *Deleter[T]) OrderBy(orders ...OrderBy) *Deleter[T] {
+	if len(orders) == 0 {
+		return d
+	}
+	if d.whereAt == 0 {
+		d.whereAt = d.builder.Len()
+	}
+	writeOrderBy(d.builder, d.model, &d.args, orders)
+	d.ordered = true
+	return d
+}
+
+// Limit 限制删除的行数，PostgreSQL 和 SQLite 不支持 DELETE ... LIMIT，改写为按行标识限定的子查询
 func (d *Deleter[T]) Limit(num int) *Deleter[T] {
-	d.builder.WriteString(" LIMIT " + strconv.Itoa(num))
+	d.limit = num
 	return d
 }
 
+// Offset 跳过前 num 行，MySQL 不支持在 DELETE 中使用，Build 时返回 ErrOffsetNotSupported
 func (d *Deleter[T]) Offset(num int) *Deleter[T] {
-	d.builder.WriteString(" OFFSET " + strconv.Itoa(num))
+	d.offset = num
+	return d
+}
+
+// Returning 添加 RETURNING 子句，通过 ExecReturning 获取被删除的行，cols 为字段名，为空时返回所有列
+// 只有支持 RETURNING 的方言（如PostgreSQL）可以使用
+func (d *Deleter[T]) Returning(cols ...string) *Deleter[T] {
+	d.returning = cols
+	d.hasReturning = true
 	return d
 }
 
 func (d *Deleter[T]) Build() (*Query, error) {
+	if d.whereAt == 0 {
+		d.whereAt = d.builder.Len()
+	}
+	stmt, err := writeLimit(d.dialect, d.model.table, d.builder.String(), d.whereAt, d.limit, d.offset, d.ordered)
+	if err != nil {
+		return nil, err
+	}
+	d.builder.Reset()
+	d.builder.WriteString(stmt)
+	d.limit, d.offset, d.ordered = -1, 0, false
+	if d.hasReturning && supportsReturning(d.dialect) {
+		if err := buildReturning(d.builder, d.dialect, d.model, d.returning); err != nil {
+			return nil, err
+		}
+	}
 	d.builder.WriteByte(';')
 	return &Query{
 		SQL:  d.builder.String(),
@@ -139,7 +205,11 @@ func (d *Deleter[T]) Exec(ctx context.Context) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+	return d.exec(ctx, q)
+}
 
+// exec 执行已经构建的删除语句，执行钩子并使缓存失效
+func (d *Deleter[T]) exec(ctx context.Context, q *Query) (Result, error) {
 	qc := &QueryContext{
 		QueryType: "exec",
 		Query:     q,
@@ -147,20 +217,151 @@ func (d *Deleter[T]) Exec(ctx context.Context) (Result, error) {
 		Builder:   d,
 	}
 
-	res, err := d.layer.HandleQuery(ctx, qc)
+	db := d.layer.getDB()
+	if err := db.runHooks(ctx, &HookContext{Event: BeforeDeleteEvent, Table: d.model.table, Query: q}); err != nil {
+		return Result{err: err}, err
+	}
 
-	// 如果执行成功且需要使缓存失效
-	if err == nil && d.invalidateCache {
-		db := d.layer.getDB()
-		if db.cacheManager != nil && db.cacheManager.IsEnabled() {
-			modelName := d.model.GetTableName()
-			// 传入标签或使用模型的默认标签
-			_ = db.cacheManager.InvalidateCache(ctx, modelName, d.invalidateTags...)
-		}
+	res, err := d.layer.HandleQuery(ctx, qc)
+	if err != nil {
+		return Result{err: err}, err
 	}
+	d.invalidate(ctx)
 
+	if err = db.runHooks(ctx, &HookContext{Event: AfterDeleteEvent, Table: d.model.table, Query: q}); err != nil {
+		return Result{err: err}, err
+	}
 	return Result{
 		res: res.Result.res,
-		err: err,
-	}, err
+	}, nil
+}
+
+// ErrBatchOffset 分批删除不能与 Offset 一起使用，每一批都会跳过同样的行
+var ErrBatchOffset = errors.New("orm: OFFSET cannot be used with ExecInBatches")
+
+type deleteBatchConfig struct {
+	pause    time.Duration
+	progress func(deleted int64)
+}
+
+// DeleteBatchOption 分批删除的配置选项
+type DeleteBatchOption func(*deleteBatchConfig)
+
+// WithBatchPause 设置两批之间的等待时间，给其他事务获取锁和从库同步留出时间
+func WithBatchPause(pause time.Duration) DeleteBatchOption {
+	return func(c *deleteBatchConfig) {
+		c.pause = pause
+	}
+}
+
+// WithBatchProgress 每批删除之后调用 fn，参数为已经删除的总行数
+func WithBatchProgress(fn func(deleted int64)) DeleteBatchOption {
+	return func(c *deleteBatchConfig) {
+		c.progress = fn
+	}
+}
+
+// ExecInBatches 每次最多删除 size 行，重复执行直到删除的行数少于 size，返回删除的总行数
+// 一次删除大量数据会长时间持有锁并产生很大的事务，分批删除时每一批都是单独的语句，在事务外执行时单独提交。
+// 每一批都会执行删除钩子并使缓存失效；出错或 context 取消时返回已经删除的行数和错误
+//
+//	n, err := RegisterDeleter[Log](db).Delete().
+//		Where(Col("CreatedAt").Lt(cutoff)).
+//		OrderBy(Asc(Col("ID"))).
+//		ExecInBatches(ctx, 1000, WithBatchPause(100*time.Millisecond))
+func (d *Deleter[T]) ExecInBatches(ctx context.Context, size int, opts ...DeleteBatchOption) (int64, error) {
+	if size <= 0 {
+		return 0, errors.New("orm: batch size must be positive")
+	}
+	if d.offset > 0 {
+		return 0, ErrBatchOffset
+	}
+	cfg := &deleteBatchConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	d.limit = size
+	q, err := d.Build()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		res, err := d.exec(ctx, q)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if cfg.progress != nil {
+			cfg.progress(total)
+		}
+		if n < int64(size) {
+			return total, nil
+		}
+
+		if cfg.pause > 0 {
+			timer := time.NewTimer(cfg.pause)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return total, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// ExecReturning 执行删除并返回 RETURNING 子句返回的行
+// 方言不支持 RETURNING 时返回 ErrReturningNotSupported，不会执行删除
+func (d *Deleter[T]) ExecReturning(ctx context.Context) ([]*T, error) {
+	if !supportsReturning(d.dialect) {
+		return nil, ErrReturningNotSupported
+	}
+	if !d.hasReturning {
+		d.Returning()
+	}
+
+	q, err := d.Build()
+	if err != nil {
+		return nil, err
+	}
+	db := d.layer.getDB()
+	if err = db.runHooks(ctx, &HookContext{Event: BeforeDeleteEvent, Table: d.model.table, Query: q}); err != nil {
+		return nil, err
+	}
+
+	res, err := d.layer.HandleQuery(ctx, &QueryContext{
+		QueryType: "query",
+		Query:     q,
+		Model:     d.model,
+		Builder:   d,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := scanReturningRows[T](db, d.model, res.Rows)
+	if err != nil {
+		return nil, err
+	}
+	d.invalidate(ctx)
+
+	// 在返回的每一行上执行模型钩子和全局钩子
+	if err = runRowHooks(ctx, db, AfterDeleteEvent, d.model.table, rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// invalidate 执行成功后按配置使缓存失效
+func (d *Deleter[T]) invalidate(ctx context.Context) {
+	invalidateAfterWrite(ctx, d.layer, d.model.GetTableName(), d.invalidation, d.invalidateTags)
 }
@@ -1,9 +1,13 @@
 package orm
 
 import (
+	"errors"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 )
 
 type Dialect interface {
@@ -80,15 +84,25 @@ func (b *BaseDialect) IfNull(expr string, defaultVal string) string {
 
 // 创建表的SQL语句通用实现
 func (b *BaseDialect) CreateTableSQL(m *model) string {
+	return createTableSQL(b, m, true)
+}
+
+// columnDialect 生成建表语句需要的方言方法
+type columnDialect interface {
+	Quote(name string) string
+	ColumnType(f *field) string
+}
+
+// createTableSQL 按方言 d 的列类型生成建表语句，inlineIndexes 为 false 时不在建表语句中定义索引，
+// 由方言在建表之后单独创建
+func createTableSQL(d columnDialect, m *model, inlineIndexes bool) string {
 	var builder strings.Builder
 	builder.WriteString("CREATE TABLE ")
-	builder.WriteString(b.Quote(m.table))
+	builder.WriteString(d.Quote(m.table))
 	builder.WriteString(" (\n")
 
 	// 添加列定义
 	var primaryKeys []string
-	var uniques []string
-	var indexes []string
 
 	i := 0
 	for _, f := range m.fieldsMap {
@@ -98,9 +112,10 @@ func (b *BaseDialect) CreateTableSQL(m *model) string {
 
 		// 列名和类型
 		builder.WriteString("  ")
-		builder.WriteString(b.Quote(f.colName))
+		builder.WriteString(d.Quote(f.colName))
 		builder.WriteString(" ")
-		builder.WriteString(b.ColumnType(f))
+		colType := d.ColumnType(f)
+		builder.WriteString(colType)
 
 		// 约束
 		if !f.nullable {
@@ -112,29 +127,23 @@ func (b *BaseDialect) CreateTableSQL(m *model) string {
 			builder.WriteString(f.default_)
 		}
 
-		if f.autoIncr {
+		// 其他方言的自增由 SERIAL、AUTOINCREMENT 等列类型表示
+		_, mysql := d.(*Mysql)
+		if mysql && f.autoIncr && !strings.Contains(colType, "AUTO_INCREMENT") {
 			builder.WriteString(" AUTO_INCREMENT")
 		}
 
-		if f.comment != "" {
+		if mysql && f.comment != "" {
 			builder.WriteString(" COMMENT '")
 			builder.WriteString(f.comment)
 			builder.WriteString("'")
 		}
 
-		// 收集约束信息
-		if f.primaryKey {
+		// 收集约束信息，列类型中已经声明主键的列（SQLite 的自增主键）不再重复声明
+		if f.primaryKey && !strings.Contains(colType, "PRIMARY KEY") {
 			primaryKeys = append(primaryKeys, f.colName)
 		}
 
-		if f.unique {
-			uniques = append(uniques, f.colName)
-		}
-
-		if f.index {
-			indexes = append(indexes, f.colName)
-		}
-
 		i++
 	}
 
@@ -145,27 +154,30 @@ func (b *BaseDialect) CreateTableSQL(m *model) string {
 			if i > 0 {
 				builder.WriteString(", ")
 			}
-			builder.WriteString(b.Quote(pk))
+			builder.WriteString(d.Quote(pk))
 		}
 		builder.WriteString(")")
 	}
 
-	// 添加唯一约束
-	for _, colName := range uniques {
-		builder.WriteString(",\n  UNIQUE KEY ")
-		builder.WriteString(b.Quote("uk_" + m.table + "_" + colName))
-		builder.WriteString(" (")
-		builder.WriteString(b.Quote(colName))
-		builder.WriteString(")")
+	// 添加唯一约束和索引，联合索引的列按字段顺序排列
+	if inlineIndexes {
+		for _, idx := range m.indexes {
+			if idx.Unique {
+				builder.WriteString(",\n  UNIQUE KEY ")
+			} else {
+				builder.WriteString(",\n  KEY ")
+			}
+			builder.WriteString(d.Quote(idx.Name))
+			builder.WriteString(" (")
+			builder.WriteString(joinQuoted(d.Quote, idx.Columns))
+			builder.WriteString(")")
+		}
 	}
 
-	// 添加索引
-	for _, colName := range indexes {
-		builder.WriteString(",\n  KEY ")
-		builder.WriteString(b.Quote("idx_" + m.table + "_" + colName))
-		builder.WriteString(" (")
-		builder.WriteString(b.Quote(colName))
-		builder.WriteString(")")
+	// 添加外键
+	for _, fk := range m.foreignKeys {
+		builder.WriteString(",\n  ")
+		builder.WriteString(foreignKeyClause(d.Quote, fk))
 	}
 
 	builder.WriteString("\n)")
@@ -292,4 +304,110 @@ func (b *BaseDialect) ColumnType(f *field) string {
 
 	// 默认
 	return "TEXT"
-}
\ No newline at end of file
+}
+// buildOnConflict 生成 PostgreSQL 和 SQLite 的 ON CONFLICT (...) DO UPDATE SET 子句。
+// conflictCols 为空时使用模型的主键（没有声明主键时使用 ID 字段），需要与表上的主键或唯一约束一致
+func buildOnConflict(builder *strings.Builder, quote func(string) string, dialect string, conflictCols, cols []*Column) {
+	if len(cols) == 0 {
+		panic(ferr.ErrUpsertRowNotFound)
+	}
+
+	var targets []string
+	for _, col := range conflictCols {
+		targets = append(targets, upsertColumnName(col))
+	}
+	if len(targets) == 0 && cols[0].model != nil {
+		for _, f := range cols[0].model.fieldsMap {
+			if f.primaryKey {
+				targets = append(targets, f.colName)
+			}
+		}
+		sort.Strings(targets)
+		// 没有声明主键时与回填自增ID一样使用 ID 字段
+		if f, ok := cols[0].model.fieldsMap["ID"]; ok && len(targets) == 0 {
+			targets = append(targets, f.colName)
+		}
+	}
+	if len(targets) == 0 {
+		panic(errors.New(dialect + " must have conflict columns"))
+	}
+
+	builder.WriteString(" ON CONFLICT(")
+	builder.WriteString(joinQuoted(quote, targets))
+	builder.WriteString(") DO UPDATE SET ")
+	for index, col := range cols {
+		if index > 0 {
+			builder.WriteString(", ")
+		}
+		name := quote(upsertColumnName(col))
+		builder.WriteString(name)
+		builder.WriteString(" = EXCLUDED.")
+		builder.WriteString(name)
+	}
+}
+
+// upsertColumnName 返回 UPSERT 中字段对应的列名，不带表名和引号
+func upsertColumnName(col *Column) string {
+	var sb strings.Builder
+	col.BuildWithoutQuote(&sb)
+	return sb.String()
+}
+
+// ErrOffsetNotSupported 方言不支持在 UPDATE 和 DELETE 中使用 OFFSET
+var ErrOffsetNotSupported = errors.New("orm: OFFSET in UPDATE and DELETE is not supported by the dialect")
+
+// noLimitClause 只有 OFFSET 时需要补上的 LIMIT：MySQL 和 SQLite 的 OFFSET 必须跟在 LIMIT 之后，
+// 分别使用最大的行数和 -1 表示不限制；PostgreSQL 可以单独使用 OFFSET
+func noLimitClause(d Dialect) string {
+	switch d.(type) {
+	case *Mysql:
+		return " LIMIT 18446744073709551615"
+	case *Sqlite:
+		return " LIMIT -1"
+	}
+	return ""
+}
+
+// writeLimit UPDATE 和 DELETE 的 LIMIT 和 OFFSET，limit 小于 0 表示没有限制。
+// MySQL 直接追加 LIMIT，不支持 OFFSET；PostgreSQL 和 SQLite（默认的编译选项）不支持在 UPDATE 和 DELETE 中使用 LIMIT 和 ORDER BY，
+// 改写为按行标识限定的子查询：WHERE ctid IN (SELECT ctid FROM 表 WHERE 条件 ORDER BY 列 LIMIT n)，SQLite 使用 rowid。
+// stmt 为已经构建的语句，whereAt 为 WHERE 子句开始的位置，没有 WHERE 时为语句的长度，ordered 表示语句包含 ORDER BY
+func writeLimit(d Dialect, table, stmt string, whereAt, limit, offset int, ordered bool) (string, error) {
+	if limit < 0 && offset <= 0 && !ordered {
+		return stmt, nil
+	}
+
+	var clause string
+	if limit >= 0 {
+		clause = " LIMIT " + strconv.Itoa(limit)
+	} else if offset > 0 {
+		clause = noLimitClause(d)
+	}
+	if offset > 0 {
+		clause += " OFFSET " + strconv.Itoa(offset)
+	}
+
+	var rowID string
+	switch d.(type) {
+	case *Postgresql:
+		rowID = "ctid"
+	case *Sqlite:
+		rowID = "rowid"
+	default:
+		if offset > 0 {
+			return "", ErrOffsetNotSupported
+		}
+		return stmt + clause, nil
+	}
+	return stmt[:whereAt] + " WHERE " + rowID + " IN (SELECT " + rowID + " FROM " + d.Quote(table) +
+		stmt[whereAt:] + clause + ")", nil
+}
+
+// derefField 返回把指针字段替换为指向的类型后的字段副本，用于列类型映射
+func derefField(f *field) *field {
+	c := *f
+	for c.typ.Kind() == reflect.Ptr {
+		c.typ = c.typ.Elem()
+	}
+	return &c
+}
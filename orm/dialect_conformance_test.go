@@ -0,0 +1,588 @@
+package orm
+
+import (
+	"database/sql"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ConformanceOrder 列名 order 和 group 是保留字，用于检查各方言的引用
+type ConformanceOrder struct {
+	ID    int64 `orm:"primary_key;auto_increment"`
+	Order int
+	Group string
+}
+
+// conformanceCase 方言一致性测试的场景，want 和 wantErr 按方言名称给出期望结果，
+// 每个注册的方言都必须有对应的期望
+type conformanceCase struct {
+	name    string
+	build   func(db *DB) (*Query, error)
+	want    map[string]*Query
+	wantErr map[string]error
+}
+
+func conformanceCases() []conformanceCase {
+	return []conformanceCase{
+		{
+			name: "select where order limit offset",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select().
+					Where(Col("ID").Eq(1), Col("Group").Like("a%")).
+					OrderBy(Desc(Col("ID"))).Limit(10).Offset(20).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT * FROM `conformance_order` WHERE `id` = ? AND `group` LIKE ? ORDER BY `id` DESC LIMIT 10 OFFSET 20;",
+					Args: []any{1, "a%"},
+				},
+				"postgresql": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "id" = $1 AND "group" LIKE $2 ORDER BY "id" DESC LIMIT 10 OFFSET 20;`,
+					Args: []any{1, "a%"},
+				},
+				"sqlite": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "id" = ? AND "group" LIKE ? ORDER BY "id" DESC LIMIT 10 OFFSET 20;`,
+					Args: []any{1, "a%"},
+				},
+			},
+		},
+		{
+			name: "offset without limit",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select().Offset(20).Build()
+			},
+			want: map[string]*Query{
+				"mysql":      {SQL: "SELECT * FROM `conformance_order` LIMIT 18446744073709551615 OFFSET 20;"},
+				"postgresql": {SQL: `SELECT * FROM "conformance_order" OFFSET 20;`},
+				"sqlite":     {SQL: `SELECT * FROM "conformance_order" LIMIT -1 OFFSET 20;`},
+			},
+		},
+		{
+			name: "in",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select().
+					Where(Col("ID").In(1, 2, 3), Col("Order").Eq(4)).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT * FROM `conformance_order` WHERE `id` IN (?, ?, ?) AND `order` = ?;",
+					Args: []any{1, 2, 3, 4},
+				},
+				"postgresql": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "id" IN ($1, $2, $3) AND "order" = $4;`,
+					Args: []any{1, 2, 3, 4},
+				},
+				"sqlite": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "id" IN (?, ?, ?) AND "order" = ?;`,
+					Args: []any{1, 2, 3, 4},
+				},
+			},
+		},
+		{
+			name: "group by having",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select(Col("Group"), Count("ID").As("cnt")).
+					GroupBy(Col("Group")).Having(Count("ID").Gt(2)).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT `group`, COUNT(`id`) AS `cnt` FROM `conformance_order` GROUP BY `group` HAVING COUNT(`id`) > ?;",
+					Args: []any{2},
+				},
+				"postgresql": {
+					SQL:  `SELECT "group", COUNT("id") AS "cnt" FROM "conformance_order" GROUP BY "group" HAVING COUNT("id") > $1;`,
+					Args: []any{2},
+				},
+				"sqlite": {
+					SQL:  `SELECT "group", COUNT("id") AS "cnt" FROM "conformance_order" GROUP BY "group" HAVING COUNT("id") > ?;`,
+					Args: []any{2},
+				},
+			},
+		},
+		{
+			name: "subquery",
+			build: func(db *DB) (*Query, error) {
+				sub := RegisterSelector[ConformanceOrder](db).Select(Col("ID")).Where(Col("Order").Gt(3)).AsSubQuery("s")
+				return RegisterSelector[ConformanceOrder](db).Select().
+					Where(Col("Group").Eq("a"), Col("ID").In(sub)).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT * FROM `conformance_order` WHERE `group` = ? AND `id` IN (SELECT `id` FROM `conformance_order` WHERE `order` > ?);",
+					Args: []any{"a", 3},
+				},
+				"postgresql": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "group" = $1 AND "id" IN (SELECT "id" FROM "conformance_order" WHERE "order" > $2);`,
+					Args: []any{"a", 3},
+				},
+				"sqlite": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "group" = ? AND "id" IN (SELECT "id" FROM "conformance_order" WHERE "order" > ?);`,
+					Args: []any{"a", 3},
+				},
+			},
+		},
+		{
+			name: "union",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select(Col("ID")).Where(Col("ID").Eq(1)).
+					Union(RegisterSelector[ConformanceOrder](db).Select(Col("ID")).Where(Col("ID").Eq(2))).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT `id` FROM `conformance_order` WHERE `id` = ? UNION SELECT `id` FROM `conformance_order` WHERE `id` = ?;",
+					Args: []any{1, 2},
+				},
+				"postgresql": {
+					SQL:  `SELECT "id" FROM "conformance_order" WHERE "id" = $1 UNION SELECT "id" FROM "conformance_order" WHERE "id" = $2;`,
+					Args: []any{1, 2},
+				},
+				"sqlite": {
+					SQL:  `SELECT "id" FROM "conformance_order" WHERE "id" = ? UNION SELECT "id" FROM "conformance_order" WHERE "id" = ?;`,
+					Args: []any{1, 2},
+				},
+			},
+		},
+		{
+			name: "raw placeholders",
+			build: func(db *DB) (*Query, error) {
+				return RegisterSelector[ConformanceOrder](db).Select().
+					Where(Col("Group").Eq(Raw("CONCAT(?, '?')", "a")), Col("Order").Eq(Raw("? + 1", 9))).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "SELECT * FROM `conformance_order` WHERE `group` = CONCAT(?, '?') AND `order` = ? + 1;",
+					Args: []any{"a", 9},
+				},
+				"postgresql": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "group" = CONCAT($1, '?') AND "order" = $2 + 1;`,
+					Args: []any{"a", 9},
+				},
+				"sqlite": {
+					SQL:  `SELECT * FROM "conformance_order" WHERE "group" = CONCAT(?, '?') AND "order" = ? + 1;`,
+					Args: []any{"a", 9},
+				},
+			},
+		},
+		{
+			name: "batch insert",
+			build: func(db *DB) (*Query, error) {
+				return RegisterInserter[ConformanceOrder](db).
+					Insert(nil, &ConformanceOrder{ID: 1, Order: 2, Group: "a"}, &ConformanceOrder{ID: 3, Order: 4, Group: "b"}).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "INSERT INTO `conformance_order` (`id`, `order`, `group`) VALUES (?, ?, ?), (?, ?, ?);",
+					Args: []any{int64(1), 2, "a", int64(3), 4, "b"},
+				},
+				"postgresql": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES ($1, $2, $3), ($4, $5, $6);`,
+					Args: []any{int64(1), 2, "a", int64(3), 4, "b"},
+				},
+				"sqlite": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES (?, ?, ?), (?, ?, ?);`,
+					Args: []any{int64(1), 2, "a", int64(3), 4, "b"},
+				},
+			},
+		},
+		{
+			name: "upsert with conflict columns",
+			build: func(db *DB) (*Query, error) {
+				return RegisterInserter[ConformanceOrder](db).Insert(nil, &ConformanceOrder{ID: 1, Order: 2, Group: "a"}).
+					Upsert([]*Column{Col("Group")}, []*Column{Col("Order")}).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "INSERT INTO `conformance_order` (`id`, `order`, `group`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `order` = VALUES(`order`);",
+					Args: []any{int64(1), 2, "a"},
+				},
+				"postgresql": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES ($1, $2, $3) ON CONFLICT("group") DO UPDATE SET "order" = EXCLUDED."order";`,
+					Args: []any{int64(1), 2, "a"},
+				},
+				"sqlite": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES (?, ?, ?) ON CONFLICT("group") DO UPDATE SET "order" = EXCLUDED."order";`,
+					Args: []any{int64(1), 2, "a"},
+				},
+			},
+		},
+		{
+			name: "upsert on primary key",
+			build: func(db *DB) (*Query, error) {
+				return RegisterInserter[ConformanceOrder](db).Insert(nil, &ConformanceOrder{ID: 1, Order: 2, Group: "a"}).
+					Upsert(nil, []*Column{Col("Order"), Col("Group")}).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "INSERT INTO `conformance_order` (`id`, `order`, `group`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `order` = VALUES(`order`), `group` = VALUES(`group`);",
+					Args: []any{int64(1), 2, "a"},
+				},
+				"postgresql": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES ($1, $2, $3) ON CONFLICT("id") DO UPDATE SET "order" = EXCLUDED."order", "group" = EXCLUDED."group";`,
+					Args: []any{int64(1), 2, "a"},
+				},
+				"sqlite": {
+					SQL:  `INSERT INTO "conformance_order" ("id", "order", "group") VALUES (?, ?, ?) ON CONFLICT("id") DO UPDATE SET "order" = EXCLUDED."order", "group" = EXCLUDED."group";`,
+					Args: []any{int64(1), 2, "a"},
+				},
+			},
+		},
+		{
+			name: "insert returning",
+			build: func(db *DB) (*Query, error) {
+				return RegisterInserter[ConformanceOrder](db).Insert([]string{"Order", "Group"}, &ConformanceOrder{Order: 2, Group: "a"}).
+					Returning("ID").Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "INSERT INTO `conformance_order` (`order`, `group`) VALUES (?, ?);",
+					Args: []any{2, "a"},
+				},
+				"postgresql": {
+					SQL:  `INSERT INTO "conformance_order" ("order", "group") VALUES ($1, $2) RETURNING "id";`,
+					Args: []any{2, "a"},
+				},
+				"sqlite": {
+					SQL:  `INSERT INTO "conformance_order" ("order", "group") VALUES (?, ?);`,
+					Args: []any{2, "a"},
+				},
+			},
+		},
+		{
+			name: "update returning",
+			build: func(db *DB) (*Query, error) {
+				return RegisterUpdater[ConformanceOrder](db).Update().Set(Col("Group"), "b").
+					Where(Col("ID").Eq(1)).Returning("ID", "Group").Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "UPDATE `conformance_order` SET `group` = ? WHERE `id` = ?;",
+					Args: []any{"b", 1},
+				},
+				"postgresql": {
+					SQL:  `UPDATE "conformance_order" SET "group" = $1 WHERE "id" = $2 RETURNING "id", "group";`,
+					Args: []any{"b", 1},
+				},
+				"sqlite": {
+					SQL:  `UPDATE "conformance_order" SET "group" = ? WHERE "id" = ?;`,
+					Args: []any{"b", 1},
+				},
+			},
+		},
+		{
+			name: "update limit",
+			build: func(db *DB) (*Query, error) {
+				return RegisterUpdater[ConformanceOrder](db).Update().Set(Col("Group"), "b").
+					Where(Col("Order").Gt(1)).Limit(3).Returning("ID").Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "UPDATE `conformance_order` SET `group` = ? WHERE `order` > ? LIMIT 3;",
+					Args: []any{"b", 1},
+				},
+				"postgresql": {
+					SQL:  `UPDATE "conformance_order" SET "group" = $1 WHERE ctid IN (SELECT ctid FROM "conformance_order" WHERE "order" > $2 LIMIT 3) RETURNING "id";`,
+					Args: []any{"b", 1},
+				},
+				"sqlite": {
+					SQL:  `UPDATE "conformance_order" SET "group" = ? WHERE rowid IN (SELECT rowid FROM "conformance_order" WHERE "order" > ? LIMIT 3);`,
+					Args: []any{"b", 1},
+				},
+			},
+		},
+		{
+			name: "delete returning",
+			build: func(db *DB) (*Query, error) {
+				return RegisterDeleter[ConformanceOrder](db).Delete().Where(Col("ID").Eq(1)).Returning("ID").Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "DELETE FROM `conformance_order` WHERE `id` = ?;",
+					Args: []any{1},
+				},
+				"postgresql": {
+					SQL:  `DELETE FROM "conformance_order" WHERE "id" = $1 RETURNING "id";`,
+					Args: []any{1},
+				},
+				"sqlite": {
+					SQL:  `DELETE FROM "conformance_order" WHERE "id" = ?;`,
+					Args: []any{1},
+				},
+			},
+		},
+		{
+			name: "update arithmetic",
+			build: func(db *DB) (*Query, error) {
+				return RegisterUpdater[ConformanceOrder](db).Update().
+					Set(Col("Order"), Func("COALESCE", Col("Order").Sub(2), 0)).
+					Where(Col("ID").Eq(1)).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "UPDATE `conformance_order` SET `order` = COALESCE(`order` - ?, ?) WHERE `id` = ?;",
+					Args: []any{2, 0, 1},
+				},
+				"postgresql": {
+					SQL:  `UPDATE "conformance_order" SET "order" = COALESCE("order" - $1, $2) WHERE "id" = $3;`,
+					Args: []any{2, 0, 1},
+				},
+				"sqlite": {
+					SQL:  `UPDATE "conformance_order" SET "order" = COALESCE("order" - ?, ?) WHERE "id" = ?;`,
+					Args: []any{2, 0, 1},
+				},
+			},
+		},
+		{
+			name: "update order by limit",
+			build: func(db *DB) (*Query, error) {
+				return RegisterUpdater[ConformanceOrder](db).Update().Set(Col("Group"), "b").
+					Where(Col("Order").Gt(1)).OrderBy(Desc(Col("Order")), Asc(Col("ID"))).Limit(3).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "UPDATE `conformance_order` SET `group` = ? WHERE `order` > ? ORDER BY `order` DESC, `id` LIMIT 3;",
+					Args: []any{"b", 1},
+				},
+				"postgresql": {
+					SQL:  `UPDATE "conformance_order" SET "group" = $1 WHERE ctid IN (SELECT ctid FROM "conformance_order" WHERE "order" > $2 ORDER BY "order" DESC, "id" LIMIT 3);`,
+					Args: []any{"b", 1},
+				},
+				"sqlite": {
+					SQL:  `UPDATE "conformance_order" SET "group" = ? WHERE rowid IN (SELECT rowid FROM "conformance_order" WHERE "order" > ? ORDER BY "order" DESC, "id" LIMIT 3);`,
+					Args: []any{"b", 1},
+				},
+			},
+		},
+		{
+			name: "delete order by limit without where",
+			build: func(db *DB) (*Query, error) {
+				return RegisterDeleter[ConformanceOrder](db).Delete().OrderBy(Asc(Col("ID"))).Limit(10).Build()
+			},
+			want: map[string]*Query{
+				"mysql":      {SQL: "DELETE FROM `conformance_order` ORDER BY `id` LIMIT 10;"},
+				"postgresql": {SQL: `DELETE FROM "conformance_order" WHERE ctid IN (SELECT ctid FROM "conformance_order" ORDER BY "id" LIMIT 10);`},
+				"sqlite":     {SQL: `DELETE FROM "conformance_order" WHERE rowid IN (SELECT rowid FROM "conformance_order" ORDER BY "id" LIMIT 10);`},
+			},
+		},
+		{
+			name: "delete order by without limit",
+			build: func(db *DB) (*Query, error) {
+				return RegisterDeleter[ConformanceOrder](db).Delete().Where(Col("Order").Eq(1)).OrderBy(Asc(Col("ID"))).Build()
+			},
+			want: map[string]*Query{
+				"mysql": {
+					SQL:  "DELETE FROM `conformance_order` WHERE `order` = ? ORDER BY `id`;",
+					Args: []any{1},
+				},
+				"postgresql": {
+					SQL:  `DELETE FROM "conformance_order" WHERE ctid IN (SELECT ctid FROM "conformance_order" WHERE "order" = $1 ORDER BY "id");`,
+					Args: []any{1},
+				},
+				"sqlite": {
+					SQL:  `DELETE FROM "conformance_order" WHERE rowid IN (SELECT rowid FROM "conformance_order" WHERE "order" = ? ORDER BY "id");`,
+					Args: []any{1},
+				},
+			},
+		},
+		{
+			name: "delete limit without where",
+			build: func(db *DB) (*Query, error) {
+				return RegisterDeleter[ConformanceOrder](db).Delete().Limit(10).Build()
+			},
+			want: map[string]*Query{
+				"mysql":      {SQL: "DELETE FROM `conformance_order` LIMIT 10;"},
+				"postgresql": {SQL: `DELETE FROM "conformance_order" WHERE ctid IN (SELECT ctid FROM "conformance_order" LIMIT 10);`},
+				"sqlite":     {SQL: `DELETE FROM "conformance_order" WHERE rowid IN (SELECT rowid FROM "conformance_order" LIMIT 10);`},
+			},
+		},
+		{
+			name: "delete limit offset",
+			build: func(db *DB) (*Query, error) {
+				return RegisterDeleter[ConformanceOrder](db).Delete().Where(Col("Order").Eq(1)).Limit(2).Offset(1).Build()
+			},
+			want: map[string]*Query{
+				"postgresql": {
+					SQL:  `DELETE FROM "conformance_order" WHERE ctid IN (SELECT ctid FROM "conformance_order" WHERE "order" = $1 LIMIT 2 OFFSET 1);`,
+					Args: []any{1},
+				},
+				"sqlite": {
+					SQL:  `DELETE FROM "conformance_order" WHERE rowid IN (SELECT rowid FROM "conformance_order" WHERE "order" = ? LIMIT 2 OFFSET 1);`,
+					Args: []any{1},
+				},
+			},
+			wantErr: map[string]error{
+				"mysql": ErrOffsetNotSupported,
+			},
+		},
+	}
+}
+
+// conformancePlaceholder 匹配引号外的占位符，字符串字面量中的 ? 不计入
+var conformancePlaceholder = regexp.MustCompile(`'[^']*'|\$\d+|\?`)
+
+func conformancePlaceholders(sql string) []string {
+	var res []string
+	for _, m := range conformancePlaceholder.FindAllString(sql, -1) {
+		if !strings.HasPrefix(m, "'") {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func TestDialectConformance(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	for _, tc := range conformanceCases() {
+		for name := range dialects {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				want, hasWant := tc.want[name]
+				wantErr, hasErr := tc.wantErr[name]
+				require.True(t, hasWant || hasErr, "缺少方言 %s 的期望结果", name)
+
+				db, err := Open(mockDB, name)
+				require.NoError(t, err)
+				q, err := tc.build(db)
+				if hasErr {
+					assert.ErrorIs(t, err, wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, want.SQL, q.SQL)
+				assert.Equal(t, want.Args, q.Args)
+			})
+		}
+	}
+}
+
+// TestDialectConformance_Invariants 检查所有场景生成的语句都满足方言的通用约定：
+// 占位符数量与参数数量一致，PostgreSQL 的占位符从 $1 开始连续编号，使用双引号的方言不出现反引号
+func TestDialectConformance_Invariants(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	for name, d := range dialects {
+		db, err := Open(mockDB, name)
+		require.NoError(t, err)
+
+		for _, tc := range conformanceCases() {
+			q, err := tc.build(db)
+			if err != nil {
+				continue
+			}
+
+			ph := conformancePlaceholders(q.SQL)
+			assert.Equal(t, len(q.Args), len(ph), "%s %s: %s", name, tc.name, q.SQL)
+
+			if d.Placeholder(1) == "$1" {
+				var numbered []string
+				for _, p := range ph {
+					if strings.HasPrefix(p, "$") {
+						numbered = append(numbered, p)
+					}
+				}
+				for i, p := range numbered {
+					assert.Equal(t, "$"+strconv.Itoa(i+1), p, "%s %s: %s", name, tc.name, q.SQL)
+				}
+			}
+			if d.Quote("c") == `"c"` {
+				assert.NotContains(t, q.SQL, "`", "%s %s", name, tc.name)
+			}
+			assert.True(t, strings.HasSuffix(q.SQL, ";"), "%s %s", name, tc.name)
+		}
+	}
+}
+
+func TestDialectConformance_RawEscape(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	// ?? 写出字面量 ?，如 PostgreSQL 的 jsonb 运算符
+	q, err := RegisterSelector[ConformanceOrder](db).Select(Raw(`"group"::jsonb ?? ?`, "a")).
+		Where(Col("ID").Eq(1)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "group"::jsonb ? $1 FROM "conformance_order" WHERE "id" = $2;`, q.SQL)
+	assert.Equal(t, []any{"a", 1}, q.Args)
+}
+
+func TestDialectConformance_ColumnType(t *testing.T) {
+	var (
+		bytes   []byte
+		ptrInt  *int
+		ptrTime *time.Time
+	)
+	testCases := []struct {
+		name string
+		typ  reflect.Type
+		tag  string
+		want map[string]string
+	}{
+		{name: "bool", typ: reflect.TypeOf(false),
+			want: map[string]string{"mysql": "TINYINT(1)", "postgresql": "BOOLEAN", "sqlite": "BOOLEAN"}},
+		{name: "int", typ: reflect.TypeOf(0),
+			want: map[string]string{"mysql": "INT", "postgresql": "INTEGER", "sqlite": "INTEGER"}},
+		{name: "int64 auto increment", typ: reflect.TypeOf(int64(0)), tag: "auto_increment",
+			want: map[string]string{"mysql": "BIGINT", "postgresql": "BIGINT", "sqlite": "INTEGER"}},
+		{name: "uint32", typ: reflect.TypeOf(uint32(0)),
+			want: map[string]string{"mysql": "INT UNSIGNED", "postgresql": "BIGINT", "sqlite": "INTEGER"}},
+		{name: "uint64", typ: reflect.TypeOf(uint64(0)),
+			want: map[string]string{"mysql": "BIGINT UNSIGNED", "postgresql": "NUMERIC(20,0)", "sqlite": "INTEGER"}},
+		{name: "float64", typ: reflect.TypeOf(0.0),
+			want: map[string]string{"mysql": "DOUBLE", "postgresql": "DOUBLE PRECISION", "sqlite": "REAL"}},
+		{name: "decimal", typ: reflect.TypeOf(0.0), tag: "precision:10;scale:2",
+			want: map[string]string{"mysql": "DECIMAL(10,2)", "postgresql": "NUMERIC(10,2)", "sqlite": "REAL"}},
+		{name: "varchar", typ: reflect.TypeOf(""), tag: "size:64",
+			want: map[string]string{"mysql": "VARCHAR(64)", "postgresql": "VARCHAR(64)", "sqlite": "TEXT(64)"}},
+		{name: "text", typ: reflect.TypeOf(""),
+			want: map[string]string{"mysql": "TEXT", "postgresql": "TEXT", "sqlite": "TEXT"}},
+		{name: "bytes", typ: reflect.TypeOf(bytes),
+			want: map[string]string{"mysql": "BLOB", "postgresql": "BYTEA", "sqlite": "BLOB"}},
+		{name: "time", typ: reflect.TypeOf(time.Time{}),
+			want: map[string]string{"mysql": "DATETIME", "postgresql": "TIMESTAMP WITH TIME ZONE", "sqlite": "DATETIME"}},
+		{name: "pointer int", typ: reflect.TypeOf(ptrInt),
+			want: map[string]string{"mysql": "INT", "postgresql": "INTEGER", "sqlite": "INTEGER"}},
+		{name: "pointer time", typ: reflect.TypeOf(ptrTime),
+			want: map[string]string{"mysql": "DATETIME", "postgresql": "TIMESTAMP WITH TIME ZONE", "sqlite": "DATETIME"}},
+		{name: "null int32", typ: reflect.TypeOf(sql.NullInt32{}),
+			want: map[string]string{"mysql": "INT", "postgresql": "INTEGER", "sqlite": "INTEGER"}},
+		{name: "null int16", typ: reflect.TypeOf(sql.NullInt16{}),
+			want: map[string]string{"mysql": "SMALLINT", "postgresql": "SMALLINT", "sqlite": "INTEGER"}},
+		{name: "null string", typ: reflect.TypeOf(sql.NullString{}), tag: "size:32",
+			want: map[string]string{"mysql": "VARCHAR(32)", "postgresql": "VARCHAR(32)", "sqlite": "TEXT"}},
+		{name: "explicit type", typ: reflect.TypeOf(""), tag: "type:char(2)",
+			want: map[string]string{"mysql": "CHAR(2)", "postgresql": "CHAR(2)", "sqlite": "CHAR(2)"}},
+	}
+
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, tc := range testCases {
+		for _, name := range names {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				want, ok := tc.want[name]
+				require.True(t, ok, "缺少方言 %s 的期望结果", name)
+				got, err := ColumnType(dialects[name], tc.typ, tc.tag)
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			})
+		}
+	}
+}
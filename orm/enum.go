@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	enumTypeRe  = regexp.MustCompile(`(?is)^\s*enum\s*\((.*)\)\s*$`)
+	enumValueRe = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// setEnumTypes 为没有指定 enum_type 的枚举字段设置 PostgreSQL 的类型名：表名_列名
+func setEnumTypes(table string, fields []*field) {
+	for _, f := range fields {
+		if len(f.enum) > 0 && f.enumType == "" {
+			f.enumType = table + "_" + f.colName
+		}
+	}
+}
+
+// enumValues 枚举取值的列表：'a', 'b'，取值中的单引号转义
+func enumValues(values []string, sep string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(strings.TrimSpace(v), "'", "''")+"'")
+	}
+	return strings.Join(quoted, sep)
+}
+
+// createEnumSQL PostgreSQL 创建枚举类型的语句，类型已经存在时跳过，可以重复执行
+func createEnumSQL(name string, values []string) string {
+	return "DO $$ BEGIN CREATE TYPE " + name + " AS ENUM (" + enumValues(values, ", ") +
+		"); EXCEPTION WHEN duplicate_object THEN NULL; END $$"
+}
+
+// enumOf 字段的枚举取值，显式指定了类型的字段按指定的类型处理
+func enumOf(f *field) []string {
+	if f.sqlType != "" {
+		return nil
+	}
+	return f.enum
+}
+
+// parseEnumType 解析 MySQL 的 ENUM('a','b') 类型，返回枚举的取值
+func parseEnumType(typ string) ([]string, bool) {
+	m := enumTypeRe.FindStringSubmatch(typ)
+	if m == nil {
+		return nil, false
+	}
+	var values []string
+	for _, v := range enumValueRe.FindAllStringSubmatch(m[1], -1) {
+		values = append(values, strings.ReplaceAll(v[1], "''", "'"))
+	}
+	return values, true
+}
@@ -0,0 +1,113 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EnumOrder struct {
+	ID     int64    `orm:"primary_key;auto_increment"`
+	Status string   `orm:"enum:pending,paid,it's"`
+	Level  string   `orm:"enum:low,high;enum_type:order_level"`
+	Tags   []string `orm:"nullable"`
+}
+
+func TestEnum_Field(t *testing.T) {
+	m, err := parseModel(&EnumOrder{})
+	require.NoError(t, err)
+
+	status := m.fieldsMap["Status"]
+	assert.Equal(t, []string{"pending", "paid", "it's"}, status.enum)
+	assert.Equal(t, "enum_order_status", status.enumType)
+	assert.Equal(t, "order_level", m.fieldsMap["Level"].enumType)
+
+	assert.Equal(t, "ENUM('pending','paid','it''s')", Get("mysql").ColumnType(status))
+	assert.Equal(t, "enum_order_status", Get("postgresql").ColumnType(status))
+	assert.Equal(t, "TEXT", Get("sqlite").ColumnType(status))
+}
+
+func TestEnum_CreateTableSQL(t *testing.T) {
+	m, err := parseModel(&EnumOrder{})
+	require.NoError(t, err)
+
+	ddl := Get("postgresql").CreateTableSQL(m)
+	assert.Contains(t, ddl, "DO $$ BEGIN CREATE TYPE enum_order_status AS ENUM ('pending', 'paid', 'it''s'); "+
+		"EXCEPTION WHEN duplicate_object THEN NULL; END $$;\n")
+	assert.Contains(t, ddl, "DO $$ BEGIN CREATE TYPE order_level AS ENUM ('low', 'high');")
+	// 列的顺序不固定，不检查列之间的逗号
+	assert.Contains(t, ddl, `"id" BIGSERIAL`)
+	assert.Contains(t, ddl, `"status" enum_order_status`)
+	assert.Contains(t, ddl, `"tags" TEXT[]`)
+	assert.NotContains(t, ddl, "AUTO_INCREMENT")
+
+	// 枚举类型的语句块中的分号不拆分
+	plan := createPlan(m.table, ddl)
+	require.Len(t, plan.Statements, 3)
+	assert.True(t, strings.HasPrefix(plan.Statements[2], `CREATE TABLE "enum_order"`))
+	for _, stmt := range plan.Statements[:2] {
+		assert.True(t, strings.HasSuffix(stmt, "END $$"), stmt)
+	}
+
+	mysql := Get("mysql").CreateTableSQL(m)
+	assert.Contains(t, mysql, "`status` ENUM('pending','paid','it''s')")
+	assert.Equal(t, 1, strings.Count(mysql, "AUTO_INCREMENT"))
+
+	sqlite := Get("sqlite").CreateTableSQL(m)
+	assert.Contains(t, sqlite, `"id" INTEGER PRIMARY KEY AUTOINCREMENT`)
+	assert.NotContains(t, sqlite, "PRIMARY KEY (")
+}
+
+func TestEnum_SchemaPlan(t *testing.T) {
+	m, err := parseModel(&EnumOrder{})
+	require.NoError(t, err)
+	d := &Postgresql{}
+	desired := desiredTableSchema(d, m, reflect.TypeOf(EnumOrder{}))
+
+	actual := &TableSchema{Name: "enum_order", Columns: []*ColumnSchema{
+		{Name: "id", Type: "BIGINT", PrimaryKey: true, AutoIncr: true},
+		{Name: "status", Type: "TEXT", Nullable: true},
+		{Name: "tags", Type: "TEXT[]", Nullable: true},
+	}}
+	plan := buildSchemaPlan(d, desired, actual, true)
+	assert.Equal(t, []string{
+		"DO $$ BEGIN CREATE TYPE order_level AS ENUM ('low', 'high'); EXCEPTION WHEN duplicate_object THEN NULL; END $$",
+		"DO $$ BEGIN CREATE TYPE enum_order_status AS ENUM ('pending', 'paid', 'it''s'); EXCEPTION WHEN duplicate_object THEN NULL; END $$",
+		"ALTER TABLE \"enum_order\"\n" +
+			"  ADD COLUMN \"level\" ORDER_LEVEL,\n" +
+			"  ALTER COLUMN \"status\" TYPE ENUM_ORDER_STATUS USING \"status\"::ENUM_ORDER_STATUS",
+	}, plan.Statements)
+
+	// 数据库中的枚举类型和数组类型与模型一致时没有变更
+	actual = &TableSchema{Name: "enum_order", Columns: []*ColumnSchema{
+		{Name: "id", Type: "BIGINT", PrimaryKey: true, AutoIncr: true},
+		{Name: "status", Type: "ENUM_ORDER_STATUS", Nullable: true},
+		{Name: "level", Type: "ORDER_LEVEL", Nullable: true},
+		{Name: "tags", Type: "TEXT[]", Nullable: true},
+	}}
+	assert.True(t, buildSchemaPlan(d, desired, actual, true).Empty())
+}
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		name string
+		ddl  string
+		want []string
+	}{
+		{name: "single", ddl: "CREATE TABLE a (id INT);", want: []string{"CREATE TABLE a (id INT)"}},
+		{name: "multiple", ddl: "CREATE TABLE a (id INT);\nCREATE INDEX i ON a (id)", want: []string{"CREATE TABLE a (id INT)", "CREATE INDEX i ON a (id)"}},
+		{name: "quoted", ddl: "ALTER TABLE a ADD COLUMN b TEXT DEFAULT 'x;y';", want: []string{"ALTER TABLE a ADD COLUMN b TEXT DEFAULT 'x;y'"}},
+		{name: "dollar", ddl: "DO $$ BEGIN CREATE TYPE t AS ENUM ('a'); EXCEPTION WHEN duplicate_object THEN NULL; END $$;\nSELECT 1;",
+			want: []string{"DO $$ BEGIN CREATE TYPE t AS ENUM ('a'); EXCEPTION WHEN duplicate_object THEN NULL; END $$", "SELECT 1"}},
+		{name: "empty", ddl: " ;\n; ", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, splitStatements(tc.ddl))
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
+	"github.com/go-sql-driver/mysql"
+)
+
+// 约束错误的类型，执行语句返回的驱动错误转换为 *ConstraintError，使用 errors.Is 判断：
+//
+//	if errors.Is(err, orm.ErrDuplicateKey) {
+//		// 返回 409 Conflict
+//	}
+var (
+	ErrDuplicateKey        = ferr.ErrDuplicateKey
+	ErrForeignKeyViolation = ferr.ErrForeignKeyViolation
+	ErrNotNullViolation    = ferr.ErrNotNullViolation
+	ErrCheckViolation      = ferr.ErrCheckViolation
+	ErrDataTooLong         = ferr.ErrDataTooLong
+	ErrOutOfRange          = ferr.ErrOutOfRange
+)
+
+// ConstraintError 违反约束的错误，errors.Is 可以匹配 Kind，errors.As 可以取出驱动的原始错误
+type ConstraintError struct {
+	Kind       error  // 错误类型，如 ErrDuplicateKey
+	Column     string // 违反约束的列，联合约束为逗号分隔的列名，无法从错误中取得时为空
+	Constraint string // 约束或索引名，无法从错误中取得时为空
+	Err        error  // 驱动返回的原始错误
+}
+
+func (e *ConstraintError) Error() string {
+	msg := e.Kind.Error()
+	if e.Column != "" {
+		msg += " on column " + e.Column
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+var (
+	mysqlQuotedRe  = regexp.MustCompile("(?:for key|Column|column|Field|constraint) '([^']+)'")
+	mysqlFKRe      = regexp.MustCompile("CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]+)\\)")
+	pgKeyRe        = regexp.MustCompile(`Key \(([^)]+)\)=`)
+	pgColumnRe     = regexp.MustCompile(`column "([^"]+)"`)
+	sqliteFailedRe = regexp.MustCompile(`^(UNIQUE|NOT NULL|FOREIGN KEY|CHECK) constraint failed(?:: (.+))?$`)
+)
+
+// translateError 把驱动返回的约束错误转换为 *ConstraintError，其他错误原样返回
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *ConstraintError
+	if errors.As(err, &ce) {
+		return err
+	}
+
+	// MySQL：错误号区分错误类型，列名和约束名在错误信息中
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return translateMySQLError(err, mysqlErr)
+	}
+
+	// PostgreSQL：lib/pq 和 pgx 的错误都提供 SQLState，列名和约束名在错误的字段中
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return translatePostgresError(err, stateErr)
+	}
+
+	// SQLite：错误信息为 UNIQUE constraint failed: 表名.列名
+	return translateSqliteError(err)
+}
+
+func translateMySQLError(err error, mysqlErr *mysql.MySQLError) error {
+	var kind error
+	switch mysqlErr.Number {
+	case 1062, 1586: // Duplicate entry 'x' for key 'table.name'
+		kind = ErrDuplicateKey
+	case 1216, 1217, 1451, 1452: // Cannot add or update a child row: a foreign key constraint fails
+		kind = ErrForeignKeyViolation
+	case 1048, 1364: // Column 'name' cannot be null、Field 'name' doesn't have a default value
+		kind = ErrNotNullViolation
+	case 3819: // Check constraint 'name' is violated.
+		kind = ErrCheckViolation
+	case 1406: // Data too long for column 'name' at row 1
+		kind = ErrDataTooLong
+	case 1264: // Out of range value for column 'name' at row 1
+		kind = ErrOutOfRange
+	default:
+		return err
+	}
+
+	ce := &ConstraintError{Kind: kind, Err: err}
+	if m := mysqlFKRe.FindStringSubmatch(mysqlErr.Message); m != nil {
+		ce.Constraint = m[1]
+		ce.Column = strings.ReplaceAll(m[2], "`", "")
+	} else if m := mysqlQuotedRe.FindStringSubmatch(mysqlErr.Message); m != nil {
+		switch kind {
+		case ErrDuplicateKey:
+			// MySQL 8.0.19 之后索引名带有表名前缀
+			ce.Constraint = m[1][strings.LastIndexByte(m[1], '.')+1:]
+		case ErrCheckViolation:
+			ce.Constraint = m[1]
+		default:
+			ce.Column = m[1]
+		}
+	}
+	return ce
+}
+
+func translatePostgresError(err error, stateErr interface{ SQLState() string }) error {
+	var kind error
+	switch stateErr.SQLState() {
+	case "23505":
+		kind = ErrDuplicateKey
+	case "23503":
+		kind = ErrForeignKeyViolation
+	case "23502":
+		kind = ErrNotNullViolation
+	case "23514":
+		kind = ErrCheckViolation
+	case "22001":
+		kind = ErrDataTooLong
+	case "22003":
+		kind = ErrOutOfRange
+	default:
+		return err
+	}
+
+	// lib/pq 的字段为 Column、Constraint，pgx 的字段为 ColumnName、ConstraintName
+	ce := &ConstraintError{
+		Kind:       kind,
+		Column:     errorField(stateErr, "Column", "ColumnName"),
+		Constraint: errorField(stateErr, "Constraint", "ConstraintName"),
+		Err:        err,
+	}
+	if ce.Column == "" {
+		// 唯一约束和外键的列在 Detail 中：Key (email)=(a@b.c) already exists.
+		if m := pgKeyRe.FindStringSubmatch(errorField(stateErr, "Detail")); m != nil {
+			ce.Column = m[1]
+		} else if m := pgColumnRe.FindStringSubmatch(err.Error()); m != nil {
+			ce.Column = m[1]
+		}
+	}
+	return ce
+}
+
+func translateSqliteError(err error) error {
+	msg := err.Error()
+	// mattn/go-sqlite3 的错误信息就是 SQLite 的错误信息，modernc.org/sqlite 的错误信息带有错误码后缀
+	if i := strings.Index(msg, " ("); i > 0 {
+		msg = msg[:i]
+	}
+	m := sqliteFailedRe.FindStringSubmatch(msg)
+	if m == nil {
+		return err
+	}
+
+	ce := &ConstraintError{Err: err}
+	switch m[1] {
+	case "UNIQUE":
+		ce.Kind = ErrDuplicateKey
+	case "NOT NULL":
+		ce.Kind = ErrNotNullViolation
+	case "FOREIGN KEY":
+		ce.Kind = ErrForeignKeyViolation
+	case "CHECK":
+		ce.Kind = ErrCheckViolation
+		ce.Constraint = m[2]
+		return ce
+	}
+	if m[2] != "" {
+		// 列名带有表名前缀：users.tenant_id, users.code
+		cols := strings.Split(m[2], ", ")
+		for i, col := range cols {
+			cols[i] = col[strings.LastIndexByte(col, '.')+1:]
+		}
+		ce.Column = strings.Join(cols, ", ")
+	}
+	return ce
+}
+
+// errorField 读取驱动错误结构体中第一个非空的字符串字段
+func errorField(err any, names ...string) string {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,201 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pqError 与 lib/pq 的错误字段相同
+type pqError struct {
+	Code       string
+	Message    string
+	Detail     string
+	Column     string
+	Constraint string
+}
+
+func (e *pqError) Error() string    { return "pq: " + e.Message }
+func (e *pqError) SQLState() string { return e.Code }
+
+// pgxError 与 pgconn.PgError 的字段相同
+type pgxError struct {
+	Code           string
+	Message        string
+	Detail         string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *pgxError) Error() string    { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *pgxError) SQLState() string { return e.Code }
+
+func TestTranslateError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		wantKind       error
+		wantColumn     string
+		wantConstraint string
+	}{
+		{
+			name:           "mysql duplicate",
+			err:            &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'users.uk_users_email'"},
+			wantKind:       ErrDuplicateKey,
+			wantConstraint: "uk_users_email",
+		},
+		{
+			name: "mysql foreign key",
+			err: &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+				"(`shop`.`orders`, CONSTRAINT `fk_orders_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			wantKind:       ErrForeignKeyViolation,
+			wantColumn:     "user_id",
+			wantConstraint: "fk_orders_user_id",
+		},
+		{
+			name:       "mysql data too long",
+			err:        &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'name' at row 1"},
+			wantKind:   ErrDataTooLong,
+			wantColumn: "name",
+		},
+		{
+			name:       "mysql not null",
+			err:        &mysql.MySQLError{Number: 1048, Message: "Column 'email' cannot be null"},
+			wantKind:   ErrNotNullViolation,
+			wantColumn: "email",
+		},
+		{
+			name:       "mysql no default",
+			err:        &mysql.MySQLError{Number: 1364, Message: "Field 'email' doesn't have a default value"},
+			wantKind:   ErrNotNullViolation,
+			wantColumn: "email",
+		},
+		{
+			name:           "mysql check",
+			err:            &mysql.MySQLError{Number: 3819, Message: "Check constraint 'chk_age' is violated."},
+			wantKind:       ErrCheckViolation,
+			wantConstraint: "chk_age",
+		},
+		{
+			name:       "mysql out of range",
+			err:        &mysql.MySQLError{Number: 1264, Message: "Out of range value for column 'age' at row 1"},
+			wantKind:   ErrOutOfRange,
+			wantColumn: "age",
+		},
+		{
+			name: "pq duplicate",
+			err: &pqError{Code: "23505", Message: `duplicate key value violates unique constraint "users_email_key"`,
+				Detail: "Key (email)=(a@b.c) already exists.", Constraint: "users_email_key"},
+			wantKind:       ErrDuplicateKey,
+			wantColumn:     "email",
+			wantConstraint: "users_email_key",
+		},
+		{
+			name: "pgx foreign key",
+			err: &pgxError{Code: "23503", Message: `insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`,
+				Detail: `Key (user_id)=(3) is not present in table "users".`, ConstraintName: "orders_user_id_fkey"},
+			wantKind:       ErrForeignKeyViolation,
+			wantColumn:     "user_id",
+			wantConstraint: "orders_user_id_fkey",
+		},
+		{
+			name:       "pq not null",
+			err:        &pqError{Code: "23502", Message: `null value in column "name" of relation "users" violates not-null constraint`},
+			wantKind:   ErrNotNullViolation,
+			wantColumn: "name",
+		},
+		{
+			name:       "pgx not null column",
+			err:        &pgxError{Code: "23502", Message: "null value violates not-null constraint", ColumnName: "name"},
+			wantKind:   ErrNotNullViolation,
+			wantColumn: "name",
+		},
+		{
+			name:     "pq too long",
+			err:      &pqError{Code: "22001", Message: "value too long for type character varying(10)"},
+			wantKind: ErrDataTooLong,
+		},
+		{
+			name:       "sqlite unique",
+			err:        errors.New("UNIQUE constraint failed: users.tenant_id, users.code"),
+			wantKind:   ErrDuplicateKey,
+			wantColumn: "tenant_id, code",
+		},
+		{
+			name:       "sqlite not null with code",
+			err:        errors.New("NOT NULL constraint failed: users.name (1299)"),
+			wantKind:   ErrNotNullViolation,
+			wantColumn: "name",
+		},
+		{
+			name:     "sqlite foreign key",
+			err:      errors.New("FOREIGN KEY constraint failed"),
+			wantKind: ErrForeignKeyViolation,
+		},
+		{
+			name:           "sqlite check",
+			err:            errors.New("CHECK constraint failed: age > 0"),
+			wantKind:       ErrCheckViolation,
+			wantConstraint: "age > 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translateError(tc.err)
+			var ce *ConstraintError
+			require.ErrorAs(t, err, &ce)
+			assert.ErrorIs(t, err, tc.wantKind)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.wantColumn, ce.Column)
+			assert.Equal(t, tc.wantConstraint, ce.Constraint)
+			// 已经转换的错误不再转换
+			assert.Same(t, err, translateError(err))
+		})
+	}
+
+	// 其他错误原样返回
+	for _, err := range []error{
+		nil,
+		errors.New("syntax error"),
+		&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+		&pqError{Code: "40001", Message: "could not serialize access"},
+	} {
+		assert.Equal(t, err, translateError(err))
+	}
+}
+
+func TestConstraintError_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	driverErr := &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'name' at row 1"}
+	mock.ExpectExec("INSERT INTO `bulk_user`.*").WillReturnError(driverErr)
+	_, err = RegisterInserter[BulkUser](db).Insert(nil, &BulkUser{ID: 1, Name: "a"}).Exec(context.Background())
+
+	assert.ErrorIs(t, err, ErrDataTooLong)
+	var mysqlErr *mysql.MySQLError
+	require.ErrorAs(t, err, &mysqlErr)
+	assert.Equal(t, uint16(1406), mysqlErr.Number)
+	assert.EqualError(t, err, "orm: data too long on column name: Error 1406: Data too long for column 'name' at row 1")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `bulk_user`.*").WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'PRIMARY'"})
+	mock.ExpectRollback()
+	err = db.Tx(context.Background(), func(tx *Tx) error {
+		_, err := RegisterUpdater[BulkUser](tx).Update().Set(Col("Name"), "a").Exec(context.Background())
+		return err
+	}, nil)
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.False(t, IsTransientError(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
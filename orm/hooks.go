@@ -0,0 +1,161 @@
+package orm
+
+import "context"
+
+// HookEvent 生命周期事件
+type HookEvent string
+
+const (
+	BeforeInsertEvent HookEvent = "before_insert"
+	AfterInsertEvent  HookEvent = "after_insert"
+	BeforeUpdateEvent HookEvent = "before_update"
+	AfterUpdateEvent  HookEvent = "after_update"
+	BeforeDeleteEvent HookEvent = "before_delete"
+	AfterDeleteEvent  HookEvent = "after_delete"
+	AfterFindEvent    HookEvent = "after_find"
+)
+
+// BeforeInsertHook 模型在插入前执行的钩子，可以在这里设置创建时间等字段，返回错误时取消插入
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInsertHook 模型插入成功后执行的钩子，自增ID已经回填时可以读取
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// AfterFindHook 模型查询得到后执行的钩子，返回错误时查询返回该错误
+type AfterFindHook interface {
+	AfterFind(ctx context.Context) error
+}
+
+// AfterUpdateHook 模型更新后执行的钩子，在 Updater.ExecReturning 返回的每一行上调用
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// AfterDeleteHook 模型删除后执行的钩子，在 Deleter.ExecReturning 返回的每一行上调用
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// HookContext 全局钩子的上下文
+type HookContext struct {
+	Event HookEvent
+	Table string
+	// Value 插入和查询时为当前行的结构体指针；更新和删除按条件执行，
+	// Before 事件和 Exec 的 After 事件中为nil，ExecReturning 的 After 事件中为返回的行
+	Value any
+	// Query 更新和删除执行的语句，插入和查询时为nil
+	Query *Query
+}
+
+// HookFunc 全局钩子，返回错误时取消操作（Before 事件）或使操作返回该错误（After 事件）
+type HookFunc func(ctx context.Context, hc *HookContext) error
+
+// RegisterHook 注册全局钩子，对所有模型生效，同一事件的钩子按注册顺序执行
+// 需要在DB开始使用之前注册
+//
+//	db.RegisterHook(orm.BeforeInsertEvent, func(ctx context.Context, hc *orm.HookContext) error {
+//		if m, ok := hc.Value.(interface{ SetCreatedAt(time.Time) }); ok {
+//			m.SetCreatedAt(db.Now())
+//		}
+//		return nil
+//	})
+func (db *DB) RegisterHook(event HookEvent, hooks ...HookFunc) {
+	if db.hooks == nil {
+		db.hooks = make(map[HookEvent][]HookFunc)
+	}
+	db.hooks[event] = append(db.hooks[event], hooks...)
+}
+
+// runHooks 依次执行事件的全局钩子
+func (db *DB) runHooks(ctx context.Context, hc *HookContext) error {
+	for _, hook := range db.hooks[hc.Event] {
+		if err := hook(ctx, hc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRowHooks 判断事件是否有需要执行的全局钩子或模型钩子，sample 为模型的指针
+func (db *DB) hasRowHooks(event HookEvent, sample any) bool {
+	if len(db.hooks[event]) > 0 {
+		return true
+	}
+	switch event {
+	case BeforeInsertEvent:
+		_, ok := sample.(BeforeInsertHook)
+		return ok
+	case AfterInsertEvent:
+		_, ok := sample.(AfterInsertHook)
+		return ok
+	case AfterUpdateEvent:
+		_, ok := sample.(AfterUpdateHook)
+		return ok
+	case AfterDeleteEvent:
+		_, ok := sample.(AfterDeleteHook)
+		return ok
+	case AfterFindEvent:
+		_, ok := sample.(AfterFindHook)
+		return ok
+	}
+	return false
+}
+
+// runRowHook 对一行数据执行钩子，Before 事件先执行全局钩子再执行模型钩子，After 事件相反
+func (db *DB) runRowHook(ctx context.Context, event HookEvent, table string, row any) error {
+	hc := &HookContext{Event: event, Table: table, Value: row}
+	if event == BeforeInsertEvent {
+		if err := db.runHooks(ctx, hc); err != nil {
+			return err
+		}
+		return modelHook(ctx, event, row)
+	}
+	if err := modelHook(ctx, event, row); err != nil {
+		return err
+	}
+	return db.runHooks(ctx, hc)
+}
+
+// runRowHooks 对每一行依次执行钩子
+func runRowHooks[T any](ctx context.Context, db *DB, event HookEvent, table string, rows []*T) error {
+	if len(rows) == 0 || !db.hasRowHooks(event, new(T)) {
+		return nil
+	}
+	for _, row := range rows {
+		if err := db.runRowHook(ctx, event, table, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modelHook 调用模型实现的钩子
+func modelHook(ctx context.Context, event HookEvent, row any) error {
+	switch event {
+	case BeforeInsertEvent:
+		if h, ok := row.(BeforeInsertHook); ok {
+			return h.BeforeInsert(ctx)
+		}
+	case AfterInsertEvent:
+		if h, ok := row.(AfterInsertHook); ok {
+			return h.AfterInsert(ctx)
+		}
+	case AfterUpdateEvent:
+		if h, ok := row.(AfterUpdateHook); ok {
+			return h.AfterUpdate(ctx)
+		}
+	case AfterDeleteEvent:
+		if h, ok := row.(AfterDeleteHook); ok {
+			return h.AfterDelete(ctx)
+		}
+	case AfterFindEvent:
+		if h, ok := row.(AfterFindHook); ok {
+			return h.AfterFind(ctx)
+		}
+	}
+	return nil
+}
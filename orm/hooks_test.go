@@ -0,0 +1,244 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookCallsKey struct{}
+
+// HookModel 实现了所有模型钩子，调用记录保存在context中
+type HookModel struct {
+	ID   int
+	Name string
+	Job  sql.NullString
+}
+
+func recordHook(ctx context.Context, call string) {
+	if calls, ok := ctx.Value(hookCallsKey{}).(*[]string); ok {
+		*calls = append(*calls, call)
+	}
+}
+
+func (h *HookModel) BeforeInsert(ctx context.Context) error {
+	if h.Name == "" {
+		return errors.New("name is required")
+	}
+	h.Job = sql.NullString{String: "default", Valid: true}
+	recordHook(ctx, "model.BeforeInsert")
+	return nil
+}
+
+func (h *HookModel) AfterInsert(ctx context.Context) error {
+	recordHook(ctx, "model.AfterInsert")
+	return nil
+}
+
+func (h *HookModel) AfterFind(ctx context.Context) error {
+	recordHook(ctx, "model.AfterFind:"+h.Name)
+	return nil
+}
+
+func (h *HookModel) AfterDelete(ctx context.Context) error {
+	recordHook(ctx, "model.AfterDelete")
+	return nil
+}
+
+func recordGlobal(name string) HookFunc {
+	return func(ctx context.Context, hc *HookContext) error {
+		recordHook(ctx, "global."+name)
+		return nil
+	}
+}
+
+func TestHooks_Insert(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	db.RegisterHook(BeforeInsertEvent, recordGlobal("BeforeInsert"))
+	db.RegisterHook(AfterInsertEvent, func(ctx context.Context, hc *HookContext) error {
+		// 自增ID已经回填
+		recordHook(ctx, "global.AfterInsert")
+		assert.Equal(t, 5, hc.Value.(*HookModel).ID)
+		assert.Equal(t, "hook_model", hc.Table)
+		return nil
+	})
+
+	var calls []string
+	ctx := context.WithValue(context.Background(), hookCallsKey{}, &calls)
+
+	// 钩子设置的字段会被写入
+	mock.ExpectExec("INSERT INTO `hook_model` (`name`, `job`) VALUES (?, ?);").
+		WithArgs("Tom", sql.NullString{String: "default", Valid: true}).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+
+	row := &HookModel{Name: "Tom"}
+	_, err = RegisterInserter[HookModel](db).Insert([]string{"Name", "Job"}, row).Returning().Exec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"global.BeforeInsert", "model.BeforeInsert",
+		"model.AfterInsert", "global.AfterInsert",
+	}, calls)
+
+	// BeforeInsert 返回错误时不执行插入
+	_, err = RegisterInserter[HookModel](db).Insert([]string{"Name"}, &HookModel{}).Exec(ctx)
+	assert.EqualError(t, err, "name is required")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHooks_AfterFind(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	db.RegisterHook(AfterFindEvent, recordGlobal("AfterFind"))
+
+	var calls []string
+	ctx := context.WithValue(context.Background(), hookCallsKey{}, &calls)
+
+	mock.ExpectQuery("SELECT * FROM `hook_model`;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil).AddRow(2, "Jerry", nil))
+	_, err = RegisterSelector[HookModel](db).Select().GetMulti(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"model.AfterFind:Tom", "global.AfterFind",
+		"model.AfterFind:Jerry", "global.AfterFind",
+	}, calls)
+
+	// 钩子返回的错误由查询返回
+	db.RegisterHook(AfterFindEvent, func(ctx context.Context, hc *HookContext) error {
+		return errors.New("forbidden")
+	})
+	mock.ExpectQuery("SELECT * FROM `hook_model` WHERE `id` = ?;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil))
+	_, err = RegisterSelector[HookModel](db).Select().Where(Col("ID").Eq(1)).Get(ctx)
+	assert.EqualError(t, err, "forbidden")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// ShoutModel 的 AfterFind 钩子修改字段，用于检查钩子只执行一次
+type ShoutModel struct {
+	ID   int
+	Name string
+}
+
+func (m *ShoutModel) AfterFind(ctx context.Context) error {
+	m.Name += "!"
+	return nil
+}
+
+func TestHooks_AfterFindWithCache(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql", WithDBCache(NewMemoryCache()))
+	require.NoError(t, err)
+	db.SetModelCacheConfig("shout_model", &ModelCacheConfig{
+		Enabled: true,
+		TTL:     time.Minute,
+	})
+
+	mock.ExpectQuery("SELECT * FROM `shout_model` WHERE `id` = ?;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom"))
+	mock.ExpectQuery("SELECT * FROM `shout_model`;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom").AddRow(2, "Jerry"))
+
+	// 未命中和命中缓存时钩子都只执行一次
+	for i := 0; i < 2; i++ {
+		res, err := RegisterSelector[ShoutModel](db).Select().Where(Col("ID").Eq(1)).WithCache().Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Tom!", res.Name)
+
+		list, err := RegisterSelector[ShoutModel](db).Select().WithCache().GetMulti(context.Background())
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, "Tom!", list[0].Name)
+		assert.Equal(t, "Jerry!", list[1].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHooks_UpdateDelete(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	var calls []string
+	ctx := context.WithValue(context.Background(), hookCallsKey{}, &calls)
+
+	db.RegisterHook(BeforeUpdateEvent, func(ctx context.Context, hc *HookContext) error {
+		recordHook(ctx, "global.BeforeUpdate:"+hc.Query.SQL)
+		return nil
+	})
+	db.RegisterHook(AfterUpdateEvent, recordGlobal("AfterUpdate"))
+	db.RegisterHook(BeforeDeleteEvent, func(ctx context.Context, hc *HookContext) error {
+		if hc.Table == "hook_model" && len(hc.Query.Args) == 0 {
+			return errors.New("delete without where")
+		}
+		return nil
+	})
+	db.RegisterHook(AfterDeleteEvent, recordGlobal("AfterDelete"))
+
+	mock.ExpectExec(`UPDATE "hook_model" SET "name" = $1 WHERE "id" = $2;`).
+		WithArgs("Tom", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = RegisterUpdater[HookModel](db).Update().Set(Col("Name"), "Tom").Where(Col("ID").Eq(1)).Exec(ctx)
+	require.NoError(t, err)
+
+	// Before 钩子返回错误时不执行删除
+	_, err = RegisterDeleter[HookModel](db).Delete().Exec(ctx)
+	assert.EqualError(t, err, "delete without where")
+
+	// ExecReturning 在返回的每一行上执行钩子
+	mock.ExpectQuery(`DELETE FROM "hook_model" WHERE "id" = $1 RETURNING *;`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil))
+	_, err = RegisterDeleter[HookModel](db).Delete().Where(Col("ID").Eq(1)).ExecReturning(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`global.BeforeUpdate:UPDATE "hook_model" SET "name" = $1 WHERE "id" = $2;`,
+		"global.AfterUpdate",
+		"model.AfterDelete", "global.AfterDelete",
+	}, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHooks_Collection(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	db.RegisterHook(AfterInsertEvent, recordGlobal("AfterInsert"))
+
+	var calls []string
+	ctx := context.WithValue(context.Background(), hookCallsKey{}, &calls)
+
+	mock.ExpectExec("INSERT INTO `hook_model`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT \\* FROM `hook_model`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil))
+
+	users := New(db).Collection(&HookModel{})
+	_, err = users.Insert(ctx, &HookModel{Name: "Tom"})
+	require.NoError(t, err)
+	_, err = users.Find(ctx, Col("ID").Eq(1))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"model.BeforeInsert", "model.AfterInsert", "global.AfterInsert", "model.AfterFind:Tom"}, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
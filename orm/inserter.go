@@ -2,6 +2,8 @@ package orm
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 	"reflect"
 	"strings"
@@ -13,25 +15,44 @@ type Inserter[T any] struct {
 	model   *model
 	dialect Dialect
 	layer   Layer
+	rows    []*T     // 插入的数据，用于回填 RETURNING 的结果和分批执行
+	fields  []string // 插入的字段名
+
+	upsert       bool // 分批执行时每个批次重新构建 UPSERT 子句
+	conflictCols []*Column
+	upsertCols   []*Column
+
+	batchSize int       // 每条语句插入的行数，为0时不分批
+	perRow    bool      // 逐行执行插入
+	stmt      *sql.Stmt // 分批执行时复用的预编译语句
+
+	returning    []string // RETURNING 的字段名
+	hasReturning bool
 
 	// 缓存相关字段
-	invalidateCache bool     // 是否使缓存失效
-	invalidateTags  []string // 要失效的缓存标签
+	invalidation   cacheInvalidation // 执行成功后使缓存失效的方式，默认在模型启用缓存时自动失效
+	invalidateTags []string          // 要失效的缓存标签
 }
 
-// WithInvalidateCache 设置是否使相关缓存失效
+// WithInvalidateCache 执行成功后总是使相关缓存失效，即使模型关闭了自动失效
 func (i *Inserter[T]) WithInvalidateCache() *Inserter[T] {
-	i.invalidateCache = true
+	i.invalidation = invalidateAlways
 	return i
 }
 
 // WithInvalidateTags 设置要使失效的缓存标签
 func (i *Inserter[T]) WithInvalidateTags(tags ...string) *Inserter[T] {
-	i.invalidateCache = true
+	i.invalidation = invalidateAlways
 	i.invalidateTags = tags
 	return i
 }
 
+// WithoutInvalidateCache 执行成功后不使缓存失效
+func (i *Inserter[T]) WithoutInvalidateCache() *Inserter[T] {
+	i.invalidation = invalidateNever
+	return i
+}
+
 func RegisterInserter[T any](layer Layer) *Inserter[T] {
 	var val T
 
@@ -51,6 +72,9 @@ func RegisterInserter[T any](layer Layer) *Inserter[T] {
 		}
 	}
 
+	// 缓存的模型被多个构造器共享，每个构造器使用自己的副本
+	m = m.clone()
+
 	// 结构体或者结构体指针实现TableNamer接口即可
 	if tablename, ok := any(val).(TableNamer); ok {
 		m.table = tablename.TableName()
@@ -84,7 +108,6 @@ func (i *Inserter[T]) Insert(cols []string, vals ...*T) *Inserter[T] {
 
 	colsString := strings.Builder{}
 	placeholders := strings.Builder{}
-	basePlaceHolders := strings.Builder{}
 
 	// 使用cols来确定要插入的列
 	fields := make([]string, 0, len(cols))
@@ -103,27 +126,33 @@ func (i *Inserter[T]) Insert(cols []string, vals ...*T) *Inserter[T] {
 
 	// 构建列名部分
 	colsString.WriteByte('(')
-	basePlaceHolders.WriteByte('(')
 	for idx, fieldName := range fields {
 		col, ok := i.model.fieldsMap[fieldName]
 		if !ok {
 			panic(ferr.ErrInvalidColumn(fieldName))
 		}
 		colsString.WriteString(i.dialect.Quote(col.colName))
-		basePlaceHolders.WriteString(i.dialect.Placeholder(i.model.index))
-		i.model.index ++
 		if idx != len(fields)-1 {
 			colsString.WriteString(", ")
-			basePlaceHolders.WriteString(", ")
 		}
 	}
 	colsString.WriteByte(')')
-	basePlaceHolders.WriteByte(')')
 
-	// 构建值部分
+	i.fields = fields
+
+	// 构建值部分，每一行使用递增的占位符（PostgreSQL的$n不能在行之间复用）
+	i.rows = vals
 	for index, val := range vals {
 		v := reflect.ValueOf(val).Elem()
-		placeholders.WriteString(basePlaceHolders.String())
+		placeholders.WriteByte('(')
+		for idx := range fields {
+			placeholders.WriteString(i.dialect.Placeholder(i.model.index))
+			i.model.index ++
+			if idx != len(fields)-1 {
+				placeholders.WriteString(", ")
+			}
+		}
+		placeholders.WriteByte(')')
 		if index != len(vals)-1 {
 			placeholders.WriteString(", ")
 		}
@@ -131,7 +160,7 @@ func (i *Inserter[T]) Insert(cols []string, vals ...*T) *Inserter[T] {
 		// 只取指定列的值
 		for _, fieldName := range fields {
 			valField := v.FieldByName(fieldName)
-			i.values = append(i.values, valField.Interface())
+			i.values = append(i.values, fieldArg(i.dialect, i.model.fieldsMap[fieldName], valField.Interface()))
 		}
 	}
 
@@ -146,55 +175,289 @@ func (i *Inserter[T]) Upsert(conflictCols []*Column, cols []*Column) *Inserter[T
 
 	dialect, ok := db.dialect.(interface {
 		BuildUpsert(builder *strings.Builder, conflictCols []*Column, cols []*Column)
-		setModel(m *model)
 	})
 	if !ok {
 		panic(ferr.ErrInvalidDialect(db.dialect))
 	}
 
-	// 注入模型信息
-	dialect.setModel(i.model)
+	// 注入模型信息，方言被所有构造器共享，不能把模型保存在方言中
+	for _, col := range conflictCols {
+		col.model = i.model
+	}
+	for _, col := range cols {
+		col.model = i.model
+	}
 	dialect.BuildUpsert(i.builder, conflictCols, cols)
+	i.upsert, i.conflictCols, i.upsertCols = true, conflictCols, cols
+	return i
+}
+
+// Batch 将插入的数据按 size 行一批拆分为多条语句执行，避免单条语句超出数据库的占位符数量限制
+// 行数相同的批次生成的SQL相同，会复用同一个预编译语句；size 不大于0时不分批
+// 各批次依次执行，某一批次失败时之前的批次不会回滚，需要原子性时请在事务中执行
+func (i *Inserter[T]) Batch(size int) *Inserter[T] {
+	i.batchSize = size
+	return i
+}
+
+// PerRow 每条语句只插入一行，所有行复用同一个预编译语句，设置后忽略 Batch 的批次大小
+// 适用于不支持多行 VALUES 的场景，或需要 MySQL、SQLite 逐行得到准确自增ID的场景
+func (i *Inserter[T]) PerRow() *Inserter[T] {
+	i.perRow = true
+	return i
+}
+
+// Returning 插入后把数据库生成的值回填到传入 Insert 的结构体中，cols 为字段名
+// 支持 RETURNING 的方言（如PostgreSQL）使用 RETURNING 子句，cols 为空时返回所有列；
+// 其余方言通过 LastInsertId 回填自增ID，此时只能指定一个整数字段，为空时使用自增列或主键
+func (i *Inserter[T]) Returning(cols ...string) *Inserter[T] {
+	i.returning = cols
+	i.hasReturning = true
 	return i
 }
 
 func (i *Inserter[T]) Build() (*Query, error) {
-	i.builder.WriteByte(';')
+	// RETURNING 和结尾的分号写入新的 builder，Build 可以多次调用
+	builder := &strings.Builder{}
+	builder.WriteString(i.builder.String())
+	if i.hasReturning && supportsReturning(i.dialect) {
+		if err := buildReturning(builder, i.dialect, i.model, i.returning); err != nil {
+			return nil, err
+		}
+	}
+	builder.WriteByte(';')
 
 	return &Query{
-		SQL:  i.builder.String(),
+		SQL:  builder.String(),
 		Args: i.values,
 	}, nil
 }
 
-// Exec 添加了缓存失效逻辑
+// Clone 复制当前已经构建的部分，返回的插入器与原插入器互不影响
+func (i *Inserter[T]) Clone() *Inserter[T] {
+	c := *i
+	c.builder = &strings.Builder{}
+	c.builder.WriteString(i.builder.String())
+	c.model = i.model.clone()
+	c.values = append([]any(nil), i.values...)
+	c.rows = append([]*T(nil), i.rows...)
+	c.fields = append([]string(nil), i.fields...)
+	c.returning = append([]string(nil), i.returning...)
+	c.invalidateTags = append([]string(nil), i.invalidateTags...)
+	c.stmt = nil
+	return &c
+}
+
+// Exec 执行插入，成功后按配置使缓存失效
 func (i *Inserter[T]) Exec(ctx context.Context) (Result, error) {
-	q, err := i.Build()
+	var (
+		res sql.Result
+		err error
+	)
+	db := i.layer.getDB()
+	// 钩子可能修改插入的字段，执行钩子后需要重新生成语句参数
+	target := i
+	if db.hasRowHooks(BeforeInsertEvent, new(T)) {
+		if err = runRowHooks(ctx, db, BeforeInsertEvent, i.model.table, i.rows); err != nil {
+			return Result{err: err}, err
+		}
+		target = i.chunk(i.rows)
+	}
+
+	if size := i.chunkSize(); size < len(i.rows) {
+		res, err = i.execBatches(ctx, size)
+	} else {
+		var q *Query
+		if q, err = target.Build(); err != nil {
+			return Result{}, err
+		}
+		res, err = target.exec(ctx, q)
+	}
+
+	// 执行成功后按配置使缓存失效
+	if err == nil {
+		invalidateAfterWrite(ctx, i.layer, i.model.GetTableName(), i.invalidation, i.invalidateTags)
+	}
+
+	if err == nil {
+		err = runRowHooks(ctx, db, AfterInsertEvent, i.model.table, i.rows)
+	}
 	if err != nil {
-		return Result{}, err
+		return Result{err: err}, err
 	}
+	return Result{
+		res: res,
+	}, nil
+}
 
+// exec 执行一条插入语句，设置了 RETURNING 时回填插入的结构体
+func (i *Inserter[T]) exec(ctx context.Context, q *Query) (sql.Result, error) {
 	qc := &QueryContext{
 		QueryType: "exec",
 		Query:     q,
 		Model:     i.model,
 		Builder:   i,
+		stmt:      i.stmt,
+	}
+
+	if i.hasReturning && supportsReturning(i.dialect) {
+		qc.QueryType = "query"
+		res, err := i.layer.HandleQuery(ctx, qc)
+		if err != nil {
+			return nil, err
+		}
+		return i.scanReturning(res.Rows)
 	}
 
 	res, err := i.layer.HandleQuery(ctx, qc)
+	if err != nil {
+		return nil, err
+	}
+	if i.hasReturning {
+		if err = i.fillInsertIDs(res.Result.res); err != nil {
+			return nil, err
+		}
+	}
+	return res.Result.res, nil
+}
+
+// chunkSize 返回每条语句插入的行数，不分批时返回总行数
+func (i *Inserter[T]) chunkSize() int {
+	switch {
+	case i.perRow:
+		return 1
+	case i.batchSize > 0:
+		return i.batchSize
+	default:
+		return len(i.rows)
+	}
+}
+
+// execBatches 按 size 行一批依次执行，完整的批次复用同一个预编译语句，最后不足 size 行的批次直接执行
+func (i *Inserter[T]) execBatches(ctx context.Context, size int) (sql.Result, error) {
+	var (
+		result batchResult
+		stmt   *sql.Stmt
+	)
+	defer func() {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+	}()
 
-	// 如果执行成功且需要使缓存失效
-	if err == nil && i.invalidateCache {
-		db := i.layer.getDB()
-		if db.cacheManager != nil && db.cacheManager.IsEnabled() {
-			modelName := i.model.GetTableName()
-			// 传入标签或使用模型的默认标签
-			_ = db.cacheManager.InvalidateCache(ctx, modelName, i.invalidateTags...)
+	for start := 0; start < len(i.rows); start += size {
+		chunk := i.chunk(i.rows[start:min(start+size, len(i.rows))])
+		q, err := chunk.Build()
+		if err != nil {
+			return nil, err
+		}
+
+		// 完整批次超过一个时才值得预编译
+		if len(chunk.rows) == size && len(i.rows)/size > 1 {
+			if stmt == nil {
+				if stmt, err = i.layer.prepareContext(ctx, q.SQL); err != nil {
+					return nil, err
+				}
+			}
+			chunk.stmt = stmt
+		}
+
+		res, err := chunk.exec(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("orm: insert batch %d: %w", start/size+1, err)
+		}
+		if err = result.add(res); err != nil {
+			return nil, err
 		}
 	}
+	return result, nil
+}
 
-	return Result{
-		res: res.Result.res,
-		err: err,
-	}, err
+// chunk 使用相同的列、UPSERT 和 RETURNING 设置构建只插入 rows 的插入器
+func (i *Inserter[T]) chunk(rows []*T) *Inserter[T] {
+	c := RegisterInserter[T](i.layer)
+	c.Insert(i.fields, rows...)
+	if i.upsert {
+		c.Upsert(i.conflictCols, i.upsertCols)
+	}
+	c.returning, c.hasReturning = i.returning, i.hasReturning
+	return c
+}
+
+// batchResult 分批执行的结果，受影响行数为各批次之和，LastInsertId 取自最后一个批次
+type batchResult struct {
+	last     sql.Result
+	affected int64
+}
+
+func (r *batchResult) add(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	r.last = res
+	r.affected += affected
+	return nil
+}
+
+func (r batchResult) LastInsertId() (int64, error) {
+	return r.last.LastInsertId()
+}
+
+func (r batchResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// scanReturning 按顺序将 RETURNING 返回的行扫描到插入的结构体中
+// 返回的第一列（未指定列时为自增列或主键）为整数时，最后一行的值作为 LastInsertId
+func (i *Inserter[T]) scanReturning(rows *sql.Rows) (sql.Result, error) {
+	defer rows.Close()
+
+	idField, _ := generatedIDField(i.model, i.returning[:min(len(i.returning), 1)])
+	var result returningResult
+	for idx := 0; rows.Next(); idx++ {
+		if idx >= len(i.rows) {
+			break
+		}
+		val := reflect.ValueOf(i.rows[idx]).Elem()
+		if err := scanModelRow(rows, i.model, val); err != nil {
+			return nil, err
+		}
+		i.layer.getDB().localizeTimes(val)
+		result.affected++
+
+		if idField != "" {
+			result.lastID, result.hasID = intField(val.FieldByName(idField))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fillInsertIDs 不支持 RETURNING 时通过 LastInsertId 回填自增ID，批量插入时假设自增ID连续
+func (i *Inserter[T]) fillInsertIDs(res sql.Result) error {
+	fieldName, err := generatedIDField(i.model, i.returning)
+	if err != nil {
+		return err
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	firstID := lastID
+	if rd, ok := i.dialect.(ReturningDialect); ok {
+		firstID = rd.FirstInsertID(lastID, len(i.rows))
+	} else if len(i.rows) > 1 {
+		return ErrReturningNotSupported
+	}
+
+	for idx, row := range i.rows {
+		if err := setIntField(reflect.ValueOf(row).Elem().FieldByName(fieldName), firstID+int64(idx)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -1,7 +1,10 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -199,7 +202,7 @@ func TestInserter_Upsert(t *testing.T) {
 				Insert(nil, &testModel).
 				Upsert(nil, []*Column{Col("ID"), Col("Name")}),
 			wantQuery: &Query{
-				SQL:  "INSERT INTO `test_model` (`id`, `name`, `job`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `id` = VALUES(id), `name` = VALUES(name);",
+				SQL:  "INSERT INTO `test_model` (`id`, `name`, `job`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `id` = VALUES(`id`), `name` = VALUES(`name`);",
 				Args: []any{1, "Tom", sql.NullString{String: "Engineer", Valid: true}},
 			},
 		},
@@ -209,7 +212,7 @@ func TestInserter_Upsert(t *testing.T) {
 				Insert(nil, &testModel).
 				Upsert([]*Column{Col("ID"), Col("Name")}, []*Column{Col("ID"), Col("Name")}),
 			wantQuery: &Query{
-				SQL:  "INSERT INTO \"test_model\" (\"id\", \"name\", \"job\") VALUES (?, ?, ?) ON CONFLICT(\"id\", \"name\") DO UPDATE SET id = EXCLUDED.id, name = EXCLUDED.name;",
+				SQL:  "INSERT INTO \"test_model\" (\"id\", \"name\", \"job\") VALUES (?, ?, ?) ON CONFLICT(\"id\", \"name\") DO UPDATE SET \"id\" = EXCLUDED.\"id\", \"name\" = EXCLUDED.\"name\";",
 				Args: []any{1, "Tom", sql.NullString{String: "Engineer", Valid: true}},
 			},
 		},
@@ -223,3 +226,163 @@ func TestInserter_Upsert(t *testing.T) {
 		})
 	}
 }
+
+func TestInserter_Batch(t *testing.T) {
+	newRows := func(n int) []*TestModel {
+		rows := make([]*TestModel, n)
+		for i := range rows {
+			rows[i] = &TestModel{Name: fmt.Sprintf("user%d", i)}
+		}
+		return rows
+	}
+
+	testCases := []struct {
+		name     string
+		dialect  string
+		rows     []*TestModel
+		q        func(i *Inserter[TestModel]) *Inserter[TestModel]
+		expect   func(mock sqlmock.Sqlmock)
+		wantIDs  []int
+		affected int64
+		wantErr  string
+	}{
+		{
+			name:    "full batches reuse prepared statement",
+			dialect: "mysql",
+			rows:    newRows(5),
+			q: func(i *Inserter[TestModel]) *Inserter[TestModel] {
+				return i.Batch(2)
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				prep := mock.ExpectPrepare("INSERT INTO `test_model` (`name`) VALUES (?), (?);")
+				prep.ExpectExec().WithArgs("user0", "user1").WillReturnResult(sqlmock.NewResult(1, 2))
+				prep.ExpectExec().WithArgs("user2", "user3").WillReturnResult(sqlmock.NewResult(3, 2))
+				prep.WillBeClosed()
+				mock.ExpectExec("INSERT INTO `test_model` (`name`) VALUES (?);").
+					WithArgs("user4").WillReturnResult(sqlmock.NewResult(5, 1))
+			},
+			affected: 5,
+		},
+		{
+			name:    "single batch is not prepared",
+			dialect: "mysql",
+			rows:    newRows(2),
+			q: func(i *Inserter[TestModel]) *Inserter[TestModel] {
+				return i.Batch(10)
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `test_model` (`name`) VALUES (?), (?);").
+					WillReturnResult(sqlmock.NewResult(1, 2))
+			},
+			affected: 2,
+		},
+		{
+			name:    "per row fills insert ids",
+			dialect: "sqlite",
+			rows:    newRows(3),
+			q: func(i *Inserter[TestModel]) *Inserter[TestModel] {
+				return i.Batch(2).PerRow().Returning()
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				prep := mock.ExpectPrepare(`INSERT INTO "test_model" ("name") VALUES (?);`)
+				prep.ExpectExec().WithArgs("user0").WillReturnResult(sqlmock.NewResult(4, 1))
+				prep.ExpectExec().WithArgs("user1").WillReturnResult(sqlmock.NewResult(5, 1))
+				prep.ExpectExec().WithArgs("user2").WillReturnResult(sqlmock.NewResult(6, 1))
+			},
+			wantIDs:  []int{4, 5, 6},
+			affected: 3,
+		},
+		{
+			name:    "postgresql placeholders restart in each batch",
+			dialect: "postgresql",
+			rows:    newRows(3),
+			q: func(i *Inserter[TestModel]) *Inserter[TestModel] {
+				return i.Batch(2).Returning("ID")
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "test_model" ("name") VALUES ($1), ($2) RETURNING "id";`).
+					WithArgs("user0", "user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectQuery(`INSERT INTO "test_model" ("name") VALUES ($1) RETURNING "id";`).
+					WithArgs("user2").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+			},
+			wantIDs:  []int{1, 2, 3},
+			affected: 3,
+		},
+		{
+			name:    "failed batch",
+			dialect: "mysql",
+			rows:    newRows(4),
+			q: func(i *Inserter[TestModel]) *Inserter[TestModel] {
+				return i.Batch(2)
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				prep := mock.ExpectPrepare("INSERT INTO `test_model` (`name`) VALUES (?), (?);")
+				prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 2))
+				prep.ExpectExec().WillReturnError(errors.New("duplicate entry"))
+			},
+			wantErr: "orm: insert batch 2: duplicate entry",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mockDB.Close()
+			db, err := Open(mockDB, tc.dialect)
+			require.NoError(t, err)
+			tc.expect(mock)
+
+			res, err := tc.q(RegisterInserter[TestModel](db).Insert([]string{"Name"}, tc.rows...)).
+				Exec(context.Background())
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			affected, err := res.RowsAffected()
+			require.NoError(t, err)
+			assert.Equal(t, tc.affected, affected)
+			if tc.wantIDs != nil {
+				ids := make([]int, 0, len(tc.rows))
+				for _, row := range tc.rows {
+					ids = append(ids, row.ID)
+				}
+				assert.Equal(t, tc.wantIDs, ids)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestInserter_Clone(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	base := RegisterInserter[TestModel](db).Insert([]string{"ID", "Name"}, &TestModel{ID: 1, Name: "Tom"})
+	upsert := base.Clone().Upsert([]*Column{Col("ID")}, []*Column{Col("Name")})
+
+	q, err := upsert.Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  `INSERT INTO "test_model" ("id", "name") VALUES ($1, $2) ON CONFLICT("id") DO UPDATE SET "name" = EXCLUDED."name";`,
+		Args: []any{1, "Tom"},
+	}, q)
+
+	// 原插入器不受影响，并且可以多次 Build
+	for j := 0; j < 2; j++ {
+		q, err = base.Returning("ID").Build()
+		require.NoError(t, err)
+		assert.Equal(t, &Query{
+			SQL:  `INSERT INTO "test_model" ("id", "name") VALUES ($1, $2) RETURNING "id";`,
+			Args: []any{1, "Tom"},
+		}, q)
+	}
+}
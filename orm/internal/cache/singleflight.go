@@ -0,0 +1,71 @@
+package cache
+
+import "sync"
+
+// call 正在执行或已经完成的一次调用
+type call struct {
+	wg   sync.WaitGroup
+	val  interface{}
+	err  error
+	dups int
+}
+
+// Group 合并相同键的并发调用，同一时刻每个键只执行一次，其他调用方等待并共享结果
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+	bg    map[string]struct{} // 正在后台执行的键
+}
+
+// Do 执行 fn 并返回结果，键相同的调用正在执行时等待它完成并共享结果。
+// shared 表示结果被多个调用方共享
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		shared = c.dups > 0
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
+
+// TryGo 在后台执行 fn，键相同的后台任务正在执行时直接返回 false
+func (g *Group) TryGo(key string, fn func()) bool {
+	g.mu.Lock()
+	if g.bg == nil {
+		g.bg = make(map[string]struct{})
+	}
+	if _, ok := g.bg[key]; ok {
+		g.mu.Unlock()
+		return false
+	}
+	g.bg[key] = struct{}{}
+	g.mu.Unlock()
+
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			delete(g.bg, key)
+			g.mu.Unlock()
+		}()
+		fn()
+	}()
+	return true
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var g Group
+	var calls int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	shared := make([]bool, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, shared[i] = g.Do("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "value", nil
+			})
+		}(i)
+	}
+	// 等待所有调用方进入等待
+	assert.Eventually(t, func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		c, ok := g.calls["key"]
+		return ok && c.dups == 9
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	for i := range results {
+		assert.Equal(t, "value", results[i])
+		assert.True(t, shared[i])
+	}
+
+	// 调用完成后再次执行
+	v, _, s := g.Do("key", func() (interface{}, error) { return "again", nil })
+	assert.Equal(t, "again", v)
+	assert.False(t, s)
+}
+
+func TestGroup_TryGo(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	assert.True(t, g.TryGo("key", func() {
+		<-release
+		close(done)
+	}))
+	assert.False(t, g.TryGo("key", func() {}))
+	assert.True(t, g.TryGo("other", func() {}))
+
+	close(release)
+	<-done
+	assert.Eventually(t, func() bool {
+		return g.TryGo("key", func() {})
+	}, time.Second, time.Millisecond)
+}
@@ -24,6 +24,16 @@ var (
 	ErrDBClosed                = errors.New("orm: operation on a closed database")
 )
 
+// 约束错误的类型，由各数据库驱动的错误转换而来
+var (
+	ErrDuplicateKey        = errors.New("orm: duplicate key")
+	ErrForeignKeyViolation = errors.New("orm: foreign key violation")
+	ErrNotNullViolation    = errors.New("orm: not null violation")
+	ErrCheckViolation      = errors.New("orm: check constraint violation")
+	ErrDataTooLong         = errors.New("orm: data too long")
+	ErrOutOfRange          = errors.New("orm: value out of range")
+)
+
 func ErrInvalidColumn(col string) error {
 	return fmt.Errorf("invalid column name: %s", col)
 }
@@ -32,6 +42,14 @@ func ErrInvalidTag(tag string) error {
 	return fmt.Errorf("orm: invalid tag %s", tag)
 }
 
+func ErrInvalidIndex(name string) error {
+	return fmt.Errorf("orm: index %s is declared as both unique and non-unique", name)
+}
+
+func ErrStaleModelMeta(model any, field string) error {
+	return fmt.Errorf("orm: model meta of %v is out of date, field %s not found, please regenerate it", model, field)
+}
+
 func ErrInvalidSelectable(col any) error {
 	return fmt.Errorf("invalid selectable column: %v", col)
 }
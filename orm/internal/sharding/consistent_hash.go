@@ -0,0 +1,293 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrEmptyHashRing 哈希环上没有可用的数据库，所有数据库的权重都为0
+var ErrEmptyHashRing = errors.New("no database available in hash ring")
+
+// DefaultVirtualNodes 一致性哈希中每个单位权重对应的虚拟节点数
+const DefaultVirtualNodes = 160
+
+// WeightedStrategy 支持按权重分配数据的分片策略，权重越大的数据库分到的数据越多
+type WeightedStrategy interface {
+	Strategy
+
+	// SetWeight 设置数据库的权重，权重为0时该数据库不再分配数据
+	SetWeight(dbIndex, weight int) error
+
+	// Weight 获取数据库的权重
+	Weight(dbIndex int) int
+}
+
+// ringNode 哈希环上的虚拟节点
+type ringNode struct {
+	hash    uint32
+	dbIndex int
+}
+
+// ConsistentHashStrategy 基于一致性哈希的分片策略。
+// 增加或者移除数据库时，只有哈希环上相邻区间的数据需要迁移，而不是像取模那样几乎全部重新分布
+type ConsistentHashStrategy struct {
+	*BaseStrategy
+	VirtualNodes int // 每个单位权重对应的虚拟节点数
+
+	mu      sync.RWMutex
+	weights []int
+	ring    []ringNode
+}
+
+// NewConsistentHashStrategy 创建基于一致性哈希的分片策略，virtualNodes 小于等于0时使用 DefaultVirtualNodes
+func NewConsistentHashStrategy(dbPrefix string, dbCount int, tablePrefix string, tableCount int, shardKey string, virtualNodes int) *ConsistentHashStrategy {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	weights := make([]int, dbCount)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	s := &ConsistentHashStrategy{
+		BaseStrategy: NewBaseStrategy(dbPrefix, dbCount, tablePrefix, tableCount, shardKey),
+		VirtualNodes: virtualNodes,
+		weights:      weights,
+	}
+	s.buildRing()
+	return s
+}
+
+// buildRing 根据权重重新生成哈希环，调用方需要持有写锁
+func (s *ConsistentHashStrategy) buildRing() {
+	ring := make([]ringNode, 0, len(s.weights)*s.VirtualNodes)
+	for i, w := range s.weights {
+		// 虚拟节点的哈希值只与数据库名称有关，增加数据库不会改变已有节点的位置
+		name := s.DBPrefix + strconv.Itoa(i)
+		for v := 0; v < w*s.VirtualNodes; v++ {
+			ring = append(ring, ringNode{
+				hash:    crc32.ChecksumIEEE([]byte(name + "#" + strconv.Itoa(v))),
+				dbIndex: i,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash == ring[j].hash {
+			return ring[i].dbIndex < ring[j].dbIndex
+		}
+		return ring[i].hash < ring[j].hash
+	})
+	s.ring = ring
+}
+
+// locate 查找哈希值在环上顺时针方向的第一个节点，调用方需要持有读锁
+func (s *ConsistentHashStrategy) locate(hash uint32) (int, bool) {
+	if len(s.ring) == 0 {
+		return 0, false
+	}
+	i := sort.Search(len(s.ring), func(i int) bool { return s.ring[i].hash >= hash })
+	if i == len(s.ring) {
+		i = 0
+	}
+	return s.ring[i].dbIndex, true
+}
+
+// Hash 计算键值在哈希环上的位置
+func (s *ConsistentHashStrategy) Hash(key interface{}) (uint32, error) {
+	if key == nil {
+		return 0, ErrInvalidShardKey
+	}
+
+	var strKey string
+	switch v := key.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		strKey = fmt.Sprintf("%d", v)
+	case string:
+		strKey = v
+	case []byte:
+		strKey = string(v)
+	default:
+		return 0, fmt.Errorf("unsupported key type: %T", key)
+	}
+	return crc32.ChecksumIEEE([]byte(strKey)), nil
+}
+
+// Route 基于一致性哈希的路由算法，数据库由哈希环决定，表在数据库内按哈希值取模，
+// 数据迁移到其他数据库时表的索引保持不变
+func (s *ConsistentHashStrategy) Route(key interface{}) (int, int, error) {
+	hash, err := s.Hash(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s.mu.RLock()
+	dbIndex, ok := s.locate(hash)
+	s.mu.RUnlock()
+	if !ok {
+		return 0, 0, ErrEmptyHashRing
+	}
+
+	return dbIndex, int(hash % uint32(s.TableCount)), nil
+}
+
+// GetShardName 获取分片的数据库和表名
+func (s *ConsistentHashStrategy) GetShardName(dbIndex, tableIndex int) (string, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.BaseStrategy.GetShardName(dbIndex, tableIndex)
+}
+
+// SetWeight 设置数据库的权重并重新生成哈希环
+func (s *ConsistentHashStrategy) SetWeight(dbIndex, weight int) error {
+	if weight < 0 {
+		return fmt.Errorf("invalid weight: %d", weight)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if dbIndex < 0 || dbIndex >= len(s.weights) {
+		return fmt.Errorf("db index out of range: %d", dbIndex)
+	}
+	s.weights[dbIndex] = weight
+	s.buildRing()
+	return nil
+}
+
+// Weight 获取数据库的权重
+func (s *ConsistentHashStrategy) Weight(dbIndex int) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if dbIndex < 0 || dbIndex >= len(s.weights) {
+		return 0
+	}
+	return s.weights[dbIndex]
+}
+
+// AddDB 增加一个数据库，返回新数据库的索引
+func (s *ConsistentHashStrategy) AddDB(weight int) int {
+	if weight < 0 {
+		weight = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.weights = append(s.weights, weight)
+	s.DBCount = len(s.weights)
+	s.buildRing()
+	return s.DBCount - 1
+}
+
+// RemoveDB 移除数据库。为了保持其他数据库的名称不变，只是将其权重设置为0
+func (s *ConsistentHashStrategy) RemoveDB(dbIndex int) error {
+	return s.SetWeight(dbIndex, 0)
+}
+
+// Clone 复制策略，通常用于在副本上调整数据库后通过 PlanMigration 计算需要迁移的数据
+func (s *ConsistentHashStrategy) Clone() *ConsistentHashStrategy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	base := *s.BaseStrategy
+	return &ConsistentHashStrategy{
+		BaseStrategy: &base,
+		VirtualNodes: s.VirtualNodes,
+		weights:      append([]int(nil), s.weights...),
+		ring:         append([]ringNode(nil), s.ring...),
+	}
+}
+
+// KeyRange 哈希环上需要迁移的区间，包含 Start 和 End
+type KeyRange struct {
+	Start  uint32
+	End    uint32
+	FromDB int // 迁移前所在的数据库
+	ToDB   int // 迁移后所在的数据库
+}
+
+// Contains 判断哈希值是否在区间内
+func (r KeyRange) Contains(hash uint32) bool {
+	return hash >= r.Start && hash <= r.End
+}
+
+// MigrationPlan 调整数据库之后的数据迁移计划
+type MigrationPlan struct {
+	Ranges []KeyRange
+
+	from *ConsistentHashStrategy
+	to   *ConsistentHashStrategy
+}
+
+// PlanMigration 比较调整前后的两个哈希环，计算需要迁移的区间：
+//
+//	next := strategy.Clone()
+//	next.AddDB(1)
+//	plan, err := sharding.PlanMigration(strategy, next)
+func PlanMigration(from, to *ConsistentHashStrategy) (*MigrationPlan, error) {
+	from, to = from.Clone(), to.Clone()
+	if len(from.ring) == 0 || len(to.ring) == 0 {
+		return nil, ErrEmptyHashRing
+	}
+
+	// 两个环上所有节点把哈希空间切分为若干区间，每个区间 (prev, point] 都归属于 point 所在的节点
+	points := make([]uint32, 0, len(from.ring)+len(to.ring)+1)
+	for _, n := range from.ring {
+		points = append(points, n.hash)
+	}
+	for _, n := range to.ring {
+		points = append(points, n.hash)
+	}
+	points = append(points, math.MaxUint32)
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	plan := &MigrationPlan{from: from, to: to}
+	var start uint32
+	for i, p := range points {
+		if i > 0 && p == points[i-1] {
+			continue
+		}
+		src, _ := from.locate(p)
+		dst, _ := to.locate(p)
+		if src != dst {
+			// 与上一个区间相邻且迁移方向相同时合并
+			if n := len(plan.Ranges); n > 0 && plan.Ranges[n-1].End+1 == start &&
+				plan.Ranges[n-1].FromDB == src && plan.Ranges[n-1].ToDB == dst {
+				plan.Ranges[n-1].End = p
+			} else {
+				plan.Ranges = append(plan.Ranges, KeyRange{Start: start, End: p, FromDB: src, ToDB: dst})
+			}
+		}
+		if p == math.MaxUint32 {
+			break
+		}
+		start = p + 1
+	}
+	return plan, nil
+}
+
+// Moved 判断键值是否需要迁移，返回迁移前后所在的数据库
+func (p *MigrationPlan) Moved(key interface{}) (fromDB, toDB int, moved bool, err error) {
+	fromDB, _, err = p.from.Route(key)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	toDB, _, err = p.to.Route(key)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return fromDB, toDB, fromDB != toDB, nil
+}
+
+// MovedRatio 需要迁移的数据占整个哈希空间的比例
+func (p *MigrationPlan) MovedRatio() float64 {
+	var total float64
+	for _, r := range p.Ranges {
+		total += float64(r.End-r.Start) + 1
+	}
+	return total / (float64(math.MaxUint32) + 1)
+}
@@ -0,0 +1,94 @@
+package sharding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistentHashStrategy_Route(t *testing.T) {
+	s := NewConsistentHashStrategy("user_db_", 4, "user_", 8, "UserID", 0)
+
+	counts := make([]int, 4)
+	for i := 0; i < 10000; i++ {
+		dbIndex, tableIndex, err := s.Route(i)
+		require.NoError(t, err)
+		assert.True(t, tableIndex >= 0 && tableIndex < 8)
+		counts[dbIndex]++
+
+		// 相同的键总是路由到相同的分片
+		again, _, _ := s.Route(int64(i))
+		assert.Equal(t, dbIndex, again)
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 2500, c, 600)
+	}
+
+	_, _, err := s.Route(nil)
+	assert.ErrorIs(t, err, ErrInvalidShardKey)
+	_, _, err = s.Route(1.5)
+	assert.Error(t, err)
+}
+
+func TestConsistentHashStrategy_Weight(t *testing.T) {
+	s := NewConsistentHashStrategy("user_db_", 2, "user_", 1, "UserID", 0)
+	require.NoError(t, s.SetWeight(1, 3))
+	assert.Equal(t, 3, s.Weight(1))
+	assert.Error(t, s.SetWeight(2, 1))
+	assert.Error(t, s.SetWeight(0, -1))
+
+	counts := make([]int, 2)
+	for i := 0; i < 10000; i++ {
+		dbIndex, _, err := s.Route(i)
+		require.NoError(t, err)
+		counts[dbIndex]++
+	}
+	assert.InDelta(t, 7500, counts[1], 800)
+
+	require.NoError(t, s.RemoveDB(0))
+	require.NoError(t, s.RemoveDB(1))
+	_, _, err := s.Route(1)
+	assert.ErrorIs(t, err, ErrEmptyHashRing)
+}
+
+func TestPlanMigration(t *testing.T) {
+	s := NewConsistentHashStrategy("user_db_", 3, "user_", 4, "UserID", 0)
+	next := s.Clone()
+	assert.Equal(t, 3, next.AddDB(1))
+	assert.Equal(t, 3, s.DBCount)
+
+	plan, err := PlanMigration(s, next)
+	require.NoError(t, err)
+	require.NotEmpty(t, plan.Ranges)
+	// 新增一个数据库时大约 1/4 的数据需要迁移，且只会迁移到新的数据库
+	assert.InDelta(t, 0.25, plan.MovedRatio(), 0.08)
+	for _, r := range plan.Ranges {
+		assert.Equal(t, 3, r.ToDB)
+	}
+
+	for i := 0; i < 1000; i++ {
+		from, to, moved, err := plan.Moved(i)
+		require.NoError(t, err)
+
+		hash, _ := s.Hash(i)
+		inRange := false
+		for _, r := range plan.Ranges {
+			if r.Contains(hash) {
+				inRange = true
+				assert.Equal(t, r.FromDB, from)
+				assert.Equal(t, r.ToDB, to)
+			}
+		}
+		assert.Equal(t, inRange, moved)
+
+		// 迁移前后表的索引不变
+		_, t1, _ := s.Route(i)
+		_, t2, _ := next.Route(i)
+		assert.Equal(t, t1, t2)
+	}
+
+	plan, err = PlanMigration(s, s)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Ranges)
+}
@@ -0,0 +1,140 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExecConfig 在多个分片上执行操作的配置
+type ExecConfig struct {
+	MaxParallelism int           // 同时执行的分片数，小于等于0时不限制
+	Timeout        time.Duration // 每个分片的超时时间，为0时不限制
+	FailFast       bool          // 某个分片失败后取消其他分片，没有开始执行的分片不再执行
+}
+
+// ExecOption 在多个分片上执行操作的配置项
+type ExecOption func(*ExecConfig)
+
+// WithMaxParallelism 限制同时执行的分片数
+func WithMaxParallelism(n int) ExecOption {
+	return func(c *ExecConfig) {
+		c.MaxParallelism = n
+	}
+}
+
+// WithShardTimeout 设置每个分片的超时时间
+func WithShardTimeout(timeout time.Duration) ExecOption {
+	return func(c *ExecConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithFailFast 某个分片失败后取消其他分片，默认等待所有分片执行完成并收集所有错误
+func WithFailFast() ExecOption {
+	return func(c *ExecConfig) {
+		c.FailFast = true
+	}
+}
+
+// ShardResult 单个分片的执行结果
+type ShardResult struct {
+	Shard    string        // 分片名称
+	Err      error         // 执行失败时的错误
+	Duration time.Duration // 执行耗时
+	Skipped  bool          // FailFast 模式下其他分片已经失败，没有执行
+}
+
+// ShardResults 所有分片的执行结果，按分片名称排序
+type ShardResults []ShardResult
+
+// Err 合并所有失败分片的错误，全部成功时返回nil
+func (r ShardResults) Err() error {
+	var errs []error
+	for _, res := range r {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("shard %s: %w", res.Shard, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failed 返回失败的分片
+func (r ShardResults) Failed() ShardResults {
+	var failed ShardResults
+	for _, res := range r {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Execute 在多个分片上并发执行 fn，每个分片的 ctx 带有配置的超时时间
+func Execute(ctx context.Context, shards []string, fn func(ctx context.Context, shard string) error, cfg ExecConfig) ShardResults {
+	shards = append([]string(nil), shards...)
+	sort.Strings(shards)
+	results := make(ShardResults, len(shards))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem chan struct{}
+	if cfg.MaxParallelism > 0 {
+		sem = make(chan struct{}, cfg.MaxParallelism)
+	}
+
+	var wg sync.WaitGroup
+	var failOnce sync.Once
+	for i, shard := range shards {
+		results[i].Shard = shard
+		acquired := false
+		if sem != nil {
+			// 在调用方的协程中等待，避免为所有分片同时创建协程
+			select {
+			case sem <- struct{}{}:
+				acquired = true
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			if acquired {
+				<-sem
+			}
+			if cfg.FailFast {
+				results[i].Skipped = true
+			} else {
+				results[i].Err = ctx.Err()
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(res *ShardResult) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+
+			shardCtx := ctx
+			if cfg.Timeout > 0 {
+				var shardCancel context.CancelFunc
+				shardCtx, shardCancel = context.WithTimeout(ctx, cfg.Timeout)
+				defer shardCancel()
+			}
+
+			start := time.Now()
+			res.Err = fn(shardCtx, res.Shard)
+			res.Duration = time.Since(start)
+			if res.Err != nil && cfg.FailFast {
+				failOnce.Do(cancel)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
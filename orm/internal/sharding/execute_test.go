@@ -0,0 +1,94 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	shards := []string{"db_2", "db_0", "db_1", "db_3"}
+	errBoom := errors.New("boom")
+
+	t.Run("collect all", func(t *testing.T) {
+		results := Execute(context.Background(), shards, func(ctx context.Context, shard string) error {
+			if shard == "db_1" || shard == "db_3" {
+				return errBoom
+			}
+			return nil
+		}, ExecConfig{})
+
+		require.Len(t, results, 4)
+		assert.Equal(t, "db_0", results[0].Shard)
+		assert.Len(t, results.Failed(), 2)
+		assert.ErrorIs(t, results.Err(), errBoom)
+		assert.ErrorContains(t, results.Err(), "shard db_1")
+		assert.ErrorContains(t, results.Err(), "shard db_3")
+	})
+
+	t.Run("max parallelism", func(t *testing.T) {
+		var running, peak int32
+		results := Execute(context.Background(), shards, func(ctx context.Context, shard string) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}, ExecConfig{MaxParallelism: 2})
+
+		assert.NoError(t, results.Err())
+		assert.LessOrEqual(t, peak, int32(2))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		results := Execute(context.Background(), shards, func(ctx context.Context, shard string) error {
+			if shard != "db_0" {
+				return nil
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, ExecConfig{Timeout: 10 * time.Millisecond})
+
+		assert.ErrorIs(t, results[0].Err, context.DeadlineExceeded)
+		assert.Len(t, results.Failed(), 1)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		var executed int32
+		results := Execute(context.Background(), shards, func(ctx context.Context, shard string) error {
+			atomic.AddInt32(&executed, 1)
+			if shard == "db_0" {
+				return errBoom
+			}
+			return nil
+		}, ExecConfig{MaxParallelism: 1, FailFast: true})
+
+		assert.ErrorIs(t, results.Err(), errBoom)
+		assert.Equal(t, int32(1), executed)
+		for _, res := range results[1:] {
+			assert.True(t, res.Skipped)
+			assert.NoError(t, res.Err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := Execute(ctx, shards, func(ctx context.Context, shard string) error {
+			return nil
+		}, ExecConfig{MaxParallelism: 1})
+
+		assert.Len(t, results.Failed(), 4)
+		assert.ErrorIs(t, results.Err(), context.Canceled)
+	})
+}
@@ -361,48 +361,26 @@ func (sdb *ShardedDB) GetShardNames() []string {
 	return names
 }
 
-// ExecuteOnAllShards 在所有分片上执行指定操作
-func (sdb *ShardedDB) ExecuteOnAllShards(ctx context.Context, fn func(db *sql.DB, shardName string) error) []error {
+// ExecuteOnAllShards 在所有分片上并发执行指定操作，返回每个分片的执行结果。
+// 默认等待所有分片执行完成，可以通过 WithMaxParallelism、WithShardTimeout 和 WithFailFast 调整
+func (sdb *ShardedDB) ExecuteOnAllShards(ctx context.Context, fn func(ctx context.Context, db *sql.DB, shardName string) error, opts ...ExecOption) ShardResults {
 	sdb.RLock()
 	shardsCopy := make(map[string]*sql.DB)
+	names := make([]string, 0, len(sdb.shards))
 	for name, db := range sdb.shards {
 		shardsCopy[name] = db
+		names = append(names, name)
 	}
 	sdb.RUnlock()
 
-	var errors []error
-	var errorsMu sync.Mutex
-
-	// 对每个分片执行操作
-	var wg sync.WaitGroup
-	for name, db := range shardsCopy {
-		wg.Add(1)
-		go func(db *sql.DB, name string) {
-			defer wg.Done()
-
-			// 检查上下文是否已取消
-			select {
-			case <-ctx.Done():
-				errorsMu.Lock()
-				errors = append(errors, fmt.Errorf("context canceled for shard %s: %w", name, ctx.Err()))
-				errorsMu.Unlock()
-				return
-			default:
-			}
-
-			// 执行操作
-			if err := fn(db, name); err != nil {
-				errorsMu.Lock()
-				errors = append(errors, fmt.Errorf("shard %s: %w", name, err))
-				errorsMu.Unlock()
-			}
-		}(db, name)
+	var cfg ExecConfig
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	// 等待所有操作完成
-	wg.Wait()
-
-	return errors
+	return Execute(ctx, names, func(ctx context.Context, name string) error {
+		return fn(ctx, shardsCopy[name], name)
+	}, cfg)
 }
 
 // ExecuteOnShard 在指定分片上执行操作
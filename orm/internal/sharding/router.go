@@ -92,6 +92,8 @@ func (r *DefaultRouter) RegisterStrategy(modelName string, strategy Strategy) {
 			info.ShardKey = s.BaseStrategy.ShardKey
 		case *DateStrategy:
 			info.ShardKey = s.BaseStrategy.ShardKey
+		case *ConsistentHashStrategy:
+			info.ShardKey = s.BaseStrategy.ShardKey
 		default:
 			// 如果是其他类型的策略，尝试通过反射获取ShardKey
 			val := reflect.ValueOf(strategy)
@@ -122,6 +124,8 @@ func (r *DefaultRouter) RegisterStrategy(modelName string, strategy Strategy) {
 			info.ShardKey = s.BaseStrategy.ShardKey
 		case *DateStrategy:
 			info.ShardKey = s.BaseStrategy.ShardKey
+		case *ConsistentHashStrategy:
+			info.ShardKey = s.BaseStrategy.ShardKey
 		}
 	}
 }
@@ -25,6 +25,18 @@ type Strategy interface {
 	GetShardName(dbIndex, tableIndex int) (dbName, tableName string, err error)
 }
 
+// ShardIndex 分片的数据库和表索引
+type ShardIndex struct {
+	DB    int
+	Table int
+}
+
+// RangeRouter 支持按范围路由的分片策略，用于根据范围条件缩小需要访问的分片
+type RangeRouter interface {
+	// RouteRange 计算 [min, max] 内的键可能路由到的分片，min 或 max 为 nil 时表示该方向无界
+	RouteRange(min, max interface{}) ([]ShardIndex, error)
+}
+
 // BaseStrategy 提供分片策略的基本功能
 type BaseStrategy struct {
 	// 数据库配置
@@ -129,45 +141,49 @@ func NewRangeStrategy(dbPrefix string, dbCount int, tablePrefix string, tableCou
 
 // Route 基于范围的路由算法
 func (s *RangeStrategy) Route(key interface{}) (int, int, error) {
-	if key == nil {
-		return 0, 0, ErrInvalidShardKey
+	intKey, err := rangeKey(key)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	// 将key转换为int64
-	var intKey int64
+	// 计算分片索引
+	dbIndex, tableIndex := s.shardOf(s.bucket(intKey))
+	return dbIndex, tableIndex, nil
+}
 
-	switch v := key.(type) {
-	case int:
-		intKey = int64(v)
-	case int8:
-		intKey = int64(v)
-	case int16:
-		intKey = int64(v)
-	case int32:
-		intKey = int64(v)
-	case int64:
-		intKey = v
-	case uint:
-		intKey = int64(v)
-	case uint8:
-		intKey = int64(v)
-	case uint16:
-		intKey = int64(v)
-	case uint32:
-		intKey = int64(v)
-	case uint64:
-		intKey = int64(v)
-	case string:
-		var err error
-		intKey, err = strconv.ParseInt(v, 10, 64)
+// RouteRange 计算 [min, max] 内的键可能路由到的分片，范围跨越的区间数不少于分片数时返回所有分片
+func (s *RangeStrategy) RouteRange(min, max interface{}) ([]ShardIndex, error) {
+	lo, hi := 0, len(s.Ranges)
+	if min != nil {
+		intKey, err := rangeKey(min)
 		if err != nil {
-			return 0, 0, ErrInvalidShardKey
+			return nil, err
 		}
-	default:
-		return 0, 0, fmt.Errorf("unsupported key type: %T", key)
+		lo = s.bucket(intKey)
+	}
+	if max != nil {
+		intKey, err := rangeKey(max)
+		if err != nil {
+			return nil, err
+		}
+		hi = s.bucket(intKey)
+	}
+
+	totalShards := s.DBCount * s.TableCount
+	if hi-lo+1 > totalShards {
+		hi = lo + totalShards - 1
+	}
+
+	var shards []ShardIndex
+	for idx := lo; idx <= hi; idx++ {
+		dbIndex, tableIndex := s.shardOf(idx)
+		shards = append(shards, ShardIndex{DB: dbIndex, Table: tableIndex})
 	}
+	return shards, nil
+}
 
-	// 查找所在范围
+// bucket 查找键所在的范围
+func (s *RangeStrategy) bucket(intKey int64) int {
 	idx := 0
 	for i, r := range s.Ranges {
 		if intKey < r {
@@ -176,15 +192,56 @@ func (s *RangeStrategy) Route(key interface{}) (int, int, error) {
 		}
 		idx = i + 1
 	}
+	return idx
+}
 
-	// 计算分片索引
+// shardOf 将范围的序号映射到分片
+func (s *RangeStrategy) shardOf(idx int) (int, int) {
 	totalShards := s.DBCount * s.TableCount
 	shardIndex := idx % totalShards
 
 	dbIndex := shardIndex % s.DBCount
 	tableIndex := shardIndex / s.DBCount
 
-	return dbIndex, tableIndex, nil
+	return dbIndex, tableIndex
+}
+
+// rangeKey 将范围分片的键转换为int64
+func rangeKey(key interface{}) (int64, error) {
+	if key == nil {
+		return 0, ErrInvalidShardKey
+	}
+
+	switch v := key.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case string:
+		intKey, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, ErrInvalidShardKey
+		}
+		return intKey, nil
+	default:
+		return 0, fmt.Errorf("unsupported key type: %T", key)
+	}
 }
 
 // DateStrategy 基于日期的分片策略
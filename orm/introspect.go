@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Tables 返回数据库中所有表的名称，按名称排序，不包含视图和 SQLite 的内部表
+func (sm *SchemaManager) Tables(ctx context.Context, schema string) ([]string, error) {
+	var query string
+	switch sm.db.dialect.(type) {
+	case *Mysql:
+		query = fmt.Sprintf(`
+            SELECT TABLE_NAME
+            FROM INFORMATION_SCHEMA.TABLES
+            WHERE TABLE_SCHEMA = COALESCE(NULLIF('%s', ''), DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+            ORDER BY TABLE_NAME
+        `, schema)
+	case *Postgresql:
+		query = fmt.Sprintf(`
+            SELECT table_name
+            FROM information_schema.tables
+            WHERE table_schema = COALESCE(NULLIF('%s', ''), 'public') AND table_type = 'BASE TABLE'
+            ORDER BY table_name
+        `, schema)
+	case *Sqlite:
+		query = `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	default:
+		return nil, errors.New("不支持的数据库类型")
+	}
+
+	rows, err := sm.db.queryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// TableSchema 读取数据库中已存在表的结构，包括列、索引和外键，
+// 类型为规范化后的形式，与迁移时比较使用的结构相同
+func (sm *SchemaManager) TableSchema(ctx context.Context, schema, table string) (*TableSchema, error) {
+	d, ok := sm.db.dialect.(schemaDialect)
+	if !ok {
+		return nil, errors.New("不支持的数据库类型")
+	}
+
+	exists, err := sm.tableExists(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("表 %s 不存在", table)
+	}
+	return sm.readTableSchema(ctx, d, schema, table)
+}
+
+// ColumnType 返回方言 d 为 Go 类型 typ 和 orm 标签 tag 生成的列类型，规范化为与 TableSchema 相同的形式，
+// 代码生成工具用它判断生成的字段是否需要用 type 标签指定类型
+func ColumnType(d Dialect, typ reflect.Type, tag string) (string, error) {
+	tags, err := parseTagString(tag)
+	if err != nil {
+		return "", err
+	}
+	colType := d.ColumnType(newField(typ, "", tags))
+	if sd, ok := d.(schemaDialect); ok {
+		colType, _ = sd.normalizeType(colType)
+	}
+	return colType, nil
+}
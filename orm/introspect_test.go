@@ -0,0 +1,111 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Tables(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(".*FROM INFORMATION_SCHEMA.TABLES.*TABLE_SCHEMA = COALESCE\\(NULLIF\\('shop', ''\\), DATABASE\\(\\)\\).*").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("order_item").AddRow("user"))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	tables, err := db.Tables(context.Background(), "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order_item", "user"}, tables)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDB_TableSchema(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM information_schema.tables WHERE table_name = 'order_item'")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(".*FROM.*INFORMATION_SCHEMA.COLUMNS.*").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
+			"CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", "NUMERIC_SCALE", "COLUMN_KEY", "EXTRA"}).
+			AddRow("id", "bigint(20)", "NO", nil, nil, 19, 0, "PRI", "auto_increment").
+			AddRow("order_id", "int(11)", "NO", nil, nil, 10, 0, "MUL", "").
+			AddRow("price", "decimal(10,2)", "YES", "0.00", nil, 10, 2, "", ""))
+	expectConstraints(mock,
+		indexRows().AddRow("idx_order_item_order_id", "order_id", 0),
+		foreignKeyRows().AddRow("fk_order_item_order_id", "order_id", "order", "id", "CASCADE", "NO ACTION"))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	schema, err := db.TableSchema(context.Background(), "", "order_item")
+	require.NoError(t, err)
+	assert.Equal(t, &TableSchema{
+		Name: "order_item",
+		Columns: []*ColumnSchema{
+			{Name: "id", Type: "BIGINT", PrimaryKey: true, AutoIncr: true},
+			{Name: "order_id", Type: "INT"},
+			{Name: "price", Type: "DECIMAL(10,2)", Nullable: true, Default: "0.00"},
+		},
+		Indexes: []*IndexSchema{{Name: "idx_order_item_order_id", Columns: []string{"order_id"}}},
+		ForeignKeys: []*ForeignKeySchema{{
+			Name: "fk_order_item_order_id", Columns: []string{"order_id"},
+			RefTable: "order", RefColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION",
+		}},
+	}, schema)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDB_TableSchema_NotExists(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM information_schema.tables WHERE table_name = 'missing'")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	_, err = db.TableSchema(context.Background(), "", "missing")
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestColumnType(t *testing.T) {
+	testCases := []struct {
+		dialect string
+		typ     reflect.Type
+		tag     string
+		want    string
+	}{
+		{dialect: "mysql", typ: reflect.TypeOf(0), tag: "primary_key;auto_increment", want: "INT"},
+		{dialect: "mysql", typ: reflect.TypeOf(""), tag: "size:100", want: "VARCHAR(100)"},
+		{dialect: "mysql", typ: reflect.TypeOf(0.0), tag: "precision:10;scale:2", want: "DECIMAL(10,2)"},
+		{dialect: "mysql", typ: reflect.TypeOf(sql.NullInt64{}), tag: "type:int", want: "INT"},
+		{dialect: "postgresql", typ: reflect.TypeOf(int64(0)), tag: "auto_increment", want: "BIGINT"},
+		{dialect: "postgresql", typ: reflect.TypeOf(time.Time{}), want: "TIMESTAMP WITH TIME ZONE"},
+		{dialect: "sqlite", typ: reflect.TypeOf(false), want: "BOOLEAN"},
+	}
+
+	for _, tc := range testCases {
+		got, err := ColumnType(Get(tc.dialect), tc.typ, tc.tag)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "%s %s %q", tc.dialect, tc.typ, tc.tag)
+	}
+
+	_, err := ColumnType(Get("mysql"), reflect.TypeOf(""), "size:1:2")
+	assert.Error(t, err)
+}
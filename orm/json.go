@@ -0,0 +1,216 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrJSONNotSupported 方言不支持 JSON 路径查询
+var ErrJSONNotSupported = errors.New("orm: JSON path is not supported by the dialect")
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// isJSONField 判断 type 标签为 json 或 jsonb 的字段是否需要自动序列化：
+// string、[]byte 以及自己实现了 sql.Scanner 或 driver.Valuer 的类型保存的已经是 JSON 文本，原样读写
+func isJSONField(sqlType string, typ reflect.Type) bool {
+	if !strings.EqualFold(sqlType, "json") && !strings.EqualFold(sqlType, "jsonb") {
+		return false
+	}
+	if typ.Kind() == reflect.String || (typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8) {
+		return false
+	}
+	return !typ.Implements(valuerType) && !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// jsonValue 写入 JSON 列的值，执行时序列化，nil 的指针、map 和切片写入 NULL
+type jsonValue struct {
+	val any
+}
+
+// Value 实现 driver.Valuer
+func (j jsonValue) Value() (driver.Value, error) {
+	v := reflect.ValueOf(j.val)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	data, err := json.Marshal(j.val)
+	if err != nil {
+		return nil, fmt.Errorf("orm: marshal json column: %w", err)
+	}
+	return string(data), nil
+}
+
+// jsonScanner 从 JSON 列读取数据，反序列化到字段中，NULL 时字段置为零值
+type jsonScanner struct {
+	dst any
+}
+
+// Scan 实现 sql.Scanner
+func (j *jsonScanner) Scan(src any) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		v := reflect.ValueOf(j.dst).Elem()
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("orm: cannot scan %T into json column", src)
+	}
+	if err := json.Unmarshal(data, j.dst); err != nil {
+		return fmt.Errorf("orm: unmarshal json column: %w", err)
+	}
+	return nil
+}
+
+// jsonArg 写入字段 f 的值，JSON 字段的值在执行时序列化；已经是 JSON 文本或表达式的值不处理
+func jsonArg(f *field, val any) any {
+	if f == nil || !f.json {
+		return val
+	}
+	switch val.(type) {
+	case string, []byte, json.RawMessage, driver.Valuer, jsonValue:
+		return val
+	}
+	return jsonValue{val: val}
+}
+
+// JSONPath JSON 列中路径对应的值，路径使用 MySQL 的写法，如 $.plan、$.tags[0]，
+// 按方言生成 JSON_EXTRACT、#>> 或 json_extract，路径作为参数传入
+type JSONPath struct {
+	col  *Column
+	path string
+}
+
+// JSONExtract 取 JSON 列中 path 对应的值用于比较，如 Col("Meta").JSONExtract("$.plan").Eq("pro")
+func (c *Column) JSONExtract(path string) *JSONPath {
+	return &JSONPath{col: c, path: path}
+}
+
+func (j *JSONPath) expr() {}
+
+// build 写入取值表达式：MySQL 取出的值去掉 JSON 字符串的引号，PostgreSQL 按文本取值，
+// 与字符串比较时各方言的结果相同
+func (j *JSONPath) build(builder *strings.Builder, m *model, args *[]any) {
+	j.col.model = m
+	switch m.dialect.(type) {
+	case *Mysql:
+		builder.WriteString("JSON_UNQUOTE(JSON_EXTRACT(")
+		j.col.Build(builder)
+		builder.WriteString(", " + m.dialect.Placeholder(m.index) + "))")
+		*args = append(*args, j.path)
+	case *Postgresql:
+		keys, err := jsonPathKeys(j.path)
+		if err != nil {
+			panic(err)
+		}
+		j.col.Build(builder)
+		builder.WriteString(" #>> " + m.dialect.Placeholder(m.index))
+		*args = append(*args, "{"+strings.Join(keys, ",")+"}")
+	case *Sqlite:
+		builder.WriteString("json_extract(")
+		j.col.Build(builder)
+		builder.WriteString(", " + m.dialect.Placeholder(m.index) + ")")
+		*args = append(*args, j.path)
+	default:
+		panic(ErrJSONNotSupported)
+	}
+	m.index++
+}
+
+// jsonPathKeys 将 $.a.b[0] 这样的路径拆分为 PostgreSQL 的路径数组 a、b、0，
+// 带引号的键如 $."a.b" 作为一个整体
+func jsonPathKeys(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("orm: invalid json path %q", path)
+	}
+
+	var keys []string
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.IndexByte(rest[1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("orm: invalid json path %q", path)
+				}
+				keys = append(keys, `"`+rest[1:end+1]+`"`)
+				rest = rest[end+2:]
+				continue
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("orm: invalid json path %q", path)
+			}
+			keys = append(keys, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("orm: invalid json path %q", path)
+			}
+			keys = append(keys, rest[1:end])
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("orm: invalid json path %q", path)
+		}
+	}
+	return keys, nil
+}
+
+// Eq 路径的值等于 arg
+func (j *JSONPath) Eq(arg any) *Predicate {
+	return &Predicate{left: j, op: opEQ, right: valueOf(arg)}
+}
+
+// Gt 路径的值大于 arg
+func (j *JSONPath) Gt(arg any) *Predicate {
+	return &Predicate{left: j, op: opGT, right: valueOf(arg)}
+}
+
+// Gte 路径的值大于等于 arg
+func (j *JSONPath) Gte(arg any) *Predicate {
+	return &Predicate{left: j, op: opGTE, right: valueOf(arg)}
+}
+
+// Lt 路径的值小于 arg
+func (j *JSONPath) Lt(arg any) *Predicate {
+	return &Predicate{left: j, op: opLT, right: valueOf(arg)}
+}
+
+// Lte 路径的值小于等于 arg
+func (j *JSONPath) Lte(arg any) *Predicate {
+	return &Predicate{left: j, op: opLTE, right: valueOf(arg)}
+}
+
+// In 路径的值在 vals 中
+func (j *JSONPath) In(vals ...any) *Predicate {
+	return &Predicate{left: j, op: opIN, right: valueOf(vals)}
+}
+
+// IsNull 路径不存在
+func (j *JSONPath) IsNull() *Predicate {
+	return &Predicate{left: j, op: opISNULL}
+}
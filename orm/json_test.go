@@ -0,0 +1,197 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type AccountMeta struct {
+	Plan string   `json:"plan"`
+	Tags []string `json:"tags"`
+}
+
+type JSONAccount struct {
+	ID       int64
+	Meta     AccountMeta     `orm:"type:json"`
+	Settings map[string]any  `orm:"type:jsonb"`
+	Extra    *AccountMeta    `orm:"type:json"`
+	Raw      string          `orm:"type:json"`
+	Doc      json.RawMessage `orm:"type:json"`
+	Labels   map[string]string
+}
+
+func TestJSON_Field(t *testing.T) {
+	m, err := parseModel(&JSONAccount{})
+	require.NoError(t, err)
+
+	assert.True(t, m.fieldsMap["Meta"].json)
+	assert.True(t, m.fieldsMap["Settings"].json)
+	assert.True(t, m.fieldsMap["Extra"].json)
+	// 已经是 JSON 文本的字段原样读写
+	assert.False(t, m.fieldsMap["Raw"].json)
+	assert.False(t, m.fieldsMap["Doc"].json)
+	assert.False(t, m.fieldsMap["Labels"].json)
+}
+
+func TestJSON_InsertAndScan(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `json_account` (`id`, `meta`, `settings`, `extra`) VALUES (?, ?, ?, ?);")).
+		WithArgs(1, `{"plan":"pro","tags":["a","b"]}`, `{"theme":"dark"}`, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = RegisterInserter[JSONAccount](db).Insert([]string{"ID", "Meta", "Settings", "Extra"}, &JSONAccount{
+		ID:       1,
+		Meta:     AccountMeta{Plan: "pro", Tags: []string{"a", "b"}},
+		Settings: map[string]any{"theme": "dark"},
+	}).Exec(context.Background())
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "meta", "settings", "extra", "raw"}).
+		AddRow(1, []byte(`{"plan":"pro","tags":["a"]}`), `{"theme":"dark","size":2}`, nil, `{"x":1}`))
+	acc, err := RegisterSelector[JSONAccount](db).Select().Where(Col("ID").Eq(1)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, AccountMeta{Plan: "pro", Tags: []string{"a"}}, acc.Meta)
+	assert.Equal(t, map[string]any{"theme": "dark", "size": float64(2)}, acc.Settings)
+	assert.Nil(t, acc.Extra)
+	assert.Equal(t, `{"x":1}`, acc.Raw)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "meta"}).AddRow(1, "not json"))
+	_, err = RegisterSelector[JSONAccount](db).Select().Get(context.Background())
+	assert.ErrorContains(t, err, "unmarshal json column")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJSON_Update(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "json_account" SET "meta" = $1, "extra" = $2 WHERE "id" = $3;`)).
+		WithArgs(`{"plan":"free","tags":null}`, `{"plan":"x"}`, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = RegisterUpdater[JSONAccount](db).Update().
+		Set(Col("Meta"), AccountMeta{Plan: "free"}).
+		// 已经序列化的值原样写入
+		Set(Col("Extra"), `{"plan":"x"}`).
+		Where(Col("ID").Eq(1)).Exec(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJSON_Value(t *testing.T) {
+	var meta *AccountMeta
+	testCases := []struct {
+		name string
+		val  any
+		want driver.Value
+	}{
+		{name: "struct", val: AccountMeta{Plan: "pro"}, want: `{"plan":"pro","tags":null}`},
+		{name: "map", val: map[string]int{"a": 1}, want: `{"a":1}`},
+		{name: "nil pointer", val: meta, want: nil},
+		{name: "nil map", val: map[string]int(nil), want: nil},
+		{name: "slice", val: []int{1, 2}, want: "[1,2]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jsonValue{val: tc.val}.Value()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := jsonValue{val: make(chan int)}.Value()
+	assert.ErrorContains(t, err, "marshal json column")
+}
+
+func TestJSONPath_Build(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	testCases := []struct {
+		dialect string
+		want    *Query
+	}{
+		{
+			dialect: "mysql",
+			want: &Query{
+				SQL:  "SELECT * FROM `json_account` WHERE JSON_UNQUOTE(JSON_EXTRACT(`meta`, ?)) = ? AND JSON_UNQUOTE(JSON_EXTRACT(`settings`, ?)) IN (?, ?) AND JSON_UNQUOTE(JSON_EXTRACT(`meta`, ?)) IS NULL;",
+				Args: []any{"$.plan", "pro", "$.size", 1, 2, "$.tags[0]"},
+			},
+		},
+		{
+			dialect: "postgresql",
+			want: &Query{
+				SQL:  `SELECT * FROM "json_account" WHERE "meta" #>> $1 = $2 AND "settings" #>> $3 IN ($4, $5) AND "meta" #>> $6 IS NULL;`,
+				Args: []any{"{plan}", "pro", "{size}", 1, 2, "{tags,0}"},
+			},
+		},
+		{
+			dialect: "sqlite",
+			want: &Query{
+				SQL:  `SELECT * FROM "json_account" WHERE json_extract("meta", ?) = ? AND json_extract("settings", ?) IN (?, ?) AND json_extract("meta", ?) IS NULL;`,
+				Args: []any{"$.plan", "pro", "$.size", 1, 2, "$.tags[0]"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			db, err := Open(mockDB, tc.dialect)
+			require.NoError(t, err)
+
+			q, err := RegisterSelector[JSONAccount](db).Select().Where(
+				Col("Meta").JSONExtract("$.plan").Eq("pro"),
+				Col("Settings").JSONExtract("$.size").In(1, 2),
+				Col("Meta").JSONExtract("$.tags[0]").IsNull(),
+			).Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, q)
+		})
+	}
+}
+
+func TestJSONPathKeys(t *testing.T) {
+	testCases := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "$.plan", want: []string{"plan"}},
+		{path: "$.a.b[2].c", want: []string{"a", "b", "2", "c"}},
+		{path: `$."a.b".c`, want: []string{`"a.b"`, "c"}},
+		{path: "plan", wantErr: true},
+		{path: "$..a", wantErr: true},
+		{path: "$.a[1", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := jsonPathKeys(tc.path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
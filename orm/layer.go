@@ -26,6 +26,11 @@ type Layer interface {
 	queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 
+	// 预编译语句相关方法，用于多次执行相同的SQL
+	prepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	queryStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error)
+	execStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error)
+
 	// 连接池相关方法
 	getConn(ctx context.Context) (*sql.DB, pool.Connection, error)
 	putConn(conn pool.Connection, err error)
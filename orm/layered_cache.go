@@ -0,0 +1,352 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// CacheLayer 模型的查询结果写入哪些缓存层，只对 LayeredCache 生效
+type CacheLayer int
+
+const (
+	CacheLayerAll    CacheLayer = iota // 本地缓存和远程缓存，默认值
+	CacheLayerLocal                    // 只使用进程内缓存，适合很少修改的配置类数据
+	CacheLayerRemote                   // 只使用远程缓存，适合修改频繁、不能容忍本地旧数据的模型
+)
+
+// cacheScopeKey 在 ctx 中传递当前查询使用的缓存层和标签
+type cacheScopeKey struct{}
+
+type cacheScope struct {
+	layer CacheLayer
+	tags  []string
+}
+
+// withCacheScope CacheManager 读写缓存时带上模型的缓存层和标签，LayeredCache 根据它们选择缓存层，
+// 并在远程缓存命中回填本地缓存时关联标签
+func withCacheScope(ctx context.Context, layer CacheLayer, tags []string) context.Context {
+	return context.WithValue(ctx, cacheScopeKey{}, cacheScope{layer: layer, tags: tags})
+}
+
+func cacheScopeFrom(ctx context.Context) cacheScope {
+	scope, _ := ctx.Value(cacheScopeKey{}).(cacheScope)
+	return scope
+}
+
+var errRemoteNoPrefix = errors.New("orm: remote cache does not support DeleteByPrefix")
+
+// CacheInvalidation 在多个实例之间广播的缓存失效消息
+type CacheInvalidation struct {
+	Origin string   `json:"origin"` // 发出消息的 LayeredCache
+	Op     string   `json:"op"`     // key、tags、prefix 或 clear
+	Values []string `json:"values,omitempty"`
+}
+
+// 缓存失效消息的类型
+const (
+	invalidateKey    = "key"
+	invalidateTags   = "tags"
+	invalidatePrefix = "prefix"
+	invalidateClear  = "clear"
+)
+
+// InvalidationBus 在多个实例之间广播缓存失效消息，LayeredCache 通过它使其他实例的本地缓存失效
+type InvalidationBus interface {
+	// Publish 广播失效消息
+	Publish(ctx context.Context, msg CacheInvalidation) error
+
+	// Subscribe 接收失效消息直到 ctx 结束，订阅成功后才开始阻塞，订阅失败时返回错误
+	Subscribe(ctx context.Context, handler func(CacheInvalidation)) error
+}
+
+// RedisInvalidationBus 基于 Redis 发布订阅的失效消息广播
+type RedisInvalidationBus struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisInvalidationBus 创建使用 channel 频道广播失效消息的 RedisInvalidationBus
+func NewRedisInvalidationBus(client redis.UniversalClient, channel string) *RedisInvalidationBus {
+	return &RedisInvalidationBus{client: client, channel: channel}
+}
+
+// Publish 广播失效消息
+func (b *RedisInvalidationBus) Publish(ctx context.Context, msg CacheInvalidation) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe 接收失效消息直到 ctx 结束，连接断开后客户端会自动重新订阅
+func (b *RedisInvalidationBus) Subscribe(ctx context.Context, handler func(CacheInvalidation)) error {
+	ps := b.client.Subscribe(ctx, b.channel)
+	defer ps.Close()
+	// 等待订阅确认
+	if _, err := ps.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var msg CacheInvalidation
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				debugLog("Invalid cache invalidation message: %v", err)
+				continue
+			}
+			handler(msg)
+		}
+	}
+}
+
+// LayeredCache 两级缓存，进程内的 LRUCache 在前，远程缓存（通常是 RedisCache）在后。
+// 读取时先读本地缓存，未命中时读远程缓存并回填本地缓存；删除时同时删除两级缓存，
+// 并通过 InvalidationBus 通知其他实例删除各自的本地缓存
+type LayeredCache struct {
+	local    *LRUCache
+	remote   Cache
+	bus      InvalidationBus
+	nearTTL  time.Duration
+	retry    time.Duration
+	id       string
+	cancel   context.CancelFunc
+	done     chan struct{}
+	errorLog func(err error)
+}
+
+// LayeredCacheOption LayeredCache 配置项
+type LayeredCacheOption func(*LayeredCache)
+
+// WithLocalCache 设置本地缓存，默认为容量10000的 LRUCache
+func WithLocalCache(local *LRUCache) LayeredCacheOption {
+	return func(c *LayeredCache) {
+		c.local = local
+	}
+}
+
+// WithNearCacheTTL 设置本地缓存的过期时间，默认为30秒。
+// 广播消息丢失时，其他实例的本地缓存最多在这个时间内返回旧数据
+func WithNearCacheTTL(ttl time.Duration) LayeredCacheOption {
+	return func(c *LayeredCache) {
+		c.nearTTL = ttl
+	}
+}
+
+// WithInvalidationBus 设置广播失效消息的方式。远程缓存是 RedisCache 时默认使用它的客户端发布订阅
+func WithInvalidationBus(bus InvalidationBus) LayeredCacheOption {
+	return func(c *LayeredCache) {
+		c.bus = bus
+	}
+}
+
+// WithInvalidationErrorLog 设置订阅或者广播失效消息失败时的处理函数
+func WithInvalidationErrorLog(fn func(err error)) LayeredCacheOption {
+	return func(c *LayeredCache) {
+		c.errorLog = fn
+	}
+}
+
+// NewLayeredCache 在 remote 前增加本地缓存，并开始订阅其他实例的失效消息
+//
+//	remote, err := orm.OpenRedisCache(ctx, &redis.UniversalOptions{Addrs: []string{"localhost:6379"}})
+//	cache := orm.NewLayeredCache(remote, orm.WithNearCacheTTL(10*time.Second))
+//	db, err := orm.Open(sqlDB, "mysql", orm.WithDBCache(cache))
+func NewLayeredCache(remote Cache, opts ...LayeredCacheOption) *LayeredCache {
+	c := &LayeredCache{
+		remote:   remote,
+		nearTTL:  30 * time.Second,
+		retry:    time.Second,
+		id:       uuid.NewString(),
+		done:     make(chan struct{}),
+		errorLog: func(err error) { debugLog("Layered cache: %v", err) },
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.local == nil {
+		c.local = NewLRUCache(10000)
+	}
+	if c.bus == nil {
+		if rc, ok := remote.(*RedisCache); ok {
+			c.bus = NewRedisInvalidationBus(rc.client, rc.prefix+"invalidation")
+		}
+	}
+
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	if c.bus == nil {
+		close(c.done)
+		return c
+	}
+	go c.subscribe(ctx)
+	return c
+}
+
+// subscribe 订阅失效消息，订阅失败时重试
+func (c *LayeredCache) subscribe(ctx context.Context) {
+	defer close(c.done)
+	for {
+		err := c.bus.Subscribe(ctx, c.apply)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.errorLog(err)
+		}
+		// 订阅期间可能丢失了消息，清空本地缓存
+		_ = c.local.Clear(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.retry):
+		}
+	}
+}
+
+// apply 处理其他实例发出的失效消息
+func (c *LayeredCache) apply(msg CacheInvalidation) {
+	if msg.Origin == c.id {
+		return
+	}
+	ctx := context.Background()
+	switch msg.Op {
+	case invalidateKey:
+		for _, key := range msg.Values {
+			_ = c.local.Delete(ctx, key)
+		}
+	case invalidateTags:
+		_ = c.local.DeleteByTags(ctx, msg.Values...)
+	case invalidatePrefix:
+		for _, prefix := range msg.Values {
+			_ = c.local.DeleteByPrefix(ctx, prefix)
+		}
+	case invalidateClear:
+		_ = c.local.Clear(ctx)
+	}
+}
+
+// publish 通知其他实例，广播失败不影响本实例的删除结果
+func (c *LayeredCache) publish(ctx context.Context, op string, values ...string) {
+	if c.bus == nil {
+		return
+	}
+	if err := c.bus.Publish(ctx, CacheInvalidation{Origin: c.id, Op: op, Values: values}); err != nil {
+		c.errorLog(err)
+	}
+}
+
+// localTTL 本地缓存的过期时间不超过远程缓存
+func (c *LayeredCache) localTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < c.nearTTL {
+		return ttl
+	}
+	return c.nearTTL
+}
+
+// Get 从缓存获取值
+func (c *LayeredCache) Get(ctx context.Context, key string, value interface{}) error {
+	scope := cacheScopeFrom(ctx)
+	if scope.layer != CacheLayerRemote {
+		err := c.local.Get(ctx, key, value)
+		if scope.layer == CacheLayerLocal || !errors.Is(err, ErrCacheMiss) {
+			return err
+		}
+	}
+
+	if err := c.remote.Get(ctx, key, value); err != nil {
+		return err
+	}
+	if scope.layer == CacheLayerAll {
+		// 远程缓存的剩余过期时间未知，本地缓存使用 nearTTL
+		_ = c.local.SetWithTags(ctx, key, value, c.nearTTL, scope.tags...)
+	}
+	return nil
+}
+
+// Set 设置缓存值
+func (c *LayeredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags 设置缓存值，并关联标签
+func (c *LayeredCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	layer := cacheScopeFrom(ctx).layer
+	if layer != CacheLayerLocal {
+		var err error
+		if tagCache, ok := c.remote.(interface {
+			SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+		}); ok && len(tags) > 0 {
+			err = tagCache.SetWithTags(ctx, key, value, ttl, tags...)
+		} else {
+			err = c.remote.Set(ctx, key, value, ttl)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if layer == CacheLayerRemote {
+		return nil
+	}
+	if layer == CacheLayerAll {
+		ttl = c.localTTL(ttl)
+	}
+	return c.local.SetWithTags(ctx, key, value, ttl, tags...)
+}
+
+// Delete 删除两级缓存中的值，并通知其他实例
+func (c *LayeredCache) Delete(ctx context.Context, key string) error {
+	_ = c.local.Delete(ctx, key)
+	err := c.remote.Delete(ctx, key)
+	c.publish(ctx, invalidateKey, key)
+	return err
+}
+
+// DeleteByTags 通过标签批量删除两级缓存，并通知其他实例
+func (c *LayeredCache) DeleteByTags(ctx context.Context, tags ...string) error {
+	_ = c.local.DeleteByTags(ctx, tags...)
+	err := c.remote.DeleteByTags(ctx, tags...)
+	c.publish(ctx, invalidateTags, tags...)
+	return err
+}
+
+// DeleteByPrefix 删除两级缓存中以 prefix 开头的值，并通知其他实例
+func (c *LayeredCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	_ = c.local.DeleteByPrefix(ctx, prefix)
+	err := errRemoteNoPrefix
+	if prefixCache, ok := c.remote.(interface {
+		DeleteByPrefix(ctx context.Context, prefix string) error
+	}); ok {
+		err = prefixCache.DeleteByPrefix(ctx, prefix)
+	}
+	c.publish(ctx, invalidatePrefix, prefix)
+	return err
+}
+
+// Clear 清空两级缓存，并通知其他实例
+func (c *LayeredCache) Clear(ctx context.Context) error {
+	_ = c.local.Clear(ctx)
+	err := c.remote.Clear(ctx)
+	c.publish(ctx, invalidateClear)
+	return err
+}
+
+// Close 停止订阅失效消息，远程缓存由调用方关闭
+func (c *LayeredCache) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
@@ -0,0 +1,189 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+
+	require.NoError(t, c.Set(ctx, "a", 1, 0))
+	require.NoError(t, c.Set(ctx, "b", 2, 0))
+	var v int
+	require.NoError(t, c.Get(ctx, "a", &v))
+	// b 最久没有访问，被淘汰
+	require.NoError(t, c.Set(ctx, "c", 3, 0))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "b", &v))
+	require.NoError(t, c.Get(ctx, "a", &v))
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+
+	require.NoError(t, c.SetWithTags(ctx, "user:1", 1, 0, "user"))
+	require.NoError(t, c.SetWithTags(ctx, "user:2", 2, 0, "user"))
+	require.NoError(t, c.DeleteByTags(ctx, "user"))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "user:1", &v))
+	assert.Equal(t, 0, c.Len())
+
+	require.NoError(t, c.Set(ctx, "order:1", 1, 0))
+	require.NoError(t, c.Set(ctx, "item:1", 1, 0))
+	require.NoError(t, c.DeleteByPrefix(ctx, "order:"))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "order:1", &v))
+	require.NoError(t, c.Get(ctx, "item:1", &v))
+
+	require.NoError(t, c.Set(ctx, "ttl", 1, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "ttl", &v))
+	assert.Equal(t, ErrCacheKeyEmpty, c.Set(ctx, "", 1, 0))
+}
+
+// memBus 在同一个进程内广播失效消息
+type memBus struct {
+	mu       sync.Mutex
+	handlers map[int]func(CacheInvalidation)
+	next     int
+}
+
+func (b *memBus) Publish(ctx context.Context, msg CacheInvalidation) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range b.handlers {
+		h(msg)
+	}
+	return nil
+}
+
+func (b *memBus) Subscribe(ctx context.Context, handler func(CacheInvalidation)) error {
+	b.mu.Lock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]func(CacheInvalidation))
+	}
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	b.mu.Unlock()
+
+	<-ctx.Done()
+	b.mu.Lock()
+	delete(b.handlers, id)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *memBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers)
+}
+
+func TestLayeredCache(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache()
+	bus := &memBus{}
+	a := NewLayeredCache(remote, WithInvalidationBus(bus))
+	defer a.Close()
+	b := NewLayeredCache(remote, WithInvalidationBus(bus), WithNearCacheTTL(50*time.Millisecond))
+	defer b.Close()
+	require.Eventually(t, func() bool { return bus.subscribers() == 2 }, time.Second, time.Millisecond)
+
+	var v string
+	require.NoError(t, a.SetWithTags(ctx, "user:1", "Tom", time.Minute, "user"))
+	require.NoError(t, a.local.Get(ctx, "user:1", &v))
+
+	// b 从远程缓存读取并回填本地缓存，回填时关联查询的标签
+	require.NoError(t, b.Get(withCacheScope(ctx, CacheLayerAll, []string{"user"}), "user:1", &v))
+	assert.Equal(t, "Tom", v)
+	require.NoError(t, b.local.Get(ctx, "user:1", &v))
+
+	// a 按标签失效时通知 b 删除本地缓存
+	require.NoError(t, a.DeleteByTags(ctx, "user"))
+	assert.Equal(t, ErrCacheMiss, b.local.Get(ctx, "user:1", &v))
+	assert.Equal(t, ErrCacheMiss, remote.Get(ctx, "user:1", &v))
+
+	require.NoError(t, a.Set(ctx, "user:2", "Jerry", time.Minute))
+	require.NoError(t, b.Get(ctx, "user:2", &v))
+	require.NoError(t, a.DeleteByPrefix(ctx, "user:"))
+	assert.Equal(t, ErrCacheMiss, b.local.Get(ctx, "user:2", &v))
+
+	// 本地缓存的过期时间不超过 nearTTL
+	require.NoError(t, b.Set(ctx, "user:3", "Spike", time.Minute))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, ErrCacheMiss, b.local.Get(ctx, "user:3", &v))
+	require.NoError(t, b.Get(ctx, "user:3", &v))
+
+	require.NoError(t, a.Clear(ctx))
+	assert.Equal(t, ErrCacheMiss, b.local.Get(ctx, "user:3", &v))
+	assert.Equal(t, ErrCacheMiss, a.Get(ctx, "user:3", &v))
+}
+
+func TestLayeredCache_Layer(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache()
+	c := NewLayeredCache(remote)
+	defer c.Close()
+
+	var v string
+	localCtx := withCacheScope(ctx, CacheLayerLocal, nil)
+	require.NoError(t, c.Set(localCtx, "config:1", "a", time.Minute))
+	assert.Equal(t, ErrCacheMiss, remote.Get(ctx, "config:1", &v))
+	require.NoError(t, c.Get(localCtx, "config:1", &v))
+
+	remoteCtx := withCacheScope(ctx, CacheLayerRemote, nil)
+	require.NoError(t, c.Set(remoteCtx, "order:1", "b", time.Minute))
+	require.NoError(t, c.Get(remoteCtx, "order:1", &v))
+	assert.Equal(t, ErrCacheMiss, c.local.Get(ctx, "order:1", &v))
+	assert.Equal(t, ErrCacheMiss, c.Get(localCtx, "order:1", &v))
+}
+
+// TestLayeredCache_ModelLayer 测试通过模型缓存配置选择缓存层
+func TestLayeredCache_ModelLayer(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	mock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", sql.NullString{}))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	remote := NewMemoryCache()
+	layered := NewLayeredCache(remote)
+	defer layered.Close()
+	db.SetCacheManager(NewCacheManager(layered))
+	db.SetModelCacheConfig("test_model", &ModelCacheConfig{Enabled: true, TTL: time.Minute, Layer: CacheLayerRemote})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(1)).WithCache().Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Tom", res.Name)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 0, layered.local.Len())
+}
+
+func TestLayeredCache_Redis(t *testing.T) {
+	remote := newTestRedisCache(t)
+	ctx := context.Background()
+	a := NewLayeredCache(remote)
+	defer a.Close()
+	b := NewLayeredCache(remote)
+	defer b.Close()
+	// 等待订阅完成
+	time.Sleep(100 * time.Millisecond)
+
+	var v string
+	require.NoError(t, a.Set(ctx, "user:1", "Tom", time.Minute))
+	require.NoError(t, b.Get(ctx, "user:1", &v))
+	require.NoError(t, a.Delete(ctx, "user:1"))
+	assert.Eventually(t, func() bool {
+		return b.local.Get(ctx, "user:1", &v) == ErrCacheMiss
+	}, time.Second, 10*time.Millisecond)
+}
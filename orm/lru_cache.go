@@ -0,0 +1,193 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+)
+
+// LRUCache 容量固定的进程内缓存，超过容量时淘汰最久没有访问的数据。
+// 值在写入时序列化，读取时反序列化，调用方拿到的是各自的副本
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	codec      CacheCodec
+	ll         *list.List // 最近访问的在前面
+	items      map[string]*list.Element
+	tagToKeys  map[string]map[string]struct{}
+	clock      clock.Clock
+}
+
+type lruEntry struct {
+	key        string
+	value      []byte
+	expiration int64 // Unix 纳秒时间戳，0 表示永不过期
+	tags       []string
+}
+
+// LRUCacheOption LRUCache 配置项
+type LRUCacheOption func(*LRUCache)
+
+// WithLRUCacheCodec 设置序列化方式，默认为 JSONCodec
+func WithLRUCacheCodec(codec CacheCodec) LRUCacheOption {
+	return func(c *LRUCache) {
+		c.codec = codec
+	}
+}
+
+// NewLRUCache 创建最多保存 maxEntries 条数据的缓存，maxEntries 小于等于0时使用10000
+func NewLRUCache(maxEntries int, opts ...LRUCacheOption) *LRUCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	c := &LRUCache{
+		maxEntries: maxEntries,
+		codec:      JSONCodec,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tagToKeys:  make(map[string]map[string]struct{}),
+		clock:      clock.System(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get 从缓存获取值
+func (c *LRUCache) Get(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return ErrCacheKeyEmpty
+	}
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return ErrCacheMiss
+	}
+	e := el.Value.(*lruEntry)
+	if e.expiration > 0 && e.expiration < c.clock.Now().UnixNano() {
+		c.removeElement(el)
+		c.mu.Unlock()
+		return ErrCacheMiss
+	}
+	c.ll.MoveToFront(el)
+	data := e.value
+	c.mu.Unlock()
+
+	return c.codec.Unmarshal(data, value)
+}
+
+// Set 设置缓存值，ttl 为0时永不过期
+func (c *LRUCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags 设置缓存值，并关联标签
+func (c *LRUCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if key == "" {
+		return ErrCacheKeyEmpty
+	}
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var exp int64
+	if ttl > 0 {
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	e := &lruEntry{key: key, value: data, expiration: exp, tags: tags}
+	c.items[key] = c.ll.PushFront(e)
+	for _, tag := range tags {
+		if c.tagToKeys[tag] == nil {
+			c.tagToKeys[tag] = make(map[string]struct{})
+		}
+		c.tagToKeys[tag][key] = struct{}{}
+	}
+
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+	return nil
+}
+
+// Delete 删除缓存值
+func (c *LRUCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	return nil
+}
+
+// DeleteByTags 通过标签批量删除缓存
+func (c *LRUCache) DeleteByTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tagToKeys[tag] {
+			if el, ok := c.items[key]; ok {
+				c.removeElement(el)
+			}
+		}
+		delete(c.tagToKeys, tag)
+	}
+	return nil
+}
+
+// DeleteByPrefix 删除以 prefix 开头的所有缓存
+func (c *LRUCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+		}
+	}
+	return nil
+}
+
+// Clear 清空缓存
+func (c *LRUCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.tagToKeys = make(map[string]map[string]struct{})
+	return nil
+}
+
+// Len 返回缓存中的数据条数，包括已经过期但还没有被访问到的数据
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// removeElement 删除数据和标签关联，调用方需要持有锁
+func (c *LRUCache) removeElement(el *list.Element) {
+	e := c.ll.Remove(el).(*lruEntry)
+	delete(c.items, e.key)
+	for _, tag := range e.tags {
+		delete(c.tagToKeys[tag], e.key)
+		if len(c.tagToKeys[tag]) == 0 {
+			delete(c.tagToKeys, tag)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// rolesKey 调用方角色在context中的键
+type rolesKey struct{}
+
+// WithRoles 在context中记录调用方角色，脱敏规则根据角色决定是否返回原始数据
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// RolesFromContext 获取context中记录的调用方角色
+func RolesFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
+
+// MaskFunc 字段脱敏函数，接收字段原始值并返回脱敏后的值
+// 返回值需要能够赋值或转换为字段类型，否则保持原值
+type MaskFunc func(value any) any
+
+// maskRule 单个字段的脱敏规则
+type maskRule struct {
+	fieldIndex []int
+	mask       MaskFunc
+	exempt     map[string]struct{} // 可以查看原始数据的角色
+}
+
+// MaskOption 脱敏规则配置选项
+type MaskOption func(*maskRule)
+
+// MaskExempt 设置可以查看原始数据的角色
+func MaskExempt(roles ...string) MaskOption {
+	return func(r *maskRule) {
+		for _, role := range roles {
+			r.exempt[role] = struct{}{}
+		}
+	}
+}
+
+// exempted 判断调用方是否拥有豁免角色
+func (r *maskRule) exempted(roles []string) bool {
+	for _, role := range roles {
+		if _, ok := r.exempt[role]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Masker 读取结果的脱敏管道
+// 查询结果在扫描完成后按模型和字段应用脱敏函数，缓存中保存的始终是原始数据
+type Masker struct {
+	mu    sync.RWMutex
+	rules map[reflect.Type][]*maskRule
+}
+
+// NewMasker 创建脱敏管道
+func NewMasker() *Masker {
+	return &Masker{
+		rules: make(map[reflect.Type][]*maskRule),
+	}
+}
+
+// Register 为模型的字段注册脱敏函数
+// model为模型结构体或其指针，field为结构体字段名
+func (m *Masker) Register(model any, field string, fn MaskFunc, opts ...MaskOption) error {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return fmt.Errorf("orm: masking requires a struct model, got %T", model)
+	}
+
+	sf, ok := typ.FieldByName(field)
+	if !ok || !sf.IsExported() {
+		return fmt.Errorf("orm: invalid masking field %s.%s", typ.Name(), field)
+	}
+
+	rule := &maskRule{
+		fieldIndex: sf.Index,
+		mask:       fn,
+		exempt:     make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(rule)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules[typ] = append(m.rules[typ], rule)
+	return nil
+}
+
+// Apply 根据context中的调用方角色对结构体指针应用脱敏规则
+func (m *Masker) Apply(ctx context.Context, val any) {
+	if m == nil || val == nil {
+		return
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return
+	}
+	v = v.Elem()
+
+	m.mu.RLock()
+	rules := m.rules[v.Type()]
+	m.mu.RUnlock()
+	if len(rules) == 0 {
+		return
+	}
+
+	roles := RolesFromContext(ctx)
+	for _, rule := range rules {
+		if rule.exempted(roles) {
+			continue
+		}
+		applyMask(v.FieldByIndex(rule.fieldIndex), rule.mask)
+	}
+}
+
+// applyMask 对单个字段应用脱敏函数，指针字段会对其指向的值脱敏
+func applyMask(fv reflect.Value, fn MaskFunc) {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	if !fv.CanSet() {
+		return
+	}
+
+	masked := fn(fv.Interface())
+	if masked == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return
+	}
+
+	mv := reflect.ValueOf(masked)
+	switch {
+	case mv.Type().AssignableTo(fv.Type()):
+		fv.Set(mv)
+	case mv.Type().ConvertibleTo(fv.Type()):
+		fv.Set(mv.Convert(fv.Type()))
+	}
+}
+
+// WithMasker 为数据库配置读取结果的脱敏管道
+func WithMasker(masker *Masker) DBOption {
+	return func(db *DB) error {
+		db.masker = masker
+		return nil
+	}
+}
+
+// applyMasks 对查询结果应用脱敏规则
+func (s *Selector[T]) applyMasks(ctx context.Context, results ...*T) {
+	masker := s.layer.getDB().masker
+	if masker == nil {
+		return
+	}
+	for _, t := range results {
+		masker.Apply(ctx, t)
+	}
+}
+
+// MaskRedact 将字段替换为零值
+func MaskRedact() MaskFunc {
+	return func(value any) any {
+		return nil
+	}
+}
+
+// MaskString 保留字符串首尾指定数量的字符，其余部分替换为*
+func MaskString(keepPrefix, keepSuffix int) MaskFunc {
+	return func(value any) any {
+		s, ok := value.(string)
+		if !ok {
+			return value
+		}
+		return maskMiddle(s, keepPrefix, keepSuffix)
+	}
+}
+
+// MaskEmail 保留邮箱用户名首字符和域名，例如 t***@example.com
+func MaskEmail() MaskFunc {
+	return func(value any) any {
+		s, ok := value.(string)
+		if !ok {
+			return value
+		}
+		name, domain, found := strings.Cut(s, "@")
+		if !found {
+			return maskMiddle(s, 1, 0)
+		}
+		return maskMiddle(name, 1, 0) + "@" + domain
+	}
+}
+
+// maskMiddle 替换字符串中间部分，字符串过短时全部替换
+func maskMiddle(s string, keepPrefix, keepSuffix int) string {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		return s
+	}
+	if keepPrefix+keepSuffix >= n {
+		return strings.Repeat("*", n)
+	}
+
+	runes := []rune(s)
+	var sb strings.Builder
+	sb.WriteString(string(runes[:keepPrefix]))
+	sb.WriteString(strings.Repeat("*", n-keepPrefix-keepSuffix))
+	sb.WriteString(string(runes[n-keepSuffix:]))
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MaskedUser struct {
+	ID    int
+	Name  string
+	Email string
+	Phone *string
+}
+
+func TestMaskFuncs(t *testing.T) {
+	assert.Equal(t, "138****5678", MaskString(3, 4)("13812345678"))
+	assert.Equal(t, "***", MaskString(2, 2)("abc"))
+	assert.Equal(t, "张*", MaskString(1, 0)("张三"))
+	assert.Equal(t, "t**@example.com", MaskEmail()("tom@example.com"))
+	assert.Equal(t, 42, MaskString(1, 1)(42))
+	assert.Nil(t, MaskRedact()("secret"))
+}
+
+func TestMasker_Register(t *testing.T) {
+	masker := NewMasker()
+	assert.NoError(t, masker.Register(&MaskedUser{}, "Email", MaskEmail()))
+	assert.Error(t, masker.Register(MaskedUser{}, "Unknown", MaskEmail()))
+	assert.Error(t, masker.Register("user", "Email", MaskEmail()))
+}
+
+func TestSelector_Masking(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	masker := NewMasker()
+	require.NoError(t, masker.Register(MaskedUser{}, "Email", MaskEmail(), MaskExempt("admin", "support")))
+	require.NoError(t, masker.Register(MaskedUser{}, "Phone", MaskString(3, 4), MaskExempt("admin")))
+
+	db, err := Open(mockDB, "mysql", WithMasker(masker))
+	require.NoError(t, err)
+
+	phone := "13812345678"
+	maskedPhone := "138****5678"
+
+	testCases := []struct {
+		name    string
+		roles   []string
+		wantRes *MaskedUser
+	}{
+		{
+			name:    "anonymous",
+			wantRes: &MaskedUser{ID: 1, Name: "Tom", Email: "t**@example.com", Phone: &maskedPhone},
+		},
+		{
+			name:    "support",
+			roles:   []string{"support"},
+			wantRes: &MaskedUser{ID: 1, Name: "Tom", Email: "tom@example.com", Phone: &maskedPhone},
+		},
+		{
+			name:    "admin",
+			roles:   []string{"user", "admin"},
+			wantRes: &MaskedUser{ID: 1, Name: "Tom", Email: "tom@example.com", Phone: &phone},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery("SELECT \\* FROM `masked_user` WHERE `id` = \\?;").
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
+					AddRow(1, "Tom", "tom@example.com", phone))
+
+			ctx := WithRoles(context.Background(), tc.roles...)
+			res, err := RegisterSelector[MaskedUser](db).
+				Select().
+				Where(Col("ID").Eq(1)).
+				Get(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM `masked_user`;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
+			AddRow(1, "Tom", "tom@example.com", nil).
+			AddRow(2, "Jerry", "jerry@example.com", phone))
+
+	res, err := RegisterSelector[MaskedUser](db).Select().GetMulti(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*MaskedUser{
+		{ID: 1, Name: "Tom", Email: "t**@example.com"},
+		{ID: 2, Name: "Jerry", Email: "j****@example.com", Phone: &maskedPhone},
+	}, res)
+}
+
+func TestSelector_MaskingWithCache(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	masker := NewMasker()
+	require.NoError(t, masker.Register(MaskedUser{}, "Email", MaskEmail(), MaskExempt("admin")))
+
+	db, err := Open(mockDB, "mysql", WithMasker(masker), WithDBCache(NewMemoryCache()))
+	require.NoError(t, err)
+	db.SetModelCacheConfig("masked_user", &ModelCacheConfig{
+		Enabled: true,
+		TTL:     time.Minute,
+	})
+
+	mock.ExpectQuery("SELECT \\* FROM `masked_user` WHERE `id` = \\?;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
+			AddRow(1, "Tom", "tom@example.com", nil))
+
+	// 第一次查询写入缓存，普通调用方拿到脱敏数据
+	res, err := RegisterSelector[MaskedUser](db).Select().Where(Col("ID").Eq(1)).WithCache().Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "t**@example.com", res.Email)
+
+	// 缓存中保存的是原始数据，有权限的调用方命中缓存时拿到原始数据
+	res, err = RegisterSelector[MaskedUser](db).Select().Where(Col("ID").Eq(1)).WithCache().
+		Get(WithRoles(context.Background(), "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, "tom@example.com", res.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
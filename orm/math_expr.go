@@ -0,0 +1,127 @@
+package orm
+
+import "strings"
+
+// MathExpr 算术表达式，用于在数据库中基于列的当前值计算新值，避免先读后写的竞争：
+//
+//	RegisterUpdater[Account](db).Update().
+//		Set(Col("Balance"), Col("Balance").Add(10)).
+//		Where(Col("ID").Eq(1))
+//	// UPDATE `account` SET `balance` = `balance` + ? WHERE `id` = ?;
+//
+// 嵌套的表达式总是带括号，Col("A").Add(1).Mul(2) 生成 (`a` + ?) * ?
+type MathExpr struct {
+	left  Expression
+	op    string
+	right Expression
+}
+
+func (m *MathExpr) expr() {}
+
+// Add 返回 c + val，val 可以是值、列或其他表达式
+func (c *Column) Add(val any) *MathExpr {
+	return newMathExpr(c, "+", val)
+}
+
+// Sub 返回 c - val
+func (c *Column) Sub(val any) *MathExpr {
+	return newMathExpr(c, "-", val)
+}
+
+// Mul 返回 c * val
+func (c *Column) Mul(val any) *MathExpr {
+	return newMathExpr(c, "*", val)
+}
+
+// Div 返回 c / val，整数列在 MySQL 中的结果为小数，PostgreSQL 和 SQLite 中为截断后的整数
+func (c *Column) Div(val any) *MathExpr {
+	return newMathExpr(c, "/", val)
+}
+
+// Add 返回 m + val
+func (m *MathExpr) Add(val any) *MathExpr {
+	return newMathExpr(m, "+", val)
+}
+
+// Sub 返回 m - val
+func (m *MathExpr) Sub(val any) *MathExpr {
+	return newMathExpr(m, "-", val)
+}
+
+// Mul 返回 m * val
+func (m *MathExpr) Mul(val any) *MathExpr {
+	return newMathExpr(m, "*", val)
+}
+
+// Div 返回 m / val
+func (m *MathExpr) Div(val any) *MathExpr {
+	return newMathExpr(m, "/", val)
+}
+
+func newMathExpr(left Expression, op string, right any) *MathExpr {
+	return &MathExpr{left: left, op: op, right: exprArg(right)}
+}
+
+// exprArg 表达式原样返回，其他值作为参数
+func exprArg(val any) Expression {
+	if e, ok := val.(Expression); ok {
+		return e
+	}
+	return valueOf(val)
+}
+
+func (m *MathExpr) build(builder *strings.Builder, md *model, args *[]any) {
+	buildOperand(builder, md, m.left, args)
+	builder.WriteByte(' ')
+	builder.WriteString(m.op)
+	builder.WriteByte(' ')
+	buildOperand(builder, md, m.right, args)
+}
+
+// FuncExpr SQL函数调用，函数名原样写入，参数可以是值、列或其他表达式：
+//
+//	Set(Col("Stock"), Func("GREATEST", Col("Stock").Sub(n), 0))
+//	// SET `stock` = GREATEST(`stock` - ?, ?)
+type FuncExpr struct {
+	name string
+	args []Expression
+}
+
+func (f *FuncExpr) expr() {}
+
+// Func 返回函数调用表达式，name 会被原样写入SQL，不能来自用户输入
+func Func(name string, args ...any) *FuncExpr {
+	f := &FuncExpr{name: name, args: make([]Expression, 0, len(args))}
+	for _, arg := range args {
+		f.args = append(f.args, exprArg(arg))
+	}
+	return f
+}
+
+func (f *FuncExpr) build(builder *strings.Builder, md *model, args *[]any) {
+	builder.WriteString(f.name)
+	builder.WriteByte('(')
+	for i, arg := range f.args {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		// 函数的参数之间用逗号分隔，不需要括号
+		if m, ok := arg.(*MathExpr); ok {
+			m.build(builder, md, args)
+			continue
+		}
+		buildOperand(builder, md, arg, args)
+	}
+	builder.WriteByte(')')
+}
+
+// buildOperand 写入算术表达式和函数的操作数，嵌套的算术表达式加括号
+func buildOperand(builder *strings.Builder, md *model, expr Expression, args *[]any) {
+	if m, ok := expr.(*MathExpr); ok {
+		builder.WriteByte('(')
+		m.build(builder, md, args)
+		builder.WriteByte(')')
+		return
+	}
+	(&Predicate{model: md}).buildExpr(expr, builder, args)
+}
@@ -3,8 +3,11 @@ package orm
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
 )
 
 // MemoryCache 是一个简单的内存缓存实现
@@ -16,6 +19,7 @@ type MemoryCache struct {
 	gcInterval time.Duration
 	maxEntries int
 	stopCh     chan struct{}
+	clock      clock.Clock
 }
 
 type item struct {
@@ -48,6 +52,7 @@ func NewMemoryCache(options ...MemoryCacheOption) *MemoryCache {
 		gcInterval: 5 * time.Minute,
 		maxEntries: 10000,
 		stopCh:     make(chan struct{}),
+		clock:      clock.System(),
 	}
 
 	for _, option := range options {
@@ -76,7 +81,7 @@ func (c *MemoryCache) gcLoop() {
 
 // deleteExpired 删除所有过期的缓存项
 func (c *MemoryCache) deleteExpired() {
-	now := time.Now().UnixNano()
+	now := c.clock.Now().UnixNano()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -116,7 +121,7 @@ func (c *MemoryCache) Get(ctx context.Context, key string, value interface{}) er
 	}
 
 	// 检查是否过期
-	if item.expiration > 0 && item.expiration < time.Now().UnixNano() {
+	if item.expiration > 0 && item.expiration < c.clock.Now().UnixNano() {
 		c.mu.Lock()
 		c.delete(key)
 		c.mu.Unlock()
@@ -138,7 +143,7 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 	// 计算过期时间
 	var exp int64
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixNano()
+		exp = c.clock.Now().Add(ttl).UnixNano()
 	}
 
 	c.mu.Lock()
@@ -249,6 +254,19 @@ func (c *MemoryCache) DeleteByTags(ctx context.Context, tags ...string) error {
 	return nil
 }
 
+// DeleteByPrefix 删除以 prefix 开头的所有缓存，CacheManager 在模型没有标签时使用它失效模型的缓存
+func (c *MemoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.data {
+		if strings.HasPrefix(key, prefix) {
+			c.delete(key)
+		}
+	}
+	return nil
+}
+
 // Clear 清空缓存
 func (c *MemoryCache) Clear(ctx context.Context) error {
 	c.mu.Lock()
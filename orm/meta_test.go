@@ -1,6 +1,13 @@
 package orm
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
 
 func TestMeta(t *testing.T) {
 	type User struct {
@@ -28,3 +35,105 @@ func TestMeta(t *testing.T) {
 		}
 	}
 }
+
+type metaUser struct {
+	ID       int64
+	UserName string
+	Age      int
+}
+
+func TestModelMeta(t *testing.T) {
+	RegisterModelMeta(ModelMeta[metaUser]{
+		Table: "meta_users",
+		Fields: []FieldMeta{
+			{Name: "ID", Column: "id", Tag: "primary_key;auto_increment"},
+			{Name: "UserName", Column: "name", Tag: "column_name:name;size:64"},
+			{Name: "Age", Column: "age"},
+		},
+		Addr: func(t *metaUser, i int) any {
+			switch i {
+			case 0:
+				return &t.ID
+			case 1:
+				return &t.UserName
+			case 2:
+				return &t.Age
+			}
+			return nil
+		},
+	})
+
+	m, err := parseModel(&metaUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.table != "meta_users" {
+		t.Fatalf("expected table meta_users, but got %s", m.table)
+	}
+	if f := m.fieldsMap["UserName"]; f == nil || f.colName != "name" || f.size != 64 {
+		t.Fatalf("unexpected field UserName: %+v", f)
+	}
+	if !m.fieldsMap["ID"].primaryKey || !m.fieldsMap["ID"].autoIncr {
+		t.Fatal("expected ID to be an auto increment primary key")
+	}
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM `meta_users` WHERE `name` = \\?;").
+		WithArgs("tom").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age", "unknown"}).AddRow(1, "tom", 18, "x"))
+
+	u, err := RegisterSelector[metaUser](db).Select().
+		Where(Col("UserName").Eq("tom")).
+		Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *u != (metaUser{ID: 1, UserName: "tom", Age: 18}) {
+		t.Fatalf("unexpected result: %+v", u)
+	}
+}
+
+func TestModelMeta_OutOfDate(t *testing.T) {
+	type staleUser struct {
+		ID   int64
+		Name string
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for out of date meta")
+		}
+	}()
+	RegisterModelMeta(ModelMeta[staleUser]{
+		Fields: []FieldMeta{{Name: "ID", Column: "id"}},
+		Addr:   func(t *staleUser, i int) any { return &t.ID },
+	})
+}
+
+func TestParseModelMeta_StaleField(t *testing.T) {
+	type staleUser struct {
+		ID   int64
+		Name string
+	}
+
+	// 绕过注册时的检查，模拟结构体修改后元数据没有重新生成
+	_, err := parseModelMeta(reflect.TypeOf(staleUser{}), &modelMeta{
+		table: "stale_user",
+		fields: []FieldMeta{
+			{Name: "ID", Column: "id"},
+			{Name: "Email", Column: "email"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "field Email not found") {
+		t.Fatalf("expected stale field error, got %v", err)
+	}
+}
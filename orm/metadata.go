@@ -17,6 +17,14 @@ type model struct {
 	tableAliasMap map[string]string
 	dialect       Dialect // 添加dialect字段
 	index         int     // 用于postgresql的占位符
+
+	// 使用生成的元数据时设置，colIndex 为列名到字段下标的映射，addr 为 func(*T, int) any
+	colIndex map[string]int
+	addr     any
+
+	// 按字段顺序收集的索引、唯一约束和外键
+	indexes     []*IndexSchema
+	foreignKeys []*ForeignKeySchema
 }
 
 // field 扩展字段结构体，添加更多类型和约束信息
@@ -28,12 +36,21 @@ type field struct {
 	primaryKey bool          // 是否为主键
 	unique     bool          // 是否唯一
 	index      bool          // 是否索引
+	uniqueName string        // 唯一约束名，多个字段使用同一个名称时为联合唯一约束
+	indexName  string        // 索引名，多个字段使用同一个名称时为联合索引
+	fk         string        // 外键引用，如 users(id)
+	onDelete   string        // 外键的删除规则
+	onUpdate   string        // 外键的更新规则
 	default_   string        // 默认值
 	comment    string        // 字段注释
 	precision  int           // 精度(小数点后位数)
 	scale      int           // 范围(总位数)
 	autoIncr   bool          // 是否自增
 	sqlType    string        // 显式指定的SQL类型
+	json       bool          // 是否为需要自动序列化的 JSON 字段
+	array      bool          // 是否为 PostgreSQL 数组字段
+	enum       []string      // 枚举的取值
+	enumType   string        // PostgreSQL 的枚举类型名，默认为 表名_列名
 }
 
 func parseModel(v any) (*model, error) {
@@ -45,17 +62,19 @@ func parseModel(v any) (*model, error) {
 		typ = typ.Elem()
 	}
 
+	// 优先使用生成的元数据
+	if meta, ok := lookupModelMeta(typ); ok {
+		return parseModelMeta(typ, meta)
+	}
+
 	num := typ.NumField()
 	fields := make(map[string]*field, num)
 	colNameMap := make(map[string]string, num)
+	ordered := make([]*field, 0, num)
 
 	for i := 0; i < num; i++ {
-		fieldVar := &field{}
 		f := typ.Field(i)
 
-		// 记录字段类型信息
-		fieldVar.typ = f.Type
-
 		// 检查是否有自定义tag
 		tags, err := parseTag(f)
 		if err != nil {
@@ -63,56 +82,157 @@ func parseModel(v any) (*model, error) {
 		}
 
 		// 设置列名
-		if colName, ok := tags["column_name"]; ok {
-			fieldVar.colName = colName
-		} else {
-			fieldVar.colName = utils.CamelToSnake(f.Name)
+		colName, ok := tags["column_name"]
+		if !ok {
+			colName = utils.CamelToSnake(f.Name)
 		}
 
-		// 解析其他标签属性
-		fieldVar.primaryKey = tags["primary_key"] == "true"
-		fieldVar.nullable = tags["nullable"] != "false" // 默认可空
-		fieldVar.unique = tags["unique"] == "true"
-		fieldVar.index = tags["index"] == "true"
-		fieldVar.autoIncr = tags["auto_increment"] == "true" || tags["auto_incr"] == "true"
-		fieldVar.default_ = tags["default"]
-		fieldVar.comment = tags["comment"]
-
-		if size, ok := tags["size"]; ok {
-			fieldVar.size, _ = strconv.Atoi(size)
-		}
+		fieldVar := newField(f.Type, colName, tags)
+		fields[f.Name] = fieldVar
+		// 存储列名到字段名的映射
+		colNameMap[fieldVar.colName] = f.Name
+		ordered = append(ordered, fieldVar)
+	}
 
-		if precision, ok := tags["precision"]; ok {
-			fieldVar.precision, _ = strconv.Atoi(precision)
-		}
+	table := utils.CamelToSnake(typ.Name())
+	setEnumTypes(table, ordered)
+	indexes, foreignKeys, err := modelConstraints(table, ordered)
+	if err != nil {
+		return nil, err
+	}
 
-		if scale, ok := tags["scale"]; ok {
-			fieldVar.scale, _ = strconv.Atoi(scale)
-		}
+	return &model{
+		table:         table,
+		fieldsMap:     fields,
+		colNameMap:    colNameMap,
+		colAliasMap:   make(map[string]bool, 4),
+		tableAliasMap: make(map[string]string, 4),
+		dialect:       nil, // 初始为nil，将在后续设置
+		indexes:       indexes,
+		foreignKeys:   foreignKeys,
+	}, nil
+}
 
-		if sqlType, ok := tags["type"]; ok {
-			fieldVar.sqlType = sqlType
+// parseModelMeta 根据生成的元数据构造模型，列名和标签都来自生成的代码
+func parseModelMeta(typ reflect.Type, meta *modelMeta) (*model, error) {
+	num := len(meta.fields)
+	fields := make(map[string]*field, num)
+	colNameMap := make(map[string]string, num)
+	colIndex := make(map[string]int, num)
+	ordered := make([]*field, 0, num)
+
+	for i, fm := range meta.fields {
+		tags, err := parseTagString(fm.Tag)
+		if err != nil {
+			return nil, err
+		}
+		// 结构体修改后没有重新生成元数据时，字段可能已经不存在
+		f, ok := typ.FieldByName(fm.Name)
+		if !ok {
+			return nil, ferr.ErrStaleModelMeta(typ, fm.Name)
 		}
+		fields[fm.Name] = newField(f.Type, fm.Column, tags)
+		colNameMap[fm.Column] = fm.Name
+		colIndex[fm.Column] = i
+		ordered = append(ordered, fields[fm.Name])
+	}
 
-		fields[f.Name] = fieldVar
-		// 存储列名到字段名的映射
-		colNameMap[fieldVar.colName] = f.Name
+	setEnumTypes(meta.table, ordered)
+	indexes, foreignKeys, err := modelConstraints(meta.table, ordered)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model{
-		table:         utils.CamelToSnake(typ.Name()),
+		table:         meta.table,
 		fieldsMap:     fields,
 		colNameMap:    colNameMap,
 		colAliasMap:   make(map[string]bool, 4),
 		tableAliasMap: make(map[string]string, 4),
-		dialect:       nil, // 初始为nil，将在后续设置
+		colIndex:      colIndex,
+		addr:          meta.addr,
+		indexes:       indexes,
+		foreignKeys:   foreignKeys,
 	}, nil
 }
 
+// newField 根据字段类型、列名和标签构造字段信息
+func newField(typ reflect.Type, colName string, tags map[string]string) *field {
+	fieldVar := &field{
+		colName: colName,
+		typ:     typ, // 记录字段类型信息
+	}
+
+	// 解析其他标签属性
+	fieldVar.primaryKey = tags["primary_key"] == "true"
+	fieldVar.nullable = tags["nullable"] != "false" // 默认可空
+	// unique 和 index 可以指定名称，如 `orm:"index:idx_name_email"`，同名的字段组成联合索引
+	fieldVar.unique, fieldVar.uniqueName = tagName(tags["unique"])
+	fieldVar.index, fieldVar.indexName = tagName(tags["index"])
+	fieldVar.fk = tags["fk"]
+	fieldVar.onDelete = tags["on_delete"]
+	fieldVar.onUpdate = tags["on_update"]
+	fieldVar.autoIncr = tags["auto_increment"] == "true" || tags["auto_incr"] == "true"
+	fieldVar.default_ = tags["default"]
+	fieldVar.comment = tags["comment"]
+
+	if size, ok := tags["size"]; ok {
+		fieldVar.size, _ = strconv.Atoi(size)
+	}
+
+	if precision, ok := tags["precision"]; ok {
+		fieldVar.precision, _ = strconv.Atoi(precision)
+	}
+
+	if scale, ok := tags["scale"]; ok {
+		fieldVar.scale, _ = strconv.Atoi(scale)
+	}
+
+	if sqlType, ok := tags["type"]; ok {
+		fieldVar.sqlType = sqlType
+	}
+	fieldVar.json = isJSONField(fieldVar.sqlType, typ)
+	fieldVar.array = isArrayField(fieldVar.sqlType, typ)
+	if enum, ok := tags["enum"]; ok {
+		fieldVar.enum = strings.Split(enum, ",")
+		fieldVar.enumType = tags["enum_type"]
+	}
+
+	return fieldVar
+}
+
+// fieldArg 写入字段 f 的值：JSON 字段在执行时序列化，PostgreSQL 的数组字段编码为数组字面量
+func fieldArg(d Dialect, f *field, val any) any {
+	val = jsonArg(f, val)
+	if _, ok := d.(*Postgresql); ok {
+		val = arrayArg(f, val)
+	}
+	return val
+}
+
+// scanDest 列 col 的扫描目标，JSON 字段和 PostgreSQL 的数组字段先扫描到转换用的 Scanner 中
+func (m *model) scanDest(col string, dst any) any {
+	f, ok := m.fieldsMap[m.colNameMap[col]]
+	if !ok {
+		return dst
+	}
+	if f.json {
+		return &jsonScanner{dst: dst}
+	}
+	if _, pg := m.dialect.(*Postgresql); pg && f.array {
+		return &arrayScanner{dst: dst}
+	}
+	return dst
+}
+
 // parseTag 解析tag
 // tag格式：`orm:"column_name:col_name;primary_key:true;size:255"`
 func parseTag(field reflect.StructField) (map[string]string, error) {
-	tag := field.Tag.Get("orm")
+	return parseTagString(field.Tag.Get("orm"))
+}
+
+// parseTagString 解析 orm 标签的内容
+func parseTagString(tag string) (map[string]string, error) {
 	if tag == "" {
 		return nil, nil
 	}
@@ -123,6 +243,23 @@ func parseTag(field reflect.StructField) (map[string]string, error) {
 			continue
 		}
 
+		// 外键的删除和更新规则可以写在同一项中：fk:users(id) on_delete:cascade
+		if strings.HasPrefix(part, "fk:") {
+			opts := strings.Fields(strings.TrimPrefix(part, "fk:"))
+			if len(opts) == 0 {
+				return nil, ferr.ErrInvalidTag(tag)
+			}
+			tags["fk"] = opts[0]
+			for _, opt := range opts[1:] {
+				kv := strings.Split(opt, ":")
+				if len(kv) != 2 || (kv[0] != "on_delete" && kv[0] != "on_update") {
+					return nil, ferr.ErrInvalidTag(tag)
+				}
+				tags[kv[0]] = kv[1]
+			}
+			continue
+		}
+
 		kvs := strings.Split(part, ":")
 		if len(kvs) == 1 {
 			// 处理没有值的标签，如 `orm:"primary_key"`
@@ -137,6 +274,33 @@ func parseTag(field reflect.StructField) (map[string]string, error) {
 	return tags, nil
 }
 
+// tagName 解析 unique 和 index 标签的值：true 表示使用默认名称，其他值为索引名
+func tagName(val string) (bool, string) {
+	switch val {
+	case "", "false":
+		return false, ""
+	case "true":
+		return true, ""
+	default:
+		return true, val
+	}
+}
+
+// clone 复制模型供单个构造器使用：字段信息只读，与缓存的模型共享；
+// 构造SQL时会修改的表名、占位符序号和别名表各自独立，多个构造器可以并发使用同一个类型的模型
+func (m *model) clone() *model {
+	c := *m
+	c.colAliasMap = make(map[string]bool, len(m.colAliasMap))
+	for k, v := range m.colAliasMap {
+		c.colAliasMap[k] = v
+	}
+	c.tableAliasMap = make(map[string]string, len(m.tableAliasMap))
+	for k, v := range m.tableAliasMap {
+		c.tableAliasMap[k] = v
+	}
+	return &c
+}
+
 // SetDialect 为模型设置方言
 func (m *model) SetDialect(dialect Dialect) {
 	m.dialect = dialect
@@ -25,6 +25,9 @@ type QueryContext struct {
 	TableName  string      // 表名，支持分片时可能会被替换
 	ShardKey   string      // 用于分片的键
 	ShardValue interface{} // 分片键的值
+
+	stmt *sql.Stmt // 复用的预编译语句，设置后核心处理器通过它执行 Query
+	inTx bool      // 是否在事务中执行
 }
 
 // QueryResult 查询结果定义
@@ -53,15 +56,44 @@ type CoreHandler struct {
 }
 
 func (c *CoreHandler) QueryHandler(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+	stmt := qc.stmt
+	query := qc.Query.SQL
+	if stmt == nil && c.db.stmtCache != nil && (qc.QueryType == "query" || qc.QueryType == "exec") {
+		// 注释通常包含请求ID等每次都不同的标签，附加了注释的SQL不使用预编译语句缓存，直接执行
+		if query = c.db.commentSQL(ctx, query); query == qc.Query.SQL {
+			// 预编译失败时退回直接执行，由执行返回具体的错误
+			if cs, err := c.db.stmtCache.acquire(ctx, query); err == nil {
+				defer c.db.stmtCache.release(cs)
+				stmt = cs.stmt
+			}
+		}
+	}
+
 	switch qc.QueryType {
 	case "query":
-		rows, err := c.db.queryContext(ctx, qc.Query.SQL, qc.Query.Args...)
+		var (
+			rows *sql.Rows
+			err  error
+		)
+		if stmt != nil {
+			rows, err = c.db.queryStmtContext(ctx, stmt, qc.Query.SQL, qc.Query.Args...)
+		} else {
+			rows, err = c.db.queryContext(ctx, query, qc.Query.Args...)
+		}
 		return &QueryResult{
 			Rows: rows,
 			Err:  err,
 		}, err
 	case "exec":
-		res, err := c.db.execContext(ctx, qc.Query.SQL, qc.Query.Args...)
+		var (
+			res sql.Result
+			err error
+		)
+		if stmt != nil {
+			res, err = c.db.execStmtContext(ctx, stmt, qc.Query.SQL, qc.Query.Args...)
+		} else {
+			res, err = c.db.execContext(ctx, query, qc.Query.Args...)
+		}
 		return &QueryResult{
 			Result: Result{
 				res: res,
@@ -139,6 +171,9 @@ func ShardingMiddleware(manager *ShardingManager) Middleware {
 				qc.TableName = tableName
 			}
 
+			// 预编译语句属于默认数据库，在分片上直接执行
+			qc.stmt = nil
+
 			// 创建一个新的查询上下文，使用分片DB处理
 			shardHandler := &CoreHandler{db: shardDB}
 			return shardHandler.QueryHandler(ctx, qc)
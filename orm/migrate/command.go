@@ -0,0 +1,235 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Run 执行命令行形式的迁移命令，供 fyer migrate 和嵌入迁移的程序使用
+//
+//	up [N]      执行没有执行过的迁移，N 为最多执行的个数
+//	down [N]    回滚最近执行的 N 个迁移，默认为1
+//	redo        回滚并重新执行最近执行的迁移
+//	status      显示迁移的执行状态
+//	unlock      强制释放迁移锁
+//	create NAME [-go]  在 WithDir 设置的目录中创建迁移文件
+func Run(ctx context.Context, m *Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("migrate: missing command")
+	}
+	cmd, rest := args[0], args[1:]
+
+	switch cmd {
+	case "up":
+		n, err := stepsArg(rest, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := m.Up(ctx, n)
+		for _, mg := range applied {
+			fmt.Fprintf(out, "applied  %s\n", mg)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+		return err
+	case "down":
+		n, err := stepsArg(rest, 1)
+		if err != nil {
+			return err
+		}
+		reverted, err := m.Down(ctx, n)
+		for _, mg := range reverted {
+			fmt.Fprintf(out, "reverted %s\n", mg)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(out, "no applied migrations")
+		}
+		return err
+	case "redo":
+		mg, err := m.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		if mg == nil {
+			fmt.Fprintln(out, "no applied migrations")
+			return nil
+		}
+		fmt.Fprintf(out, "redone   %s\n", mg)
+		return nil
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(out, statuses)
+	case "unlock":
+		if err := m.Unlock(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "lock released")
+		return nil
+	case "create":
+		if m.dir == "" {
+			return errors.New("migrate: create needs a migration directory, use WithDir")
+		}
+		return RunCreate(m.dir, rest, out)
+	default:
+		return fmt.Errorf("migrate: unknown command %q", cmd)
+	}
+}
+
+// RunCreate 执行 create 命令，不需要连接数据库
+func RunCreate(dir string, args []string, out io.Writer) error {
+	var name string
+	goFile := false
+	for _, arg := range args {
+		if arg == "-go" || arg == "--go" {
+			goFile = true
+			continue
+		}
+		if name != "" {
+			return fmt.Errorf("migrate: unexpected argument %q", arg)
+		}
+		name = arg
+	}
+	files, err := Create(dir, name, goFile)
+	for _, f := range files {
+		fmt.Fprintf(out, "created  %s\n", f)
+	}
+	return err
+}
+
+func stepsArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("migrate: unexpected argument %q", args[1])
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("migrate: invalid step count %q", args[0])
+	}
+	return n, nil
+}
+
+func printStatus(out io.Writer, statuses []Status) error {
+	if len(statuses) == 0 {
+		fmt.Fprintln(out, "no migrations")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, at := "pending", ""
+		if s.Applied {
+			state = "applied"
+			at = s.AppliedAt.Format(time.RFC3339)
+			switch {
+			case s.Migration == nil:
+				state = "applied (missing)"
+			case s.Modified:
+				state = "applied (modified)"
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+	}
+	return w.Flush()
+}
+
+// nameRe 迁移名称只能包含字母、数字和下划线
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Create 在 dir 中创建一个以当前时间为版本号的迁移。goFile 为 false 时创建 up 和 down 两个
+// SQL 文件，为 true 时创建一个调用 Register 的 Go 文件，返回创建的文件路径
+func Create(dir, name string, goFile bool) ([]string, error) {
+	if name == "" {
+		return nil, errors.New("migrate: migration name is required")
+	}
+	if !nameRe.MatchString(name) {
+		return nil, fmt.Errorf("migrate: invalid migration name %q, use letters, digits and underscores", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	version := time.Now().UTC().Format("20060102150405")
+	base := filepath.Join(dir, version+"_"+name)
+
+	contents := map[string]string{
+		base + ".up.sql":   fmt.Sprintf("-- %s_%s up\n", version, name),
+		base + ".down.sql": fmt.Sprintf("-- %s_%s down\n", version, name),
+	}
+	order := []string{base + ".up.sql", base + ".down.sql"}
+	if goFile {
+		contents = map[string]string{base + ".go": goTemplate(dir, version, name)}
+		order = []string{base + ".go"}
+	}
+
+	var created []string
+	for _, path := range order {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return created, err
+		}
+		_, err = f.WriteString(contents[path])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+func goTemplate(dir, version, name string) string {
+	pkg := strings.ReplaceAll(filepath.Base(dir), "-", "_")
+	if !nameRe.MatchString(pkg) || pkg[0] >= '0' && pkg[0] <= '9' {
+		pkg = "migrations"
+	}
+	fn := camel(name)
+	return fmt.Sprintf(`package %s
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fyerfyer/fyer-webframe/orm/migrate"
+)
+
+func init() {
+	migrate.Register(%s, %q, up%s, down%s)
+}
+
+func up%s(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+func down%s(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+`, pkg, version, name, fn, fn, fn, fn)
+}
+
+// camel add_user_status -> AddUserStatus
+func camel(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
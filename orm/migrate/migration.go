@@ -0,0 +1,239 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Func Go 迁移函数，在事务中执行
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+// Migration 一个版本化迁移。版本号通常是创建时的时间戳，例如 20240102150405
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Func
+	Down    Func
+
+	// 来自 SQL 文件的迁移，Up 和 Down 执行这里的语句
+	UpSQL   string
+	DownSQL string
+	Source  string // up 文件名，Go 迁移为空
+	NoTx    bool   // 不在事务中执行，SQL 文件以 "-- migrate:no-transaction" 开头时设置
+}
+
+// Checksum 返回 SQL 迁移内容的校验和，用于发现已执行的迁移文件被修改。Go 迁移返回空字符串
+func (m *Migration) Checksum() string {
+	if m.Source == "" {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(m.UpSQL+"\x00"+m.DownSQL)))
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[int64]*Migration)
+)
+
+// Register 注册 Go 迁移，通常在迁移文件的 init 函数中调用。版本号重复时 panic
+//
+//	func init() {
+//		migrate.Register(20240102150405, "add_user_status", upAddUserStatus, downAddUserStatus)
+//	}
+func Register(version int64, name string, up, down Func) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[version]; ok {
+		panic(fmt.Sprintf("migrate: duplicate migration version %d", version))
+	}
+	registry[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+}
+
+// registered 返回已注册的 Go 迁移
+func registered() []*Migration {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	res := make([]*Migration, 0, len(registry))
+	for _, m := range registry {
+		res = append(res, m)
+	}
+	return res
+}
+
+// fileNameRe SQL 迁移文件名：<版本号>_<名称>.up.sql 或 <版本号>_<名称>.down.sql
+var fileNameRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// noTxDirective SQL 文件以这一行开头时不在事务中执行，例如 PostgreSQL 的 CREATE INDEX CONCURRENTLY
+const noTxDirective = "-- migrate:no-transaction"
+
+// loadSQLMigrations 读取 fsys 根目录下的 SQL 迁移文件
+func loadSQLMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := fileNameRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version in %s: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		content := string(data)
+		if match[3] == "up" {
+			m.UpSQL = content
+			m.Source = e.Name()
+			m.NoTx = strings.HasPrefix(strings.TrimSpace(content), noTxDirective)
+		} else {
+			m.DownSQL = content
+		}
+	}
+
+	res := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migrate: migration %s has no up file", m)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// mergeMigrations 合并 SQL 迁移和 Go 迁移并按版本号排序，版本号重复时返回错误
+func mergeMigrations(lists ...[]*Migration) ([]*Migration, error) {
+	seen := make(map[int64]*Migration)
+	var res []*Migration
+	for _, list := range lists {
+		for _, m := range list {
+			if prev, ok := seen[m.Version]; ok {
+				return nil, fmt.Errorf("migrate: duplicate migration version %d: %s and %s", m.Version, prev, m)
+			}
+			seen[m.Version] = m
+			res = append(res, m)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// splitStatements 把 SQL 文件拆分为单条语句。分号出现在字符串、引用的标识符、注释
+// 或 PostgreSQL 的 $$ 引用中时不作为分隔符
+func splitStatements(content string) []string {
+	var stmts []string
+	var cur strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(cur.String()); stmt != "" && !isComment(stmt) {
+			stmts = append(stmts, stmt)
+		}
+		cur.Reset()
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// 字符串和引用的标识符，两个连续的引号表示引号本身
+			end := i + 1
+			for end < len(content) {
+				if content[end] == c {
+					if end+1 < len(content) && content[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				if content[end] == '\\' && c == '\'' {
+					end++
+				}
+				end++
+			}
+			cur.WriteString(content[i:min(end+1, len(content))])
+			i = end
+		case c == '-' && i+1 < len(content) && content[i+1] == '-':
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			cur.WriteString(content[i : i+end])
+			i += end - 1
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			} else {
+				end += 2
+			}
+			cur.WriteString(content[i : i+2+end])
+			i += 2 + end - 1
+		case c == '$':
+			// PostgreSQL 的 $tag$ ... $tag$
+			if tag := dollarTag(content[i:]); tag != "" {
+				end := strings.Index(content[i+len(tag):], tag)
+				if end < 0 {
+					end = len(content) - i - len(tag)
+				} else {
+					end += len(tag)
+				}
+				cur.WriteString(content[i : i+len(tag)+end])
+				i += len(tag) + end - 1
+				continue
+			}
+			cur.WriteByte(c)
+		case c == ';':
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return stmts
+}
+
+var dollarTagRe = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+func dollarTag(s string) string {
+	return dollarTagRe.FindString(s)
+}
+
+// isComment 语句只包含注释
+func isComment(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
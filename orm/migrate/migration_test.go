@@ -0,0 +1,145 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "multiple statements",
+			content: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:    []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:    "semicolon in string",
+			content: "INSERT INTO a VALUES ('x;y', 'it''s');INSERT INTO a VALUES ('\\';')",
+			want:    []string{"INSERT INTO a VALUES ('x;y', 'it''s')", "INSERT INTO a VALUES ('\\';')"},
+		},
+		{
+			name:    "semicolon in comments",
+			content: "-- drop; later\nSELECT 1; /* a; b */ SELECT 2;",
+			want:    []string{"-- drop; later\nSELECT 1", "/* a; b */ SELECT 2"},
+		},
+		{
+			name:    "comment only",
+			content: "-- 20240101000000_init up\n",
+			want:    nil,
+		},
+		{
+			name: "dollar quoting",
+			content: "CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a = 1; RETURN NEW; END; $body$ LANGUAGE plpgsql;" +
+				"SELECT $$a;b$$",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a = 1; RETURN NEW; END; $body$ LANGUAGE plpgsql",
+				"SELECT $$a;b$$",
+			},
+		},
+		{
+			name:    "placeholder is not dollar quote",
+			content: "UPDATE a SET b = $1; SELECT 1",
+			want:    []string{"UPDATE a SET b = $1", "SELECT 1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, splitStatements(tc.content))
+		})
+	}
+}
+
+func TestLoadSQLMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240102000000_add_email.up.sql":   {Data: []byte("ALTER TABLE user ADD email VARCHAR(255);")},
+		"20240102000000_add_email.down.sql": {Data: []byte("ALTER TABLE user DROP email;")},
+		"20240101000000_init.up.sql":        {Data: []byte("CREATE TABLE user (id INT);")},
+		"20240103000000_index.up.sql":       {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY idx ON user (email);")},
+		"README.md":                         {Data: []byte("ignored")},
+	}
+
+	migrations, err := loadSQLMigrations(fsys)
+	require.NoError(t, err)
+	migrations, err = mergeMigrations(migrations)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+
+	assert.Equal(t, "20240101000000_init", migrations[0].String())
+	assert.Empty(t, migrations[0].DownSQL)
+
+	assert.Equal(t, "add_email", migrations[1].Name)
+	assert.Equal(t, "ALTER TABLE user DROP email;", migrations[1].DownSQL)
+	assert.Equal(t, "20240102000000_add_email.up.sql", migrations[1].Source)
+	assert.NotEmpty(t, migrations[1].Checksum())
+	assert.False(t, migrations[1].NoTx)
+
+	assert.True(t, migrations[2].NoTx)
+}
+
+func TestLoadSQLMigrations_Invalid(t *testing.T) {
+	_, err := loadSQLMigrations(fstest.MapFS{
+		"20240101000000_init.down.sql": {Data: []byte("DROP TABLE user;")},
+	})
+	assert.ErrorContains(t, err, "no up file")
+
+	_, err = loadSQLMigrations(fstest.MapFS{
+		"20240101000000_init.up.sql":    {Data: []byte("CREATE TABLE user (id INT);")},
+		"20240101000000_other.down.sql": {Data: []byte("DROP TABLE user;")},
+	})
+	assert.ErrorContains(t, err, "different names")
+}
+
+func TestMergeMigrations_Duplicate(t *testing.T) {
+	files := []*Migration{{Version: 1, Name: "a", UpSQL: "SELECT 1", Source: "1_a.up.sql"}}
+	gos := []*Migration{{Version: 1, Name: "b"}}
+	_, err := mergeMigrations(files, gos)
+	assert.ErrorContains(t, err, "duplicate migration version 1")
+}
+
+func TestMigration_Checksum(t *testing.T) {
+	goMigration := &Migration{Version: 1, Name: "go"}
+	assert.Empty(t, goMigration.Checksum())
+
+	a := &Migration{Version: 1, Name: "a", UpSQL: "SELECT 1", Source: "1_a.up.sql"}
+	b := &Migration{Version: 1, Name: "a", UpSQL: "SELECT 2", Source: "1_a.up.sql"}
+	assert.NotEqual(t, a.Checksum(), b.Checksum())
+}
+
+func TestCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+
+	files, err := Create(dir, "add_user_status", false)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.True(t, strings.HasSuffix(files[0], "_add_user_status.up.sql"))
+	assert.True(t, strings.HasSuffix(files[1], "_add_user_status.down.sql"))
+
+	migrations, err := loadSQLMigrations(os.DirFS(dir))
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, "add_user_status", migrations[0].Name)
+
+	files, err = Create(dir, "backfill", true)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package migrations")
+	assert.Contains(t, string(data), `"backfill", upBackfill, downBackfill)`)
+
+	_, err = Create(dir, "bad name", false)
+	assert.Error(t, err)
+	_, err = Create(dir, "", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,512 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLocked 其他进程正在执行迁移，等待超过 WithLockTimeout 设置的时间
+	ErrLocked = errors.New("migrate: another migration is running")
+
+	// ErrMissingMigration 数据库中记录已执行的迁移找不到对应的迁移文件或 Go 迁移
+	ErrMissingMigration = errors.New("migrate: applied migration not found")
+
+	// ErrNoDown 迁移没有 down 部分，不能回滚
+	ErrNoDown = errors.New("migrate: migration has no down migration")
+)
+
+// Migrator 执行版本化迁移。已执行的版本记录在 schema_migrations 表中，
+// 执行期间通过 schema_migrations_lock 表保证同一时刻只有一个进程在迁移
+type Migrator struct {
+	db          *sql.DB
+	dialect     orm.Dialect
+	schema      *orm.SchemaManager // 写入 SchemaManager 的迁移日志，为 nil 时不写入
+	fsys        fs.FS
+	dir         string
+	extra       []*Migration
+	table       string
+	lockTimeout time.Duration
+	lockRetry   time.Duration
+	owner       string
+	schemaLog   bool
+}
+
+// Option Migrator 配置项
+type Option func(*Migrator)
+
+// WithDir 从目录读取 SQL 迁移文件，create 命令也在这个目录中创建文件
+func WithDir(dir string) Option {
+	return func(m *Migrator) {
+		m.dir = dir
+		m.fsys = os.DirFS(dir)
+	}
+}
+
+// WithFS 从 fsys 的根目录读取 SQL 迁移文件，例如通过 embed 打包到程序中的迁移文件
+func WithFS(fsys fs.FS) Option {
+	return func(m *Migrator) {
+		m.fsys = fsys
+	}
+}
+
+// WithMigrations 添加 Go 迁移，与 Register 注册的迁移一起执行
+func WithMigrations(migrations ...*Migration) Option {
+	return func(m *Migrator) {
+		m.extra = append(m.extra, migrations...)
+	}
+}
+
+// WithTable 设置记录已执行版本的表名，默认为 schema_migrations，锁表名为表名加 _lock
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLockTimeout 设置等待其他进程释放迁移锁的最长时间，默认为1分钟
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithSchemaLog 设置是否把执行的迁移同时写入 SchemaManager 的迁移日志表 orm_migration_log，默认写入
+func WithSchemaLog(enabled bool) Option {
+	return func(m *Migrator) {
+		m.schemaLog = enabled
+	}
+}
+
+// New 创建 Migrator，dialect 为 mysql、postgresql 或 sqlite
+//
+//	migrator, err := migrate.New(sqlDB, "mysql", migrate.WithDir("./migrations"))
+//	applied, err := migrator.Up(ctx, 0)
+func New(db *sql.DB, dialect string, opts ...Option) (*Migrator, error) {
+	d := orm.Get(dialect)
+	if d == nil {
+		return nil, fmt.Errorf("migrate: unknown dialect %q", dialect)
+	}
+	m := &Migrator{
+		db:          db,
+		dialect:     d,
+		table:       "schema_migrations",
+		lockTimeout: time.Minute,
+		lockRetry:   time.Second,
+		owner:       uuid.NewString(),
+		schemaLog:   true,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.schemaLog {
+		ormDB, err := orm.Open(db, dialect)
+		if err != nil {
+			return nil, err
+		}
+		m.schema = orm.NewSchemaManager(ormDB)
+	}
+	return m, nil
+}
+
+// Migrations 返回全部迁移，按版本号排序
+func (m *Migrator) Migrations() ([]*Migration, error) {
+	var files []*Migration
+	if m.fsys != nil {
+		var err error
+		if files, err = loadSQLMigrations(m.fsys); err != nil {
+			return nil, err
+		}
+	}
+	return mergeMigrations(files, registered(), m.extra)
+}
+
+// Up 按版本号顺序执行没有执行过的迁移，steps 大于0时最多执行 steps 个，返回执行了的迁移
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(ctx, func() error {
+		all, applied, err := m.load(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mg := range all {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if err := m.run(ctx, mg, true); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按版本号倒序回滚最近执行的 steps 个迁移，steps 小于1时回滚1个，返回回滚了的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	var done []*Migration
+	err := m.withLock(ctx, func() error {
+		all, applied, err := m.load(ctx)
+		if err != nil {
+			return err
+		}
+		for _, version := range latestFirst(applied) {
+			if len(done) == steps {
+				break
+			}
+			mg := findMigration(all, version)
+			if mg == nil {
+				return fmt.Errorf("%w: version %d (%s)", ErrMissingMigration, version, applied[version].name)
+			}
+			if err := m.run(ctx, mg, false); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo 回滚最近执行的迁移后重新执行，没有执行过的迁移时返回 nil
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func() error {
+		all, applied, err := m.load(ctx)
+		if err != nil {
+			return err
+		}
+		versions := latestFirst(applied)
+		if len(versions) == 0 {
+			return nil
+		}
+		mg := findMigration(all, versions[0])
+		if mg == nil {
+			return fmt.Errorf("%w: version %d (%s)", ErrMissingMigration, versions[0], applied[versions[0]].name)
+		}
+		if err := m.run(ctx, mg, false); err != nil {
+			return err
+		}
+		if err := m.run(ctx, mg, true); err != nil {
+			return err
+		}
+		redone = mg
+		return nil
+	})
+	return redone, err
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Migration *Migration // 已执行但找不到迁移定义时为 nil
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // 执行之后 SQL 文件被修改过
+}
+
+// Status 返回全部迁移和已执行版本的状态，按版本号排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	all, applied, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Status
+	for _, mg := range all {
+		s := Status{Version: mg.Version, Name: mg.Name, Migration: mg}
+		if rec, ok := applied[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = rec.appliedAt
+			s.Modified = rec.checksum != "" && rec.checksum != mg.Checksum()
+		}
+		res = append(res, s)
+	}
+	for version, rec := range applied {
+		if findMigration(all, version) == nil {
+			res = append(res, Status{Version: version, Name: rec.name, Applied: true, AppliedAt: rec.appliedAt})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Unlock 强制释放迁移锁，用于持有锁的进程异常退出之后
+func (m *Migrator) Unlock(ctx context.Context) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+	_, err := m.db.ExecContext(ctx, "DELETE FROM "+m.dialect.Quote(m.lockTable())+" WHERE id = 1")
+	return err
+}
+
+// appliedRecord schema_migrations 表中的一行
+type appliedRecord struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// load 读取全部迁移和已执行的版本
+func (m *Migrator) load(ctx context.Context) ([]*Migration, map[int64]appliedRecord, error) {
+	all, err := m.Migrations()
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+m.dialect.Quote(m.table))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var rec appliedRecord
+		if err := rows.Scan(&version, &rec.name, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, nil, err
+		}
+		applied[version] = rec
+	}
+	return all, applied, rows.Err()
+}
+
+// execer *sql.DB 和 *sql.Tx 共有的方法
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// run 执行迁移的 up 或 down 部分，并在同一个事务中更新 schema_migrations
+func (m *Migrator) run(ctx context.Context, mg *Migration, up bool) error {
+	fn, script := mg.Up, mg.UpSQL
+	if !up {
+		fn, script = mg.Down, mg.DownSQL
+	}
+	if fn == nil && script == "" {
+		if up {
+			return fmt.Errorf("migrate: migration %s has no up migration", mg)
+		}
+		return fmt.Errorf("%w: %s", ErrNoDown, mg)
+	}
+
+	apply := func(ctx context.Context, ex execer, tx *sql.Tx) error {
+		for _, stmt := range splitStatements(script) {
+			if _, err := ex.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		if fn != nil {
+			if err := fn(ctx, tx); err != nil {
+				return err
+			}
+		}
+		var err error
+		if up {
+			_, err = ex.ExecContext(ctx, m.bindVars("INSERT INTO "+m.dialect.Quote(m.table)+
+				" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"),
+				mg.Version, mg.Name, mg.Checksum(), time.Now().UTC())
+		} else {
+			_, err = ex.ExecContext(ctx, m.bindVars("DELETE FROM "+m.dialect.Quote(m.table)+" WHERE version = ?"), mg.Version)
+		}
+		return err
+	}
+
+	var err error
+	if mg.NoTx {
+		// 部分语句不能在事务中执行，失败时已经执行的语句不会回滚
+		err = apply(ctx, m.db, nil)
+	} else {
+		err = m.inTx(ctx, func(tx *sql.Tx) error {
+			return apply(ctx, tx, tx)
+		})
+	}
+	if err != nil {
+		direction := "up"
+		if !up {
+			direction = "down"
+		}
+		return fmt.Errorf("migrate: %s %s: %w", direction, mg, err)
+	}
+
+	if up && m.schema != nil {
+		if err := m.logSchema(ctx, mg); err != nil {
+			return fmt.Errorf("migrate: %s applied but writing migration log failed: %w", mg, err)
+		}
+	}
+	return nil
+}
+
+// logSchema 把执行的迁移写入 SchemaManager 的迁移日志，与 MigrateModel 的记录放在一起
+func (m *Migrator) logSchema(ctx context.Context, mg *Migration) error {
+	ddl := mg.UpSQL
+	if ddl == "" {
+		ddl = "-- go migration " + mg.String()
+	}
+	now := time.Now()
+	return m.schema.LogMigration(ctx, &orm.Migration{
+		ModelName: mg.Name,
+		TableName: m.table,
+		Version:   int(mg.Version),
+		CreatedAt: now,
+		AppliedAt: now,
+		DDL:       ddl,
+		CheckSum:  fmt.Sprintf("%x", sha256.Sum256([]byte(ddl))),
+	})
+}
+
+// inTx 在事务中执行 fn，fn 返回错误时回滚
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock 持有迁移锁执行 fn
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		// ctx 被取消时也要释放锁
+		if err := m.unlock(context.WithoutCancel(ctx)); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: release lock: %v\n", err)
+		}
+	}()
+	return fn()
+}
+
+// lock 在锁表中插入 id 为1的行，行已经存在说明其他进程正在迁移，等待它释放
+func (m *Migrator) lock(ctx context.Context) error {
+	insert := m.bindVars("INSERT INTO " + m.dialect.Quote(m.lockTable()) + " (id, owner, locked_at) VALUES (1, ?, ?)")
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		_, err := m.db.ExecContext(ctx, insert, m.owner, time.Now().UTC())
+		if err == nil {
+			return nil
+		}
+
+		var owner string
+		var lockedAt time.Time
+		row := m.db.QueryRowContext(ctx, "SELECT owner, locked_at FROM "+m.dialect.Quote(m.lockTable())+" WHERE id = 1")
+		if qerr := row.Scan(&owner, &lockedAt); qerr != nil {
+			// 锁不存在，插入失败是其他原因
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: locked by %s at %s, run unlock if that process has exited",
+				ErrLocked, owner, lockedAt.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.lockRetry):
+		}
+	}
+}
+
+// unlock 释放自己持有的锁
+func (m *Migrator) unlock(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, m.bindVars("DELETE FROM "+m.dialect.Quote(m.lockTable())+" WHERE id = 1 AND owner = ?"), m.owner)
+	return err
+}
+
+func (m *Migrator) lockTable() string {
+	return m.table + "_lock"
+}
+
+// ensureTables 创建版本表和锁表
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	timeType := "TIMESTAMP"
+	switch m.dialect.(type) {
+	case *orm.Mysql, *orm.Sqlite:
+		timeType = "DATETIME"
+	case *orm.Postgresql:
+		timeType = "TIMESTAMP WITH TIME ZONE"
+	}
+
+	ddls := []string{
+		"CREATE TABLE IF NOT EXISTS " + m.dialect.Quote(m.table) + " (\n" +
+			"  version BIGINT NOT NULL PRIMARY KEY,\n" +
+			"  name VARCHAR(255) NOT NULL,\n" +
+			"  checksum VARCHAR(64) NOT NULL,\n" +
+			"  applied_at " + timeType + " NOT NULL\n" +
+			")",
+		"CREATE TABLE IF NOT EXISTS " + m.dialect.Quote(m.lockTable()) + " (\n" +
+			"  id INT NOT NULL PRIMARY KEY,\n" +
+			"  owner VARCHAR(64) NOT NULL,\n" +
+			"  locked_at " + timeType + " NOT NULL\n" +
+			")",
+	}
+	for _, ddl := range ddls {
+		if _, err := m.db.ExecContext(ctx, ddl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindVars 把语句中的 ? 替换为方言的占位符
+func (m *Migrator) bindVars(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString(m.dialect.Placeholder(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// latestFirst 已执行的版本，从新到旧排列
+func latestFirst(applied map[int64]appliedRecord) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	return versions
+}
+
+func findMigration(all []*Migration, version int64) *Migration {
+	for _, mg := range all {
+		if mg.Version == version {
+			return mg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"20240101000000_init.up.sql":        {Data: []byte("CREATE TABLE `user` (id INT);")},
+		"20240101000000_init.down.sql":      {Data: []byte("DROP TABLE `user`;")},
+		"20240102000000_add_email.up.sql":   {Data: []byte("ALTER TABLE `user` ADD email VARCHAR(255);")},
+		"20240102000000_add_email.down.sql": {Data: []byte("ALTER TABLE `user` DROP email;")},
+	}
+}
+
+func newTestMigrator(t *testing.T, opts ...Option) (*Migrator, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mockDB.Close() })
+
+	opts = append([]Option{WithFS(testFS()), WithSchemaLog(false)}, opts...)
+	m, err := New(mockDB, "mysql", opts...)
+	require.NoError(t, err)
+	m.lockRetry = time.Millisecond
+	return m, mock
+}
+
+func expectTables(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_migrations` (")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_migrations_lock` (")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations_lock` (id, owner, locked_at) VALUES (1, ?, ?)")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations_lock` WHERE id = 1 AND owner = ?")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions ...int64) {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, v := range versions {
+		rows.AddRow(v, "migration", "", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, name, checksum, applied_at FROM `schema_migrations`")).
+		WillReturnRows(rows)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock, 20240101000000)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `user` ADD email VARCHAR(255)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations` (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)")).
+		WithArgs(int64(20240102000000), "add_email", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	applied, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "20240102000000_add_email", applied[0].String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Up_RollbackOnError(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE `user` (id INT)")).
+		WillReturnError(errors.New("table exists"))
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	applied, err := m.Up(context.Background(), 0)
+	assert.ErrorContains(t, err, "up 20240101000000_init: table exists")
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_GoMigration(t *testing.T) {
+	var called bool
+	m, mock := newTestMigrator(t, WithFS(fstest.MapFS{}), WithMigrations(&Migration{
+		Version: 20240105000000,
+		Name:    "backfill",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			called = true
+			_, err := tx.ExecContext(ctx, "UPDATE `user` SET status = 1")
+			return err
+		},
+	}))
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `user` SET status = 1")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations`")).
+		WithArgs(int64(20240105000000), "backfill", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	_, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	assert.True(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock, 20240101000000, 20240102000000)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE `user` DROP email")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations` WHERE version = ?")).
+		WithArgs(int64(20240102000000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	reverted, err := m.Down(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, int64(20240102000000), reverted[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_Missing(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock, 20240101000000, 20240109000000)
+	expectUnlock(mock)
+
+	_, err := m.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrMissingMigration)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Redo(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock, 20240101000000)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE `user`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations`")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE `user` (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	mg, err := m.Redo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "init", mg.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Locked(t *testing.T) {
+	m, mock := newTestMigrator(t, WithLockTimeout(0))
+
+	expectTables(mock)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations_lock`")).
+		WillReturnError(errors.New("Duplicate entry '1' for key 'PRIMARY'"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT owner, locked_at FROM `schema_migrations_lock` WHERE id = 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"owner", "locked_at"}).AddRow("other", time.Now()))
+
+	_, err := m.Up(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.ErrorContains(t, err, "locked by other")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"}).
+		AddRow(int64(20240101000000), "init", "stale", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)).
+		AddRow(int64(20231231000000), "removed", "", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, name, checksum, applied_at FROM `schema_migrations`")).
+		WillReturnRows(rows)
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+
+	assert.Equal(t, "removed", statuses[0].Name)
+	assert.True(t, statuses[0].Applied)
+	assert.Nil(t, statuses[0].Migration)
+
+	assert.True(t, statuses[1].Applied)
+	assert.True(t, statuses[1].Modified)
+
+	assert.False(t, statuses[2].Applied)
+
+	var out bytes.Buffer
+	require.NoError(t, printStatus(&out, statuses))
+	assert.Contains(t, out.String(), "applied (missing)")
+	assert.Contains(t, out.String(), "applied (modified)")
+	assert.Contains(t, out.String(), "pending")
+}
+
+func TestMigrator_SchemaLog(t *testing.T) {
+	m, mock := newTestMigrator(t, WithSchemaLog(true), WithFS(fstest.MapFS{
+		"20240101000000_init.up.sql": {Data: []byte("CREATE TABLE `user` (id INT);")},
+	}))
+
+	expectTables(mock)
+	expectLock(mock)
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE `user` (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_migrations`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS orm_migration_log")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM orm_migration_log")).
+		WithArgs("init", "schema_migrations", 20240101000000).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO orm_migration_log")).
+		WithArgs("init", "schema_migrations", 20240101000000, sqlmock.AnyArg(), sqlmock.AnyArg(),
+			"CREATE TABLE `user` (id INT);", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectUnlock(mock)
+
+	_, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRun(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectTables(mock)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `schema_migrations_lock` WHERE id = 1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var out bytes.Buffer
+	require.NoError(t, Run(context.Background(), m, []string{"unlock"}, &out))
+	assert.Equal(t, "lock released\n", out.String())
+
+	assert.Error(t, Run(context.Background(), m, []string{"up", "x"}, &out))
+	assert.Error(t, Run(context.Background(), m, []string{"sideways"}, &out))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -6,7 +6,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +23,7 @@ type Migration struct {
 	AppliedAt   time.Time // 应用时间
 	DDL         string    // DDL语句
 	CheckSum    string    // 迁移内容的校验和
+	Plan        *SchemaPlan // 迁移计划，只在创建表和 AlterIfNeeded 修改表时设置
 }
 
 // MigrationStrategy 定义迁移策略
@@ -47,6 +50,8 @@ type MigrateOptions struct {
 	DryRun             bool              // 是否为试运行模式（不实际执行SQL）
 	OnMigrated         func(m *Migration) // 迁移完成后的回调
 	Schema             string            // 数据库Schema（仅PostgreSQL等支持schema的数据库有效）
+	AllowDestructive   bool              // AlterIfNeeded 是否执行删除列、缩小类型等可能丢失数据的变更
+	PlanOutput         io.Writer         // 输出迁移计划，通常与 DryRun 一起使用
 }
 
 // MigrateOption 是构建MigrateOptions的函数选项
@@ -87,6 +92,20 @@ func WithSchema(schema string) MigrateOption {
 	}
 }
 
+// WithAllowDestructive 设置 AlterIfNeeded 是否执行破坏性变更。默认不执行，只在迁移计划中列出
+func WithAllowDestructive(allow bool) MigrateOption {
+	return func(o *MigrateOptions) {
+		o.AllowDestructive = allow
+	}
+}
+
+// WithPlanOutput 设置迁移计划的输出，每个模型的计划中安全变更和破坏性变更分开列出
+func WithPlanOutput(w io.Writer) MigrateOption {
+	return func(o *MigrateOptions) {
+		o.PlanOutput = w
+	}
+}
+
 // SchemaManager 管理数据库架构迁移
 type SchemaManager struct {
 	db            *DB
@@ -136,7 +155,7 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 	}
 
 	var ddl string
-	var existingModel *model
+	var plan *SchemaPlan
 
 	// 根据策略生成DDL
 	switch options.Strategy {
@@ -146,41 +165,39 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 			return nil
 		}
 		ddl = sm.db.dialect.CreateTableSQL(m)
+		plan = createPlan(m.table, ddl)
 
 	case AlterIfNeeded:
 		if tableExists {
-			// 获取已存在表的结构
-			existingModel, err = sm.getExistingTableModel(ctx, options.Schema, m.table)
+			// 比较已存在表的结构，生成ALTER TABLE语句
+			plan, err = sm.alterPlan(ctx, val, m, options)
 			if err != nil {
 				return fmt.Errorf("获取已存在表结构失败: %w", err)
 			}
-
-			// 比较并生成ALTER TABLE语句
-			ddl = sm.db.dialect.AlterTableSQL(m, existingModel)
+			ddl = plan.DDL()
 		} else {
 			ddl = sm.db.dialect.CreateTableSQL(m)
+			plan = createPlan(m.table, ddl)
 		}
 
 	case DropAndCreateIfChanged:
 		if tableExists {
-			// 获取已存在表的结构
-			existingModel, err = sm.getExistingTableModel(ctx, options.Schema, m.table)
+			// 表结构是否变化
+			changed, err := sm.alterPlan(ctx, val, m, options)
 			if err != nil {
 				return fmt.Errorf("获取已存在表结构失败: %w", err)
 			}
-
-			// 表结构是否变化
-			if sm.isTableChanged(m, existingModel) {
-				// 生成删除和创建表的SQL
-				dropSQL := fmt.Sprintf("DROP TABLE %s;", sm.db.dialect.Quote(m.table))
-				createSQL := sm.db.dialect.CreateTableSQL(m)
-				ddl = dropSQL + "\n" + createSQL
-			} else {
+			if changed.Empty() {
 				// 表结构没有变化，不需要操作
 				return nil
 			}
+			// 生成删除和创建表的SQL
+			dropSQL := fmt.Sprintf("DROP TABLE %s;", sm.db.dialect.Quote(m.table))
+			createSQL := sm.db.dialect.CreateTableSQL(m)
+			ddl = dropSQL + "\n" + createSQL
 		} else {
 			ddl = sm.db.dialect.CreateTableSQL(m)
+			plan = createPlan(m.table, ddl)
 		}
 
 	case ForceRecreate:
@@ -191,12 +208,18 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 			ddl = dropSQL + "\n" + createSQL
 		} else {
 			ddl = sm.db.dialect.CreateTableSQL(m)
+			plan = createPlan(m.table, ddl)
 		}
 
 	default:
 		return errors.New("未知的迁移策略")
 	}
 
+	// 输出迁移计划，没有执行的破坏性变更也会列出
+	if options.PlanOutput != nil && plan != nil {
+		fmt.Fprint(options.PlanOutput, plan.String())
+	}
+
 	// 如果没有需要执行的DDL，直接返回
 	if ddl == "" {
 		return nil
@@ -207,9 +230,10 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 		ModelName: modelName,
 		TableName: m.table,
 		Version:   1, // 简单实现，实际应基于变更计算
-		CreatedAt: time.Now(),
+		CreatedAt: sm.db.Now(),
 		DDL:       ddl,
 		CheckSum:  calculateChecksum(ddl),
+		Plan:      plan,
 	}
 
 	// 执行DDL
@@ -220,7 +244,7 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 
 		// 记录迁移日志
 		if options.CreateMigrationLog {
-			migration.AppliedAt = time.Now()
+			migration.AppliedAt = sm.db.Now()
 			if err := sm.logMigration(ctx, migration); err != nil {
 				return fmt.Errorf("记录迁移日志失败: %w", err)
 			}
@@ -235,6 +259,50 @@ func (sm *SchemaManager) MigrateModel(ctx context.Context, val any, opts ...Migr
 	return nil
 }
 
+// PlanModel 生成把表结构迁移到模型定义的计划，不执行任何变更。表不存在时计划为创建表
+func (sm *SchemaManager) PlanModel(ctx context.Context, val any, opts ...MigrateOption) (*SchemaPlan, error) {
+	options := &MigrateOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	m, err := sm.db.getModel(val)
+	if err != nil {
+		return nil, err
+	}
+	tableExists, err := sm.tableExists(ctx, options.Schema, m.table)
+	if err != nil {
+		return nil, fmt.Errorf("检查表是否存在失败: %w", err)
+	}
+	if !tableExists {
+		return createPlan(m.table, sm.db.dialect.CreateTableSQL(m)), nil
+	}
+	return sm.alterPlan(ctx, val, m, options)
+}
+
+// alterPlan 比较模型定义和已存在表的结构，生成修改表的计划
+func (sm *SchemaManager) alterPlan(ctx context.Context, val any, m *model, options *MigrateOptions) (*SchemaPlan, error) {
+	d, ok := sm.db.dialect.(schemaDialect)
+	if !ok {
+		return nil, errors.New("不支持的数据库类型")
+	}
+	actual, err := sm.readTableSchema(ctx, d, options.Schema, m.table)
+	if err != nil {
+		return nil, err
+	}
+	desired := desiredTableSchema(d, m, reflect.TypeOf(val))
+	return buildSchemaPlan(d, desired, actual, options.AllowDestructive), nil
+}
+
+// createPlan 创建表的计划，PostgreSQL 和 SQLite 的建表语句之后还有创建索引的语句
+func createPlan(table, ddl string) *SchemaPlan {
+	return &SchemaPlan{
+		Table:      table,
+		Create:     true,
+		Statements: splitStatements(ddl),
+	}
+}
+
 // MigrateAll 迁移所有已注册的模型
 func (sm *SchemaManager) MigrateAll(ctx context.Context, opts ...MigrateOption) error {
 	sm.mu.RLock()
@@ -324,27 +392,16 @@ func (sm *SchemaManager) tableExists(ctx context.Context, schema, table string)
 	return rows.Next(), nil
 }
 
-// getExistingTableModel 从数据库中获取已存在表的结构
-func (sm *SchemaManager) getExistingTableModel(ctx context.Context, schema, table string) (*model, error) {
-	// 这个实现是简化的，实际应该从数据库的information_schema中读取表结构
-	// 不同数据库有不同的方式获取表结构信息
-
-	// 创建一个空的模型用于存储表结构
-	m := &model{
-		table:       table,
-		fieldsMap:   make(map[string]*field),
-		colNameMap:  make(map[string]string),
-		dialect:     sm.db.dialect,
-	}
-
+// readTableSchema 从数据库的系统表中读取已存在表的结构，列按在表中的顺序排列
+func (sm *SchemaManager) readTableSchema(ctx context.Context, d schemaDialect, schema, table string) (*TableSchema, error) {
 	// 根据数据库类型，从系统表中查询列信息
 	var query string
-	switch sm.db.dialect.(type) {
+	switch d.(type) {
 	case *Mysql:
 		query = fmt.Sprintf(`
             SELECT 
                 COLUMN_NAME,
-                DATA_TYPE,
+                COLUMN_TYPE,
                 IS_NULLABLE,
                 COLUMN_DEFAULT,
                 CHARACTER_MAXIMUM_LENGTH,
@@ -355,22 +412,35 @@ func (sm *SchemaManager) getExistingTableModel(ctx context.Context, schema, tabl
             FROM 
                 INFORMATION_SCHEMA.COLUMNS
             WHERE 
-                TABLE_SCHEMA = DATABASE() AND TABLE_NAME = '%s'
-        `, table)
+                TABLE_SCHEMA = COALESCE(NULLIF('%s', ''), DATABASE()) AND TABLE_NAME = '%s'
+            ORDER BY ORDINAL_POSITION
+        `, schema, table)
 	case *Postgresql:
 		query = fmt.Sprintf(`
             SELECT 
-                column_name,
-                data_type,
-                is_nullable,
-                column_default,
-                character_maximum_length,
-                numeric_precision,
-                numeric_scale
+                c.column_name,
+                CASE c.data_type
+                    WHEN 'ARRAY' THEN substr(c.udt_name, 2) || '[]'
+                    WHEN 'USER-DEFINED' THEN c.udt_name
+                    ELSE c.data_type
+                END,
+                c.is_nullable,
+                c.column_default,
+                c.character_maximum_length,
+                c.numeric_precision,
+                c.numeric_scale,
+                CASE WHEN EXISTS (
+                    SELECT 1 FROM information_schema.table_constraints tc
+                    JOIN information_schema.key_column_usage kcu
+                        ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+                    WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+                        AND tc.table_name = c.table_name AND kcu.column_name = c.column_name
+                ) THEN 'PRI' ELSE '' END
             FROM 
-                information_schema.columns
+                information_schema.columns c
             WHERE 
-                table_schema = COALESCE('%s', 'public') AND table_name = '%s'
+                c.table_schema = COALESCE(NULLIF('%s', ''), 'public') AND c.table_name = '%s'
+            ORDER BY c.ordinal_position
         `, schema, table)
 	case *Sqlite:
 		query = fmt.Sprintf(`PRAGMA table_info('%s')`, table)
@@ -385,17 +455,18 @@ func (sm *SchemaManager) getExistingTableModel(ctx context.Context, schema, tabl
 	}
 	defer rows.Close()
 
-	// 解析结果并填充模型
+	t := &TableSchema{Name: table}
+	// 解析结果
 	for rows.Next() {
 		var colName, dataType, isNullable, columnDefault, columnKey, extra sql.NullString
 		var maxLength, precision, scale sql.NullInt64
 
 		// 根据数据库类型处理不同的结果集结构
-		switch sm.db.dialect.(type) {
+		switch d.(type) {
 		case *Mysql:
 			err = rows.Scan(&colName, &dataType, &isNullable, &columnDefault, &maxLength, &precision, &scale, &columnKey, &extra)
 		case *Postgresql:
-			err = rows.Scan(&colName, &dataType, &isNullable, &columnDefault, &maxLength, &precision, &scale)
+			err = rows.Scan(&colName, &dataType, &isNullable, &columnDefault, &maxLength, &precision, &scale, &columnKey)
 		case *Sqlite:
 			// SQLite的PRAGMA table_info结果列是：cid, name, type, notnull, dflt_value, pk
 			var cid, notNull, pk sql.NullInt64
@@ -405,66 +476,236 @@ func (sm *SchemaManager) getExistingTableModel(ctx context.Context, schema, tabl
 			} else {
 				isNullable.String = "NO"
 			}
-			isNullable.Valid = true
-			if pk.Int64 == 1 {
+			// 联合主键的 pk 为列在主键中的序号
+			if pk.Int64 > 0 {
 				columnKey.String = "PRI"
 			}
-			columnKey.Valid = true
 		}
 
 		if err != nil {
 			return nil, err
 		}
 
-		// 创建字段
-		f := &field{
-			colName:    colName.String,
-			nullable:   isNullable.String == "YES",
-			primaryKey: columnKey.String == "PRI",
-			default_:   columnDefault.String,
-			autoIncr:   strings.Contains(strings.ToLower(extra.String), "auto_increment"),
-			sqlType:    dataType.String,
+		col := &ColumnSchema{
+			Name:       colName.String,
+			Nullable:   isNullable.String == "YES",
+			PrimaryKey: columnKey.String == "PRI",
+			Default:    columnDefault.String,
 		}
 
-		if maxLength.Valid {
-			f.size = int(maxLength.Int64)
+		// 系统表中的类型补上长度和精度后再规范化
+		typ := dataType.String
+		lower := strings.ToLower(typ)
+		if !strings.Contains(typ, "(") {
+			switch lower {
+			case "varchar", "char", "varbinary", "binary", "character varying", "character":
+				if maxLength.Valid {
+					typ += fmt.Sprintf("(%d)", maxLength.Int64)
+				}
+			case "decimal", "numeric":
+				if precision.Valid {
+					typ += fmt.Sprintf("(%d,%d)", precision.Int64, scale.Int64)
+				}
+			}
 		}
+		col.Type, col.AutoIncr = d.normalizeType(typ)
 
-		if precision.Valid {
-			f.precision = int(precision.Int64)
+		switch d.(type) {
+		case *Mysql:
+			col.AutoIncr = strings.Contains(strings.ToLower(extra.String), "auto_increment")
+			if columnDefault.Valid {
+				col.Default = mysqlDefaultLiteral(columnDefault.String, extra.String)
+			}
+		case *Postgresql:
+			// SERIAL 列的默认值为 nextval('表名_列名_seq'::regclass)
+			if strings.HasPrefix(col.Default, "nextval(") {
+				col.AutoIncr = true
+				col.Default = ""
+			}
 		}
-
-		if scale.Valid {
-			f.scale = int(scale.Int64)
+		if strings.EqualFold(col.Default, "NULL") {
+			col.Default = ""
 		}
 
-		// 生成一个对应的字段名
-		fieldName := strings.Title(strings.ToLower(colName.String))
-		m.fieldsMap[fieldName] = f
-		m.colNameMap[colName.String] = fieldName
+		t.Columns = append(t.Columns, col)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return m, nil
+	if err = sm.readConstraints(ctx, d, schema, t); err != nil {
+		return nil, err
+	}
+
+	finishTableSchema(d, t)
+	return t, nil
 }
 
-// executeDDL 执行DDL语句
-func (sm *SchemaManager) executeDDL(ctx context.Context, ddl string) error {
-	// 处理可能的多条SQL语句
-	for _, statement := range strings.Split(ddl, ";") {
-		statement = strings.TrimSpace(statement)
-		if statement == "" {
-			continue
+// readConstraints 读取表上的索引和外键，不包含主键和唯一约束自动创建的索引
+func (sm *SchemaManager) readConstraints(ctx context.Context, d schemaDialect, schema string, t *TableSchema) error {
+	var indexQuery, fkQuery string
+	switch d.(type) {
+	case *Mysql:
+		indexQuery = fmt.Sprintf(`
+            SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0
+            FROM INFORMATION_SCHEMA.STATISTICS
+            WHERE TABLE_SCHEMA = COALESCE(NULLIF('%s', ''), DATABASE()) AND TABLE_NAME = '%s'
+                AND INDEX_NAME <> 'PRIMARY'
+            ORDER BY INDEX_NAME, SEQ_IN_INDEX
+        `, schema, t.Name)
+		fkQuery = fmt.Sprintf(`
+            SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+                r.DELETE_RULE, r.UPDATE_RULE
+            FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+            JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r
+                ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+            WHERE k.TABLE_SCHEMA = COALESCE(NULLIF('%s', ''), DATABASE()) AND k.TABLE_NAME = '%s'
+                AND k.REFERENCED_TABLE_NAME IS NOT NULL
+            ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION
+        `, schema, t.Name)
+	case *Postgresql:
+		indexQuery = fmt.Sprintf(`
+            SELECT i.relname, a.attname, ix.indisunique
+            FROM pg_index ix
+            JOIN pg_class t ON t.oid = ix.indrelid
+            JOIN pg_class i ON i.oid = ix.indexrelid
+            JOIN pg_namespace n ON n.oid = t.relnamespace
+            JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+            JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+            WHERE n.nspname = COALESCE(NULLIF('%s', ''), 'public') AND t.relname = '%s'
+                AND NOT ix.indisprimary
+            ORDER BY i.relname, k.ord
+        `, schema, t.Name)
+		fkQuery = fmt.Sprintf(`
+            SELECT tc.constraint_name, kcu.column_name, ccu.table_name, ccu.column_name,
+                rc.delete_rule, rc.update_rule
+            FROM information_schema.table_constraints tc
+            JOIN information_schema.key_column_usage kcu
+                ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+            JOIN information_schema.referential_constraints rc
+                ON rc.constraint_name = tc.constraint_name AND rc.constraint_schema = tc.table_schema
+            JOIN information_schema.constraint_column_usage ccu
+                ON ccu.constraint_name = tc.constraint_name AND ccu.constraint_schema = tc.table_schema
+            WHERE tc.constraint_type = 'FOREIGN KEY'
+                AND tc.table_schema = COALESCE(NULLIF('%s', ''), 'public') AND tc.table_name = '%s'
+            ORDER BY tc.constraint_name, kcu.ordinal_position
+        `, schema, t.Name)
+	case *Sqlite:
+		// origin 为 c 的索引由 CREATE INDEX 创建，UNIQUE 约束和主键的索引不能单独删除
+		indexQuery = fmt.Sprintf(`
+            SELECT il.name, ii.name, il."unique"
+            FROM pragma_index_list('%s') AS il, pragma_index_info(il.name) AS ii
+            WHERE il.origin = 'c'
+            ORDER BY il.name, ii.seqno
+        `, t.Name)
+		fkQuery = fmt.Sprintf(`
+            SELECT id, "from", "table", "to", on_delete, on_update
+            FROM pragma_foreign_key_list('%s')
+            ORDER BY id, seq
+        `, t.Name)
+	default:
+		return errors.New("不支持的数据库类型")
+	}
+
+	rows, err := sm.db.queryContext(ctx, indexQuery)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, col string
+		var unique bool
+		if err = rows.Scan(&name, &col, &unique); err != nil {
+			rows.Close()
+			return err
 		}
+		if idx := t.Index(name); idx != nil {
+			idx.Columns = append(idx.Columns, col)
+		} else {
+			t.Indexes = append(t.Indexes, &IndexSchema{Name: name, Columns: []string{col}, Unique: unique})
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
 
-		// 确保语句以分号结尾
-		if !strings.HasSuffix(statement, ";") {
-			statement += ";"
+	rows, err = sm.db.queryContext(ctx, fkQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	byKey := make(map[string]*ForeignKeySchema)
+	for rows.Next() {
+		var key, col, refTable, refCol, onDelete, onUpdate string
+		if err = rows.Scan(&key, &col, &refTable, &refCol, &onDelete, &onUpdate); err != nil {
+			return err
+		}
+		fk, ok := byKey[key]
+		if !ok {
+			// SQLite 不保存外键的名称，key 为外键的序号，按默认的命名方式命名
+			name := key
+			if _, isSqlite := d.(*Sqlite); isSqlite {
+				name = "fk_" + t.Name + "_" + col
+			}
+			fk = &ForeignKeySchema{Name: name, RefTable: refTable}
+			byKey[key] = fk
+			fk.OnDelete, _ = normalizeRule(onDelete)
+			fk.OnUpdate, _ = normalizeRule(onUpdate)
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+		// PostgreSQL 联合外键的查询结果中每列会重复出现
+		if !containsString(fk.Columns, col) {
+			fk.Columns = append(fk.Columns, col)
+		}
+		if !containsString(fk.RefColumns, refCol) {
+			fk.RefColumns = append(fk.RefColumns, refCol)
 		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
 
+	// MySQL 创建外键时会自动创建同名的索引，不作为模型的索引比较
+	if _, ok := d.(*Mysql); ok {
+		indexes := t.Indexes[:0]
+		for _, idx := range t.Indexes {
+			if t.ForeignKey(idx.Name) == nil {
+				indexes = append(indexes, idx)
+			}
+		}
+		t.Indexes = indexes
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mysqlDefaultLiteral MySQL 系统表中字符串默认值不带引号，转换为 SQL 字面量
+func mysqlDefaultLiteral(def, extra string) string {
+	upper := strings.ToUpper(def)
+	if strings.Contains(strings.ToUpper(extra), "DEFAULT_GENERATED") ||
+		strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || upper == "NULL" {
+		return def
+	}
+	if _, err := strconv.ParseFloat(def, 64); err == nil {
+		return def
+	}
+	return "'" + strings.ReplaceAll(def, "'", "''") + "'"
+}
+
+// executeDDL 执行DDL语句
+func (sm *SchemaManager) executeDDL(ctx context.Context, ddl string) error {
+	// 处理可能的多条SQL语句
+	for _, statement := range splitStatements(ddl) {
+		statement += ";"
 		_, err := sm.db.execContext(ctx, statement)
 		if err != nil {
 			return fmt.Errorf("执行DDL失败: %s, 错误: %w", statement, err)
@@ -473,6 +714,65 @@ func (sm *SchemaManager) executeDDL(ctx context.Context, ddl string) error {
 	return nil
 }
 
+// splitStatements 按分号拆分多条语句，去掉语句末尾的分号。引号和 $$ 之间的分号不拆分，
+// 例如 PostgreSQL 创建枚举类型使用的 DO $$ ... $$ 语句块
+func splitStatements(ddl string) []string {
+	var (
+		stmts  []string
+		quote  byte
+		dollar bool
+		start  int
+	)
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case dollar:
+			if strings.HasPrefix(ddl[i:], "$$") {
+				dollar = false
+				i++
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(ddl[i:], "$$"):
+			dollar = true
+			i++
+		case c == ';':
+			if stmt := strings.TrimSpace(ddl[start:i]); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if stmt := strings.TrimSpace(ddl[start:]); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// LogMigration 在迁移日志表中记录一次迁移，版本化迁移等不经过 MigrateModel 的变更也通过它记录
+func (sm *SchemaManager) LogMigration(ctx context.Context, m *Migration) error {
+	return sm.logMigration(ctx, m)
+}
+
+// bindVars 把语句中的 ? 替换为方言的占位符
+func (sm *SchemaManager) bindVars(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString(sm.db.dialect.Placeholder(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 // logMigration 记录迁移日志
 func (sm *SchemaManager) logMigration(ctx context.Context, m *Migration) error {
 	// 确保迁移日志表存在
@@ -486,7 +786,7 @@ func (sm *SchemaManager) logMigration(ctx context.Context, m *Migration) error {
         WHERE model_name = ? AND table_name = ? AND version = ?
     `)
 
-	rows, err := sm.db.queryContext(ctx, query, m.ModelName, m.TableName, m.Version)
+	rows, err := sm.db.queryContext(ctx, sm.bindVars(query), m.ModelName, m.TableName, m.Version)
 	if err != nil {
 		return err
 	}
@@ -506,7 +806,7 @@ func (sm *SchemaManager) logMigration(ctx context.Context, m *Migration) error {
             SET ddl = ?, checksum = ?, applied_at = ?
             WHERE model_name = ? AND table_name = ? AND version = ?
         `
-		_, err = sm.db.execContext(ctx, query, m.DDL, m.CheckSum, m.AppliedAt, m.ModelName, m.TableName, m.Version)
+		_, err = sm.db.execContext(ctx, sm.bindVars(query), m.DDL, m.CheckSum, m.AppliedAt, m.ModelName, m.TableName, m.Version)
 	} else {
 		// 否则，插入新的记录
 		query = `
@@ -514,7 +814,7 @@ func (sm *SchemaManager) logMigration(ctx context.Context, m *Migration) error {
             (model_name, table_name, version, created_at, applied_at, ddl, checksum)
             VALUES (?, ?, ?, ?, ?, ?, ?)
         `
-		_, err = sm.db.execContext(ctx, query, m.ModelName, m.TableName, m.Version, m.CreatedAt, m.AppliedAt, m.DDL, m.CheckSum)
+		_, err = sm.db.execContext(ctx, sm.bindVars(query), m.ModelName, m.TableName, m.Version, m.CreatedAt, m.AppliedAt, m.DDL, m.CheckSum)
 	}
 
 	return err
@@ -533,7 +833,7 @@ func (sm *SchemaManager) createMigrationTable(ctx context.Context) error {
                 id INT AUTO_INCREMENT PRIMARY KEY,
                 model_name VARCHAR(255) NOT NULL,
                 table_name VARCHAR(255) NOT NULL,
-                version INT NOT NULL,
+                version BIGINT NOT NULL,
                 created_at DATETIME NOT NULL,
                 applied_at DATETIME NOT NULL,
                 ddl TEXT NOT NULL,
@@ -547,7 +847,7 @@ func (sm *SchemaManager) createMigrationTable(ctx context.Context) error {
                 id SERIAL PRIMARY KEY,
                 model_name VARCHAR(255) NOT NULL,
                 table_name VARCHAR(255) NOT NULL,
-                version INTEGER NOT NULL,
+                version BIGINT NOT NULL,
                 created_at TIMESTAMP WITH TIME ZONE NOT NULL,
                 applied_at TIMESTAMP WITH TIME ZONE NOT NULL,
                 ddl TEXT NOT NULL,
@@ -85,6 +85,11 @@ func TestAutoMigrate_TableExists(t *testing.T) {
 			AddRow("updated_at", "datetime", "YES", nil, nil, nil, nil, "", "").
 			AddRow("deleted_at", "datetime", "YES", nil, nil, nil, nil, "", ""))
 
+	// 设置获取现有索引的预期，索引与模型一致
+	expectConstraints(mock, indexRows().
+		AddRow("idx_migration_test_model_changed_name", "name", false).
+		AddRow("uk_migration_test_model_changed_email", "email", true), nil)
+
 	// 设置修改表的预期
 	mock.ExpectExec("ALTER TABLE").
 		WillReturnResult(sqlmock.NewResult(0, 0))
@@ -269,6 +274,8 @@ func TestDifferentDialects(t *testing.T) {
 		name           string
 		dialectName    string
 		expectedCreate string
+		// PostgreSQL 和 SQLite 在建表之后单独创建索引
+		expectedIndexes []string
 	}{
 		{
 			name:           "mysql",
@@ -279,11 +286,19 @@ func TestDifferentDialects(t *testing.T) {
 			name:           "postgresql",
 			dialectName:    "postgresql",
 			expectedCreate: "CREATE TABLE \"migration_test_model\".*",
+			expectedIndexes: []string{
+				`CREATE INDEX "idx_migration_test_model_name" ON "migration_test_model" ("name")`,
+				`CREATE UNIQUE INDEX "uk_migration_test_model_email" ON "migration_test_model" ("email")`,
+			},
 		},
 		{
 			name:           "sqlite",
 			dialectName:    "sqlite",
 			expectedCreate: "CREATE TABLE \"migration_test_model\".*",
+			expectedIndexes: []string{
+				`CREATE INDEX "idx_migration_test_model_name" ON "migration_test_model" ("name")`,
+				`CREATE UNIQUE INDEX "uk_migration_test_model_email" ON "migration_test_model" ("email")`,
+			},
 		},
 	}
 
@@ -301,6 +316,10 @@ func TestDifferentDialects(t *testing.T) {
 			// 设置创建表的预期，使用正则匹配不同方言生成的SQL
 			mock.ExpectExec(tc.expectedCreate).
 				WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, stmt := range tc.expectedIndexes {
+				mock.ExpectExec(regexp.QuoteMeta(stmt)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
 
 			// 创建ORM实例
 			db, err := Open(mockDB, tc.dialectName)
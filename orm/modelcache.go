@@ -7,7 +7,8 @@ import (
 
 type modelCache struct {
 	sync.RWMutex
-	models map[reflect.Type]*model
+	models  map[reflect.Type]*model
+	dialect Dialect // 解析模型时设置的方言，之后不再修改缓存的模型
 }
 
 func NewModelCache() *modelCache {
@@ -36,6 +37,7 @@ func (m *modelCache) get(val any) (*model, error) {
 	if err != nil {
 		return nil, err
 	}
+	model.dialect = m.dialect
 	m.models[typ] = model
 	return model, nil
 }
@@ -0,0 +1,75 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
+)
+
+// FieldMeta 生成的字段元数据
+type FieldMeta struct {
+	Name   string // 字段名
+	Column string // 列名
+	Tag    string // orm 标签的原始内容
+}
+
+// ModelMeta 生成的模型元数据，由 predicate_gen 的 -meta 模式生成，不需要手写。
+// Addr 根据字段在 Fields 中的下标返回字段的地址，扫描结果时直接使用，不再通过反射和指针运算计算地址
+type ModelMeta[T any] struct {
+	Table  string
+	Fields []FieldMeta
+	Addr   func(t *T, i int) any
+}
+
+// modelMeta 去掉类型参数后的模型元数据
+type modelMeta struct {
+	table  string
+	fields []FieldMeta
+	addr   any // func(*T, int) any
+}
+
+var modelMetas sync.Map // map[reflect.Type]*modelMeta
+
+// RegisterModelMeta 注册生成的模型元数据，解析模型时优先使用已注册的元数据。
+// 生成的代码在 init 中调用，结构体修改后没有重新生成时会 panic
+func RegisterModelMeta[T any](meta ModelMeta[T]) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("orm: model meta of %s must be registered for a struct", typ))
+	}
+	if meta.Addr == nil {
+		panic(fmt.Sprintf("orm: model meta of %s has no Addr", typ))
+	}
+
+	exported := 0
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).IsExported() {
+			exported++
+		}
+	}
+	if exported != len(meta.Fields) {
+		panic(fmt.Sprintf("orm: model meta of %s is out of date, please regenerate it", typ))
+	}
+	for _, fm := range meta.Fields {
+		if _, ok := typ.FieldByName(fm.Name); !ok {
+			panic(ferr.ErrStaleModelMeta(typ, fm.Name).Error())
+		}
+	}
+
+	modelMetas.Store(typ, &modelMeta{
+		table:  meta.Table,
+		fields: meta.Fields,
+		addr:   meta.Addr,
+	})
+}
+
+// lookupModelMeta 查找结构体类型注册的元数据
+func lookupModelMeta(typ reflect.Type) (*modelMeta, bool) {
+	v, ok := modelMetas.Load(typ)
+	if !ok {
+		return nil, false
+	}
+	return v.(*modelMeta), true
+}
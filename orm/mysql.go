@@ -1,7 +1,6 @@
 package orm
 
 import (
-	"errors"
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 	"reflect"
 	"strconv"
@@ -12,10 +11,9 @@ type Mysql struct {
 	BaseDialect
 }
 
+// BuildUpsert 使用 ON DUPLICATE KEY UPDATE，任意主键或唯一键冲突时都会更新，
+// 忽略 conflictCols，同一段代码可以在不同方言之间切换
 func (m Mysql) BuildUpsert(builder *strings.Builder, conflictCols []*Column, cols []*Column) {
-	if conflictCols != nil {
-		panic(errors.New("mysql does not support conflict columns"))
-	}
 	m.buildUpsert(builder, cols)
 }
 
@@ -32,10 +30,12 @@ func (m Mysql) buildUpsert(builder *strings.Builder, cols []*Column) {
 		}
 
 		// 注入模型信息
-		col.model = m.model
+		if col.model == nil {
+			col.model = m.model
+		}
 		col.Build(builder)
 		builder.WriteString(" = VALUES(")
-		col.BuildWithoutQuote(builder)
+		builder.WriteString(m.Quote(upsertColumnName(col)))
 		builder.WriteByte(')')
 	}
 }
@@ -77,7 +77,7 @@ func (m Mysql) DateFormat(dateExpr string, format string) string {
 // CreateTableSQL 为MySQL生成建表语句
 func (m Mysql) CreateTableSQL(model *model) string {
 	// 先调用基本实现生成通用的SQL
-	baseSQL := m.BaseDialect.CreateTableSQL(model)
+	baseSQL := createTableSQL(&m, model, true)
 
 	// 添加MySQL特有的表选项
 	return baseSQL + " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;";
@@ -103,6 +103,13 @@ func (m Mysql) ColumnType(f *field) string {
 	if f.sqlType != "" {
 		return f.sqlType
 	}
+	if len(f.enum) > 0 {
+		return "ENUM(" + enumValues(f.enum, ",") + ")"
+	}
+	// 指针字段按指向的类型映射，NULL 由 nullable 决定
+	if f.typ.Kind() == reflect.Ptr {
+		return m.ColumnType(derefField(f))
+	}
 
 	// 根据Go类型映射MySQL类型
 	switch f.typ.Kind() {
@@ -137,6 +144,10 @@ func (m Mysql) ColumnType(f *field) string {
 			return "DECIMAL(" + strconv.Itoa(f.precision) + "," + strconv.Itoa(f.scale) + ")"
 		}
 		return "DOUBLE"
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.Uint8 {
+			return "BLOB"
+		}
 	case reflect.String:
 		if f.size > 0 {
 			if f.size > 16383 {
@@ -163,6 +174,12 @@ func (m Mysql) ColumnType(f *field) string {
 			return "TEXT"
 		case "sql.NullInt64":
 			return "BIGINT"
+		case "sql.NullInt32":
+			return "INT"
+		case "sql.NullInt16":
+			return "SMALLINT"
+		case "sql.NullByte":
+			return "TINYINT UNSIGNED"
 		case "sql.NullFloat64":
 			return "DOUBLE"
 		case "sql.NullBool":
@@ -181,4 +198,13 @@ func (m Mysql) ColumnType(f *field) string {
 func init() {
 	RegisterDialect("mysql", &Mysql{})
 	DisableCacheDebugLog()
-}
\ No newline at end of file
+}
+// SupportsReturning MySQL 不支持 RETURNING 子句，插入后通过 LastInsertId 回填自增ID
+func (m Mysql) SupportsReturning() bool {
+	return false
+}
+
+// FirstInsertID MySQL 批量插入时 LastInsertId 返回第一行的自增ID
+func (m Mysql) FirstInsertID(lastID int64, rows int) int64 {
+	return lastID
+}
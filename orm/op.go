@@ -21,6 +21,8 @@ var (
 	opNOT        = Op{Type: OpUnary, Keyword: "NOT"}
 	opISNULL     = Op{Type: OpUnary, Keyword: "IS NULL"}
 	opNOTNULL    = Op{Type: OpUnary, Keyword: "IS NOT NULL"}
+	opEXISTS     = Op{Type: OpUnary, Keyword: "EXISTS"}
+	opNOTEXISTS  = Op{Type: OpUnary, Keyword: "NOT EXISTS"}
 	opLTE        = Op{Type: OpBinary, Keyword: "<="}
 	opLT         = Op{Type: OpBinary, Keyword: "<"}
 	opGTE        = Op{Type: OpBinary, Keyword: ">="}
@@ -28,6 +30,10 @@ var (
 	opNOTLIKE    = Op{Type: OpBinary, Keyword: "NOT LIKE"}
 	opIN         = Op{Type: OpBinary, Keyword: "IN"}
 	opNOTIN      = Op{Type: OpBinary, Keyword: "NOT IN"}
+	opAND        = Op{Type: OpBinary, Keyword: "AND"}
+	opOR         = Op{Type: OpBinary, Keyword: "OR"}
 	opBETWEEN    = Op{Type: OpTernary, Keyword: "BETWEEN"}
 	opNOTBETWEEN = Op{Type: OpTernary, Keyword: "NOT BETWEEN"}
+	opContains   = Op{Type: OpBinary, Keyword: "@>"}
+	opOverlaps   = Op{Type: OpBinary, Keyword: "&&"}
 )
@@ -1,5 +1,11 @@
 package orm
 
+import (
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
+)
+
 // OrderBy 定义排序方向
 type OrderBy struct {
 	expr Expression
@@ -19,3 +25,25 @@ func Desc(expr Expression) OrderBy {
 		desc: true,
 	}
 }
+
+// writeOrderBy 写入 UPDATE 和 DELETE 的 ORDER BY 子句，只能按列或原生表达式排序
+func writeOrderBy(builder *strings.Builder, m *model, args *[]any, orders []OrderBy) {
+	builder.WriteString(" ORDER BY ")
+	for i, order := range orders {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		switch expr := order.expr.(type) {
+		case *Column:
+			expr.model = m
+			expr.Build(builder)
+		case RawExpr:
+			buildRaw(builder, m, expr, args)
+		default:
+			panic(ferr.ErrInvalidOrderBy(order.expr))
+		}
+		if order.desc {
+			builder.WriteString(" DESC")
+		}
+	}
+}
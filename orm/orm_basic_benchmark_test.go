@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -22,6 +23,16 @@ type BenchmarkUser struct {
 
 var benchDB *DB
 
+// benchmarkDSN 返回基准测试的 DSN，ORM_BENCH_DSN 设置服务器地址和账号，
+// 例如 user:password@tcp(127.0.0.1:3306)/，默认为本地无密码的 root
+func benchmarkDSN(database string) string {
+	base := os.Getenv("ORM_BENCH_DSN")
+	if base == "" {
+		base = "root:@tcp(127.0.0.1:3306)/"
+	}
+	return base + database
+}
+
 func TestMain(m *testing.M) {
 	DisableCacheDebugLog()
 
@@ -32,7 +43,7 @@ func TestMain(m *testing.M) {
 
 func setupBenchmarkDB() {
 	var err error
-	sqlDB, err := sql.Open("mysql", "root:@tcp(127.0.0.1:3306)/")
+	sqlDB, err := sql.Open("mysql", benchmarkDSN(""))
 	if err != nil {
 		panic(err)
 	}
@@ -54,7 +65,7 @@ func setupBenchmarkDB() {
 
 	sqlDB.Close()
 
-	sqlDB, err = sql.Open("mysql", "root:@tcp(127.0.0.1:3306)/orm_benchmark?parseTime=true")
+	sqlDB, err = sql.Open("mysql", benchmarkDSN("orm_benchmark?parseTime=true"))
 	if err != nil {
 		panic(err)
 	}
@@ -141,12 +152,10 @@ func BenchmarkBatchInsert(b *testing.B) {
 			}
 		}
 
-		for _, user := range users {
-			inserter := RegisterInserter[BenchmarkUser](benchDB)
-			_, err := inserter.Insert(nil, user).Exec(ctx)
-			if err != nil {
-				b.Fatalf("Batch insert failed: %v", err)
-			}
+		// 逐行插入，所有行复用同一个预编译语句
+		_, err := RegisterInserter[BenchmarkUser](benchDB).Insert(nil, users...).PerRow().Exec(ctx)
+		if err != nil {
+			b.Fatalf("Batch insert failed: %v", err)
 		}
 	}
 }
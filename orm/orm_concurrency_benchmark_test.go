@@ -16,7 +16,7 @@ var concurrentBenchDB *DB
 
 func setupConcurrentBenchDB() {
 	var err error
-	sqlDB, err := sql.Open("mysql", "root:@tcp(127.0.0.1:3306)/")
+	sqlDB, err := sql.Open("mysql", benchmarkDSN(""))
 	if err != nil {
 		panic(err)
 	}
@@ -43,7 +43,7 @@ func setupConcurrentBenchDB() {
 
 	sqlDB.Close()
 
-	sqlDB, err = sql.Open("mysql", "root:@tcp(127.0.0.1:3306)/orm_benchmark?parseTime=true")
+	sqlDB, err = sql.Open("mysql", benchmarkDSN("orm_benchmark?parseTime=true"))
 	if err != nil {
 		panic(err)
 	}
@@ -56,7 +56,8 @@ func setupConcurrentBenchDB() {
 
 	concurrentBenchDB, err = Open(sqlDB, "mysql",
 		WithPoolSize(20, 50),
-		WithPoolTimeouts(time.Minute, time.Minute*3))
+		WithPoolTimeouts(time.Minute, time.Minute*3),
+		WithStmtCache(128))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,198 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPage 页码或每页数量不是正数
+var ErrInvalidPage = errors.New("orm: page and perPage must be positive")
+
+// pageTotalColumn 单次查询分页时总数所在的列
+const pageTotalColumn = "orm_total"
+
+// Page 分页查询的结果，可以直接作为JSON响应返回
+type Page[T any] struct {
+	Items      []*T  `json:"items"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPage 根据当前页的数据和总数创建分页结果，Items 为空时返回空切片而不是nil，JSON中为 []
+func NewPage[T any](items []*T, total int64, page, perPage int) *Page[T] {
+	if items == nil {
+		items = []*T{}
+	}
+	var totalPages int
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+	return &Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}
+}
+
+// HasNext 是否还有下一页
+func (p *Page[T]) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+type paginateConfig struct {
+	windowCount bool
+}
+
+// PaginateOption 分页查询的配置选项
+type PaginateOption func(*paginateConfig)
+
+// WithWindowCount 使用 COUNT(*) OVER() 在查询当前页时一并得到总数，只需要一次查询
+// 需要数据库支持窗口函数：MySQL 8.0+、PostgreSQL 和 SQLite 3.25+；
+// 页码超出范围时查询没有返回行，会再执行一次 COUNT 查询获取总数
+func WithWindowCount() PaginateOption {
+	return func(c *paginateConfig) {
+		c.windowCount = true
+	}
+}
+
+// Paginate 查询第 page 页（从1开始）的数据，每页 perPage 行，同时返回满足条件的总行数
+// 默认先执行 SELECT COUNT(*) 包裹的查询得到总数，再追加 LIMIT 和 OFFSET 查询当前页；
+// 当前页的顺序由 OrderBy 决定，调用前不要再设置 Limit 和 Offset
+//
+//	users, total, err := RegisterSelector[User](db).Select().
+//		Where(Col("Age").Gt(18)).
+//		OrderBy(Desc(Col("ID"))).
+//		Paginate(ctx, 2, 20)
+func (s *Selector[T]) Paginate(ctx context.Context, page, perPage int, opts ...PaginateOption) ([]*T, int64, error) {
+	if page < 1 || perPage < 1 {
+		return nil, 0, ErrInvalidPage
+	}
+	config := &paginateConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	q, err := s.Build()
+	if err != nil {
+		return nil, 0, err
+	}
+	base := strings.TrimSuffix(q.SQL, ";")
+	offset := (page - 1) * perPage
+	window := " LIMIT " + strconv.Itoa(perPage) + " OFFSET " + strconv.Itoa(offset)
+
+	if config.windowCount && s.selectEnd > 0 {
+		items, total, err := s.pageWithWindowCount(ctx, base, window, q.Args)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(items) > 0 {
+			s.applyMasks(ctx, items...)
+			return items, total, nil
+		}
+	}
+
+	total, err := s.count(ctx, base, q.Args)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 页码超出范围时不需要再查询当前页
+	if int64(offset) >= total {
+		return nil, total, nil
+	}
+
+	items, err := s.execGetMulti(ctx, &Query{SQL: base + window + ";", Args: q.Args})
+	if err != nil {
+		return nil, 0, err
+	}
+	s.applyMasks(ctx, items...)
+	return items, total, nil
+}
+
+// GetPage 与 Paginate 相同，结果封装为 Page
+func (s *Selector[T]) GetPage(ctx context.Context, page, perPage int, opts ...PaginateOption) (*Page[T], error) {
+	items, total, err := s.Paginate(ctx, page, perPage, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewPage(items, total, page, perPage), nil
+}
+
+// Count 返回满足查询条件的行数，查询包裹在 SELECT COUNT(*) 中执行，GROUP BY 查询返回的是分组数
+//
+//	n, err := RegisterSelector[User](db).Select().Where(Col("Status").Eq(1)).Count(ctx)
+func (s *Selector[T]) Count(ctx context.Context) (int64, error) {
+	q, err := s.Build()
+	if err != nil {
+		return 0, err
+	}
+	return s.count(ctx, strings.TrimSuffix(q.SQL, ";"), q.Args)
+}
+
+// count 将查询作为子查询统计总行数，GROUP BY 查询统计的是分组数
+func (s *Selector[T]) count(ctx context.Context, base string, args []any) (int64, error) {
+	q := &Query{
+		SQL:  "SELECT COUNT(*) FROM (" + base + ") AS " + s.dialect.Quote("orm_page") + ";",
+		Args: args,
+	}
+	res, err := s.layer.HandleQuery(ctx, &QueryContext{
+		QueryType: "query",
+		Query:     q,
+		Model:     s.model,
+		Builder:   s,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer res.Rows.Close()
+
+	var total int64
+	if res.Rows.Next() {
+		if err = res.Rows.Scan(&total); err != nil {
+			return 0, err
+		}
+	}
+	return total, res.Rows.Err()
+}
+
+// pageWithWindowCount 在 SELECT 列表后追加 COUNT(*) OVER()，查询当前页的同时得到总数
+func (s *Selector[T]) pageWithWindowCount(ctx context.Context, base, window string, args []any) ([]*T, int64, error) {
+	// selectEnd 之前是 "SELECT 列 "，去掉末尾的空格后追加窗口函数
+	sql := base[:s.selectEnd-1] + ", COUNT(*) OVER() AS " + s.dialect.Quote(pageTotalColumn) + " " +
+		base[s.selectEnd:] + window + ";"
+	res, err := s.layer.HandleQuery(ctx, &QueryContext{
+		QueryType: "query",
+		Query:     &Query{SQL: sql, Args: args},
+		Model:     s.model,
+		Builder:   s,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Rows.Close()
+
+	var (
+		items []*T
+		total int64
+	)
+	extras := map[string]any{pageTotalColumn: &total}
+	for res.Rows.Next() {
+		t, err := s.scanRowWith(res.Rows, extras)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, t)
+	}
+	if err = res.Rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if err = runRowHooks(ctx, s.layer.getDB(), AfterFindEvent, s.model.table, items); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector_Paginate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		dialect   string
+		page      int
+		opts      []PaginateOption
+		expect    func(mock sqlmock.Sqlmock)
+		wantIDs   []int
+		wantTotal int64
+		wantErr   error
+	}{
+		{
+			name:    "count and page query",
+			dialect: "mysql",
+			page:    2,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(*) FROM (SELECT * FROM `test_model` WHERE `id` > ? ORDER BY `id`) AS `orm_page`;").
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(5))
+				mock.ExpectQuery("SELECT * FROM `test_model` WHERE `id` > ? ORDER BY `id` LIMIT 2 OFFSET 2;").
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(3, "Tom", nil).AddRow(4, "Jerry", nil))
+			},
+			wantIDs:   []int{3, 4},
+			wantTotal: 5,
+		},
+		{
+			name:    "page out of range",
+			dialect: "mysql",
+			page:    4,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(*) FROM (SELECT * FROM `test_model` WHERE `id` > ? ORDER BY `id`) AS `orm_page`;").
+					WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(5))
+			},
+			wantTotal: 5,
+		},
+		{
+			name:    "window count in one query",
+			dialect: "postgresql",
+			page:    1,
+			opts:    []PaginateOption{WithWindowCount()},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT *, COUNT(*) OVER() AS "orm_total" FROM "test_model" WHERE "id" > $1 ORDER BY "id" LIMIT 2 OFFSET 0;`).
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job", "orm_total"}).
+						AddRow(1, "Tom", nil, 3).AddRow(2, "Jerry", nil, 3))
+			},
+			wantIDs:   []int{1, 2},
+			wantTotal: 3,
+		},
+		{
+			name:    "window count falls back to count",
+			dialect: "postgresql",
+			page:    3,
+			opts:    []PaginateOption{WithWindowCount()},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT *, COUNT(*) OVER() AS "orm_total" FROM "test_model" WHERE "id" > $1 ORDER BY "id" LIMIT 2 OFFSET 4;`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job", "orm_total"}))
+				mock.ExpectQuery(`SELECT COUNT(*) FROM (SELECT * FROM "test_model" WHERE "id" > $1 ORDER BY "id") AS "orm_page";`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+			},
+			wantTotal: 3,
+		},
+		{
+			name:    "invalid page",
+			dialect: "mysql",
+			page:    0,
+			expect:  func(mock sqlmock.Sqlmock) {},
+			wantErr: ErrInvalidPage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mockDB.Close()
+			db, err := Open(mockDB, tc.dialect)
+			require.NoError(t, err)
+			tc.expect(mock)
+
+			items, total, err := RegisterSelector[TestModel](db).Select().
+				Where(Col("ID").Gt(0)).
+				OrderBy(Asc(Col("ID"))).
+				Paginate(context.Background(), tc.page, 2, tc.opts...)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+
+			var ids []int
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+			assert.Equal(t, tc.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSelector_Count(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM (SELECT * FROM `test_model` WHERE `name` LIKE ?) AS `orm_page`;").
+		WithArgs("T%").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+
+	n, err := RegisterSelector[TestModel](db).Select().Where(Col("Name").Like("T%")).Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewPage(t *testing.T) {
+	page := NewPage[TestModel](nil, 5, 3, 2)
+	assert.Equal(t, 3, page.TotalPages)
+	assert.False(t, page.HasNext())
+
+	data, err := json.Marshal(page)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":[],"total":5,"page":3,"per_page":2,"total_pages":3}`, string(data))
+
+	assert.True(t, NewPage([]*TestModel{{ID: 1}}, 5, 1, 2).HasNext())
+}
@@ -1,8 +1,6 @@
 package orm
 
 import (
-	"errors"
-	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,33 +10,9 @@ type Postgresql struct {
 	BaseDialect
 }
 
+// BuildUpsert 使用 ON CONFLICT ... DO UPDATE，没有指定冲突列时使用主键
 func (p Postgresql) BuildUpsert(builder *strings.Builder, conflictCols []*Column, cols []*Column) {
-	if conflictCols == nil || len(conflictCols) == 0 {
-		panic(errors.New("postgresql must have conflict columns"))
-	}
-	if len(cols) == 0 {
-		panic(ferr.ErrUpsertRowNotFound)
-	}
-
-	builder.WriteString(" ON CONFLICT(")
-	for index, col := range cols {
-		col.model = p.model
-		col.Build(builder)
-		if index != len(cols)-1 {
-			builder.WriteString(", ")
-		}
-	}
-
-	builder.WriteString(") DO UPDATE SET ")
-
-	for index, col := range cols {
-		col.BuildWithoutQuote(builder)
-		builder.WriteString(" = EXCLUDED.")
-		col.BuildWithoutQuote(builder)
-		if index != len(cols)-1 {
-			builder.WriteString(", ")
-		}
-	}
+	buildOnConflict(builder, p.Quote, "postgresql", conflictCols, cols)
 }
 
 // Quote PostgreSQL使用双引号作为标识符引用符
@@ -76,10 +50,18 @@ func (p Postgresql) DateFormat(dateExpr string, format string) string {
 // CreateTableSQL 为PostgreSQL生成建表语句
 func (p Postgresql) CreateTableSQL(m *model) string {
 	// 先调用基本实现生成通用的SQL
-	baseSQL := p.BaseDialect.CreateTableSQL(m)
-	newSQL := strings.ReplaceAll(baseSQL, "`", "\"")
-	// PostgreSQL没有特殊的表选项，所以直接返回基础SQL
-	return newSQL + ";"
+	baseSQL := createTableSQL(&p, m, false)
+	// 枚举字段使用的类型在建表之前创建
+	var enums strings.Builder
+	seen := make(map[string]bool)
+	for _, f := range m.fieldsMap {
+		if len(f.enum) > 0 && f.sqlType == "" && !seen[f.enumType] {
+			seen[f.enumType] = true
+			enums.WriteString(createEnumSQL(f.enumType, f.enum) + ";\n")
+		}
+	}
+	// PostgreSQL没有特殊的表选项，不支持在建表语句中定义索引，索引在建表之后创建
+	return enums.String() + appendIndexSQL(p.Quote, baseSQL, m)
 }
 
 // AlterTableSQL 实现PostgreSQL特定的表结构修改语句
@@ -159,6 +141,14 @@ func (p Postgresql) ColumnType(f *field) string {
 	if f.sqlType != "" {
 		return f.sqlType
 	}
+	// 枚举字段使用建表时创建的枚举类型
+	if len(f.enum) > 0 {
+		return f.enumType
+	}
+	// 指针字段按指向的类型映射，NULL 由 nullable 决定
+	if f.typ.Kind() == reflect.Ptr {
+		return p.ColumnType(derefField(f))
+	}
 
 	// 根据Go类型映射PostgreSQL类型
 	switch f.typ.Kind() {
@@ -179,13 +169,14 @@ func (p Postgresql) ColumnType(f *field) string {
 		}
 		return "BIGINT"
 	case reflect.Uint, reflect.Uint32:
-		return "INTEGER"
+		// PostgreSQL 没有无符号整数，使用更大的类型容纳全部取值
+		return "BIGINT"
 	case reflect.Uint8:
 		return "SMALLINT"
 	case reflect.Uint16:
 		return "INTEGER"
 	case reflect.Uint64:
-		return "BIGINT"
+		return "NUMERIC(20,0)"
 	case reflect.Float32:
 		return "REAL"
 	case reflect.Float64:
@@ -193,6 +184,17 @@ func (p Postgresql) ColumnType(f *field) string {
 			return "NUMERIC(" + strconv.Itoa(f.precision) + "," + strconv.Itoa(f.scale) + ")"
 		}
 		return "DOUBLE PRECISION"
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.Uint8 {
+			return "BYTEA"
+		}
+		// 基础类型的切片映射为数组，如 []string 映射为 TEXT[]
+		if isArrayElem(f.typ.Elem()) {
+			elem := *f
+			elem.typ = f.typ.Elem()
+			elem.autoIncr = false
+			return p.ColumnType(&elem) + "[]"
+		}
 	case reflect.String:
 		if f.size > 0 {
 			return "VARCHAR(" + strconv.Itoa(f.size) + ")"
@@ -213,6 +215,10 @@ func (p Postgresql) ColumnType(f *field) string {
 			return "TEXT"
 		case "sql.NullInt64":
 			return "BIGINT"
+		case "sql.NullInt32":
+			return "INTEGER"
+		case "sql.NullInt16", "sql.NullByte":
+			return "SMALLINT"
 		case "sql.NullFloat64":
 			return "DOUBLE PRECISION"
 		case "sql.NullBool":
@@ -240,4 +246,13 @@ func (p Postgresql) JsonExtractText(jsonExpr string, path string) string {
 
 func init() {
 	RegisterDialect("postgresql", &Postgresql{})
-}
\ No newline at end of file
+}
+// SupportsReturning PostgreSQL 支持 RETURNING 子句
+func (p Postgresql) SupportsReturning() bool {
+	return true
+}
+
+// FirstInsertID PostgreSQL 通过 RETURNING 获取自增ID，不使用 LastInsertId
+func (p Postgresql) FirstInsertID(lastID int64, rows int) int64 {
+	return lastID
+}
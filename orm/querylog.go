@@ -0,0 +1,149 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// QueryLogEntry 一次SQL执行的日志内容
+type QueryLogEntry struct {
+	SQL      string
+	Args     []any // 经过 WithQueryArgsMask 处理后的参数
+	Duration time.Duration
+	Rows     int64 // 写操作影响的行数，查询在读取结果之前无法得知行数，为 -1
+	Err      error
+	Slow     bool // 耗时达到慢查询阈值
+}
+
+// QueryLogger SQL日志记录器
+type QueryLogger interface {
+	LogQuery(ctx context.Context, entry *QueryLogEntry)
+}
+
+// QueryLoggerFunc 用于将函数转换为 QueryLogger 接口
+type QueryLoggerFunc func(ctx context.Context, entry *QueryLogEntry)
+
+func (f QueryLoggerFunc) LogQuery(ctx context.Context, entry *QueryLogEntry) {
+	f(ctx, entry)
+}
+
+// queryLog SQL日志配置
+type queryLog struct {
+	logger        QueryLogger
+	slowThreshold time.Duration
+	slowOnly      bool
+	mask          MaskFunc
+}
+
+// QueryLogOption SQL日志配置项
+type QueryLogOption func(*queryLog)
+
+// WithSlowQueryThreshold 设置慢查询阈值，耗时达到阈值的SQL标记为慢查询，为0时不区分慢查询
+func WithSlowQueryThreshold(threshold time.Duration) QueryLogOption {
+	return func(l *queryLog) {
+		l.slowThreshold = threshold
+	}
+}
+
+// WithSlowQueryOnly 只记录慢查询和执行失败的SQL
+func WithSlowQueryOnly() QueryLogOption {
+	return func(l *queryLog) {
+		l.slowOnly = true
+	}
+}
+
+// WithQueryArgsMask 记录日志前对每个参数脱敏，例如 WithQueryArgsMask(MaskRedact())
+func WithQueryArgsMask(fn MaskFunc) QueryLogOption {
+	return func(l *queryLog) {
+		l.mask = fn
+	}
+}
+
+// WithQueryLogger 记录执行的每条SQL，包括参数、耗时、影响的行数和错误。
+// 事务中执行的SQL同样会被记录，命中缓存的查询没有访问数据库，不会记录
+//
+//	db, err := orm.Open(sqlDB, "mysql", orm.WithQueryLogger(
+//		orm.NewQueryLogger(logger.NewLogger()),
+//		orm.WithSlowQueryThreshold(200*time.Millisecond),
+//		orm.WithQueryArgsMask(orm.MaskRedact()),
+//	))
+func WithQueryLogger(l QueryLogger, opts ...QueryLogOption) DBOption {
+	return func(db *DB) error {
+		ql := &queryLog{logger: l}
+		for _, opt := range opts {
+			opt(ql)
+		}
+		db.queryLog = ql
+		return nil
+	}
+}
+
+// logQuery 记录一次SQL执行，res 为nil表示查询
+func (db *DB) logQuery(ctx context.Context, query string, args []any, start time.Time, res sql.Result, err error) {
+	ql := db.queryLog
+	if ql == nil {
+		return
+	}
+
+	duration := time.Since(start)
+	slow := ql.slowThreshold > 0 && duration >= ql.slowThreshold
+	if ql.slowOnly && !slow && err == nil {
+		return
+	}
+
+	if ql.mask != nil && len(args) > 0 {
+		masked := make([]any, len(args))
+		for i, arg := range args {
+			masked[i] = ql.mask(arg)
+		}
+		args = masked
+	}
+
+	rows := int64(-1)
+	if res != nil && err == nil {
+		if n, e := res.RowsAffected(); e == nil {
+			rows = n
+		}
+	}
+
+	ql.logger.LogQuery(ctx, &QueryLogEntry{
+		SQL:      query,
+		Args:     args,
+		Duration: duration,
+		Rows:     rows,
+		Err:      err,
+		Slow:     slow,
+	})
+}
+
+// webLogger 将SQL日志输出到 web/logger
+type webLogger struct {
+	l logger.Logger
+}
+
+// NewQueryLogger 将SQL日志输出到 web/logger，正常的SQL为 Debug 级别，慢查询为 Warn 级别，执行失败为 Error 级别
+func NewQueryLogger(l logger.Logger) QueryLogger {
+	return &webLogger{l: l}
+}
+
+func (w *webLogger) LogQuery(ctx context.Context, entry *QueryLogEntry) {
+	fields := []logger.Field{
+		logger.String("sql", entry.SQL),
+		logger.Interface("args", entry.Args),
+		logger.String("duration", entry.Duration.String()),
+		logger.Int64("rows", entry.Rows),
+	}
+
+	l := w.l.WithContext(ctx)
+	switch {
+	case entry.Err != nil:
+		l.Error("sql failed", append(fields, logger.FieldError(entry.Err))...)
+	case entry.Slow:
+		l.Warn("slow sql", fields...)
+	default:
+		l.Debug("sql", fields...)
+	}
+}
@@ -0,0 +1,125 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_QueryLogger(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	var entries []*QueryLogEntry
+	db, err := Open(mockDB, "mysql", WithQueryLogger(
+		QueryLoggerFunc(func(ctx context.Context, entry *QueryLogEntry) {
+			entries = append(entries, entry)
+		}),
+		WithQueryArgsMask(MaskRedact()),
+	))
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT * FROM `test_model` WHERE `name` = ?;").
+		WithArgs("Tom").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom"))
+	_, err = RegisterSelector[TestModel](db).Select().Where(Col("Name").Eq("Tom")).Get(context.Background())
+	require.NoError(t, err)
+
+	mock.ExpectExec("DELETE FROM `test_model` WHERE `id` > ?;").
+		WithArgs(18).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	_, err = RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Gt(18)).Exec(context.Background())
+	require.NoError(t, err)
+
+	queryErr := errors.New("connection reset")
+	mock.ExpectExec("DELETE FROM `test_model`;").WillReturnError(queryErr)
+	_, _ = RegisterDeleter[TestModel](db).Delete().Exec(context.Background())
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, "SELECT * FROM `test_model` WHERE `name` = ?;", entries[0].SQL)
+	assert.Equal(t, []any{nil}, entries[0].Args)
+	assert.Equal(t, int64(-1), entries[0].Rows)
+	assert.NoError(t, entries[0].Err)
+	assert.False(t, entries[0].Slow)
+
+	assert.Equal(t, int64(3), entries[1].Rows)
+	assert.Equal(t, []any{nil}, entries[1].Args)
+
+	assert.Equal(t, queryErr, entries[2].Err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDB_QueryLogger_SlowOnly(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	var entries []*QueryLogEntry
+	db, err := Open(mockDB, "mysql", WithQueryLogger(
+		QueryLoggerFunc(func(ctx context.Context, entry *QueryLogEntry) {
+			entries = append(entries, entry)
+		}),
+		WithSlowQueryThreshold(20*time.Millisecond),
+		WithSlowQueryOnly(),
+	))
+	require.NoError(t, err)
+
+	mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE").WillDelayFor(30 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for i := 0; i < 2; i++ {
+		_, err = RegisterDeleter[TestModel](db).Delete().Exec(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].Slow)
+	assert.GreaterOrEqual(t, entries[0].Duration, 20*time.Millisecond)
+}
+
+func TestDB_QueryLogger_Tx(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	var sqls []string
+	db, err := Open(mockDB, "mysql", WithQueryLogger(
+		QueryLoggerFunc(func(ctx context.Context, entry *QueryLogEntry) {
+			sqls = append(sqls, entry.SQL)
+		}),
+	))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = db.Tx(context.Background(), func(tx *Tx) error {
+		_, err := RegisterDeleter[TestModel](tx).Delete().Exec(context.Background())
+		return err
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DELETE FROM `test_model`;"}, sqls)
+}
+
+func TestNewQueryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(logger.NewLogger(logger.WithOutput(&buf), logger.WithLevel(logger.DebugLevel)))
+
+	l.LogQuery(context.Background(), &QueryLogEntry{SQL: "SELECT 1;", Rows: -1, Duration: time.Second, Slow: true})
+	assert.Contains(t, buf.String(), "slow sql")
+	assert.Contains(t, buf.String(), "SELECT 1;")
+
+	buf.Reset()
+	l.LogQuery(context.Background(), &QueryLogEntry{SQL: "SELECT 1;", Err: errors.New("boom")})
+	assert.Contains(t, buf.String(), "sql failed")
+	assert.Contains(t, buf.String(), "boom")
+}
@@ -15,9 +15,38 @@ func (r RawExpr) Build(builder *strings.Builder) {
 	builder.WriteString(r.raw)
 }
 
+// Raw 原生 SQL 片段，其中的 ? 为参数占位符，按方言转换为 $1 这样的形式；
+// 字符串字面量和引用的标识符中的 ? 保持不变，需要问号运算符（如 PostgreSQL 的 jsonb ?）时写作 ??
 func Raw(raw string, args ...any) RawExpr {
 	return RawExpr{
 		raw:  raw,
 		args: args,
 	}
 }
+
+// buildRaw 写入原生 SQL 片段，把其中的 ? 转换为方言的占位符并收集参数
+func buildRaw(builder *strings.Builder, m *model, r RawExpr, args *[]any) {
+	var quote byte
+	for i := 0; i < len(r.raw); i++ {
+		c := r.raw[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if i+1 < len(r.raw) && r.raw[i+1] == '?' {
+				builder.WriteByte('?')
+				i++
+				continue
+			}
+			builder.WriteString(m.dialect.Placeholder(m.index))
+			m.index++
+			continue
+		}
+		builder.WriteByte(c)
+	}
+	*args = append(*args, r.args...)
+}
@@ -0,0 +1,271 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// CacheCodec 缓存值的序列化方式
+type CacheCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 内置的序列化方式
+var (
+	JSONCodec    CacheCodec = jsonCodec{}
+	MsgpackCodec CacheCodec = msgpackCodec{}
+	GobCodec     CacheCodec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// gobCodec 使用 gob 序列化，值中包含接口类型时需要先通过 gob.Register 注册具体类型
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// deleteByTagsScript 原子地删除标签关联的所有键和标签本身，避免删除期间新写入的键丢失标签关联
+var deleteByTagsScript = redis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	local members = redis.call('SMEMBERS', tag)
+	for i = 1, #members, 500 do
+		redis.call('DEL', unpack(members, i, math.min(i + 499, #members)))
+	end
+	redis.call('DEL', tag)
+end
+return 0
+`)
+
+// RedisCache 基于 Redis 的缓存实现，多个应用实例可以共享缓存和失效通知。
+// 标签使用 Redis 集合保存关联的键，使用 Redis Cluster 时前缀需要包含哈希标签，
+// 例如 "{orm}:cache:"，保证标签和键在同一个槽中
+type RedisCache struct {
+	client    redis.UniversalClient
+	prefix    string
+	codec     CacheCodec
+	scanCount int64
+	owned     bool // client 由 RedisCache 创建，Close 时需要关闭
+}
+
+// RedisCacheOption RedisCache 配置项
+type RedisCacheOption func(*RedisCache)
+
+// WithRedisCachePrefix 设置键的前缀，默认为 "orm:cache:"
+func WithRedisCachePrefix(prefix string) RedisCacheOption {
+	return func(c *RedisCache) {
+		c.prefix = prefix
+	}
+}
+
+// WithRedisCacheCodec 设置序列化方式，默认为 JSONCodec
+func WithRedisCacheCodec(codec CacheCodec) RedisCacheOption {
+	return func(c *RedisCache) {
+		c.codec = codec
+	}
+}
+
+// WithRedisCacheScanCount 设置 Clear 和按前缀删除时每次 SCAN 的数量，默认为1000
+func WithRedisCacheScanCount(count int64) RedisCacheOption {
+	return func(c *RedisCache) {
+		c.scanCount = count
+	}
+}
+
+// NewRedisCache 使用已有的 Redis 客户端创建缓存，client 可以是单机、哨兵或者集群客户端
+func NewRedisCache(client redis.UniversalClient, opts ...RedisCacheOption) *RedisCache {
+	c := &RedisCache{
+		client:    client,
+		prefix:    "orm:cache:",
+		codec:     JSONCodec,
+		scanCount: 1000,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// OpenRedisCache 根据连接配置创建 Redis 客户端和缓存，创建后会检查连接是否可用
+//
+//	cache, err := orm.OpenRedisCache(ctx, &redis.UniversalOptions{
+//		Addrs:    []string{"localhost:6379"},
+//		PoolSize: 20,
+//	}, orm.WithRedisCacheCodec(orm.MsgpackCodec))
+func OpenRedisCache(ctx context.Context, options *redis.UniversalOptions, opts ...RedisCacheOption) (*RedisCache, error) {
+	client := redis.NewUniversalClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	c := NewRedisCache(client, opts...)
+	c.owned = true
+	return c, nil
+}
+
+func (c *RedisCache) key(key string) string {
+	return c.prefix + key
+}
+
+func (c *RedisCache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
+
+// Get 从缓存获取值
+func (c *RedisCache) Get(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return ErrCacheKeyEmpty
+	}
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(data, value)
+}
+
+// Set 设置缓存值，ttl 为0时永不过期
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags 设置缓存值，并关联标签
+func (c *RedisCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if key == "" {
+		return ErrCacheKeyEmpty
+	}
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return c.client.Set(ctx, c.key(key), data, ttl).Err()
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.key(key), data, ttl)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, c.tagKey(tag), c.key(key))
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete 删除缓存值
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrCacheKeyEmpty
+	}
+	return c.client.Del(ctx, c.key(key)).Err()
+}
+
+// DeleteByTags 通过标签批量删除缓存
+func (c *RedisCache) DeleteByTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = c.tagKey(tag)
+	}
+	return deleteByTagsScript.Run(ctx, c.client, keys).Err()
+}
+
+// DeleteByPrefix 删除以 prefix 开头的所有缓存，CacheManager 在模型没有标签时使用它失效模型的缓存
+func (c *RedisCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	return c.deleteMatch(ctx, escapePattern(c.key(prefix))+"*")
+}
+
+// Clear 清空当前前缀下的所有缓存，不影响 Redis 中的其他数据
+func (c *RedisCache) Clear(ctx context.Context) error {
+	return c.deleteMatch(ctx, escapePattern(c.prefix)+"*")
+}
+
+// deleteMatch 使用 SCAN 查找并删除匹配的键，集群模式下遍历所有主节点
+func (c *RedisCache) deleteMatch(ctx context.Context, pattern string) error {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scanDelete(ctx, client, pattern, c.scanCount)
+		})
+	}
+	return scanDelete(ctx, c.client, pattern, c.scanCount)
+}
+
+func scanDelete(ctx context.Context, client redis.Cmdable, pattern string, count int64) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err = client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// escapePattern 转义 SCAN MATCH 中的通配符
+func escapePattern(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Close 关闭由 OpenRedisCache 创建的客户端，通过 NewRedisCache 传入的客户端由调用方关闭
+func (c *RedisCache) Close() error {
+	if c.owned {
+		return c.client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheCodec(t *testing.T) {
+	type cached struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	want := []cached{{ID: 1, Name: "Tom", Tags: []string{"a"}}, {ID: 2, Name: "Jerry"}}
+
+	for name, codec := range map[string]CacheCodec{
+		"json":    JSONCodec,
+		"msgpack": MsgpackCodec,
+		"gob":     GobCodec,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(want)
+			require.NoError(t, err)
+
+			var got []cached
+			require.NoError(t, codec.Unmarshal(data, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	assert.Equal(t, `orm:user:\*\?\[a\]\\`, escapePattern(`orm:user:*?[a]\`))
+}
+
+// newTestRedisCache 连接本地的 Redis，不可用时跳过测试
+func newTestRedisCache(t *testing.T, opts ...RedisCacheOption) *RedisCache {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	opts = append([]RedisCacheOption{WithRedisCachePrefix("orm_test:" + t.Name() + ":")}, opts...)
+	c, err := OpenRedisCache(ctx, &redis.UniversalOptions{Addrs: []string{"localhost:6379"}}, opts...)
+	if err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = c.Clear(context.Background())
+		_ = c.Close()
+	})
+	return c
+}
+
+func TestRedisCache(t *testing.T) {
+	c := newTestRedisCache(t, WithRedisCacheCodec(MsgpackCodec))
+	ctx := context.Background()
+
+	var result string
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "test_key", &result))
+
+	require.NoError(t, c.Set(ctx, "test_key", "test_value", 5*time.Second))
+	require.NoError(t, c.Get(ctx, "test_key", &result))
+	assert.Equal(t, "test_value", result)
+
+	require.NoError(t, c.SetWithTags(ctx, "tagged_key", "tagged_value", 5*time.Second, "tag1", "tag2"))
+	require.NoError(t, c.DeleteByTags(ctx, "tag1"))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "tagged_key", &result))
+
+	require.NoError(t, c.Set(ctx, "user:1", "a", 0))
+	require.NoError(t, c.Set(ctx, "user:2", "b", 0))
+	require.NoError(t, c.Set(ctx, "order:1", "c", 0))
+	require.NoError(t, c.DeleteByPrefix(ctx, "user:"))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "user:1", &result))
+	require.NoError(t, c.Get(ctx, "order:1", &result))
+
+	require.NoError(t, c.Delete(ctx, "order:1"))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "order:1", &result))
+
+	require.NoError(t, c.Clear(ctx))
+	assert.Equal(t, ErrCacheMiss, c.Get(ctx, "test_key", &result))
+	assert.Equal(t, ErrCacheKeyEmpty, c.Set(ctx, "", "x", 0))
+}
@@ -0,0 +1,280 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrCircuitOpen 熔断器打开时直接返回，不访问数据库
+var ErrCircuitOpen = errors.New("orm: circuit breaker is open")
+
+// IsTransientError 判断错误是否是可以重试的临时错误：死锁、锁等待超时、序列化失败、
+// 语句超时、连接断开和网络超时。context 被取消或超时的错误不是临时错误
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// MySQL：1213 死锁，1205 锁等待超时，3024 语句执行超时
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1213, 1205, 3024:
+			return true
+		}
+		return false
+	}
+
+	// PostgreSQL：lib/pq 和 pgx 的错误都提供 SQLState
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01", "55P03", "57014":
+			// 序列化失败、死锁、获取锁失败、语句超时
+			return true
+		}
+		return false
+	}
+
+	// SQLite：数据库被锁定
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
+// matchQueryType 判断查询类型是否在配置的类型中，没有配置时对所有类型生效
+func matchQueryType(types []string, queryType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == queryType {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	MaxAttempts int                  // 最多执行的次数，包括第一次执行，默认3
+	BaseDelay   time.Duration        // 第一次重试前的等待时间，之后每次翻倍，默认10ms
+	MaxDelay    time.Duration        // 等待时间的上限，默认1s
+	QueryTypes  []string             // 生效的查询类型，例如 "query"、"exec"，为空时只重试查询
+	Retryable   func(err error) bool // 判断错误是否需要重试，默认为 IsTransientError
+}
+
+// delay 第n次重试前的等待时间
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// RetryMiddleware 创建重试中间件，查询遇到临时错误时按指数退避重试。
+// 事务中的查询不会重试：死锁等错误发生后整个事务已经回滚，需要由调用方重新执行事务。
+//
+// 默认只重试查询。连接断开或超时时写操作可能已经在数据库中执行，重试会重复写入，
+// 只有确认所有写操作都是幂等的才应该在 QueryTypes 中加入 "exec"
+//
+//	db.Use(orm.RetryMiddleware(orm.RetryPolicy{
+//		MaxAttempts: 3,
+//		QueryTypes:  []string{"query"},
+//	}))
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 10 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransientError
+	}
+	if len(policy.QueryTypes) == 0 {
+		policy.QueryTypes = []string{"query"}
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+			if qc.inTx || !matchQueryType(policy.QueryTypes, qc.QueryType) {
+				return next.QueryHandler(ctx, qc)
+			}
+
+			res, err := next.QueryHandler(ctx, qc)
+			for attempt := 1; attempt < policy.MaxAttempts && err != nil && policy.Retryable(err); attempt++ {
+				timer := time.NewTimer(policy.delay(attempt))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return res, err
+				case <-timer.C:
+				}
+				res, err = next.QueryHandler(ctx, qc)
+			}
+			return res, err
+		})
+	}
+}
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭，查询正常执行
+	CircuitOpen                         // 打开，查询直接返回 ErrCircuitOpen
+	CircuitHalfOpen                     // 半开，允许少量查询试探数据库是否恢复
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	FailureThreshold int                         // 连续失败多少次后打开，默认5
+	OpenTimeout      time.Duration               // 打开多久后进入半开状态，默认30s
+	HalfOpenRequests int                         // 半开状态下允许同时执行的试探查询数，默认1
+	QueryTypes       []string                    // 生效的查询类型，为空时对所有类型生效
+	IsFailure        func(err error) bool        // 判断错误是否计为失败，默认为 IsTransientError
+	OnStateChange    func(from, to CircuitState) // 状态变化时调用
+}
+
+// circuitBreaker 熔断器
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int       // 关闭状态下连续失败的次数
+	openedAt time.Time // 最近一次打开的时间
+	probes   int       // 半开状态下正在执行的试探查询数
+}
+
+// allow 判断查询是否可以执行
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.cfg.OpenTimeout {
+			return false
+		}
+		cb.setState(CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probes >= cb.cfg.HalfOpenRequests {
+			return false
+		}
+		cb.probes++
+	}
+	return true
+}
+
+// record 记录查询结果
+func (cb *circuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.cfg.FailureThreshold {
+			cb.trip()
+		}
+	case CircuitHalfOpen:
+		if cb.probes > 0 {
+			cb.probes--
+		}
+		if failed {
+			cb.trip()
+			return
+		}
+		cb.failures = 0
+		cb.setState(CircuitClosed)
+	}
+}
+
+func (cb *circuitBreaker) trip() {
+	cb.openedAt = time.Now()
+	cb.probes = 0
+	cb.setState(CircuitOpen)
+}
+
+func (cb *circuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	if cb.cfg.OnStateChange != nil {
+		cb.cfg.OnStateChange(from, state)
+	}
+}
+
+// CircuitBreakerMiddleware 创建熔断中间件：连续出现临时错误后熔断器打开，之后的查询直接返回 ErrCircuitOpen，
+// 避免数据库故障时请求堆积；经过 OpenTimeout 后放行少量查询试探，成功则恢复，失败则继续熔断。
+// 与 RetryMiddleware 一起使用时，先添加熔断中间件，重试耗尽后才计为一次失败
+func CircuitBreakerMiddleware(cfg CircuitBreakerConfig) Middleware {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = IsTransientError
+	}
+	cb := &circuitBreaker{cfg: cfg}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+			if !matchQueryType(cfg.QueryTypes, qc.QueryType) {
+				return next.QueryHandler(ctx, qc)
+			}
+			if !cb.allow() {
+				return &QueryResult{Err: ErrCircuitOpen}, ErrCircuitOpen
+			}
+
+			res, err := next.QueryHandler(ctx, qc)
+			cb.record(err != nil && cfg.IsFailure(err))
+			return res, err
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "pq: " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+func TestIsTransientError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "mysql deadlock", err: &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, want: true},
+		{name: "mysql lock wait timeout", err: fmt.Errorf("exec: %w", &mysql.MySQLError{Number: 1205}), want: true},
+		{name: "mysql duplicate entry", err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "postgres serialization failure", err: sqlStateErr("40001"), want: true},
+		{name: "postgres unique violation", err: sqlStateErr("23505"), want: false},
+		{name: "sqlite busy", err: errors.New("database is locked"), want: true},
+		{name: "bad conn", err: driver.ErrBadConn, want: true},
+		{name: "context deadline", err: context.DeadlineExceeded, want: false},
+		{name: "other", err: errors.New("syntax error"), want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsTransientError(tc.err))
+		})
+	}
+}
+
+// failingHandler 前 failures 次返回 err，之后成功
+type failingHandler struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (h *failingHandler) QueryHandler(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+	h.calls++
+	if h.calls <= h.failures {
+		return &QueryResult{Err: h.err}, h.err
+	}
+	return &QueryResult{}, nil
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213}
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, QueryTypes: []string{"query"}}
+
+	testCases := []struct {
+		name      string
+		handler   *failingHandler
+		qc        *QueryContext
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "recovered",
+			handler:   &failingHandler{failures: 2, err: deadlock},
+			qc:        &QueryContext{QueryType: "query"},
+			wantCalls: 3,
+		},
+		{
+			name:      "exhausted",
+			handler:   &failingHandler{failures: 5, err: deadlock},
+			qc:        &QueryContext{QueryType: "query"},
+			wantErr:   deadlock,
+			wantCalls: 3,
+		},
+		{
+			name:      "not transient",
+			handler:   &failingHandler{failures: 5, err: errors.New("syntax error")},
+			qc:        &QueryContext{QueryType: "query"},
+			wantErr:   errors.New("syntax error"),
+			wantCalls: 1,
+		},
+		{
+			name:      "query type not configured",
+			handler:   &failingHandler{failures: 5, err: deadlock},
+			qc:        &QueryContext{QueryType: "exec"},
+			wantErr:   deadlock,
+			wantCalls: 1,
+		},
+		{
+			name:      "in transaction",
+			handler:   &failingHandler{failures: 5, err: deadlock},
+			qc:        &QueryContext{QueryType: "query", inTx: true},
+			wantErr:   deadlock,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := RetryMiddleware(policy)(tc.handler)
+			_, err := h.QueryHandler(context.Background(), tc.qc)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCalls, tc.handler.calls)
+		})
+	}
+}
+
+func TestRetryMiddleware_DefaultQueryTypes(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213}
+
+	// 默认不重试写操作
+	handler := &failingHandler{failures: 1, err: deadlock}
+	_, err := RetryMiddleware(RetryPolicy{BaseDelay: time.Millisecond})(handler).
+		QueryHandler(context.Background(), &QueryContext{QueryType: "exec"})
+	assert.Equal(t, deadlock, err)
+	assert.Equal(t, 1, handler.calls)
+
+	// 显式配置后重试写操作
+	handler = &failingHandler{failures: 1, err: deadlock}
+	_, err = RetryMiddleware(RetryPolicy{BaseDelay: time.Millisecond, QueryTypes: []string{"query", "exec"}})(handler).
+		QueryHandler(context.Background(), &QueryContext{QueryType: "exec"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, handler.calls)
+}
+
+func TestRetryMiddleware_ContextDone(t *testing.T) {
+	handler := &failingHandler{failures: 5, err: driver.ErrBadConn}
+	h := RetryMiddleware(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := h.QueryHandler(ctx, &QueryContext{QueryType: "query"})
+	assert.Equal(t, driver.ErrBadConn, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestRetryMiddleware_Selector(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	db.Use(RetryMiddleware(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	mock.ExpectQuery("SELECT").WillReturnError(&mysql.MySQLError{Number: 1213})
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Tom"))
+
+	res, err := RegisterSelector[TestModel](db).Select().Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Tom", res.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	var changes []string
+	handler := &failingHandler{failures: 3, err: driver.ErrBadConn}
+	h := CircuitBreakerMiddleware(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})(handler)
+	qc := &QueryContext{QueryType: "query"}
+
+	// 连续失败两次后打开
+	for i := 0; i < 2; i++ {
+		_, err := h.QueryHandler(context.Background(), qc)
+		assert.Equal(t, driver.ErrBadConn, err)
+	}
+	_, err := h.QueryHandler(context.Background(), qc)
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, 2, handler.calls)
+
+	// 半开状态下试探失败，重新打开
+	time.Sleep(25 * time.Millisecond)
+	_, err = h.QueryHandler(context.Background(), qc)
+	assert.Equal(t, driver.ErrBadConn, err)
+	_, err = h.QueryHandler(context.Background(), qc)
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// 试探成功后关闭
+	time.Sleep(25 * time.Millisecond)
+	_, err = h.QueryHandler(context.Background(), qc)
+	assert.NoError(t, err)
+	_, err = h.QueryHandler(context.Background(), qc)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerMiddleware_IgnoredErrors(t *testing.T) {
+	handler := &failingHandler{failures: 10, err: errors.New("duplicate entry")}
+	h := CircuitBreakerMiddleware(CircuitBreakerConfig{FailureThreshold: 1})(handler)
+
+	for i := 0; i < 3; i++ {
+		_, err := h.QueryHandler(context.Background(), &QueryContext{QueryType: "exec"})
+		assert.EqualError(t, err, "duplicate entry")
+	}
+	assert.Equal(t, 3, handler.calls)
+}
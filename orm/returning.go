@@ -0,0 +1,159 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
+)
+
+// ErrReturningNotSupported 方言不支持 RETURNING 且无法通过 LastInsertId 模拟
+var ErrReturningNotSupported = errors.New("orm: RETURNING is not supported by the dialect")
+
+// ReturningDialect 方言对 RETURNING 子句的支持情况
+// 未实现该接口的方言视为不支持 RETURNING，插入单行时通过 LastInsertId 获取自增ID
+type ReturningDialect interface {
+	// SupportsReturning 是否支持 INSERT/UPDATE/DELETE ... RETURNING
+	SupportsReturning() bool
+	// FirstInsertID 根据批量插入后的 LastInsertId 计算第一行的自增ID
+	FirstInsertID(lastID int64, rows int) int64
+}
+
+// supportsReturning 判断方言是否支持 RETURNING 子句
+func supportsReturning(d Dialect) bool {
+	rd, ok := d.(ReturningDialect)
+	return ok && rd.SupportsReturning()
+}
+
+// buildReturning 写入 RETURNING 子句，cols 为字段名，为空时返回所有列
+func buildReturning(builder *strings.Builder, d Dialect, m *model, cols []string) error {
+	builder.WriteString(" RETURNING ")
+	if len(cols) == 0 {
+		builder.WriteByte('*')
+		return nil
+	}
+	for idx, fieldName := range cols {
+		f, ok := m.fieldsMap[fieldName]
+		if !ok {
+			return ferr.ErrInvalidColumn(fieldName)
+		}
+		if idx > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(d.Quote(f.colName))
+	}
+	return nil
+}
+
+// generatedIDField 返回不支持 RETURNING 时通过 LastInsertId 回填的字段
+// 指定了一列时使用该列，否则依次使用自增列、主键和 ID 字段
+func generatedIDField(m *model, cols []string) (string, error) {
+	if len(cols) > 1 {
+		return "", ErrReturningNotSupported
+	}
+	if len(cols) == 1 {
+		if _, ok := m.fieldsMap[cols[0]]; !ok {
+			return "", ferr.ErrInvalidColumn(cols[0])
+		}
+		return cols[0], nil
+	}
+
+	var primaryKey string
+	for name, f := range m.fieldsMap {
+		if f.autoIncr {
+			return name, nil
+		}
+		if f.primaryKey {
+			primaryKey = name
+		}
+	}
+	if primaryKey != "" {
+		return primaryKey, nil
+	}
+	if _, ok := m.fieldsMap["ID"]; ok {
+		return "ID", nil
+	}
+	return "", ErrReturningNotSupported
+}
+
+// setIntField 将自增ID写入整数字段
+func setIntField(field reflect.Value, id int64) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	default:
+		return ErrReturningNotSupported
+	}
+	return nil
+}
+
+// intField 读取整数字段的值，用于从 RETURNING 结果得到 LastInsertId
+func intField(field reflect.Value) (int64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), true
+	}
+	return 0, false
+}
+
+// scanModelRow 将一行结果扫描到结构体中，不属于模型的列被忽略
+func scanModelRow(rows *sql.Rows, m *model, dst reflect.Value) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	vals := make([]any, len(cols))
+	for i, col := range cols {
+		if fieldName, ok := m.colNameMap[col]; ok {
+			if field := dst.FieldByName(fieldName); field.IsValid() && field.CanAddr() {
+				vals[i] = m.scanDest(col, field.Addr().Interface())
+				continue
+			}
+		}
+		var placeholder any
+		vals[i] = &placeholder
+	}
+	return rows.Scan(vals...)
+}
+
+// scanReturningRows 将 RETURNING 返回的所有行扫描为新的结构体
+func scanReturningRows[T any](db *DB, m *model, rows *sql.Rows) ([]*T, error) {
+	defer rows.Close()
+
+	var result []*T
+	for rows.Next() {
+		t := new(T)
+		val := reflect.ValueOf(t).Elem()
+		if err := scanModelRow(rows, m, val); err != nil {
+			return nil, err
+		}
+		db.localizeTimes(val)
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// returningResult 使用 RETURNING 执行时构造的 sql.Result
+type returningResult struct {
+	lastID   int64
+	hasID    bool
+	affected int64
+}
+
+func (r returningResult) LastInsertId() (int64, error) {
+	if !r.hasID {
+		return 0, ErrReturningNotSupported
+	}
+	return r.lastID, nil
+}
+
+func (r returningResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
@@ -0,0 +1,137 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInserter_Returning(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dialect string
+		expect  func(mock sqlmock.Sqlmock)
+		wantIDs []int
+		wantErr error
+	}{
+		{
+			name:    "postgresql returning",
+			dialect: "postgresql",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO "test_model" ("name", "job") VALUES ($1, $2), ($3, $4) RETURNING "id";`).
+					WithArgs("Tom", sql.NullString{}, "Jerry", sql.NullString{}).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+			},
+			wantIDs: []int{7, 8},
+		},
+		{
+			name:    "mysql last insert id is the first row",
+			dialect: "mysql",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `test_model` (`name`, `job`) VALUES (?, ?), (?, ?);").
+					WillReturnResult(sqlmock.NewResult(10, 2))
+			},
+			wantIDs: []int{10, 11},
+		},
+		{
+			name:    "sqlite last insert id is the last row",
+			dialect: "sqlite",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "test_model" ("name", "job") VALUES (?, ?), (?, ?);`).
+					WillReturnResult(sqlmock.NewResult(11, 2))
+			},
+			wantIDs: []int{10, 11},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mockDB.Close()
+			db, err := Open(mockDB, tc.dialect)
+			require.NoError(t, err)
+			tc.expect(mock)
+
+			tom, jerry := &TestModel{Name: "Tom"}, &TestModel{Name: "Jerry"}
+			res, err := RegisterInserter[TestModel](db).
+				Insert([]string{"Name", "Job"}, tom, jerry).
+				Returning("ID").
+				Exec(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIDs, []int{tom.ID, jerry.ID})
+
+			affected, err := res.RowsAffected()
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), affected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestInserter_ReturningLastInsertId(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	// 未指定列时返回所有列，LastInsertId 取自主键
+	mock.ExpectQuery(`INSERT INTO "test_model" ("name", "job") VALUES ($1, $2) RETURNING *;`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(3, "Tom", "Engineer"))
+
+	tom := &TestModel{Name: "Tom"}
+	res, err := RegisterInserter[TestModel](db).
+		Insert([]string{"Name", "Job"}, tom).
+		Returning().
+		Exec(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &TestModel{ID: 3, Name: "Tom", Job: sql.NullString{String: "Engineer", Valid: true}}, tom)
+
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdaterDeleter_ExecReturning(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`UPDATE "test_model" SET "name" = $1 WHERE "id" > $2 RETURNING "id", "name";`).
+		WithArgs("Tom", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Tom").AddRow(3, "Tom"))
+	mock.ExpectQuery(`DELETE FROM "test_model" WHERE "id" = $1 RETURNING *;`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(2, "Tom", nil))
+
+	updated, err := RegisterUpdater[TestModel](db).Update().
+		Set(Col("Name"), "Tom").
+		Where(Col("ID").Gt(1)).
+		Returning("ID", "Name").
+		ExecReturning(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*TestModel{{ID: 2, Name: "Tom"}, {ID: 3, Name: "Tom"}}, updated)
+
+	deleted, err := RegisterDeleter[TestModel](db).Delete().
+		Where(Col("ID").Eq(2)).
+		ExecReturning(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*TestModel{{ID: 2, Name: "Tom"}}, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// 不支持 RETURNING 的方言不会执行语句
+	mysqlDB, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	_, err = RegisterDeleter[TestModel](mysqlDB).Delete().
+		Where(Col("ID").Eq(2)).
+		ExecReturning(context.Background())
+	assert.ErrorIs(t, err, ErrReturningNotSupported)
+}
@@ -0,0 +1,392 @@
+package orm
+
+import (
+	"strings"
+)
+
+// 各方言把结构变更转换为 ALTER 语句。MySQL 和 PostgreSQL 把同一张表的列变更合并为一条 ALTER TABLE，
+// 由数据库保证各个子句的执行顺序；SQLite 只支持添加和删除列，其他变更通过重建表完成。
+// 索引和外键先删除后添加：删除在修改列之前执行，添加在修改列之后执行
+
+// normalizeType 规范化 MySQL 类型：去掉整数的显示宽度，统一类型的别名
+func (m Mysql) normalizeType(typ string) (string, bool) {
+	// 枚举的取值区分大小写，只统一关键字和空白
+	if values, ok := parseEnumType(typ); ok {
+		return "ENUM(" + enumValues(values, ",") + ")", false
+	}
+	typ = canonicalType(typ)
+	autoIncr := strings.Contains(typ, "AUTO_INCREMENT")
+	if autoIncr {
+		typ = spaceRe.ReplaceAllString(strings.TrimSpace(strings.ReplaceAll(typ, "AUTO_INCREMENT", "")), " ")
+	}
+
+	base, args, suffix := parseType(typ)
+	switch base {
+	case "BOOL", "BOOLEAN":
+		return "TINYINT(1)", autoIncr
+	case "INTEGER":
+		base = "INT"
+	case "NUMERIC":
+		base = "DECIMAL"
+	case "REAL":
+		base = "DOUBLE"
+	case "DOUBLE":
+		if suffix == "PRECISION" {
+			suffix = ""
+		}
+	}
+	if _, ok := intRank[base]; ok && !(base == "TINYINT" && len(args) == 1 && args[0] == 1) {
+		// 整数的显示宽度不影响取值范围
+		args = nil
+	}
+	if base == "DECIMAL" {
+		switch len(args) {
+		case 0:
+			args = []int{10, 0}
+		case 1:
+			args = append(args, 0)
+		}
+	}
+	return formatType(base, args, suffix), autoIncr
+}
+
+// columnDefinition 生成 ADD COLUMN 和 MODIFY COLUMN 使用的完整列定义
+func (m Mysql) columnDefinition(c *ColumnSchema) string {
+	def := m.Quote(c.Name) + " " + c.Type
+	if c.Nullable {
+		def += " NULL"
+	} else {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	if c.AutoIncr {
+		def += " AUTO_INCREMENT"
+	}
+	if c.Comment != "" {
+		def += " COMMENT '" + strings.ReplaceAll(c.Comment, "'", "''") + "'"
+	}
+	return def
+}
+
+// alterStatements MySQL 修改列时需要给出完整的列定义，只修改默认值时使用 ALTER COLUMN，不需要重建表
+func (m Mysql) alterStatements(a *schemaAlter) []string {
+	var stmts, clauses []string
+	table := m.Quote(a.actual.Name)
+	// 先单独删除外键，外键使用的索引才能在后面删除
+	if len(a.dropForeignKeys) > 0 {
+		var drops []string
+		for _, fk := range a.dropForeignKeys {
+			drops = append(drops, "DROP FOREIGN KEY "+m.Quote(fk.Name))
+		}
+		stmts = append(stmts, "ALTER TABLE "+table+"\n  "+strings.Join(drops, ",\n  "))
+	}
+	for _, idx := range a.dropIndexes {
+		clauses = append(clauses, "DROP INDEX "+m.Quote(idx.Name))
+	}
+	for _, c := range a.add {
+		clauses = append(clauses, "ADD COLUMN "+m.columnDefinition(c))
+	}
+	for _, ca := range a.modify {
+		if ca.typ || ca.notNull {
+			clauses = append(clauses, "MODIFY COLUMN "+m.columnDefinition(ca.to))
+		} else if ca.to.Default == "" {
+			clauses = append(clauses, "ALTER COLUMN "+m.Quote(ca.to.Name)+" DROP DEFAULT")
+		} else {
+			clauses = append(clauses, "ALTER COLUMN "+m.Quote(ca.to.Name)+" SET DEFAULT "+ca.to.Default)
+		}
+	}
+	if a.primaryKey != nil {
+		if len(a.actual.PrimaryKey()) > 0 {
+			clauses = append(clauses, "DROP PRIMARY KEY")
+		}
+		clauses = append(clauses, "ADD PRIMARY KEY ("+quoteColumns(m, a.primaryKey)+")")
+	}
+	for _, col := range a.drop {
+		clauses = append(clauses, "DROP COLUMN "+m.Quote(col))
+	}
+	for _, idx := range a.addIndexes {
+		if idx.Unique {
+			clauses = append(clauses, "ADD UNIQUE INDEX "+m.Quote(idx.Name)+" ("+quoteColumns(m, idx.Columns)+")")
+		} else {
+			clauses = append(clauses, "ADD INDEX "+m.Quote(idx.Name)+" ("+quoteColumns(m, idx.Columns)+")")
+		}
+	}
+	for _, fk := range a.addForeignKeys {
+		clauses = append(clauses, "ADD "+foreignKeyClause(m.Quote, fk))
+	}
+	if len(clauses) > 0 {
+		stmts = append(stmts, "ALTER TABLE "+table+"\n  "+strings.Join(clauses, ",\n  "))
+	}
+	return stmts
+}
+
+// postgresqlTypes PostgreSQL 类型的别名
+var postgresqlTypes = map[string]string{
+	"INT":         "INTEGER",
+	"INT4":        "INTEGER",
+	"INT8":        "BIGINT",
+	"INT2":        "SMALLINT",
+	"BOOL":        "BOOLEAN",
+	"FLOAT4":      "REAL",
+	"FLOAT8":      "DOUBLE PRECISION",
+	"DOUBLE":      "DOUBLE PRECISION",
+	"TIMESTAMPTZ": "TIMESTAMP WITH TIME ZONE",
+	"TIMESTAMP":   "TIMESTAMP WITHOUT TIME ZONE",
+	"TIMETZ":      "TIME WITH TIME ZONE",
+	"TIME":        "TIME WITHOUT TIME ZONE",
+}
+
+// normalizeType 规范化 PostgreSQL 类型：SERIAL 等类型拆为整数和自增属性，统一类型的别名。
+// 系统表中数组元素的类型不带长度，数组类型去掉元素的长度后比较
+func (p Postgresql) normalizeType(typ string) (string, bool) {
+	typ = canonicalType(typ)
+	if elem, ok := strings.CutSuffix(typ, "[]"); ok {
+		elem, _ = p.normalizeType(elem)
+		base, _, suffix := parseType(elem)
+		return formatType(base, nil, suffix) + "[]", false
+	}
+	switch typ {
+	case "SERIAL", "SERIAL4":
+		return "INTEGER", true
+	case "BIGSERIAL", "SERIAL8":
+		return "BIGINT", true
+	case "SMALLSERIAL", "SERIAL2":
+		return "SMALLINT", true
+	}
+	if alias, ok := postgresqlTypes[typ]; ok {
+		return alias, false
+	}
+	base, args, suffix := parseType(typ)
+	switch base {
+	case "CHARACTER VARYING":
+		base = "VARCHAR"
+	case "CHARACTER":
+		base = "CHAR"
+	case "DECIMAL":
+		base = "NUMERIC"
+	}
+	return formatType(base, args, suffix), false
+}
+
+// columnDefinition 生成 ADD COLUMN 使用的列定义，自增列使用 SERIAL 类型
+func (p Postgresql) columnDefinition(c *ColumnSchema) string {
+	typ := c.Type
+	if c.AutoIncr {
+		switch typ {
+		case "INTEGER":
+			typ = "SERIAL"
+		case "BIGINT":
+			typ = "BIGSERIAL"
+		case "SMALLINT":
+			typ = "SMALLSERIAL"
+		}
+	}
+	def := p.Quote(c.Name) + " " + typ
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" && !c.AutoIncr {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
+
+// alterStatements PostgreSQL 分别修改列的类型、默认值和是否允许 NULL，修改类型时通过 USING 转换已有数据
+func (p Postgresql) alterStatements(a *schemaAlter) []string {
+	var stmts, clauses []string
+	// PostgreSQL 的索引不属于 ALTER TABLE，单独删除和创建
+	for _, idx := range a.dropIndexes {
+		stmts = append(stmts, "DROP INDEX "+p.Quote(idx.Name))
+	}
+	for _, fk := range a.dropForeignKeys {
+		clauses = append(clauses, "DROP CONSTRAINT "+p.Quote(fk.Name))
+	}
+	for _, c := range a.add {
+		if len(c.Enum) > 0 {
+			stmts = append(stmts, createEnumSQL(strings.ToLower(c.Type), c.Enum))
+		}
+		clauses = append(clauses, "ADD COLUMN "+p.columnDefinition(c))
+	}
+	for _, ca := range a.modify {
+		col := "ALTER COLUMN " + p.Quote(ca.to.Name)
+		if ca.typ && ca.from.Type != ca.to.Type {
+			if len(ca.to.Enum) > 0 {
+				stmts = append(stmts, createEnumSQL(strings.ToLower(ca.to.Type), ca.to.Enum))
+			}
+			clauses = append(clauses, col+" TYPE "+ca.to.Type+" USING "+p.Quote(ca.to.Name)+"::"+ca.to.Type)
+		}
+		if ca.typ && ca.from.AutoIncr != ca.to.AutoIncr {
+			if ca.to.AutoIncr {
+				clauses = append(clauses, col+" ADD GENERATED BY DEFAULT AS IDENTITY")
+			} else {
+				// SERIAL 列的自增来自默认值 nextval
+				clauses = append(clauses, col+" DROP DEFAULT")
+			}
+		}
+		if ca.def {
+			if ca.to.Default == "" {
+				clauses = append(clauses, col+" DROP DEFAULT")
+			} else {
+				clauses = append(clauses, col+" SET DEFAULT "+ca.to.Default)
+			}
+		}
+		if ca.notNull {
+			if ca.to.Nullable {
+				clauses = append(clauses, col+" DROP NOT NULL")
+			} else {
+				clauses = append(clauses, col+" SET NOT NULL")
+			}
+		}
+	}
+	if a.primaryKey != nil {
+		if len(a.actual.PrimaryKey()) > 0 {
+			// 使用 PostgreSQL 默认的主键约束名
+			clauses = append(clauses, "DROP CONSTRAINT "+p.Quote(a.actual.Name+"_pkey"))
+		}
+		clauses = append(clauses, "ADD PRIMARY KEY ("+quoteColumns(p, a.primaryKey)+")")
+	}
+	for _, col := range a.drop {
+		clauses = append(clauses, "DROP COLUMN "+p.Quote(col))
+	}
+	for _, fk := range a.addForeignKeys {
+		clauses = append(clauses, "ADD "+foreignKeyClause(p.Quote, fk))
+	}
+	if len(clauses) > 0 {
+		stmts = append(stmts, "ALTER TABLE "+p.Quote(a.actual.Name)+"\n  "+strings.Join(clauses, ",\n  "))
+	}
+	for _, idx := range a.addIndexes {
+		stmts = append(stmts, createIndexSQL(p.Quote, a.actual.Name, idx))
+	}
+	return stmts
+}
+
+// normalizeType 规范化 SQLite 类型，去掉列定义中的 PRIMARY KEY 和 AUTOINCREMENT
+func (s Sqlite) normalizeType(typ string) (string, bool) {
+	typ = canonicalType(typ)
+	autoIncr := strings.Contains(typ, "AUTOINCREMENT")
+	typ = strings.ReplaceAll(typ, "PRIMARY KEY", "")
+	typ = strings.ReplaceAll(typ, "AUTOINCREMENT", "")
+	return spaceRe.ReplaceAllString(strings.TrimSpace(typ), " "), autoIncr
+}
+
+// columnDefinition 生成列定义，inlinePK 为 true 时把自增主键写在列定义中
+func (s Sqlite) columnDefinition(c *ColumnSchema, inlinePK bool) string {
+	def := s.Quote(c.Name) + " " + c.Type
+	if inlinePK {
+		def += " PRIMARY KEY"
+		if c.AutoIncr {
+			def += " AUTOINCREMENT"
+		}
+	} else if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
+
+// canAddColumn SQLite 的 ADD COLUMN 不能添加主键，NOT NULL 的列需要默认值，默认值不能是时间函数或表达式
+func (s Sqlite) canAddColumn(c *ColumnSchema) bool {
+	if c.PrimaryKey || (!c.Nullable && c.Default == "") {
+		return false
+	}
+	def := strings.ToUpper(c.Default)
+	return !strings.HasPrefix(def, "(") && !strings.HasPrefix(def, "CURRENT_")
+}
+
+// alterStatements SQLite 只能逐列添加和删除（删除需要 3.35.0 及以上版本），
+// 修改列、主键或外键时创建新表、复制数据、删除旧表并重命名新表，重建后重新创建索引。
+// 原表上的触发器和视图需要手动重新创建
+func (s Sqlite) alterStatements(a *schemaAlter) []string {
+	rebuild := len(a.modify) > 0 || a.primaryKey != nil || len(a.addForeignKeys) > 0 || len(a.dropForeignKeys) > 0
+	for _, c := range a.add {
+		if !s.canAddColumn(c) {
+			rebuild = true
+		}
+	}
+
+	table := s.Quote(a.actual.Name)
+	if !rebuild {
+		var stmts []string
+		for _, idx := range a.dropIndexes {
+			stmts = append(stmts, "DROP INDEX "+s.Quote(idx.Name))
+		}
+		for _, c := range a.add {
+			stmts = append(stmts, "ALTER TABLE "+table+" ADD COLUMN "+s.columnDefinition(c, false))
+		}
+		for _, col := range a.drop {
+			stmts = append(stmts, "ALTER TABLE "+table+" DROP COLUMN "+s.Quote(col))
+		}
+		for _, idx := range a.addIndexes {
+			stmts = append(stmts, createIndexSQL(s.Quote, a.actual.Name, idx))
+		}
+		return stmts
+	}
+
+	// 新表的列：保留的旧列在前，新增的列在后
+	dropped := make(map[string]bool, len(a.drop))
+	for _, col := range a.drop {
+		dropped[col] = true
+	}
+	modified := make(map[string]*ColumnSchema, len(a.modify))
+	for _, ca := range a.modify {
+		modified[ca.to.Name] = ca.to
+	}
+	var cols []*ColumnSchema
+	var copied []string
+	for _, c := range a.actual.Columns {
+		if dropped[c.Name] {
+			continue
+		}
+		copied = append(copied, c.Name)
+		if to, ok := modified[c.Name]; ok {
+			c = to
+		}
+		cols = append(cols, c)
+	}
+	cols = append(cols, a.add...)
+
+	pk := a.actual.PrimaryKey()
+	if a.primaryKey != nil {
+		pk = a.primaryKey
+	}
+	// 唯一的自增主键写在列定义中，其他主键使用表级约束
+	inlinePK := ""
+	for _, c := range cols {
+		if len(pk) == 1 && c.Name == pk[0] && c.AutoIncr {
+			inlinePK = c.Name
+		}
+	}
+	var defs []string
+	for _, c := range cols {
+		defs = append(defs, s.columnDefinition(c, c.Name == inlinePK))
+	}
+	if len(pk) > 0 && inlinePK == "" {
+		defs = append(defs, "PRIMARY KEY ("+quoteColumns(s, pk)+")")
+	}
+	for _, fk := range a.remainingForeignKeys() {
+		defs = append(defs, foreignKeyClause(s.Quote, fk))
+	}
+
+	tmp := s.Quote(a.actual.Name + "__new")
+	copyCols := quoteColumns(s, copied)
+	stmts := []string{
+		"CREATE TABLE " + tmp + " (\n  " + strings.Join(defs, ",\n  ") + "\n)",
+		"INSERT INTO " + tmp + " (" + copyCols + ") SELECT " + copyCols + " FROM " + table,
+		"DROP TABLE " + table,
+		"ALTER TABLE " + tmp + " RENAME TO " + table,
+	}
+	// 删除旧表时索引也被删除
+	for _, idx := range a.remainingIndexes() {
+		stmts = append(stmts, createIndexSQL(s.Quote, a.actual.Name, idx))
+	}
+	return stmts
+}
+
+// quoteColumns 引用列名并以逗号分隔
+func quoteColumns(d Dialect, cols []string) string {
+	return joinQuoted(d.Quote, cols)
+}
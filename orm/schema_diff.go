@@ -0,0 +1,654 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ColumnSchema 表中一列的结构
+type ColumnSchema struct {
+	Name       string
+	Type       string // 规范化后的类型，例如 VARCHAR(255)、INT UNSIGNED，不包含自增属性
+	Nullable   bool
+	Default    string // 默认值的 SQL 字面量，空字符串表示没有默认值
+	PrimaryKey bool
+	AutoIncr   bool
+	Comment    string   // 只用于生成语句，不参与比较
+	Enum       []string // 枚举的取值，只用于生成语句，不参与比较
+}
+
+// TableSchema 表结构，Columns 按列在表中的顺序排列
+type TableSchema struct {
+	Name        string
+	Columns     []*ColumnSchema
+	Indexes     []*IndexSchema
+	ForeignKeys []*ForeignKeySchema
+}
+
+// Column 返回名为 name 的列，不存在时返回 nil
+func (t *TableSchema) Column(name string) *ColumnSchema {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// PrimaryKey 返回主键列名
+func (t *TableSchema) PrimaryKey() []string {
+	var cols []string
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			cols = append(cols, c.Name)
+		}
+	}
+	return cols
+}
+
+// SchemaChangeKind 结构变更的类型
+type SchemaChangeKind int
+
+const (
+	ChangeAddColumn      SchemaChangeKind = iota // 添加列
+	ChangeColumnType                             // 修改列类型或自增属性
+	ChangeColumnDefault                          // 修改列默认值
+	ChangeColumnNullable                         // 修改列是否允许 NULL
+	ChangePrimaryKey                             // 修改主键
+	ChangeDropColumn                             // 删除列
+	ChangeAddIndex                               // 添加索引或唯一约束
+	ChangeDropIndex                              // 删除索引或唯一约束
+	ChangeAddForeignKey                          // 添加外键
+	ChangeDropForeignKey                         // 删除外键
+)
+
+// SchemaChange 一项结构变更
+type SchemaChange struct {
+	Kind        SchemaChangeKind
+	Column      string // 修改主键、索引和外键时为空
+	Name        string // 索引或外键的名称
+	From        string // 变更前的类型、默认值、NULL/NOT NULL 或主键列，添加列时为空
+	To          string // 变更后的值，添加列时为列定义，删除列时为空
+	Destructive bool   // 变更可能丢失数据，或者因为已有数据而失败
+	Reason      string // 破坏性变更的原因
+}
+
+// String 返回变更的说明
+func (c SchemaChange) String() string {
+	var s string
+	switch c.Kind {
+	case ChangeAddColumn:
+		s = "添加列 " + c.Column + " " + c.To
+	case ChangeColumnType:
+		s = fmt.Sprintf("修改列 %s 的类型: %s -> %s", c.Column, c.From, c.To)
+	case ChangeColumnDefault:
+		s = fmt.Sprintf("修改列 %s 的默认值: %s -> %s", c.Column, orNone(c.From), orNone(c.To))
+	case ChangeColumnNullable:
+		s = fmt.Sprintf("修改列 %s: %s -> %s", c.Column, c.From, c.To)
+	case ChangePrimaryKey:
+		s = fmt.Sprintf("修改主键: (%s) -> (%s)", c.From, c.To)
+	case ChangeDropColumn:
+		s = "删除列 " + c.Column
+	case ChangeAddIndex:
+		s = describeIndex(c)
+	case ChangeDropIndex:
+		s = "删除索引 " + c.Name
+	case ChangeAddForeignKey:
+		s = "添加外键 " + c.Name + " " + c.To
+	case ChangeDropForeignKey:
+		s = "删除外键 " + c.Name
+	}
+	if c.Reason != "" {
+		s += "（" + c.Reason + "）"
+	}
+	return s
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "无"
+	}
+	return s
+}
+
+// SchemaPlan 把表结构迁移到模型定义需要执行的变更
+type SchemaPlan struct {
+	Table            string
+	Create           bool           // 表不存在，需要创建
+	Changes          []SchemaChange // 按执行顺序排列的全部变更
+	Statements       []string       // 要执行的语句，不允许破坏性变更时不包含破坏性变更
+	AllowDestructive bool
+}
+
+// Empty 表结构与模型定义一致时返回 true
+func (p *SchemaPlan) Empty() bool {
+	return !p.Create && len(p.Changes) == 0
+}
+
+// Safe 返回不会丢失数据的变更
+func (p *SchemaPlan) Safe() []SchemaChange {
+	var res []SchemaChange
+	for _, c := range p.Changes {
+		if !c.Destructive {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Destructive 返回可能丢失数据的变更
+func (p *SchemaPlan) Destructive() []SchemaChange {
+	var res []SchemaChange
+	for _, c := range p.Changes {
+		if c.Destructive {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Skipped 返回因为不允许破坏性变更而不会执行的变更
+func (p *SchemaPlan) Skipped() []SchemaChange {
+	if p.AllowDestructive {
+		return nil
+	}
+	return p.Destructive()
+}
+
+// DDL 返回要执行的语句，语句之间以分号分隔
+func (p *SchemaPlan) DDL() string {
+	if len(p.Statements) == 0 {
+		return ""
+	}
+	return strings.Join(p.Statements, ";\n") + ";"
+}
+
+// String 返回迁移计划，安全变更和破坏性变更分开列出，适合试运行时输出
+func (p *SchemaPlan) String() string {
+	var sb strings.Builder
+	switch {
+	case p.Create:
+		sb.WriteString("表 " + p.Table + " 不存在，将创建\n")
+	case len(p.Changes) == 0:
+		sb.WriteString("表 " + p.Table + " 没有变更\n")
+		return sb.String()
+	default:
+		sb.WriteString("表 " + p.Table + ":\n")
+	}
+
+	if safe := p.Safe(); len(safe) > 0 {
+		sb.WriteString("  安全变更:\n")
+		for _, c := range safe {
+			sb.WriteString("    " + c.String() + "\n")
+		}
+	}
+	if destructive := p.Destructive(); len(destructive) > 0 {
+		if p.AllowDestructive {
+			sb.WriteString("  破坏性变更:\n")
+		} else {
+			sb.WriteString("  破坏性变更（未执行，使用 WithAllowDestructive(true) 执行）:\n")
+		}
+		for _, c := range destructive {
+			sb.WriteString("    " + c.String() + "\n")
+		}
+	}
+	if len(p.Statements) > 0 {
+		sb.WriteString("  SQL:\n")
+		for _, stmt := range p.Statements {
+			sb.WriteString("    " + strings.ReplaceAll(stmt, "\n", "\n    ") + ";\n")
+		}
+	}
+	return sb.String()
+}
+
+// schemaDialect 支持结构比较的方言需要实现的方法
+type schemaDialect interface {
+	Dialect
+
+	// normalizeType 把类型规范化为可以直接比较的形式，并拆出其中的自增属性
+	normalizeType(typ string) (string, bool)
+
+	// alterStatements 生成执行变更的语句
+	alterStatements(a *schemaAlter) []string
+}
+
+// schemaAlter 过滤后要执行的变更，供方言生成语句
+type schemaAlter struct {
+	desired *TableSchema
+	actual  *TableSchema
+	add     []*ColumnSchema
+	modify  []columnAlter
+	drop    []string
+	// primaryKey 新的主键列，nil 表示主键不变
+	primaryKey []string
+
+	addIndexes      []*IndexSchema
+	dropIndexes     []*IndexSchema
+	addForeignKeys  []*ForeignKeySchema
+	dropForeignKeys []*ForeignKeySchema
+}
+
+// columnAlter 一列需要执行的修改。to 为修改后的列结构，跳过的变更保持 from 的值
+type columnAlter struct {
+	from, to          *ColumnSchema
+	typ, def, notNull bool // 类型、默认值、是否允许 NULL 是否变化
+}
+
+// empty 没有需要执行的变更
+func (a *schemaAlter) empty() bool {
+	return len(a.add) == 0 && len(a.modify) == 0 && len(a.drop) == 0 && a.primaryKey == nil &&
+		len(a.addIndexes) == 0 && len(a.dropIndexes) == 0 && len(a.addForeignKeys) == 0 && len(a.dropForeignKeys) == 0
+}
+
+// remainingIndexes 变更执行后表上的索引，SQLite 重建表后需要重新创建
+func (a *schemaAlter) remainingIndexes() []*IndexSchema {
+	var res []*IndexSchema
+	for _, idx := range a.actual.Indexes {
+		dropped := false
+		for _, d := range a.dropIndexes {
+			dropped = dropped || d == idx
+		}
+		if !dropped {
+			res = append(res, idx)
+		}
+	}
+	return append(res, a.addIndexes...)
+}
+
+// remainingForeignKeys 变更执行后表上的外键
+func (a *schemaAlter) remainingForeignKeys() []*ForeignKeySchema {
+	var res []*ForeignKeySchema
+	for _, fk := range a.actual.ForeignKeys {
+		dropped := false
+		for _, d := range a.dropForeignKeys {
+			dropped = dropped || d == fk
+		}
+		if !dropped {
+			res = append(res, fk)
+		}
+	}
+	return append(res, a.addForeignKeys...)
+}
+
+// desiredTableSchema 根据模型定义生成期望的表结构，列按结构体字段顺序排列
+func desiredTableSchema(d schemaDialect, m *model, typ reflect.Type) *TableSchema {
+	t := &TableSchema{Name: m.table, Indexes: m.indexes, ForeignKeys: m.foreignKeys}
+	seen := make(map[string]bool, len(m.fieldsMap))
+	add := func(f *field) {
+		colType, autoIncr := d.normalizeType(d.ColumnType(f))
+		def := f.default_
+		if strings.EqualFold(def, "NULL") {
+			def = ""
+		}
+		t.Columns = append(t.Columns, &ColumnSchema{
+			Name:       f.colName,
+			Type:       colType,
+			Nullable:   f.nullable,
+			Default:    def,
+			PrimaryKey: f.primaryKey,
+			AutoIncr:   f.autoIncr || autoIncr,
+			Comment:    f.comment,
+			Enum:       enumOf(f),
+		})
+		seen[f.colName] = true
+	}
+
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != nil && typ.Kind() == reflect.Struct {
+		for i := 0; i < typ.NumField(); i++ {
+			if f, ok := m.fieldsMap[typ.Field(i).Name]; ok {
+				add(f)
+			}
+		}
+	}
+	// 使用生成的元数据时字段可能不在结构体中，按列名排在最后
+	var rest []*field
+	for _, f := range m.fieldsMap {
+		if !seen[f.colName] {
+			rest = append(rest, f)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].colName < rest[j].colName })
+	for _, f := range rest {
+		add(f)
+	}
+
+	finishTableSchema(d, t)
+	return t
+}
+
+// finishTableSchema 消除期望结构和数据库结构之间不影响结果的差别
+func finishTableSchema(d Dialect, t *TableSchema) {
+	pk := t.PrimaryKey()
+	for _, c := range t.Columns {
+		// 主键列总是不允许 NULL
+		if c.PrimaryKey {
+			c.Nullable = false
+		}
+		// SQLite 中唯一的 INTEGER 主键是 rowid 的别名，总是自增
+		if _, ok := d.(*Sqlite); ok && len(pk) == 1 && c.PrimaryKey && c.Type == "INTEGER" {
+			c.AutoIncr = true
+		}
+	}
+}
+
+// buildSchemaPlan 比较期望的表结构和数据库中的表结构，生成迁移计划
+func buildSchemaPlan(d schemaDialect, desired, actual *TableSchema, allowDestructive bool) *SchemaPlan {
+	plan := &SchemaPlan{
+		Table:            desired.Name,
+		Changes:          diffSchema(desired, actual),
+		AllowDestructive: allowDestructive,
+	}
+
+	a := &schemaAlter{desired: desired, actual: actual}
+	alters := make(map[string]*columnAlter)
+	for _, c := range plan.Changes {
+		if c.Destructive && !allowDestructive {
+			continue
+		}
+		switch c.Kind {
+		case ChangeAddColumn:
+			a.add = append(a.add, desired.Column(c.Column))
+		case ChangeDropColumn:
+			a.drop = append(a.drop, c.Column)
+		case ChangePrimaryKey:
+			a.primaryKey = desired.PrimaryKey()
+		case ChangeAddIndex:
+			a.addIndexes = append(a.addIndexes, desired.Index(c.Name))
+		case ChangeDropIndex:
+			a.dropIndexes = append(a.dropIndexes, actual.Index(c.Name))
+		case ChangeAddForeignKey:
+			a.addForeignKeys = append(a.addForeignKeys, desired.ForeignKey(c.Name))
+		case ChangeDropForeignKey:
+			a.dropForeignKeys = append(a.dropForeignKeys, actual.ForeignKey(c.Name))
+		default:
+			ca, ok := alters[c.Column]
+			if !ok {
+				from := actual.Column(c.Column)
+				to := *from
+				ca = &columnAlter{from: from, to: &to}
+				alters[c.Column] = ca
+			}
+			want := desired.Column(c.Column)
+			switch c.Kind {
+			case ChangeColumnType:
+				ca.typ = true
+				ca.to.Type, ca.to.AutoIncr = want.Type, want.AutoIncr
+			case ChangeColumnDefault:
+				ca.def = true
+				ca.to.Default = want.Default
+			case ChangeColumnNullable:
+				ca.notNull = true
+				ca.to.Nullable = want.Nullable
+			}
+			ca.to.Comment, ca.to.Enum = want.Comment, want.Enum
+		}
+	}
+	// 修改按列在期望结构中的顺序执行
+	for _, col := range desired.Columns {
+		if ca, ok := alters[col.Name]; ok {
+			a.modify = append(a.modify, *ca)
+		}
+	}
+
+	if !a.empty() {
+		plan.Statements = d.alterStatements(a)
+	}
+	return plan
+}
+
+// diffSchema 比较两个表结构，返回按执行顺序排列的变更：删除外键和索引、
+// 添加列、修改列、修改主键、删除列、添加索引和外键。模型没有声明主键时不比较主键
+func diffSchema(desired, actual *TableSchema) []SchemaChange {
+	var adds, modifies, drops []SchemaChange
+
+	for _, want := range desired.Columns {
+		have := actual.Column(want.Name)
+		if have == nil {
+			c := SchemaChange{Kind: ChangeAddColumn, Column: want.Name, To: columnSummary(want)}
+			if !want.Nullable && want.Default == "" && !want.AutoIncr {
+				c.Destructive = true
+				c.Reason = "NOT NULL 且没有默认值，表中已有数据时会失败"
+			}
+			adds = append(adds, c)
+			continue
+		}
+
+		if want.Type != have.Type || want.AutoIncr != have.AutoIncr {
+			c := SchemaChange{Kind: ChangeColumnType, Column: want.Name, From: typeSummary(have), To: typeSummary(want)}
+			switch {
+			case want.AutoIncr != have.AutoIncr:
+				c.Destructive = true
+				c.Reason = "自增属性变化"
+			case !isWideningType(have.Type, want.Type):
+				c.Destructive = true
+				c.Reason = "类型不兼容或长度缩小，数据可能被截断"
+			}
+			modifies = append(modifies, c)
+		}
+		if normalizeDefault(want.Default) != normalizeDefault(have.Default) && !(want.AutoIncr && have.AutoIncr) {
+			modifies = append(modifies, SchemaChange{Kind: ChangeColumnDefault, Column: want.Name, From: have.Default, To: want.Default})
+		}
+		if want.Nullable != have.Nullable {
+			c := SchemaChange{Kind: ChangeColumnNullable, Column: want.Name, From: nullSummary(have.Nullable), To: nullSummary(want.Nullable)}
+			if !want.Nullable {
+				c.Destructive = true
+				c.Reason = "已有的 NULL 值会导致变更失败"
+			}
+			modifies = append(modifies, c)
+		}
+	}
+
+	if wantPK, havePK := desired.PrimaryKey(), actual.PrimaryKey(); len(wantPK) > 0 && !equalStrings(wantPK, havePK) {
+		modifies = append(modifies, SchemaChange{
+			Kind:        ChangePrimaryKey,
+			From:        strings.Join(havePK, ", "),
+			To:          strings.Join(wantPK, ", "),
+			Destructive: true,
+			Reason:      "重复的值会导致变更失败",
+		})
+	}
+
+	for _, have := range actual.Columns {
+		if desired.Column(have.Name) == nil {
+			drops = append(drops, SchemaChange{
+				Kind:        ChangeDropColumn,
+				Column:      have.Name,
+				From:        columnSummary(have),
+				Destructive: true,
+				Reason:      "列中的数据会丢失",
+			})
+		}
+	}
+
+	dropConstraints, addConstraints := diffConstraints(desired, actual)
+	changes := append(dropConstraints, adds...)
+	changes = append(changes, modifies...)
+	changes = append(changes, drops...)
+	return append(changes, addConstraints...)
+}
+
+func typeSummary(c *ColumnSchema) string {
+	if c.AutoIncr {
+		return c.Type + " AUTO_INCREMENT"
+	}
+	return c.Type
+}
+
+func nullSummary(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func columnSummary(c *ColumnSchema) string {
+	s := typeSummary(c)
+	if !c.Nullable {
+		s += " NOT NULL"
+	}
+	if c.Default != "" {
+		s += " DEFAULT " + c.Default
+	}
+	return s
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	spaceRe    = regexp.MustCompile(`\s+`)
+	parenRe    = regexp.MustCompile(`\s*([(,])\s*|\s*(\))`)
+	pgCastRe   = regexp.MustCompile(`^(.*?)::[a-zA-Z][a-zA-Z0-9_ ]*(\([0-9, ]*\))?(\[\])?$`)
+	typeArgsRe = regexp.MustCompile(`^([A-Z][A-Z ]*?)(?:\(([0-9, ]*)\))?((?: [A-Z]+)*)$`)
+)
+
+// canonicalType 类型转为大写，去掉多余的空白
+func canonicalType(typ string) string {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	typ = spaceRe.ReplaceAllString(typ, " ")
+	return parenRe.ReplaceAllString(typ, "$1$2")
+}
+
+// normalizeDefault 规范化默认值用于比较：去掉 PostgreSQL 的类型转换、字符串的引号，
+// NULL 等同于没有默认值
+func normalizeDefault(def string) string {
+	def = strings.TrimSpace(def)
+	if m := pgCastRe.FindStringSubmatch(def); m != nil {
+		def = strings.TrimSpace(m[1])
+	}
+	for len(def) >= 2 && def[0] == '(' && def[len(def)-1] == ')' {
+		def = strings.TrimSpace(def[1 : len(def)-1])
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		return strings.ReplaceAll(def[1:len(def)-1], "''", "'")
+	}
+	switch strings.ToUpper(def) {
+	case "NULL":
+		return ""
+	case "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP()", "NOW()":
+		return "CURRENT_TIMESTAMP"
+	}
+	return def
+}
+
+// parseType 拆分类型的名称、参数和修饰，例如 DECIMAL(10,2) UNSIGNED
+func parseType(typ string) (base string, args []int, suffix string) {
+	m := typeArgsRe.FindStringSubmatch(typ)
+	if m == nil {
+		return typ, nil, ""
+	}
+	if m[2] != "" {
+		for _, s := range strings.Split(m[2], ",") {
+			n, _ := strconv.Atoi(strings.TrimSpace(s))
+			args = append(args, n)
+		}
+	}
+	return m[1], args, strings.TrimSpace(m[3])
+}
+
+var (
+	intRank = map[string]int{
+		"TINYINT": 1, "SMALLINT": 2, "MEDIUMINT": 3, "INT": 4, "INTEGER": 4, "BIGINT": 5,
+	}
+	textRank = map[string]int{
+		"CHAR": 1, "CHARACTER": 1, "VARCHAR": 1, "CHARACTER VARYING": 1,
+		"TINYTEXT": 2, "TEXT": 3, "MEDIUMTEXT": 4, "LONGTEXT": 5,
+	}
+	floatRank = map[string]int{
+		"FLOAT": 1, "REAL": 1, "DOUBLE": 2, "DOUBLE PRECISION": 2,
+	}
+)
+
+// isWideningType 从 from 修改为 to 是否不会截断数据：加长字符串、换成更大的整数或浮点数、
+// 增加小数的整数位和小数位
+func isWideningType(from, to string) bool {
+	fromBase, fromArgs, fromSuffix := parseType(from)
+	toBase, toArgs, toSuffix := parseType(to)
+
+	if fr, ok := intRank[fromBase]; ok {
+		tr, ok := intRank[toBase]
+		if !ok {
+			return false
+		}
+		if fromBase == "TINYINT" && len(fromArgs) == 1 && fromArgs[0] == 1 {
+			// MySQL 的布尔类型
+			fr = 1
+		}
+		switch {
+		case fromSuffix == toSuffix:
+			return tr >= fr
+		case fromSuffix == "UNSIGNED" && toSuffix == "":
+			return tr > fr
+		default:
+			return false
+		}
+	}
+
+	if fr, ok := textRank[fromBase]; ok {
+		tr, ok := textRank[toBase]
+		if !ok || fromSuffix != toSuffix {
+			return false
+		}
+		if fr == 1 && tr == 1 {
+			if len(toArgs) == 0 {
+				return toBase == "VARCHAR" || toBase == "CHARACTER VARYING"
+			}
+			if len(fromArgs) == 0 {
+				return false
+			}
+			// CHAR 改为 VARCHAR 会去掉末尾的空格，只允许同一种类型加长
+			return (fromBase == toBase || toBase == "VARCHAR") && toArgs[0] >= fromArgs[0]
+		}
+		if fr == 3 && tr == 3 && len(fromArgs) > 0 {
+			// SQLite 的 TEXT(n)
+			return len(toArgs) == 0 || toArgs[0] >= fromArgs[0]
+		}
+		return tr > fr || (tr == fr && len(toArgs) == 0 && len(fromArgs) == 0)
+	}
+
+	if fr, ok := floatRank[fromBase]; ok {
+		tr, ok := floatRank[toBase]
+		return ok && tr >= fr
+	}
+
+	if (fromBase == "DECIMAL" || fromBase == "NUMERIC") && fromBase == toBase {
+		if len(fromArgs) != 2 || len(toArgs) != 2 {
+			return len(toArgs) == 0
+		}
+		return toArgs[0]-toArgs[1] >= fromArgs[0]-fromArgs[1] && toArgs[1] >= fromArgs[1]
+	}
+
+	return false
+}
+
+// formatType 按 parseType 拆出的各部分重新拼出类型
+func formatType(base string, args []int, suffix string) string {
+	typ := base
+	if len(args) > 0 {
+		strs := make([]string, len(args))
+		for i, n := range args {
+			strs[i] = strconv.Itoa(n)
+		}
+		typ += "(" + strings.Join(strs, ",") + ")"
+	}
+	if suffix != "" {
+		typ += " " + suffix
+	}
+	return typ
+}
@@ -0,0 +1,351 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SchemaDiffTestModel struct {
+	ID    int    `orm:"primary_key;auto_increment"`
+	Name  string `orm:"size:255"`
+	Age   int    `orm:"nullable:false;default:18"`
+	Email string `orm:"size:100"`
+}
+
+// mockSchemaDiffTable 表存在，name 比模型短，age 允许 NULL，缺少 email，多出 legacy
+func mockSchemaDiffTable(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM information_schema.tables WHERE table_name = 'schema_diff_test_model'")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(".*FROM.*INFORMATION_SCHEMA.COLUMNS.*").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
+			"CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", "NUMERIC_SCALE", "COLUMN_KEY", "EXTRA"}).
+			AddRow("id", "int(11)", "NO", nil, nil, 10, 0, "PRI", "auto_increment").
+			AddRow("name", "varchar(100)", "YES", nil, 100, nil, nil, "", "").
+			AddRow("age", "int(11)", "YES", "18", nil, 10, 0, "", "").
+			AddRow("legacy", "varchar(20)", "YES", "none", 20, nil, nil, "", ""))
+	expectConstraints(mock, nil, nil)
+}
+
+func TestPlanMigration(t *testing.T) {
+	testCases := []struct {
+		name             string
+		allowDestructive bool
+		wantSQL          string
+	}{
+		{
+			name: "safe only",
+			wantSQL: "ALTER TABLE `schema_diff_test_model`\n" +
+				"  ADD COLUMN `email` VARCHAR(100) NULL,\n" +
+				"  MODIFY COLUMN `name` VARCHAR(255) NULL",
+		},
+		{
+			name:             "allow destructive",
+			allowDestructive: true,
+			wantSQL: "ALTER TABLE `schema_diff_test_model`\n" +
+				"  ADD COLUMN `email` VARCHAR(100) NULL,\n" +
+				"  MODIFY COLUMN `name` VARCHAR(255) NULL,\n" +
+				"  MODIFY COLUMN `age` INT NOT NULL DEFAULT 18,\n" +
+				"  DROP COLUMN `legacy`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+			mockSchemaDiffTable(mock)
+
+			db, err := Open(mockDB, "mysql")
+			require.NoError(t, err)
+
+			plan, err := db.PlanMigration(context.Background(), &SchemaDiffTestModel{}, WithAllowDestructive(tc.allowDestructive))
+			require.NoError(t, err)
+			assert.Equal(t, []string{tc.wantSQL}, plan.Statements)
+
+			assert.Equal(t, []SchemaChange{
+				{Kind: ChangeAddColumn, Column: "email", To: "VARCHAR(100)"},
+				{Kind: ChangeColumnType, Column: "name", From: "VARCHAR(100)", To: "VARCHAR(255)"},
+			}, plan.Safe())
+			destructive := plan.Destructive()
+			require.Len(t, destructive, 2)
+			assert.Equal(t, ChangeColumnNullable, destructive[0].Kind)
+			assert.Equal(t, "age", destructive[0].Column)
+			assert.Equal(t, ChangeDropColumn, destructive[1].Kind)
+			assert.Equal(t, "legacy", destructive[1].Column)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAutoMigrate_DryRunPlan(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	mockSchemaDiffTable(mock)
+
+	// 试运行模式下不执行任何DDL语句
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	var migration *Migration
+	err = db.MigrateModel(context.Background(), &SchemaDiffTestModel{},
+		WithDryRun(true),
+		WithPlanOutput(&out),
+		WithMigrationCallback(func(m *Migration) { migration = m }))
+	require.NoError(t, err)
+
+	assert.Equal(t, "表 schema_diff_test_model:\n"+
+		"  安全变更:\n"+
+		"    添加列 email VARCHAR(100)\n"+
+		"    修改列 name 的类型: VARCHAR(100) -> VARCHAR(255)\n"+
+		"  破坏性变更（未执行，使用 WithAllowDestructive(true) 执行）:\n"+
+		"    修改列 age: NULL -> NOT NULL（已有的 NULL 值会导致变更失败）\n"+
+		"    删除列 legacy（列中的数据会丢失）\n"+
+		"  SQL:\n"+
+		"    ALTER TABLE `schema_diff_test_model`\n"+
+		"      ADD COLUMN `email` VARCHAR(100) NULL,\n"+
+		"      MODIFY COLUMN `name` VARCHAR(255) NULL;\n", out.String())
+	require.NotNil(t, migration)
+	assert.Equal(t, migration.Plan.DDL(), migration.DDL)
+	assert.Len(t, migration.Plan.Skipped(), 2)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAutoMigrate_NoChanges(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM information_schema.tables WHERE table_name = 'schema_diff_test_model'")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(".*FROM.*INFORMATION_SCHEMA.COLUMNS.*").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
+			"CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", "NUMERIC_SCALE", "COLUMN_KEY", "EXTRA"}).
+			AddRow("id", "int(11)", "NO", nil, nil, 10, 0, "PRI", "auto_increment").
+			AddRow("name", "varchar(255)", "YES", nil, 255, nil, nil, "", "").
+			AddRow("age", "int", "NO", "18", nil, 10, 0, "", "").
+			AddRow("email", "varchar(100)", "YES", nil, 100, nil, nil, "", ""))
+	expectConstraints(mock, nil, nil)
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	// 表结构与模型一致时不执行任何语句
+	err = db.MigrateModel(context.Background(), &SchemaDiffTestModel{}, WithMigrationLog(false))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildSchemaPlan(t *testing.T) {
+	actual := &TableSchema{Name: "user", Columns: []*ColumnSchema{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true, AutoIncr: true},
+		{Name: "name", Type: "VARCHAR(100)", Nullable: true},
+		{Name: "score", Type: "INTEGER", Nullable: true, Default: "0"},
+		{Name: "legacy", Type: "TEXT", Nullable: true},
+	}}
+	desired := &TableSchema{Name: "user", Columns: []*ColumnSchema{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true, AutoIncr: true},
+		{Name: "name", Type: "VARCHAR(50)", Nullable: true},
+		{Name: "score", Type: "BIGINT", Nullable: true, Default: "1"},
+		{Name: "email", Type: "TEXT", Nullable: true},
+	}}
+
+	testCases := []struct {
+		name             string
+		dialect          schemaDialect
+		allowDestructive bool
+		wantSQL          []string
+	}{
+		{
+			name:    "postgresql safe only",
+			dialect: &Postgresql{},
+			wantSQL: []string{"ALTER TABLE \"user\"\n" +
+				"  ADD COLUMN \"email\" TEXT,\n" +
+				"  ALTER COLUMN \"score\" TYPE BIGINT USING \"score\"::BIGINT,\n" +
+				"  ALTER COLUMN \"score\" SET DEFAULT 1"},
+		},
+		{
+			name:             "postgresql allow destructive",
+			dialect:          &Postgresql{},
+			allowDestructive: true,
+			wantSQL: []string{"ALTER TABLE \"user\"\n" +
+				"  ADD COLUMN \"email\" TEXT,\n" +
+				"  ALTER COLUMN \"name\" TYPE VARCHAR(50) USING \"name\"::VARCHAR(50),\n" +
+				"  ALTER COLUMN \"score\" TYPE BIGINT USING \"score\"::BIGINT,\n" +
+				"  ALTER COLUMN \"score\" SET DEFAULT 1,\n" +
+				"  DROP COLUMN \"legacy\""},
+		},
+		{
+			name:    "mysql",
+			dialect: &Mysql{},
+			wantSQL: []string{"ALTER TABLE `user`\n" +
+				"  ADD COLUMN `email` TEXT NULL,\n" +
+				"  MODIFY COLUMN `score` BIGINT NULL DEFAULT 1"},
+		},
+		{
+			name:             "sqlite rebuild",
+			dialect:          &Sqlite{},
+			allowDestructive: true,
+			wantSQL: []string{
+				"CREATE TABLE \"user__new\" (\n" +
+					"  \"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n" +
+					"  \"name\" VARCHAR(50),\n" +
+					"  \"score\" BIGINT DEFAULT 1,\n" +
+					"  \"email\" TEXT\n" +
+					")",
+				"INSERT INTO \"user__new\" (\"id\", \"name\", \"score\") SELECT \"id\", \"name\", \"score\" FROM \"user\"",
+				"DROP TABLE \"user\"",
+				"ALTER TABLE \"user__new\" RENAME TO \"user\"",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := buildSchemaPlan(tc.dialect, desired, actual, tc.allowDestructive)
+			assert.Equal(t, tc.wantSQL, plan.Statements)
+		})
+	}
+}
+
+func TestBuildSchemaPlan_SqliteAddColumn(t *testing.T) {
+	actual := &TableSchema{Name: "user", Columns: []*ColumnSchema{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true, AutoIncr: true},
+		{Name: "legacy", Type: "TEXT", Nullable: true},
+	}}
+	desired := &TableSchema{Name: "user", Columns: []*ColumnSchema{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true, AutoIncr: true},
+		{Name: "status", Type: "INTEGER", Default: "0"},
+		{Name: "remark", Type: "TEXT", Nullable: true},
+	}}
+
+	// SQLite 每条语句只能添加或删除一列
+	plan := buildSchemaPlan(&Sqlite{}, desired, actual, true)
+	assert.Equal(t, []string{
+		"ALTER TABLE \"user\" ADD COLUMN \"status\" INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE \"user\" ADD COLUMN \"remark\" TEXT",
+		"ALTER TABLE \"user\" DROP COLUMN \"legacy\"",
+	}, plan.Statements)
+}
+
+func TestBuildSchemaPlan_PrimaryKey(t *testing.T) {
+	actual := &TableSchema{Name: "member", Columns: []*ColumnSchema{
+		{Name: "user_id", Type: "BIGINT", PrimaryKey: true},
+		{Name: "group_id", Type: "BIGINT"},
+	}}
+	desired := &TableSchema{Name: "member", Columns: []*ColumnSchema{
+		{Name: "user_id", Type: "BIGINT", PrimaryKey: true},
+		{Name: "group_id", Type: "BIGINT", PrimaryKey: true},
+	}}
+
+	// 修改主键是破坏性变更，默认不执行
+	plan := buildSchemaPlan(&Mysql{}, desired, actual, false)
+	assert.Empty(t, plan.Statements)
+	assert.Equal(t, []SchemaChange{{
+		Kind: ChangePrimaryKey, From: "user_id", To: "user_id, group_id",
+		Destructive: true, Reason: "重复的值会导致变更失败",
+	}}, plan.Changes)
+
+	plan = buildSchemaPlan(&Mysql{}, desired, actual, true)
+	assert.Equal(t, []string{"ALTER TABLE `member`\n  DROP PRIMARY KEY,\n  ADD PRIMARY KEY (`user_id`, `group_id`)"}, plan.Statements)
+
+	plan = buildSchemaPlan(&Postgresql{}, desired, actual, true)
+	assert.Equal(t, []string{"ALTER TABLE \"member\"\n  DROP CONSTRAINT \"member_pkey\",\n  ADD PRIMARY KEY (\"user_id\", \"group_id\")"}, plan.Statements)
+
+	// 模型没有声明主键时不比较主键
+	desired = &TableSchema{Name: "member", Columns: []*ColumnSchema{
+		{Name: "user_id", Type: "BIGINT"},
+		{Name: "group_id", Type: "BIGINT"},
+	}}
+	plan = buildSchemaPlan(&Mysql{}, desired, actual, true)
+	assert.True(t, plan.Empty())
+}
+
+func TestNormalizeType(t *testing.T) {
+	testCases := []struct {
+		dialect      schemaDialect
+		typ          string
+		wantType     string
+		wantAutoIncr bool
+	}{
+		{dialect: &Mysql{}, typ: "int(11)", wantType: "INT"},
+		{dialect: &Mysql{}, typ: "INT AUTO_INCREMENT", wantType: "INT", wantAutoIncr: true},
+		{dialect: &Mysql{}, typ: "int(10) unsigned", wantType: "INT UNSIGNED"},
+		{dialect: &Mysql{}, typ: "INTEGER UNSIGNED", wantType: "INT UNSIGNED"},
+		{dialect: &Mysql{}, typ: "tinyint(1)", wantType: "TINYINT(1)"},
+		{dialect: &Mysql{}, typ: "BOOLEAN", wantType: "TINYINT(1)"},
+		{dialect: &Mysql{}, typ: "decimal(10, 2)", wantType: "DECIMAL(10,2)"},
+		{dialect: &Postgresql{}, typ: "SERIAL", wantType: "INTEGER", wantAutoIncr: true},
+		{dialect: &Postgresql{}, typ: "character varying(255)", wantType: "VARCHAR(255)"},
+		{dialect: &Postgresql{}, typ: "timestamp with time zone", wantType: "TIMESTAMP WITH TIME ZONE"},
+		{dialect: &Postgresql{}, typ: "int8", wantType: "BIGINT"},
+		{dialect: &Postgresql{}, typ: "int4[]", wantType: "INTEGER[]"},
+		{dialect: &Postgresql{}, typ: "VARCHAR(64)[]", wantType: "VARCHAR[]"},
+		{dialect: &Postgresql{}, typ: "timestamptz[]", wantType: "TIMESTAMP WITH TIME ZONE[]"},
+		{dialect: &Postgresql{}, typ: "order_status", wantType: "ORDER_STATUS"},
+		{dialect: &Mysql{}, typ: "enum('Paid','refunded')", wantType: "ENUM('Paid','refunded')"},
+		{dialect: &Mysql{}, typ: "ENUM('Paid', 'it''s')", wantType: "ENUM('Paid','it''s')"},
+		{dialect: &Sqlite{}, typ: "INTEGER PRIMARY KEY AUTOINCREMENT", wantType: "INTEGER", wantAutoIncr: true},
+		{dialect: &Sqlite{}, typ: "text(255)", wantType: "TEXT(255)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			typ, autoIncr := tc.dialect.normalizeType(tc.typ)
+			assert.Equal(t, tc.wantType, typ)
+			assert.Equal(t, tc.wantAutoIncr, autoIncr)
+		})
+	}
+}
+
+func TestIsWideningType(t *testing.T) {
+	testCases := []struct {
+		from, to string
+		want     bool
+	}{
+		{from: "VARCHAR(100)", to: "VARCHAR(255)", want: true},
+		{from: "VARCHAR(255)", to: "VARCHAR(100)", want: false},
+		{from: "VARCHAR(255)", to: "TEXT", want: true},
+		{from: "TEXT", to: "VARCHAR(255)", want: false},
+		{from: "CHAR(10)", to: "VARCHAR(20)", want: true},
+		{from: "INT", to: "BIGINT", want: true},
+		{from: "BIGINT", to: "INT", want: false},
+		{from: "INT UNSIGNED", to: "BIGINT", want: true},
+		{from: "INT UNSIGNED", to: "INT", want: false},
+		{from: "INT", to: "INT UNSIGNED", want: false},
+		{from: "TINYINT(1)", to: "INT", want: true},
+		{from: "FLOAT", to: "DOUBLE", want: true},
+		{from: "DOUBLE", to: "FLOAT", want: false},
+		{from: "DECIMAL(10,2)", to: "DECIMAL(12,2)", want: true},
+		{from: "DECIMAL(10,2)", to: "DECIMAL(10,4)", want: false},
+		{from: "INT", to: "VARCHAR(20)", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+" -> "+tc.to, func(t *testing.T) {
+			assert.Equal(t, tc.want, isWideningType(tc.from, tc.to))
+		})
+	}
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	assert.Equal(t, "active", normalizeDefault("'active'::character varying"))
+	assert.Equal(t, "active", normalizeDefault("'active'"))
+	assert.Equal(t, "0", normalizeDefault("(0)"))
+	assert.Equal(t, "CURRENT_TIMESTAMP", normalizeDefault("current_timestamp()"))
+	assert.Equal(t, "CURRENT_TIMESTAMP", normalizeDefault("now()"))
+	assert.Equal(t, "", normalizeDefault("NULL"))
+	assert.Equal(t, "it's", normalizeDefault("'it''s'"))
+}
@@ -0,0 +1,288 @@
+package orm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
+)
+
+// IndexSchema 索引或唯一约束，不包含主键
+type IndexSchema struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// String 返回索引的列，唯一索引带 UNIQUE 前缀，例如 UNIQUE (name, email)
+func (i *IndexSchema) String() string {
+	s := "(" + strings.Join(i.Columns, ", ") + ")"
+	if i.Unique {
+		return "UNIQUE " + s
+	}
+	return s
+}
+
+// ForeignKeySchema 外键约束
+type ForeignKeySchema struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string // 删除规则，如 CASCADE、SET NULL，没有指定时为 NO ACTION
+	OnUpdate   string // 更新规则
+}
+
+// String 返回外键定义，例如 (company_id) REFERENCES company(id) ON DELETE CASCADE
+func (fk *ForeignKeySchema) String() string {
+	s := "(" + strings.Join(fk.Columns, ", ") + ") REFERENCES " + fk.RefTable + "(" + strings.Join(fk.RefColumns, ", ") + ")"
+	if fk.OnDelete != "NO ACTION" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "NO ACTION" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+// sameDefinition 两个外键的列、引用和规则都相同
+func (fk *ForeignKeySchema) sameDefinition(other *ForeignKeySchema) bool {
+	return equalStrings(fk.Columns, other.Columns) && fk.RefTable == other.RefTable &&
+		equalStrings(fk.RefColumns, other.RefColumns) &&
+		ruleKey(fk.OnDelete) == ruleKey(other.OnDelete) && ruleKey(fk.OnUpdate) == ruleKey(other.OnUpdate)
+}
+
+// Index 返回名为 name 的索引，不存在时返回 nil
+func (t *TableSchema) Index(name string) *IndexSchema {
+	for _, idx := range t.Indexes {
+		if idx.Name == name {
+			return idx
+		}
+	}
+	return nil
+}
+
+// ForeignKey 返回名为 name 的外键，不存在时返回 nil
+func (t *TableSchema) ForeignKey(name string) *ForeignKeySchema {
+	for _, fk := range t.ForeignKeys {
+		if fk.Name == name {
+			return fk
+		}
+	}
+	return nil
+}
+
+// fkRefRe 外键引用：表名(列名)，省略列名时引用 id
+var fkRefRe = regexp.MustCompile(`^([\w.]+)(?:\(([\w, ]+)\))?$`)
+
+// modelConstraints 按字段顺序收集模型的索引和外键。
+// 没有指定名称的索引命名为 idx_表名_列名，唯一约束为 uk_表名_列名，外键为 fk_表名_列名；
+// 多个字段使用同一个名称时组成联合索引，列按字段顺序排列
+func modelConstraints(table string, fields []*field) ([]*IndexSchema, []*ForeignKeySchema, error) {
+	var indexes []*IndexSchema
+	var foreignKeys []*ForeignKeySchema
+	byName := make(map[string]*IndexSchema)
+
+	addIndex := func(name, col string, unique bool) error {
+		if idx, ok := byName[name]; ok {
+			if idx.Unique != unique {
+				return ferr.ErrInvalidIndex(name)
+			}
+			idx.Columns = append(idx.Columns, col)
+			return nil
+		}
+		idx := &IndexSchema{Name: name, Columns: []string{col}, Unique: unique}
+		byName[name] = idx
+		indexes = append(indexes, idx)
+		return nil
+	}
+
+	for _, f := range fields {
+		if f.unique {
+			name := f.uniqueName
+			if name == "" {
+				name = "uk_" + table + "_" + f.colName
+			}
+			if err := addIndex(name, f.colName, true); err != nil {
+				return nil, nil, err
+			}
+		}
+		if f.index {
+			name := f.indexName
+			if name == "" {
+				name = "idx_" + table + "_" + f.colName
+			}
+			if err := addIndex(name, f.colName, false); err != nil {
+				return nil, nil, err
+			}
+		}
+		if f.fk == "" {
+			continue
+		}
+
+		match := fkRefRe.FindStringSubmatch(f.fk)
+		if match == nil {
+			return nil, nil, ferr.ErrInvalidTag("fk:" + f.fk)
+		}
+		refCols := []string{"id"}
+		if match[2] != "" {
+			refCols = strings.Split(strings.ReplaceAll(match[2], " ", ""), ",")
+		}
+		onDelete, ok := normalizeRule(f.onDelete)
+		if !ok {
+			return nil, nil, ferr.ErrInvalidTag("on_delete:" + f.onDelete)
+		}
+		onUpdate, ok := normalizeRule(f.onUpdate)
+		if !ok {
+			return nil, nil, ferr.ErrInvalidTag("on_update:" + f.onUpdate)
+		}
+		foreignKeys = append(foreignKeys, &ForeignKeySchema{
+			Name:       "fk_" + table + "_" + f.colName,
+			Columns:    []string{f.colName},
+			RefTable:   match[1],
+			RefColumns: refCols,
+			OnDelete:   onDelete,
+			OnUpdate:   onUpdate,
+		})
+	}
+	return indexes, foreignKeys, nil
+}
+
+// normalizeRule 规范化外键的删除和更新规则，标签中可以写 cascade、set_null 等
+func normalizeRule(rule string) (string, bool) {
+	rule = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(rule, "_", " ")))
+	switch rule {
+	case "":
+		return "NO ACTION", true
+	case "CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION":
+		return rule, true
+	}
+	return rule, false
+}
+
+// ruleKey 比较规则时 RESTRICT 和 NO ACTION 视为相同，MySQL 中两者没有区别
+func ruleKey(rule string) string {
+	if rule == "RESTRICT" || rule == "" {
+		return "NO ACTION"
+	}
+	return rule
+}
+
+// diffConstraints 比较索引和外键，返回需要先执行的删除和需要最后执行的添加。
+// 索引按名称比较；外键按定义比较，SQLite 不保存外键的名称。
+// 模型中没有声明的索引和外键可能是手动创建的，删除属于破坏性变更；
+// 同名但定义变化的索引和外键需要先删除再添加，删除与添加一起执行或跳过
+func diffConstraints(desired, actual *TableSchema) (drops, adds []SchemaChange) {
+	var fkDrops, idxDrops, idxAdds, fkAdds []SchemaChange
+
+	for _, want := range desired.Indexes {
+		have := actual.Index(want.Name)
+		if have != nil && have.Unique == want.Unique && equalStrings(have.Columns, want.Columns) {
+			continue
+		}
+		c := SchemaChange{Kind: ChangeAddIndex, Name: want.Name, To: want.String()}
+		if want.Unique {
+			c.Destructive = true
+			c.Reason = "表中已有重复的值时会失败"
+		}
+		idxAdds = append(idxAdds, c)
+		if have != nil {
+			idxDrops = append(idxDrops, SchemaChange{
+				Kind: ChangeDropIndex, Name: have.Name, From: have.String(),
+				Destructive: c.Destructive, Reason: "索引定义变化，需要重建",
+			})
+		}
+	}
+	for _, have := range actual.Indexes {
+		if desired.Index(have.Name) == nil {
+			idxDrops = append(idxDrops, SchemaChange{
+				Kind: ChangeDropIndex, Name: have.Name, From: have.String(),
+				Destructive: true, Reason: "模型中没有声明，可能是手动创建的",
+			})
+		}
+	}
+
+	matched := make(map[*ForeignKeySchema]bool)
+	for _, want := range desired.ForeignKeys {
+		found := false
+		for _, have := range actual.ForeignKeys {
+			if !matched[have] && want.sameDefinition(have) {
+				matched[have], found = true, true
+				break
+			}
+		}
+		if !found {
+			fkAdds = append(fkAdds, SchemaChange{
+				Kind: ChangeAddForeignKey, Name: want.Name, To: want.String(),
+				Destructive: true, Reason: "表中已有不满足约束的数据时会失败",
+			})
+		}
+	}
+	for _, have := range actual.ForeignKeys {
+		if matched[have] {
+			continue
+		}
+		reason := "模型中没有声明，可能是手动创建的"
+		if desired.ForeignKey(have.Name) != nil {
+			reason = "外键定义变化，需要重建"
+		}
+		fkDrops = append(fkDrops, SchemaChange{
+			Kind: ChangeDropForeignKey, Name: have.Name, From: have.String(),
+			Destructive: true, Reason: reason,
+		})
+	}
+
+	return append(fkDrops, idxDrops...), append(idxAdds, fkAdds...)
+}
+
+// joinQuoted 引用名称并以逗号分隔
+func joinQuoted(quote func(string) string, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// foreignKeyClause 生成 CREATE TABLE 和 ALTER TABLE ADD 使用的外键约束
+func foreignKeyClause(quote func(string) string, fk *ForeignKeySchema) string {
+	s := "CONSTRAINT " + quote(fk.Name) + " FOREIGN KEY (" + joinQuoted(quote, fk.Columns) + ") REFERENCES " +
+		quote(fk.RefTable) + " (" + joinQuoted(quote, fk.RefColumns) + ")"
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+// createIndexSQL 生成 CREATE INDEX 语句，用于不支持在建表语句中定义索引的 PostgreSQL 和 SQLite
+func createIndexSQL(quote func(string) string, table string, idx *IndexSchema) string {
+	s := "CREATE INDEX "
+	if idx.Unique {
+		s = "CREATE UNIQUE INDEX "
+	}
+	return s + quote(idx.Name) + " ON " + quote(table) + " (" + joinQuoted(quote, idx.Columns) + ")"
+}
+
+// appendIndexSQL 在建表语句后追加创建索引的语句
+func appendIndexSQL(quote func(string) string, createSQL string, m *model) string {
+	var sb strings.Builder
+	sb.WriteString(createSQL)
+	sb.WriteString(";")
+	for _, idx := range m.indexes {
+		sb.WriteString("\n")
+		sb.WriteString(createIndexSQL(quote, m.table, idx))
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+func describeIndex(c SchemaChange) string {
+	if strings.HasPrefix(c.To, "UNIQUE") {
+		return fmt.Sprintf("添加唯一索引 %s %s", c.Name, strings.TrimPrefix(c.To, "UNIQUE "))
+	}
+	return fmt.Sprintf("添加索引 %s %s", c.Name, c.To)
+}
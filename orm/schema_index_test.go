@@ -0,0 +1,292 @@
+package orm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func indexRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "UNIQUE"})
+}
+
+func foreignKeyRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME",
+		"REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE"})
+}
+
+// expectConstraints 设置读取 MySQL 表上索引和外键的预期，nil 表示没有
+func expectConstraints(mock sqlmock.Sqlmock, indexes, foreignKeys *sqlmock.Rows) {
+	if indexes == nil {
+		indexes = indexRows()
+	}
+	if foreignKeys == nil {
+		foreignKeys = foreignKeyRows()
+	}
+	mock.ExpectQuery(".*FROM INFORMATION_SCHEMA.STATISTICS.*").WillReturnRows(indexes)
+	mock.ExpectQuery(".*FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE.*").WillReturnRows(foreignKeys)
+}
+
+type IndexTestCompany struct {
+	ID int `orm:"primary_key;auto_increment"`
+}
+
+type IndexTestUser struct {
+	ID        int    `orm:"primary_key;auto_increment"`
+	Name      string `orm:"size:100;index:idx_name_email"`
+	Email     string `orm:"size:100;index:idx_name_email;unique"`
+	TenantID  int    `orm:"unique:uk_tenant_code"`
+	Code      string `orm:"size:20;unique:uk_tenant_code"`
+	CompanyID int    `orm:"fk:index_test_company(id) on_delete:cascade"`
+	ManagerID int    `orm:"nullable;fk:index_test_user;on_delete:set_null;on_update:restrict"`
+}
+
+func TestModelConstraints(t *testing.T) {
+	m, err := parseModel(&IndexTestUser{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*IndexSchema{
+		{Name: "idx_name_email", Columns: []string{"name", "email"}},
+		{Name: "uk_index_test_user_email", Columns: []string{"email"}, Unique: true},
+		{Name: "uk_tenant_code", Columns: []string{"tenant_id", "code"}, Unique: true},
+	}, m.indexes)
+	assert.Equal(t, []*ForeignKeySchema{
+		{
+			Name: "fk_index_test_user_company_id", Columns: []string{"company_id"},
+			RefTable: "index_test_company", RefColumns: []string{"id"},
+			OnDelete: "CASCADE", OnUpdate: "NO ACTION",
+		},
+		{
+			Name: "fk_index_test_user_manager_id", Columns: []string{"manager_id"},
+			RefTable: "index_test_user", RefColumns: []string{"id"},
+			OnDelete: "SET NULL", OnUpdate: "RESTRICT",
+		},
+	}, m.foreignKeys)
+}
+
+func TestModelConstraints_Invalid(t *testing.T) {
+	type conflict struct {
+		A int `orm:"index:idx_ab"`
+		B int `orm:"unique:idx_ab"`
+	}
+	_, err := parseModel(&conflict{})
+	assert.ErrorContains(t, err, "idx_ab")
+
+	type badRef struct {
+		A int `orm:"fk:users(id"`
+	}
+	_, err = parseModel(&badRef{})
+	assert.ErrorContains(t, err, "invalid tag")
+
+	type badRule struct {
+		A int `orm:"fk:users(id) on_delete:explode"`
+	}
+	_, err = parseModel(&badRule{})
+	assert.ErrorContains(t, err, "invalid tag")
+
+	type badOption struct {
+		A int `orm:"fk:users(id) size:10"`
+	}
+	_, err = parseModel(&badOption{})
+	assert.ErrorContains(t, err, "invalid tag")
+}
+
+func TestCreateTableSQL_Constraints(t *testing.T) {
+	m, err := parseModel(&IndexTestUser{})
+	require.NoError(t, err)
+
+	mysql := Get("mysql").CreateTableSQL(m)
+	assert.Contains(t, mysql, "KEY `idx_name_email` (`name`, `email`)")
+	assert.Contains(t, mysql, "UNIQUE KEY `uk_tenant_code` (`tenant_id`, `code`)")
+	assert.Contains(t, mysql, "CONSTRAINT `fk_index_test_user_company_id` FOREIGN KEY (`company_id`) "+
+		"REFERENCES `index_test_company` (`id`) ON DELETE CASCADE")
+	assert.Contains(t, mysql, "CONSTRAINT `fk_index_test_user_manager_id` FOREIGN KEY (`manager_id`) "+
+		"REFERENCES `index_test_user` (`id`) ON DELETE SET NULL ON UPDATE RESTRICT")
+
+	for _, name := range []string{"postgresql", "sqlite"} {
+		t.Run(name, func(t *testing.T) {
+			ddl := Get(name).CreateTableSQL(m)
+			assert.NotContains(t, ddl, "KEY \"idx")
+			assert.Contains(t, ddl, "CONSTRAINT \"fk_index_test_user_company_id\" FOREIGN KEY (\"company_id\") "+
+				"REFERENCES \"index_test_company\" (\"id\") ON DELETE CASCADE")
+			assert.True(t, strings.HasSuffix(ddl, "\n);\n"+
+				"CREATE INDEX \"idx_name_email\" ON \"index_test_user\" (\"name\", \"email\");\n"+
+				"CREATE UNIQUE INDEX \"uk_index_test_user_email\" ON \"index_test_user\" (\"email\");\n"+
+				"CREATE UNIQUE INDEX \"uk_tenant_code\" ON \"index_test_user\" (\"tenant_id\", \"code\");"), ddl)
+
+			plan := createPlan(m.table, ddl)
+			assert.Len(t, plan.Statements, 4)
+		})
+	}
+}
+
+// constraintSchemas 数据库中的表：已有 name 单列索引、手动创建的索引和一个外键，缺少联合索引
+func constraintSchemas() (desired, actual *TableSchema) {
+	cols := func() []*ColumnSchema {
+		return []*ColumnSchema{
+			{Name: "id", Type: "INT", PrimaryKey: true, AutoIncr: true},
+			{Name: "name", Type: "VARCHAR(100)", Nullable: true},
+			{Name: "email", Type: "VARCHAR(100)", Nullable: true},
+			{Name: "company_id", Type: "INT"},
+		}
+	}
+	desired = &TableSchema{
+		Name:    "user",
+		Columns: cols(),
+		Indexes: []*IndexSchema{
+			{Name: "idx_name_email", Columns: []string{"name", "email"}},
+			{Name: "uk_user_email", Columns: []string{"email"}, Unique: true},
+		},
+		ForeignKeys: []*ForeignKeySchema{{
+			Name: "fk_user_company_id", Columns: []string{"company_id"},
+			RefTable: "company", RefColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION",
+		}},
+	}
+	actual = &TableSchema{
+		Name:    "user",
+		Columns: cols(),
+		Indexes: []*IndexSchema{
+			{Name: "idx_name_email", Columns: []string{"name"}},
+			{Name: "idx_manual", Columns: []string{"company_id"}},
+		},
+		ForeignKeys: []*ForeignKeySchema{{
+			Name: "fk_user_company_id", Columns: []string{"company_id"},
+			RefTable: "company", RefColumns: []string{"id"}, OnDelete: "NO ACTION", OnUpdate: "RESTRICT",
+		}},
+	}
+	return desired, actual
+}
+
+func TestBuildSchemaPlan_Constraints(t *testing.T) {
+	desired, actual := constraintSchemas()
+
+	plan := buildSchemaPlan(Get("mysql").(schemaDialect), desired, actual, false)
+	assert.Equal(t, []SchemaChange{
+		{Kind: ChangeDropIndex, Name: "idx_name_email", From: "(name)", Reason: "索引定义变化，需要重建"},
+		{Kind: ChangeAddIndex, Name: "idx_name_email", To: "(name, email)"},
+	}, plan.Safe())
+	var kinds []SchemaChangeKind
+	for _, c := range plan.Destructive() {
+		kinds = append(kinds, c.Kind)
+	}
+	assert.Equal(t, []SchemaChangeKind{ChangeDropForeignKey, ChangeDropIndex, ChangeAddIndex, ChangeAddForeignKey}, kinds)
+	assert.Equal(t, []string{"ALTER TABLE `user`\n" +
+		"  DROP INDEX `idx_name_email`,\n" +
+		"  ADD INDEX `idx_name_email` (`name`, `email`)"}, plan.Statements)
+	assert.Contains(t, plan.String(), "添加唯一索引 uk_user_email (email)（表中已有重复的值时会失败）")
+	assert.Contains(t, plan.String(), "删除外键 fk_user_company_id（外键定义变化，需要重建）")
+
+	testCases := []struct {
+		dialect string
+		want    []string
+	}{
+		{
+			dialect: "mysql",
+			want: []string{
+				"ALTER TABLE `user`\n  DROP FOREIGN KEY `fk_user_company_id`",
+				"ALTER TABLE `user`\n" +
+					"  DROP INDEX `idx_name_email`,\n" +
+					"  DROP INDEX `idx_manual`,\n" +
+					"  ADD INDEX `idx_name_email` (`name`, `email`),\n" +
+					"  ADD UNIQUE INDEX `uk_user_email` (`email`),\n" +
+					"  ADD CONSTRAINT `fk_user_company_id` FOREIGN KEY (`company_id`) REFERENCES `company` (`id`) ON DELETE CASCADE",
+			},
+		},
+		{
+			dialect: "postgresql",
+			want: []string{
+				`DROP INDEX "idx_name_email"`,
+				`DROP INDEX "idx_manual"`,
+				"ALTER TABLE \"user\"\n" +
+					"  DROP CONSTRAINT \"fk_user_company_id\",\n" +
+					"  ADD CONSTRAINT \"fk_user_company_id\" FOREIGN KEY (\"company_id\") REFERENCES \"company\" (\"id\") ON DELETE CASCADE",
+				`CREATE INDEX "idx_name_email" ON "user" ("name", "email")`,
+				`CREATE UNIQUE INDEX "uk_user_email" ON "user" ("email")`,
+			},
+		},
+		{
+			// 修改外键需要重建表，重建后重新创建索引
+			dialect: "sqlite",
+			want: []string{
+				"CREATE TABLE \"user__new\" (\n" +
+					"  \"id\" INT PRIMARY KEY AUTOINCREMENT,\n" +
+					"  \"name\" VARCHAR(100),\n" +
+					"  \"email\" VARCHAR(100),\n" +
+					"  \"company_id\" INT NOT NULL,\n" +
+					"  CONSTRAINT \"fk_user_company_id\" FOREIGN KEY (\"company_id\") REFERENCES \"company\" (\"id\") ON DELETE CASCADE\n" +
+					")",
+				`INSERT INTO "user__new" ("id", "name", "email", "company_id") SELECT "id", "name", "email", "company_id" FROM "user"`,
+				`DROP TABLE "user"`,
+				`ALTER TABLE "user__new" RENAME TO "user"`,
+				`CREATE INDEX "idx_name_email" ON "user" ("name", "email")`,
+				`CREATE UNIQUE INDEX "uk_user_email" ON "user" ("email")`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			desired, actual := constraintSchemas()
+			plan := buildSchemaPlan(Get(tc.dialect).(schemaDialect), desired, actual, true)
+			assert.Equal(t, tc.want, plan.Statements)
+		})
+	}
+}
+
+func TestBuildSchemaPlan_ConstraintsUnchanged(t *testing.T) {
+	desired, actual := constraintSchemas()
+	actual.Indexes = []*IndexSchema{
+		{Name: "uk_user_email", Columns: []string{"email"}, Unique: true},
+		{Name: "idx_name_email", Columns: []string{"name", "email"}},
+	}
+	// RESTRICT 与 NO ACTION 视为相同
+	actual.ForeignKeys[0].OnDelete = "CASCADE"
+
+	plan := buildSchemaPlan(Get("postgresql").(schemaDialect), desired, actual, true)
+	assert.True(t, plan.Empty(), plan.String())
+}
+
+func TestPlanMigration_ReadConstraints(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM information_schema.tables WHERE table_name = 'index_test_user'")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(".*FROM.*INFORMATION_SCHEMA.COLUMNS.*").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
+			"CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", "NUMERIC_SCALE", "COLUMN_KEY", "EXTRA"}).
+			AddRow("id", "int", "NO", nil, nil, 10, 0, "PRI", "auto_increment").
+			AddRow("name", "varchar(100)", "YES", nil, 100, nil, nil, "MUL", "").
+			AddRow("email", "varchar(100)", "YES", nil, 100, nil, nil, "UNI", "").
+			AddRow("tenant_id", "int", "YES", nil, nil, 10, 0, "MUL", "").
+			AddRow("code", "varchar(20)", "YES", nil, 20, nil, nil, "", "").
+			AddRow("company_id", "int", "YES", nil, nil, 10, 0, "MUL", "").
+			AddRow("manager_id", "int", "YES", nil, nil, 10, 0, "MUL", ""))
+	// MySQL 为外键自动创建的同名索引不参与比较
+	expectConstraints(mock,
+		indexRows().
+			AddRow("fk_index_test_user_company_id", "company_id", false).
+			AddRow("fk_index_test_user_manager_id", "manager_id", false).
+			AddRow("idx_name_email", "name", false).
+			AddRow("idx_name_email", "email", false).
+			AddRow("uk_index_test_user_email", "email", true).
+			AddRow("uk_tenant_code", "tenant_id", true).
+			AddRow("uk_tenant_code", "code", true),
+		foreignKeyRows().
+			AddRow("fk_index_test_user_company_id", "company_id", "index_test_company", "id", "CASCADE", "NO ACTION").
+			AddRow("fk_index_test_user_manager_id", "manager_id", "index_test_user", "id", "SET NULL", "RESTRICT"))
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	plan, err := db.PlanMigration(context.Background(), &IndexTestUser{})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
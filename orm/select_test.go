@@ -7,6 +7,8 @@ import (
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -990,6 +992,72 @@ func TestSelector_Build_NewOperators(t *testing.T) {
 				Args: []any{18, 35},
 			},
 		},
+		{
+			name: "is not null",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Job").IsNotNull()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `job` IS NOT NULL;",
+			},
+		},
+		{
+			name: "in slice",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").In([]int{1, 2, 3})),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?, ?, ?);",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			name: "in bytes",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Name").In([]byte("Tom"))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `name` IN (?);",
+				Args: []any{[]byte("Tom")},
+			},
+		},
+		{
+			name: "in empty",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").In([]int{}), Col("Age").Gt(18)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE 1 = 0 AND `age` > ?;",
+				Args: []any{18},
+			},
+		},
+		{
+			name: "not in empty",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").NotIn()),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE 1 = 1;",
+			},
+		},
+		{
+			name: "in subquery",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Age").Gt(18),
+					Col("ID").In(RegisterSelector[Order](db).
+						Select(Col("UserID")).
+						Where(Col("Amount").Gt(100)))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `age` > ? AND " +
+					"`id` IN (SELECT `user_id` FROM `order` WHERE `amount` > ?);",
+				Args: []any{18, 100},
+			},
+		},
+		{
+			name: "not in subquery",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").NotIn(RegisterSelector[Order](db).
+					Select(Col("UserID")).
+					AsSubQuery("t"))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `id` NOT IN (SELECT `user_id` FROM `order`);",
+			},
+		},
 		{
 			name: "complex query with multiple operators",
 			q: RegisterSelector[TestModel2](db).Select().
@@ -1013,4 +1081,357 @@ func TestSelector_Build_NewOperators(t *testing.T) {
 			assert.Equal(t, tc.wantQuery, query)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSelector_Build_OperatorsPostgres(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		q         *Selector[TestModel2]
+		wantQuery *Query
+	}{
+		{
+			name: "in and between",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").In(1, 2, 3),
+					Col("Age").Between(18, 35),
+					Col("Name").Like("Tom%")),
+			wantQuery: &Query{
+				SQL: `SELECT * FROM "test_model" WHERE "id" IN ($1, $2, $3) AND ` +
+					`"age" BETWEEN $4 AND $5 AND "name" LIKE $6;`,
+				Args: []any{1, 2, 3, 18, 35, "Tom%"},
+			},
+		},
+		{
+			name: "in subquery",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Age").Gt(18),
+					Col("ID").In(RegisterSelector[Order](db).
+						Select(Col("UserID")).
+						Where(Col("Amount").Gt(100), Col("Status").In(1, 2))),
+					Col("Name").NotIn("Tom", "Jerry")),
+			wantQuery: &Query{
+				SQL: `SELECT * FROM "test_model" WHERE "age" > $1 AND ` +
+					`"id" IN (SELECT "user_id" FROM "order" WHERE "amount" > $2 AND "status" IN ($3, $4)) AND ` +
+					`"name" NOT IN ($5, $6);`,
+				Args: []any{18, 100, 1, 2, "Tom", "Jerry"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Build_Logical(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		q         *Selector[TestModel2]
+		wantQuery *Query
+	}{
+		{
+			name: "or",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Or(Col("Age").Lt(18), Col("Age").Gt(60))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (`age` < ? OR `age` > ?);",
+				Args: []any{18, 60},
+			},
+		},
+		{
+			name: "or with other conditions",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Job").NotNull(), Or(Col("Name").Eq("Tom"), Col("Name").Eq("Jerry"), Col("ID").In(1, 2))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `job` IS NOT NULL AND (`name` = ? OR `name` = ? OR `id` IN (?, ?));",
+				Args: []any{"Tom", "Jerry", 1, 2},
+			},
+		},
+		{
+			name: "and inside or",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Or(
+					And(Col("Name").Eq("Tom"), Col("Age").Gt(18)),
+					Col("ID").Eq(1),
+				)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE ((`name` = ? AND `age` > ?) OR `id` = ?);",
+				Args: []any{"Tom", 18, 1},
+			},
+		},
+		{
+			name: "or inside and",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(And(
+					Col("Age").Gt(18),
+					Or(Col("Name").Like("T%"), Col("Name").Like("J%")),
+					Col("Job").IsNull(),
+				)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` > ? AND (`name` LIKE ? OR `name` LIKE ?) AND `job` IS NULL;",
+				Args: []any{18, "T%", "J%"},
+			},
+		},
+		{
+			name: "method chaining",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").Eq(1).Or(Col("ID").Eq(2)).Or(Col("ID").Eq(3))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (`id` = ? OR `id` = ? OR `id` = ?);",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			name: "not or",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(NOT(Or(Col("Name").Eq("Tom"), Col("Age").Lt(18)))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE NOT (`name` = ? OR `age` < ?);",
+				Args: []any{"Tom", 18},
+			},
+		},
+		{
+			name: "single predicate",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Or(Col("ID").Eq(1))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` = ?;",
+				Args: []any{1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+
+	assert.Panics(t, func() { Or() })
+}
+
+func TestSelector_Union(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	pg, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+	}{
+		{
+			name: "union",
+			q: RegisterSelector[TestModel2](db).Select(Col("ID")).Where(Col("Age").Lt(18)).
+				Union(RegisterSelector[TestModel2](db).Select(Col("ID")).Where(Col("Age").Gt(60))),
+			wantQuery: &Query{
+				SQL: "SELECT `id` FROM `test_model` WHERE `age` < ? UNION " +
+					"SELECT `id` FROM `test_model` WHERE `age` > ?;",
+				Args: []any{18, 60},
+			},
+		},
+		{
+			name: "union all with order by",
+			q: RegisterSelector[TestModel2](db).Select(Col("ID"), Col("Name")).
+				UnionAll(RegisterSelector[Order](db).Select(Col("ID"), Col("OrderNo")).Where(Col("Status").Eq(1))).
+				UnionAll(RegisterSelector[Order](db).Select(Col("ID"), Col("OrderNo")).Where(Col("Status").Eq(2))).
+				Limit(10),
+			wantQuery: &Query{
+				SQL: "SELECT `id`, `name` FROM `test_model` UNION ALL " +
+					"SELECT `id`, `order_no` FROM `order` WHERE `status` = ? UNION ALL " +
+					"SELECT `id`, `order_no` FROM `order` WHERE `status` = ? LIMIT 10;",
+				Args: []any{1, 2},
+			},
+		},
+		{
+			name: "union as subquery",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("ID").In(RegisterSelector[Order](db).Select(Col("UserID")).Where(Col("Status").Eq(1)).
+					Union(RegisterSelector[Order](db).Select(Col("UserID")).Where(Col("Amount").Gt(100))))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `id` IN (" +
+					"SELECT `user_id` FROM `order` WHERE `status` = ? UNION " +
+					"SELECT `user_id` FROM `order` WHERE `amount` > ?);",
+				Args: []any{1, 100},
+			},
+		},
+		{
+			name: "postgresql placeholders",
+			q: RegisterSelector[TestModel2](pg).Select(Col("ID")).Where(Col("Age").Between(18, 30)).
+				Union(RegisterSelector[Order](pg).Select(Col("UserID")).Where(Col("Status").In(1, 2))).
+				Union(RegisterSelector[Order](pg).Select(Col("UserID")).Where(Col("Amount").Gt(100))),
+			wantQuery: &Query{
+				SQL: `SELECT "id" FROM "test_model" WHERE "age" BETWEEN $1 AND $2 UNION ` +
+					`SELECT "user_id" FROM "order" WHERE "status" IN ($3, $4) UNION ` +
+					`SELECT "user_id" FROM "order" WHERE "amount" > $5;`,
+				Args: []any{18, 30, 1, 2, 100},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Exists(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	pg, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		q         *Selector[TestModel2]
+		wantQuery *Query
+	}{
+		{
+			name: "exists",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(Col("Age").Gt(18), Exists(RegisterSelector[Order](db).Select(Raw("1")).
+					Where(Col("UserID").Eq(FromTable("test_model", Col("ID"))), Col("Amount").Gt(100)))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE `age` > ? AND EXISTS (" +
+					"SELECT 1 FROM `order` WHERE `user_id` = `test_model`.`id` AND `amount` > ?);",
+				Args: []any{18, 100},
+			},
+		},
+		{
+			name: "not exists",
+			q: RegisterSelector[TestModel2](db).Select().
+				Where(NotExists(RegisterSelector[Order](db).Select(Raw("1")).
+					Where(Col("UserID").Eq(FromTable("test_model", Col("ID")))))),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE NOT EXISTS (" +
+					"SELECT 1 FROM `order` WHERE `user_id` = `test_model`.`id`);",
+			},
+		},
+		{
+			name: "exists in or",
+			q: RegisterSelector[TestModel2](pg).Select().
+				Where(Or(Col("Name").Eq("Tom"), Exists(RegisterSelector[Order](pg).Select(Raw("1")).
+					Where(Col("Status").Eq(1)))), Col("Age").Lt(60)),
+			wantQuery: &Query{
+				SQL: `SELECT * FROM "test_model" WHERE ("name" = $1 OR EXISTS (` +
+					`SELECT 1 FROM "order" WHERE "status" = $2)) AND "age" < $3;`,
+				Args: []any{"Tom", 1, 60},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestSelector_Clone(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	base := RegisterSelector[TestModel2](db).Select().Where(Col("Age").Gt(18))
+
+	adults := base.Clone().Limit(10).Offset(20)
+	named := base.Clone().OrderBy(Asc(Col("ID")))
+
+	q, err := adults.Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  `SELECT * FROM "test_model" WHERE "age" > $1 LIMIT 10 OFFSET 20;`,
+		Args: []any{18},
+	}, q)
+
+	q, err = named.Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  `SELECT * FROM "test_model" WHERE "age" > $1 ORDER BY "id";`,
+		Args: []any{18},
+	}, q)
+
+	// 原选择器不受影响，并且可以多次 Build
+	for j := 0; j < 2; j++ {
+		q, err = base.Build()
+		require.NoError(t, err)
+		assert.Equal(t, &Query{
+			SQL:  `SELECT * FROM "test_model" WHERE "age" > $1;`,
+			Args: []any{18},
+		}, q)
+	}
+
+	// Build 之后可以继续追加子句
+	q, err = base.Limit(1).Build()
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "test_model" WHERE "age" > $1 LIMIT 1;`, q.SQL)
+}
+
+func TestSelector_ConcurrentBuild(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	// 同一个模型的选择器并发构建时占位符序号和 FROM 子句互不影响
+	var wg sync.WaitGroup
+	for j := 0; j < 50; j++ {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			var q *Query
+			var err error
+			if j%2 == 0 {
+				q, err = RegisterSelector[TestModel2](db).Select().
+					Where(Col("ID").In(j, j+1), Col("Age").Gt(j)).Build()
+				require.NoError(t, err)
+				assert.Equal(t, `SELECT * FROM "test_model" WHERE "id" IN ($1, $2) AND "age" > $3;`, q.SQL)
+			} else {
+				q, err = RegisterSelector[Order](db).Select(Col("ID")).
+					Where(Col("Amount").Gt(j)).Build()
+				require.NoError(t, err)
+				assert.Equal(t, `SELECT "id" FROM "order" WHERE "amount" > $1;`, q.SQL, strconv.Itoa(j))
+			}
+		}(j)
+	}
+	wg.Wait()
+}
@@ -3,7 +3,6 @@ package orm
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -14,8 +13,6 @@ import (
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 )
 
-var sqlWithFrom = ""
-
 type Selector[T any] struct {
 	builder       *strings.Builder
 	model         *model
@@ -25,6 +22,9 @@ type Selector[T any] struct {
 	delayCols     []*Column                   // 延迟处理的子查询列
 	args          []any
 	layer         Layer
+	selectEnd     int    // SELECT 列表结束的位置，分页时在此追加窗口函数
+	fromClause    string // Select 写入的默认 FROM 子句，From 指定其他表时替换
+	hasLimit      bool   // 已经写入 LIMIT，只有 OFFSET 时部分方言需要补上 LIMIT
 
 	// 缓存相关字段
 	useCache  bool          // 是否使用缓存
@@ -58,6 +58,19 @@ func (s *Selector[T]) WithCacheTags(tags ...string) *Selector[T] {
 	return s
 }
 
+// cacheLoad 使用选择器或模型的缓存设置
+func (s *Selector[T]) cacheLoad(cm *CacheManager, key string) cacheLoad {
+	ttl := s.cacheTTL
+	if ttl <= 0 {
+		ttl = cm.GetTTL(s.model.GetTableName())
+	}
+	tags := s.cacheTags
+	if len(tags) == 0 {
+		tags = cm.GetTags(s.model.GetTableName())
+	}
+	return cacheLoad{key: key, model: s.model.GetTableName(), ttl: ttl, tags: tags, layer: cm.GetLayer(s.model.GetTableName())}
+}
+
 func RegisterSelector[T any](layer Layer) *Selector[T] {
 	var val T
 
@@ -77,6 +90,9 @@ func RegisterSelector[T any](layer Layer) *Selector[T] {
 		}
 	}
 
+	// 缓存的模型被多个构造器共享，每个构造器使用自己的副本
+	m = m.clone()
+
 	// 处理表名
 	if tablename, ok := any(val).(TableNamer); ok {
 		m.table = tablename.TableName()
@@ -97,11 +113,39 @@ func RegisterSelector[T any](layer Layer) *Selector[T] {
 	}
 }
 
+// Clone 复制当前已经构建的部分，返回的选择器与原选择器互不影响，
+// 可以在公共的查询条件上分别追加不同的子句，也可以交给其他goroutine继续构建和执行
+//
+//	base := RegisterSelector[User](db).Select().Where(Col("Age").Gt(18))
+//	first, err := base.Clone().OrderBy(Asc(Col("ID"))).Get(ctx)
+//	users, total, err := base.Clone().Paginate(ctx, 1, 20)
+func (s *Selector[T]) Clone() *Selector[T] {
+	c := *s
+	c.builder = &strings.Builder{}
+	c.builder.WriteString(s.builder.String())
+	c.model = s.model.clone()
+	c.args = append([]any(nil), s.args...)
+	c.cols = append([]string(nil), s.cols...)
+	c.delayCols = append([]*Column(nil), s.delayCols...)
+	c.cacheTags = append([]string(nil), s.cacheTags...)
+	return &c
+}
+
+// tableModel 获取 FromTable 引用的结构体的模型副本
+func (s *Selector[T]) tableModel(tableStruct any) *model {
+	m, err := s.layer.getModel(tableStruct)
+	if err != nil {
+		panic(err)
+	}
+	return m.clone()
+}
+
 func (s *Selector[T]) Select(cols ...Selectable) *Selector[T] {
-	sqlWithFrom = "FROM " + s.dialect.Quote(s.model.table)
+	s.fromClause = "FROM " + s.dialect.Quote(s.model.table)
 	if cols == nil {
 		s.builder.WriteString("SELECT * ")
-		s.builder.WriteString(sqlWithFrom)
+		s.selectEnd = s.builder.Len()
+		s.builder.WriteString(s.fromClause)
 		return s
 	}
 
@@ -113,11 +157,7 @@ func (s *Selector[T]) Select(cols ...Selectable) *Selector[T] {
 			// 注意：子查询传入的是字符串、并且col的table名称已经设置好，这种情况不需要解析，等到延迟验证那步再验证就行
 			if col.table == "" {
 				if col.tableStruct != nil {
-					var err error
-					col.fromModel, err = s.layer.getModel(col.tableStruct)
-					if err != nil {
-						panic(err)
-					}
+					col.fromModel = s.tableModel(col.tableStruct)
 					col.table = col.fromModel.table
 				} else {
 					// 注入模型信息
@@ -148,21 +188,21 @@ func (s *Selector[T]) Select(cols ...Selectable) *Selector[T] {
 			}
 			s.builder.WriteByte(' ')
 		case RawExpr:
-			col.Build(s.builder)
+			buildRaw(s.builder, s.model, col, &s.args)
 			s.builder.WriteByte(' ')
-			s.args = append(s.args, col.args...)
 		default:
 			panic(ferr.ErrInvalidSelectable(col))
 		}
 	}
 
-	s.builder.WriteString(sqlWithFrom)
+	s.selectEnd = s.builder.Len()
+	s.builder.WriteString(s.fromClause)
 	return s
 }
 
 func (s *Selector[T]) From(table any) *Selector[T] {
-	if sqlWithFrom != "" {
-		sqlWithoutFrom := strings.TrimSuffix(s.builder.String(), sqlWithFrom)
+	if s.fromClause != "" {
+		sqlWithoutFrom := strings.TrimSuffix(s.builder.String(), s.fromClause)
 		s.builder.Reset()
 		s.builder.WriteString(sqlWithoutFrom)
 	}
@@ -207,10 +247,15 @@ func (s *Selector[T]) Where(conditions ...Condition) *Selector[T] {
 
 func (s *Selector[T]) Limit(num int) *Selector[T] {
 	s.builder.WriteString(" LIMIT " + strconv.Itoa(num))
+	s.hasLimit = true
 	return s
 }
 
+// Offset 跳过前 num 行，需要在 Limit 之后调用；没有 Limit 时按方言补上不限制行数的 LIMIT
 func (s *Selector[T]) Offset(num int) *Selector[T] {
+	if !s.hasLimit {
+		s.builder.WriteString(noLimitClause(s.dialect))
+	}
 	s.builder.WriteString(" OFFSET " + strconv.Itoa(num))
 	return s
 }
@@ -270,8 +315,7 @@ func (s *Selector[T]) OrderBy(orders ...OrderBy) *Selector[T] {
 			expr.model = s.model
 			expr.Build(s.builder)
 		case RawExpr:
-			expr.Build(s.builder)
-			s.args = append(s.args, expr.args...)
+			buildRaw(s.builder, s.model, expr, &s.args)
 		default:
 			panic(ferr.ErrInvalidOrderBy(order.expr))
 		}
@@ -336,22 +380,14 @@ func (s *Selector[T]) On(conditions ...Condition) *Selector[T] {
 			// 其实这个逻辑也可以放到build里面，但是我不想把db注入到model，感觉很奇怪
 			if leftCol, ok := cond.left.(*Column); ok {
 				if leftCol.tableStruct != nil {
-					var err error
-					leftCol.fromModel, err = s.layer.getModel(leftCol.tableStruct)
-					if err != nil {
-						panic(err)
-					}
+					leftCol.fromModel = s.tableModel(leftCol.tableStruct)
 					leftCol.table = leftCol.fromModel.table
 				}
 			}
 
 			if rightCol, ok := cond.right.(*Column); ok {
 				if rightCol.tableStruct != nil {
-					var err error
-					rightCol.fromModel, err = s.layer.getModel(rightCol.tableStruct)
-					if err != nil {
-						panic(err)
-					}
+					rightCol.fromModel = s.tableModel(rightCol.tableStruct)
 					rightCol.table = rightCol.fromModel.table
 				}
 			}
@@ -379,6 +415,36 @@ func (s *Selector[T]) Using(cols ...string) *Selector[T] {
 	return s
 }
 
+// Union 使用 UNION 合并另一个查询的结果并去除重复行，两个查询的列需要一一对应
+// 之后调用的 OrderBy、Limit 和 Offset 作用于合并后的结果，合并后的查询同样可以作为子查询使用
+//
+//	RegisterSelector[User](db).Select(Col("ID")).Where(Col("Age").Lt(18)).
+//		Union(RegisterSelector[User](db).Select(Col("ID")).Where(Col("Age").Gt(60)))
+func (s *Selector[T]) Union(other QueryBuilder) *Selector[T] {
+	return s.union(" UNION ", other)
+}
+
+// UnionAll 使用 UNION ALL 合并另一个查询的结果，保留重复行
+func (s *Selector[T]) UnionAll(other QueryBuilder) *Selector[T] {
+	return s.union(" UNION ALL ", other)
+}
+
+func (s *Selector[T]) union(keyword string, other QueryBuilder) *Selector[T] {
+	q, err := other.Build()
+	if err != nil {
+		panic(err)
+	}
+	s.builder.WriteString(keyword)
+	// 另一个查询的 $n 占位符接在当前查询的参数之后编号
+	s.builder.WriteString(rebasePlaceholders(s.dialect, strings.TrimSuffix(q.SQL, ";"), len(s.args)))
+	s.args = append(s.args, q.Args...)
+	s.model.index = len(s.args) + 1
+	s.hasLimit = false
+	// 窗口函数只能追加在第一个查询中，合并后分页使用 COUNT 查询获取总数
+	s.selectEnd = 0
+	return s
+}
+
 func (s *Selector[T]) AsSubQuery(alias string) *SubQuery[T] {
 	return &SubQuery[T]{
 		selector: s,
@@ -401,12 +467,14 @@ func (s *Selector[T]) Build() (*Query, error) {
 		}
 	}
 
-	if str := s.builder.String(); str[len(str)-1] != ';' {
-		s.builder.WriteByte(';')
+	// 不修改已经构建的内容，Build 之后还可以继续追加子句或者多次调用
+	query := s.builder.String()
+	if !strings.HasSuffix(query, ";") {
+		query += ";"
 	}
 
 	return &Query{
-		SQL:  s.builder.String(),
+		SQL:  query,
 		Args: s.args,
 	}, nil
 }
@@ -443,6 +511,11 @@ func (s *Selector[T]) Build() (*Query, error) {
 
 // scanRow 将一行数据扫描到结构体中
 func (s *Selector[T]) scanRow(rows *sql.Rows) (*T, error) {
+	return s.scanRowWith(rows, nil)
+}
+
+// scanRowWith 将一行数据扫描到结构体中，extras 中的列扫描到对应的目标而不是结构体字段
+func (s *Selector[T]) scanRowWith(rows *sql.Rows, extras map[string]any) (*T, error) {
 	cols, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -451,6 +524,33 @@ func (s *Selector[T]) scanRow(rows *sql.Rows) (*T, error) {
 	t := new(T)
 	vals := make([]any, len(cols))
 
+	// 有生成的元数据时直接取字段地址，不需要反射和指针运算
+	var addr func(*T, int) any
+	if s.model != nil {
+		addr, _ = s.model.addr.(func(*T, int) any)
+	}
+	if addr != nil {
+		for i, col := range cols {
+			if dst, ok := extras[col]; ok {
+				vals[i] = dst
+				continue
+			}
+			if idx, ok := s.model.colIndex[col]; ok {
+				vals[i] = s.model.scanDest(col, addr(t, idx))
+				continue
+			}
+			var dummy any
+			vals[i] = &dummy
+		}
+
+		if err := rows.Scan(vals...); err != nil {
+			return nil, err
+		}
+		s.layer.getDB().localizeTimes(reflect.ValueOf(t).Elem())
+
+		return t, nil
+	}
+
 	// 获取结构体的值和类型
 	value := reflect.ValueOf(t).Elem()
 	typ := value.Type()
@@ -487,8 +587,12 @@ func (s *Selector[T]) scanRow(rows *sql.Rows) (*T, error) {
 
 	// 创建scan列表
 	for i, col := range cols {
+		if dst, ok := extras[col]; ok {
+			vals[i] = dst
+			continue
+		}
 		if addr, ok := fieldAddrs[col]; ok {
-			vals[i] = reflect.NewAt(fieldTypes[col], addr).Interface()
+			vals[i] = s.model.scanDest(col, reflect.NewAt(fieldTypes[col], addr).Interface())
 			continue
 		}
 
@@ -510,6 +614,7 @@ func (s *Selector[T]) scanRow(rows *sql.Rows) (*T, error) {
 	if err := rows.Scan(vals...); err != nil {
 		return nil, err
 	}
+	s.layer.getDB().localizeTimes(value)
 
 	return t, nil
 }
@@ -535,96 +640,55 @@ func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 
 			// 检查是否应该缓存此查询
 			if db.cacheManager.ShouldCache(ctx, qc) {
-				debugLog("Cache enabled for query: %s\n", q.SQL) // 日志
-
 				// 生成缓存键
 				cacheKey := db.cacheManager.GenerateKey(qc)
 				if cacheKey != "" {
-					debugLog("Generated cache key: %s\n", cacheKey) // 日志
-
-					// 尝试从缓存获取结果
-					var cachedResult T
-					err := db.cacheManager.cache.Get(ctx, cacheKey, &cachedResult)
-					if err == nil {
-						// 缓存命中，直接返回
-						debugLog("Cache hit: %+v\n", cachedResult) // 日志
-						return &cachedResult, nil
-					}
-
-					if !errors.Is(err, ErrCacheMiss) {
-						// 如果是其他错误而非缓存未命中，记录但继续执行查询
-						debugLog("Cache error: %v\n", err) // 日志
-					} else {
-						debugLog("Cache miss for key: %s\n", cacheKey) // 日志
-					}
-
-					// 缓存未命中，执行查询
-					result, err := s.execGet(ctx, q)
+					// 缓存中保存查询得到的原始数据，AfterFind 钩子和脱敏在返回前执行，
+					// 避免钩子对缓存的数据生效后命中时再执行一次
+					result, hit, err := loadThroughCache(ctx, db.cacheManager, s.cacheLoad(db.cacheManager, cacheKey), func(ctx context.Context) (*T, error) {
+						return s.queryRow(ctx, q)
+					})
 					if err != nil {
 						return nil, err
 					}
-
-					// 查询成功，缓存结果
-					ttl := s.cacheTTL
-					if ttl <= 0 {
-						ttl = db.cacheManager.GetTTL(s.model.GetTableName())
-					}
-
-					tags := s.cacheTags
-					if len(tags) == 0 {
-						tags = db.cacheManager.GetTags(s.model.GetTableName())
+					if hit {
+						// 缓存命中，缓存反序列化后时区只保留偏移量，需要重新转换
+						db.localizeTimes(reflect.ValueOf(result))
 					}
-
-					// 缓存结果部分需要修改
-					debugLog("Caching result: %+v with TTL: %v\n", result, ttl) // 日志
-
-					// 使用标签存储缓存
-					if len(tags) > 0 {
-						// 检查缓存实现是否支持标签
-						if tagCache, ok := db.cacheManager.cache.(interface {
-							SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
-						}); ok {
-							err = tagCache.SetWithTags(ctx, cacheKey, result, ttl, tags...)
-							if err != nil {
-								debugLog("Error setting cache with tags: %v\n", err) // 日志
-							} else {
-								debugLog("Cache set with tags: %v\n", tags) // 日志
-							}
-						} else {
-							// 不支持标签，仅设置缓存
-							err = db.cacheManager.cache.Set(ctx, cacheKey, result, ttl)
-							if err != nil {
-								debugLog("Error setting cache: %v\n", err) // 日志
-							}
-						}
-					} else {
-						// 没有标签，直接设置缓存
-						err = db.cacheManager.cache.Set(ctx, cacheKey, result, ttl)
-						if err != nil {
-							debugLog("Error setting cache: %v\n", err) // 日志
-						}
+					if err = runRowHooks(ctx, db, AfterFindEvent, s.model.table, []*T{result}); err != nil {
+						return nil, err
 					}
 
+					s.applyMasks(ctx, result)
 					return result, nil
-				} else {
-					debugLog("Empty cache key generated\n") // 日志
 				}
-			} else {
-				debugLog("Cache should not be used for this query\n") // 日志
 			}
-		} else {
-			debugLog("Cache manager not enabled\n") // 日志
 		}
-	} else {
-		debugLog("Cache not requested for this query\n") // 日志
 	}
 
 	// 没有使用缓存，直接执行查询
-	return s.execGet(ctx, q)
+	t, err := s.execGet(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	s.applyMasks(ctx, t)
+	return t, nil
 }
 
-// execGet 执行获取单行数据的实际查询
+// execGet 执行获取单行数据的实际查询，并执行 AfterFind 钩子
 func (s *Selector[T]) execGet(ctx context.Context, q *Query) (*T, error) {
+	t, err := s.queryRow(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err = runRowHooks(ctx, s.layer.getDB(), AfterFindEvent, s.model.table, []*T{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// queryRow 查询并扫描单行数据，不执行钩子
+func (s *Selector[T]) queryRow(ctx context.Context, q *Query) (*T, error) {
 	// 构建查询上下文
 	qc := &QueryContext{
 		QueryType: "query",
@@ -679,52 +743,24 @@ func (s *Selector[T]) GetMulti(ctx context.Context) ([]*T, error) {
 				// 生成缓存键
 				cacheKey := db.cacheManager.GenerateKey(qc)
 				if cacheKey != "" {
-					// 尝试从缓存获取结果
-					var cachedResult []*T
-					err := db.cacheManager.cache.Get(ctx, cacheKey, &cachedResult)
-					if err == nil {
-						// 缓存命中，直接返回
-						return cachedResult, nil
-					}
-
-					if !errors.Is(err, ErrCacheMiss) {
-						// 如果是其他错误而非缓存未命中，记录但继续执行查询
-						debugLog("Cache error: %v\n", err)
-					}
-
-					// 缓存未命中，执行查询
-					result, err := s.execGetMulti(ctx, q)
+					// 与 Get 相同，缓存中保存原始数据，钩子和脱敏在返回前执行
+					result, hit, err := loadThroughCache(ctx, db.cacheManager, s.cacheLoad(db.cacheManager, cacheKey), func(ctx context.Context) ([]*T, error) {
+						return s.queryRows(ctx, q)
+					})
 					if err != nil {
 						return nil, err
 					}
-
-					// 查询成功，缓存结果
-					ttl := s.cacheTTL
-					if ttl <= 0 {
-						ttl = db.cacheManager.GetTTL(s.model.GetTableName())
-					}
-
-					tags := s.cacheTags
-					if len(tags) == 0 {
-						tags = db.cacheManager.GetTags(s.model.GetTableName())
-					}
-
-					// 缓存结果
-					if len(tags) > 0 {
-						// 检查缓存实现是否支持标签
-						if tagCache, ok := db.cacheManager.cache.(interface {
-							SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
-						}); ok {
-							_ = tagCache.SetWithTags(ctx, cacheKey, result, ttl, tags...)
-						} else {
-							// 不支持标签，仅设置缓存
-							_ = db.cacheManager.cache.Set(ctx, cacheKey, result, ttl)
+					if hit {
+						// 缓存命中，缓存反序列化后时区只保留偏移量，需要重新转换
+						for _, r := range result {
+							db.localizeTimes(reflect.ValueOf(r))
 						}
-					} else {
-						// 没有标签，直接设置缓存
-						_ = db.cacheManager.cache.Set(ctx, cacheKey, result, ttl)
+					}
+					if err = runRowHooks(ctx, db, AfterFindEvent, s.model.table, result); err != nil {
+						return nil, err
 					}
 
+					s.applyMasks(ctx, result...)
 					return result, nil
 				}
 			}
@@ -732,11 +768,28 @@ func (s *Selector[T]) GetMulti(ctx context.Context) ([]*T, error) {
 	}
 
 	// 没有使用缓存，直接执行查询
-	return s.execGetMulti(ctx, q)
+	result, err := s.execGetMulti(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	s.applyMasks(ctx, result...)
+	return result, nil
 }
 
-// execGetMulti 执行获取多行数据的实际查询
+// execGetMulti 执行获取多行数据的实际查询，并执行 AfterFind 钩子
 func (s *Selector[T]) execGetMulti(ctx context.Context, q *Query) ([]*T, error) {
+	result, err := s.queryRows(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err = runRowHooks(ctx, s.layer.getDB(), AfterFindEvent, s.model.table, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// queryRows 查询并扫描多行数据，不执行钩子
+func (s *Selector[T]) queryRows(ctx context.Context, q *Query) ([]*T, error) {
 	// 构建查询上下文
 	qc := &QueryContext{
 		QueryType: "query",
@@ -759,6 +812,5 @@ func (s *Selector[T]) execGetMulti(ctx context.Context, q *Query) ([]*T, error)
 		}
 		result = append(result, t)
 	}
-
 	return result, nil
 }
@@ -2,6 +2,7 @@ package orm
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
@@ -485,13 +486,94 @@ func (sc *ShardedCollection) Find(ctx context.Context, where ...Condition) (inte
 	return coll.Find(ctx, where...)
 }
 
-// FindAll 查找多条记录
+// FindAll 查找多条记录，条件中没有分片键时查询所有分片并合并结果
 func (sc *ShardedCollection) FindAll(ctx context.Context, where ...Condition) ([]interface{}, error) {
-	// 实现类似 Find 的逻辑，但返回多条记录
-	// ...
+	return sc.FindWithOptions(ctx, FindOptions{}, where...)
+}
+
+// FindWithOptions 使用选项查找记录，条件中没有分片键时查询所有分片，
+// 合并后按 OrderBy 重新排序，再应用 Offset 和 Limit
+func (sc *ShardedCollection) FindWithOptions(ctx context.Context, opts FindOptions, where ...Condition) ([]interface{}, error) {
+	targets := sc.targets(ctx, where)
+
+	// 每个分片需要返回前 Offset+Limit 行，合并后再分页
+	shardOpts := opts
+	shardOpts.Offset = 0
+	if opts.Limit > 0 {
+		shardOpts.Limit = opts.Offset + opts.Limit
+	}
+
+	results := make([][]interface{}, len(targets))
+	err := sc.scatter(ctx, targets, func(ctx context.Context, i int, coll *Collection, db *DB, m *model) (func() error, error) {
+		q, err := coll.buildFind(db, m, shardOpts, where)
+		if err != nil {
+			return nil, err
+		}
+		return func() (err error) {
+			results[i], err = coll.queryAll(ctx, db, m, q)
+			return err
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []interface{}
+	for _, res := range results {
+		merged = append(merged, res...)
+	}
+	if len(targets) > 1 && len(opts.OrderBy) > 0 {
+		if err = sortRows(merged, opts.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return paginateRows(merged, opts.Offset, opts.Limit), nil
+}
+
+// Count 统计匹配的记录数，跨分片时汇总各分片的结果
+func (sc *ShardedCollection) Count(ctx context.Context, where ...Condition) (int64, error) {
+	targets := sc.targets(ctx, where)
+	counts := make([]int64, len(targets))
+	err := sc.scatter(ctx, targets, func(ctx context.Context, i int, coll *Collection, db *DB, m *model) (func() error, error) {
+		q := coll.buildAggregate(db, m, "COUNT(*)", where)
+		return func() error {
+			return coll.queryAggregate(ctx, db, q, &counts[i])
+		}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total, nil
+}
+
+// Sum 对字段求和，跨分片时汇总各分片的结果
+func (sc *ShardedCollection) Sum(ctx context.Context, field string, where ...Condition) (float64, error) {
+	targets := sc.targets(ctx, where)
+	sums := make([]sql.NullFloat64, len(targets))
+	err := sc.scatter(ctx, targets, func(ctx context.Context, i int, coll *Collection, db *DB, m *model) (func() error, error) {
+		expr, err := sumExpr(db, m, field)
+		if err != nil {
+			return nil, err
+		}
+		q := coll.buildAggregate(db, m, expr, where)
+		return func() error {
+			return coll.queryAggregate(ctx, db, q, &sums[i])
+		}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
 
-	// 临时返回
-	return nil, errors.New("not implemented")
+	var total float64
+	for _, sum := range sums {
+		total += sum.Float64
+	}
+	return total, nil
 }
 
 // Insert 插入记录
@@ -519,22 +601,18 @@ func (sc *ShardedCollection) Insert(ctx context.Context, model interface{}) (Res
 	return coll.Insert(ctx, model)
 }
 
-// Update 更新记录
+// Update 更新记录，条件中没有分片键时更新所有分片，返回的影响行数为各分片之和
 func (sc *ShardedCollection) Update(ctx context.Context, update map[string]interface{}, where ...Condition) (Result, error) {
-	// 实现类似 Find 的路由逻辑，然后执行更新
-	// ...
-
-	// 临时返回
-	return Result{}, errors.New("not implemented")
+	return sc.execAll(ctx, where, func(coll *Collection) (Result, error) {
+		return coll.Update(ctx, update, where...)
+	})
 }
 
-// Delete 删除记录
+// Delete 删除记录，条件中没有分片键时删除所有分片中匹配的记录，返回的影响行数为各分片之和
 func (sc *ShardedCollection) Delete(ctx context.Context, where ...Condition) (Result, error) {
-	// 实现类似 Find 的路由逻辑，然后执行删除
-	// ...
-
-	// 临时返回
-	return Result{}, errors.New("not implemented")
+	return sc.execAll(ctx, where, func(coll *Collection) (Result, error) {
+		return coll.Delete(ctx, where...)
+	})
 }
 
 // defaultShardingRouter 默认路由器实现
@@ -593,49 +671,6 @@ func (r *defaultShardingRouter) CalculateRoute(ctx context.Context, model interf
 	return strategy.GetShardName(dbIndex, tableIndex)
 }
 
-// extractShardKeyFromConditions 从条件中提取分片键值
-func extractShardKeyFromConditions(conditions []Condition, modelName string, manager *ShardingManager) (map[string]interface{}, error) {
-	// 获取模型的分片信息
-	info, ok := manager.GetModelInfo(modelName)
-	if !ok {
-		return nil, ErrModelNotRegistered
-	}
-
-	// 获取分片键
-	shardKey := info.strategy.GetShardKey()
-
-	// 从条件中查找分片键
-	values := make(map[string]interface{})
-	found := false
-
-	// 遍历所有条件
-	for _, cond := range conditions {
-		// 尝试类型断言为Predicate
-		if pred, ok := cond.(*Predicate); ok {
-			// 检查是否为相等操作符，且左侧为列
-			if pred.op == opEQ {
-				if col, ok := pred.left.(*Column); ok {
-					// 检查列名是否为分片键
-					if col.name == shardKey {
-						// 提取右侧的值
-						if val, ok := pred.right.(*Value); ok {
-							values[shardKey] = val.val
-							found = true
-							break
-						}
-					}
-				}
-			}
-		}
-	}
-
-	if !found {
-		return nil, ErrNoShardKeyFound
-	}
-
-	return values, nil
-}
-
 // WithHashStrategy 为模型创建哈希分片策略
 func WithHashStrategy(dbPrefix string, dbCount int, tablePrefix string, tableCount int, shardKey string) ShardingStrategy {
 	return &shardingStrategyAdapter{
@@ -664,11 +699,54 @@ func WithDateStrategy(dbPrefix string, dbCount int, tablePrefix string, tableCou
 	}
 }
 
+// WeightedShardingStrategy 支持按权重分配数据的分片策略，权重越大的数据库分到的数据越多
+type WeightedShardingStrategy interface {
+	ShardingStrategy
+
+	// SetWeight 设置数据库的权重，权重为0时该数据库不再分配数据
+	SetWeight(dbIndex, weight int) error
+
+	// Weight 获取数据库的权重
+	Weight(dbIndex int) int
+}
+
+// WithConsistentHashStrategy 为模型创建一致性哈希分片策略，virtualNodes 为每个单位权重对应的虚拟节点数，
+// 小于等于0时使用默认值。增加数据库或者调整权重时只有少量数据需要迁移，
+// 可以通过 internal/sharding 中的 PlanMigration 计算需要迁移的区间
+func WithConsistentHashStrategy(dbPrefix string, dbCount int, tablePrefix string, tableCount int, shardKey string, virtualNodes int) WeightedShardingStrategy {
+	inner := sharding.NewConsistentHashStrategy(dbPrefix, dbCount, tablePrefix, tableCount, shardKey, virtualNodes)
+	return &weightedStrategyAdapter{
+		shardingStrategyAdapter: shardingStrategyAdapter{inner: inner},
+		weighted:                inner,
+	}
+}
+
+// weightedStrategyAdapter 适配器，使支持权重的内部分片策略实现WeightedShardingStrategy接口
+type weightedStrategyAdapter struct {
+	shardingStrategyAdapter
+	weighted sharding.WeightedStrategy
+}
+
+// SetWeight 实现WeightedShardingStrategy.SetWeight
+func (s *weightedStrategyAdapter) SetWeight(dbIndex, weight int) error {
+	return s.weighted.SetWeight(dbIndex, weight)
+}
+
+// Weight 实现WeightedShardingStrategy.Weight
+func (s *weightedStrategyAdapter) Weight(dbIndex int) int {
+	return s.weighted.Weight(dbIndex)
+}
+
 // shardingStrategyAdapter 适配器，使内部分片策略实现ShardingStrategy接口
 type shardingStrategyAdapter struct {
 	inner sharding.Strategy
 }
 
+// unwrap 返回内部分片策略
+func (s *shardingStrategyAdapter) unwrap() sharding.Strategy {
+	return s.inner
+}
+
 // Route 实现ShardingStrategy.Route
 func (s *shardingStrategyAdapter) Route(key interface{}) (int, int, error) {
 	return s.inner.Route(key)
@@ -691,6 +769,8 @@ func (s *shardingStrategyAdapter) GetShardKey() string {
 		return st.BaseStrategy.ShardKey
 	case *sharding.DateStrategy:
 		return st.BaseStrategy.ShardKey
+	case *sharding.ConsistentHashStrategy:
+		return st.BaseStrategy.ShardKey
 	}
 
 	// 通过反射获取ShardKey
@@ -715,36 +795,15 @@ func (s *shardingStrategyAdapter) GetShardKey() string {
 	return ""
 }
 
-// ExecuteOnAllShards 在所有分片上执行操作
-func (sdb *ShardingDB) ExecuteOnAllShards(ctx context.Context, fn func(db *DB) error) []error {
-	var errors []error
-	var mu sync.Mutex
-
-	// 获取所有分片
-	shards := make(map[string]*DB)
-	sdb.shardingManager.mu.RLock()
-	for name, db := range sdb.shardingManager.shards {
-		shards[name] = db
-	}
-	sdb.shardingManager.mu.RUnlock()
-
-	// 并发执行
-	var wg sync.WaitGroup
-	for name, db := range shards {
-		wg.Add(1)
-		go func(name string, db *DB) {
-			defer wg.Done()
-
-			if err := fn(db); err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("shard %s: %w", name, err))
-				mu.Unlock()
-			}
-		}(name, db)
-	}
-
-	// 等待所有操作完成
-	wg.Wait()
-
-	return errors
+// ExecuteOnAllShards 在所有分片上并发执行操作，返回每个分片的执行结果：
+//
+//	results := sdb.ExecuteOnAllShards(ctx, func(ctx context.Context, db *orm.DB) error {
+//		_, err := db.NewClient().Exec(ctx, "ANALYZE TABLE user")
+//		return err
+//	}, orm.WithShardParallelism(4), orm.WithShardTimeout(10*time.Second))
+//	if err := results.Err(); err != nil {
+//		// 处理失败的分片
+//	}
+func (sdb *ShardingDB) ExecuteOnAllShards(ctx context.Context, fn func(ctx context.Context, db *DB) error, opts ...ShardExecOption) ShardResults {
+	return sdb.shardingManager.executeOnAllShards(ctx, fn, opts)
 }
@@ -0,0 +1,54 @@
+package orm
+
+import (
+	"context"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm/internal/sharding"
+)
+
+// ShardResult 单个分片的执行结果
+type ShardResult = sharding.ShardResult
+
+// ShardResults 所有分片的执行结果，按分片名称排序，Err 合并所有失败分片的错误
+type ShardResults = sharding.ShardResults
+
+// ShardExecOption 在所有分片上执行操作的配置项
+type ShardExecOption = sharding.ExecOption
+
+// WithShardParallelism 限制同时执行的分片数，避免分片很多时同时占用大量连接
+func WithShardParallelism(n int) ShardExecOption {
+	return sharding.WithMaxParallelism(n)
+}
+
+// WithShardTimeout 设置每个分片的超时时间，超时后传给操作的 ctx 被取消
+func WithShardTimeout(timeout time.Duration) ShardExecOption {
+	return sharding.WithShardTimeout(timeout)
+}
+
+// WithShardFailFast 某个分片失败后取消其他分片，没有开始执行的分片标记为 Skipped。
+// 默认等待所有分片执行完成并收集所有错误
+func WithShardFailFast() ShardExecOption {
+	return sharding.WithFailFast()
+}
+
+// executeOnAllShards 在所有已注册的分片上执行操作
+func (m *ShardingManager) executeOnAllShards(ctx context.Context, fn func(ctx context.Context, db *DB) error, opts []ShardExecOption) ShardResults {
+	m.mu.RLock()
+	shards := make(map[string]*DB, len(m.shards))
+	names := make([]string, 0, len(m.shards))
+	for name, db := range m.shards {
+		shards[name] = db
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	var cfg sharding.ExecConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return sharding.Execute(ctx, names, func(ctx context.Context, name string) error {
+		return fn(ctx, shards[name])
+	}, cfg)
+}
@@ -0,0 +1,39 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardingDB_ExecuteOnAllShards(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+
+	mock0.ExpectExec("ANALYZE TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock1.ExpectExec("ANALYZE TABLE").WillReturnError(errors.New("table is locked"))
+
+	results := sdb.ExecuteOnAllShards(context.Background(), func(ctx context.Context, db *DB) error {
+		_, err := db.NewClient().Exec(ctx, "ANALYZE TABLE sharding_order")
+		return err
+	}, WithShardParallelism(1))
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "order_db_0", results[0].Shard)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "order_db_1", results[1].Shard)
+	assert.ErrorContains(t, results.Err(), "shard order_db_1: table is locked")
+
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+
+	// 通过客户端执行时使用同一组分片
+	results = sdb.NewClient().ExecuteOnAllShards(context.Background(), func(ctx context.Context, db *DB) error {
+		return nil
+	})
+	assert.Len(t, results, 2)
+	assert.NoError(t, results.Err())
+}
@@ -0,0 +1,233 @@
+package orm
+
+import (
+	"github.com/fyerfyer/fyer-webframe/orm/internal/sharding"
+)
+
+// extractShardKeyFromConditions 从条件中提取分片键值。支持顶层和 AND 嵌套中的 Eq，
+// 以及只有一个值的 IN；可能命中多个分片的条件使用 routeConditions
+func extractShardKeyFromConditions(conditions []Condition, modelName string, manager *ShardingManager) (map[string]interface{}, error) {
+	// 获取模型的分片信息
+	info, ok := manager.GetModelInfo(modelName)
+	if !ok {
+		return nil, ErrModelNotRegistered
+	}
+
+	// 获取分片键
+	shardKey := info.strategy.GetShardKey()
+
+	for _, cond := range conditions {
+		if pred, ok := cond.(*Predicate); ok {
+			if val, ok := findShardKeyValue(pred, shardKey); ok {
+				return map[string]interface{}{shardKey: val}, nil
+			}
+		}
+	}
+	return nil, ErrNoShardKeyFound
+}
+
+// findShardKeyValue 在 AND 连接的条件中查找分片键唯一确定的值
+func findShardKeyValue(pred *Predicate, shardKey string) (any, bool) {
+	if pred.op == opAND {
+		for _, expr := range []Expression{pred.left, pred.right} {
+			if sub, ok := expr.(*Predicate); ok {
+				if val, ok := findShardKeyValue(sub, shardKey); ok {
+					return val, true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	vals, ok := shardKeyValues(pred, shardKey)
+	if !ok || len(vals) != 1 {
+		return nil, false
+	}
+	return vals[0], true
+}
+
+// shardKeyValues 条件为分片键的 Eq 或 IN 时返回可能的取值。
+// 右侧为子查询时，取值要到执行时才能确定，不能用于路由
+func shardKeyValues(pred *Predicate, shardKey string) ([]any, bool) {
+	if pred.op != opEQ && pred.op != opIN {
+		return nil, false
+	}
+	if col, ok := pred.left.(*Column); !ok || col.name != shardKey {
+		return nil, false
+	}
+	val, ok := pred.right.(*Value)
+	if !ok {
+		return nil, false
+	}
+	if pred.op == opEQ {
+		return []any{val.val}, true
+	}
+	vals, ok := val.val.([]any)
+	return vals, ok
+}
+
+// shardSet 条件可能命中的分片，nil 表示所有分片
+type shardSet map[string]struct{}
+
+// intersect 两个条件用 AND 连接时，只可能命中两者都会命中的分片
+func (s shardSet) intersect(other shardSet) shardSet {
+	if s == nil {
+		return other
+	}
+	if other == nil {
+		return s
+	}
+	res := make(shardSet)
+	for name := range s {
+		if _, ok := other[name]; ok {
+			res[name] = struct{}{}
+		}
+	}
+	return res
+}
+
+// union 两个条件用 OR 连接时，可能命中任意一个条件命中的分片
+func (s shardSet) union(other shardSet) shardSet {
+	if s == nil || other == nil {
+		return nil
+	}
+	res := make(shardSet, len(s)+len(other))
+	for name := range s {
+		res[name] = struct{}{}
+	}
+	for name := range other {
+		res[name] = struct{}{}
+	}
+	return res
+}
+
+// conditionRouter 根据条件计算可能命中的分片
+type conditionRouter struct {
+	shardKey string
+	strategy ShardingStrategy
+}
+
+// routeConditions 计算条件可能命中的分片数据库，返回的结果可能多于实际命中的分片，但不会遗漏：
+//   - Eq 和 IN 路由到每个取值所在的分片
+//   - 范围分片策略下，Gt、Gte、Lt、Lte 和 Between 路由到范围覆盖的分片
+//   - AND 取交集，OR 取并集
+//   - 子查询、NOT 等无法确定分片的条件会命中所有分片
+//
+// 条件不能缩小分片范围时返回 ErrNoShardKeyFound
+func routeConditions(conditions []Condition, modelName string, manager *ShardingManager) ([]string, error) {
+	info, ok := manager.GetModelInfo(modelName)
+	if !ok {
+		return nil, ErrModelNotRegistered
+	}
+
+	r := &conditionRouter{shardKey: info.strategy.GetShardKey(), strategy: info.strategy}
+	var set shardSet
+	for _, cond := range conditions {
+		if pred, ok := cond.(*Predicate); ok {
+			set = set.intersect(r.route(pred))
+		}
+	}
+	if set == nil {
+		return nil, ErrNoShardKeyFound
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (r *conditionRouter) route(pred *Predicate) shardSet {
+	switch pred.op {
+	case opAND, opOR:
+		left, ok := pred.left.(*Predicate)
+		if !ok {
+			return nil
+		}
+		right, ok := pred.right.(*Predicate)
+		if !ok {
+			return nil
+		}
+		if pred.op == opAND {
+			return r.route(left).intersect(r.route(right))
+		}
+		return r.route(left).union(r.route(right))
+	case opEQ, opIN:
+		vals, ok := shardKeyValues(pred, r.shardKey)
+		// IN 的列表为空时条件恒为假，不需要特殊处理，查询所有分片同样返回空结果
+		if !ok || len(vals) == 0 {
+			return nil
+		}
+		set := make(shardSet, len(vals))
+		for _, val := range vals {
+			dbIndex, tableIndex, err := r.strategy.Route(val)
+			if err != nil {
+				return nil
+			}
+			if !r.add(set, dbIndex, tableIndex) {
+				return nil
+			}
+		}
+		return set
+	case opGT, opGTE, opLT, opLTE, opBETWEEN:
+		return r.routeRange(pred)
+	}
+	return nil
+}
+
+// routeRange 范围条件只能用于实现了 sharding.RangeRouter 的策略，例如范围分片。
+// 边界按闭区间处理，Gt 和 Lt 可能多命中边界所在的分片
+func (r *conditionRouter) routeRange(pred *Predicate) shardSet {
+	if col, ok := pred.left.(*Column); !ok || col.name != r.shardKey {
+		return nil
+	}
+	val, ok := pred.right.(*Value)
+	if !ok {
+		return nil
+	}
+	u, ok := r.strategy.(interface{ unwrap() sharding.Strategy })
+	if !ok {
+		return nil
+	}
+	ranger, ok := u.unwrap().(sharding.RangeRouter)
+	if !ok {
+		return nil
+	}
+
+	var min, max any
+	switch pred.op {
+	case opGT, opGTE:
+		min = val.val
+	case opLT, opLTE:
+		max = val.val
+	case opBETWEEN:
+		bounds, ok := val.val.([]any)
+		if !ok || len(bounds) != 2 {
+			return nil
+		}
+		min, max = bounds[0], bounds[1]
+	}
+
+	shards, err := ranger.RouteRange(min, max)
+	if err != nil {
+		return nil
+	}
+	set := make(shardSet, len(shards))
+	for _, shard := range shards {
+		if !r.add(set, shard.DB, shard.Table) {
+			return nil
+		}
+	}
+	return set
+}
+
+// add 将分片的数据库加入集合
+func (r *conditionRouter) add(set shardSet, dbIndex, tableIndex int) bool {
+	dbName, _, err := r.strategy.GetShardName(dbIndex, tableIndex)
+	if err != nil {
+		return false
+	}
+	set[dbName] = struct{}{}
+	return true
+}
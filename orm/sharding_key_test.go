@@ -0,0 +1,151 @@
+package orm
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteConditions(t *testing.T) {
+	m := NewShardingManager(nil, NewShardingRouter())
+	m.RegisterModelInfo("ShardingOrder", WithModStrategy("order_db_", 4, "order_", 1, "OrderID"), "")
+	// [0,100) -> range_db_0, [100,200) -> range_db_1, [200,300) -> range_db_2, [300,∞) -> range_db_3
+	m.RegisterModelInfo("RangeOrder", WithRangeStrategy("range_db_", 4, "order_", 1, "OrderID", []int64{100, 200, 300}), "")
+
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	sub := RegisterSelector[ShardingOrder](db).Select(Col("OrderID")).Where(Col("UserID").Eq(1))
+
+	testCases := []struct {
+		name    string
+		model   string
+		where   []Condition
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "eq",
+			model: "ShardingOrder",
+			where: []Condition{Col("OrderID").Eq(5)},
+			want:  []string{"order_db_1"},
+		},
+		{
+			name:  "in fan-out",
+			model: "ShardingOrder",
+			where: []Condition{Col("OrderID").In(1, 2, 5, 9)},
+			want:  []string{"order_db_1", "order_db_2"},
+		},
+		{
+			name:  "nested and",
+			model: "ShardingOrder",
+			where: []Condition{And(Col("Status").Eq(1), And(Col("Amount").Gt(10), Col("OrderID").In(3, 4)))},
+			want:  []string{"order_db_0", "order_db_3"},
+		},
+		{
+			name:  "and intersects",
+			model: "ShardingOrder",
+			where: []Condition{Col("OrderID").In(1, 2, 3), Col("OrderID").In(2, 3, 4)},
+			want:  []string{"order_db_2", "order_db_3"},
+		},
+		{
+			name:  "or unions",
+			model: "ShardingOrder",
+			where: []Condition{Or(Col("OrderID").Eq(1), Col("OrderID").Eq(6))},
+			want:  []string{"order_db_1", "order_db_2"},
+		},
+		{
+			name:    "or without shard key",
+			model:   "ShardingOrder",
+			where:   []Condition{Or(Col("OrderID").Eq(1), Col("Status").Eq(1))},
+			wantErr: ErrNoShardKeyFound,
+		},
+		{
+			name:    "subquery",
+			model:   "ShardingOrder",
+			where:   []Condition{Col("OrderID").In(sub)},
+			wantErr: ErrNoShardKeyFound,
+		},
+		{
+			name:  "subquery and eq",
+			model: "ShardingOrder",
+			where: []Condition{Col("OrderID").In(sub), Col("OrderID").Eq(2)},
+			want:  []string{"order_db_2"},
+		},
+		{
+			name:    "range on mod strategy",
+			model:   "ShardingOrder",
+			where:   []Condition{Col("OrderID").Gt(10)},
+			wantErr: ErrNoShardKeyFound,
+		},
+		{
+			name:  "range",
+			model: "RangeOrder",
+			where: []Condition{Col("OrderID").Gte(150), Col("OrderID").Lt(250)},
+			want:  []string{"range_db_1", "range_db_2"},
+		},
+		{
+			name:  "between",
+			model: "RangeOrder",
+			where: []Condition{Col("OrderID").Between(10, 120)},
+			want:  []string{"range_db_0", "range_db_1"},
+		},
+		{
+			name:  "unbounded range",
+			model: "RangeOrder",
+			where: []Condition{Col("OrderID").Gt(250)},
+			want:  []string{"range_db_2", "range_db_3"},
+		},
+		{
+			name:  "contradiction",
+			model: "ShardingOrder",
+			where: []Condition{Col("OrderID").Eq(1), Col("OrderID").Eq(2)},
+			want:  []string{},
+		},
+		{
+			name:    "not",
+			model:   "ShardingOrder",
+			where:   []Condition{NOT(Col("OrderID").Eq(1))},
+			wantErr: ErrNoShardKeyFound,
+		},
+		{
+			name:    "unregistered model",
+			model:   "Unknown",
+			where:   []Condition{Col("OrderID").Eq(1)},
+			wantErr: ErrModelNotRegistered,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			names, err := routeConditions(tc.where, tc.model, m)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			sort.Strings(names)
+			assert.Equal(t, tc.want, names)
+		})
+	}
+}
+
+func TestExtractShardKeyFromConditions(t *testing.T) {
+	m := NewShardingManager(nil, NewShardingRouter())
+	m.RegisterModelInfo("ShardingOrder", WithModStrategy("order_db_", 4, "order_", 1, "OrderID"), "")
+
+	values, err := extractShardKeyFromConditions([]Condition{
+		Col("Status").Eq(1),
+		And(Col("Amount").Gt(10), Col("OrderID").In(7)),
+	}, "ShardingOrder", m)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"OrderID": 7}, values)
+
+	_, err = extractShardKeyFromConditions([]Condition{Col("OrderID").In(1, 2)}, "ShardingOrder", m)
+	assert.ErrorIs(t, err, ErrNoShardKeyFound)
+}
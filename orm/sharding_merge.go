@@ -0,0 +1,245 @@
+package orm
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// shardTarget 跨分片操作的目标分片
+type shardTarget struct {
+	name string
+	db   *DB
+}
+
+// targets 根据条件确定需要访问的分片：条件能够确定分片时只访问这些分片，否则访问所有分片。
+// 条件互相矛盾时（例如分片键同时等于路由到不同分片的两个值）不访问任何分片
+func (sc *ShardedCollection) targets(ctx context.Context, where []Condition) []shardTarget {
+	m := sc.shardingManager
+	m.mu.RLock()
+	shards := make([]shardTarget, 0, len(m.shards))
+	for name, db := range m.shards {
+		shards = append(shards, shardTarget{name: name, db: db})
+	}
+	m.mu.RUnlock()
+	sort.Slice(shards, func(i, j int) bool { return shards[i].name < shards[j].name })
+
+	if len(shards) == 0 {
+		return []shardTarget{{name: "default", db: m.GetDefaultDB()}}
+	}
+
+	names, err := routeConditions(where, sc.modelName, m)
+	if err != nil || !m.IsEnabled() {
+		return shards
+	}
+	routed := make([]shardTarget, 0, len(names))
+	for _, name := range names {
+		db, ok := m.GetShard(name)
+		if !ok {
+			// 路由到未注册的分片时无法确定数据在哪里，访问所有分片
+			return shards
+		}
+		routed = append(routed, shardTarget{name: name, db: db})
+	}
+	sort.Slice(routed, func(i, j int) bool { return routed[i].name < routed[j].name })
+	return routed
+}
+
+// scatter 在目标分片上并发执行查询。prepare 在调用方的协程中依次为每个分片构建查询，
+// 条件会在构建时被修改，不能并发构建；prepare 返回的函数在各自的协程中执行查询
+func (sc *ShardedCollection) scatter(ctx context.Context, targets []shardTarget,
+	prepare func(ctx context.Context, i int, coll *Collection, db *DB, m *model) (func() error, error)) error {
+	runs := make([]func() error, len(targets))
+	for i, t := range targets {
+		coll := t.db.NewClient().Collection(sc.modelType)
+		m, err := coll.getModel(t.db)
+		if err != nil {
+			return err
+		}
+		if runs[i], err = prepare(ctx, i, coll, t.db, m); err != nil {
+			return err
+		}
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, run := range runs {
+		wg.Add(1)
+		go func(i int, run func() error) {
+			defer wg.Done()
+			if err := run(); err != nil {
+				errs[i] = fmt.Errorf("shard %s: %w", targets[i].name, err)
+			}
+		}(i, run)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// execAll 在目标分片上依次执行写操作，汇总影响的行数。某个分片失败时停止，返回已经执行的分片影响的行数
+func (sc *ShardedCollection) execAll(ctx context.Context, where []Condition, fn func(coll *Collection) (Result, error)) (Result, error) {
+	var affected int64
+	for _, t := range sc.targets(ctx, where) {
+		res, err := fn(t.db.NewClient().Collection(sc.modelType))
+		var n int64
+		if err == nil {
+			n, err = res.RowsAffected()
+		}
+		if err != nil {
+			return Result{res: shardedResult(affected)}, fmt.Errorf("shard %s: %w", t.name, err)
+		}
+		affected += n
+	}
+	return Result{res: shardedResult(affected)}, nil
+}
+
+// shardedResult 跨分片写操作的结果，只支持影响的行数
+type shardedResult int64
+
+func (r shardedResult) LastInsertId() (int64, error) {
+	return 0, errors.New("orm: LastInsertId is not supported across shards")
+}
+
+func (r shardedResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+// sortRows 按排序条件对合并后的结果重新排序，结果为结构体指针
+func sortRows(rows []interface{}, orderBy []OrderBy) error {
+	fields := make([]string, len(orderBy))
+	for i, order := range orderBy {
+		col, ok := order.expr.(*Column)
+		if !ok {
+			return errors.New("unsupported order by expression")
+		}
+		fields[i] = col.name
+	}
+	for _, row := range rows {
+		v := reflect.ValueOf(row).Elem()
+		for _, name := range fields {
+			if !v.FieldByName(name).IsValid() {
+				return fmt.Errorf("unknown field: %s", name)
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := reflect.ValueOf(rows[i]).Elem(), reflect.ValueOf(rows[j]).Elem()
+		for k, name := range fields {
+			c := compareValues(sortKey(a.FieldByName(name)), sortKey(b.FieldByName(name)))
+			if c == 0 {
+				continue
+			}
+			if orderBy[k].desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// paginateRows 对合并后的结果应用 Offset 和 Limit
+func paginateRows(rows []interface{}, offset, limit int) []interface{} {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return nil
+		}
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// sortKey 将字段的值转换为可以比较的值，与数据库驱动使用的类型一致
+func sortKey(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		return val
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	return v.Interface()
+}
+
+// compareValues 比较两个值，NULL 排在最前
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y)
+		case float64:
+			return cmp.Compare(float64(x), y)
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, float64(y))
+		case float64:
+			return cmp.Compare(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return cmp.Compare(x, y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if x {
+				return 1
+			}
+			return -1
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCollection_FindWithOptions(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+	coll := sdb.NewClient().ShardedCollection(&ShardingOrder{})
+
+	// 每个分片返回前 Offset+Limit 行
+	mock0.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sharding_order` WHERE `status` = ? ORDER BY `amount` DESC LIMIT 3;")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "amount"}).
+			AddRow(2, 90.0).AddRow(4, 50.0).AddRow(6, 10.0))
+	mock1.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sharding_order` WHERE `status` = ? ORDER BY `amount` DESC LIMIT 3;")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "amount"}).
+			AddRow(1, 80.0).AddRow(3, 70.0).AddRow(5, 20.0))
+
+	res, err := coll.FindWithOptions(context.Background(), FindOptions{
+		OrderBy: []OrderBy{Desc(Col("Amount"))},
+		Offset:  1,
+		Limit:   2,
+	}, Col("Status").Eq(1))
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, r := range res {
+		ids = append(ids, r.(*ShardingOrder).OrderID)
+	}
+	assert.Equal(t, []int64{1, 3}, ids)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
+
+func TestShardedCollection_FindAll_Routed(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+	coll := sdb.NewClient().ShardedCollection(&ShardingOrder{})
+
+	// 条件中包含分片键时只查询路由到的分片，3 % 2 = 1
+	mock1.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `sharding_order` WHERE `order_id` = ?;")).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow(3))
+
+	res, err := coll.FindAll(context.Background(), Col("OrderID").Eq(int64(3)))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
+
+func TestShardedCollection_Aggregate(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+	coll := sdb.NewClient().ShardedCollection(&ShardingOrder{})
+
+	mock0.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `sharding_order` WHERE `status` = ?;")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock1.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `sharding_order` WHERE `status` = ?;")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	n, err := coll.Count(context.Background(), Col("Status").Eq(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+
+	mock0.ExpectQuery(regexp.QuoteMeta("SELECT SUM(`amount`) FROM `sharding_order`;")).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(10.5))
+	mock1.ExpectQuery(regexp.QuoteMeta("SELECT SUM(`amount`) FROM `sharding_order`;")).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(nil))
+	sum, err := coll.Sum(context.Background(), "Amount")
+	require.NoError(t, err)
+	assert.Equal(t, 10.5, sum)
+
+	mock1.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `sharding_order`;")).WillReturnError(sql.ErrConnDone)
+	mock0.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `sharding_order`;")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	_, err = coll.Count(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.ErrorContains(t, err, "shard order_db_1")
+}
+
+func TestShardedCollection_UpdateDelete(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+	coll := sdb.NewClient().ShardedCollection(&ShardingOrder{})
+
+	mock0.ExpectExec(regexp.QuoteMeta("UPDATE `sharding_order` SET `status` = ? WHERE `status` = ?;")).
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock1.ExpectExec(regexp.QuoteMeta("UPDATE `sharding_order` SET `status` = ? WHERE `status` = ?;")).
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	res, err := coll.Update(context.Background(), map[string]interface{}{"Status": 2}, Col("Status").Eq(1))
+	require.NoError(t, err)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), affected)
+	_, err = res.LastInsertId()
+	assert.Error(t, err)
+
+	mock0.ExpectExec(regexp.QuoteMeta("DELETE FROM `sharding_order` WHERE `order_id` = ?;")).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res, err = coll.Delete(context.Background(), Col("OrderID").Eq(int64(4)))
+	require.NoError(t, err)
+	affected, err = res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
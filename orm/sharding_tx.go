@@ -0,0 +1,237 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShardTxError 跨分片事务失败时返回，记录每个分片的最终状态
+type ShardTxError struct {
+	Err          error            // 导致事务失败的错误
+	Committed    []string         // 已经提交的分片
+	RolledBack   []string         // 已经回滚的分片
+	Failed       map[string]error // 提交或回滚失败的分片
+	Compensated  []string         // 补偿成功的分片
+	Compensation map[string]error // 补偿失败的分片
+}
+
+func (e *ShardTxError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("orm: sharding transaction failed: ")
+	sb.WriteString(e.Err.Error())
+	if len(e.Committed) > 0 {
+		sb.WriteString(fmt.Sprintf(", committed: %v", e.Committed))
+	}
+	if len(e.RolledBack) > 0 {
+		sb.WriteString(fmt.Sprintf(", rolled back: %v", e.RolledBack))
+	}
+	for _, name := range sortedKeys(e.Failed) {
+		sb.WriteString(fmt.Sprintf(", shard %s: %v", name, e.Failed[name]))
+	}
+	for _, name := range sortedKeys(e.Compensation) {
+		sb.WriteString(fmt.Sprintf(", compensate shard %s: %v", name, e.Compensation[name]))
+	}
+	return sb.String()
+}
+
+func (e *ShardTxError) Unwrap() error {
+	return e.Err
+}
+
+// Partial 是否有分片已经提交，此时各分片的数据可能不一致，需要根据 Compensation 人工处理
+func (e *ShardTxError) Partial() bool {
+	return len(e.Committed) > 0
+}
+
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ShardTx 跨分片事务，在第一次访问分片时开启该分片上的本地事务
+type ShardTx struct {
+	ctx  context.Context
+	sdb  *ShardingDB
+	opts *sql.TxOptions
+
+	mu            sync.Mutex
+	txs           map[string]*Tx
+	order         []string // 开启事务的顺序，也是提交的顺序
+	compensations map[string][]func(ctx context.Context) error
+}
+
+// Shard 返回指定分片上的事务
+func (st *ShardTx) Shard(name string) (*Tx, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if tx, ok := st.txs[name]; ok {
+		return tx, nil
+	}
+
+	db, ok := st.sdb.GetShardDB(name)
+	if !ok {
+		return nil, fmt.Errorf("shard %s not found: %w", name, ErrShardNotAvailable)
+	}
+	tx, err := db.BeginTx(st.ctx, st.opts)
+	if err != nil {
+		return nil, fmt.Errorf("shard %s: %w", name, err)
+	}
+	st.txs[name] = tx
+	st.order = append(st.order, name)
+	return tx, nil
+}
+
+// Route 根据模型的分片策略路由，返回目标分片上的事务和分表名。
+// 与普通查询不同，路由失败时不会退回默认数据库，而是直接返回错误
+func (st *ShardTx) Route(modelName string, values map[string]any) (*Tx, string, error) {
+	dbName, tableName, err := st.sdb.shardingManager.GetRouter().CalculateRoute(st.ctx, modelName, values)
+	if err != nil {
+		return nil, "", err
+	}
+	tx, err := st.Shard(dbName)
+	if err != nil {
+		return nil, "", err
+	}
+	return tx, tableName, nil
+}
+
+// Compensate 为 tx 所在的分片注册补偿操作。提交阶段部分分片提交成功、其余分片失败时，
+// 按注册的逆序执行已提交分片的补偿操作，撤销这些分片上的修改（saga）。
+// 补偿操作在事务结束之后执行，需要使用分片的 DB 而不是 tx
+func (st *ShardTx) Compensate(tx *Tx, fn func(ctx context.Context) error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for name, t := range st.txs {
+		if t == tx {
+			st.compensations[name] = append(st.compensations[name], fn)
+			return
+		}
+	}
+}
+
+// rollback 回滚所有未结束的事务
+func (st *ShardTx) rollback(names []string, txErr *ShardTxError) {
+	for _, name := range names {
+		if err := st.txs[name].RollBack(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			txErr.Failed[name] = err
+			continue
+		}
+		txErr.RolledBack = append(txErr.RolledBack, name)
+	}
+}
+
+// prepare 提交前确认每个分片的事务仍然可用，尽量在任何分片提交之前发现连接断开等问题
+func (st *ShardTx) prepare() (string, error) {
+	for _, name := range st.order {
+		rows, err := st.txs[name].queryContext(st.ctx, "SELECT 1")
+		if err != nil {
+			return name, err
+		}
+		_ = rows.Close()
+	}
+	return "", nil
+}
+
+// commit 两阶段提交：先确认所有分片可用，再依次提交。提交阶段出现错误时回滚剩余的分片并执行补偿
+func (st *ShardTx) commit() error {
+	txErr := &ShardTxError{
+		Failed:       make(map[string]error),
+		Compensation: make(map[string]error),
+	}
+
+	if name, err := st.prepare(); err != nil {
+		txErr.Err = fmt.Errorf("prepare shard %s: %w", name, err)
+		st.rollback(st.order, txErr)
+		return txErr
+	}
+
+	for i, name := range st.order {
+		if err := st.txs[name].Commit(); err != nil {
+			txErr.Err = fmt.Errorf("commit shard %s: %w", name, err)
+			txErr.Failed[name] = err
+			st.rollback(st.order[i+1:], txErr)
+			st.compensate(txErr)
+			return txErr
+		}
+		txErr.Committed = append(txErr.Committed, name)
+	}
+	return nil
+}
+
+// compensate 按提交的逆序执行已提交分片的补偿操作
+func (st *ShardTx) compensate(txErr *ShardTxError) {
+	for i := len(txErr.Committed) - 1; i >= 0; i-- {
+		name := txErr.Committed[i]
+		fns := st.compensations[name]
+		if len(fns) == 0 {
+			continue
+		}
+		var err error
+		for j := len(fns) - 1; j >= 0 && err == nil; j-- {
+			err = fns[j](st.ctx)
+		}
+		if err != nil {
+			txErr.Compensation[name] = err
+			continue
+		}
+		txErr.Compensated = append(txErr.Compensated, name)
+	}
+}
+
+// TxAll 在多个分片上执行事务，fn 中通过 Shard 或 Route 获取各分片上的事务，分片的事务在第一次访问时开启。
+// fn 返回错误时回滚所有分片；否则先确认所有分片的事务仍然可用，再按开启的顺序依次提交。
+// 数据库之间没有真正的两阶段提交，提交阶段仍然可能部分成功，此时返回 *ShardTxError，
+// 并执行通过 Compensate 注册的补偿操作：
+//
+//	err := sdb.TxAll(ctx, func(stx *orm.ShardTx) error {
+//		from, _, err := stx.Route("account", map[string]any{"UserID": fromID})
+//		if err != nil {
+//			return err
+//		}
+//		// 在 from 上扣款...
+//		stx.Compensate(from, func(ctx context.Context) error {
+//			// 在分片的 DB 上退回扣款
+//		})
+//		to, _, err := stx.Route("account", map[string]any{"UserID": toID})
+//		// 在 to 上入账...
+//		return err
+//	}, nil)
+func (sdb *ShardingDB) TxAll(ctx context.Context, fn func(stx *ShardTx) error, opts *sql.TxOptions) (err error) {
+	st := &ShardTx{
+		ctx:           ctx,
+		sdb:           sdb,
+		opts:          opts,
+		txs:           make(map[string]*Tx),
+		compensations: make(map[string][]func(ctx context.Context) error),
+	}
+
+	panicked := true
+	defer func() {
+		if panicked {
+			st.rollback(st.order, &ShardTxError{Failed: make(map[string]error)})
+		}
+	}()
+
+	if err = fn(st); err != nil {
+		panicked = false
+		txErr := &ShardTxError{Err: err, Failed: make(map[string]error)}
+		st.rollback(st.order, txErr)
+		if len(txErr.Failed) > 0 {
+			return txErr
+		}
+		return err
+	}
+
+	panicked = false
+	return st.commit()
+}
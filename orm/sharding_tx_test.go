@@ -0,0 +1,168 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newShardTxTestDB 创建包含 order_db_0、order_db_1 两个分片的 ShardingDB
+func newShardTxTestDB(t *testing.T) (*ShardingDB, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	newMock := func() (*DB, sqlmock.Sqlmock) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mockDB.Close() })
+		db, err := Open(mockDB, "mysql")
+		require.NoError(t, err)
+		return db, mock
+	}
+
+	defaultDB, _ := newMock()
+	db0, mock0 := newMock()
+	db1, mock1 := newMock()
+
+	sdb := NewShardingDB(defaultDB, NewShardingRouter())
+	sdb.RegisterShardStrategy("ShardingOrder", WithModStrategy("order_db_", 2, "order_", 2, "OrderID"), "")
+	sdb.RegisterShard("order_db_0", db0)
+	sdb.RegisterShard("order_db_1", db1)
+	return sdb, mock0, mock1
+}
+
+func TestShardingDB_TxAll(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+
+	mock0.ExpectBegin()
+	mock0.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock1.ExpectBegin()
+	mock1.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock0.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock1.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock0.ExpectCommit()
+	mock1.ExpectCommit()
+
+	err := sdb.TxAll(context.Background(), func(stx *ShardTx) error {
+		for _, id := range []int64{2, 3} {
+			tx, table, err := stx.Route("ShardingOrder", map[string]any{"OrderID": id})
+			if err != nil {
+				return err
+			}
+			if _, err = tx.execContext(context.Background(), "UPDATE "+table+" SET status = 1"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+	require.NoError(t, err)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
+
+func TestShardingDB_TxAll_Rollback(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+
+	mock0.ExpectBegin()
+	mock1.ExpectBegin()
+	mock0.ExpectRollback()
+	mock1.ExpectRollback()
+
+	bizErr := errors.New("insufficient balance")
+	err := sdb.TxAll(context.Background(), func(stx *ShardTx) error {
+		if _, err := stx.Shard("order_db_0"); err != nil {
+			return err
+		}
+		if _, err := stx.Shard("order_db_1"); err != nil {
+			return err
+		}
+		return bizErr
+	}, nil)
+	assert.Equal(t, bizErr, err)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+
+	err = sdb.TxAll(context.Background(), func(stx *ShardTx) error {
+		_, err := stx.Shard("order_db_9")
+		return err
+	}, nil)
+	assert.ErrorIs(t, err, ErrShardNotAvailable)
+}
+
+func TestShardingDB_TxAll_PrepareFailed(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+
+	connErr := errors.New("connection lost")
+	mock0.ExpectBegin()
+	mock1.ExpectBegin()
+	mock0.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock1.ExpectQuery("SELECT 1").WillReturnError(connErr)
+	mock0.ExpectRollback()
+	mock1.ExpectRollback()
+
+	err := sdb.TxAll(context.Background(), func(stx *ShardTx) error {
+		_, err := stx.Shard("order_db_0")
+		if err != nil {
+			return err
+		}
+		_, err = stx.Shard("order_db_1")
+		return err
+	}, nil)
+
+	var txErr *ShardTxError
+	require.ErrorAs(t, err, &txErr)
+	assert.ErrorIs(t, err, connErr)
+	assert.False(t, txErr.Partial())
+	assert.Equal(t, []string{"order_db_0", "order_db_1"}, txErr.RolledBack)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
+
+func TestShardingDB_TxAll_CommitFailed(t *testing.T) {
+	sdb, mock0, mock1 := newShardTxTestDB(t)
+
+	commitErr := errors.New("commit failed")
+	mock0.ExpectBegin()
+	mock1.ExpectBegin()
+	mock0.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock1.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock0.ExpectCommit()
+	mock1.ExpectCommit().WillReturnError(commitErr)
+
+	var compensated []string
+	err := sdb.TxAll(context.Background(), func(stx *ShardTx) error {
+		tx0, err := stx.Shard("order_db_0")
+		if err != nil {
+			return err
+		}
+		stx.Compensate(tx0, func(ctx context.Context) error {
+			compensated = append(compensated, "first")
+			return nil
+		})
+		stx.Compensate(tx0, func(ctx context.Context) error {
+			compensated = append(compensated, "second")
+			return nil
+		})
+		tx1, err := stx.Shard("order_db_1")
+		if err != nil {
+			return err
+		}
+		stx.Compensate(tx1, func(ctx context.Context) error {
+			compensated = append(compensated, "never")
+			return nil
+		})
+		return nil
+	}, nil)
+
+	var txErr *ShardTxError
+	require.ErrorAs(t, err, &txErr)
+	assert.ErrorIs(t, err, commitErr)
+	assert.True(t, txErr.Partial())
+	assert.Equal(t, []string{"order_db_0"}, txErr.Committed)
+	assert.Equal(t, commitErr, txErr.Failed["order_db_1"])
+	assert.Equal(t, []string{"order_db_0"}, txErr.Compensated)
+	assert.Equal(t, []string{"second", "first"}, compensated)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
@@ -1,8 +1,6 @@
 package orm
 
 import (
-	"errors"
-	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,33 +10,9 @@ type Sqlite struct {
 	BaseDialect
 }
 
+// BuildUpsert 使用 ON CONFLICT ... DO UPDATE，没有指定冲突列时使用主键
 func (s Sqlite) BuildUpsert(builder *strings.Builder, conflictCols []*Column, cols []*Column) {
-	if conflictCols == nil || len(conflictCols) == 0 {
-		panic(errors.New("sqlite must have conflict columns"))
-	}
-	if len(cols) == 0 {
-		panic(ferr.ErrUpsertRowNotFound)
-	}
-
-	builder.WriteString(" ON CONFLICT(")
-	for index, col := range cols {
-		col.model = s.model
-		col.Build(builder)
-		if index != len(cols)-1 {
-			builder.WriteString(", ")
-		}
-	}
-
-	builder.WriteString(") DO UPDATE SET ")
-
-	for index, col := range cols {
-		col.BuildWithoutQuote(builder)
-		builder.WriteString(" = EXCLUDED.")
-		col.BuildWithoutQuote(builder)
-		if index != len(cols)-1 {
-			builder.WriteString(", ")
-		}
-	}
+	buildOnConflict(builder, s.Quote, "sqlite", conflictCols, cols)
 }
 
 // Quote SQLite使用双引号作为标识符引用符
@@ -81,10 +55,9 @@ func (s Sqlite) JulianDay(dateExpr string) string {
 // CreateTableSQL 为SQLite生成建表语句
 func (s Sqlite) CreateTableSQL(m *model) string {
 	// 先调用基本实现生成通用的SQL
-	baseSQL := s.BaseDialect.CreateTableSQL(m)
-	newSQL := strings.ReplaceAll(baseSQL, "`", "\"")
-	// SQLite没有特殊的表选项，所以直接返回基础SQL
-	return newSQL + ";";
+	baseSQL := createTableSQL(&s, m, false)
+	// SQLite没有特殊的表选项，不支持在建表语句中定义索引，索引在建表之后创建
+	return appendIndexSQL(s.Quote, baseSQL, m)
 }
 
 // AlterTableSQL 实现SQLite特定的表结构修改语句
@@ -138,6 +111,10 @@ func (s Sqlite) ColumnType(f *field) string {
 	if f.sqlType != "" {
 		return f.sqlType
 	}
+	// 指针字段按指向的类型映射，NULL 由 nullable 决定
+	if f.typ.Kind() == reflect.Ptr {
+		return s.ColumnType(derefField(f))
+	}
 
 	// SQLite只有 NULL, INTEGER, REAL, TEXT, BLOB 5种类型
 	// 但为了兼容其他数据库，我们会使用更丰富的类型名
@@ -153,6 +130,10 @@ func (s Sqlite) ColumnType(f *field) string {
 		return "INTEGER"
 	case reflect.Float32, reflect.Float64:
 		return "REAL"
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.Uint8 {
+			return "BLOB"
+		}
 	case reflect.String:
 		if f.size > 0 {
 			return "TEXT(" + strconv.Itoa(f.size) + ")" // 注意SQLite实际上忽略这个大小
@@ -170,6 +151,8 @@ func (s Sqlite) ColumnType(f *field) string {
 			return "TEXT"
 		case "sql.NullInt64":
 			return "INTEGER"
+		case "sql.NullInt32", "sql.NullInt16", "sql.NullByte":
+			return "INTEGER"
 		case "sql.NullFloat64":
 			return "REAL"
 		case "sql.NullBool":
@@ -187,4 +170,13 @@ func (s Sqlite) ColumnType(f *field) string {
 
 func init() {
 	RegisterDialect("sqlite", &Sqlite{})
-}
\ No newline at end of file
+}
+// SupportsReturning 使用 LastInsertId 回填自增ID，以兼容不支持 RETURNING 的旧版本 SQLite
+func (s Sqlite) SupportsReturning() bool {
+	return false
+}
+
+// FirstInsertID SQLite 批量插入时 LastInsertId 返回最后一行的自增ID
+func (s Sqlite) FirstInsertID(lastID int64, rows int) int64 {
+	return lastID - int64(rows) + 1
+}
@@ -0,0 +1,149 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// WithStmtCache 缓存最近使用的 size 条SQL的预编译语句，Selector、Inserter、Updater 和 Deleter
+// 生成的SQL相同时直接复用预编译语句，省去数据库重复解析SQL的开销
+// 语句按最近最少使用淘汰，事务中的查询和 WithQueryComment 附加了注释的SQL不使用缓存；预编译失败时退回直接执行
+func WithStmtCache(size int) DBOption {
+	return func(db *DB) error {
+		if size <= 0 {
+			return errors.New("orm: statement cache size must be positive")
+		}
+		db.stmtCache = newStmtCache(size, func(ctx context.Context, query string) (*sql.Stmt, error) {
+			return db.sqlDB.PrepareContext(ctx, query)
+		})
+		return nil
+	}
+}
+
+// StmtCacheStats 预编译语句缓存的统计信息
+type StmtCacheStats struct {
+	Size      int   // 当前缓存的语句数
+	Hits      int64 // 命中次数
+	Misses    int64 // 未命中并预编译的次数
+	Evictions int64 // 淘汰的语句数
+}
+
+// StmtCacheStats 返回预编译语句缓存的统计信息，未启用缓存时返回零值
+func (db *DB) StmtCacheStats() StmtCacheStats {
+	if db.stmtCache == nil {
+		return StmtCacheStats{}
+	}
+	return db.stmtCache.stats()
+}
+
+// cachedStmt 缓存的预编译语句，refs 为正在使用的次数，被淘汰后等到不再使用时才关闭
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// stmtCache 以SQL为键的预编译语句LRU缓存
+type stmtCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	items   map[string]*list.Element
+	prepare func(ctx context.Context, query string) (*sql.Stmt, error)
+
+	hits, misses, evictions int64
+}
+
+func newStmtCache(size int, prepare func(ctx context.Context, query string) (*sql.Stmt, error)) *stmtCache {
+	return &stmtCache{
+		size:    size,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element, size),
+		prepare: prepare,
+	}
+}
+
+// acquire 获取 query 的预编译语句，未缓存时预编译并加入缓存，使用完毕后需要调用 release
+func (c *stmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if elem, ok := c.items[query]; ok {
+		c.ll.MoveToFront(elem)
+		cs := elem.Value.(*cachedStmt)
+		cs.refs++
+		c.hits++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// 预编译需要访问数据库，不持有锁
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 其他goroutine已经预编译了相同的SQL时使用已缓存的语句
+	if elem, ok := c.items[query]; ok {
+		_ = stmt.Close()
+		c.ll.MoveToFront(elem)
+		cs := elem.Value.(*cachedStmt)
+		cs.refs++
+		return cs, nil
+	}
+
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.ll.PushFront(cs)
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+	return cs, nil
+}
+
+// release 归还语句，已被淘汰且不再使用的语句会被关闭
+func (c *stmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+// evict 从缓存中移除语句，调用方需要持有锁
+func (c *stmtCache) evict(elem *list.Element) {
+	cs := elem.Value.(*cachedStmt)
+	c.ll.Remove(elem)
+	delete(c.items, cs.query)
+	c.evictions++
+	cs.evicted = true
+	if cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+// close 移除并关闭所有语句
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.ll.Len() > 0 {
+		c.evict(c.ll.Back())
+	}
+}
+
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return StmtCacheStats{
+		Size:      c.ll.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
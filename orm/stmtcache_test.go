@@ -0,0 +1,134 @@
+package orm
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtCache(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	db, err := Open(mockDB, "mysql", WithStmtCache(2))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	selectSQL := "SELECT * FROM `test_model` WHERE `id` = ?;"
+	deleteSQL := "DELETE FROM `test_model` WHERE `id` = ?;"
+	updateSQL := "UPDATE `test_model` SET `name` = ? WHERE `id` = ?;"
+
+	// 相同的SQL只预编译一次
+	prepSelect := mock.ExpectPrepare(selectSQL)
+	prepSelect.ExpectQuery().WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(1, "Tom", nil))
+	prepSelect.ExpectQuery().WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(2, "Jerry", nil))
+
+	// 缓存已满时淘汰最久未使用的DELETE语句
+	prepDelete := mock.ExpectPrepare(deleteSQL)
+	prepDelete.ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	prepDelete.WillBeClosed()
+	prepSelect.ExpectQuery().WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(3, "Bob", nil))
+	prepUpdate := mock.ExpectPrepare(updateSQL)
+	prepUpdate.ExpectExec().WithArgs("Tom", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for _, id := range []int{1, 2} {
+		res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(id)).Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, id, res.ID)
+	}
+	_, err = RegisterDeleter[TestModel](db).Delete().Where(Col("ID").Eq(1)).Exec(ctx)
+	require.NoError(t, err)
+	_, err = RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(3)).Get(ctx)
+	require.NoError(t, err)
+	_, err = RegisterUpdater[TestModel](db).Update().Set(Col("Name"), "Tom").Where(Col("ID").Eq(1)).Exec(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, StmtCacheStats{Size: 2, Hits: 2, Misses: 3, Evictions: 1}, db.StmtCacheStats())
+
+	// 关闭DB时关闭所有缓存的语句
+	prepSelect.WillBeClosed()
+	prepUpdate.WillBeClosed()
+	mock.ExpectClose()
+	require.NoError(t, db.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStmtCache_ReleaseEvicted(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	cache := newStmtCache(1, mockDB.PrepareContext)
+	ctx := context.Background()
+
+	prepA := mock.ExpectPrepare("SELECT 1")
+	mock.ExpectPrepare("SELECT 2")
+
+	a, err := cache.acquire(ctx, "SELECT 1")
+	require.NoError(t, err)
+	b, err := cache.acquire(ctx, "SELECT 2")
+	require.NoError(t, err)
+
+	// 被淘汰的语句仍在使用，归还后才关闭
+	assert.True(t, a.evicted)
+	prepA.WillBeClosed()
+	cache.release(a)
+	cache.release(b)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithStmtCache_InvalidSize(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	_, err = Open(mockDB, "mysql", WithStmtCache(0))
+	assert.Error(t, err)
+}
+
+func TestStmtCache_QueryComment(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	db, err := Open(mockDB, "mysql", WithStmtCache(2), WithQueryComment(CommentTagsFromContext))
+	require.NoError(t, err)
+
+	selectSQL := "SELECT * FROM `test_model` WHERE `id` = ?;"
+	rows := func(id int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "job"}).AddRow(id, "Tom", nil)
+	}
+
+	// 每个请求的注释不同，附加了注释的SQL直接执行，不预编译
+	mock.ExpectQuery("SELECT * FROM `test_model` WHERE `id` = ? /*request_id='1'*/;").WithArgs(1).WillReturnRows(rows(1))
+	mock.ExpectQuery("SELECT * FROM `test_model` WHERE `id` = ? /*request_id='2'*/;").WithArgs(2).WillReturnRows(rows(2))
+	// 没有注释的SQL仍然使用预编译语句缓存
+	prepSelect := mock.ExpectPrepare(selectSQL)
+	prepSelect.ExpectQuery().WithArgs(3).WillReturnRows(rows(3))
+	prepSelect.ExpectQuery().WithArgs(4).WillReturnRows(rows(4))
+
+	for _, id := range []int{1, 2} {
+		ctx := WithCommentTags(context.Background(), map[string]string{"request_id": strconv.Itoa(id)})
+		res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(id)).Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, id, res.ID)
+	}
+	for _, id := range []int{3, 4} {
+		res, err := RegisterSelector[TestModel](db).Select().Where(Col("ID").Eq(id)).Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, id, res.ID)
+	}
+
+	assert.Equal(t, StmtCacheStats{Size: 1, Hits: 1, Misses: 1}, db.StmtCacheStats())
+
+	prepSelect.WillBeClosed()
+	mock.ExpectClose()
+	require.NoError(t, db.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -41,3 +41,43 @@ func (sq *SubQuery[T]) Build(builder *strings.Builder, args *[]any) any {
 
 	return &mp
 }
+
+// subQuery 可以作为 IN 右侧子查询的查询，Selector 和 SubQuery 都实现了该接口
+type subQuery interface {
+	subQuery() (*Query, error)
+}
+
+func (sq *SubQuery[T]) subQuery() (*Query, error) {
+	return sq.selector.Build()
+}
+
+func (s *Selector[T]) subQuery() (*Query, error) {
+	return s.Build()
+}
+
+// subQueryExpr 条件中的子查询，用于 IN 和 EXISTS
+type subQueryExpr struct {
+	build func() (*Query, error)
+}
+
+func (e *subQueryExpr) expr() {}
+
+// Exists 生成 EXISTS 条件，子查询可以通过 FromTable 引用外层查询的表：
+//
+//	RegisterSelector[User](db).Select().Where(Exists(
+//		RegisterSelector[Order](db).Select(Raw("1")).
+//			Where(Col("UserID").Eq(FromTable("user", Col("ID"))))))
+func Exists(q QueryBuilder) *Predicate {
+	return &Predicate{
+		op:    opEXISTS,
+		right: &subQueryExpr{build: q.Build},
+	}
+}
+
+// NotExists 生成 NOT EXISTS 条件
+func NotExists(q QueryBuilder) *Predicate {
+	return &Predicate{
+		op:    opNOTEXISTS,
+		right: &subQueryExpr{build: q.Build},
+	}
+}
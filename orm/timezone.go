@@ -0,0 +1,125 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+)
+
+// WithTimeLocation 设置时区策略：写入数据库的 time.Time 参数统一转换为UTC，
+// 查询结果中的 time.Time 字段统一转换为loc，避免不同驱动和数据库会话时区带来的偏差
+// 驱动需要按UTC解析时间，如MySQL驱动使用 loc=UTC&parseTime=true
+func WithTimeLocation(loc *time.Location) DBOption {
+	return func(db *DB) error {
+		if loc == nil {
+			return errors.New("orm: time location cannot be nil")
+		}
+		db.timeLoc = loc
+		return nil
+	}
+}
+
+// normalizeArgs 将参数中的时间转换为UTC，未设置时区策略时原样返回
+func (db *DB) normalizeArgs(args []any) []any {
+	if db.timeLoc == nil {
+		return args
+	}
+
+	var normalized []any
+	for i, arg := range args {
+		var v any
+		switch t := arg.(type) {
+		case time.Time:
+			v = t.UTC()
+		case *time.Time:
+			if t == nil {
+				continue
+			}
+			v = t.UTC()
+		case sql.NullTime:
+			if !t.Valid {
+				continue
+			}
+			v = sql.NullTime{Time: t.Time.UTC(), Valid: true}
+		default:
+			continue
+		}
+		// 不修改调用方的参数切片
+		if normalized == nil {
+			normalized = make([]any, len(args))
+			copy(normalized, args)
+		}
+		normalized[i] = v
+	}
+
+	if normalized == nil {
+		return args
+	}
+	return normalized
+}
+
+// localizeTimes 将扫描结果中的时间字段转换为配置的时区，包括嵌入结构体中的字段
+func (db *DB) localizeTimes(val reflect.Value) {
+	if db.timeLoc == nil {
+		return
+	}
+	localizeValue(val, db.timeLoc)
+}
+
+// localizeValue 递归转换结构体中的时间字段
+func localizeValue(val reflect.Value, loc *time.Location) {
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	switch val.Type() {
+	case timeType:
+		if val.CanSet() {
+			t := val.Interface().(time.Time)
+			if !t.IsZero() {
+				val.Set(reflect.ValueOf(t.In(loc)))
+			}
+		}
+		return
+	case nullTimeType:
+		if val.CanSet() {
+			nt := val.Interface().(sql.NullTime)
+			if nt.Valid {
+				val.Set(reflect.ValueOf(sql.NullTime{Time: nt.Time.In(loc), Valid: true}))
+			}
+		}
+		return
+	}
+
+	if val.Kind() != reflect.Struct {
+		return
+	}
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		if typ.Field(i).PkgPath != "" {
+			continue
+		}
+		localizeValue(val.Field(i), loc)
+	}
+}
+
+// DateEq 按日期匹配时间列，d所在时区的当天零点到次日零点之间的记录都会命中
+// 生成 col >= ? AND col < ? 的范围条件，不依赖数据库的日期函数，也可以使用索引
+func (c *Column) DateEq(d time.Time) *Predicate {
+	start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+	end := start.AddDate(0, 0, 1)
+	return &Predicate{
+		left:  c.Gte(start),
+		op:    opAND,
+		right: c.Lt(end),
+	}
+}
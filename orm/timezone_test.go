@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EventModel struct {
+	ID        int
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+func TestDB_TimeLocation(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	shanghai := time.FixedZone("CST", 8*3600)
+	db, err := Open(mockDB, "mysql", WithTimeLocation(shanghai))
+	require.NoError(t, err)
+
+	local := time.Date(2024, 5, 1, 8, 30, 0, 0, shanghai)
+	utc := local.UTC()
+
+	// 写入时转换为UTC
+	mock.ExpectExec("INSERT INTO `event_model` \\(`id`, `created_at`, `deleted_at`\\) VALUES \\(\\?, \\?, \\?\\);").
+		WithArgs(1, utc, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = RegisterInserter[EventModel](db).Insert(nil, &EventModel{ID: 1, CreatedAt: local}).Exec(context.Background())
+	require.NoError(t, err)
+
+	// 读取时转换为配置的时区
+	mock.ExpectQuery("SELECT \\* FROM `event_model` WHERE `id` = \\?;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "deleted_at"}).AddRow(1, utc, utc))
+	res, err := RegisterSelector[EventModel](db).Select().Where(Col("ID").Eq(1)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, shanghai, res.CreatedAt.Location())
+	assert.True(t, res.CreatedAt.Equal(local))
+	require.NotNil(t, res.DeletedAt)
+	assert.Equal(t, shanghai, res.DeletedAt.Location())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDB_NormalizeArgs(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, loc)
+	args := []any{1, now, &now}
+
+	assert.Equal(t, args, (&DB{}).normalizeArgs(args))
+
+	normalized := (&DB{timeLoc: loc}).normalizeArgs(args)
+	assert.Equal(t, []any{1, now.UTC(), now.UTC()}, normalized)
+	// 不修改原参数
+	assert.Equal(t, now, args[1])
+}
+
+func TestWithTimeLocation_Nil(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	_, err = Open(mockDB, "mysql", WithTimeLocation(nil))
+	assert.Error(t, err)
+}
+
+func TestColumn_DateEq(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	loc := time.FixedZone("CST", 8*3600)
+	d := time.Date(2024, 5, 1, 15, 4, 5, 0, loc)
+
+	q, err := RegisterSelector[EventModel](db).Select().Where(Col("CreatedAt").DateEq(d)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `event_model` WHERE `created_at` >= ? AND `created_at` < ?;", q.SQL)
+	assert.Equal(t, []any{
+		time.Date(2024, 5, 1, 0, 0, 0, 0, loc),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, loc),
+	}, q.Args)
+}
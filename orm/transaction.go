@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/fyerfyer/fyer-webframe/orm/internal/ferr"
 
 	"github.com/fyerfyer/fyer-kit/pool"
@@ -13,40 +17,266 @@ type Tx struct {
 	db       *DB
 	tx       *sql.Tx
 	poolConn pool.Connection // 来自连接池的连接
+	handler  Handler         // 在事务上执行查询的处理器链，首次查询时创建
+
+	afterCommit []func() // 提交成功后执行，用于推迟缓存失效
+
+	// 嵌套事务使用外层事务的连接，提交和回滚对应释放保存点和回滚到保存点
+	parent     *Tx
+	savepoint  string
+	savepoints int  // 已经创建的保存点个数，用于生成保存点名称
+	done       bool // 嵌套事务已经提交或回滚
 }
 
 func (t *Tx) getModel(val any) (*model, error) {
-	m, err := t.db.getModel(val)
-	if err != nil {
-		return nil, err
-	}
-	// 确保从事务中获取的模型也设置了方言
-	m.SetDialect(t.db.dialect)
-	return m, nil
+	return t.db.getModel(val)
 }
 
 func (t *Tx) getDB() *DB {
 	return t.db
 }
 
-func (t *Tx) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+func (t *Tx) queryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { err = translateError(err) }()
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	query, args = t.db.commentSQL(ctx, query), t.db.normalizeArgs(args)
+	query, tag := t.db.killTag(ctx, query)
+	if t.db.queryKill != nil {
+		defer func(start time.Time) { t.db.queryKill.done(ctx, t.db, tag, start, err) }(time.Now())
+	}
+	if t.db.queryLog != nil {
+		start := time.Now()
+		defer func() { t.db.logQuery(ctx, query, args, start, nil, err) }()
+	}
 	return t.tx.QueryContext(ctx, query, args...)
 }
 
-func (t *Tx) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+func (t *Tx) execContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	defer func() { err = translateError(err) }()
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	query, args = t.db.commentSQL(ctx, query), t.db.normalizeArgs(args)
+	query, tag := t.db.killTag(ctx, query)
+	if t.db.queryKill != nil {
+		defer func(start time.Time) { t.db.queryKill.done(ctx, t.db, tag, start, err) }(time.Now())
+	}
+	if t.db.queryLog != nil {
+		start := time.Now()
+		defer func() { t.db.logQuery(ctx, query, args, start, res, err) }()
+	}
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
+// prepareContext 在事务上预编译语句，语句在事务结束时失效
+func (t *Tx) prepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.tx.PrepareContext(ctx, t.db.commentSQL(ctx, query))
+}
+
+func (t *Tx) queryStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { err = translateError(err) }()
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	args = t.db.normalizeArgs(args)
+	if t.db.queryKill != nil {
+		defer func(start time.Time) { t.db.queryKill.done(ctx, t.db, "", start, err) }(time.Now())
+	}
+	if t.db.queryLog != nil {
+		start := time.Now()
+		defer func() { t.db.logQuery(ctx, query, args, start, nil, err) }()
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (t *Tx) execStmtContext(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (res sql.Result, err error) {
+	defer func() { err = translateError(err) }()
+	if err := acquireBudget(ctx); err != nil {
+		return nil, err
+	}
+	defer recordBudget(ctx, time.Now())
+	args = t.db.normalizeArgs(args)
+	if t.db.queryKill != nil {
+		defer func(start time.Time) { t.db.queryKill.done(ctx, t.db, "", start, err) }(time.Now())
+	}
+	if t.db.queryLog != nil {
+		start := time.Now()
+		defer func() { t.db.logQuery(ctx, query, args, start, res, err) }()
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// getHandler 返回事务的处理器链，DB的中间件同样生效，最终的查询在事务绑定的连接上执行
 func (t *Tx) getHandler() Handler {
-	return t.db.handler
+	if t.handler == nil {
+		t.handler = BuildChain(&txCoreHandler{tx: t}, t.db.middlewares)
+	}
+	return t.handler
 }
 
 func (t *Tx) HandleQuery(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
-	return t.db.handler.QueryHandler(ctx, qc)
+	qc.inTx = true
+	return t.getHandler().QueryHandler(ctx, qc)
+}
+
+// txCoreHandler 事务的核心处理器，与 CoreHandler 相同但在事务上执行查询
+type txCoreHandler struct {
+	tx *Tx
+}
+
+func (c *txCoreHandler) QueryHandler(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+	switch qc.QueryType {
+	case "query":
+		var (
+			rows *sql.Rows
+			err  error
+		)
+		if qc.stmt != nil {
+			rows, err = c.tx.queryStmtContext(ctx, qc.stmt, qc.Query.SQL, qc.Query.Args...)
+		} else {
+			rows, err = c.tx.queryContext(ctx, qc.Query.SQL, qc.Query.Args...)
+		}
+		return &QueryResult{
+			Rows: rows,
+			Err:  err,
+		}, err
+	case "exec":
+		var (
+			res sql.Result
+			err error
+		)
+		if qc.stmt != nil {
+			res, err = c.tx.execStmtContext(ctx, qc.stmt, qc.Query.SQL, qc.Query.Args...)
+		} else {
+			res, err = c.tx.execContext(ctx, qc.Query.SQL, qc.Query.Args...)
+		}
+		return &QueryResult{
+			Result: Result{
+				res: res,
+				err: err,
+			},
+			Err: err,
+		}, err
+	default:
+		return nil, fmt.Errorf("unknown query type: %s", qc.QueryType)
+	}
+}
+
+// BeginTx 在事务中开启嵌套事务，嵌套事务通过保存点实现：提交时释放保存点，变更随外层事务一起提交；
+// 回滚时回滚到保存点，外层事务可以继续执行。保存点不能修改隔离级别，opt 被忽略
+func (t *Tx) BeginTx(ctx context.Context, opt *sql.TxOptions) (*Tx, error) {
+	root := t
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.savepoints++
+	name := "sp_" + strconv.Itoa(root.savepoints)
+	if err := t.execSavepoint(ctx, "SAVEPOINT", name); err != nil {
+		return nil, err
+	}
+	return &Tx{db: t.db, tx: t.tx, parent: t, savepoint: name}, nil
+}
+
+// Tx 在事务中执行嵌套事务闭包，fn 返回错误或 panic 时回滚到保存点，否则释放保存点。
+// 签名与 DB.Tx 相同，接收 DB 或 Tx 的事务函数可以在事务内外组合使用
+func (t *Tx) Tx(ctx context.Context, fn func(tx *Tx) error, opt *sql.TxOptions) (err error) {
+	var nested *Tx
+	nested, err = t.BeginTx(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			_ = nested.RollBack()
+		}
+	}()
+
+	err = fn(nested)
+	if err != nil {
+		return err
+	}
+
+	err = nested.Commit()
+	panicked = false
+	return err
+}
+
+// SavepointDialect 保存点语法与标准 SQL 不同的方言实现该接口，例如 SQL Server 使用 SAVE TRANSACTION
+type SavepointDialect interface {
+	// SavepointSQL 返回保存点语句，action 为 SAVEPOINT、ROLLBACK TO SAVEPOINT 或 RELEASE SAVEPOINT，
+	// 返回空字符串时跳过该操作
+	SavepointSQL(action, name string) string
+}
+
+// savepointSQL 生成保存点语句，内置的方言都使用标准语法，保存点名称按方言引用
+func savepointSQL(d Dialect, action, name string) string {
+	if sd, ok := d.(SavepointDialect); ok {
+		return sd.SavepointSQL(action, name)
+	}
+	return action + " " + d.Quote(name)
+}
+
+// txKey 上下文中事务的键
+type txKey struct{}
+
+// WithTx 返回携带事务 tx 的上下文，使用该上下文调用同一个 DB 的 Tx 时不再开启新事务，
+// 而是在 tx 中开启嵌套事务，这样各自调用 DB.Tx 的函数可以组合到同一个事务中
+func WithTx(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// txFromContext 返回上下文中属于 db 的事务
+func txFromContext(ctx context.Context, db *DB) *Tx {
+	tx, _ := ctx.Value(txKey{}).(*Tx)
+	if tx == nil || tx.db != db {
+		return nil
+	}
+	return tx
+}
+
+func (t *Tx) execSavepoint(ctx context.Context, action, name string) error {
+	query := savepointSQL(t.db.dialect, action, name)
+	if query == "" {
+		return nil
+	}
+	_, err := t.execContext(ctx, query)
+	return err
+}
+
+// endSavepoint 结束嵌套事务，提交成功后的回调交给外层事务，回滚时丢弃
+func (t *Tx) endSavepoint(action string) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if err := t.execSavepoint(context.Background(), action, t.savepoint); err != nil {
+		return err
+	}
+	if action == "RELEASE SAVEPOINT" {
+		t.parent.afterCommit = append(t.parent.afterCommit, t.afterCommit...)
+	}
+	t.afterCommit = nil
+	return nil
 }
 
 func (t *Tx) Commit() error {
+	if t.parent != nil {
+		return t.endSavepoint("RELEASE SAVEPOINT")
+	}
 	err := t.tx.Commit()
+	if err == nil {
+		for _, fn := range t.afterCommit {
+			fn()
+		}
+	}
+	t.afterCommit = nil
 
 	// 如果是连接池模式，归还连接
 	if t.poolConn != nil {
@@ -58,6 +288,9 @@ func (t *Tx) Commit() error {
 }
 
 func (t *Tx) RollBack() error {
+	if t.parent != nil {
+		return t.endSavepoint("ROLLBACK TO SAVEPOINT")
+	}
 	err := t.tx.Rollback()
 
 	// 如果是连接池模式，归还连接
@@ -70,6 +303,12 @@ func (t *Tx) RollBack() error {
 }
 
 func (t *Tx) RollbackIfNotCommitted() error {
+	if t.parent != nil {
+		if err := t.RollBack(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			return err
+		}
+		return nil
+	}
 	if t.tx != nil {
 		err := t.tx.Rollback()
 
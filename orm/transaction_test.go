@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx_HandleQuery(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	// 只有一个连接时，不在事务上执行的查询会一直等待事务占用的连接
+	mockDB.SetMaxOpenConns(1)
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	var calls int
+	db.Use(func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, qc *QueryContext) (*QueryResult, error) {
+			calls++
+			return next.QueryHandler(ctx, qc)
+		})
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?);").
+		WithArgs(1, "a").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT * FROM `bulk_user` WHERE `id` = ?;").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	mock.ExpectCommit()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	_, err = RegisterInserter[BulkUser](tx).Insert(nil, &BulkUser{ID: 1, Name: "a"}).Exec(ctx)
+	require.NoError(t, err)
+	user, err := RegisterSelector[BulkUser](tx).Select().Where(Col("ID").Eq(1)).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a", user.Name)
+	require.NoError(t, tx.Commit())
+
+	// DB的中间件在事务中同样生效
+	assert.Equal(t, 2, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTx_NestedSavepoint(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	mockDB.SetMaxOpenConns(1)
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?);").
+		WithArgs(1, "a").WillReturnResult(sqlmock.NewResult(1, 1))
+	// 内层失败只回滚到保存点
+	mock.ExpectExec("SAVEPOINT `sp_1`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?);").
+		WithArgs(2, "b").WillReturnError(errors.New("duplicate"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT `sp_1`").WillReturnResult(sqlmock.NewResult(0, 0))
+	// 内层成功释放保存点，再嵌套一层
+	mock.ExpectExec("SAVEPOINT `sp_2`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT `sp_3`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `bulk_user` (`id`, `name`) VALUES (?, ?);").
+		WithArgs(3, "c").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT `sp_3`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT `sp_2`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	insert := func(l Layer, id int, name string) error {
+		_, err := RegisterInserter[BulkUser](l).Insert(nil, &BulkUser{ID: id, Name: name}).Exec(ctx)
+		return err
+	}
+
+	var released []string
+	err = db.Tx(ctx, func(tx *Tx) error {
+		if err := insert(tx, 1, "a"); err != nil {
+			return err
+		}
+		err := tx.Tx(ctx, func(inner *Tx) error {
+			inner.afterCommit = append(inner.afterCommit, func() { released = append(released, "rolled back") })
+			return insert(inner, 2, "b")
+		}, nil)
+		assert.EqualError(t, err, "duplicate")
+
+		// 通过上下文传递事务时，DB.Tx 开启嵌套事务
+		return db.Tx(WithTx(ctx, tx), func(inner *Tx) error {
+			return inner.Tx(ctx, func(deepest *Tx) error {
+				deepest.afterCommit = append(deepest.afterCommit, func() { released = append(released, "committed") })
+				return insert(deepest, 3, "c")
+			}, nil)
+		}, nil)
+	}, nil)
+	require.NoError(t, err)
+
+	// 回滚到保存点的回调被丢弃，释放的保存点的回调在最外层提交后执行
+	assert.Equal(t, []string{"committed"}, released)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTx_NestedPanic(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer mockDB.Close()
+	db, err := Open(mockDB, "postgresql")
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT "sp_1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT "sp_1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.Panics(t, func() {
+		_ = db.Tx(context.Background(), func(tx *Tx) error {
+			return tx.Tx(context.Background(), func(inner *Tx) error {
+				panic("boom")
+			}, nil)
+		}, nil)
+	})
+
+	tx := &Tx{db: db, parent: &Tx{db: db}, savepoint: "sp_1", done: true}
+	assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+	assert.NoError(t, tx.RollbackIfNotCommitted())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedCollection 是带类型参数的集合，Find 和 FindAll 直接返回 *T，不需要类型断言
+// 与 Collection 共用同一套实现，事务、钩子和缓存失效的行为完全相同
+//
+//	users := orm.CollectionOf[User](client)
+//	user, err := users.Find(ctx, orm.Col("ID").Eq(1)) // user 的类型为 *User
+type TypedCollection[T any] struct {
+	coll *Collection
+}
+
+// CollectionOf 返回模型 T 的集合。Go 的方法不能有类型参数，因此以函数的形式提供，
+// 事务中的客户端返回的集合同样在事务上执行查询
+func CollectionOf[T any](c *Client) *TypedCollection[T] {
+	return &TypedCollection[T]{coll: c.Collection(new(T))}
+}
+
+// Untyped 返回底层基于反射的集合
+func (c *TypedCollection[T]) Untyped() *Collection {
+	return c.coll
+}
+
+// WithInvalidateCache 返回写操作成功后总是使缓存失效的集合，即使模型关闭了自动失效
+func (c *TypedCollection[T]) WithInvalidateCache() *TypedCollection[T] {
+	return &TypedCollection[T]{coll: c.coll.WithInvalidateCache()}
+}
+
+// WithInvalidateTags 返回写操作成功后按指定标签使缓存失效的集合
+func (c *TypedCollection[T]) WithInvalidateTags(tags ...string) *TypedCollection[T] {
+	return &TypedCollection[T]{coll: c.coll.WithInvalidateTags(tags...)}
+}
+
+// WithoutInvalidateCache 返回写操作成功后不使缓存失效的集合
+func (c *TypedCollection[T]) WithoutInvalidateCache() *TypedCollection[T] {
+	return &TypedCollection[T]{coll: c.coll.WithoutInvalidateCache()}
+}
+
+// Find 查找单个记录，没有匹配的记录时返回 sql.ErrNoRows
+func (c *TypedCollection[T]) Find(ctx context.Context, where ...Condition) (*T, error) {
+	res, err := c.coll.Find(ctx, where...)
+	if err != nil {
+		return nil, err
+	}
+	return typedResult[T](res)
+}
+
+// FindAll 查找所有匹配的记录
+func (c *TypedCollection[T]) FindAll(ctx context.Context, where ...Condition) ([]*T, error) {
+	return c.FindWithOptions(ctx, FindOptions{}, where...)
+}
+
+// FindWithOptions 使用选项查找记录
+func (c *TypedCollection[T]) FindWithOptions(ctx context.Context, opts FindOptions, where ...Condition) ([]*T, error) {
+	res, err := c.coll.FindWithOptions(ctx, opts, where...)
+	if err != nil {
+		return nil, err
+	}
+	return typedResults[T](res)
+}
+
+// Insert 插入记录
+func (c *TypedCollection[T]) Insert(ctx context.Context, model *T) (Result, error) {
+	return c.coll.Insert(ctx, model)
+}
+
+// Update 更新记录，update 的键为字段名或列名
+func (c *TypedCollection[T]) Update(ctx context.Context, update map[string]any, where ...Condition) (Result, error) {
+	return c.coll.Update(ctx, update, where...)
+}
+
+// Delete 删除记录
+func (c *TypedCollection[T]) Delete(ctx context.Context, where ...Condition) (Result, error) {
+	return c.coll.Delete(ctx, where...)
+}
+
+// Count 统计匹配的记录数
+func (c *TypedCollection[T]) Count(ctx context.Context, where ...Condition) (int64, error) {
+	return c.coll.Count(ctx, where...)
+}
+
+// Sum 对字段求和，没有匹配的记录时返回0
+func (c *TypedCollection[T]) Sum(ctx context.Context, field string, where ...Condition) (float64, error) {
+	return c.coll.Sum(ctx, field, where...)
+}
+
+// typedResult 把 Collection 返回的结果转换为 *T
+func typedResult[T any](res any) (*T, error) {
+	val, ok := res.(*T)
+	if !ok {
+		return nil, fmt.Errorf("orm: unexpected collection result %T, want %T", res, new(T))
+	}
+	return val, nil
+}
+
+func typedResults[T any](res []any) ([]*T, error) {
+	vals := make([]*T, 0, len(res))
+	for _, r := range res {
+		val, err := typedResult[T](r)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedCollection_Find(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+	users := CollectionOf[TestModel](New(db))
+	assert.Equal(t, "TestModel", users.Untyped().modelName)
+
+	mock.ExpectQuery("SELECT (.+) FROM `test_model` WHERE `id` = ?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}).
+			AddRow(1, "Test User", sql.NullString{String: "Developer", Valid: true}))
+	user, err := users.Find(context.Background(), Col("ID").Eq(1))
+	require.NoError(t, err)
+	assert.Equal(t, &TestModel{ID: 1, Name: "Test User", Job: sql.NullString{String: "Developer", Valid: true}}, user)
+
+	mock.ExpectQuery("SELECT (.+) FROM `test_model` WHERE `id` = ?").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "job"}))
+	user, err = users.Find(context.Background(), Col("ID").Eq(2))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, user)
+
+	mock.ExpectQuery("SELECT (.+) FROM `test_model` WHERE `id` > \\? ORDER BY `id` DESC LIMIT 2;").
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(2, "User 2").
+			AddRow(1, "User 1"))
+	all, err := users.FindWithOptions(context.Background(), FindOptions{
+		Limit:   2,
+		OrderBy: []OrderBy{Desc(Col("ID"))},
+	}, Col("ID").Gt(0))
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "User 2", all[0].Name)
+	assert.Equal(t, 1, all[1].ID)
+
+	mock.ExpectQuery("SELECT (.+) FROM `test_model`;").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	all, err = users.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTypedCollection_Write(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	mockDB.SetMaxOpenConns(1)
+
+	db, err := Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `test_model`").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("UPDATE `test_model` SET `name` = \\? WHERE `id` = \\?;").
+		WithArgs("Renamed", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `test_model` WHERE `id` = \\?;").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("DELETE FROM `test_model` WHERE `id` = \\?;").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 事务中的客户端返回的集合在事务上执行
+	err = New(db).Transaction(context.Background(), func(tc *Client) error {
+		users := CollectionOf[TestModel](tc).WithoutInvalidateCache()
+		if _, err := users.Insert(context.Background(), &TestModel{ID: 3, Name: "New"}); err != nil {
+			return err
+		}
+		if _, err := users.Update(context.Background(), map[string]any{"Name": "Renamed"}, Col("ID").Eq(3)); err != nil {
+			return err
+		}
+		n, err := users.Count(context.Background(), Col("ID").Eq(3))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1), n)
+		res, err := users.Delete(context.Background(), Col("ID").Eq(3))
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		assert.Equal(t, int64(1), affected)
+		return err
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
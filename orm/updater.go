@@ -2,7 +2,6 @@ package orm
 
 import (
 	"context"
-	"strconv"
 	"strings"
 )
 
@@ -16,25 +15,37 @@ type Updater[T any] struct {
 	hasSet      bool
 	setCnt      int
 	tableName   string        // 用于分片时替换表名
+	whereAt     int           // WHERE 子句开始的位置
+	limit       int           // 更新的最大行数，小于0表示不限制
+	ordered     bool          // 是否包含 ORDER BY 子句
+
+	returning    []string // RETURNING 的字段名
+	hasReturning bool
 
 	// 缓存相关字段
-	invalidateCache bool     // 是否使缓存失效
-	invalidateTags  []string // 要失效的缓存标签
+	invalidation   cacheInvalidation // 执行成功后使缓存失效的方式，默认在模型启用缓存时自动失效
+	invalidateTags []string          // 要失效的缓存标签
 }
 
-// WithInvalidateCache 设置是否使相关缓存失效
+// WithInvalidateCache 执行成功后总是使相关缓存失效，即使模型关闭了自动失效
 func (u *Updater[T]) WithInvalidateCache() *Updater[T] {
-	u.invalidateCache = true
+	u.invalidation = invalidateAlways
 	return u
 }
 
 // WithInvalidateTags 设置要使失效的缓存标签
 func (u *Updater[T]) WithInvalidateTags(tags ...string) *Updater[T] {
-	u.invalidateCache = true
+	u.invalidation = invalidateAlways
 	u.invalidateTags = tags
 	return u
 }
 
+// WithoutInvalidateCache 执行成功后不使缓存失效
+func (u *Updater[T]) WithoutInvalidateCache() *Updater[T] {
+	u.invalidation = invalidateNever
+	return u
+}
+
 // RegisterUpdater 创建一个新的更新构建器
 func RegisterUpdater[T any](layer Layer) *Updater[T] {
 	var val T
@@ -55,6 +66,9 @@ func RegisterUpdater[T any](layer Layer) *Updater[T] {
 		}
 	}
 
+	// 缓存的模型被多个构造器共享，每个构造器使用自己的副本
+	m = m.clone()
+
 	// 处理表名
 	if tablename, ok := any(val).(TableNamer); ok {
 		m.table = tablename.TableName()
@@ -72,6 +86,7 @@ func RegisterUpdater[T any](layer Layer) *Updater[T] {
 		model:   m,
 		dialect: dialect,
 		layer:   layer,
+		limit:   -1,
 	}
 }
 
@@ -150,8 +165,11 @@ func (u *Updater[T]) setClauses(cols []*Column, vals []any) *Updater[T] {
 				expr.model = u.model
 				expr.Build(u.builder)
 			case RawExpr:
-				expr.Build(u.builder)
-				u.args = append(u.args, expr.args...)
+				buildRaw(u.builder, u.model, expr, &u.args)
+			case *MathExpr:
+				expr.build(u.builder, u.model, &u.args)
+			case *FuncExpr:
+				expr.build(u.builder, u.model, &u.args)
 			default:
 				u.builder.WriteString(u.dialect.Placeholder(u.model.index))
 				u.model.index++
@@ -161,7 +179,7 @@ func (u *Updater[T]) setClauses(cols []*Column, vals []any) *Updater[T] {
 			// 普通值，添加占位符
 			u.builder.WriteString(u.dialect.Placeholder(u.model.index))
 			u.model.index++
-			u.args = append(u.args, val)
+			u.args = append(u.args, fieldArg(u.dialect, u.model.fieldsMap[col.name], val))
 		}
 	}
 	return u
@@ -170,6 +188,7 @@ func (u *Updater[T]) setClauses(cols []*Column, vals []any) *Updater[T] {
 // Where 添加条件子句
 func (u *Updater[T]) Where(conditions ...Condition) *Updater[T] {
 	u.setCnt = 0
+	u.whereAt = u.builder.Len()
 	u.builder.WriteString(" WHERE ")
 	for i := 0; i < len(conditions); i++ {
 		if pred, ok := conditions[i].(*Predicate); ok {
@@ -183,10 +202,33 @@ func (u *Updater[T]) Where(conditions ...Condition) *Updater[T] {
 	return u
 }
 
-// Limit 限制更新的行数
+// OrderBy 指定更新的顺序，通常与 Limit 一起使用，例如只更新最早的 n 行
+// PostgreSQL 和 SQLite 不支持 UPDATE ... ORDER BY，与 Limit 一样改写为按行标识限定的子查询
+func (u *Updater[T]) OrderBy(orders ...OrderBy) *Updater[T] {
+	if len(orders) == 0 {
+		return u
+	}
+	u.setCnt = 0
+	if u.whereAt == 0 {
+		u.whereAt = u.builder.Len()
+	}
+	writeOrderBy(u.builder, u.model, &u.args, orders)
+	u.ordered = true
+	return u
+}
+
+// Limit 限制更新的行数，PostgreSQL 和 SQLite 不支持 UPDATE ... LIMIT，改写为按行标识限定的子查询
 func (u *Updater[T]) Limit(num int) *Updater[T] {
 	u.setCnt = 0
-	u.builder.WriteString(" LIMIT " + strconv.Itoa(num))
+	u.limit = num
+	return u
+}
+
+// Returning 添加 RETURNING 子句，通过 ExecReturning 获取更新后的行，cols 为字段名，为空时返回所有列
+// 只有支持 RETURNING 的方言（如PostgreSQL）可以使用
+func (u *Updater[T]) Returning(cols ...string) *Updater[T] {
+	u.returning = cols
+	u.hasReturning = true
 	return u
 }
 
@@ -195,6 +237,25 @@ func (u *Updater[T]) Build() (*Query, error) {
 	if !u.hasSet {
 		panic("no set clause")
 	}
+	if u.whereAt == 0 {
+		u.whereAt = u.builder.Len()
+	}
+	table := u.model.table
+	if u.tableName != "" {
+		table = u.tableName
+	}
+	stmt, err := writeLimit(u.dialect, table, u.builder.String(), u.whereAt, u.limit, 0, u.ordered)
+	if err != nil {
+		return nil, err
+	}
+	u.builder.Reset()
+	u.builder.WriteString(stmt)
+	u.limit, u.ordered = -1, false
+	if u.hasReturning && supportsReturning(u.dialect) {
+		if err := buildReturning(u.builder, u.dialect, u.model, u.returning); err != nil {
+			return nil, err
+		}
+	}
 	u.builder.WriteByte(';')
 	return &Query{
 		SQL:  u.builder.String(),
@@ -216,26 +277,67 @@ func (u *Updater[T]) Exec(ctx context.Context) (Result, error) {
 		Builder:   u,
 	}
 
+	db := u.layer.getDB()
+	if err = db.runHooks(ctx, &HookContext{Event: BeforeUpdateEvent, Table: u.model.table, Query: q}); err != nil {
+		return Result{err: err}, err
+	}
+
 	res, err := u.layer.HandleQuery(ctx, qc)
-	// 如果执行成功且需要使缓存失效
-	if err == nil && u.invalidateCache {
-		// 获取数据库实例
-		db := u.layer.getDB()
-		// 如果DB有缓存管理器，则使相关缓存失效
-		if db != nil && db.cacheManager != nil {
-			modelName := u.model.GetTableName()
-			if len(u.invalidateTags) > 0 {
-				// 如果指定了标签，使用标签使缓存失效
-				_ = db.cacheManager.cache.DeleteByTags(ctx, u.invalidateTags...)
-			} else {
-				// 否则使用模型名作为标签使缓存失效
-				_ = db.cacheManager.cache.DeleteByTags(ctx, modelName)
-			}
-		}
+	if err != nil {
+		return Result{err: err}, err
 	}
+	u.invalidate(ctx)
 
+	if err = db.runHooks(ctx, &HookContext{Event: AfterUpdateEvent, Table: u.model.table, Query: q}); err != nil {
+		return Result{err: err}, err
+	}
 	return Result{
 		res: res.Result.res,
-		err: err,
-	}, err
-}
\ No newline at end of file
+	}, nil
+}
+
+// ExecReturning 执行更新并返回 RETURNING 子句返回的行
+// 方言不支持 RETURNING 时返回 ErrReturningNotSupported，不会执行更新
+func (u *Updater[T]) ExecReturning(ctx context.Context) ([]*T, error) {
+	if !supportsReturning(u.dialect) {
+		return nil, ErrReturningNotSupported
+	}
+	if !u.hasReturning {
+		u.Returning()
+	}
+
+	q, err := u.Build()
+	if err != nil {
+		return nil, err
+	}
+	db := u.layer.getDB()
+	if err = db.runHooks(ctx, &HookContext{Event: BeforeUpdateEvent, Table: u.model.table, Query: q}); err != nil {
+		return nil, err
+	}
+
+	res, err := u.layer.HandleQuery(ctx, &QueryContext{
+		QueryType: "query",
+		Query:     q,
+		Model:     u.model,
+		Builder:   u,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := scanReturningRows[T](db, u.model, res.Rows)
+	if err != nil {
+		return nil, err
+	}
+	u.invalidate(ctx)
+
+	// 在返回的每一行上执行模型钩子和全局钩子
+	if err = runRowHooks(ctx, db, AfterUpdateEvent, u.model.table, rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// invalidate 执行成功后按配置使缓存失效
+func (u *Updater[T]) invalidate(ctx context.Context) {
+	invalidateAfterWrite(ctx, u.layer, u.model.GetTableName(), u.invalidation, u.invalidateTags)
+}
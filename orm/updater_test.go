@@ -89,6 +89,27 @@ func TestUpdater_Build(t *testing.T) {
 				Args: []any{12},
 			},
 		},
+		{
+			name: "update with arithmetic",
+			q: RegisterUpdater[TestModel](db).Update().
+				Set(Col("ID"), Col("ID").Add(10)).
+				Set(Col("Name"), Func("CONCAT", Col("Name"), "_", Col("ID").Mul(2).Sub(1))).
+				Where(Col("ID").Eq(12)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `id` = `id` + ?, `name` = CONCAT(`name`, ?, (`id` * ?) - ?) WHERE `id` = ?;",
+				Args: []any{10, "_", 2, 1, 12},
+			},
+		},
+		{
+			name: "update with nested arithmetic",
+			q: RegisterUpdater[TestModel](db).Update().
+				Set(Col("ID"), Col("ID").Sub(Col("ID").Div(2)).Add(Raw("?", 1))).
+				Where(Col("ID").Eq(Col("ID").Add(0))),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `id` = (`id` - (`id` / ?)) + ? WHERE `id` = `id` + ?;",
+				Args: []any{2, 1, 0},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
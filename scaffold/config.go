@@ -0,0 +1,140 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName 项目配置文件名，记录创建项目时选择的配置，供之后的生成命令读取
+const ConfigFileName = "fyer.yaml"
+
+// SupportedDrivers 支持的数据库驱动，名称与 orm 注册的方言名一致
+var SupportedDrivers = []string{"mysql", "postgresql", "sqlite"}
+
+// Feature 创建项目时可以选择的可选功能
+type Feature struct {
+	Name        string // 功能名称，写入配置文件
+	Description string // 功能说明，用于交互式提示
+}
+
+// SupportedFeatures 支持的可选功能
+var SupportedFeatures = []Feature{
+	{Name: "accesslog", Description: "Access log middleware"},
+	{Name: "recovery", Description: "Panic recovery middleware"},
+	{Name: "session", Description: "Cookie based sessions"},
+	{Name: "prometheus", Description: "Prometheus metrics"},
+	{Name: "opentracing", Description: "OpenTelemetry tracing"},
+}
+
+// ErrConfigNotFound 当前目录及其所有上级目录中都没有 fyer.yaml
+var ErrConfigNotFound = errors.New("scaffold: " + ConfigFileName + " not found")
+
+// ProjectConfig 描述项目的配置，对应 fyer.yaml 的内容
+type ProjectConfig struct {
+	Name       string           `yaml:"name"`
+	Module     string           `yaml:"module"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Migrations MigrationsConfig `yaml:"migrations,omitempty"`
+	Features   []string         `yaml:"features,omitempty"`
+}
+
+// DatabaseConfig 项目使用的数据库
+type DatabaseConfig struct {
+	Driver string `yaml:"driver"`        // 方言名称，与 orm 注册的方言名一致
+	DSN    string `yaml:"dsn,omitempty"` // 连接地址，通常只在本地开发时填写
+}
+
+// MigrationsConfig 迁移文件的位置，相对路径相对于 fyer.yaml 所在的目录
+type MigrationsConfig struct {
+	Dir   string `yaml:"dir,omitempty"`
+	Table string `yaml:"table,omitempty"`
+}
+
+// Validate 检查配置是否合法
+func (c *ProjectConfig) Validate() error {
+	if err := ValidateProjectName(c.Name); err != nil {
+		return err
+	}
+	if c.Module == "" {
+		return fmt.Errorf("module path cannot be empty")
+	}
+	if !slices.Contains(SupportedDrivers, c.Database.Driver) {
+		return fmt.Errorf("unsupported database driver %q, supported: %v", c.Database.Driver, SupportedDrivers)
+	}
+	for _, name := range c.Features {
+		if !IsSupportedFeature(name) {
+			return fmt.Errorf("unsupported feature %q", name)
+		}
+	}
+	return nil
+}
+
+// IsSupportedFeature 判断功能名称是否受支持
+func IsSupportedFeature(name string) bool {
+	for _, f := range SupportedFeatures {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultDatabasePort 返回数据库驱动的默认端口，SQLite 没有端口
+func DefaultDatabasePort(driver string) string {
+	switch driver {
+	case "postgresql":
+		return "5432"
+	case "sqlite":
+		return ""
+	default:
+		return "3306"
+	}
+}
+
+// WriteProjectConfig 将配置写入 dir 目录下的 fyer.yaml
+func WriteProjectConfig(dir string, cfg *ProjectConfig) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ConfigFileName), data, 0644)
+}
+
+// LoadProjectConfig 读取配置文件
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ProjectConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// FindProjectConfig 从 dir 开始逐级向上查找 fyer.yaml，返回配置和配置文件所在的目录，
+// 找不到时返回 ErrConfigNotFound
+func FindProjectConfig(dir string) (*ProjectConfig, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			cfg, err := LoadProjectConfig(path)
+			return cfg, dir, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, "", ErrConfigNotFound
+		}
+		dir = parent
+	}
+}
@@ -43,6 +43,8 @@ type TemplateData struct {
 	Title       string // 页面标题
 	Message     string // 页面消息
 	CurrentYear string // 当前年份
+	DBDriver    string // 数据库驱动
+	DBPort      string // 数据库端口
 }
 
 // ParseTemplateContent 解析模板内容
@@ -56,6 +58,11 @@ func ParseTemplateContent(content string, data TemplateData) (string, error) {
 		data.CurrentYear = time.Now().Format("2006")
 	}
 
+	if data.DBDriver == "" {
+		data.DBDriver = "mysql"
+		data.DBPort = DefaultDatabasePort(data.DBDriver)
+	}
+
 	// 确保模板引擎能找到所有需要的变量
 	if data.Message == "" {
 		data.Message = "Welcome to " + data.ProjectName
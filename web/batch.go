@@ -0,0 +1,232 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+const (
+	defaultBatchMaxRequests = 20
+	defaultBatchConcurrency = 4
+)
+
+// BatchRequest 批量请求中的单个子请求
+type BatchRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse 子请求的响应，JSON响应体原样嵌入，其余响应体以字符串返回
+type BatchResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batchConfig 批量请求端点配置
+type batchConfig struct {
+	maxRequests    int
+	concurrency    int
+	forwardHeaders []string
+}
+
+// BatchOption 批量请求端点配置选项
+type BatchOption func(*batchConfig)
+
+// WithBatchMaxRequests 设置单次批量请求允许的最大子请求数，默认20
+func WithBatchMaxRequests(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxRequests = n
+	}
+}
+
+// WithBatchConcurrency 设置子请求的最大并发数，默认4
+func WithBatchConcurrency(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.concurrency = n
+	}
+}
+
+// WithBatchForwardHeaders 设置从批量请求复制到子请求的请求头，默认复制 Authorization 和 Cookie
+func WithBatchForwardHeaders(headers ...string) BatchOption {
+	return func(c *batchConfig) {
+		c.forwardHeaders = headers
+	}
+}
+
+// WithBatch 注册批量请求端点，客户端通过一次POST请求提交多个子请求
+// 子请求经过正常的路由和中间件处理，响应按提交顺序返回
+func WithBatch(path string, opts ...BatchOption) ServerOption {
+	return func(server *HTTPServer) {
+		server.Post(path, server.batchHandler(path, opts...))
+	}
+}
+
+// batchHandler 创建批量请求处理函数
+func (s *HTTPServer) batchHandler(path string, opts ...BatchOption) HandlerFunc {
+	config := &batchConfig{
+		maxRequests:    defaultBatchMaxRequests,
+		concurrency:    defaultBatchConcurrency,
+		forwardHeaders: []string{"Authorization", "Cookie"},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.concurrency <= 0 {
+		config.concurrency = 1
+	}
+	batchPath := s.fullPath(path)
+
+	return func(ctx *Context) {
+		var reqs []BatchRequest
+		if err := ctx.BindJSON(&reqs); err != nil {
+			ctx.Error(NewHTTPError(http.StatusBadRequest, "invalid batch request").WithInternal(err))
+			return
+		}
+		if len(reqs) == 0 {
+			ctx.Error(NewHTTPError(http.StatusBadRequest, "empty batch request"))
+			return
+		}
+		if config.maxRequests > 0 && len(reqs) > config.maxRequests {
+			ctx.Error(NewHTTPError(http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("batch request exceeds %d sub-requests", config.maxRequests)))
+			return
+		}
+
+		resps := make([]BatchResponse, len(reqs))
+		sem := make(chan struct{}, config.concurrency)
+		var wg sync.WaitGroup
+		for i := range reqs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				resps[i] = s.serveBatchRequest(ctx, &reqs[i], batchPath, config)
+			}(i)
+		}
+		wg.Wait()
+
+		ctx.JSON(http.StatusOK, resps)
+	}
+}
+
+// serveBatchRequest 通过服务器的完整处理流程执行单个子请求
+func (s *HTTPServer) serveBatchRequest(ctx *Context, br *BatchRequest, batchPath string, config *batchConfig) (resp BatchResponse) {
+	defer func() {
+		if p := recover(); p != nil {
+			ctx.Logger().Error("Panic in batch sub-request", logger.FieldError(fmt.Errorf("%v", p)))
+			resp = batchError(http.StatusInternalServerError, "internal server error")
+		}
+	}()
+
+	method := strings.ToUpper(br.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.ParseRequestURI(br.Path)
+	if err != nil || !strings.HasPrefix(br.Path, "/") {
+		return batchError(http.StatusBadRequest, "invalid sub-request path")
+	}
+	// 禁止嵌套批量请求
+	if u.Path == batchPath {
+		return batchError(http.StatusBadRequest, "nested batch request is not allowed")
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Req.Context(), method, br.Path, bytes.NewReader(br.Body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, "invalid sub-request")
+	}
+	req.RemoteAddr = ctx.Req.RemoteAddr
+	req.Host = ctx.Req.Host
+	for _, h := range config.forwardHeaders {
+		if v := ctx.Req.Header.Values(h); len(v) > 0 {
+			req.Header[http.CanonicalHeaderKey(h)] = v
+		}
+	}
+	if len(br.Body) > 0 {
+		req.Header.Set("Content-Type", ContentTypeJSON)
+	}
+	for k, v := range br.Headers {
+		req.Header.Set(k, v)
+	}
+
+	w := newBatchWriter()
+	s.ServeHTTP(w, req)
+	return w.response()
+}
+
+// batchError 创建子请求的错误响应
+func batchError(code int, msg string) BatchResponse {
+	body, _ := json.Marshal(NewHTTPError(code, msg))
+	return BatchResponse{
+		Status:  code,
+		Headers: map[string]string{"Content-Type": ContentTypeJSON},
+		Body:    body,
+	}
+}
+
+// batchWriter 收集子请求响应的 http.ResponseWriter
+type batchWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBatchWriter() *batchWriter {
+	return &batchWriter{header: make(http.Header)}
+}
+
+func (w *batchWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *batchWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// response 将收集到的响应转换为 BatchResponse
+func (w *batchWriter) response() BatchResponse {
+	resp := BatchResponse{Status: w.status}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+
+	if len(w.header) > 0 {
+		resp.Headers = make(map[string]string, len(w.header))
+		for k := range w.header {
+			resp.Headers[k] = w.header.Get(k)
+		}
+	}
+
+	if w.body.Len() == 0 {
+		return resp
+	}
+	body := w.body.Bytes()
+	if strings.HasPrefix(w.header.Get("Content-Type"), "application/json") && json.Valid(body) {
+		resp.Body = body
+	} else {
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Batch(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	s := NewHTTPServer(WithBatch("/batch", WithBatchConcurrency(2), WithBatchMaxRequests(5)))
+	s.Use("GET", "/users/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.SetHeader("X-Middleware", "users")
+			next(ctx)
+		}
+	})
+	s.Get("/users/:id", func(ctx *Context) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		ctx.JSON(http.StatusOK, map[string]string{"id": ctx.PathParam("id").Value, "auth": ctx.GetHeader("Authorization")})
+	})
+	s.Post("/users", func(ctx *Context) {
+		var body map[string]string
+		if err := ctx.BindJSON(&body); err != nil {
+			ctx.BadRequest(err.Error())
+			return
+		}
+		ctx.String(http.StatusCreated, "created "+body["name"])
+	})
+	s.Get("/panic", func(ctx *Context) {
+		panic("boom")
+	})
+
+	body := `[
+		{"method": "GET", "path": "/users/1"},
+		{"method": "GET", "path": "/users/2"},
+		{"method": "GET", "path": "/users/3"},
+		{"method": "POST", "path": "/users", "body": {"name": "tom"}},
+		{"method": "GET", "path": "/batch"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	var results []BatchResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &results))
+	require.Len(t, results, 5)
+
+	for i, id := range []string{"1", "2", "3"} {
+		assert.Equal(t, http.StatusOK, results[i].Status)
+		assert.Equal(t, "users", results[i].Headers["X-Middleware"])
+		assert.JSONEq(t, `{"id":"`+id+`","auth":"Bearer token"}`, string(results[i].Body))
+	}
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	assert.Equal(t, http.StatusCreated, results[3].Status)
+	assert.Equal(t, `"created tom"`, string(results[3].Body))
+
+	assert.Equal(t, http.StatusBadRequest, results[4].Status)
+
+	// 子请求的panic不会影响其他子请求
+	req = httptest.NewRequest(http.MethodPost, "/batch",
+		strings.NewReader(`[{"path": "/panic"}, {"path": "/missing"}]`))
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &results))
+	assert.Equal(t, http.StatusInternalServerError, results[0].Status)
+	assert.Equal(t, http.StatusNotFound, results[1].Status)
+
+	// 超出子请求数量限制
+	req = httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[{},{},{},{},{},{}]`))
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`{}`))
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
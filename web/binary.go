@@ -0,0 +1,89 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+
+	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
+)
+
+// 二进制格式的请求绑定和响应方法
+
+// BindProtobuf 将请求体绑定到Protocol Buffers消息
+func (c *Context) BindProtobuf(msg proto.Message) error {
+	if c.Req.Body == nil {
+		return errors.New("request body is empty")
+	}
+	body, err := c.ReadBody()
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(body, msg)
+}
+
+// BindMsgpack 将请求体绑定到MessagePack结构体
+func (c *Context) BindMsgpack(v any) error {
+	if c.Req.Body == nil {
+		return errors.New("request body is empty")
+	}
+	return msgpack.NewDecoder(c.Req.Body).Decode(v)
+}
+
+// IsProtobuf 检查请求Content-Type是否为Protocol Buffers
+func (c *Context) IsProtobuf() bool {
+	return c.IsContentType(ContentTypeProtobuf) || c.IsContentType("application/protobuf")
+}
+
+// IsMsgpack 检查请求Content-Type是否为MessagePack
+func (c *Context) IsMsgpack() bool {
+	return c.IsContentType(ContentTypeMsgpack) || c.IsContentType("application/x-msgpack")
+}
+
+// Protobuf 返回 Protocol Buffers 格式的响应
+func (c *Context) Protobuf(code int, msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", ContentTypeProtobuf)
+	c.RespStatusCode = code
+	c.releaseRespBuffer()
+	c.RespData = data
+	c.unhandled = true
+	return nil
+}
+
+// Msgpack 返回 MessagePack 格式的响应
+func (c *Context) Msgpack(code int, data any) error {
+	// 获取一个响应缓冲区，响应写出后再归还
+	buf := objPool.AcquireBuffer()
+	if err := msgpack.NewEncoder(buf.Buffer).Encode(data); err != nil {
+		objPool.ReleaseBuffer(buf)
+		return err
+	}
+
+	// 编码成功后才设置头部，编码失败时的错误响应不会带上MessagePack的Content-Type
+	c.Resp.Header().Set("Content-Type", ContentTypeMsgpack)
+	c.RespStatusCode = code
+	c.setRespBuffer(buf)
+	c.unhandled = true
+	return nil
+}
+
+// renderProtobuf Protocol Buffers渲染器，只支持proto.Message
+func renderProtobuf(_ *Context, data any) ([]byte, error) {
+	msg, ok := unwrapView(data).(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot render %T as protobuf: %w", data, ErrUnsupportedData)
+	}
+	return proto.Marshal(msg)
+}
+
+// renderMsgpack MessagePack渲染器
+func renderMsgpack(_ *Context, data any) ([]byte, error) {
+	return msgpack.Marshal(unwrapView(data))
+}
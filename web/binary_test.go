@@ -0,0 +1,86 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestContextProtobuf(t *testing.T) {
+	body, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", ContentTypeProtobuf)
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+	assert.True(t, ctx.IsProtobuf())
+
+	msg := &wrapperspb.StringValue{}
+	require.NoError(t, ctx.BindProtobuf(msg))
+	assert.Equal(t, "hello", msg.GetValue())
+
+	require.NoError(t, ctx.Protobuf(http.StatusCreated, wrapperspb.String("world")))
+	assert.Equal(t, http.StatusCreated, ctx.RespStatusCode)
+	assert.Equal(t, ContentTypeProtobuf, ctx.Resp.Header().Get("Content-Type"))
+
+	out := &wrapperspb.StringValue{}
+	require.NoError(t, proto.Unmarshal(ctx.RespData, out))
+	assert.Equal(t, "world", out.GetValue())
+}
+
+func TestContextMsgpack(t *testing.T) {
+	type item struct {
+		ID   int    `msgpack:"id"`
+		Name string `msgpack:"name"`
+	}
+
+	body, err := msgpack.Marshal(item{ID: 1, Name: "book"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+	assert.True(t, ctx.IsMsgpack())
+
+	var in item
+	require.NoError(t, ctx.BindMsgpack(&in))
+	assert.Equal(t, item{ID: 1, Name: "book"}, in)
+
+	require.NoError(t, ctx.Msgpack(http.StatusOK, in))
+	assert.Equal(t, ContentTypeMsgpack, ctx.Resp.Header().Get("Content-Type"))
+
+	var out item
+	require.NoError(t, msgpack.Unmarshal(ctx.RespData, &out))
+	assert.Equal(t, in, out)
+
+	// 编码失败时不设置头部和状态码
+	ctx = &Context{Req: req, Resp: httptest.NewRecorder()}
+	assert.Error(t, ctx.Msgpack(http.StatusCreated, make(chan int)))
+	assert.Empty(t, ctx.Resp.Header().Get("Content-Type"))
+	assert.Zero(t, ctx.RespStatusCode)
+	assert.Empty(t, ctx.RespData)
+}
+
+func TestNegotiateBinaryFormats(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/x-protobuf, application/json;q=0.5")
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+	// 非proto.Message的数据回退到JSON
+	require.NoError(t, ctx.Negotiate(http.StatusOK, map[string]int{"a": 1}))
+	assert.Equal(t, ContentTypeJSON, ctx.Resp.Header().Get("Content-Type"))
+
+	require.NoError(t, ctx.Negotiate(http.StatusOK, wrapperspb.Int64(7)))
+	assert.Equal(t, ContentTypeProtobuf, ctx.Resp.Header().Get("Content-Type"))
+
+	req.Header.Set("Accept", "application/msgpack")
+	require.NoError(t, ctx.Negotiate(http.StatusOK, map[string]int{"a": 1}))
+	assert.Equal(t, ContentTypeMsgpack, ctx.Resp.Header().Get("Content-Type"))
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"math/rand/v2"
+	"net/http"
+)
+
+const (
+	// CanaryVariantKey UserValues中记录请求命中版本的键
+	CanaryVariantKey = "canary_variant"
+	// StableVariant 未命中任何灰度版本时的默认版本名
+	StableVariant = "stable"
+)
+
+// canaryVariant 同一路由下注册的灰度版本
+type canaryVariant struct {
+	name        string
+	handler     HandlerFunc
+	weight      int    // 流量百分比，0-100
+	header      string // 命中灰度的请求头名称
+	headerValue string // 请求头的值，为空时只要求请求头存在
+	cookie      string // 命中灰度的Cookie名称
+	cookieValue string // Cookie的值，为空时只要求Cookie存在
+}
+
+// CanaryOption 灰度版本配置选项
+type CanaryOption func(*canaryVariant)
+
+// CanaryWeight 设置路由到灰度版本的流量百分比
+func CanaryWeight(percent int) CanaryOption {
+	return func(v *canaryVariant) {
+		if percent < 0 {
+			percent = 0
+		}
+		if percent > 100 {
+			percent = 100
+		}
+		v.weight = percent
+	}
+}
+
+// CanaryHeader 携带指定请求头的请求总是路由到灰度版本
+// value为空时只要请求头存在即命中
+func CanaryHeader(name, value string) CanaryOption {
+	return func(v *canaryVariant) {
+		v.header = name
+		v.headerValue = value
+	}
+}
+
+// CanaryCookie 携带指定Cookie的请求总是路由到灰度版本
+// value为空时只要Cookie存在即命中
+func CanaryCookie(name, value string) CanaryOption {
+	return func(v *canaryVariant) {
+		v.cookie = name
+		v.cookieValue = value
+	}
+}
+
+// forced 判断请求是否通过请求头或Cookie强制命中该版本
+func (v *canaryVariant) forced(req *http.Request) bool {
+	if v.header != "" {
+		if val := req.Header.Get(v.header); val != "" && (v.headerValue == "" || val == v.headerValue) {
+			return true
+		}
+	}
+	if v.cookie != "" {
+		if c, err := req.Cookie(v.cookie); err == nil && (v.cookieValue == "" || c.Value == v.cookieValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// canaryRouter 在同一路由的稳定版本和灰度版本之间分配流量
+type canaryRouter struct {
+	variants []*canaryVariant
+	roll     func() int // 返回[0,100)的随机数，便于测试替换
+}
+
+func newCanaryRouter() *canaryRouter {
+	return &canaryRouter{
+		roll: func() int {
+			return rand.IntN(100)
+		},
+	}
+}
+
+// pick 选择请求应当命中的灰度版本，返回nil表示使用稳定版本
+// 请求头和Cookie规则优先于权重规则
+func (r *canaryRouter) pick(req *http.Request) *canaryVariant {
+	for _, v := range r.variants {
+		if v.forced(req) {
+			return v
+		}
+	}
+
+	n := r.roll()
+	acc := 0
+	for _, v := range r.variants {
+		acc += v.weight
+		if n < acc {
+			return v
+		}
+	}
+	return nil
+}
+
+// handler 返回在稳定版本 stable 和灰度版本之间分流的处理函数
+// 它替换路由最内层的处理函数，路由组和路由的中间件对所有版本都生效
+func (r *canaryRouter) handler(stable HandlerFunc) HandlerFunc {
+	return func(ctx *Context) {
+		variant := r.pick(ctx.Req)
+		if variant == nil {
+			ctx.setCanaryVariant(StableVariant)
+			stable(ctx)
+			return
+		}
+
+		ctx.setCanaryVariant(variant.name)
+		variant.handler(ctx)
+	}
+}
+
+// setCanaryVariant 记录请求命中的版本
+func (c *Context) setCanaryVariant(name string) {
+	if c.UserValues == nil {
+		c.UserValues = make(map[string]any)
+	}
+	c.UserValues[CanaryVariantKey] = name
+}
+
+// CanaryVariant 返回请求命中的版本名，未配置灰度的路由返回StableVariant
+func (c *Context) CanaryVariant() string {
+	if name, ok := c.UserValues[CanaryVariantKey].(string); ok {
+		return name
+	}
+	return StableVariant
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanaryRouting(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/api/items", func(ctx *Context) {
+		ctx.String(http.StatusOK, "v1:%s", ctx.CanaryVariant())
+	}).Canary("v2", func(ctx *Context) {
+		ctx.String(http.StatusOK, "v2:%s", ctx.CanaryVariant())
+	}, CanaryWeight(30), CanaryHeader("X-Canary", "v2"), CanaryCookie("canary", ""))
+
+	s.Get("/api/items/:id", func(ctx *Context) {
+		ctx.String(http.StatusOK, "item")
+	})
+
+	cr := s.canaries["GET /api/items"]
+	roll := 0
+	cr.roll = func() int { return roll }
+
+	serve := func(req *http.Request) string {
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, req)
+		return resp.Body.String()
+	}
+
+	t.Run("weight hit", func(t *testing.T) {
+		roll = 29
+		assert.Equal(t, "v2:v2", serve(httptest.NewRequest(http.MethodGet, "/api/items", nil)))
+	})
+
+	t.Run("weight miss", func(t *testing.T) {
+		roll = 30
+		assert.Equal(t, "v1:stable", serve(httptest.NewRequest(http.MethodGet, "/api/items", nil)))
+	})
+
+	t.Run("header forces canary", func(t *testing.T) {
+		roll = 99
+		req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+		req.Header.Set("X-Canary", "v2")
+		assert.Equal(t, "v2:v2", serve(req))
+
+		req = httptest.NewRequest(http.MethodGet, "/api/items", nil)
+		req.Header.Set("X-Canary", "other")
+		assert.Equal(t, "v1:stable", serve(req))
+	})
+
+	t.Run("cookie forces canary", func(t *testing.T) {
+		roll = 99
+		req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+		req.AddCookie(&http.Cookie{Name: "canary", Value: "1"})
+		assert.Equal(t, "v2:v2", serve(req))
+	})
+
+	t.Run("sub path not affected", func(t *testing.T) {
+		roll = 0
+		assert.Equal(t, "item", serve(httptest.NewRequest(http.MethodGet, "/api/items/1", nil)))
+	})
+}
+
+func TestCanaryMultipleVariants(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/", func(ctx *Context) {
+		ctx.String(http.StatusOK, "stable")
+	}).Canary("a", func(ctx *Context) {
+		ctx.String(http.StatusOK, "a")
+	}, CanaryWeight(10)).Canary("b", func(ctx *Context) {
+		ctx.String(http.StatusOK, "b")
+	}, CanaryWeight(20))
+
+	cr := s.canaries["GET /"]
+	testCases := []struct {
+		roll int
+		want string
+	}{
+		{roll: 5, want: "a"},
+		{roll: 15, want: "b"},
+		{roll: 30, want: "stable"},
+	}
+
+	for _, tc := range testCases {
+		roll := tc.roll
+		cr.roll = func() int { return roll }
+
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, tc.want, resp.Body.String())
+	}
+}
+
+func TestCanaryTrailingSlash(t *testing.T) {
+	s := NewHTTPServer(WithRedirectTrailingSlash(true))
+	s.Get("/api/", func(ctx *Context) {
+		ctx.String(http.StatusOK, "stable")
+	}).Canary("v2", func(ctx *Context) {
+		ctx.String(http.StatusOK, "v2")
+	}, CanaryHeader("X-Canary", ""))
+
+	// 路由按去掉尾部斜杠的路径注册，灰度版本同样生效
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set("X-Canary", "1")
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	assert.Equal(t, "v2", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api", nil))
+	assert.Equal(t, "stable", resp.Body.String())
+}
+
+func TestCanaryBehindGroupMiddleware(t *testing.T) {
+	s := NewHTTPServer()
+	api := s.Group("/api")
+	api.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			if ctx.GetHeader("Authorization") == "" {
+				ctx.String(http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			next(ctx)
+		}
+	})
+	api.Get("/items", func(ctx *Context) {
+		ctx.String(http.StatusOK, "stable")
+	}).Canary("v2", func(ctx *Context) {
+		ctx.String(http.StatusOK, "v2")
+	}, CanaryHeader("X-Canary", ""))
+
+	testCases := []struct {
+		name     string
+		canary   bool
+		auth     bool
+		wantCode int
+		wantBody string
+	}{
+		{"stable without auth", false, false, http.StatusUnauthorized, "unauthorized"},
+		{"canary without auth", true, false, http.StatusUnauthorized, "unauthorized"},
+		{"stable with auth", false, true, http.StatusOK, "stable"},
+		{"canary with auth", true, true, http.StatusOK, "v2"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+			if tc.canary {
+				req.Header.Set("X-Canary", "1")
+			}
+			if tc.auth {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/idgen"
+)
+
+// WithClock 设置服务器的时间来源，请求日志的耗时、默认的请求ID和 ctx.Now 都使用它，默认为系统时间。
+// 测试中可以传入 clock.Mock 固定时间
+func WithClock(c clock.Clock) ServerOption {
+	return func(s *HTTPServer) {
+		s.clock = c
+	}
+}
+
+// WithIDGenerator 设置请求没有 X-Request-ID 头部时生成请求ID的方式，
+// 未设置时请求ID为请求路径加上当前时间
+func WithIDGenerator(gen idgen.IDGenerator) ServerOption {
+	return func(s *HTTPServer) {
+		s.idGen = gen
+	}
+}
+
+// Clock 返回服务器的时间来源，便于后台任务和会话等组件共用
+func (s *HTTPServer) Clock() clock.Clock {
+	return clock.OrSystem(s.clock)
+}
+
+// now 返回服务器的当前时间
+func (s *HTTPServer) now() time.Time {
+	return clock.OrSystem(s.clock).Now()
+}
+
+// requestID 为没有 X-Request-ID 头部的请求生成请求ID
+func (s *HTTPServer) requestID(path string) string {
+	if s.idGen != nil {
+		return s.idGen.NewID()
+	}
+	return path + "-" + s.now().Format(time.RFC3339Nano)
+}
+
+// Now 返回服务器的时间来源的当前时间，处理函数中需要当前时间时应当使用它，便于测试
+func (c *Context) Now() time.Time {
+	return clock.OrSystem(c.clock).Now()
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/idgen"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerClockAndIDGenerator(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(now)
+	var out bytes.Buffer
+	s := NewHTTPServer(
+		WithClock(clk),
+		WithIDGenerator(idgen.Sequence("req")),
+		WithLogger(logger.NewLogger(logger.WithOutput(&out))),
+	)
+	s.Get("/now", func(ctx *Context) {
+		ctx.String(http.StatusOK, ctx.Now().Format(time.RFC3339))
+	})
+	assert.Equal(t, clk, s.Clock())
+
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/now", nil)
+		if header != "" {
+			req.Header.Set("X-Request-ID", header)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "2025-01-01T12:00:00Z", serve("").Body.String())
+	clk.Advance(time.Hour)
+	assert.Equal(t, "2025-01-01T13:00:00Z", serve("").Body.String())
+	serve("from-client")
+
+	logs := out.String()
+	assert.Contains(t, logs, `"request_id":"req-1"`)
+	assert.Contains(t, logs, `"request_id":"req-2"`)
+	assert.Contains(t, logs, `"request_id":"from-client"`)
+	assert.NotContains(t, logs, `"request_id":"req-3"`, "requests with X-Request-ID do not consume IDs")
+	assert.Contains(t, logs, `"duration_ms":0`, "durations use the frozen clock")
+
+	assert.WithinDuration(t, time.Now(), NewHTTPServer().Clock().Now(), time.Minute)
+	assert.WithinDuration(t, time.Now(), (&Context{}).Now(), time.Minute)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// AWSSecretsManagerClient 读取 AWS Secrets Manager 中密钥的字符串值。
+// 框架不依赖 AWS SDK，可以用 SDK 的客户端实现：
+//
+//	type smClient struct{ c *secretsmanager.Client }
+//
+//	func (s smClient) GetSecretString(ctx context.Context, id string) (string, error) {
+//		out, err := s.c.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+//		if err != nil {
+//			return "", err
+//		}
+//		return aws.ToString(out.SecretString), nil
+//	}
+type AWSSecretsManagerClient interface {
+	GetSecretString(ctx context.Context, secretID string) (string, error)
+}
+
+// AWSSecretsProvider 使用 client 读取密钥，路径为密钥的名称或 ARN，
+// 密钥是 JSON 对象时可以通过 # 读取字段：
+//
+//	config.WithSecretProvider("aws", config.AWSSecretsProvider(smClient{c: client}))
+//	// db.password: secret://aws/prod/app/db#password
+func AWSSecretsProvider(client AWSSecretsManagerClient) SecretProvider {
+	return SecretProviderFunc(func(ctx context.Context, secretID, key string) (string, error) {
+		value, err := client.GetSecretString(ctx, secretID)
+		if err != nil {
+			return "", fmt.Errorf("config: aws secrets manager: %w", err)
+		}
+		return secretField(value, key)
+	})
+}
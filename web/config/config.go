@@ -0,0 +1,517 @@
+// Package config 加载应用配置，按以下顺序合并，后面的来源覆盖前面的来源：
+//
+//  1. 结构体字段的 default 标签
+//  2. 配置文件，支持 YAML、TOML 和 JSON，按扩展名识别，多个文件按添加的顺序合并
+//  3. 带有前缀的环境变量，例如前缀为 APP 时 APP_SERVER_ADDR 覆盖 server.addr
+//  4. 命令行参数，参数名为配置键，例如 -server.addr=:9090
+//
+// 配置值可以引用密钥，例如 secret://env/DB_PASSWORD，在读取配置时才通过对应的 SecretProvider 解析。
+//
+// 配置键不区分大小写，用 . 分隔层级：
+//
+//	type AppConfig struct {
+//		Server config.ServerConfig `config:"server"`
+//		DB     config.DBConfig     `config:"db"`
+//		Debug  bool                `config:"debug"`
+//	}
+//
+//	var cfg AppConfig
+//	c, err := config.Bind(&cfg, config.WithFile("config.yaml"), config.WithEnvPrefix("APP"))
+//	server := web.NewHTTPServer(cfg.Server.Options()...)
+//	db, err := cfg.DB.Open()
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedFormat 配置文件的扩展名不是 .yaml、.yml、.toml 或 .json
+var ErrUnsupportedFormat = errors.New("config: unsupported file format")
+
+// Option 配置的加载选项
+type Option func(*Config)
+
+// source 配置文件
+type source struct {
+	path     string
+	optional bool
+}
+
+// WithFile 添加配置文件，文件不存在时加载失败
+func WithFile(path string) Option {
+	return func(c *Config) {
+		c.files = append(c.files, source{path: path})
+	}
+}
+
+// WithOptionalFile 添加配置文件，文件不存在时跳过，适合 config.local.yaml 等本地覆盖文件
+func WithOptionalFile(path string) Option {
+	return func(c *Config) {
+		c.files = append(c.files, source{path: path, optional: true})
+	}
+}
+
+// WithEnvPrefix 使用带有 prefix 前缀的环境变量覆盖配置。
+// 变量名去掉前缀后，__ 分隔层级；没有 __ 时优先匹配已有的配置键，例如 APP_SERVER_READ_TIMEOUT
+// 在 server.read_timeout 存在时覆盖它，否则 _ 分隔层级
+func WithEnvPrefix(prefix string) Option {
+	return func(c *Config) {
+		c.envPrefix = strings.TrimSuffix(strings.ToUpper(prefix), "_") + "_"
+	}
+}
+
+// WithFlags 使用命令行参数覆盖配置，只有显式设置的参数生效，参数名为配置键。
+// fs 需要在加载配置之前完成解析
+func WithFlags(fs *flag.FlagSet) Option {
+	return func(c *Config) {
+		c.flags = fs
+	}
+}
+
+// WithLogger 设置记录配置重新加载的日志记录器，默认使用全局日志记录器
+func WithLogger(log logger.Logger) Option {
+	return func(c *Config) {
+		c.logger = log
+	}
+}
+
+// WithEnviron 设置读取的环境变量，格式与 os.Environ 相同，默认为 os.Environ()，便于测试
+func WithEnviron(environ []string) Option {
+	return func(c *Config) {
+		c.environ = environ
+	}
+}
+
+// Config 合并后的配置
+type Config struct {
+	files     []source
+	envPrefix string
+	flags     *flag.FlagSet
+	environ   []string
+	logger    logger.Logger
+
+	mu       sync.RWMutex
+	defaults map[string]any // Bind 时从 default 标签得到的默认值
+	known    map[string]struct{}
+	data     map[string]any // 当前生效的配置
+	modTimes map[string]time.Time
+	watchers []*watcher
+
+	secretProviders map[string]SecretProvider
+	secretTimeout   time.Duration
+	secretMu        sync.Mutex
+	secrets         map[string]string // 已经解析的密钥，重新加载时清空
+}
+
+// Load 按选项加载配置
+func Load(opts ...Option) (*Config, error) {
+	c := &Config{
+		defaults:        make(map[string]any),
+		known:           make(map[string]struct{}),
+		secretProviders: make(map[string]SecretProvider),
+		secretTimeout:   10 * time.Second,
+		secrets:         make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.environ == nil {
+		c.environ = os.Environ()
+	}
+	if _, ok := c.secretProviders["env"]; !ok {
+		c.secretProviders["env"] = EnvSecretProvider(c.environ)
+	}
+	if _, ok := c.secretProviders["file"]; !ok {
+		c.secretProviders["file"] = FileSecretProvider("")
+	}
+	data, modTimes, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	c.data, c.modTimes = data, modTimes
+	return c, nil
+}
+
+// Bind 加载配置并绑定到 target 指向的结构体
+func Bind(target any, opts ...Option) (*Config, error) {
+	c, err := Load(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Bind(target); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Bind 将配置绑定到 target 指向的结构体，字段的配置键为 config 标签，没有标签时为字段名的蛇形命名。
+// default 标签中的值作为默认值，环境变量会按 target 的字段匹配配置键
+func (c *Config) Bind(target any) error {
+	return c.BindKey("", target)
+}
+
+// BindKey 将 key 下的配置绑定到 target，key 为空时绑定全部配置
+func (c *Config) BindKey(key string, target any) error {
+	rv, err := targetValue(target)
+	if err != nil {
+		return err
+	}
+	prefix := normalizeKey(key)
+
+	// 结构体的字段也是已知的配置键，用于匹配环境变量，default 标签作为默认值
+	defaults := make(map[string]any)
+	known := make(map[string]struct{})
+	collectFields(rv.Type(), prefix, defaults, known)
+	c.mu.Lock()
+	changed := false
+	for k, v := range defaults {
+		if _, ok := c.defaults[k]; !ok {
+			c.defaults[k] = v
+			changed = true
+		}
+		// 默认值的优先级最低，只填充没有配置的键
+		setDefault(c.data, k, v)
+	}
+	for k := range known {
+		if _, ok := c.known[k]; !ok {
+			c.known[k] = struct{}{}
+			changed = true
+		}
+	}
+	if changed {
+		// 新的已知键可能改变环境变量对应的配置键，命令行参数的优先级更高，需要在之后重新应用
+		c.applyEnv(c.data)
+		c.applyFlags(c.data)
+	}
+	c.mu.Unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := lookup(c.data, prefix)
+	if !ok {
+		return nil
+	}
+	value, err = c.resolveSecrets(value, prefix)
+	if err != nil {
+		return err
+	}
+	return decode(value, rv, prefix)
+}
+
+// Get 返回 key 对应的值，key 对应一组配置时返回 map[string]any。密钥无法解析时记录错误并返回 false
+func (c *Config) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.get(normalizeKey(key))
+}
+
+// String 返回 key 对应的字符串，不存在时返回空字符串
+func (c *Config) String(key string) string {
+	var s string
+	c.getAs(key, &s)
+	return s
+}
+
+// Int 返回 key 对应的整数，不存在或无法转换时返回0
+func (c *Config) Int(key string) int {
+	var n int
+	c.getAs(key, &n)
+	return n
+}
+
+// Bool 返回 key 对应的布尔值，不存在或无法转换时返回 false
+func (c *Config) Bool(key string) bool {
+	var b bool
+	c.getAs(key, &b)
+	return b
+}
+
+// Duration 返回 key 对应的时间间隔，值可以是 5s 这样的字符串，不存在或无法转换时返回0
+func (c *Config) Duration(key string) time.Duration {
+	var d time.Duration
+	c.getAs(key, &d)
+	return d
+}
+
+// Strings 返回 key 对应的字符串列表，字符串值按逗号分隔
+func (c *Config) Strings(key string) []string {
+	var s []string
+	c.getAs(key, &s)
+	return s
+}
+
+// Keys 返回所有配置键，按字典序排列
+func (c *Config) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	walkLeaves(c.data, "", func(key string, _ any) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Config) getAs(key string, target any) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key = normalizeKey(key)
+	value, ok := c.get(key)
+	if !ok {
+		return
+	}
+	rv, _ := targetValue(target)
+	_ = decode(value, rv, key)
+}
+
+// get 返回解析了密钥的值，调用时需要持有读锁
+func (c *Config) get(key string) (any, bool) {
+	value, ok := lookup(c.data, key)
+	if !ok {
+		return nil, false
+	}
+	value, err := c.resolveSecrets(value, key)
+	if err != nil {
+		c.log().Error("Failed to resolve secret", logger.String("key", key), logger.FieldError(err))
+		return nil, false
+	}
+	return value, true
+}
+
+// build 读取所有来源，返回合并后的配置和配置文件的修改时间
+func (c *Config) build() (map[string]any, map[string]time.Time, error) {
+	c.mu.RLock()
+	data := make(map[string]any)
+	for k, v := range c.defaults {
+		set(data, k, v)
+	}
+	c.mu.RUnlock()
+
+	modTimes := make(map[string]time.Time)
+	for _, src := range c.files {
+		info, err := os.Stat(src.path)
+		if err != nil {
+			if src.optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("config: %w", err)
+		}
+		modTimes[src.path] = info.ModTime()
+		tree, err := readFile(src.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		merge(data, tree)
+	}
+
+	c.mu.RLock()
+	c.applyEnv(data)
+	c.applyFlags(data)
+	c.mu.RUnlock()
+	return data, modTimes, nil
+}
+
+// applyFlags 将显式设置的命令行参数写入 data
+func (c *Config) applyFlags(data map[string]any) {
+	if c.flags == nil {
+		return
+	}
+	c.flags.Visit(func(f *flag.Flag) {
+		set(data, normalizeKey(f.Name), f.Value.String())
+	})
+}
+
+// applyEnv 将带有前缀的环境变量写入 data，调用时需要持有锁
+func (c *Config) applyEnv(data map[string]any) {
+	if c.envPrefix == "" {
+		return
+	}
+	known := make(map[string]string)
+	walkLeaves(data, "", func(key string, _ any) {
+		known[strings.ReplaceAll(key, ".", "_")] = key
+	})
+	for key := range c.known {
+		known[strings.ReplaceAll(key, ".", "_")] = key
+	}
+
+	for _, kv := range c.environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(strings.ToUpper(name), c.envPrefix) {
+			continue
+		}
+		rest := strings.ToLower(name[len(c.envPrefix):])
+		if rest == "" {
+			continue
+		}
+		key, ok := known[rest]
+		switch {
+		case strings.Contains(rest, "__"):
+			key = strings.ReplaceAll(rest, "__", ".")
+		case !ok:
+			key = strings.ReplaceAll(rest, "_", ".")
+		}
+		set(data, key, value)
+	}
+}
+
+// readFile 按扩展名解析配置文件
+func readFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var raw any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".json":
+		err = json.Unmarshal(content, &raw)
+	case ".toml":
+		raw, err = parseTOML(content)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	if raw == nil {
+		return map[string]any{}, nil
+	}
+	tree, ok := normalize(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config: parse %s: top level must be a mapping", path)
+	}
+	return tree, nil
+}
+
+// normalize 将键转换为小写，map[any]any 转换为 map[string]any
+func normalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[strings.ToLower(k)] = normalize(item)
+		}
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[strings.ToLower(fmt.Sprint(k))] = normalize(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return v
+}
+
+func normalizeKey(key string) string {
+	return strings.Trim(strings.ToLower(key), ".")
+}
+
+// merge 将 src 合并到 dst，两边都是 map 时递归合并，否则 src 覆盖 dst
+func merge(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				merge(dm, sm)
+				continue
+			}
+			copied := make(map[string]any, len(sm))
+			merge(copied, sm)
+			dst[k] = copied
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// set 将 key 设置为 value，中间的层级不存在或不是 map 时创建
+func set(data map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			data[part] = next
+		}
+		data = next
+	}
+	data[parts[len(parts)-1]] = value
+}
+
+// setDefault 在 key 没有配置时设置为 value，中间的层级已经配置为其他值时不覆盖
+func setDefault(data map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		v, ok := data[part]
+		if !ok {
+			v = make(map[string]any)
+			data[part] = v
+		}
+		next, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		data = next
+	}
+	if _, ok := data[parts[len(parts)-1]]; !ok {
+		data[parts[len(parts)-1]] = value
+	}
+}
+
+// lookup 查找 key 对应的值，key 为空时返回整个配置
+func lookup(data map[string]any, key string) (any, bool) {
+	if key == "" {
+		return data, true
+	}
+	var cur any = data
+	for _, part := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// walkLeaves 按键遍历所有不是 map 的值
+func walkLeaves(data map[string]any, prefix string, fn func(key string, value any)) {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok {
+			walkLeaves(m, key, fn)
+			continue
+		}
+		fn(key, v)
+	}
+}
+
+func (c *Config) log() logger.Logger {
+	if c.logger == nil {
+		return logger.GetDefaultLogger()
+	}
+	return c.logger
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogConfig struct {
+	Level  string `config:"level" default:"info"`
+	Format string `default:"json"`
+}
+
+type testAppConfig struct {
+	Name    string        `default:"app"`
+	Debug   bool          `config:"debug"`
+	Timeout time.Duration `config:"timeout" default:"3s"`
+	Tags    []string      `config:"tags"`
+	Server  ServerConfig  `config:"server"`
+	Log     testLogConfig `config:"log"`
+	Limits  map[string]int
+	Ignored string `config:"-"`
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestBind_Layers(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "config.yaml", `
+name: demo
+tags: [a, b]
+server:
+  addr: ":8000"
+  read_timeout: 5s
+log:
+  level: debug
+limits:
+  upload: 10
+`)
+	local := writeFile(t, dir, "config.local.json", `{"server": {"write_timeout": "7s"}}`)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("server.addr", ":8080", "")
+	fs.String("log.level", "info", "")
+	require.NoError(t, fs.Parse([]string{"-server.addr=:9090"}))
+
+	var cfg testAppConfig
+	c, err := Bind(&cfg,
+		WithFile(base),
+		WithOptionalFile(local),
+		WithOptionalFile(filepath.Join(dir, "missing.yaml")),
+		WithEnvPrefix("APP"),
+		WithEnviron([]string{
+			"APP_SERVER_READ_TIMEOUT=1m",
+			"APP_DEBUG=true",
+			"APP_LOG__FORMAT=text",
+			"OTHER_NAME=ignored",
+		}),
+		WithFlags(fs),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "demo", cfg.Name)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 3*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	// 命令行参数覆盖配置文件，没有显式设置的参数不生效
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	// 环境变量覆盖配置文件
+	assert.Equal(t, time.Minute, cfg.Server.ReadTimeout)
+	assert.Equal(t, 7*time.Second, cfg.Server.WriteTimeout)
+	assert.Equal(t, "text", cfg.Log.Format)
+	assert.Equal(t, map[string]int{"upload": 10}, cfg.Limits)
+
+	assert.Equal(t, "demo", c.String("NAME"))
+	assert.Equal(t, time.Minute, c.Duration("server.read_timeout"))
+	assert.True(t, c.Bool("debug"))
+	assert.Equal(t, 10, c.Int("limits.upload"))
+	assert.Equal(t, []string{"a", "b"}, c.Strings("tags"))
+	assert.Contains(t, c.Keys(), "server.write_timeout")
+}
+
+func TestBind_Defaults(t *testing.T) {
+	var cfg testAppConfig
+	_, err := Bind(&cfg, WithEnviron([]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, 3*time.Second, cfg.Timeout)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, "info", cfg.Log.Level)
+	assert.Equal(t, "json", cfg.Log.Format)
+}
+
+func TestBindKey(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.toml", `
+[server]
+addr = ":7000"
+idle_timeout = 30
+`)
+	c, err := Load(WithFile(path), WithEnvPrefix("APP"), WithEnviron([]string{"APP_SERVER_MAX_HEADER_BYTES=4096"}))
+	require.NoError(t, err)
+
+	var server ServerConfig
+	require.NoError(t, c.BindKey("server", &server))
+	assert.Equal(t, ":7000", server.Addr)
+	// 数字按秒处理
+	assert.Equal(t, 30*time.Second, server.IdleTimeout)
+	// 绑定后 server.max_header_bytes 成为已知的配置键，环境变量按它匹配
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+
+	var missing ServerConfig
+	require.NoError(t, c.BindKey("missing", &missing))
+	assert.Equal(t, ":8080", missing.Addr)
+}
+
+func TestBind_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		opts    []Option
+		target  any
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			opts:    []Option{WithFile(filepath.Join(dir, "missing.yaml"))},
+			target:  &testAppConfig{},
+			wantErr: "no such file",
+		},
+		{
+			name:    "unsupported format",
+			opts:    []Option{WithFile(writeFile(t, dir, "config.ini", "a=1"))},
+			target:  &testAppConfig{},
+			wantErr: ErrUnsupportedFormat.Error(),
+		},
+		{
+			name:    "invalid yaml",
+			opts:    []Option{WithFile(writeFile(t, dir, "invalid.yaml", "a: [1"))},
+			target:  &testAppConfig{},
+			wantErr: "config: parse",
+		},
+		{
+			name:    "invalid duration",
+			opts:    []Option{WithFile(writeFile(t, dir, "duration.yaml", "timeout: soon"))},
+			target:  &testAppConfig{},
+			wantErr: "config: timeout",
+		},
+		{
+			name:    "type mismatch",
+			opts:    []Option{WithFile(writeFile(t, dir, "mismatch.yaml", "server: 1"))},
+			target:  &testAppConfig{},
+			wantErr: "config: server: cannot use int",
+		},
+		{
+			name:    "non pointer target",
+			target:  testAppConfig{},
+			wantErr: "non-nil pointer",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Bind(tc.target, append(tc.opts, WithEnviron([]string{}))...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	testCases := map[string]string{
+		"Name":              "name",
+		"ReadTimeout":       "read_timeout",
+		"DSN":               "dsn",
+		"MaxHeaderBytes":    "max_header_bytes",
+		"HTTPAddr":          "http_addr",
+		"OAuth2ClientID":    "o_auth2_client_id",
+		"ReadHeaderTimeout": "read_header_timeout",
+	}
+	for name, want := range testCases {
+		assert.Equal(t, want, snakeCase(name), name)
+	}
+}
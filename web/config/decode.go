@@ -0,0 +1,340 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// targetValue 返回 target 指向的值，target 需要是非 nil 的指针
+func targetValue(target any) (reflect.Value, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return reflect.Value{}, errors.New("config: bind target must be a non-nil pointer")
+	}
+	return rv.Elem(), nil
+}
+
+// fieldKey 返回字段的配置键，config 标签为 - 时返回空字符串
+func fieldKey(f reflect.StructField) string {
+	tag, _, _ := strings.Cut(f.Tag.Get("config"), ",")
+	switch tag {
+	case "-":
+		return ""
+	case "":
+		return snakeCase(f.Name)
+	}
+	return strings.ToLower(tag)
+}
+
+// snakeCase 将 ReadTimeout 转换为 read_timeout，DSN 这样的连续大写视为一个单词
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// collectFields 收集结构体字段对应的配置键和 default 标签中的默认值
+func collectFields(t reflect.Type, prefix string, defaults map[string]any, known map[string]struct{}) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		// 没有 config 标签的匿名结构体字段展开到当前层级
+		if f.Anonymous && f.Tag.Get("config") == "" {
+			collectFields(f.Type, prefix, defaults, known)
+			continue
+		}
+		name := fieldKey(f)
+		if name == "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if def, ok := f.Tag.Lookup("default"); ok {
+			defaults[key] = def
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType && !reflect.PointerTo(ft).Implements(textUnmarshalerType) {
+			collectFields(ft, key, defaults, known)
+			continue
+		}
+		known[key] = struct{}{}
+	}
+}
+
+// decode 将配置值写入 rv，key 用于错误信息
+func decode(value any, rv reflect.Value, key string) error {
+	if value == nil {
+		return nil
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(value, rv.Elem(), key)
+	}
+	if rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType) {
+		s, ok := scalarString(value)
+		if !ok {
+			return typeError(key, value, rv.Type())
+		}
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return typeError(key, value, rv.Type())
+		}
+		return decodeStruct(m, rv, key)
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return typeError(key, value, rv.Type())
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("config: %s: map key must be string", key)
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		}
+		for k, item := range m {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := decode(item, elem, joinKey(key, k)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		var items []any
+		switch v := value.(type) {
+		case []any:
+			items = v
+		case string:
+			// 环境变量和命令行参数中的列表用逗号分隔
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					items = append(items, s)
+				}
+			}
+		default:
+			items = []any{v}
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decode(item, slice.Index(i), fmt.Sprintf("%s[%d]", key, i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Interface:
+		rv.Set(reflect.ValueOf(value))
+		return nil
+	}
+	return decodeScalar(value, rv, key)
+}
+
+// decodeStruct 按字段的配置键写入结构体
+func decodeStruct(m map[string]any, rv reflect.Value, key string) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Tag.Get("config") == "" {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := decodeStruct(m, fv, key); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		name := fieldKey(f)
+		if name == "" {
+			continue
+		}
+		value, ok := m[name]
+		if !ok {
+			continue
+		}
+		if err := decode(value, rv.Field(i), joinKey(key, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeScalar 写入基本类型，字符串会按目标类型解析
+func decodeScalar(value any, rv reflect.Value, key string) error {
+	if rv.Type() == durationType {
+		switch v := value.(type) {
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			rv.SetInt(int64(d))
+			return nil
+		case int, int64, float64:
+			// 数字按秒处理，例如 timeout: 30
+			n, _ := toFloat(v)
+			rv.SetInt(int64(n * float64(time.Second)))
+			return nil
+		}
+		return typeError(key, value, rv.Type())
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		s, ok := scalarString(value)
+		if !ok {
+			return typeError(key, value, rv.Type())
+		}
+		rv.SetString(s)
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			rv.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			rv.SetBool(b)
+		default:
+			return typeError(key, value, rv.Type())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s, ok := value.(string); ok {
+			n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			rv.SetInt(n)
+			return nil
+		}
+		n, ok := toFloat(value)
+		if !ok || n != float64(int64(n)) || rv.OverflowInt(int64(n)) {
+			return typeError(key, value, rv.Type())
+		}
+		rv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s, ok := value.(string); ok {
+			n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			rv.SetUint(n)
+			return nil
+		}
+		n, ok := toFloat(value)
+		if !ok || n < 0 || n != float64(uint64(n)) || rv.OverflowUint(uint64(n)) {
+			return typeError(key, value, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		if s, ok := value.(string); ok {
+			n, err := strconv.ParseFloat(s, rv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			rv.SetFloat(n)
+			return nil
+		}
+		n, ok := toFloat(value)
+		if !ok {
+			return typeError(key, value, rv.Type())
+		}
+		rv.SetFloat(n)
+	default:
+		return fmt.Errorf("config: %s: unsupported type %s", key, rv.Type())
+	}
+	return nil
+}
+
+// scalarString 将字符串、数字和布尔值转换为字符串
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	}
+	return "", false
+}
+
+// toFloat 将 JSON、YAML 和 TOML 解析得到的数字转换为 float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func typeError(key string, value any, t reflect.Type) error {
+	return fmt.Errorf("config: %s: cannot use %T as %s", key, value, t)
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
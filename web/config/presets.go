@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+)
+
+// ServerConfig HTTP 服务器的常用配置，零值表示使用服务器的默认值
+//
+//	server:
+//	  addr: ":8080"
+//	  base_path: /api
+//	  read_timeout: 5s
+//	  write_timeout: 10s
+type ServerConfig struct {
+	Addr              string        `config:"addr" default:":8080"`
+	BasePath          string        `config:"base_path"`
+	ReadTimeout       time.Duration `config:"read_timeout"`
+	WriteTimeout      time.Duration `config:"write_timeout"`
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout"`
+	IdleTimeout       time.Duration `config:"idle_timeout"`
+	MaxHeaderBytes    int           `config:"max_header_bytes"`
+	// KeepAlive 为 nil 时使用默认值（开启）
+	KeepAlive *bool `config:"keep_alive"`
+}
+
+// Options 返回对应的服务器选项，可以追加其他选项后传给 web.NewHTTPServer，Addr 需要传给 Start
+func (c ServerConfig) Options() []web.ServerOption {
+	var opts []web.ServerOption
+	if c.BasePath != "" {
+		opts = append(opts, web.WithBasePath(c.BasePath))
+	}
+	if c.ReadTimeout > 0 {
+		opts = append(opts, web.WithReadTimeout(c.ReadTimeout))
+	}
+	if c.WriteTimeout > 0 {
+		opts = append(opts, web.WithWriteTimeout(c.WriteTimeout))
+	}
+	if c.ReadHeaderTimeout > 0 {
+		opts = append(opts, web.WithReadHeaderTimeout(c.ReadHeaderTimeout))
+	}
+	if c.IdleTimeout > 0 {
+		opts = append(opts, web.WithIdleTimeout(c.IdleTimeout))
+	}
+	if c.MaxHeaderBytes > 0 {
+		opts = append(opts, web.WithMaxHeaderBytes(c.MaxHeaderBytes))
+	}
+	if c.KeepAlive != nil {
+		opts = append(opts, web.WithKeepAlive(*c.KeepAlive))
+	}
+	return opts
+}
+
+// DBConfig 数据库的常用配置
+//
+//	db:
+//	  driver: mysql
+//	  dsn: root:root@tcp(localhost:3306)/app?parseTime=true
+//	  pool:
+//	    max_idle: 10
+//	    max_active: 50
+//	  cache:
+//	    enabled: true
+//	    default_ttl: 5m
+type DBConfig struct {
+	Driver string `config:"driver" default:"mysql"`
+	DSN    string `config:"dsn"`
+	// Dialect 为空时与 Driver 相同
+	Dialect string      `config:"dialect"`
+	Pool    PoolConfig  `config:"pool"`
+	Cache   CacheConfig `config:"cache"`
+}
+
+// PoolConfig 连接池配置，所有字段都为零值时不启用连接池
+type PoolConfig struct {
+	MaxIdle     int           `config:"max_idle"`
+	MaxActive   int           `config:"max_active"`
+	MaxIdleTime time.Duration `config:"max_idle_time"`
+	MaxLifetime time.Duration `config:"max_lifetime"`
+	WaitTimeout time.Duration `config:"wait_timeout"`
+}
+
+// CacheConfig 查询缓存配置，Enabled 为 true 时使用进程内的 orm.MemoryCache
+type CacheConfig struct {
+	Enabled    bool          `config:"enabled"`
+	DefaultTTL time.Duration `config:"default_ttl"`
+	KeyPrefix  string        `config:"key_prefix"`
+	MaxEntries int           `config:"max_entries"`
+}
+
+// Options 返回对应的 ORM 选项
+func (c DBConfig) Options() []orm.DBOption {
+	var opts []orm.DBOption
+	if p := c.Pool; p != (PoolConfig{}) {
+		var poolOpts []orm.DBPoolOption
+		if p.MaxIdle > 0 {
+			poolOpts = append(poolOpts, orm.WithPoolMaxIdle(p.MaxIdle))
+		}
+		if p.MaxActive > 0 {
+			poolOpts = append(poolOpts, orm.WithPoolMaxActive(p.MaxActive))
+		}
+		if p.MaxIdleTime > 0 {
+			poolOpts = append(poolOpts, orm.WithPoolMaxIdleTime(p.MaxIdleTime))
+		}
+		if p.MaxLifetime > 0 {
+			poolOpts = append(poolOpts, orm.WithPoolMaxLifetime(p.MaxLifetime))
+		}
+		if p.WaitTimeout > 0 {
+			poolOpts = append(poolOpts, orm.WithPoolWaitTimeout(p.WaitTimeout))
+		}
+		opts = append(opts, orm.WithConnectionPool(poolOpts...))
+	}
+	if c.Cache.Enabled {
+		var cacheOpts []orm.MemoryCacheOption
+		if c.Cache.MaxEntries > 0 {
+			cacheOpts = append(cacheOpts, orm.WithMaxEntries(c.Cache.MaxEntries))
+		}
+		opts = append(opts, orm.WithDBCache(orm.NewMemoryCache(cacheOpts...)))
+		if c.Cache.DefaultTTL > 0 {
+			opts = append(opts, orm.WithDefaultCacheTTL(c.Cache.DefaultTTL))
+		}
+		if c.Cache.KeyPrefix != "" {
+			opts = append(opts, orm.WithCacheKeyPrefix(c.Cache.KeyPrefix))
+		}
+	}
+	return opts
+}
+
+// Open 按配置打开数据库，opts 追加在配置生成的选项之后
+func (c DBConfig) Open(opts ...orm.DBOption) (*orm.DB, error) {
+	if c.DSN == "" {
+		return nil, errors.New("config: db.dsn is required")
+	}
+	dialect := c.Dialect
+	if dialect == "" {
+		dialect = c.Driver
+	}
+	return orm.OpenDB(c.Driver, c.DSN, dialect, append(c.Options(), opts...)...)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerConfig_Options(t *testing.T) {
+	assert.Empty(t, ServerConfig{Addr: ":8080"}.Options())
+
+	keepAlive := false
+	cfg := ServerConfig{
+		BasePath:          "/api",
+		ReadTimeout:       time.Second,
+		WriteTimeout:      time.Second,
+		ReadHeaderTimeout: time.Second,
+		IdleTimeout:       time.Second,
+		MaxHeaderBytes:    1024,
+		KeepAlive:         &keepAlive,
+	}
+	assert.Len(t, cfg.Options(), 7)
+}
+
+func TestDBConfig_Options(t *testing.T) {
+	assert.Empty(t, DBConfig{}.Options())
+
+	cfg := DBConfig{
+		Pool:  PoolConfig{MaxIdle: 5, MaxActive: 10},
+		Cache: CacheConfig{Enabled: true, DefaultTTL: time.Minute, KeyPrefix: "app", MaxEntries: 100},
+	}
+	// 连接池选项合并为一个，缓存选项为缓存、默认过期时间和前缀
+	assert.Len(t, cfg.Options(), 4)
+}
+
+func TestDBConfig_Bind(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", `
+db:
+  dsn: "file::memory:"
+  pool:
+    max_idle: 2
+    max_lifetime: 1h
+  cache:
+    enabled: true
+`)
+	var cfg struct {
+		DB DBConfig `config:"db"`
+	}
+	_, err := Bind(&cfg, WithFile(path), WithEnvPrefix("APP"), WithEnviron([]string{"APP_DB_DRIVER=sqlite3"}))
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite3", cfg.DB.Driver)
+	assert.Equal(t, "file::memory:", cfg.DB.DSN)
+	assert.Equal(t, 2, cfg.DB.Pool.MaxIdle)
+	assert.Equal(t, time.Hour, cfg.DB.Pool.MaxLifetime)
+	assert.True(t, cfg.DB.Cache.Enabled)
+}
+
+func TestDBConfig_Open(t *testing.T) {
+	_, err := DBConfig{Driver: "mysql"}.Open()
+	assert.ErrorContains(t, err, "db.dsn is required")
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretScheme 密钥引用的前缀
+const secretScheme = "secret://"
+
+var (
+	// ErrSecretNotFound 密钥不存在
+	ErrSecretNotFound = errors.New("config: secret not found")
+	// ErrUnknownSecretProvider 密钥引用中的提供者没有注册
+	ErrUnknownSecretProvider = errors.New("config: unknown secret provider")
+)
+
+// SecretProvider 按路径读取密钥。key 为引用中 # 之后的部分，不为空时表示读取密钥中的某个字段
+type SecretProvider interface {
+	Secret(ctx context.Context, path, key string) (string, error)
+}
+
+// SecretProviderFunc 函数形式的 SecretProvider
+type SecretProviderFunc func(ctx context.Context, path, key string) (string, error)
+
+func (f SecretProviderFunc) Secret(ctx context.Context, path, key string) (string, error) {
+	return f(ctx, path, key)
+}
+
+// WithSecretProvider 注册名称为 name 的密钥提供者，覆盖同名的提供者。
+// 配置值中的 secret://name/path#key 会在读取时通过它解析
+func WithSecretProvider(name string, provider SecretProvider) Option {
+	return func(c *Config) {
+		c.secretProviders[strings.ToLower(name)] = provider
+	}
+}
+
+// WithSecretTimeout 设置解析单个密钥的超时时间，默认为10秒
+func WithSecretTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.secretTimeout = timeout
+	}
+}
+
+// EnvSecretProvider 从环境变量读取密钥，例如 secret://env/DB_PASSWORD。
+// environ 为 nil 时读取进程的环境变量
+func EnvSecretProvider(environ []string) SecretProvider {
+	return SecretProviderFunc(func(_ context.Context, name, key string) (string, error) {
+		var (
+			value string
+			ok    bool
+		)
+		if environ == nil {
+			value, ok = os.LookupEnv(name)
+		} else {
+			for _, kv := range environ {
+				if k, v, found := strings.Cut(kv, "="); found && k == name {
+					value, ok = v, true
+				}
+			}
+		}
+		if !ok {
+			return "", fmt.Errorf("%w: env %s", ErrSecretNotFound, name)
+		}
+		return secretField(value, key)
+	})
+}
+
+// FileSecretProvider 从 dir 下的文件读取密钥，去掉末尾的换行，适合 Docker 和 Kubernetes 挂载的密钥文件。
+// 例如 dir 为 /run/secrets 时，secret://file/db_password 读取 /run/secrets/db_password；
+// dir 为空时路径按绝对路径处理，例如 secret://file//run/secrets/db_password
+func FileSecretProvider(dir string) SecretProvider {
+	return SecretProviderFunc(func(_ context.Context, path, key string) (string, error) {
+		if dir != "" {
+			// 不允许通过 .. 读取 dir 之外的文件
+			path = filepath.Join(dir, filepath.Clean("/"+path))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("%w: file %s", ErrSecretNotFound, path)
+			}
+			return "", fmt.Errorf("config: read secret: %w", err)
+		}
+		return secretField(strings.TrimRight(string(content), "\r\n"), key)
+	})
+}
+
+// secretField 返回 JSON 对象形式的密钥中 key 对应的字段，key 为空时返回整个值
+func secretField(value, key string) (string, error) {
+	if key == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("config: secret is not a JSON object, cannot read field %q", key)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("%w: field %s", ErrSecretNotFound, key)
+	}
+	s, ok := scalarString(field)
+	if !ok {
+		return "", fmt.Errorf("config: secret field %q is not a scalar", key)
+	}
+	return s, nil
+}
+
+// secretRef 解析后的密钥引用
+type secretRef struct {
+	provider string
+	path     string
+	key      string
+}
+
+// parseSecretRef 解析 secret://provider/path#key
+func parseSecretRef(ref string) (secretRef, error) {
+	rest := strings.TrimPrefix(ref, secretScheme)
+	provider, path, ok := strings.Cut(rest, "/")
+	if !ok || provider == "" || path == "" {
+		return secretRef{}, fmt.Errorf("config: invalid secret reference %q", ref)
+	}
+	path, key, _ := strings.Cut(path, "#")
+	return secretRef{provider: strings.ToLower(provider), path: path, key: key}, nil
+}
+
+// hasSecret 判断字符串是否包含密钥引用
+func hasSecret(s string) bool {
+	return strings.Contains(s, secretScheme)
+}
+
+// resolveSecrets 返回解析了密钥引用的副本，没有引用时返回原值，调用时需要持有读锁
+func (c *Config) resolveSecrets(value any, key string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !hasSecret(v) {
+			return v, nil
+		}
+		return c.resolveString(v, key)
+	case map[string]any:
+		var out map[string]any
+		for k, item := range v {
+			resolved, err := c.resolveSecrets(item, joinKey(key, k))
+			if err != nil {
+				return nil, err
+			}
+			if out == nil && !reflect.DeepEqual(resolved, item) {
+				out = make(map[string]any, len(v))
+				for k2, item2 := range v {
+					out[k2] = item2
+				}
+			}
+			if out != nil {
+				out[k] = resolved
+			}
+		}
+		if out == nil {
+			return v, nil
+		}
+		return out, nil
+	case []any:
+		var out []any
+		for i, item := range v {
+			resolved, err := c.resolveSecrets(item, fmt.Sprintf("%s[%d]", key, i))
+			if err != nil {
+				return nil, err
+			}
+			if out == nil && !reflect.DeepEqual(resolved, item) {
+				out = make([]any, len(v))
+				copy(out, v)
+			}
+			if out != nil {
+				out[i] = resolved
+			}
+		}
+		if out == nil {
+			return v, nil
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+// resolveString 解析字符串中的密钥引用。整个值为 secret://... 时替换整个值；
+// 嵌入在其他内容中时需要写成 ${secret://...}，例如 root:${secret://env/DB_PASSWORD}@tcp(localhost:3306)/app
+func (c *Config) resolveString(s, key string) (string, error) {
+	if strings.HasPrefix(s, secretScheme) {
+		return c.secret(s, key)
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${"+secretScheme)
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("config: %s: unterminated secret reference", key)
+		}
+		value, err := c.secret(s[start+2:start+end], key)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+// secret 通过提供者读取密钥，结果会缓存到下一次重新加载
+func (c *Config) secret(ref, key string) (string, error) {
+	c.secretMu.Lock()
+	defer c.secretMu.Unlock()
+	if value, ok := c.secrets[ref]; ok {
+		return value, nil
+	}
+
+	parsed, err := parseSecretRef(ref)
+	if err != nil {
+		return "", fmt.Errorf("%w (key %s)", err, key)
+	}
+	provider, ok := c.secretProviders[parsed.provider]
+	if !ok {
+		return "", fmt.Errorf("%w: %s (key %s)", ErrUnknownSecretProvider, parsed.provider, key)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.secretTimeout)
+	defer cancel()
+	value, err := provider.Secret(ctx, parsed.path, parsed.key)
+	if err != nil {
+		// 错误信息中只包含引用，不包含密钥的值
+		return "", fmt.Errorf("config: %s: resolve %s: %w", key, ref, err)
+	}
+	c.secrets[ref] = value
+	return value, nil
+}
+
+// clearSecrets 清空密钥缓存，重新加载后读取轮换后的密钥
+func (c *Config) clearSecrets() {
+	c.secretMu.Lock()
+	c.secrets = make(map[string]string)
+	c.secretMu.Unlock()
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager map[string]string
+
+func (f fakeSecretsManager) GetSecretString(_ context.Context, id string) (string, error) {
+	value, ok := f[id]
+	if !ok {
+		return "", errors.New("ResourceNotFoundException")
+	}
+	return value, nil
+}
+
+func TestBind_Secrets(t *testing.T) {
+	dir := t.TempDir()
+	secretsDir := filepath.Join(dir, "secrets")
+	require.NoError(t, os.Mkdir(secretsDir, 0o700))
+	writeFile(t, secretsDir, "api_key", "file-key\n")
+
+	path := writeFile(t, dir, "config.yaml", `
+db:
+  dsn: root:${secret://env/DB_PASSWORD}@tcp(localhost:3306)/app
+api:
+  key: secret://files/api_key
+  token: secret://aws/prod/app#token
+  plain: value
+`)
+
+	calls := 0
+	aws := fakeSecretsManager{"prod/app": `{"token": "aws-token", "port": 5432}`}
+	var cfg struct {
+		DB  DBConfig `config:"db"`
+		API struct {
+			Key   string
+			Token string
+			Plain string
+		} `config:"api"`
+	}
+	c, err := Bind(&cfg,
+		WithFile(path),
+		WithEnviron([]string{"DB_PASSWORD=p@ss"}),
+		WithSecretProvider("files", FileSecretProvider(secretsDir)),
+		WithSecretProvider("aws", SecretProviderFunc(func(ctx context.Context, path, key string) (string, error) {
+			calls++
+			return AWSSecretsProvider(aws).Secret(ctx, path, key)
+		})),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "root:p@ss@tcp(localhost:3306)/app", cfg.DB.DSN)
+	assert.Equal(t, "file-key", cfg.API.Key)
+	assert.Equal(t, "aws-token", cfg.API.Token)
+	assert.Equal(t, "value", cfg.API.Plain)
+
+	// 解析结果会缓存，配置中保留的是引用
+	assert.Equal(t, "aws-token", c.String("api.token"))
+	assert.Equal(t, 1, calls)
+
+	// 重新加载后重新解析，读取轮换后的密钥
+	aws["prod/app"] = `{"token": "rotated"}`
+	require.NoError(t, c.Reload())
+	assert.Equal(t, "rotated", c.String("api.token"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestBind_SecretErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		content string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "unknown provider",
+			content: "token: secret://unknown/name",
+			wantErr: ErrUnknownSecretProvider,
+		},
+		{
+			name:    "missing env",
+			content: "token: secret://env/MISSING",
+			wantErr: ErrSecretNotFound,
+		},
+		{
+			name:    "missing file",
+			content: "token: secret://file/" + filepath.Join(dir, "missing"),
+			wantErr: ErrSecretNotFound,
+		},
+		{
+			name:    "invalid reference",
+			content: "token: secret://env",
+			wantMsg: "invalid secret reference",
+		},
+		{
+			name:    "unterminated reference",
+			content: "token: a${secret://env/TOKEN",
+			wantMsg: "unterminated secret reference",
+		},
+		{
+			name:    "field of non JSON secret",
+			content: "token: secret://env/TOKEN#field",
+			wantMsg: "not a JSON object",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeFile(t, dir, "config.yaml", tc.content)
+			var cfg struct{ Token string }
+			_, err := Bind(&cfg, WithFile(path), WithEnviron([]string{"TOKEN=abc"}))
+			require.Error(t, err)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+			assert.Contains(t, err.Error(), tc.wantMsg)
+		})
+	}
+}
+
+func TestConfig_GetSecretError(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "token: secret://env/MISSING")
+	var buf bytes.Buffer
+	c, err := Load(WithFile(path), WithEnviron([]string{}), WithLogger(logger.NewLogger(logger.WithOutput(&buf))))
+	require.NoError(t, err)
+
+	assert.Equal(t, "", c.String("token"))
+	assert.Contains(t, buf.String(), "Failed to resolve secret")
+}
+
+func TestFileSecretProvider_StaysInDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "outside", "outside")
+	inner := filepath.Join(dir, "inner")
+	require.NoError(t, os.Mkdir(inner, 0o700))
+
+	_, err := FileSecretProvider(inner).Secret(context.Background(), "../outside", "")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"v2-pass","user":"app"},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"value":"v1-value","other":"x"}}`))
+		case "/v1/kv/single":
+			_, _ = w.Write([]byte(`{"data":{"only":"single"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewVaultProvider(srv.URL, "token", WithVaultNamespace("team"))
+	ctx := context.Background()
+
+	value, err := p.Secret(ctx, "secret/data/app", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-pass", value)
+
+	value, err = p.Secret(ctx, "kv/app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1-value", value)
+
+	value, err = p.Secret(ctx, "kv/single", "")
+	require.NoError(t, err)
+	assert.Equal(t, "single", value)
+
+	_, err = p.Secret(ctx, "secret/data/app", "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Contains(t, err.Error(), "password, user")
+	assert.NotContains(t, err.Error(), "v2-pass")
+
+	_, err = p.Secret(ctx, "secret/data/none", "")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = NewVaultProvider(srv.URL, "wrong", WithVaultNamespace("team")).Secret(ctx, "kv/app", "")
+	assert.ErrorContains(t, err, "permission denied")
+}
@@ -0,0 +1,487 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML 解析配置文件常用的 TOML 子集：表、表数组、点分隔的键、字符串（包括多行字符串）、
+// 整数、浮点数、布尔值、数组和内联表。日期时间按字符串保存，绑定到 time.Time 字段时再解析
+func parseTOML(content []byte) (map[string]any, error) {
+	p := &tomlParser{src: string(content), line: 1}
+	root := make(map[string]any)
+	current := root
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(p.rest(), "[["):
+			p.pos += 2
+			current, err = p.arrayTable(root)
+		case p.peek() == '[':
+			p.pos++
+			current, err = p.table(root)
+		default:
+			err = p.keyValue(current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) rest() string {
+	return p.src[p.pos:]
+}
+
+// skipSpace 跳过一行内的空白
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank 跳过空白、换行和注释
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine 确认一行剩下的内容只有空白和注释
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q after value", p.peek())
+	}
+	return nil
+}
+
+// table 解析 [a.b]，返回对应的表
+func (p *tomlParser) table(root map[string]any) (map[string]any, error) {
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, fmt.Errorf("expected ] after table name")
+	}
+	p.pos++
+	return descend(root, keys)
+}
+
+// arrayTable 解析 [[a.b]]，在数组末尾添加一个表并返回它
+func (p *tomlParser) arrayTable(root map[string]any) (map[string]any, error) {
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !strings.HasPrefix(p.rest(), "]]") {
+		return nil, fmt.Errorf("expected ]] after array table name")
+	}
+	p.pos += 2
+	parent, err := descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := keys[len(keys)-1]
+	table := make(map[string]any)
+	switch existing := parent[name].(type) {
+	case nil:
+		parent[name] = []any{table}
+	case []any:
+		parent[name] = append(existing, table)
+	default:
+		return nil, fmt.Errorf("key %q is already defined", name)
+	}
+	return table, nil
+}
+
+// descend 返回 keys 对应的表，不存在时创建，经过表数组时使用最后一个元素
+func descend(m map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch next := m[k].(type) {
+		case nil:
+			child := make(map[string]any)
+			m[k] = child
+			m = child
+		case map[string]any:
+			m = next
+		case []any:
+			last, ok := next[len(next)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("key %q is not a table", k)
+			}
+			m = last
+		default:
+			return nil, fmt.Errorf("key %q is not a table", k)
+		}
+	}
+	return m, nil
+}
+
+// keyValue 解析 key = value 并写入 m
+func (p *tomlParser) keyValue(m map[string]any) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return fmt.Errorf("expected = after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	parent, err := descend(m, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	name := keys[len(keys)-1]
+	if _, exists := parent[name]; exists {
+		return fmt.Errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	parent[name] = value
+	return nil
+}
+
+// key 解析点分隔的键，每一段可以是裸键或带引号的键
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		var part string
+		switch p.peek() {
+		case '"':
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case '\'':
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("expected key, found %q", p.peek())
+			}
+			part = p.src[start:p.pos]
+		}
+		keys = append(keys, part)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value 解析一个值
+func (p *tomlParser) value() (any, error) {
+	switch {
+	case strings.HasPrefix(p.rest(), `"""`):
+		return p.multilineBasicString()
+	case strings.HasPrefix(p.rest(), "'''"):
+		return p.multilineLiteralString()
+	case p.peek() == '"':
+		return p.basicString()
+	case p.peek() == '\'':
+		return p.literalString()
+	case p.peek() == '[':
+		return p.array()
+	case p.peek() == '{':
+		return p.inlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}#\n\r", rune(p.peek())) {
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	switch raw {
+	case "":
+		return nil, fmt.Errorf("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return strconv.ParseFloat(raw, 64)
+	}
+	num := strings.ReplaceAll(raw, "_", "")
+	if n, err := strconv.ParseInt(num, 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(num, 64); err == nil && !strings.HasPrefix(num, "0x") {
+		return f, nil
+	}
+	// 日期时间按字符串保存
+	if raw[0] >= '0' && raw[0] <= '9' && strings.ContainsAny(raw, "-:") {
+		return raw, nil
+	}
+	return nil, fmt.Errorf("invalid value %q", raw)
+}
+
+// array 解析数组，数组可以跨越多行，最后一个元素后面可以有逗号
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++
+	items := []any{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+// inlineTable 解析 {a = 1, b = "x"}
+func (p *tomlParser) inlineTable() (map[string]any, error) {
+	p.pos++
+	m := make(map[string]any)
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return m, nil
+	}
+	for {
+		if err := p.keyValue(m); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return m, nil
+		default:
+			return nil, fmt.Errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// basicString 解析带有转义的双引号字符串
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// literalString 解析不处理转义的单引号字符串
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.rest(), "'\n")
+	if end < 0 || p.src[p.pos+end] != '\'' {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// multilineBasicString 解析三个双引号包围的多行字符串，紧跟开头引号的换行会被去掉，行尾的 \ 连接下一行
+func (p *tomlParser) multilineBasicString() (string, error) {
+	p.pos += 3
+	p.trimLeadingNewline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if strings.HasPrefix(p.rest(), `"""`) {
+			p.pos += 3
+			return b.String(), nil
+		}
+		c := p.peek()
+		switch {
+		case c == '\\' && p.lineEndingBackslash():
+			for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+				if p.peek() == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// multilineLiteralString 解析三个单引号包围的多行字符串
+func (p *tomlParser) multilineLiteralString() (string, error) {
+	p.pos += 3
+	p.trimLeadingNewline()
+	end := strings.Index(p.rest(), "'''")
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(s, "\n")
+	p.pos += end + 3
+	return s, nil
+}
+
+func (p *tomlParser) trimLeadingNewline() {
+	if strings.HasPrefix(p.rest(), "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+}
+
+// lineEndingBackslash 当前的 \ 之后直到行尾只有空白
+func (p *tomlParser) lineEndingBackslash() bool {
+	rest := p.src[p.pos+1:]
+	end := strings.IndexByte(rest, '\n')
+	if end < 0 {
+		return false
+	}
+	return strings.TrimSpace(rest[:end]) == ""
+}
+
+// escape 解析转义序列
+func (p *tomlParser) escape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.src) {
+		return fmt.Errorf("unterminated escape")
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		n, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		b.WriteRune(rune(n))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `
+# 注释
+title = "demo" # 行尾注释
+"quoted key" = 'literal \n'
+port = 8_080
+ratio = 0.5
+enabled = true
+created = 2025-03-14T10:00:00Z
+site.name = "dotted"
+
+[server]
+addr = ":8080"
+hosts = [
+  "a",
+  "b", # 注释
+]
+limits = { upload = 10, download = 20 }
+
+[server.tls]
+enabled = false
+
+[[users]]
+name = "alice"
+
+[[users]]
+name = "bob"
+
+[text]
+basic = """
+line1
+line2"""
+literal = '''raw\t'''
+escaped = "tab\tquote\"unicodeé"
+`
+	got, err := parseTOML([]byte(content))
+	require.NoError(t, err)
+
+	want := map[string]any{
+		"title":      "demo",
+		"quoted key": `literal \n`,
+		"port":       int64(8080),
+		"ratio":      0.5,
+		"enabled":    true,
+		"created":    "2025-03-14T10:00:00Z",
+		"site":       map[string]any{"name": "dotted"},
+		"server": map[string]any{
+			"addr":   ":8080",
+			"hosts":  []any{"a", "b"},
+			"limits": map[string]any{"upload": int64(10), "download": int64(20)},
+			"tls":    map[string]any{"enabled": false},
+		},
+		"users": []any{
+			map[string]any{"name": "alice"},
+			map[string]any{"name": "bob"},
+		},
+		"text": map[string]any{
+			"basic":   "line1\nline2",
+			"literal": `raw\t`,
+			"escaped": "tab\tquote\"unicodeé",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing value",
+			content: "a =",
+			wantErr: "line 1",
+		},
+		{
+			name:    "duplicate key",
+			content: "a = 1\na = 2",
+			wantErr: "line 2",
+		},
+		{
+			name:    "unterminated string",
+			content: `a = "abc`,
+			wantErr: "line 1",
+		},
+		{
+			name:    "unterminated table",
+			content: "[server",
+			wantErr: "line 1",
+		},
+		{
+			name:    "trailing content",
+			content: "a = 1 b",
+			wantErr: "line 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseTOML([]byte(tc.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
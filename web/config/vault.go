@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VaultOption VaultProvider 的选项
+type VaultOption func(*VaultProvider)
+
+// WithVaultNamespace 设置 Vault Enterprise 的命名空间
+func WithVaultNamespace(namespace string) VaultOption {
+	return func(p *VaultProvider) {
+		p.namespace = namespace
+	}
+}
+
+// WithVaultHTTPClient 设置请求 Vault 使用的 HTTP 客户端，默认超时时间为10秒
+func WithVaultHTTPClient(client *http.Client) VaultOption {
+	return func(p *VaultProvider) {
+		p.client = client
+	}
+}
+
+// VaultProvider 通过 HTTP API 读取 HashiCorp Vault KV 引擎中的密钥，同时支持 KV v1 和 v2。
+// 路径为 API 路径去掉 /v1/ 前缀，例如 KV v2 挂载在 secret 时：
+//
+//	config.WithSecretProvider("vault", config.NewVaultProvider("", ""))
+//	// db.password: secret://vault/secret/data/app#db_password
+type VaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultProvider 创建 VaultProvider，addr 和 token 为空时分别读取 VAULT_ADDR 和 VAULT_TOKEN 环境变量
+func NewVaultProvider(addr, token string, opts ...VaultOption) *VaultProvider {
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	p := &VaultProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Secret 读取 path 下的密钥字段。key 为空时，密钥只有一个字段则返回该字段，否则返回 value 字段
+func (p *VaultProvider) Secret(ctx context.Context, path, key string) (string, error) {
+	if p.addr == "" {
+		return "", fmt.Errorf("config: vault address is not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("config: vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("config: vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault %s", ErrSecretNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Vault 的错误响应中不包含密钥，读取一部分便于排查
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("config: vault: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("config: vault: decode response: %w", err)
+	}
+	fields := payload.Data
+	// KV v2 的字段在 data.data 中，同时有 data.metadata
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = inner
+		}
+	}
+
+	if key == "" {
+		if len(fields) == 1 {
+			for k := range fields {
+				key = k
+			}
+		} else {
+			key = "value"
+		}
+	}
+	field, ok := fields[key]
+	if !ok {
+		names := make([]string, 0, len(fields))
+		for k := range fields {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("%w: vault %s has no field %q (fields: %s)", ErrSecretNotFound, path, key, strings.Join(names, ", "))
+	}
+	s, ok := scalarString(field)
+	if !ok {
+		return "", fmt.Errorf("config: vault %s: field %q is not a scalar", path, key)
+	}
+	return s, nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// watcher 通过 OnChange 注册的热更新配置键
+type watcher struct {
+	key string
+	fn  func(c *Config)
+}
+
+// OnChange 将 key 标记为可以热更新，重新加载后 key 或它下面的配置发生变化时调用 fn。
+// 没有注册的配置键在重新加载后保持原来的值，因为服务器地址、数据库连接等配置只在启动时使用，
+// 修改它们需要重启，此时只记录一条警告
+//
+//	c.OnChange("log.level", func(c *config.Config) {
+//		level.Set(c.String("log.level"))
+//	})
+func (c *Config) OnChange(key string, fn func(c *Config)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchers = append(c.watchers, &watcher{key: normalizeKey(key), fn: fn})
+}
+
+// Reload 重新读取所有来源，更新通过 OnChange 注册的配置键并调用对应的函数，之后读取的密钥会重新解析。
+// 读取失败时保持当前的配置并返回错误
+func (c *Config) Reload() error {
+	data, modTimes, err := c.build()
+	if err != nil {
+		return err
+	}
+	c.clearSecrets()
+
+	c.mu.Lock()
+	c.modTimes = modTimes
+	// 先比较再更新，注册的配置键可能互相包含，例如 log 和 log.level
+	var fired []*watcher
+	changed := make(map[string]bool)
+	for _, w := range c.watchers {
+		oldValue, _ := lookup(c.data, w.key)
+		newValue, _ := lookup(data, w.key)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			fired = append(fired, w)
+			changed[w.key] = true
+		}
+	}
+	for key := range changed {
+		if value, ok := lookup(data, key); ok {
+			set(c.data, key, value)
+		} else {
+			unset(c.data, key)
+		}
+	}
+	restart := c.changedKeys(data)
+	c.mu.Unlock()
+
+	for _, key := range restart {
+		c.log().Warn("Configuration changed, restart required to apply", logger.String("key", key))
+	}
+	for _, w := range fired {
+		w.fn(c)
+	}
+	return nil
+}
+
+// changedKeys 返回与 data 不同且没有注册热更新的配置键，调用时需要持有锁
+func (c *Config) changedKeys(data map[string]any) []string {
+	seen := make(map[string]struct{})
+	collect := func(from, to map[string]any) {
+		walkLeaves(from, "", func(key string, value any) {
+			other, ok := lookup(to, key)
+			if !ok || !reflect.DeepEqual(value, other) {
+				seen[key] = struct{}{}
+			}
+		})
+	}
+	collect(data, c.data)
+	collect(c.data, data)
+
+	var keys []string
+	for key := range seen {
+		if !c.watched(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// watched 判断 key 是否属于通过 OnChange 注册的配置键
+func (c *Config) watched(key string) bool {
+	for _, w := range c.watchers {
+		if w.key == "" || key == w.key || strings.HasPrefix(key, w.key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch 每隔 interval 检查配置文件的修改时间，文件变化时调用 Reload，ctx 结束时返回。
+// 通常在单独的协程中运行：
+//
+//	go c.Watch(ctx, 2*time.Second)
+func (c *Config) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.filesChanged() {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				c.log().Error("Failed to reload configuration", logger.FieldError(err))
+				// 记录新的修改时间，避免同一个错误的文件反复重新加载
+				c.mu.Lock()
+				c.modTimes = c.statFiles()
+				c.mu.Unlock()
+				continue
+			}
+			c.log().Info("Configuration reloaded")
+		}
+	}
+}
+
+// filesChanged 判断配置文件是否被修改、创建或删除
+func (c *Config) filesChanged() bool {
+	current := c.statFiles()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(current) != len(c.modTimes) {
+		return true
+	}
+	for path, t := range current {
+		if old, ok := c.modTimes[path]; !ok || !old.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// statFiles 返回存在的配置文件的修改时间
+func (c *Config) statFiles() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, src := range c.files {
+		info, err := os.Stat(src.path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				c.log().Warn("Failed to stat configuration file", logger.String("path", src.path), logger.FieldError(err))
+			}
+			continue
+		}
+		modTimes[src.path] = info.ModTime()
+	}
+	return modTimes
+}
+
+// unset 删除 key 对应的值
+func unset(data map[string]any, key string) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part].(map[string]any)
+		if !ok {
+			return
+		}
+		data = next
+	}
+	delete(data, parts[len(parts)-1])
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", `
+server:
+  addr: ":8000"
+log:
+  level: info
+  format: json
+`)
+	var buf bytes.Buffer
+	c, err := Load(WithFile(path), WithEnviron([]string{}), WithLogger(logger.NewLogger(logger.WithOutput(&buf))))
+	require.NoError(t, err)
+
+	var levels []string
+	var logCalls int
+	c.OnChange("log.level", func(c *Config) {
+		levels = append(levels, c.String("log.level"))
+	})
+	c.OnChange("log", func(c *Config) {
+		logCalls++
+	})
+
+	writeFile(t, dir, "config.yaml", `
+server:
+  addr: ":9000"
+log:
+  level: debug
+  format: json
+`)
+	require.NoError(t, c.Reload())
+	assert.Equal(t, []string{"debug"}, levels)
+	assert.Equal(t, 1, logCalls)
+	assert.Equal(t, "debug", c.String("log.level"))
+	// 没有注册热更新的配置保持原来的值
+	assert.Equal(t, ":8000", c.String("server.addr"))
+	assert.Contains(t, buf.String(), "restart required")
+	assert.Contains(t, buf.String(), "server.addr")
+
+	// 没有变化时不调用
+	require.NoError(t, c.Reload())
+	assert.Equal(t, []string{"debug"}, levels)
+	assert.Equal(t, 1, logCalls)
+
+	// 删除的配置键
+	writeFile(t, dir, "config.yaml", "server:\n  addr: \":9000\"\n")
+	require.NoError(t, c.Reload())
+	assert.Equal(t, []string{"debug", ""}, levels)
+	_, ok := c.Get("log")
+	assert.False(t, ok)
+
+	// 读取失败时保持当前的配置
+	writeFile(t, dir, "config.yaml", "log: [")
+	require.Error(t, c.Reload())
+	assert.Equal(t, ":8000", c.String("server.addr"))
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.json", `{"feature": {"enabled": false}}`)
+	c, err := Load(WithFile(path), WithEnviron([]string{}), WithLogger(logger.NewLogger(logger.WithOutput(&bytes.Buffer{}))))
+	require.NoError(t, err)
+
+	changed := make(chan bool, 1)
+	c.OnChange("feature.enabled", func(c *Config) {
+		changed <- c.Bool("feature.enabled")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Watch(ctx, 10*time.Millisecond)
+
+	writeFile(t, dir, "config.json", `{"feature": {"enabled": true}}`)
+	// 部分文件系统的修改时间精度较低，显式修改时间保证能检测到变化
+	future := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, future, future))
+
+	select {
+	case enabled := <-changed:
+		assert.True(t, enabled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("configuration change not detected")
+	}
+}
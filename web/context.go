@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/clock"
 	"github.com/fyerfyer/fyer-webframe/web/logger"
 	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
+	"github.com/fyerfyer/fyer-webframe/web/router"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -20,39 +22,58 @@ import (
 
 // Context 表示HTTP请求和响应的上下文信息
 type Context struct {
-	Req            *http.Request       // HTTP请求对象
-	Resp           http.ResponseWriter // HTTP响应写入器
-	Param          map[string]string   // 路由参数映射
-	RouteURL       string              // 当前路由的URL
-	RespStatusCode int                 // 响应状态码
-	RespData       []byte              // 响应数据
-	unhandled      bool                // 标记是否已处理请求
-	tplEngine      Template            // 模板引擎
-	UserValues     map[string]any      // 用户自定义值存储
-	Context        context.Context     // 标准上下文对象
-	aborted        bool                // 标记是否终止处理
-	poolManager    pool.PoolManager    // 连接池管理器 (注意：这不是对象池)
-	logger         logger.Logger       // 请求级别日志记录器
+	Req            *http.Request           // HTTP请求对象
+	Resp           http.ResponseWriter     // HTTP响应写入器，由服务器包装为 ResponseWriter
+	RouteURL       string                  // 当前路由的URL
+	RespStatusCode int                     // 响应状态码
+	RespData       []byte                  // 响应数据
+	unhandled      bool                    // 标记是否已处理请求
+	tplEngine      TemplateEngine          // 模板引擎
+	UserValues     map[string]any          // 用户自定义值存储
+	Context        context.Context         // 标准上下文对象
+	aborted        bool                    // 标记是否终止处理
+	poolManager    pool.PoolManager        // 连接池管理器 (注意：这不是对象池)
+	logger         logger.Logger           // 请求级别日志记录器
+	respBuf        *objPool.ResponseBuffer // 持有RespData的池化缓冲区，响应写出后归还
+	pooled         bool                    // 是否从对象池中获取
+	errorHandler   ErrorHandler            // 服务器配置的错误处理器
+	cancelFuncs    []context.CancelFunc    // WithTimeout 等派生上下文的取消函数，请求结束时调用
+	clock          clock.Clock             // 服务器的时间来源，nil 时使用系统时间
+	logFields      []logger.Field          // 中间件通过 AddLogFields 添加的日志字段
+	logState       requestLogState         // Logger 派生的日志记录器
+	writer         responseWriter          // 包装Resp，记录写出的状态码和字节数
+	clientCtx      context.Context         // 原始请求的上下文，用于判断客户端是否断开
+	paramNames     []string                // 当前路由按出现顺序排列的参数名，与路由共享
+	paramValues    []string                // 与 paramNames 对应的路径参数值，复用底层数组
 }
 
 // Reset 重置Context对象以便重用
 // 实现objPool.Poolable接口
 func (c *Context) Reset() {
+	// 释放派生的上下文
+	c.cancelContexts()
+
 	// 清空核心字段
 	c.Req = nil
 	c.Resp = nil
+	c.writer.reset(nil)
 	c.Context = nil
+	c.clientCtx = nil
 	c.RespStatusCode = 0
+	c.releaseRespBuffer()
 	c.RespData = nil
 	c.RouteURL = ""
 	c.unhandled = true
 	c.aborted = false
 	c.logger = nil // 重置日志记录器
+	c.logFields = c.logFields[:0]
+	c.logState = requestLogState{}
+	c.errorHandler = nil
+	c.clock = nil
 
-	// 清空路由参数映射但不重新分配
-	for k := range c.Param {
-		delete(c.Param, k)
-	}
+	// 清空路由参数但保留参数值的底层数组
+	c.paramNames = nil
+	c.paramValues = c.paramValues[:0]
 
 	// 清空用户值但不重新分配
 	for k := range c.UserValues {
@@ -72,7 +93,7 @@ func (c *Context) SetRequest(req *http.Request) {
 		if c.logger != nil {
 			reqID := req.Header.Get("X-Request-ID")
 			if reqID == "" {
-				reqID = fmt.Sprintf("%s-%d", req.URL.Path, time.Now().UnixNano())
+				reqID = fmt.Sprintf("%s-%d", req.URL.Path, c.Now().UnixNano())
 			}
 
 			// 创建包含请求ID的日志记录器
@@ -83,9 +104,46 @@ func (c *Context) SetRequest(req *http.Request) {
 	}
 }
 
+// WithTimeout 为当前请求设置超时时间，同时替换 ctx.Context 和 Req 的上下文
+// 之后的 GetConnection 以及传入 ctx.Context 的ORM调用都会遵守该超时时间。
+// 返回的取消函数可以提前释放资源，未调用时会在请求结束时自动调用
+func (c *Context) WithTimeout(timeout time.Duration) context.CancelFunc {
+	return c.WithDeadline(c.Now().Add(timeout))
+}
+
+// WithDeadline 为当前请求设置截止时间，行为与 WithTimeout 相同
+// 截止时间只能缩短，晚于已有截止时间的设置不会生效
+func (c *Context) WithDeadline(deadline time.Time) context.CancelFunc {
+	parent := c.Context
+	if parent == nil {
+		parent = context.Background()
+		if c.Req != nil {
+			parent = c.Req.Context()
+		}
+	}
+
+	ctx, cancel := context.WithDeadline(parent, deadline)
+	c.Context = ctx
+	if c.Req != nil {
+		c.Req = c.Req.WithContext(ctx)
+	}
+	c.cancelFuncs = append(c.cancelFuncs, cancel)
+	return cancel
+}
+
+// cancelContexts 取消请求中派生的上下文，重复调用取消函数是安全的
+func (c *Context) cancelContexts() {
+	for i := len(c.cancelFuncs) - 1; i >= 0; i-- {
+		c.cancelFuncs[i]()
+		c.cancelFuncs[i] = nil
+	}
+	c.cancelFuncs = c.cancelFuncs[:0]
+}
+
 // SetResponse 设置响应写入器，用于对象池重用时
 func (c *Context) SetResponse(resp http.ResponseWriter) {
 	c.Resp = resp
+	c.writer.reset(nil)
 }
 
 // newContextForPool 创建一个新的Context，用于对象池
@@ -96,15 +154,15 @@ func newContextForPool(opts objPool.CtxOptions) interface{} {
 	}
 
 	ctx := &Context{
-		Param:      make(map[string]string, paramCap),
-		UserValues: make(map[string]any, paramCap),
-		unhandled:  true,
-		logger:     logger.GetDefaultLogger(), // 使用默认日志记录器
+		paramValues: make([]string, 0, paramCap),
+		UserValues:  make(map[string]any, paramCap),
+		unhandled:   true,
+		logger:      logger.GetDefaultLogger(), // 使用默认日志记录器
 	}
 
 	// 只在tplEngine非空时进行类型断言
 	if opts.TplEngine != nil {
-		ctx.tplEngine = opts.TplEngine.(Template)
+		ctx.tplEngine = opts.TplEngine.(TemplateEngine)
 	}
 
 	if opts.PoolManager != nil {
@@ -114,8 +172,14 @@ func newContextForPool(opts objPool.CtxOptions) interface{} {
 	return ctx
 }
 
-// Logger 获取上下文关联的日志记录器
+// Logger 获取上下文关联的日志记录器，除了请求ID、请求方法、路径和客户端IP，
+// 还带有匹配的路由、当前用户和中间件通过 AddLogFields 添加的字段
 func (c *Context) Logger() logger.Logger {
+	return c.enrichLogger(c.baseLogger())
+}
+
+// baseLogger 返回服务器设置的请求级别日志记录器
+func (c *Context) baseLogger() logger.Logger {
 	// 如果没有设置日志记录器，返回全局默认记录器
 	if c.logger == nil {
 		c.logger = logger.GetDefaultLogger()
@@ -124,7 +188,7 @@ func (c *Context) Logger() logger.Logger {
 		if c.Req != nil {
 			reqID := c.Req.Header.Get("X-Request-ID")
 			if reqID == "" {
-				reqID = fmt.Sprintf("%s-%d", c.Req.URL.Path, time.Now().UnixNano())
+				reqID = fmt.Sprintf("%s-%d", c.Req.URL.Path, c.Now().UnixNano())
 			}
 
 			c.logger = c.logger.WithField("request_id", reqID).
@@ -135,9 +199,10 @@ func (c *Context) Logger() logger.Logger {
 	return c.logger
 }
 
-// SetLogger 设置上下文关联的日志记录器
+// SetLogger 设置上下文关联的日志记录器，Logger 会在它的基础上添加路由等字段
 func (c *Context) SetLogger(l logger.Logger) {
 	c.logger = l
+	c.logState = requestLogState{}
 }
 
 // Log 方便的日志记录方法，默认使用Info级别
@@ -152,7 +217,7 @@ func (c *Context) LogError(msg string, err error, fields ...logger.Field) {
 }
 
 // InitContextPool 初始化Context对象池
-func InitContextPool(tplEngine Template, connPoolManager pool.PoolManager, paramCap int) {
+func InitContextPool(tplEngine TemplateEngine, connPoolManager pool.PoolManager, paramCap int) {
 	opts := objPool.CtxOptions{
 		TplEngine:     tplEngine,
 		PoolManager:   connPoolManager,
@@ -165,16 +230,75 @@ func InitContextPool(tplEngine Template, connPoolManager pool.PoolManager, param
 // AcquireContext 从池中获取一个Context对象
 func AcquireContext(req *http.Request, resp http.ResponseWriter) *Context {
 	ctx := objPool.AcquireContext(req, resp).(*Context)
+	ctx.pooled = true
 	return ctx
 }
 
 // ReleaseContext 将Context对象返回到池中
+// 不是从池中获取的Context会被忽略
 func ReleaseContext(ctx *Context) {
-	if ctx != nil {
+	if ctx != nil && ctx.pooled {
 		objPool.ReleaseContext(ctx)
 	}
 }
 
+// Clone 复制一个可以在其他goroutine中独立使用的Context
+// 副本拥有独立的路由参数、用户值和响应状态，响应写入resp
+// 当前Context来自对象池时副本也从池中获取，使用完毕后需调用 ReleaseContext 归还
+func (c *Context) Clone(resp http.ResponseWriter) *Context {
+	var clone *Context
+	if c.pooled && objPool.DefaultContextPool != nil {
+		clone = AcquireContext(c.Req, resp)
+	} else {
+		clone = &Context{
+			Req:        c.Req,
+			Resp:       resp,
+			UserValues: make(map[string]any, len(c.UserValues)),
+		}
+	}
+
+	clone.paramNames = c.paramNames
+	clone.paramValues = append(clone.paramValues[:0], c.paramValues...)
+	for k, v := range c.UserValues {
+		clone.UserValues[k] = v
+	}
+
+	clone.Context = c.Context
+	clone.clientCtx = c.clientCtx
+	clone.RouteURL = c.RouteURL
+	clone.RespStatusCode = c.RespStatusCode
+	clone.unhandled = c.unhandled
+	clone.aborted = c.aborted
+	clone.tplEngine = c.tplEngine
+	clone.poolManager = c.poolManager
+	clone.logger = c.logger
+	clone.logFields = append(clone.logFields[:0], c.logFields...)
+	clone.errorHandler = c.errorHandler
+	clone.clock = c.clock
+	return clone
+}
+
+// MergeResponse 将副本上的处理结果合并回当前Context
+// 合并的内容包括响应状态、响应数据和用户值，副本持有的响应缓冲区会转移给当前Context
+func (c *Context) MergeResponse(clone *Context) {
+	c.RespStatusCode = clone.RespStatusCode
+	c.unhandled = clone.unhandled
+	c.aborted = clone.aborted
+
+	c.releaseRespBuffer()
+	c.RespData = clone.RespData
+	c.respBuf = clone.respBuf
+	clone.respBuf = nil
+	clone.RespData = nil
+
+	if c.UserValues == nil {
+		c.UserValues = make(map[string]any, len(clone.UserValues))
+	}
+	for k, v := range clone.UserValues {
+		c.UserValues[k] = v
+	}
+}
+
 // Abort 终止当前请求的处理流程
 func (c *Context) Abort() {
 	c.aborted = true
@@ -432,13 +556,47 @@ func (c *Context) QueryAll() url.Values {
 
 // PathParam 获取路径参数值
 func (c *Context) PathParam(key string) StringValue {
-	val, ok := c.Param[key]
+	val, ok := c.pathParam(key)
 	if !ok {
 		return StringValue{Error: errors.New("key not found")}
 	}
 	return StringValue{Value: val}
 }
 
+// pathParam 按参数名查找路径参数，路由的参数很少，顺序查找比映射更快
+func (c *Context) pathParam(key string) (string, bool) {
+	for i, name := range c.paramNames {
+		if name == key && i < len(c.paramValues) {
+			return c.paramValues[i], true
+		}
+	}
+	return "", false
+}
+
+// SetPathParam 设置路径参数，参数不存在时添加
+func (c *Context) SetPathParam(key, value string) {
+	for i, name := range c.paramNames {
+		if name == key && i < len(c.paramValues) {
+			c.paramValues[i] = value
+			return
+		}
+	}
+	// paramNames 与路由共享且没有多余容量，追加时会复制一份
+	c.paramNames = append(c.paramNames[:len(c.paramNames):len(c.paramNames)], key)
+	c.paramValues = append(c.paramValues[:len(c.paramNames)-1], value)
+}
+
+// PathParams 返回所有路径参数的副本
+func (c *Context) PathParams() map[string]string {
+	params := make(map[string]string, len(c.paramNames))
+	for i, name := range c.paramNames {
+		if i < len(c.paramValues) {
+			params[name] = c.paramValues[i]
+		}
+	}
+	return params
+}
+
 // PathInt 获取整数类型的路径参数
 func (c *Context) PathInt(key string) IntValue {
 	sv := c.PathParam(key)
@@ -491,6 +649,20 @@ func (c *Context) PathBool(key string) BoolValue {
 	return BoolValue{Value: val}
 }
 
+// PathValue 获取按路由约束转换后的路径参数，例如 {id:int} 返回 int64，{id:uint} 返回 uint64，
+// {id:uuid} 返回 uuid.UUID，没有约束的参数返回字符串
+func (c *Context) PathValue(key string) (any, error) {
+	sv := c.PathParam(key)
+	if sv.Error != nil {
+		return nil, sv.Error
+	}
+	constraint, ok := router.ParamConstraint(c.RouteURL, key)
+	if !ok {
+		return sv.Value, nil
+	}
+	return constraint.ParseValue(sv.Value)
+}
+
 // HTTP头部处理
 
 // GetHeader 获取请求头的值
@@ -574,8 +746,13 @@ func (c *Context) IsMultipartForm() bool {
 
 // ClientIP 获取客户端IP地址
 func (c *Context) ClientIP() string {
+	return clientIP(c.Req)
+}
+
+// clientIP 获取请求的客户端IP地址，创建 Context 之前也可以使用
+func clientIP(req *http.Request) string {
 	// 检查X-Forwarded-For和X-Real-IP头部（用于代理）
-	if ip := c.GetHeader("X-Forwarded-For"); ip != "" {
+	if ip := req.Header.Get("X-Forwarded-For"); ip != "" {
 		// X-Forwarded-For头部可能包含多个IP
 		ips := strings.Split(ip, ",")
 		// 返回第一个非空地址
@@ -587,12 +764,12 @@ func (c *Context) ClientIP() string {
 		}
 	}
 
-	if ip := c.GetHeader("X-Real-IP"); ip != "" {
+	if ip := req.Header.Get("X-Real-IP"); ip != "" {
 		return ip
 	}
 
 	// 否则使用RemoteAddr
-	ip, _, _ := strings.Cut(c.Req.RemoteAddr, ":")
+	ip, _, _ := strings.Cut(req.RemoteAddr, ":")
 	return ip
 }
 
@@ -620,6 +797,7 @@ func (c *Context) SetPoolManager(manager pool.PoolManager) {
 }
 
 // GetConnection 从指定池中获取连接
+// 获取连接时使用 ctx.Context，通过 WithTimeout 设置的超时时间同样限制等待连接的时间
 func (c *Context) GetConnection(poolName string) (pool.Connection, error) {
 	p, err := c.Pool(poolName)
 	if err != nil {
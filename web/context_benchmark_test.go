@@ -96,10 +96,8 @@ func BenchmarkContextBindXML(b *testing.B) {
 
 func BenchmarkContextPathParam(b *testing.B) {
 	ctx := &Context{
-		Param: map[string]string{
-			"id": "123",
-			"name": "test",
-		},
+		paramNames:  []string{"id", "name"},
+		paramValues: []string{"123", "test"},
 	}
 
 	b.ResetTimer()
@@ -288,4 +286,67 @@ func BenchmarkHTTPServer_ServeHTTP(b *testing.B) {
 		w.Body.Reset()
 		server.ServeHTTP(w, req)
 	}
-}
\ No newline at end of file
+}
+func BenchmarkContextStringNoFormat(b *testing.B) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := &Context{
+		Req:  req,
+		Resp: w,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.String(200, "Hello, World!")
+	}
+}
+
+func BenchmarkContextStringNoCopy(b *testing.B) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := &Context{
+		Req:  req,
+		Resp: w,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.StringNoCopy(200, "Hello, World!")
+	}
+}
+
+func BenchmarkContextBytes(b *testing.B) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := &Context{
+		Req:  req,
+		Resp: w,
+	}
+	data := []byte("Hello, World!")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Bytes(200, data)
+	}
+}
+
+func BenchmarkContextJSONPooledBuffer(b *testing.B) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := &Context{
+		Req:  req,
+		Resp: w,
+	}
+
+	user := &benchUser{ID: 123, Name: "tester"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.JSON(200, user)
+		ctx.releaseRespBuffer()
+	}
+}
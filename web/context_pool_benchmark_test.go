@@ -22,7 +22,6 @@ func BenchmarkContextCreation(b *testing.B) {
 			ctx := &Context{
 				Req:         req,
 				Resp:        resp,
-				Param:       make(map[string]string, 8),
 				UserValues:  make(map[string]any, 8),
 				Context:     req.Context(),
 				unhandled:   true,
@@ -52,7 +51,6 @@ func BenchmarkContextReset(b *testing.B) {
 	ctx := &Context{
 		Req:         httptest.NewRequest(http.MethodGet, "/test", nil),
 		Resp:        httptest.NewRecorder(),
-		Param:       make(map[string]string, 8),
 		UserValues:  make(map[string]any, 8),
 		RespData:    []byte("test data"),
 		Context:     context.Background(),
@@ -61,8 +59,8 @@ func BenchmarkContextReset(b *testing.B) {
 	}
 
 	// Add some data to maps
-	ctx.Param["id"] = "123"
-	ctx.Param["name"] = "test"
+	ctx.SetPathParam("id", "123")
+	ctx.SetPathParam("name", "test")
 	ctx.UserValues["key1"] = "value1"
 	ctx.UserValues["key2"] = 42
 
@@ -77,8 +75,8 @@ func BenchmarkContextReset(b *testing.B) {
 		ctx.Resp = httptest.NewRecorder()
 		ctx.Context = ctx.Req.Context()
 		ctx.RespData = []byte("test data")
-		ctx.Param["id"] = "123"
-		ctx.Param["name"] = "test"
+		ctx.SetPathParam("id", "123")
+		ctx.SetPathParam("name", "test")
 		ctx.UserValues["key1"] = "value1"
 		ctx.UserValues["key2"] = 42
 		ctx.unhandled = false
@@ -92,7 +90,6 @@ func BenchmarkContextSetup(b *testing.B) {
 		resp := httptest.NewRecorder()
 
 		ctx := &Context{
-			Param:      make(map[string]string, 8),
 			UserValues: make(map[string]any, 8),
 		}
 
@@ -110,7 +107,6 @@ func BenchmarkContextSetup(b *testing.B) {
 		resp := httptest.NewRecorder()
 
 		ctx := &Context{
-			Param:      make(map[string]string, 8),
 			UserValues: make(map[string]any, 8),
 		}
 
@@ -138,7 +134,7 @@ func BenchmarkConcurrentContextPool(b *testing.B) {
 			ctx := AcquireContext(req, resp)
 
 			// Simulate some work with the context
-			ctx.Param["id"] = "123"
+			ctx.SetPathParam("id", "123")
 			ctx.UserValues["key"] = "value"
 			ctx.RespStatusCode = 200
 
@@ -186,7 +182,7 @@ func BenchmarkContextPoolVsNew(b *testing.B) {
 					resp := httptest.NewRecorder()
 
 					ctx := AcquireContext(req, resp)
-					ctx.Param["id"] = "123"
+					ctx.SetPathParam("id", "123")
 					ctx.UserValues["key"] = "value"
 
 					ReleaseContext(ctx)
@@ -212,13 +208,12 @@ func BenchmarkContextPoolVsNew(b *testing.B) {
 					ctx := &Context{
 						Req:         req,
 						Resp:        resp,
-						Param:       make(map[string]string, 8),
 						UserValues:  make(map[string]any, 8),
 						Context:     req.Context(),
 						unhandled:   true,
 					}
 
-					ctx.Param["id"] = "123"
+					ctx.SetPathParam("id", "123")
 					ctx.UserValues["key"] = "value"
 
 					// No cleanup needed, let GC handle it
@@ -255,7 +250,7 @@ func BenchmarkContextOperationsWithPool(b *testing.B) {
 			_ = ctx.QueryParam("q")
 
 			// Param operations
-			ctx.Param["id"] = "123"
+			ctx.SetPathParam("id", "123")
 			_ = ctx.PathParam("id")
 
 			// Reset and release
@@ -285,7 +280,7 @@ func BenchmarkMultipleContextParameters(b *testing.B) {
 
 				// Fill with parameters
 				for j := 0; j < size; j++ {
-					ctx.Param[string(rune('a'+j))] = string(rune('1'+j))
+					ctx.SetPathParam(string(rune('a'+j)), string(rune('1'+j)))
 				}
 
 				ReleaseContext(ctx)
@@ -303,7 +298,6 @@ func BenchmarkMultipleContextParameters(b *testing.B) {
 				ctx := &Context{
 					Req:         req,
 					Resp:        resp,
-					Param:       make(map[string]string, size),
 					UserValues:  make(map[string]any, size),
 					Context:     req.Context(),
 					unhandled:   true,
@@ -311,7 +305,7 @@ func BenchmarkMultipleContextParameters(b *testing.B) {
 
 				// Fill with parameters
 				for j := 0; j < size; j++ {
-					ctx.Param[string(rune('a'+j))] = string(rune('1'+j))
+					ctx.SetPathParam(string(rune('a'+j)), string(rune('1'+j)))
 				}
 
 				// Just let GC collect it
@@ -41,7 +41,7 @@ func TestContextPool(t *testing.T) {
 		}
 
 		// 添加一些数据到上下文
-		ctx.Param["id"] = "123"
+		ctx.SetPathParam("id", "123")
 		ctx.UserValues["key"] = "value"
 		ctx.RespStatusCode = 200
 		ctx.RespData = []byte("test data")
@@ -55,8 +55,8 @@ func TestContextPool(t *testing.T) {
 		newCtx := AcquireContext(req, resp)
 
 		// 检查重置是否正确执行
-		if len(newCtx.Param) != 0 {
-			t.Errorf("Expected empty Param map, got %v", newCtx.Param)
+		if len(newCtx.PathParams()) != 0 {
+			t.Errorf("Expected empty params, got %v", newCtx.PathParams())
 		}
 
 		if len(newCtx.UserValues) != 0 {
@@ -88,7 +88,8 @@ func TestContextPool(t *testing.T) {
 		ctx := &Context{
 			Req:            httptest.NewRequest(http.MethodGet, "/test", nil),
 			Resp:           httptest.NewRecorder(),
-			Param:          map[string]string{"id": "123"},
+			paramNames:     []string{"id"},
+			paramValues:    []string{"123"},
 			RespStatusCode: 200,
 			RespData:       []byte("test data"),
 			unhandled:      false,
@@ -109,8 +110,8 @@ func TestContextPool(t *testing.T) {
 			t.Errorf("Expected Resp to be nil after reset, got %v", ctx.Resp)
 		}
 
-		if len(ctx.Param) != 0 {
-			t.Errorf("Expected Param to be empty after reset, got %v", ctx.Param)
+		if len(ctx.PathParams()) != 0 {
+			t.Errorf("Expected params to be empty after reset, got %v", ctx.PathParams())
 		}
 
 		if ctx.RespStatusCode != 0 {
@@ -137,7 +138,6 @@ func TestContextPool(t *testing.T) {
 	// 测试SetRequest和SetResponse方法
 	t.Run("SetRequestAndResponse", func(t *testing.T) {
 		ctx := &Context{
-			Param:      make(map[string]string),
 			UserValues: make(map[string]any),
 		}
 
@@ -175,7 +175,7 @@ func TestContextPool(t *testing.T) {
 			ctx := AcquireContext(req, resp)
 
 			// 模拟请求处理
-			ctx.Param["id"] = "123"
+			ctx.SetPathParam("id", "123")
 			ctx.RespStatusCode = 200
 			ctx.RespData = []byte("response data")
 
@@ -188,7 +188,7 @@ func TestContextPool(t *testing.T) {
 		resp := httptest.NewRecorder()
 		ctx := AcquireContext(req, resp)
 
-		if len(ctx.Param) != 0 {
+		if len(ctx.PathParams()) != 0 {
 			t.Error("Context not properly reset in pool lifecycle")
 		}
 
@@ -198,4 +198,49 @@ func TestContextPool(t *testing.T) {
 
 		ReleaseContext(ctx)
 	})
-}
\ No newline at end of file
+}
+func TestContextCloneAndMerge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	resp := httptest.NewRecorder()
+	ctx := &Context{
+		Req:         req,
+		Resp:        resp,
+		paramNames:  []string{"id"},
+		paramValues: []string{"1"},
+		UserValues:  map[string]any{"user": "tom"},
+		unhandled:   true,
+	}
+
+	clone := ctx.Clone(httptest.NewRecorder())
+	if clone.PathParam("id").Value != "1" || clone.UserValues["user"] != "tom" {
+		t.Fatalf("Clone did not copy params and user values: %v %v", clone.PathParams(), clone.UserValues)
+	}
+
+	// 副本的修改不影响原Context
+	clone.SetPathParam("id", "2")
+	clone.UserValues["role"] = "admin"
+	if ctx.PathParam("id").Value != "1" {
+		t.Errorf("Expected original param to stay '1', got '%s'", ctx.PathParam("id").Value)
+	}
+	if _, ok := ctx.UserValues["role"]; ok {
+		t.Error("Expected original user values to be unaffected by clone")
+	}
+
+	if err := clone.JSON(http.StatusCreated, map[string]string{"ok": "1"}); err != nil {
+		t.Fatalf("Failed to create JSON response: %v", err)
+	}
+
+	ctx.MergeResponse(clone)
+	if ctx.RespStatusCode != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, ctx.RespStatusCode)
+	}
+	if string(ctx.RespData) != "{\"ok\":\"1\"}\n" {
+		t.Errorf("Unexpected response data: %s", string(ctx.RespData))
+	}
+	if ctx.UserValues["role"] != "admin" {
+		t.Error("Expected user values from clone to be merged")
+	}
+	if ctx.respBuf == nil || clone.respBuf != nil {
+		t.Error("Expected response buffer ownership to move to the original context")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fyerfyer/fyer-kit/pool"
 	"github.com/stretchr/testify/assert"
@@ -132,12 +133,8 @@ func TestContext(t *testing.T) {
 
 	t.Run("path parameters", func(t *testing.T) {
 		ctx := &Context{
-			Param: map[string]string{
-				"id":     "123",
-				"name":   "test",
-				"active": "true",
-				"height": "1.85",
-			},
+			paramNames:  []string{"id", "name", "active", "height"},
+			paramValues: []string{"123", "test", "true", "1.85"},
 		}
 
 		idVal := ctx.PathParam("id")
@@ -580,4 +577,87 @@ func TestContextPoolAccess(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, pool)
 	})
-}
\ No newline at end of file
+}
+func TestContext_WithTimeout(t *testing.T) {
+	t.Run("swap request context", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := &Context{Req: req, Context: req.Context()}
+
+		cancel := ctx.WithTimeout(time.Minute)
+		deadline, ok := ctx.Context.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		assert.Equal(t, ctx.Context, ctx.Req.Context())
+
+		// 截止时间只能缩短
+		ctx.WithTimeout(time.Hour)
+		later, _ := ctx.Context.Deadline()
+		assert.Equal(t, deadline, later)
+
+		cancel()
+		assert.ErrorIs(t, ctx.Context.Err(), context.Canceled)
+	})
+
+	t.Run("server write timeout", func(t *testing.T) {
+		s := NewHTTPServer(WithWriteTimeout(30 * time.Second))
+
+		var handlerCtx context.Context
+		s.Get("/deadline", func(ctx *Context) {
+			handlerCtx = ctx.Context
+			deadline, ok := ctx.Req.Context().Deadline()
+			require.True(t, ok)
+			ctx.String(http.StatusOK, time.Until(deadline).Round(time.Second).String())
+		})
+
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/deadline", nil))
+		assert.Equal(t, "30s", resp.Body.String())
+
+		// 请求结束后派生的上下文被取消
+		assert.ErrorIs(t, handlerCtx.Err(), context.Canceled)
+	})
+
+	t.Run("cancel on request end", func(t *testing.T) {
+		s := NewHTTPServer(WithObjectPool(8))
+
+		var handlerCtx context.Context
+		s.Get("/timeout", func(ctx *Context) {
+			ctx.WithTimeout(time.Hour)
+			handlerCtx = ctx.Context
+			ctx.String(http.StatusOK, "ok")
+		})
+
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/timeout", nil))
+		assert.Equal(t, "ok", resp.Body.String())
+		assert.ErrorIs(t, handlerCtx.Err(), context.Canceled)
+	})
+}
+
+func TestContext_PathParamStorage(t *testing.T) {
+	s := NewHTTPServer(WithObjectPool(8))
+	s.Get("/users/:id/posts/{post:int}", func(ctx *Context) {})
+
+	ctx := AcquireContext(httptest.NewRequest(http.MethodGet, "/users/42/posts/7", nil), httptest.NewRecorder())
+	defer ReleaseContext(ctx)
+
+	// 查找路由复用上下文中的参数切片，不分配内存
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = s.lookupRoute(http.MethodGet, "/users/42/posts/7", ctx)
+		_ = ctx.PathParam("post")
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, "42", ctx.PathParam("id").Value)
+	assert.Equal(t, map[string]string{"id": "42", "post": "7"}, ctx.PathParams())
+
+	// 添加参数不影响路由共享的参数名
+	ctx.SetPathParam("id", "43")
+	ctx.SetPathParam("extra", "x")
+	assert.Equal(t, map[string]string{"id": "43", "post": "7", "extra": "x"}, ctx.PathParams())
+
+	other := &Context{}
+	_, ok := s.lookupRoute(http.MethodGet, "/users/1/posts/2", other)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "1", "post": "2"}, other.PathParams())
+	assert.Error(t, other.PathParam("extra").Error)
+}
@@ -0,0 +1,68 @@
+package web
+
+import "github.com/fyerfyer/fyer-webframe/web/logger"
+
+// Identifier 当前用户实现该接口时，ctx.Logger() 返回的日志记录器带有 user 字段。
+// 当前用户为字符串时直接使用，其他类型不会记录，避免将整个用户对象写入日志
+type Identifier interface {
+	Identity() string
+}
+
+// requestLogState 缓存 Logger 派生的日志记录器，路由、用户或字段变化时重新派生
+type requestLogState struct {
+	logger   logger.Logger
+	route    string
+	identity string
+	fields   int
+}
+
+// AddLogFields 为当前请求之后的所有日志添加字段，包括请求完成的日志，通常由中间件调用：
+//
+//	func Tenant(next web.HandlerFunc) web.HandlerFunc {
+//		return func(ctx *web.Context) {
+//			ctx.AddLogFields(logger.String("tenant", ctx.GetHeader("X-Tenant")))
+//			next(ctx)
+//		}
+//	}
+func (c *Context) AddLogFields(fields ...logger.Field) {
+	c.logFields = append(c.logFields, fields...)
+}
+
+// enrichLogger 在 base 的基础上添加路由、当前用户和 AddLogFields 添加的字段
+func (c *Context) enrichLogger(base logger.Logger) logger.Logger {
+	route, identity := c.RouteURL, c.identity()
+	if route == "" && identity == "" && len(c.logFields) == 0 {
+		return base
+	}
+	st := &c.logState
+	if st.logger != nil && st.route == route && st.identity == identity && st.fields == len(c.logFields) {
+		return st.logger
+	}
+
+	fields := make([]logger.Field, 0, len(c.logFields)+2)
+	if route != "" {
+		fields = append(fields, logger.String("route", route))
+	}
+	if identity != "" {
+		fields = append(fields, logger.String("user", identity))
+	}
+	fields = append(fields, c.logFields...)
+	*st = requestLogState{
+		logger:   base.WithFields(fields...),
+		route:    route,
+		identity: identity,
+		fields:   len(c.logFields),
+	}
+	return st.logger
+}
+
+// identity 返回当前用户的标识
+func (c *Context) identity() string {
+	switch user := c.CurrentUser().(type) {
+	case Identifier:
+		return user.Identity()
+	case string:
+		return user
+	}
+	return ""
+}
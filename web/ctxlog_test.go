@@ -0,0 +1,107 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIdentity struct {
+	ID    string
+	Email string
+}
+
+func (u testIdentity) Identity() string {
+	return u.ID
+}
+
+// readLogs 按行解析 JSON 格式的日志，返回消息到各条日志字段的映射
+func readLogs(t *testing.T, out *bytes.Buffer) map[string][]map[string]any {
+	t.Helper()
+	logs := make(map[string][]map[string]any)
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		msg := entry["message"].(string)
+		logs[msg] = append(logs[msg], entry)
+	}
+	return logs
+}
+
+func TestContextLogger(t *testing.T) {
+	var out bytes.Buffer
+	s := NewHTTPServer(WithLogger(logger.NewLogger(logger.WithOutput(&out))))
+	s.Use("GET", "/users/:id", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.SetCurrentUser(testIdentity{ID: "u-1", Email: "a@example.com"})
+			ctx.AddLogFields(logger.String("tenant", "acme"))
+			next(ctx)
+		}
+	})
+	s.Get("/users/:id", func(ctx *Context) {
+		ctx.Logger().Info("Loading user")
+		ctx.AddLogFields(logger.Int("attempt", 2))
+		ctx.Log("Loaded user")
+		ctx.String(http.StatusOK, "ok")
+	})
+	s.Get("/anonymous", func(ctx *Context) {
+		ctx.SetCurrentUser(map[string]string{"name": "hidden"})
+		ctx.Log("Anonymous")
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anonymous", nil))
+
+	logs := readLogs(t, &out)
+
+	require.Len(t, logs["Loading user"], 1)
+	loading := logs["Loading user"][0]
+	assert.Equal(t, "req-1", loading["request_id"])
+	assert.Equal(t, "GET", loading["method"])
+	assert.Equal(t, "203.0.113.7", loading["client_ip"])
+	assert.Equal(t, "/users/:id", loading["route"])
+	assert.Equal(t, "u-1", loading["user"])
+	assert.Equal(t, "acme", loading["tenant"])
+	assert.NotContains(t, loading, "attempt")
+
+	// 之后添加的字段对之后的日志生效，包括请求完成的日志
+	require.Len(t, logs["Loaded user"], 1)
+	assert.EqualValues(t, 2, logs["Loaded user"][0]["attempt"])
+	completed := logs["Request completed successfully"]
+	require.Len(t, completed, 2)
+	assert.Equal(t, "/users/:id", completed[0]["route"])
+	assert.Equal(t, "u-1", completed[0]["user"])
+	assert.Equal(t, "acme", completed[0]["tenant"])
+	assert.Equal(t, "/anonymous", completed[1]["route"])
+
+	require.Len(t, logs["Anonymous"], 1)
+	anonymous := logs["Anonymous"][0]
+	assert.NotContains(t, anonymous, "user", "only identities and strings are logged")
+	assert.NotContains(t, anonymous, "tenant", "fields do not leak between requests")
+}
+
+func TestContextLogger_Cached(t *testing.T) {
+	ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), UserValues: map[string]any{}}
+	ctx.SetLogger(logger.NewLogger(logger.WithOutput(&bytes.Buffer{})))
+	base := ctx.Logger()
+
+	ctx.RouteURL = "/items/:id"
+	routed := ctx.Logger()
+	assert.NotSame(t, base, routed)
+	assert.Same(t, routed, ctx.Logger())
+
+	ctx.SetCurrentUser("alice")
+	assert.NotSame(t, routed, ctx.Logger())
+}
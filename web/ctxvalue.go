@@ -0,0 +1,52 @@
+package web
+
+// CtxKey 带类型和命名空间的上下文值键
+// 值仍然保存在 UserValues 中，键名为 "命名空间.名称"，不同中间件使用各自的命名空间即可避免键名冲突
+// 通过 CtxValue 读取时会校验值的类型，类型不符视为不存在
+type CtxKey[T any] struct {
+	name string
+}
+
+// NewCtxKey 创建上下文值键，通常在包级别定义后复用
+//
+//	var userIDKey = web.NewCtxKey[int64]("auth", "user_id")
+func NewCtxKey[T any](namespace, name string) CtxKey[T] {
+	if namespace == "" {
+		return CtxKey[T]{name: name}
+	}
+	return CtxKey[T]{name: namespace + "." + name}
+}
+
+// Name 返回键在 UserValues 中的名称
+func (k CtxKey[T]) Name() string {
+	return k.name
+}
+
+// SetCtxValue 设置上下文值
+// 上下文从对象池复用时 UserValues 会在 Reset 中清空，值只在当前请求内有效，
+// 处理函数返回后不应再通过保存的 *Context 读取
+func SetCtxValue[T any](ctx *Context, key CtxKey[T], val T) {
+	if ctx.UserValues == nil {
+		ctx.UserValues = make(map[string]any)
+	}
+	ctx.UserValues[key.name] = val
+}
+
+// CtxValue 读取上下文值，值不存在或类型不符时返回零值和false
+func CtxValue[T any](ctx *Context, key CtxKey[T]) (T, bool) {
+	val, ok := ctx.UserValues[key.name].(T)
+	return val, ok
+}
+
+// CtxValueOr 读取上下文值，值不存在或类型不符时返回默认值
+func CtxValueOr[T any](ctx *Context, key CtxKey[T], def T) T {
+	if val, ok := CtxValue(ctx, key); ok {
+		return val
+	}
+	return def
+}
+
+// DeleteCtxValue 删除上下文值
+func DeleteCtxValue[T any](ctx *Context, key CtxKey[T]) {
+	delete(ctx.UserValues, key.name)
+}
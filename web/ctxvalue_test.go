@@ -0,0 +1,68 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtxValue(t *testing.T) {
+	userID := NewCtxKey[int64]("auth", "user_id")
+	role := NewCtxKey[string]("auth", "role")
+	// 同名但类型不同的键
+	userIDString := NewCtxKey[string]("auth", "user_id")
+
+	ctx := &Context{}
+	_, ok := CtxValue(ctx, userID)
+	assert.False(t, ok)
+	assert.Equal(t, "guest", CtxValueOr(ctx, role, "guest"))
+
+	SetCtxValue(ctx, userID, 42)
+	SetCtxValue(ctx, role, "admin")
+	assert.Equal(t, "auth.user_id", userID.Name())
+	assert.Equal(t, int64(42), ctx.UserValues["auth.user_id"])
+
+	id, ok := CtxValue(ctx, userID)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), id)
+	assert.Equal(t, "admin", CtxValueOr(ctx, role, "guest"))
+
+	_, ok = CtxValue(ctx, userIDString)
+	assert.False(t, ok)
+
+	DeleteCtxValue(ctx, role)
+	_, ok = CtxValue(ctx, role)
+	assert.False(t, ok)
+
+	ctx.Reset()
+	_, ok = CtxValue(ctx, userID)
+	assert.False(t, ok)
+
+	assert.Equal(t, "plain", NewCtxKey[int]("", "plain").Name())
+}
+
+func TestCtxValue_PooledContext(t *testing.T) {
+	counter := NewCtxKey[int]("test", "counter")
+
+	s := NewHTTPServer()
+	s.Use(http.MethodGet, "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			n := CtxValueOr(ctx, counter, 0)
+			SetCtxValue(ctx, counter, n+1)
+			next(ctx)
+		}
+	})
+	s.Get("/count", func(ctx *Context) {
+		n, _ := CtxValue(ctx, counter)
+		ctx.JSON(http.StatusOK, n)
+	})
+
+	// 复用的上下文不会带上前一个请求的值
+	for i := 0; i < 3; i++ {
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/count", nil))
+		assert.Equal(t, "1\n", resp.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// debugProfiles 通过 pprof.Handler 提供的运行时profile
+var debugProfiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// EnableDebug 在 prefix 下注册 pprof 和 expvar 调试端点，不需要再额外启动 http.DefaultServeMux
+//
+//	{prefix}/pprof/         profile列表
+//	{prefix}/pprof/{name}   cmdline、profile、symbol、trace 以及 heap、goroutine 等运行时profile
+//	{prefix}/vars           expvar 导出的变量
+//	{prefix}/pools          连接池的健康状态和统计信息，设置了连接池管理器时注册
+//
+// middleware 只应用于调试端点，通常传入认证中间件。端点会暴露进程的内部状态，
+// 只建议在开发环境或受保护的网络中启用
+func (s *HTTPServer) EnableDebug(prefix string, middleware ...Middleware) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+
+	get := func(path string, handler http.Handler) {
+		s.Get(prefix+path, wrapHTTPHandler(handler)).Middleware(middleware...)
+	}
+
+	get("/pprof", http.HandlerFunc(pprofIndex))
+	get("/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	get("/pprof/profile", http.HandlerFunc(pprof.Profile))
+	get("/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	get("/pprof/trace", http.HandlerFunc(pprof.Trace))
+	for _, name := range debugProfiles {
+		get("/pprof/"+name, pprof.Handler(name))
+	}
+	get("/vars", expvar.Handler())
+
+	// 设置了连接池管理器时同时提供连接池的状态和统计信息
+	if s.poolManager != nil {
+		s.Get(prefix+"/pools", s.poolHealthHandler).Middleware(middleware...)
+	}
+
+	// go tool pprof 通过POST请求解析符号
+	s.Post(prefix+"/pprof/symbol", wrapHTTPHandler(http.HandlerFunc(pprof.Symbol))).Middleware(middleware...)
+}
+
+// pprofIndex profile列表页面使用相对链接，需要以 / 结尾的地址访问
+func pprofIndex(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+		return
+	}
+	pprof.Index(w, r)
+}
+
+// wrapHTTPHandler 将标准库的 http.Handler 转换为处理函数，响应由 handler 直接写出
+func wrapHTTPHandler(handler http.Handler) HandlerFunc {
+	return func(ctx *Context) {
+		handler.ServeHTTP(ctx.Resp, ctx.Req)
+		ctx.unhandled = false
+	}
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_EnableDebug(t *testing.T) {
+	s := NewHTTPServer()
+	s.EnableDebug("/_debug/", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			if ctx.GetHeader("X-Debug-Token") != "secret" {
+				ctx.String(http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			next(ctx)
+		}
+	})
+	s.Get("/_debug/other", func(ctx *Context) {
+		ctx.String(http.StatusOK, "other")
+	})
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		noToken      bool
+		wantCode     int
+		wantContains string
+		wantLocation string
+	}{
+		{
+			name:         "index redirect",
+			method:       http.MethodGet,
+			path:         "/_debug/pprof",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/_debug/pprof/",
+		},
+		{
+			name:         "index",
+			method:       http.MethodGet,
+			path:         "/_debug/pprof/",
+			wantCode:     http.StatusOK,
+			wantContains: "goroutine",
+		},
+		{
+			name:         "named profile",
+			method:       http.MethodGet,
+			path:         "/_debug/pprof/goroutine?debug=1",
+			wantCode:     http.StatusOK,
+			wantContains: "goroutine profile",
+		},
+		{
+			name:     "symbol post",
+			method:   http.MethodPost,
+			path:     "/_debug/pprof/symbol",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:         "expvar",
+			method:       http.MethodGet,
+			path:         "/_debug/vars",
+			wantCode:     http.StatusOK,
+			wantContains: "\"memstats\"",
+		},
+		{
+			name:     "auth middleware",
+			method:   http.MethodGet,
+			path:     "/_debug/vars",
+			noToken:  true,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "other routes are not protected",
+			method:       http.MethodGet,
+			path:         "/_debug/other",
+			noToken:      true,
+			wantCode:     http.StatusOK,
+			wantContains: "other",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if !tc.noToken {
+				req.Header.Set("X-Debug-Token", "secret")
+			}
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantContains != "" {
+				assert.Contains(t, resp.Body.String(), tc.wantContains)
+			}
+			if tc.wantLocation != "" {
+				assert.Equal(t, tc.wantLocation, resp.Header().Get("Location"))
+			}
+		})
+	}
+}
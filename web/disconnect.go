@@ -0,0 +1,52 @@
+package web
+
+import "context"
+
+// StatusClientClosedRequest 客户端在响应写出前断开时记录的状态码，与 nginx 的约定相同
+const StatusClientClosedRequest = 499
+
+// Done 返回在客户端断开、请求超时或请求结束时关闭的通道，耗时的处理函数可以据此提前结束：
+//
+//	for _, item := range items {
+//		select {
+//		case <-ctx.Done():
+//			return
+//		default:
+//		}
+//		process(item)
+//	}
+func (c *Context) Done() <-chan struct{} {
+	if c.Context != nil {
+		return c.Context.Done()
+	}
+	if c.Req != nil {
+		return c.Req.Context().Done()
+	}
+	return nil
+}
+
+// IsClientGone 判断客户端是否已经断开，WithTimeout 设置的超时不会被视为断开
+func (c *Context) IsClientGone() bool {
+	ctx := c.clientCtx
+	if ctx == nil {
+		if c.Req == nil {
+			return false
+		}
+		ctx = c.Req.Context()
+	}
+	return ctx.Err() == context.Canceled
+}
+
+// clientGuard 客户端断开后不再进入处理链的下一层
+func clientGuard(h HandlerFunc) HandlerFunc {
+	return func(ctx *Context) {
+		if ctx.IsClientGone() {
+			ctx.Abort()
+			if ctx.RespStatusCode == 0 {
+				ctx.RespStatusCode = StatusClientClosedRequest
+			}
+			return
+		}
+		h(ctx)
+	}
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_ClientGone(t *testing.T) {
+	var steps []string
+	s := NewHTTPServer()
+	s.Use("GET", "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			steps = append(steps, "outer")
+			next(ctx)
+		}
+	})
+	s.Use("GET", "/slow", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			steps = append(steps, "inner")
+			next(ctx)
+		}
+	})
+	s.Get("/slow", func(ctx *Context) {
+		steps = append(steps, "handler")
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	testCases := []struct {
+		name       string
+		disconnect bool
+		wantSteps  []string
+		wantStatus int
+	}{
+		{name: "connected", wantSteps: []string{"outer", "inner", "handler"}, wantStatus: http.StatusOK},
+		{name: "disconnected", disconnect: true, wantSteps: nil, wantStatus: StatusClientClosedRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			steps = nil
+			reqCtx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.disconnect {
+				cancel()
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(reqCtx))
+			assert.Equal(t, tc.wantSteps, steps)
+			assert.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestContext_ClientGoneMidChain(t *testing.T) {
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handled := false
+	s := NewHTTPServer()
+	s.Use("GET", "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			cancel()
+			<-ctx.Done()
+			next(ctx)
+			assert.True(t, ctx.IsAborted())
+		}
+	})
+	s.Get("/", func(ctx *Context) {
+		handled = true
+	})
+
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(reqCtx))
+	assert.False(t, handled)
+}
+
+func TestContext_TimeoutIsNotClientGone(t *testing.T) {
+	var gone bool
+	s := NewHTTPServer()
+	s.Get("/", func(ctx *Context) {
+		cancel := ctx.WithTimeout(time.Millisecond)
+		cancel()
+		<-ctx.Done()
+		gone = ctx.IsClientGone()
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.False(t, gone)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
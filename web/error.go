@@ -0,0 +1,105 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// HTTPError 携带HTTP状态码的错误，处理函数返回或通过 ctx.Error 抛出后
+// 由错误处理器统一转换为响应
+type HTTPError struct {
+	Code    int    `json:"-" xml:"-" msgpack:"-"`                                                 // HTTP状态码
+	Message string `json:"error" xml:"error" msgpack:"error"`                                     // 返回给客户端的错误信息
+	Details any    `json:"details,omitempty" xml:"details,omitempty" msgpack:"details,omitempty"` // 附加信息，如字段校验错误
+	Err     error  `json:"-" xml:"-" msgpack:"-"`                                                 // 内部错误，只记录日志不返回给客户端
+}
+
+// NewHTTPError 创建HTTP错误，message为空时使用状态码对应的描述
+func NewHTTPError(code int, message string, details ...any) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	he := &HTTPError{Code: code, Message: message}
+	if len(details) == 1 {
+		he.Details = details[0]
+	} else if len(details) > 1 {
+		he.Details = details
+	}
+	return he
+}
+
+// Error 实现error接口
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("code=%d, message=%s, err=%v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+// Unwrap 返回内部错误
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WithInternal 设置内部错误
+func (e *HTTPError) WithInternal(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// ErrorHandler 定义错误处理器，负责将错误转换为响应
+type ErrorHandler func(ctx *Context, err error)
+
+// DefaultErrorHandler 默认的错误处理器
+// HTTPError按其状态码和信息返回，其余错误记录日志后返回500，不向客户端暴露内部信息；
+// 响应格式根据Accept头部协商，无法协商时使用JSON
+func DefaultErrorHandler(ctx *Context, err error) {
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		he = NewHTTPError(http.StatusInternalServerError, "").WithInternal(err)
+	}
+
+	if he.Code >= http.StatusInternalServerError {
+		ctx.Logger().Error("Request failed",
+			logger.FieldError(err),
+			logger.Int("status", he.Code))
+	}
+
+	// 渲染不带内部错误的副本，自定义的渲染器同样不会输出内部错误
+	public := &HTTPError{Code: he.Code, Message: he.Message, Details: he.Details}
+	if negErr := ctx.Negotiate(public.Code, public); negErr != nil {
+		ctx.JSON(public.Code, public)
+	}
+}
+
+// Error 使用服务器配置的错误处理器将错误转换为响应
+// 未配置时使用 DefaultErrorHandler
+func (c *Context) Error(err error) {
+	if err == nil {
+		return
+	}
+	if c.errorHandler != nil {
+		c.errorHandler(c, err)
+		return
+	}
+	DefaultErrorHandler(c, err)
+}
+
+// HandleError 将返回错误的处理函数转换为普通处理函数，返回的错误交给 ctx.Error 处理
+func HandleError(fn ErrorHandleFunc) HandlerFunc {
+	return func(ctx *Context) {
+		if err := fn(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// renderHTTPErrorHTML 渲染HTTPError的HTML页面
+func renderHTTPErrorHTML(he *HTTPError) []byte {
+	return []byte(fmt.Sprintf("<!DOCTYPE html>\n<html><head><title>%d %s</title></head><body><h1>%d %s</h1><p>%s</p></body></html>",
+		he.Code, http.StatusText(he.Code), he.Code, http.StatusText(he.Code), html.EscapeString(he.Message)))
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var errUserNotFound = NewHTTPError(http.StatusNotFound, "user not found")
+
+func TestDefaultErrorHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accept   string
+		err      error
+		wantCode int
+		wantType string
+		wantBody string
+	}{
+		{
+			name:     "http error",
+			err:      NewHTTPError(http.StatusBadRequest, "invalid name", map[string]string{"name": "required"}),
+			wantCode: http.StatusBadRequest,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"error\":\"invalid name\",\"details\":{\"name\":\"required\"}}\n",
+		},
+		{
+			name:     "wrapped http error",
+			err:      fmt.Errorf("load user: %w", errUserNotFound),
+			wantCode: http.StatusNotFound,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"error\":\"user not found\"}\n",
+		},
+		{
+			name:     "plain error hides internals",
+			err:      errors.New("dial tcp: connection refused"),
+			wantCode: http.StatusInternalServerError,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"error\":\"Internal Server Error\"}\n",
+		},
+		{
+			name:     "html",
+			accept:   "text/html",
+			err:      NewHTTPError(http.StatusForbidden, "<no>"),
+			wantCode: http.StatusForbidden,
+			wantType: ContentTypeHTML,
+			wantBody: "<!DOCTYPE html>\n<html><head><title>403 Forbidden</title></head><body><h1>403 Forbidden</h1><p>&lt;no&gt;</p></body></html>",
+		},
+		{
+			name:     "plain text",
+			accept:   "text/plain",
+			err:      errUserNotFound,
+			wantCode: http.StatusNotFound,
+			wantType: ContentTypePlain,
+			wantBody: "user not found",
+		},
+		{
+			name:     "not acceptable falls back to json",
+			accept:   "image/png",
+			err:      errUserNotFound,
+			wantCode: http.StatusNotFound,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"error\":\"user not found\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewHTTPServer()
+			s.Get("/", HandleError(func(ctx *Context) error {
+				return tc.err
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantType, resp.Header().Get("Content-Type"))
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	var handled error
+	s := NewHTTPServer(WithErrorHandler(func(ctx *Context, err error) {
+		handled = err
+		ctx.String(http.StatusTeapot, "custom")
+	}))
+	s.Get("/", func(ctx *Context) {
+		ctx.Error(errUserNotFound)
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, errUserNotFound, handled)
+	assert.Equal(t, http.StatusTeapot, resp.Code)
+	assert.Equal(t, "custom", resp.Body.String())
+}
+
+func TestDefaultErrorHandler_HidesInternalError(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/fail", HandleError(func(ctx *Context) error {
+		return NewHTTPError(http.StatusBadRequest, "bad input").WithInternal(errors.New("secret dsn"))
+	}))
+	s.Get("/crash", HandleError(func(ctx *Context) error {
+		return errors.New("secret stack")
+	}))
+
+	for _, accept := range []string{ContentTypeMsgpack, "application/x-msgpack", ContentTypeJSON, ContentTypeXML, ContentTypePlain, "text/html"} {
+		for _, path := range []string{"/fail", "/crash"} {
+			t.Run(accept+path, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("Accept", accept)
+				resp := httptest.NewRecorder()
+				s.ServeHTTP(resp, req)
+
+				assert.NotContains(t, resp.Body.String(), "secret")
+			})
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("Accept", ContentTypeMsgpack)
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	var body map[string]any
+	assert.NoError(t, msgpack.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{"error": "bad input"}, body)
+}
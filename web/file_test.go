@@ -234,9 +234,10 @@ func TestFileDownloader(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			ctx := &Context{
-				Req:   req,
-				Resp:  recorder,
-				Param: map[string]string{"file": tt.filePath},
+				Req:         req,
+				Resp:        recorder,
+				paramNames:  []string{"file"},
+				paramValues: []string{tt.filePath},
 			}
 
 			downloader := FileDownloader{
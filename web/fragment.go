@@ -0,0 +1,111 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTMX 请求头
+const (
+	HeaderHXRequest = "HX-Request"
+	HeaderHXBoosted = "HX-Boosted"
+	HeaderHXTarget  = "HX-Target"
+	HeaderHXTrigger = "HX-Trigger"
+)
+
+// FragmentRenderer 支持只渲染模板中某个命名块的模板引擎
+// GoTemplate 实现了该接口，自定义模板引擎可以按需实现
+type FragmentRenderer interface {
+	RenderFragment(ctx *Context, tplName, block string, data any) ([]byte, error)
+}
+
+// RenderFragment 只渲染模板中通过 {{define}} 或 {{block}} 定义的命名块
+func (g *GoTemplate) RenderFragment(ctx *Context, tplName, block string, data any) ([]byte, error) {
+	g.RLock()
+	defer g.RUnlock()
+
+	if g.tpl == nil {
+		return nil, errors.New("template not initialized")
+	}
+
+	tmpl := g.lookupSet(tplName).Lookup(tplName)
+	if tmpl == nil {
+		return nil, fmt.Errorf("template %s not found", tplName)
+	}
+
+	fragment := tmpl.Lookup(block)
+	if fragment == nil {
+		return nil, fmt.Errorf("block %s not found in template %s", block, tplName)
+	}
+
+	g.prepareViewData(ctx, data)
+
+	buf := &bytes.Buffer{}
+	if err := fragment.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute block %s: %w", block, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderFragment 只渲染模板中的命名块，用于HTMX/Turbo等局部更新场景
+func (c *Context) RenderFragment(tplName, block string, data any) error {
+	result, err := c.renderFragment(tplName, block, data)
+	if err != nil {
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", ContentTypeHTML)
+	c.RespData = result
+	c.RespStatusCode = http.StatusOK
+	return nil
+}
+
+// RenderHTMX HTMX请求只渲染命名块，普通请求和hx-boost请求渲染完整页面
+// 同一个URL会根据请求头返回不同内容，因此会设置 Vary: HX-Request
+func (c *Context) RenderHTMX(tplName, block string, data any) error {
+	c.Resp.Header().Add("Vary", HeaderHXRequest)
+	if c.IsHTMX() && !c.IsHTMXBoosted() {
+		return c.RenderFragment(tplName, block, data)
+	}
+	return c.Template(tplName, data)
+}
+
+// renderFragment 使用模板引擎渲染命名块
+func (c *Context) renderFragment(tplName, block string, data any) ([]byte, error) {
+	if c.tplEngine == nil {
+		return nil, errors.New("template engine not set")
+	}
+
+	fr, ok := c.tplEngine.(FragmentRenderer)
+	if !ok {
+		return nil, fmt.Errorf("template engine %T does not support fragments", c.tplEngine)
+	}
+
+	result, err := fr.RenderFragment(c, tplName, block, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render fragment: %w", err)
+	}
+	return result, nil
+}
+
+// IsHTMX 判断是否为HTMX发起的请求
+func (c *Context) IsHTMX() bool {
+	return c.GetHeader(HeaderHXRequest) == "true"
+}
+
+// IsHTMXBoosted 判断是否为hx-boost发起的请求，这类请求需要完整页面
+func (c *Context) IsHTMXBoosted() bool {
+	return c.GetHeader(HeaderHXBoosted) == "true"
+}
+
+// HTMXTarget 返回HTMX请求的目标元素ID
+func (c *Context) HTMXTarget() string {
+	return c.GetHeader(HeaderHXTarget)
+}
+
+// HTMXTrigger 返回触发HTMX请求的元素ID
+func (c *Context) HTMXTrigger() string {
+	return c.GetHeader(HeaderHXTrigger)
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFragmentTemplate(t *testing.T) *GoTemplate {
+	tplPath := filepath.Join(t.TempDir(), "contacts.html")
+	content := `<html><body><h1>Contacts</h1>{{block "list" .}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}</body></html>`
+	require.NoError(t, os.WriteFile(tplPath, []byte(content), 0666))
+	return NewGoTemplate(WithFiles(tplPath))
+}
+
+func TestGoTemplate_RenderFragment(t *testing.T) {
+	tpl := newFragmentTemplate(t)
+
+	result, err := tpl.RenderFragment(&Context{}, "contacts.html", "list", []string{"tom", "jerry"})
+	require.NoError(t, err)
+	assert.Equal(t, "<ul><li>tom</li><li>jerry</li></ul>", string(result))
+
+	_, err = tpl.RenderFragment(&Context{}, "contacts.html", "missing", nil)
+	assert.Error(t, err)
+
+	_, err = tpl.RenderFragment(&Context{}, "missing.html", "list", nil)
+	assert.Error(t, err)
+}
+
+func TestContextRenderHTMX(t *testing.T) {
+	s := NewHTTPServer(WithTemplate(newFragmentTemplate(t)))
+	s.Get("/contacts", func(ctx *Context) {
+		ctx.RenderHTMX("contacts.html", "list", []string{"tom"})
+	})
+
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		wantBody string
+	}{
+		{
+			name:     "full page",
+			wantBody: "<html><body><h1>Contacts</h1><ul><li>tom</li></ul></body></html>",
+		},
+		{
+			name:     "htmx request",
+			headers:  map[string]string{HeaderHXRequest: "true"},
+			wantBody: "<ul><li>tom</li></ul>",
+		},
+		{
+			name:     "boosted request",
+			headers:  map[string]string{HeaderHXRequest: "true", HeaderHXBoosted: "true"},
+			wantBody: "<html><body><h1>Contacts</h1><ul><li>tom</li></ul></body></html>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, ContentTypeHTML, resp.Header().Get("Content-Type"))
+			assert.Equal(t, HeaderHXRequest, resp.Header().Get("Vary"))
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestNegotiateViewBlock(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html")
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder(), tplEngine: newFragmentTemplate(t)}
+
+	err := ctx.Negotiate(http.StatusOK, View{Name: "contacts.html", Block: "list", Data: []string{"tom"}})
+	require.NoError(t, err)
+	assert.Equal(t, "<ul><li>tom</li></ul>", string(ctx.RespData))
+}
@@ -11,12 +11,22 @@ type RouteGroup interface {
     Delete(path string, handler HandlerFunc) RouteRegister
     Patch(path string, handler HandlerFunc) RouteRegister
     Options(path string, handler HandlerFunc) RouteRegister
+    Head(path string, handler HandlerFunc) RouteRegister
     
     // Group 嵌套组
     Group(prefix string) RouteGroup
     
     // Use 组级中间件
     Use(middleware ...Middleware) RouteGroup
+
+    // NotFound 组级404处理器，组内路径未匹配到路由时使用
+    NotFound(handler HandlerFunc) RouteGroup
+
+    // OnError 组级错误处理器，组内路由调用 ctx.Error 时使用
+    OnError(handler ErrorHandler) RouteGroup
+
+    // Mount 将模块挂载到组内的 prefix 下
+    Mount(prefix string, m *Module) RouteGroup
 }
 
 // routeGroup 实现 RouteGroup 接口，代表一个路由分组
@@ -110,6 +120,13 @@ func (g *routeGroup) Options(relativePath string, handler HandlerFunc) RouteRegi
     return newRouteRegister(g.server, "OPTIONS", fullPath)
 }
 
+// Head 注册 HEAD 路由方法
+func (g *routeGroup) Head(relativePath string, handler HandlerFunc) RouteRegister {
+    fullPath := g.normalizePath(relativePath)
+    g.server.Router.Head(fullPath, handler)
+    return newRouteRegister(g.server, "HEAD", fullPath)
+}
+
 // Group 创建嵌套路由组
 func (g *routeGroup) Group(relativePath string) RouteGroup {
     return newRouteGroup(g.server, g.normalizePath(relativePath))
@@ -126,6 +143,26 @@ func (g *routeGroup) Use(middleware ...Middleware) RouteGroup {
         g.server.Use("DELETE", g.basePath+"/*", m)
         g.server.Use("PATCH", g.basePath+"/*", m)
         g.server.Use("OPTIONS", g.basePath+"/*", m)
+        g.server.Use("HEAD", g.basePath+"/*", m)
     }
     return g
-}
\ No newline at end of file
+}
+// NotFound 为路由组设置404处理器
+// 嵌套路由组的处理器优先于外层路由组，未设置时使用全局的404处理器
+func (g *routeGroup) NotFound(handler HandlerFunc) RouteGroup {
+    g.server.groupHandlersFor(g.basePath).notFound = handler
+    return g
+}
+
+// OnError 为路由组设置错误处理器
+// 嵌套路由组的处理器优先于外层路由组，未设置时使用全局的错误处理器
+func (g *routeGroup) OnError(handler ErrorHandler) RouteGroup {
+    g.server.groupHandlersFor(g.basePath).onError = handler
+    return g
+}
+
+// Mount 将模块挂载到组内的 prefix 下，模块同时使用路由组的中间件
+func (g *routeGroup) Mount(prefix string, m *Module) RouteGroup {
+    g.server.Mount(g.normalizePath(prefix), m)
+    return g
+}
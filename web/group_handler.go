@@ -0,0 +1,75 @@
+package web
+
+import (
+	"sort"
+	"strings"
+)
+
+// groupHandlers 路由组的404和错误处理器，以及挂载模块的模板引擎
+type groupHandlers struct {
+	prefix    string
+	notFound  HandlerFunc
+	onError   ErrorHandler
+	tplEngine TemplateEngine
+}
+
+// matches 判断请求路径是否属于路由组
+func (h *groupHandlers) matches(path string) bool {
+	return h.prefix == "/" || path == h.prefix || strings.HasPrefix(path, h.prefix+"/")
+}
+
+// groupHandlersFor 返回路由组的处理器配置，不存在时创建
+// 配置按前缀长度倒序保存，查找时最长匹配的路由组优先
+func (s *HTTPServer) groupHandlersFor(prefix string) *groupHandlers {
+	if prefix == "" {
+		prefix = "/"
+	}
+	for _, h := range s.groupHandlers {
+		if h.prefix == prefix {
+			return h
+		}
+	}
+
+	h := &groupHandlers{prefix: prefix}
+	s.groupHandlers = append(s.groupHandlers, h)
+	sort.SliceStable(s.groupHandlers, func(i, j int) bool {
+		return len(s.groupHandlers[i].prefix) > len(s.groupHandlers[j].prefix)
+	})
+	return h
+}
+
+// notFoundHandler 返回路径对应的404处理器，没有路由组配置时使用全局处理器
+func (s *HTTPServer) notFoundHandler(path string) HandlerFunc {
+	for _, h := range s.groupHandlers {
+		if h.notFound != nil && h.matches(path) {
+			return h.notFound
+		}
+	}
+	return s.noRouter
+}
+
+// errorHandlerFor 返回路径对应的错误处理器，没有路由组配置时使用全局处理器
+func (s *HTTPServer) errorHandlerFor(path string) ErrorHandler {
+	for _, h := range s.groupHandlers {
+		if h.onError != nil && h.matches(path) {
+			return h.onError
+		}
+	}
+	return s.errHandler
+}
+
+// templateFor 返回路径对应的模板引擎，没有模块设置时使用服务器的模板引擎
+func (s *HTTPServer) templateFor(path string) TemplateEngine {
+	for _, h := range s.groupHandlers {
+		if h.tplEngine != nil && h.matches(path) {
+			return h.tplEngine
+		}
+	}
+	return s.tplEngine
+}
+
+// OnError 为路由设置错误处理器，优先于路由组和全局的错误处理器
+func (r *routeRegister) OnError(handler ErrorHandler) RouteRegister {
+	r.server.routeErrHandlers[r.method+" "+r.path] = handler
+	return r
+}
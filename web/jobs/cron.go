@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 周期任务的调度规则
+type Schedule interface {
+	// Next 返回 t 之后的下一次执行时间，不会再执行时返回零值
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule 解析调度规则，支持标准的五段 cron 表达式（分 时 日 月 周）和以下写法：
+//
+//	@every 5m      每隔固定时间执行
+//	@hourly        每小时整点执行，等同于 0 * * * *
+//	@daily         每天零点执行，也可以写成 @midnight
+//	@weekly        每周日零点执行
+//	@monthly       每月1日零点执行
+//	@yearly        每年1月1日零点执行，也可以写成 @annually
+//
+// 每一段支持 *、列表 1,15、范围 1-5 和步长 */10、0-30/5，周的取值为 0-7，0 和 7 都表示周日
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("jobs: invalid schedule %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("jobs: invalid schedule %q: interval must be positive", spec)
+		}
+		return Every(d), nil
+	}
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("jobs: invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	s := &cronSchedule{}
+	var err error
+	for i, f := range []struct {
+		set      *bitSet
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	} {
+		if *f.set, err = parseField(fields[i], f.min, f.max); err != nil {
+			return nil, fmt.Errorf("jobs: invalid schedule %q: %w", spec, err)
+		}
+	}
+	// 7 和 0 都表示周日
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return s, nil
+}
+
+// cronDescriptors 预定义的调度规则
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// bitSet 每一位表示一个允许的取值
+type bitSet uint64
+
+func (b bitSet) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// parseField 解析 cron 表达式中的一段
+func parseField(field string, min, max int) (bitSet, error) {
+	var set bitSet
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			lo = n
+			// 5/15 表示从5开始每15执行一次
+			hi = n
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// cronSchedule cron 表达式表示的调度规则
+type cronSchedule struct {
+	minute, hour, dom, month, dow bitSet
+
+	// 日和周都不是 * 时，满足其中一个即可执行，与标准 cron 的行为一致
+	domStar, dowStar bool
+}
+
+// Next 逐级查找满足条件的月、日、时、分，最多向后查找5年
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom.has(t.Day())
+	dow := s.dow.has(int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Every 返回每隔 d 执行一次的调度规则，从注册时开始计时
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// 2025-03-14 是周五
+	from := time.Date(2025, 3, 14, 10, 17, 30, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+			want: time.Date(2025, 3, 14, 10, 18, 0, 0, time.UTC),
+		},
+		{
+			name: "step",
+			spec: "*/15 * * * *",
+			want: time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "list and range",
+			spec: "0 9-10,14 * * *",
+			want: time.Date(2025, 3, 14, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "next day",
+			spec: "30 2 * * *",
+			want: time.Date(2025, 3, 15, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "day of week",
+			spec: "0 0 * * 1",
+			want: time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 * * 7",
+			want: time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			spec: "0 0 20 * 6",
+			want: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "month wraps year",
+			spec: "0 0 1 2 *",
+			want: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap day",
+			spec: "0 0 29 2 *",
+			want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "descriptor",
+			spec: "@hourly",
+			want: time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "every",
+			spec: "@every 90s",
+			want: time.Date(2025, 3, 14, 10, 19, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, s.Next(from))
+		})
+	}
+}
+
+func TestParseSchedule_Never(t *testing.T) {
+	s, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+		"@every -1s",
+		"@sometimes",
+	} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,338 @@
+// Package jobs 提供与服务器生命周期绑定的后台任务：按 cron 表达式执行的周期任务和只执行一次的任务。
+//
+//	scheduler := jobs.New(jobs.WithDB(db))
+//	scheduler.Cron("cleanup-sessions", "*/10 * * * *", func(ctx *jobs.Context) error {
+//		_, err := orm.RegisterDeleter[Session](ctx.DB).Where(...).Exec(ctx)
+//		return err
+//	})
+//	scheduler.Attach(server)
+//
+// 任务随服务器启动，服务器关闭时停止调度新的任务，并等待正在执行的任务完成
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// ErrStopped 调度器已经关闭，不再接受新的任务
+var ErrStopped = errors.New("jobs: scheduler is stopped")
+
+// Context 任务执行时的上下文，提供任务共享的日志记录器、数据库和连接池
+// 服务器关闭时等待任务完成，超过 Shutdown 的期限后上下文会被取消
+type Context struct {
+	context.Context
+	// Name 任务名称
+	Name string
+	// Logger 带有任务名称字段的日志记录器
+	Logger logger.Logger
+	// DB 通过 WithDB 设置的数据库，未设置时为 nil
+	DB *orm.DB
+	// Pools 通过 WithPoolManager 或 Attach 设置的连接池管理器，未设置时为 nil
+	Pools pool.PoolManager
+}
+
+// Func 任务函数，返回的错误会被记录到日志
+type Func func(ctx *Context) error
+
+// Option 调度器的配置选项
+type Option func(*Scheduler)
+
+// WithLogger 设置日志记录器，默认使用 Attach 的服务器的日志记录器，没有 Attach 时使用默认的日志记录器
+func WithLogger(log logger.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = log
+	}
+}
+
+// WithDB 设置任务使用的数据库
+func WithDB(db *orm.DB) Option {
+	return func(s *Scheduler) {
+		s.db = db
+	}
+}
+
+// WithPoolManager 设置任务使用的连接池管理器，默认使用 Attach 的服务器的连接池管理器
+func WithPoolManager(manager pool.PoolManager) Option {
+	return func(s *Scheduler) {
+		s.pools = manager
+	}
+}
+
+// WithLocation 设置 cron 表达式使用的时区，默认为 time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = loc
+	}
+}
+
+// JobOption 单个任务的配置选项
+type JobOption func(*job)
+
+// WithTimeout 设置每次执行的超时时间，超时后任务的上下文被取消
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+// AllowOverlap 允许周期任务在上一次执行还没有结束时再次执行，默认跳过这一次执行
+func AllowOverlap() JobOption {
+	return func(j *job) {
+		j.overlap = true
+	}
+}
+
+// job 已注册的任务
+type job struct {
+	name     string
+	schedule Schedule // 一次性任务为 nil
+	delay    time.Duration
+	fn       Func
+	timeout  time.Duration
+	overlap  bool
+	running  atomic.Bool
+}
+
+// Scheduler 后台任务调度器
+type Scheduler struct {
+	logger   logger.Logger
+	db       *orm.DB
+	pools    pool.PoolManager
+	location *time.Location
+
+	mu      sync.Mutex
+	jobs    []*job // 未启动时注册的任务，启动时开始调度
+	started bool
+	stopped bool
+
+	// ctx 任务的上下文，等待任务完成超时后取消
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}  // 关闭后不再调度新的任务
+	loops  sync.WaitGroup // 调度协程
+	active sync.WaitGroup // 正在执行的任务
+}
+
+// New 创建调度器，需要调用 Start 或通过 Attach 随服务器启动
+func New(opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		location: time.Local,
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Attach 让调度器随服务器启动和关闭，未设置日志记录器和连接池管理器时使用服务器的
+func (s *Scheduler) Attach(server *web.HTTPServer) {
+	if s.logger == nil {
+		s.logger = server.Logger()
+	}
+	if s.pools == nil {
+		s.pools = server.PoolManager()
+	}
+	server.OnStart(s.Start)
+	server.OnShutdown(s.Shutdown)
+}
+
+// Cron 注册按 spec 执行的周期任务，spec 的格式参见 ParseSchedule
+func (s *Scheduler) Cron(name, spec string, fn Func, opts ...JobOption) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	return s.Schedule(name, schedule, fn, opts...)
+}
+
+// Schedule 注册按 schedule 执行的周期任务
+func (s *Scheduler) Schedule(name string, schedule Schedule, fn Func, opts ...JobOption) error {
+	return s.add(&job{name: name, schedule: schedule, fn: fn}, opts)
+}
+
+// Once 注册只执行一次的任务，调度器启动 delay 之后执行，调度器已经启动时从注册时开始计时
+func (s *Scheduler) Once(name string, delay time.Duration, fn Func, opts ...JobOption) error {
+	return s.add(&job{name: name, delay: delay, fn: fn}, opts)
+}
+
+func (s *Scheduler) add(j *job, opts []JobOption) error {
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	if s.started {
+		s.launch(j)
+		return nil
+	}
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+// Start 开始调度已注册的任务，之后注册的任务会立即开始调度
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	if s.started {
+		return nil
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		s.launch(j)
+	}
+	s.jobs = nil
+	s.log().Info("Background jobs started")
+	return nil
+}
+
+// Shutdown 停止调度新的任务，等待正在执行的任务完成。
+// ctx 结束时取消仍在执行的任务的上下文，等待它们返回后返回 ctx 的错误
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.loops.Wait()
+	done := make(chan struct{})
+	go func() {
+		s.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		s.log().Info("Background jobs stopped")
+		return nil
+	case <-ctx.Done():
+		s.log().Warn("Cancelling background jobs still running at shutdown")
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// launch 启动任务的调度协程，调用时需要持有锁
+func (s *Scheduler) launch(j *job) {
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		if j.schedule == nil {
+			s.wait(j.delay)
+			s.dispatch(j)
+			return
+		}
+		for {
+			now := time.Now().In(s.location)
+			next := j.schedule.Next(now)
+			if next.IsZero() {
+				s.log().Warn("Job schedule has no next run", logger.String("job", j.name))
+				return
+			}
+			if !s.wait(next.Sub(now)) {
+				return
+			}
+			s.dispatch(j)
+		}
+	}()
+}
+
+// wait 等待 d，调度器关闭时返回 false
+func (s *Scheduler) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+// dispatch 在新的协程中执行任务，上一次执行还没有结束时跳过这一次
+func (s *Scheduler) dispatch(j *job) {
+	if !j.overlap && !j.running.CompareAndSwap(false, true) {
+		s.log().Warn("Skipping job, previous run still in progress", logger.String("job", j.name))
+		return
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		j.running.Store(false)
+		return
+	}
+	s.active.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.active.Done()
+		if !j.overlap {
+			defer j.running.Store(false)
+		}
+		s.run(j)
+	}()
+}
+
+// run 执行任务并记录结果，任务中的 panic 会被恢复并记录为错误
+func (s *Scheduler) run(j *job) {
+	ctx := s.ctx
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+	log := s.log().WithField("job", j.name)
+	jc := &Context{Context: ctx, Name: j.name, Logger: log, DB: s.db, Pools: s.pools}
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v\n%s", p, debug.Stack())
+			}
+		}()
+		return j.fn(jc)
+	}()
+
+	elapsed := logger.Int64("duration_ms", time.Since(start).Milliseconds())
+	if err != nil {
+		log.Error("Job failed", elapsed, logger.FieldError(err))
+		return
+	}
+	log.Debug("Job completed", elapsed)
+}
+
+func (s *Scheduler) log() logger.Logger {
+	if s.logger == nil {
+		return logger.GetDefaultLogger()
+	}
+	return s.logger
+}
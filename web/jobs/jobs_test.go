@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_RunsJobs(t *testing.T) {
+	s := New()
+	var recurring, once atomic.Int32
+	require.NoError(t, s.Schedule("recurring", Every(10*time.Millisecond), func(ctx *Context) error {
+		recurring.Add(1)
+		return nil
+	}))
+	require.NoError(t, s.Once("once", 0, func(ctx *Context) error {
+		assert.Equal(t, "once", ctx.Name)
+		once.Add(1)
+		return nil
+	}))
+
+	// 启动之前不会执行
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(0), recurring.Load())
+
+	require.NoError(t, s.Start(context.Background()))
+	assert.Eventually(t, func() bool { return recurring.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), once.Load())
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	n := recurring.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, recurring.Load())
+	assert.ErrorIs(t, s.Once("late", 0, func(ctx *Context) error { return nil }), ErrStopped)
+}
+
+func TestScheduler_SkipOverlap(t *testing.T) {
+	s := New()
+	var runs atomic.Int32
+	release := make(chan struct{})
+	require.NoError(t, s.Schedule("slow", Every(5*time.Millisecond), func(ctx *Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	}))
+	require.NoError(t, s.Start(context.Background()))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+	close(release)
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestScheduler_ShutdownDrains(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	var finished atomic.Bool
+	require.NoError(t, s.Once("drain", 0, func(ctx *Context) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	<-started
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.True(t, finished.Load())
+}
+
+func TestScheduler_ShutdownTimeout(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	require.NoError(t, s.Once("stuck", 0, func(ctx *Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+	assert.True(t, cancelled.Load())
+}
+
+func TestScheduler_Panic(t *testing.T) {
+	s := New()
+	var after atomic.Bool
+	require.NoError(t, s.Once("panic", 0, func(ctx *Context) error {
+		panic("boom")
+	}))
+	require.NoError(t, s.Once("after", 10*time.Millisecond, func(ctx *Context) error {
+		after.Store(true)
+		return nil
+	}))
+	require.NoError(t, s.Start(context.Background()))
+	assert.Eventually(t, after.Load, time.Second, 5*time.Millisecond)
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	s := New()
+	result := make(chan error, 1)
+	require.NoError(t, s.Once("timeout", 0, func(ctx *Context) error {
+		<-ctx.Done()
+		result <- ctx.Err()
+		return nil
+	}, WithTimeout(10*time.Millisecond)))
+	require.NoError(t, s.Start(context.Background()))
+	assert.True(t, errors.Is(<-result, context.DeadlineExceeded))
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestScheduler_Attach(t *testing.T) {
+	server := web.NewHTTPServer()
+	s := New()
+	ran := make(chan struct{})
+	require.NoError(t, s.Once("attached", 0, func(ctx *Context) error {
+		assert.NotNil(t, ctx.Logger)
+		close(ran)
+		return nil
+	}))
+	s.Attach(server)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	go func() {
+		_ = server.Start(addr)
+	}()
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run after server start")
+	}
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	assert.ErrorIs(t, s.Once("late", 0, func(ctx *Context) error { return nil }), ErrStopped)
+}
@@ -0,0 +1,355 @@
+// Package listing 为列表接口提供统一的分页、过滤和排序查询协议
+//
+// 支持的查询参数：
+//
+//	?page=2&per_page=20              分页，page从1开始
+//	?sort=-created_at,name           排序，字段前加 - 表示降序
+//	?filter[name]=tom                等值过滤
+//	?filter[age][gte]=18             指定操作符的过滤
+//	?filter[status][in]=paid,shipped 多值过滤
+//
+// 只有通过 WithSort 和 WithFilter 加入白名单的参数才会被接受，参数名映射到ORM模型的字段名
+package listing
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+)
+
+// Op 过滤操作符
+type Op string
+
+const (
+	OpEq       Op = "eq"
+	OpNe       Op = "ne"
+	OpGt       Op = "gt"
+	OpGte      Op = "gte"
+	OpLt       Op = "lt"
+	OpLte      Op = "lte"
+	OpContains Op = "contains"
+	OpIn       Op = "in"
+)
+
+const (
+	defaultPerPage = 20
+	defaultMaxPage = 100
+)
+
+// Sort 排序条件
+type Sort struct {
+	Param string // 查询参数中的名称
+	Field string // ORM模型的字段名
+	Desc  bool
+}
+
+// Filter 过滤条件
+type Filter struct {
+	Param  string   // 查询参数中的名称
+	Field  string   // ORM模型的字段名
+	Op     Op       // 操作符
+	Values []string // OpIn 时为多个值，其余操作符只有一个值
+}
+
+// Spec 解析后的列表查询规格
+type Spec struct {
+	Page    int
+	PerPage int
+	Sorts   []Sort
+	Filters []Filter
+}
+
+// Offset 返回当前页的偏移量
+func (s *Spec) Offset() int {
+	return (s.Page - 1) * s.PerPage
+}
+
+// Meta 分页元数据，可以直接放入响应体
+type Meta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// Meta 根据总数生成分页元数据
+func (s *Spec) Meta(total int64) Meta {
+	pages := 0
+	if s.PerPage > 0 {
+		pages = int((total + int64(s.PerPage) - 1) / int64(s.PerPage))
+	}
+	return Meta{
+		Page:       s.Page,
+		PerPage:    s.PerPage,
+		Total:      total,
+		TotalPages: pages,
+	}
+}
+
+// filterRule 可过滤参数的白名单规则
+type filterRule struct {
+	field string
+	ops   map[Op]bool
+}
+
+// Listing 一类列表接口的查询协议定义，通常在包级别创建后复用
+type Listing struct {
+	perPage     int
+	maxPerPage  int
+	defaultSort string
+	sorts       map[string]string
+	filters     map[string]filterRule
+}
+
+// Option Listing配置选项
+type Option func(*Listing)
+
+// WithPerPage 设置默认每页数量和每页数量上限
+func WithPerPage(def, max int) Option {
+	return func(l *Listing) {
+		l.perPage = def
+		l.maxPerPage = max
+	}
+}
+
+// WithSort 允许按参数排序，field为ORM模型的字段名
+func WithSort(param, field string) Option {
+	return func(l *Listing) {
+		l.sorts[param] = field
+	}
+}
+
+// WithDefaultSort 未指定sort参数时使用的排序，格式与sort参数相同
+func WithDefaultSort(sort string) Option {
+	return func(l *Listing) {
+		l.defaultSort = sort
+	}
+}
+
+// WithFilter 允许按参数过滤，field为ORM模型的字段名，ops为空时只允许等值过滤
+func WithFilter(param, field string, ops ...Op) Option {
+	return func(l *Listing) {
+		if len(ops) == 0 {
+			ops = []Op{OpEq}
+		}
+		rule := filterRule{field: field, ops: make(map[Op]bool, len(ops))}
+		for _, op := range ops {
+			rule.ops[op] = true
+		}
+		l.filters[param] = rule
+	}
+}
+
+// New 创建列表查询协议
+func New(opts ...Option) *Listing {
+	l := &Listing{
+		perPage:    defaultPerPage,
+		maxPerPage: defaultMaxPage,
+		sorts:      make(map[string]string),
+		filters:    make(map[string]filterRule),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Parse 从请求的查询参数中解析列表规格，参数不合法时返回400的 *web.HTTPError
+func (l *Listing) Parse(ctx *web.Context) (*Spec, error) {
+	return l.ParseValues(ctx.QueryAll())
+}
+
+// ParseValues 从查询参数中解析列表规格
+func (l *Listing) ParseValues(values url.Values) (*Spec, error) {
+	spec := &Spec{Page: 1, PerPage: l.perPage}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, badRequest("page", "must be a positive integer")
+		}
+		spec.Page = page
+	}
+
+	if v := values.Get("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil || perPage < 1 {
+			return nil, badRequest("per_page", "must be a positive integer")
+		}
+		if l.maxPerPage > 0 && perPage > l.maxPerPage {
+			return nil, badRequest("per_page", fmt.Sprintf("must not exceed %d", l.maxPerPage))
+		}
+		spec.PerPage = perPage
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = l.defaultSort
+	}
+	sorts, err := l.parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	spec.Sorts = sorts
+
+	filters, err := l.parseFilters(values)
+	if err != nil {
+		return nil, err
+	}
+	spec.Filters = filters
+
+	return spec, nil
+}
+
+// parseSort 解析 sort=-created_at,name 格式的排序参数
+func (l *Listing) parseSort(sort string) ([]Sort, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	var sorts []Sort
+	for _, item := range strings.Split(sort, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		desc := strings.HasPrefix(item, "-")
+		param := strings.TrimPrefix(item, "-")
+		field, ok := l.sorts[param]
+		if !ok {
+			return nil, badRequest("sort", fmt.Sprintf("cannot sort by %q", param))
+		}
+		sorts = append(sorts, Sort{Param: param, Field: field, Desc: desc})
+	}
+	return sorts, nil
+}
+
+// parseFilters 解析 filter[name]=foo 和 filter[name][op]=foo 格式的过滤参数
+// 过滤条件按参数名排序，保证生成的SQL稳定
+func (l *Listing) parseFilters(values url.Values) ([]Filter, error) {
+	var filters []Filter
+	for _, key := range sortedKeys(values) {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+
+		param, op, ok := parseFilterKey(key)
+		if !ok {
+			return nil, badRequest(key, "malformed filter")
+		}
+
+		rule, ok := l.filters[param]
+		if !ok {
+			return nil, badRequest(key, fmt.Sprintf("cannot filter by %q", param))
+		}
+		if !rule.ops[op] {
+			return nil, badRequest(key, fmt.Sprintf("operator %q is not allowed", op))
+		}
+
+		value := values.Get(key)
+		filter := Filter{Param: param, Field: rule.field, Op: op, Values: []string{value}}
+		if op == OpIn {
+			filter.Values = strings.Split(value, ",")
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// parseFilterKey 解析 filter[name] 或 filter[name][op]
+func parseFilterKey(key string) (string, Op, bool) {
+	rest := strings.TrimPrefix(key, "filter[")
+	end := strings.IndexByte(rest, ']')
+	if end <= 0 {
+		return "", "", false
+	}
+	param, rest := rest[:end], rest[end+1:]
+	if rest == "" {
+		return param, OpEq, true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") || len(rest) < 3 {
+		return "", "", false
+	}
+	return param, Op(rest[1 : len(rest)-1]), true
+}
+
+// Conditions 将过滤条件转换为ORM查询条件
+func (s *Spec) Conditions() []orm.Condition {
+	conds := make([]orm.Condition, 0, len(s.Filters))
+	for _, f := range s.Filters {
+		col := orm.Col(f.Field)
+		switch f.Op {
+		case OpNe:
+			conds = append(conds, orm.NOT(col.Eq(f.Values[0])))
+		case OpGt:
+			conds = append(conds, col.Gt(f.Values[0]))
+		case OpGte:
+			conds = append(conds, col.Gte(f.Values[0]))
+		case OpLt:
+			conds = append(conds, col.Lt(f.Values[0]))
+		case OpLte:
+			conds = append(conds, col.Lte(f.Values[0]))
+		case OpContains:
+			conds = append(conds, col.Like("%"+escapeLike(f.Values[0])+"%"))
+		case OpIn:
+			vals := make([]any, len(f.Values))
+			for i, v := range f.Values {
+				vals[i] = v
+			}
+			conds = append(conds, col.In(vals...))
+		default:
+			conds = append(conds, col.Eq(f.Values[0]))
+		}
+	}
+	return conds
+}
+
+// OrderBy 将排序条件转换为ORM排序
+func (s *Spec) OrderBy() []orm.OrderBy {
+	orders := make([]orm.OrderBy, 0, len(s.Sorts))
+	for _, sort := range s.Sorts {
+		if sort.Desc {
+			orders = append(orders, orm.Desc(orm.Col(sort.Field)))
+		} else {
+			orders = append(orders, orm.Asc(orm.Col(sort.Field)))
+		}
+	}
+	return orders
+}
+
+// Apply 将列表规格应用到ORM查询上，依次追加 WHERE、ORDER BY、LIMIT 和 OFFSET
+// Selector 按调用顺序拼接SQL，因此调用方自己的过滤条件需要通过 conds 传入，而不是单独调用 Where
+func Apply[T any](s *orm.Selector[T], spec *Spec, conds ...orm.Condition) *orm.Selector[T] {
+	conds = append(conds, spec.Conditions()...)
+	if len(conds) > 0 {
+		s = s.Where(conds...)
+	}
+	return s.OrderBy(spec.OrderBy()...).Limit(spec.PerPage).Offset(spec.Offset())
+}
+
+// badRequest 创建参数错误
+func badRequest(param, reason string) *web.HTTPError {
+	return web.NewHTTPError(http.StatusBadRequest, "invalid listing parameter",
+		map[string]string{"param": param, "reason": reason})
+}
+
+// escapeLike 转义LIKE模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// sortedKeys 返回排序后的参数名
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
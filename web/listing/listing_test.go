@@ -0,0 +1,122 @@
+package listing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listingUser struct {
+	ID        int
+	Name      string
+	Age       int
+	Status    string
+	CreatedAt int64
+}
+
+var userListing = New(
+	WithPerPage(10, 50),
+	WithDefaultSort("-created_at"),
+	WithSort("created_at", "CreatedAt"),
+	WithSort("name", "Name"),
+	WithFilter("name", "Name", OpEq, OpContains),
+	WithFilter("age", "Age", OpGte, OpLt),
+	WithFilter("status", "Status", OpIn),
+)
+
+func TestListing_ParseValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		wantSpec *Spec
+		wantErr  string
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			wantSpec: &Spec{
+				Page: 1, PerPage: 10,
+				Sorts: []Sort{{Param: "created_at", Field: "CreatedAt", Desc: true}},
+			},
+		},
+		{
+			name:  "full",
+			query: "page=3&per_page=20&sort=name,-created_at&filter[name]=tom&filter[age][gte]=18&filter[status][in]=a,b",
+			wantSpec: &Spec{
+				Page: 3, PerPage: 20,
+				Sorts: []Sort{
+					{Param: "name", Field: "Name"},
+					{Param: "created_at", Field: "CreatedAt", Desc: true},
+				},
+				Filters: []Filter{
+					{Param: "age", Field: "Age", Op: OpGte, Values: []string{"18"}},
+					{Param: "name", Field: "Name", Op: OpEq, Values: []string{"tom"}},
+					{Param: "status", Field: "Status", Op: OpIn, Values: []string{"a", "b"}},
+				},
+			},
+		},
+		{name: "invalid page", query: "page=0", wantErr: "page"},
+		{name: "per page over max", query: "per_page=51", wantErr: "per_page"},
+		{name: "sort not allowed", query: "sort=age", wantErr: "sort"},
+		{name: "filter not allowed", query: "filter[password]=x", wantErr: "filter[password]"},
+		{name: "operator not allowed", query: "filter[name][gt]=x", wantErr: "filter[name][gt]"},
+		{name: "malformed filter", query: "filter[name=x", wantErr: "filter[name"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			spec, err := userListing.ParseValues(values)
+			if tc.wantErr != "" {
+				var he *web.HTTPError
+				require.ErrorAs(t, err, &he)
+				assert.Equal(t, http.StatusBadRequest, he.Code)
+				assert.Equal(t, tc.wantErr, he.Details.(map[string]string)["param"])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSpec, spec)
+		})
+	}
+}
+
+func TestSpec_Meta(t *testing.T) {
+	spec := &Spec{Page: 2, PerPage: 10}
+	assert.Equal(t, 10, spec.Offset())
+	assert.Equal(t, Meta{Page: 2, PerPage: 10, Total: 21, TotalPages: 3}, spec.Meta(21))
+	assert.Equal(t, 0, spec.Meta(0).TotalPages)
+}
+
+func TestApply(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	db, err := orm.Open(mockDB, "mysql")
+	require.NoError(t, err)
+
+	values, err := url.ParseQuery("page=2&filter[name][contains]=to%25m&filter[age][lt]=30")
+	require.NoError(t, err)
+	spec, err := userListing.ParseValues(values)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT \\* FROM `listing_user` WHERE `status` = \\? AND `age` < \\? AND `name` LIKE \\? "+
+		"ORDER BY `created_at` DESC LIMIT 10 OFFSET 10;").
+		WithArgs("active", "30", `%to\%m%`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "tom"))
+
+	users, err := Apply(orm.RegisterSelector[listingUser](db).Select(), spec,
+		orm.Col("Status").Eq("active")).GetMulti(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
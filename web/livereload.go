@@ -0,0 +1,282 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"golang.org/x/net/websocket"
+)
+
+// DefaultLiveReloadPath 浏览器连接的默认 WebSocket 地址
+const DefaultLiveReloadPath = "/__livereload"
+
+// LiveReload 开发环境下的浏览器自动刷新。定期检查监控的模板和静态资源，
+// 文件发生变化时通过 WebSocket 通知已连接的浏览器刷新页面
+//
+//	s.EnableLiveReload(web.WithLiveReloadWatch("views", "public"))
+//
+// HTML 响应会自动在 </body> 之前注入连接脚本，不经过中间件的页面可以在模板中写入 Script 的返回值
+type LiveReload struct {
+	path     string
+	watch    []string
+	interval time.Duration
+	logger   logger.Logger
+
+	// beforeNotify 通知浏览器之前调用，HTTPServer 用它重新加载模板，保证刷新后的页面使用新模板
+	beforeNotify func() error
+
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// LiveReloadOption 自动刷新的配置选项
+type LiveReloadOption func(*LiveReload)
+
+// WithLiveReloadPath 设置浏览器连接的地址，默认为 /__livereload
+func WithLiveReloadPath(path string) LiveReloadOption {
+	return func(l *LiveReload) {
+		l.path = path
+	}
+}
+
+// WithLiveReloadWatch 设置监控的文件，目录会递归监控其中的所有文件，也可以使用 filepath.Glob 的匹配模式
+func WithLiveReloadWatch(paths ...string) LiveReloadOption {
+	return func(l *LiveReload) {
+		l.watch = append(l.watch, paths...)
+	}
+}
+
+// WithLiveReloadInterval 设置检查文件变化的间隔，默认为500毫秒
+func WithLiveReloadInterval(interval time.Duration) LiveReloadOption {
+	return func(l *LiveReload) {
+		l.interval = interval
+	}
+}
+
+// NewLiveReload 创建自动刷新，需要调用 Start 开始监控文件
+func NewLiveReload(opts ...LiveReloadOption) *LiveReload {
+	l := &LiveReload{
+		path:     DefaultLiveReloadPath,
+		interval: 500 * time.Millisecond,
+		logger:   logger.GetDefaultLogger(),
+		clients:  make(map[chan struct{}]struct{}),
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// EnableLiveReload 注册自动刷新的 WebSocket 地址和注入脚本的全局中间件，并开始监控文件
+// 只应在开发环境中启用。文件变化时会先重新加载实现了 TemplateReloader 的模板引擎，再通知浏览器
+func (s *HTTPServer) EnableLiveReload(opts ...LiveReloadOption) *LiveReload {
+	l := NewLiveReload(opts...)
+	l.logger = s.logger
+	l.beforeNotify = func() error {
+		if r, ok := s.tplEngine.(TemplateReloader); ok {
+			return r.Reload()
+		}
+		return nil
+	}
+	s.Get(l.path, l.Handler())
+	s.Middleware().Global().Add(l.Middleware())
+	s.liveReload = l
+	l.Start()
+	return l
+}
+
+// Start 开始监控文件，没有设置监控的文件时只能通过 Notify 通知浏览器
+func (l *LiveReload) Start() {
+	if len(l.watch) == 0 {
+		return
+	}
+	go l.watchFiles()
+}
+
+// Close 停止监控文件并断开所有浏览器的连接
+func (l *LiveReload) Close() {
+	l.once.Do(func() {
+		close(l.stop)
+	})
+}
+
+// Notify 通知所有已连接的浏览器刷新页面
+func (l *LiveReload) Notify() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.clients {
+		// 每个连接只需要一次刷新通知，已有未处理的通知时不再重复发送
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Clients 返回已连接的浏览器数量
+func (l *LiveReload) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.clients)
+}
+
+// Handler 返回浏览器连接的 WebSocket 处理函数
+func (l *LiveReload) Handler() HandlerFunc {
+	return wrapHTTPHandler(websocket.Handler(l.serveConn))
+}
+
+// serveConn 等待文件变化并发送刷新消息，浏览器断开或 LiveReload 关闭时返回
+func (l *LiveReload) serveConn(ws *websocket.Conn) {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	l.clients[ch] = struct{}{}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		delete(l.clients, ch)
+		l.mu.Unlock()
+	}()
+
+	// 浏览器不会发送消息，读取只用于发现连接已经断开
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, ws)
+		close(closed)
+	}()
+
+	select {
+	case <-ch:
+		if err := websocket.Message.Send(ws, "reload"); err != nil {
+			l.logger.Debug("Failed to send live reload message", logger.FieldError(err))
+		}
+	case <-closed:
+	case <-l.stop:
+	}
+}
+
+// Script 返回连接自动刷新地址的脚本。连接断开后会不断重连，
+// 重连成功说明服务器已经重启，此时同样刷新页面
+func (l *LiveReload) Script() string {
+	return fmt.Sprintf(liveReloadScript, l.path)
+}
+
+const liveReloadScript = `<script>(function(){var d=false;function c(){var s=new WebSocket((location.protocol==="https:"?"wss://":"ws://")+location.host+%q);` +
+	`s.onopen=function(){if(d){location.reload()}};s.onmessage=function(e){if(e.data==="reload"){location.reload()}};` +
+	`s.onclose=function(){d=true;setTimeout(c,1000)}}c()})();</script>`
+
+// Middleware 返回在 HTML 响应中注入连接脚本的中间件
+func (l *LiveReload) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			// 直接写出的响应无法修改
+			if !ctx.unhandled || len(ctx.RespData) == 0 {
+				return
+			}
+			if !strings.HasPrefix(ctx.Resp.Header().Get("Content-Type"), "text/html") {
+				return
+			}
+			ctx.RespData = injectScript(ctx.RespData, l.Script())
+		}
+	}
+}
+
+// injectScript 在最后一个 </body> 之前插入脚本，没有 </body> 时追加到末尾
+func injectScript(html []byte, script string) []byte {
+	out := make([]byte, 0, len(html)+len(script))
+	idx := bytes.LastIndex(bytes.ToLower(html), []byte("</body>"))
+	if idx < 0 {
+		out = append(out, html...)
+		return append(out, script...)
+	}
+	out = append(out, html[:idx]...)
+	out = append(out, script...)
+	return append(out, html[idx:]...)
+}
+
+// watchFiles 定期检查监控的文件，发现变化时通知浏览器
+func (l *LiveReload) watchFiles() {
+	last := l.snapshot()
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cur := l.snapshot()
+			if cur.equal(last) {
+				continue
+			}
+			last = cur
+			if l.beforeNotify != nil {
+				if err := l.beforeNotify(); err != nil {
+					l.logger.Error("Failed to reload templates", logger.FieldError(err))
+				}
+			}
+			l.Notify()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// fileSnapshot 监控的文件的状态，文件的新增、删除和修改都会改变快照
+type fileSnapshot struct {
+	count   int
+	size    int64
+	modTime time.Time
+}
+
+func (s fileSnapshot) equal(o fileSnapshot) bool {
+	return s.count == o.count && s.size == o.size && s.modTime.Equal(o.modTime)
+}
+
+// snapshot 统计监控的文件的数量、总大小和最新的修改时间
+func (l *LiveReload) snapshot() fileSnapshot {
+	var snap fileSnapshot
+	add := func(info fs.FileInfo) {
+		snap.count++
+		snap.size += info.Size()
+		if info.ModTime().After(snap.modTime) {
+			snap.modTime = info.ModTime()
+		}
+	}
+
+	for _, pattern := range l.watch {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if !info.IsDir() {
+				add(info)
+				continue
+			}
+			_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return nil
+				}
+				if info, err := d.Info(); err == nil {
+					add(info)
+				}
+				return nil
+			})
+		}
+	}
+	return snap
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// reloadCountingEngine 记录重新加载次数的模板引擎
+type reloadCountingEngine struct {
+	reloads atomic.Int32
+}
+
+func (e *reloadCountingEngine) Render(ctx *Context, tplName string, data any) ([]byte, error) {
+	return nil, nil
+}
+
+func (e *reloadCountingEngine) Reload() error {
+	e.reloads.Add(1)
+	return nil
+}
+
+func TestLiveReload_Middleware(t *testing.T) {
+	s := NewHTTPServer()
+	lr := s.EnableLiveReload()
+	defer lr.Close()
+
+	s.Get("/page", func(ctx *Context) {
+		ctx.HTML(http.StatusOK, "<html><body><h1>hi</h1></BODY></html>")
+	})
+	s.Get("/fragment", func(ctx *Context) {
+		ctx.HTML(http.StatusOK, "<p>fragment</p>")
+	})
+	s.Get("/json", func(ctx *Context) {
+		ctx.JSON(http.StatusOK, map[string]string{"body": "</body>"})
+	})
+
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "before closing body",
+			path: "/page",
+			want: "<html><body><h1>hi</h1>" + lr.Script() + "</BODY></html>",
+		},
+		{
+			name: "appended without body",
+			path: "/fragment",
+			want: "<p>fragment</p>" + lr.Script(),
+		},
+		{
+			name: "non html untouched",
+			path: "/json",
+			want: `{"body":"\u003c/body\u003e"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.want, rec.Body.String())
+		})
+	}
+
+	assert.Contains(t, lr.Script(), `"/__livereload"`)
+}
+
+func TestLiveReload_WatchAndNotify(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.css")
+	require.NoError(t, os.WriteFile(file, []byte("body{}"), 0644))
+
+	engine := &reloadCountingEngine{}
+	s := NewHTTPServer(WithTemplate(engine))
+	lr := s.EnableLiveReload(
+		WithLiveReloadPath("/_reload"),
+		WithLiveReloadWatch(dir),
+		WithLiveReloadInterval(10*time.Millisecond),
+	)
+	defer lr.Close()
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/_reload"
+	ws, err := websocket.Dial(wsURL, "", srv.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return lr.Clients() == 1 }, time.Second, 5*time.Millisecond)
+
+	// 新增文件触发刷新，刷新前重新加载模板
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("1"), 0644))
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg string
+	require.NoError(t, websocket.Message.Receive(ws, &msg))
+	assert.Equal(t, "reload", msg)
+	assert.Equal(t, int32(1), engine.reloads.Load())
+
+	// 发送刷新消息后服务端关闭连接
+	assert.Eventually(t, func() bool { return lr.Clients() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestLiveReload_Close(t *testing.T) {
+	lr := NewLiveReload()
+	srv := httptest.NewServer(websocket.Handler(lr.serveConn))
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return lr.Clients() == 1 }, time.Second, 5*time.Millisecond)
+
+	// 关闭后断开所有连接，Notify 不会阻塞
+	lr.Close()
+	lr.Notify()
+	assert.Eventually(t, func() bool { return lr.Clients() == 0 }, time.Second, 5*time.Millisecond)
+}
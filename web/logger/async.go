@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriterClosed 异步写入器已经关闭
+var ErrWriterClosed = errors.New("logger: async writer closed")
+
+// Syncer 可以将缓冲的日志写入底层存储的输出
+type Syncer interface {
+	Sync() error
+}
+
+// Sync 刷新日志记录器的输出中缓冲的日志，输出不支持刷新时直接返回。
+// HTTPServer 在 Shutdown 的最后调用它
+func Sync(l Logger) error {
+	if s, ok := l.(Syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// AsyncOption AsyncWriter 的选项
+type AsyncOption func(*AsyncWriter)
+
+// WithQueueSize 设置等待写入的日志条数，默认为1024
+func WithQueueSize(n int) AsyncOption {
+	return func(w *AsyncWriter) {
+		if n > 0 {
+			w.queueSize = n
+		}
+	}
+}
+
+// WithBufferSize 设置写入底层输出之前的缓冲区大小，默认为256KB
+func WithBufferSize(n int) AsyncOption {
+	return func(w *AsyncWriter) {
+		if n > 0 {
+			w.bufferSize = n
+		}
+	}
+}
+
+// WithFlushInterval 设置缓冲区的最长刷新间隔，默认为1秒
+func WithFlushInterval(interval time.Duration) AsyncOption {
+	return func(w *AsyncWriter) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithBlockOnFull 队列已满时阻塞写入，直到有空位，默认丢弃日志并计数
+func WithBlockOnFull() AsyncOption {
+	return func(w *AsyncWriter) {
+		w.block = true
+	}
+}
+
+// AsyncWriter 在后台协程中写入底层输出，请求处理函数不需要等待磁盘 I/O。
+// 日志先进入队列，再经过缓冲区批量写入，缓冲区写满、到达刷新间隔、调用 Flush 或 Close 时写入底层输出
+//
+//	file, _ := logger.NewRotatingWriter("logs/app.log", 100<<20, 7, 0)
+//	out := logger.NewAsyncWriter(file)
+//	defer out.Close()
+//	log := logger.NewLogger(logger.WithOutput(out))
+type AsyncWriter struct {
+	out        io.Writer
+	queueSize  int
+	bufferSize int
+	interval   time.Duration
+	block      bool
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	flushes chan chan error
+	done    chan struct{}
+	dropped atomic.Int64
+	lastErr error // 只在后台协程中访问
+}
+
+// NewAsyncWriter 创建写入 out 的异步写入器并启动后台协程
+func NewAsyncWriter(out io.Writer, opts ...AsyncOption) *AsyncWriter {
+	w := &AsyncWriter{
+		out:        out,
+		queueSize:  1024,
+		bufferSize: 256 << 10,
+		interval:   time.Second,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.queue = make(chan []byte, w.queueSize)
+	w.flushes = make(chan chan error)
+	w.done = make(chan struct{})
+	go w.run()
+	return w
+}
+
+// Write 将日志放入队列。p 会被复制，调用方可以复用。队列已满时默认丢弃日志，丢弃的条数可以通过 Dropped 获取
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	if w.block {
+		w.queue <- entry
+		return len(p), nil
+	}
+	select {
+	case w.queue <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped 返回因为队列已满而丢弃的日志条数
+func (w *AsyncWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Flush 等待队列中的日志写入底层输出，返回上一次刷新以来的写入错误
+func (w *AsyncWriter) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	reply := make(chan error, 1)
+	w.flushes <- reply
+	return <-reply
+}
+
+// Sync 刷新队列和缓冲区，底层输出实现了 Syncer 时同时调用它的 Sync
+func (w *AsyncWriter) Sync() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if s, ok := w.out.(Syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close 写入剩余的日志并停止后台协程，底层输出实现了 io.Closer 时将其关闭
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	err := w.lastErr
+	if c, ok := w.out.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// run 后台协程，从队列中取出日志写入缓冲区
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	buf := bufio.NewWriterSize(w.out, w.bufferSize)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	write := func(p []byte) {
+		if _, err := buf.Write(p); err != nil {
+			w.lastErr = err
+			// bufio.Writer 出错后不再接受写入，重新创建以便底层输出恢复后继续写入
+			buf = bufio.NewWriterSize(w.out, w.bufferSize)
+		}
+	}
+	flush := func() {
+		if err := buf.Flush(); err != nil {
+			w.lastErr = err
+			buf = bufio.NewWriterSize(w.out, w.bufferSize)
+		}
+	}
+
+	for {
+		select {
+		case p, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			write(p)
+		case <-ticker.C:
+			flush()
+		case reply := <-w.flushes:
+			// Flush 持有读锁，队列不会在这里被关闭
+			for drained := false; !drained; {
+				select {
+				case p := <-w.queue:
+					write(p)
+				default:
+					drained = true
+				}
+			}
+			flush()
+			reply <- w.lastErr
+			w.lastErr = nil
+		}
+	}
+}
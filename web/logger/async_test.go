@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer 可以在多个协程中使用的输出，release 不为nil时每次写入前等待放行
+type syncBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	err     error
+	closed  bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	if b.release != nil {
+		b.started <- struct{}{}
+		<-b.release
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newBlockedBuffer 创建写入时阻塞的输出，started 收到信号表示后台协程正在写入
+func newBlockedBuffer() *syncBuffer {
+	return &syncBuffer{started: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func TestAsyncWriter_Flush(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	_, err := w.Write([]byte("a\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("b\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "a\nb\n", out.String())
+}
+
+func TestAsyncWriter_FlushInterval(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, WithFlushInterval(10*time.Millisecond))
+	defer w.Close()
+
+	_, err := w.Write([]byte("tick\n"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return out.String() == "tick\n" }, time.Second, 5*time.Millisecond)
+}
+
+func TestAsyncWriter_CopiesInput(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	p := []byte("one\n")
+	_, err := w.Write(p)
+	require.NoError(t, err)
+	copy(p, "two\n")
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "one\n", out.String())
+}
+
+func TestAsyncWriter_Dropped(t *testing.T) {
+	out := newBlockedBuffer()
+	// 缓冲区只有1字节，每条日志都直接写入底层输出
+	w := NewAsyncWriter(out, WithQueueSize(1), WithBufferSize(1), WithFlushInterval(time.Hour))
+
+	_, err := w.Write([]byte("a\n"))
+	require.NoError(t, err)
+	<-out.started // 后台协程阻塞在写入 a
+
+	_, err = w.Write([]byte("b\n")) // 进入队列
+	require.NoError(t, err)
+	n, err := w.Write([]byte("c\n")) // 队列已满，丢弃
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int64(1), w.Dropped())
+
+	close(out.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "a\nb\n", out.String())
+}
+
+func TestAsyncWriter_BlockOnFull(t *testing.T) {
+	out := newBlockedBuffer()
+	w := NewAsyncWriter(out, WithQueueSize(1), WithBufferSize(1), WithFlushInterval(time.Hour), WithBlockOnFull())
+
+	_, err := w.Write([]byte("a\n"))
+	require.NoError(t, err)
+	<-out.started
+	_, err = w.Write([]byte("b\n"))
+	require.NoError(t, err)
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("c\n"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("write should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(out.release)
+	<-written
+	require.NoError(t, w.Close())
+	assert.Equal(t, "a\nb\nc\n", out.String())
+	assert.Equal(t, int64(0), w.Dropped())
+}
+
+func TestAsyncWriter_Close(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, WithFlushInterval(time.Hour))
+
+	var want bytes.Buffer
+	for i := 0; i < 100; i++ {
+		line := []byte("line\n")
+		want.Write(line)
+		_, err := w.Write(line)
+		require.NoError(t, err)
+	}
+
+	// 关闭时写入队列中剩余的日志并关闭底层输出
+	require.NoError(t, w.Close())
+	assert.Equal(t, want.String(), out.String())
+	assert.True(t, out.closed)
+
+	_, err := w.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, ErrWriterClosed)
+	assert.ErrorIs(t, w.Flush(), ErrWriterClosed)
+	assert.NoError(t, w.Close())
+}
+
+func TestAsyncWriter_WriteError(t *testing.T) {
+	failure := errors.New("disk full")
+	out := &syncBuffer{err: failure}
+	w := NewAsyncWriter(out, WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	_, err := w.Write([]byte("lost\n"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, w.Flush(), failure)
+
+	// 错误只返回一次，输出恢复后继续写入
+	out.mu.Lock()
+	out.err = nil
+	out.mu.Unlock()
+	_, err = w.Write([]byte("ok\n"))
+	require.NoError(t, err)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "ok\n", out.String())
+}
+
+func TestSync(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, WithFlushInterval(time.Hour))
+	defer w.Close()
+	log := NewLogger(WithOutput(w))
+
+	log.Info("hello")
+	assert.Empty(t, out.String())
+	require.NoError(t, Sync(log))
+	assert.Contains(t, out.String(), `"message":"hello"`)
+}
@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink 批量接收日志的外部系统，例如日志服务、消息队列或 syslog
+type Sink interface {
+	Send(ctx context.Context, entries []Entry) error
+}
+
+// SinkFunc 函数形式的 Sink
+type SinkFunc func(ctx context.Context, entries []Entry) error
+
+func (f SinkFunc) Send(ctx context.Context, entries []Entry) error {
+	return f(ctx, entries)
+}
+
+// OverflowPolicy BatchHook 的队列已满时的处理方式
+type OverflowPolicy int
+
+const (
+	// DropNewest 丢弃新的日志，默认的处理方式
+	DropNewest OverflowPolicy = iota
+	// DropOldest 丢弃队列中最早的日志
+	DropOldest
+	// Block 阻塞记录日志的协程，直到队列有空位
+	Block
+)
+
+// BatchOption BatchHook 的选项
+type BatchOption func(*BatchHook)
+
+// WithBatchSize 设置每批发送的最大条数，默认为100
+func WithBatchSize(n int) BatchOption {
+	return func(h *BatchHook) {
+		if n > 0 {
+			h.batchSize = n
+		}
+	}
+}
+
+// WithBatchInterval 设置不满一批时的最长等待时间，默认为1秒
+func WithBatchInterval(interval time.Duration) BatchOption {
+	return func(h *BatchHook) {
+		if interval > 0 {
+			h.interval = interval
+		}
+	}
+}
+
+// WithBatchQueueSize 设置等待发送的最大条数，默认为10000
+func WithBatchQueueSize(n int) BatchOption {
+	return func(h *BatchHook) {
+		if n > 0 {
+			h.queueSize = n
+		}
+	}
+}
+
+// WithOverflowPolicy 设置队列已满时的处理方式，默认为 DropNewest
+func WithOverflowPolicy(policy OverflowPolicy) BatchOption {
+	return func(h *BatchHook) {
+		h.policy = policy
+	}
+}
+
+// WithMinLevel 只发送级别不低于 level 的日志
+func WithMinLevel(level LogLevel) BatchOption {
+	return func(h *BatchHook) {
+		h.minLevel = level
+	}
+}
+
+// WithSendTimeout 设置每次发送的超时时间，默认为10秒
+func WithSendTimeout(timeout time.Duration) BatchOption {
+	return func(h *BatchHook) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// WithRetry 设置发送失败后的重试次数和间隔，间隔每次翻倍，默认不重试
+func WithRetry(retries int, backoff time.Duration) BatchOption {
+	return func(h *BatchHook) {
+		h.retries = retries
+		h.backoff = backoff
+	}
+}
+
+// WithSendErrorHandler 设置重试后仍然发送失败时的处理函数，默认写入标准错误。
+// 不要在其中使用带有同一个钩子的日志记录器，否则会造成循环
+func WithSendErrorHandler(fn func(err error, entries []Entry)) BatchOption {
+	return func(h *BatchHook) {
+		h.onError = fn
+	}
+}
+
+// BatchHook 将日志放入队列，在后台协程中按批发送到 Sink，记录日志的协程不需要等待网络请求。
+// 队列的长度和队列已满时的处理方式用于控制积压：
+//
+//	hook := logger.NewBatchHook(logger.NewLokiSink("http://loki:3100", map[string]string{"app": "api"}),
+//		logger.WithBatchSize(500),
+//		logger.WithOverflowPolicy(logger.DropOldest),
+//	)
+//	log := logger.NewLogger(logger.WithHook(hook))
+type BatchHook struct {
+	sink      Sink
+	batchSize int
+	interval  time.Duration
+	queueSize int
+	policy    OverflowPolicy
+	minLevel  LogLevel
+	timeout   time.Duration
+	retries   int
+	backoff   time.Duration
+	onError   func(err error, entries []Entry)
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan Entry
+	flushes chan chan error
+	done    chan struct{}
+	dropped atomic.Int64
+	lastErr error // 只在后台协程中访问
+}
+
+// NewBatchHook 创建发送到 sink 的钩子并启动后台协程
+func NewBatchHook(sink Sink, opts ...BatchOption) *BatchHook {
+	h := &BatchHook{
+		sink:      sink,
+		batchSize: 100,
+		interval:  time.Second,
+		queueSize: 10000,
+		timeout:   10 * time.Second,
+		onError: func(err error, entries []Entry) {
+			fmt.Fprintf(os.Stderr, "logger: failed to send %d log entries: %v\n", len(entries), err)
+		},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.queue = make(chan Entry, h.queueSize)
+	h.flushes = make(chan chan error)
+	h.done = make(chan struct{})
+	go h.run()
+	return h
+}
+
+// Fire 将日志放入队列，队列已满时按 OverflowPolicy 处理，丢弃的条数可以通过 Dropped 获取
+func (h *BatchHook) Fire(entry Entry) error {
+	if entry.Level < h.minLevel {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return nil
+	}
+
+	switch h.policy {
+	case Block:
+		h.queue <- entry
+		return nil
+	case DropOldest:
+		for {
+			select {
+			case h.queue <- entry:
+				return nil
+			default:
+			}
+			select {
+			case <-h.queue:
+				h.dropped.Add(1)
+			default:
+			}
+		}
+	}
+	select {
+	case h.queue <- entry:
+	default:
+		h.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped 返回因为队列已满而丢弃的日志条数
+func (h *BatchHook) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Flush 发送队列中的所有日志，返回上一次刷新以来最后一次发送失败的错误
+func (h *BatchHook) Flush() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return nil
+	}
+	reply := make(chan error, 1)
+	h.flushes <- reply
+	return <-reply
+}
+
+// Sync 与 Flush 相同，logger.Sync 和 HTTPServer.Shutdown 会调用它
+func (h *BatchHook) Sync() error {
+	return h.Flush()
+}
+
+// Close 发送剩余的日志并停止后台协程，Sink 实现了 io.Closer 时将其关闭
+func (h *BatchHook) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.queue)
+	h.mu.Unlock()
+
+	<-h.done
+	err := h.lastErr
+	if c, ok := h.sink.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// run 后台协程，凑满一批或到达等待时间时发送
+func (h *BatchHook) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, h.batchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		h.send(batch)
+		batch = make([]Entry, 0, h.batchSize)
+	}
+
+	for {
+		select {
+		case entry, ok := <-h.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= h.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case reply := <-h.flushes:
+			// Flush 持有读锁，队列不会在这里被关闭
+			for drained := false; !drained; {
+				select {
+				case entry := <-h.queue:
+					batch = append(batch, entry)
+					if len(batch) >= h.batchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			reply <- h.lastErr
+			h.lastErr = nil
+		}
+	}
+}
+
+// send 发送一批日志，失败时按配置重试
+func (h *BatchHook) send(batch []Entry) {
+	backoff := h.backoff
+	var err error
+	for attempt := 0; attempt <= h.retries; attempt++ {
+		if attempt > 0 && backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		err = h.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+	}
+	h.lastErr = err
+	if h.onError != nil {
+		h.onError(err, batch)
+	}
+}
+
+// WriterSink 将日志按 JSON Lines 格式写入 w，可以用于文件、管道或测试
+func WriterSink(w io.Writer) Sink {
+	var mu sync.Mutex
+	return SinkFunc(func(_ context.Context, entries []Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, entry := range entries {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
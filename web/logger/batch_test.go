@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordSink 记录收到的每一批日志，release 不为nil时每次发送前等待放行
+type recordSink struct {
+	mu      sync.Mutex
+	batches [][]string
+	calls   int
+	fail    int // 前 fail 次发送返回错误
+	closed  bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockedSink() *recordSink {
+	return &recordSink{started: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (s *recordSink) Send(_ context.Context, entries []Entry) error {
+	if s.release != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.fail {
+		return errors.New("sink unavailable")
+	}
+	batch := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		batch = append(batch, entry.Message)
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// messages 返回按发送顺序排列的所有日志
+func (s *recordSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []string
+	for _, batch := range s.batches {
+		messages = append(messages, batch...)
+	}
+	return messages
+}
+
+func (s *recordSink) sizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, 0, len(s.batches))
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func fire(t *testing.T, h *BatchHook, messages ...string) {
+	t.Helper()
+	for _, msg := range messages {
+		require.NoError(t, h.Fire(Entry{Level: InfoLevel, Message: msg}))
+	}
+}
+
+func TestBatchHook_BatchSize(t *testing.T) {
+	sink := &recordSink{}
+	h := NewBatchHook(sink, WithBatchSize(3), WithBatchInterval(time.Hour))
+	defer h.Close()
+
+	fire(t, h, "1", "2", "3", "4", "5", "6", "7")
+	require.NoError(t, h.Flush())
+	assert.Equal(t, []int{3, 3, 1}, sink.sizes())
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6", "7"}, sink.messages())
+}
+
+func TestBatchHook_Interval(t *testing.T) {
+	sink := &recordSink{}
+	h := NewBatchHook(sink, WithBatchSize(100), WithBatchInterval(10*time.Millisecond))
+	defer h.Close()
+
+	// 不满一批时到达等待时间后发送
+	fire(t, h, "tick")
+	assert.Eventually(t, func() bool {
+		return len(sink.messages()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBatchHook_OverflowPolicy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      OverflowPolicy
+		wantDropped int64
+		want        []string
+	}{
+		{name: "drop newest", policy: DropNewest, wantDropped: 1, want: []string{"1", "2", "3"}},
+		{name: "drop oldest", policy: DropOldest, wantDropped: 1, want: []string{"1", "3", "4"}},
+		{name: "block", policy: Block, want: []string{"1", "2", "3", "4"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := newBlockedSink()
+			h := NewBatchHook(sink,
+				WithBatchSize(1),
+				WithBatchInterval(time.Hour),
+				WithBatchQueueSize(2),
+				WithOverflowPolicy(tc.policy),
+			)
+
+			fire(t, h, "1")
+			<-sink.started // 后台协程阻塞在发送 1
+			fire(t, h, "2", "3")
+
+			fired := make(chan struct{})
+			go func() {
+				_ = h.Fire(Entry{Level: InfoLevel, Message: "4"})
+				close(fired)
+			}()
+			if tc.policy == Block {
+				select {
+				case <-fired:
+					t.Fatal("fire should block while the queue is full")
+				case <-time.After(20 * time.Millisecond):
+				}
+			} else {
+				<-fired
+			}
+
+			close(sink.release)
+			<-fired
+			require.NoError(t, h.Close())
+			assert.Equal(t, tc.want, sink.messages())
+			assert.Equal(t, tc.wantDropped, h.Dropped())
+		})
+	}
+}
+
+func TestBatchHook_MinLevel(t *testing.T) {
+	sink := &recordSink{}
+	h := NewBatchHook(sink, WithMinLevel(WarnLevel), WithBatchInterval(time.Hour))
+	defer h.Close()
+
+	require.NoError(t, h.Fire(Entry{Level: InfoLevel, Message: "info"}))
+	require.NoError(t, h.Fire(Entry{Level: WarnLevel, Message: "warn"}))
+	require.NoError(t, h.Fire(Entry{Level: ErrorLevel, Message: "error"}))
+	require.NoError(t, h.Flush())
+	assert.Equal(t, []string{"warn", "error"}, sink.messages())
+}
+
+func TestBatchHook_Retry(t *testing.T) {
+	sink := &recordSink{fail: 2}
+	h := NewBatchHook(sink, WithRetry(2, time.Millisecond), WithBatchInterval(time.Hour))
+	defer h.Close()
+
+	fire(t, h, "retried")
+	require.NoError(t, h.Flush())
+	assert.Equal(t, []string{"retried"}, sink.messages())
+	assert.Equal(t, 3, sink.calls)
+}
+
+func TestBatchHook_SendError(t *testing.T) {
+	sink := &recordSink{fail: 10}
+	var failed []Entry
+	h := NewBatchHook(sink,
+		WithRetry(1, time.Millisecond),
+		WithBatchInterval(time.Hour),
+		WithSendErrorHandler(func(err error, entries []Entry) {
+			failed = append(failed, entries...)
+		}),
+	)
+	defer h.Close()
+
+	fire(t, h, "lost")
+	assert.EqualError(t, h.Flush(), "sink unavailable")
+	require.Len(t, failed, 1)
+	assert.Equal(t, "lost", failed[0].Message)
+	assert.Equal(t, 2, sink.calls)
+
+	// 错误只返回一次
+	assert.NoError(t, h.Flush())
+}
+
+func TestBatchHook_Close(t *testing.T) {
+	sink := &recordSink{}
+	h := NewBatchHook(sink, WithBatchSize(100), WithBatchInterval(time.Hour))
+	log := NewLogger(WithHook(h), WithOutput(&bytes.Buffer{}))
+
+	log.Info("first")
+	log.Warn("second")
+	require.NoError(t, Sync(log))
+	assert.Equal(t, []string{"first", "second"}, sink.messages())
+
+	// 关闭时发送剩余的日志并关闭 Sink
+	log.Info("last")
+	require.NoError(t, h.Close())
+	assert.Equal(t, []string{"first", "second", "last"}, sink.messages())
+	assert.True(t, sink.closed)
+
+	// 关闭后的日志被忽略
+	assert.NoError(t, h.Fire(Entry{Level: InfoLevel, Message: "ignored"}))
+	assert.NoError(t, h.Flush())
+	assert.NoError(t, h.Close())
+}
+
+func TestWriterSink(t *testing.T) {
+	var out bytes.Buffer
+	sink := WriterSink(&out)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.Send(context.Background(), []Entry{
+		{Time: now, Level: InfoLevel, Message: "a", Fields: []Field{String("user", "tom")}},
+		{Time: now, Level: ErrorLevel, Message: "b", Fields: []Field{FieldError(errors.New("boom"))}},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var first, second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "a", first["message"])
+	assert.Equal(t, "tom", first["user"])
+	assert.Equal(t, "info", first["level"])
+	assert.Equal(t, "boom", second["error"])
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// loggerPackage 本包的函数名前缀，查找调用位置时跳过
+const loggerPackage = "github.com/fyerfyer/fyer-webframe/web/logger."
+
+// WithCaller 在日志中添加 caller 字段，记录调用日志方法的文件和行号
+func WithCaller() Option {
+	return func(cfg *LogConfig) {
+		cfg.Caller = true
+	}
+}
+
+// WithCallerSkip 在查找调用位置时额外跳过 skip 层调用，用于在自己的函数中封装日志方法的场景。
+// 本包中的函数总是会被跳过
+func WithCallerSkip(skip int) Option {
+	return func(cfg *LogConfig) {
+		cfg.Caller = true
+		cfg.CallerSkip = skip
+	}
+}
+
+// WithStacktrace 级别不低于 level 的日志添加 stack 字段，记录调用栈
+func WithStacktrace(level LogLevel) Option {
+	return func(cfg *LogConfig) {
+		cfg.Stacktrace = true
+		cfg.StacktraceLevel = level
+	}
+}
+
+// decorate 按配置为日志添加 caller 和 stack 字段，返回新的切片，不修改 fields
+func (l *zerologLogger) decorate(level LogLevel, fields []Field) []Field {
+	withStack := l.stacktrace && level >= l.stacktraceLevel
+	if !l.caller && !withStack {
+		return fields
+	}
+	frames := callerFrames(l.callerSkip)
+	out := make([]Field, 0, len(fields)+2)
+	out = append(out, fields...)
+	if l.caller && len(frames) > 0 {
+		out = append(out, String("caller", shortCaller(frames[0])))
+	}
+	if withStack {
+		out = append(out, String("stack", formatStack(frames)))
+	}
+	return out
+}
+
+// callerFrames 返回从调用日志方法的位置开始的调用栈
+func callerFrames(skip int) []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	// 跳过 runtime.Callers 和 callerFrames
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var result []runtime.Frame
+	inLogger := true
+	for {
+		frame, more := frames.Next()
+		if inLogger && strings.HasPrefix(frame.Function, loggerPackage) {
+			if !more {
+				break
+			}
+			continue
+		}
+		inLogger = false
+		if skip > 0 {
+			skip--
+		} else {
+			result = append(result, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// shortCaller 返回 目录/文件:行号 形式的调用位置
+func shortCaller(frame runtime.Frame) string {
+	dir, file := filepath.Split(frame.File)
+	return filepath.Base(dir) + "/" + file + ":" + strconv.Itoa(frame.Line)
+}
+
+// formatStack 按 runtime/debug.Stack 的格式输出调用栈
+func formatStack(frames []runtime.Frame) string {
+	var b strings.Builder
+	for i, frame := range frames {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// 查找调用位置时会跳过 logger 包中的函数，测试需要放在外部测试包中
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLine 解析最后一行 JSON 日志
+func decodeLine(t *testing.T, out *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+	return entry
+}
+
+// here 返回调用位置的 目录/文件:行号
+func here(offset int) string {
+	_, file, line, _ := runtime.Caller(1)
+	parts := strings.Split(file, "/")
+	return strings.Join(parts[len(parts)-2:], "/") + ":" + strconv.Itoa(line+offset)
+}
+
+// logWarn 封装日志方法的辅助函数
+func logWarn(l logger.Logger, msg string) {
+	l.Warn(msg)
+}
+
+func TestWithCaller(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewLogger(logger.WithOutput(&out), logger.WithCaller())
+
+	want := here(1)
+	log.Info("hello")
+	entry := decodeLine(t, &out)
+	assert.Equal(t, want, entry["caller"])
+	assert.NotContains(t, entry, "stack")
+
+	// 通过 WithFields 创建的日志记录器同样记录调用位置
+	want = here(1)
+	log.WithFields(logger.String("user", "tom")).Info("hello")
+	assert.Equal(t, want, decodeLine(t, &out)["caller"])
+
+	out.Reset()
+	logger.NewLogger(logger.WithOutput(&out)).Info("hello")
+	assert.NotContains(t, decodeLine(t, &out), "caller")
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	var out bytes.Buffer
+
+	// 不跳过时记录的是辅助函数中的位置
+	want := here(1)
+	logWarn(logger.NewLogger(logger.WithOutput(&out), logger.WithCaller()), "wrapped")
+	caller, _ := decodeLine(t, &out)["caller"].(string)
+	assert.True(t, strings.HasPrefix(caller, "logger/caller_test.go:"), caller)
+	assert.NotEqual(t, want, caller)
+
+	// 跳过一层后记录调用辅助函数的位置
+	want = here(1)
+	logWarn(logger.NewLogger(logger.WithOutput(&out), logger.WithCallerSkip(1)), "wrapped")
+	assert.Equal(t, want, decodeLine(t, &out)["caller"])
+}
+
+func TestWithStacktrace(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewLogger(logger.WithOutput(&out), logger.WithStacktrace(logger.ErrorLevel))
+
+	// 低于阈值的日志没有调用栈
+	log.Warn("warn")
+	entry := decodeLine(t, &out)
+	assert.NotContains(t, entry, "stack")
+	assert.NotContains(t, entry, "caller")
+
+	log.Error("error")
+	stack, ok := decodeLine(t, &out)["stack"].(string)
+	require.True(t, ok)
+	// 调用栈从调用日志方法的函数开始，不包含 logger 包中的函数
+	assert.True(t, strings.HasPrefix(stack, "github.com/fyerfyer/fyer-webframe/web/logger_test.TestWithStacktrace\n\t"), stack)
+	assert.NotContains(t, stack, "web/logger.(*zerologLogger)")
+	assert.Contains(t, stack, "testing.tRunner")
+}
@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Encoding 日志的输出格式
+type Encoding string
+
+const (
+	// EncodingJSON 每行一个 JSON 对象，默认格式
+	EncodingJSON Encoding = "json"
+	// EncodingConsole 便于阅读的单行文本，输出到终端时带有颜色
+	EncodingConsole Encoding = "console"
+	// EncodingLogfmt key=value 形式的单行文本
+	EncodingLogfmt Encoding = "logfmt"
+)
+
+// ParseEncoding 将 json、console 或 logfmt 转换为 Encoding，便于从配置中读取
+func ParseEncoding(s string) (Encoding, error) {
+	switch enc := Encoding(strings.ToLower(strings.TrimSpace(s))); enc {
+	case EncodingJSON, EncodingConsole, EncodingLogfmt:
+		return enc, nil
+	case "text":
+		return EncodingConsole, nil
+	}
+	return "", fmt.Errorf("logger: unknown encoding %q", s)
+}
+
+// ParseLevel 将 debug、info、warn、error 或 fatal 转换为 LogLevel，便于从配置中读取
+func ParseLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("logger: unknown level %q", s)
+}
+
+// String 返回级别的名称
+func (l LogLevel) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// encode 按格式包装输出，zerolog 写出的总是 JSON，其他格式由包装的写入器转换
+func encode(enc Encoding, timeFormat string, w io.Writer) io.Writer {
+	switch enc {
+	case EncodingConsole:
+		return zerolog.ConsoleWriter{Out: w, TimeFormat: timeFormat, NoColor: !isTerminal(w)}
+	case EncodingLogfmt:
+		return &logfmtWriter{out: w}
+	}
+	return w
+}
+
+// isTerminal 判断 w 是否为终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// levelRoute 级别不低于 level 的日志写入 out
+type levelRoute struct {
+	level LogLevel
+	raw   io.Writer // 未经编码的输出，用于 Sync
+	out   io.Writer
+}
+
+// levelRouter 按级别选择输出，没有匹配的级别时写入默认输出
+type levelRouter struct {
+	raw    io.Writer
+	out    io.Writer
+	routes []levelRoute // 按级别从高到低排列
+}
+
+// newLevelRouter 创建按级别选择输出的写入器
+func newLevelRouter(enc Encoding, timeFormat string, def io.Writer, outputs map[LogLevel]io.Writer) *levelRouter {
+	r := &levelRouter{raw: def, out: encode(enc, timeFormat, def)}
+	for level, w := range outputs {
+		r.routes = append(r.routes, levelRoute{level: level, raw: w, out: encode(enc, timeFormat, w)})
+	}
+	sort.Slice(r.routes, func(i, j int) bool {
+		return r.routes[i].level > r.routes[j].level
+	})
+	return r
+}
+
+// Write 没有级别信息的写入使用默认输出
+func (r *levelRouter) Write(p []byte) (int, error) {
+	return r.out.Write(p)
+}
+
+// WriteLevel 实现 zerolog.LevelWriter
+func (r *levelRouter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	l := fromZerologLevel(level)
+	for _, route := range r.routes {
+		if l >= route.level {
+			return route.out.Write(p)
+		}
+	}
+	return r.out.Write(p)
+}
+
+// Sync 刷新所有输出
+func (r *levelRouter) Sync() error {
+	var firstErr error
+	for _, w := range append([]io.Writer{r.raw}, r.rawRoutes()...) {
+		if err := syncWriter(w); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (r *levelRouter) rawRoutes() []io.Writer {
+	ws := make([]io.Writer, 0, len(r.routes))
+	for _, route := range r.routes {
+		ws = append(ws, route.raw)
+	}
+	return ws
+}
+
+// syncWriter 刷新实现了 Syncer 的输出，终端和管道不支持 fsync，跳过标准输出和标准错误
+func syncWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if s, ok := w.(Syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// fromZerologLevel 将 zerolog 级别转换为内部日志级别
+func fromZerologLevel(level zerolog.Level) LogLevel {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return DebugLevel
+	case zerolog.WarnLevel:
+		return WarnLevel
+	case zerolog.ErrorLevel:
+		return ErrorLevel
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return FatalLevel
+	}
+	return InfoLevel
+}
+
+// logfmtWriter 将 zerolog 输出的 JSON 转换为 logfmt，time、level 和 msg 排在最前面
+type logfmtWriter struct {
+	out io.Writer
+}
+
+func (w *logfmtWriter) Write(p []byte) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		// 不是 JSON 对象时原样写入
+		return w.out.Write(p)
+	}
+
+	type pair struct {
+		key   string
+		value string
+	}
+	var head [3]*pair
+	var rest []pair
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return w.out.Write(p)
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return w.out.Write(p)
+		}
+		kv := pair{key: key, value: logfmtValue(raw)}
+		switch key {
+		case zerolog.TimestampFieldName:
+			head[0] = &kv
+		case zerolog.LevelFieldName:
+			head[1] = &kv
+		case zerolog.MessageFieldName:
+			kv.key = "msg"
+			head[2] = &kv
+		default:
+			rest = append(rest, kv)
+		}
+	}
+
+	var b bytes.Buffer
+	for _, kv := range head {
+		if kv != nil {
+			writeLogfmtPair(&b, kv.key, kv.value)
+		}
+	}
+	for _, kv := range rest {
+		writeLogfmtPair(&b, kv.key, kv.value)
+	}
+	b.WriteByte('\n')
+	if _, err := w.out.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func writeLogfmtPair(b *bytes.Buffer, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(value)
+}
+
+// logfmtValue 字符串包含空格、引号或等号时加引号，对象和数组按 JSON 加引号
+func logfmtValue(raw json.RawMessage) string {
+	var s string
+	// null 解码到字符串时不会返回错误，需要和数字一样原样输出
+	if err := json.Unmarshal(raw, &s); err != nil || string(raw) == "null" {
+		// 数字、布尔值和 null 原样输出，对象和数组作为字符串
+		if len(raw) > 0 && (raw[0] == '{' || raw[0] == '[') {
+			var compact bytes.Buffer
+			if json.Compact(&compact, raw) == nil {
+				return strconv.Quote(compact.String())
+			}
+		}
+		return string(raw)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
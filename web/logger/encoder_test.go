@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEncoding(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    Encoding
+		wantErr bool
+	}{
+		{input: "json", want: EncodingJSON},
+		{input: "Console", want: EncodingConsole},
+		{input: " logfmt ", want: EncodingLogfmt},
+		{input: "text", want: EncodingConsole},
+		{input: "xml", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			enc, err := ParseEncoding(tc.input)
+			if tc.wantErr {
+				assert.EqualError(t, err, `logger: unknown encoding "`+tc.input+`"`)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, enc)
+		})
+	}
+}
+
+func TestLogfmtWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &logfmtWriter{out: &out}
+
+	line := `{"user":"tom","level":"info","n":3,"ok":true,"nil":null,"tags":["a", "b"],"meta":{"k": "v"},` +
+		`"empty":"","quote":"say \"hi\"","eq":"a=b","path":"C:\\tmp","time":"2025-01-01T00:00:00Z","message":"hello world"}` + "\n"
+	n, err := w.Write([]byte(line))
+	require.NoError(t, err)
+	assert.Equal(t, len(line), n)
+
+	// time、level 和 msg 排在最前面，其余字段保持原有顺序
+	want := `time=2025-01-01T00:00:00Z level=info msg="hello world" user=tom n=3 ok=true nil=null ` +
+		`tags="[\"a\",\"b\"]" meta="{\"k\":\"v\"}" empty="" quote="say \"hi\"" eq="a=b" path="C:\\tmp"` + "\n"
+	assert.Equal(t, want, out.String())
+
+	// 不是 JSON 对象时原样写入
+	out.Reset()
+	_, err = w.Write([]byte("plain text\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "plain text\n", out.String())
+}
+
+func TestNewLogger_Logfmt(t *testing.T) {
+	var out bytes.Buffer
+	log := NewLogger(WithOutput(&out), WithEncoding(EncodingLogfmt))
+	log.Info("started", String("addr", ":8080"), Int("workers", 4))
+
+	line := out.String()
+	assert.True(t, strings.HasPrefix(line, "time="), line)
+	assert.Contains(t, line, ` level=info msg=started addr=:8080 workers=4`+"\n")
+}
+
+// syncCounter 记录 Sync 调用次数的输出
+type syncCounter struct {
+	bytes.Buffer
+	syncs int
+	err   error
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return s.err
+}
+
+func TestLevelRouter(t *testing.T) {
+	def, warn, errOut := &syncCounter{}, &syncCounter{}, &syncCounter{}
+	log := NewLogger(
+		WithLevel(DebugLevel),
+		WithOutput(def),
+		WithLevelOutput(WarnLevel, warn),
+		WithLevelOutput(ErrorLevel, errOut),
+		WithEncoding(EncodingLogfmt),
+	)
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+
+	// 日志写入级别最接近的输出，每个输出都使用相同的格式
+	assert.Equal(t, 2, strings.Count(def.String(), "\n"))
+	assert.Contains(t, def.String(), "msg=debug")
+	assert.Contains(t, def.String(), "msg=info")
+	assert.Equal(t, 1, strings.Count(warn.String(), "\n"))
+	assert.Contains(t, warn.String(), "level=warn msg=warn")
+	assert.Equal(t, 1, strings.Count(errOut.String(), "\n"))
+	assert.Contains(t, errOut.String(), "level=error msg=error")
+
+	// Sync 刷新所有输出，返回第一个错误
+	warn.err = errors.New("disk full")
+	assert.EqualError(t, Sync(log), "disk full")
+	assert.Equal(t, 1, def.syncs)
+	assert.Equal(t, 1, warn.syncs)
+	assert.Equal(t, 1, errOut.syncs)
+}
+
+func TestLogLevel_String(t *testing.T) {
+	assert.Equal(t, "debug", DebugLevel.String())
+	assert.Equal(t, "warn", WarnLevel.String())
+	assert.Equal(t, "fatal", FatalLevel.String())
+	assert.Equal(t, "level(42)", LogLevel(42).String())
+
+	level, err := ParseLevel("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, level)
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Entry 传给 Hook 的日志条目
+type Entry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	// Fields 包括通过 WithField 等方法添加的字段和这一条日志的字段
+	Fields []Field
+}
+
+// MarshalJSON 输出与 JSON 格式日志相同的字段，error 类型的值转换为字符串
+func (e Entry) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(e.Fields)+3)
+	for _, f := range e.Fields {
+		m[f.Key] = fieldValue(f.Value)
+	}
+	m["time"] = e.Time.Format(time.RFC3339Nano)
+	m["level"] = e.Level.String()
+	m["message"] = e.Message
+	return json.Marshal(m)
+}
+
+// fieldValue 将 error 转换为字符串，其他值原样返回
+func fieldValue(v any) any {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+// Hook 每条日志写入输出之前调用，用于将日志发送到外部系统。
+// Fire 在记录日志的协程中同步调用，耗时的操作应该使用 BatchHook 异步处理
+type Hook interface {
+	Fire(entry Entry) error
+}
+
+// HookFunc 函数形式的 Hook
+type HookFunc func(entry Entry) error
+
+func (f HookFunc) Fire(entry Entry) error {
+	return f(entry)
+}
+
+// WithHook 添加日志钩子，同一条日志按添加的顺序调用
+func WithHook(hook Hook) Option {
+	return func(cfg *LogConfig) {
+		cfg.Hooks = append(cfg.Hooks, hook)
+	}
+}
+
+// fireHooks 调用所有钩子，钩子返回的错误写入标准错误，避免再次进入日志造成循环
+func (l *zerologLogger) fireHooks(level LogLevel, msg string, fields []Field) {
+	if len(l.hooks) == 0 {
+		return
+	}
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	entry := Entry{Time: time.Now(), Level: level, Message: msg, Fields: all}
+	for _, hook := range l.hooks {
+		if err := hook.Fire(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: hook failed: %v\n", err)
+		}
+	}
+}
+
+// syncHooks 刷新实现了 Syncer 的钩子
+func (l *zerologLogger) syncHooks() error {
+	var firstErr error
+	for _, hook := range l.hooks {
+		if s, ok := hook.(Syncer); ok {
+			if err := s.Sync(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -64,6 +64,19 @@ type LogConfig struct {
 	TimeFormat string
 	Async      bool
 	BufferSize int
+
+	// Encoding 输出格式，默认为 JSON
+	Encoding Encoding
+	// LevelOutputs 按级别选择的输出，没有匹配的级别时使用 Output
+	LevelOutputs map[LogLevel]io.Writer
+
+	Caller          bool
+	CallerSkip      int
+	Stacktrace      bool
+	StacktraceLevel LogLevel
+
+	// Hooks 每条日志都会调用的钩子
+	Hooks []Hook
 }
 
 // WithLevel 设置日志级别选项
@@ -80,6 +93,29 @@ func WithOutput(w io.Writer) Option {
 	}
 }
 
+// WithEncoding 设置输出格式，可选 EncodingJSON、EncodingConsole 和 EncodingLogfmt
+func WithEncoding(enc Encoding) Option {
+	return func(cfg *LogConfig) {
+		cfg.Encoding = enc
+	}
+}
+
+// WithLevelOutput 将级别不低于 level 的日志写入 w，而不是 WithOutput 设置的输出。
+// 多次调用时日志写入级别最接近的输出，例如错误写入标准错误，其他日志写入标准输出：
+//
+//	logger.NewLogger(
+//		logger.WithOutput(os.Stdout),
+//		logger.WithLevelOutput(logger.ErrorLevel, os.Stderr),
+//	)
+func WithLevelOutput(level LogLevel, w io.Writer) Option {
+	return func(cfg *LogConfig) {
+		if cfg.LevelOutputs == nil {
+			cfg.LevelOutputs = make(map[LogLevel]io.Writer)
+		}
+		cfg.LevelOutputs[level] = w
+	}
+}
+
 // WithTimeFormat 设置时间格式选项
 func WithTimeFormat(format string) Option {
 	return func(cfg *LogConfig) {
@@ -102,6 +138,7 @@ func defaultConfig() *LogConfig {
 	return &LogConfig{
 		Level:      InfoLevel,
 		TimeFormat: time.RFC3339,
+		Encoding:   EncodingJSON,
 		Async:      false,
 		BufferSize: 1024,
 	}
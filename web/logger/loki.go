@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiOption LokiSink 的选项
+type LokiOption func(*LokiSink)
+
+// WithLokiTenant 设置多租户模式下的租户，对应 X-Scope-OrgID 请求头
+func WithLokiTenant(tenant string) LokiOption {
+	return func(s *LokiSink) {
+		s.tenant = tenant
+	}
+}
+
+// WithLokiBasicAuth 设置 Basic 认证，用于 Grafana Cloud 等需要认证的服务
+func WithLokiBasicAuth(username, password string) LokiOption {
+	return func(s *LokiSink) {
+		s.username, s.password = username, password
+	}
+}
+
+// WithLokiHTTPClient 设置发送请求使用的 HTTP 客户端
+func WithLokiHTTPClient(client *http.Client) LokiOption {
+	return func(s *LokiSink) {
+		s.client = client
+	}
+}
+
+// LokiSink 通过 Grafana Loki 的 push API 发送日志，每条日志的内容为 JSON，
+// 标签为创建时指定的标签加上 level
+type LokiSink struct {
+	url      string
+	labels   map[string]string
+	tenant   string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewLokiSink 创建发送到 addr 的 LokiSink，addr 为 Loki 的地址，例如 http://loki:3100
+func NewLokiSink(addr string, labels map[string]string, opts ...LokiOption) *LokiSink {
+	s := &LokiSink{
+		url:    strings.TrimRight(addr, "/") + "/loki/api/v1/push",
+		labels: labels,
+		client: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// lokiStream push API 中的一个日志流
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Send 按级别分组发送日志
+func (s *LokiSink) Send(ctx context.Context, entries []Entry) error {
+	streams := make(map[LogLevel]*lokiStream)
+	var order []LogLevel
+	for _, entry := range entries {
+		stream, ok := streams[entry.Level]
+		if !ok {
+			labels := make(map[string]string, len(s.labels)+1)
+			for k, v := range s.labels {
+				labels[k] = v
+			}
+			labels["level"] = entry.Level.String()
+			stream = &lokiStream{Stream: labels}
+			streams[entry.Level] = stream
+			order = append(order, entry.Level)
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("logger: loki: %w", err)
+		}
+		ts := entry.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(line)})
+	}
+
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, level := range order {
+		payload.Streams = append(payload.Streams, streams[level])
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("logger: loki: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("logger: loki: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", s.tenant)
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("logger: loki: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("logger: loki: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
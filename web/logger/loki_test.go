@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiSink_Send(t *testing.T) {
+	type pushRequest struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+
+	var (
+		got    pushRequest
+		header http.Header
+		path   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, header = r.URL.Path, r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink := NewLokiSink(srv.URL+"/", map[string]string{"app": "api"},
+		WithLokiTenant("team-a"),
+		WithLokiBasicAuth("user", "secret"),
+		WithLokiHTTPClient(srv.Client()),
+	)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.Send(context.Background(), []Entry{
+		{Time: now, Level: InfoLevel, Message: "started"},
+		{Time: now.Add(time.Second), Level: ErrorLevel, Message: "failed", Fields: []Field{String("user", "tom")}},
+		{Time: now.Add(2 * time.Second), Level: InfoLevel, Message: "done"},
+	}))
+
+	assert.Equal(t, "/loki/api/v1/push", path)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "team-a", header.Get("X-Scope-OrgID"))
+	username, password, ok := (&http.Request{Header: header}).BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+
+	// 按级别分组，保持第一次出现的顺序
+	require.Len(t, got.Streams, 2)
+	assert.Equal(t, map[string]string{"app": "api", "level": "info"}, got.Streams[0].Stream)
+	assert.Equal(t, map[string]string{"app": "api", "level": "error"}, got.Streams[1].Stream)
+	require.Len(t, got.Streams[0].Values, 2)
+	require.Len(t, got.Streams[1].Values, 1)
+
+	value := got.Streams[1].Values[0]
+	assert.Equal(t, strconv.FormatInt(now.Add(time.Second).UnixNano(), 10), value[0])
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(value[1]), &line))
+	assert.Equal(t, "failed", line["message"])
+	assert.Equal(t, "tom", line["user"])
+	assert.Equal(t, strconv.FormatInt(now.Add(2*time.Second).UnixNano(), 10), got.Streams[0].Values[1][0])
+}
+
+func TestLokiSink_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry too far behind", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	sink := NewLokiSink(srv.URL, nil)
+	err := sink.Send(context.Background(), []Entry{{Level: InfoLevel, Message: "late"}})
+	assert.EqualError(t, err, "logger: loki: 400 Bad Request: entry too far behind")
+}
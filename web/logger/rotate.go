@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 备份文件名中的时间格式，不包含冒号以兼容 Windows
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingWriter 按大小切分的日志文件。当前文件写满后重命名为带有时间的备份文件，
+// 例如 app.log 切分为 app-2025-03-14T10-00-00.000.log，再创建新的 app.log
+type RotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	file *os.File
+	size int64
+}
+
+// NewRotatingWriter 打开 path 对应的日志文件，目录不存在时创建。
+// maxSize 为单个文件的最大字节数，maxBackups 为保留的备份文件数量，maxAge 为备份文件的最长保留时间，
+// 取值为0时表示不限制
+//
+//	w, err := logger.NewRotatingWriter("logs/app.log", 100<<20, 7, 30*24*time.Hour)
+func NewRotatingWriter(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*RotatingWriter, error) {
+	w := &RotatingWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAge:     maxAge,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("logger: create log directory: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if err := w.prune(); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write 写入日志，写入后超过最大大小时先切分文件。单条日志超过最大大小时仍然完整写入新文件
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即切分文件，可以用于按天切分等外部触发的场景
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Sync 将文件内容刷新到磁盘
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭当前文件，之后的写入返回 os.ErrClosed
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open 以追加模式打开当前文件
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logger: stat log file: %w", err)
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为备份文件并打开新文件，调用时需要持有锁
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("logger: close log file: %w", err)
+	}
+	w.file = nil
+	backup := w.backupName(time.Now())
+	if err := os.Rename(w.path, backup); err != nil && !os.IsNotExist(err) {
+		// 重命名失败时继续写入原文件，避免丢失日志
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("logger: rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.prune()
+}
+
+// backupName 返回不存在的备份文件名
+func (w *RotatingWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.Format(backupTimeFormat), i, ext))
+	}
+}
+
+// nameParts 返回日志文件所在的目录、备份文件名前缀和扩展名
+func (w *RotatingWriter) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// prune 删除超出数量或时间限制的备份文件
+func (w *RotatingWriter) prune() error {
+	if w.maxBackups <= 0 && w.maxAge <= 0 {
+		return nil
+	}
+	dir, prefix, ext := w.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("logger: list log directory: %w", err)
+	}
+
+	type backup struct {
+		path string
+		time time.Time
+		seq  int // 同一毫秒内切分的序号
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		seq, _ := strconv.Atoi(strings.TrimPrefix(stamp[len(backupTimeFormat):], "."))
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].seq > backups[j].seq
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+
+	cutoff := time.Now().Add(-w.maxAge)
+	var firstErr error
+	for i, b := range backups {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+				firstErr = fmt.Errorf("logger: remove old log file: %w", err)
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backups 返回目录中 app- 开头的备份文件名
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "app-") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingWriter_RotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "app.log")
+	w, err := NewRotatingWriter(path, 10, 0, 0)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("abc\n"))
+	require.NoError(t, err)
+	assert.Empty(t, backups(t, filepath.Dir(path)))
+
+	// 超过最大大小时先切分再写入
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	// 单条日志超过最大大小时完整写入新文件
+	_, err = w.Write([]byte("a very long line\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	names := backups(t, filepath.Dir(path))
+	require.Len(t, names, 2)
+	for _, name := range names {
+		assert.Regexp(t, `^app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}(\.\d+)?\.log$`, name)
+	}
+	contents := []string{
+		readFile(t, filepath.Join(filepath.Dir(path), names[0])),
+		readFile(t, filepath.Join(filepath.Dir(path), names[1])),
+	}
+	sort.Strings(contents)
+	assert.Equal(t, []string{"first\nabc\n", "second\n"}, contents)
+	assert.Equal(t, "a very long line\n", readFile(t, path))
+
+	_, err = w.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o644))
+
+	// 已有的内容计入文件大小
+	w, err := NewRotatingWriter(path, 12, 0, 0)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("next\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "next\n", readFile(t, path))
+	assert.Len(t, backups(t, filepath.Dir(path)), 1)
+}
+
+func TestRotatingWriter_BackupName(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotatingWriter{path: filepath.Join(dir, "app.log")}
+	now := time.Date(2025, 3, 14, 10, 0, 0, 0, time.Local)
+
+	name := w.backupName(now)
+	assert.Equal(t, filepath.Join(dir, "app-2025-03-14T10-00-00.000.log"), name)
+
+	// 同一毫秒内切分时追加序号
+	require.NoError(t, os.WriteFile(name, nil, 0o644))
+	assert.Equal(t, filepath.Join(dir, "app-2025-03-14T10-00-00.000.1.log"), w.backupName(now))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-2025-03-14T10-00-00.000.1.log"), nil, 0o644))
+	assert.Equal(t, filepath.Join(dir, "app-2025-03-14T10-00-00.000.2.log"), w.backupName(now))
+}
+
+// writeBackup 创建时间为 t 的备份文件
+func writeBackup(t *testing.T, dir string, at time.Time, suffix string) string {
+	t.Helper()
+	name := "app-" + at.Format(backupTimeFormat) + suffix + ".log"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644))
+	return name
+}
+
+func TestRotatingWriter_PruneByCount(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	oldest := writeBackup(t, dir, now.Add(-3*time.Hour), "")
+	older := writeBackup(t, dir, now.Add(-2*time.Hour), "")
+	sameTime := writeBackup(t, dir, now.Add(-time.Hour), "")
+	newest := writeBackup(t, dir, now.Add(-time.Hour), ".1")
+	// 不是备份文件的文件不会被删除
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-notes.log"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), nil, 0o644))
+
+	w, err := NewRotatingWriter(filepath.Join(dir, "app.log"), 0, 2, 0)
+	require.NoError(t, err)
+	defer w.Close()
+
+	names := backups(t, dir)
+	assert.Contains(t, names, newest)
+	assert.Contains(t, names, sameTime)
+	assert.NotContains(t, names, older)
+	assert.NotContains(t, names, oldest)
+	assert.Contains(t, names, "app-notes.log")
+	assert.FileExists(t, filepath.Join(dir, "other.log"))
+}
+
+func TestRotatingWriter_PruneByAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	expired := writeBackup(t, dir, now.Add(-48*time.Hour), "")
+	recent := writeBackup(t, dir, now.Add(-time.Hour), "")
+
+	w, err := NewRotatingWriter(filepath.Join(dir, "app.log"), 5, 0, 24*time.Hour)
+	require.NoError(t, err)
+	names := backups(t, dir)
+	assert.Equal(t, []string{recent}, names)
+
+	// 切分时同样清理
+	writeBackup(t, dir, now.Add(-72*time.Hour), "")
+	_, err = w.Write([]byte("line\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	require.NoError(t, w.Close())
+
+	names = backups(t, dir)
+	assert.Len(t, names, 2)
+	assert.Contains(t, names, recent)
+	assert.NotContains(t, names, expired)
+}
@@ -0,0 +1,58 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogSink 将日志发送到 syslog，日志级别对应 syslog 的严重程度，内容为 JSON
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink 连接 syslog，network 和 raddr 为空时连接本机的 syslog 服务，tag 通常为应用名称
+//
+//	sink, err := logger.NewSyslogSink("udp", "syslog.internal:514", "api")
+func NewSyslogSink(network, raddr, tag string) (*SyslogSink, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, fmt.Errorf("logger: syslog: %w", err)
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+// Send 逐条发送日志
+func (s *SyslogSink) Send(_ context.Context, entries []Entry) error {
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("logger: syslog: %w", err)
+		}
+		msg := string(line)
+		switch entry.Level {
+		case DebugLevel:
+			err = s.w.Debug(msg)
+		case InfoLevel:
+			err = s.w.Info(msg)
+		case WarnLevel:
+			err = s.w.Warning(msg)
+		case ErrorLevel:
+			err = s.w.Err(msg)
+		default:
+			err = s.w.Crit(msg)
+		}
+		if err != nil {
+			return fmt.Errorf("logger: syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 关闭 syslog 连接
+func (s *SyslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,46 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink_Send(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "api")
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Send(context.Background(), []Entry{
+		{Level: DebugLevel, Message: "debug"},
+		{Level: InfoLevel, Message: "info"},
+		{Level: WarnLevel, Message: "warn"},
+		{Level: ErrorLevel, Message: "error"},
+		{Level: FatalLevel, Message: "fatal"},
+	}))
+
+	// 优先级为 LOG_USER(8) 加上对应的严重程度
+	wantPriorities := []string{"<15>", "<14>", "<12>", "<11>", "<10>"}
+	wantMessages := []string{"debug", "info", "warn", "error", "fatal"}
+	buf := make([]byte, 4096)
+	for i := range wantPriorities {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		packet := string(buf[:n])
+		assert.True(t, strings.HasPrefix(packet, wantPriorities[i]), packet)
+		assert.Contains(t, packet, " api[")
+		assert.Contains(t, packet, `"message":"`+wantMessages[i]+`"`)
+	}
+}
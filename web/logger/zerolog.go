@@ -12,11 +12,21 @@ import (
 // zerologLogger 使用 zerolog 实现的日志记录器
 type zerologLogger struct {
 	zlog  zerolog.Logger
+	out   io.Writer // 当前的输出，用于 Sync
 	level LogLevel
 	async bool
 	mu    sync.Mutex
 	ch    chan *logEvent
 	wg    sync.WaitGroup
+
+	encoding        Encoding
+	timeFormat      string
+	caller          bool
+	callerSkip      int
+	stacktrace      bool
+	stacktraceLevel LogLevel
+	hooks           []Hook
+	fields          []Field // 通过 WithField 等方法添加的字段，传给 Hook
 }
 
 // logEvent 表示一个异步日志事件
@@ -35,6 +45,7 @@ func init() {
 	zlog := zerolog.New(os.Stderr).With().Timestamp().Logger()
 	defaultLogger = &zerologLogger{
 		zlog:  zlog,
+		out:   os.Stderr,
 		level: InfoLevel,
 		async: false,
 	}
@@ -57,17 +68,27 @@ func NewLogger(opts ...Option) Logger {
 	} else {
 		output = os.Stderr
 	}
+	// 按级别选择输出并转换格式，zerolog 写出的总是 JSON
+	router := newLevelRouter(cfg.Encoding, cfg.TimeFormat, output, cfg.LevelOutputs)
 
 	// 创建 zerolog 日志记录器
-	zlog := zerolog.New(output).With().Timestamp().Logger()
+	zlog := zerolog.New(router).With().Timestamp().Logger()
 
 	// 根据配置设置日志级别
 	setZerologLevel(&zlog, cfg.Level)
 
 	logger := &zerologLogger{
-		zlog:  zlog,
-		level: cfg.Level,
-		async: cfg.Async,
+		zlog:            zlog,
+		out:             router,
+		level:           cfg.Level,
+		async:           cfg.Async,
+		encoding:        cfg.Encoding,
+		timeFormat:      cfg.TimeFormat,
+		caller:          cfg.Caller,
+		callerSkip:      cfg.CallerSkip,
+		stacktrace:      cfg.Stacktrace,
+		stacktraceLevel: cfg.StacktraceLevel,
+		hooks:           cfg.Hooks,
 	}
 
 	// 如果启用异步，初始化通道和工作协程
@@ -133,6 +154,8 @@ func (l *zerologLogger) Debug(msg string, fields ...Field) {
 	if l.level > DebugLevel {
 		return
 	}
+	fields = l.decorate(DebugLevel, fields)
+	l.fireHooks(DebugLevel, msg, fields)
 
 	if l.async {
 		l.sendAsync(DebugLevel, msg, fields)
@@ -151,6 +174,8 @@ func (l *zerologLogger) Info(msg string, fields ...Field) {
 	if l.level > InfoLevel {
 		return
 	}
+	fields = l.decorate(InfoLevel, fields)
+	l.fireHooks(InfoLevel, msg, fields)
 
 	if l.async {
 		l.sendAsync(InfoLevel, msg, fields)
@@ -169,6 +194,8 @@ func (l *zerologLogger) Warn(msg string, fields ...Field) {
 	if l.level > WarnLevel {
 		return
 	}
+	fields = l.decorate(WarnLevel, fields)
+	l.fireHooks(WarnLevel, msg, fields)
 
 	if l.async {
 		l.sendAsync(WarnLevel, msg, fields)
@@ -187,6 +214,8 @@ func (l *zerologLogger) Error(msg string, fields ...Field) {
 	if l.level > ErrorLevel {
 		return
 	}
+	fields = l.decorate(ErrorLevel, fields)
+	l.fireHooks(ErrorLevel, msg, fields)
 
 	if l.async {
 		l.sendAsync(ErrorLevel, msg, fields)
@@ -205,6 +234,10 @@ func (l *zerologLogger) Fatal(msg string, fields ...Field) {
 	if l.level > FatalLevel {
 		return
 	}
+	fields = l.decorate(FatalLevel, fields)
+	l.fireHooks(FatalLevel, msg, fields)
+	// 写入日志后进程会退出，先发送钩子中缓冲的日志
+	_ = l.syncHooks()
 
 	if l.async {
 		l.sendAsync(FatalLevel, msg, fields)
@@ -248,14 +281,17 @@ func (l *zerologLogger) WithContext(ctx context.Context) Logger {
 
 	newLogger := &zerologLogger{
 		zlog:  l.zlog.With().Logger(),
+		out:   l.out,
 		level: l.level,
 		async: l.async,
 		ch:    l.ch,
 	}
+	newLogger.copyOptions(l)
 
 	// 从上下文中获取请求ID等信息
 	if reqID, ok := ctx.Value("request_id").(string); ok {
 		newLogger.zlog = newLogger.zlog.With().Str("request_id", reqID).Logger()
+		newLogger.fields = appendFields(l.fields, String("request_id", reqID))
 	}
 
 	return newLogger
@@ -265,10 +301,13 @@ func (l *zerologLogger) WithContext(ctx context.Context) Logger {
 func (l *zerologLogger) WithField(key string, value interface{}) Logger {
 	newLogger := &zerologLogger{
 		zlog:  l.zlog.With().Interface(key, value).Logger(),
+		out:   l.out,
 		level: l.level,
 		async: l.async,
 		ch:    l.ch,
 	}
+	newLogger.copyOptions(l)
+	newLogger.fields = appendFields(l.fields, Field{Key: key, Value: value})
 	return newLogger
 }
 
@@ -283,10 +322,13 @@ func (l *zerologLogger) WithFields(fields ...Field) Logger {
 
 	newLogger := &zerologLogger{
 		zlog:  ctx.Logger(),
+		out:   l.out,
 		level: l.level,
 		async: l.async,
 		ch:    l.ch,
 	}
+	newLogger.copyOptions(l)
+	newLogger.fields = appendFields(l.fields, fields...)
 	return newLogger
 }
 
@@ -302,7 +344,40 @@ func (l *zerologLogger) SetLevel(level LogLevel) {
 func (l *zerologLogger) SetOutput(w io.Writer) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.zlog = l.zlog.Output(w)
+	// 保留输出格式，按级别选择的输出不再生效
+	l.zlog = l.zlog.Output(encode(l.encoding, l.timeFormat, w))
+	l.out = w
+}
+
+// copyOptions 复制派生日志记录器需要保留的选项
+func (l *zerologLogger) copyOptions(from *zerologLogger) {
+	l.encoding = from.encoding
+	l.timeFormat = from.timeFormat
+	l.caller = from.caller
+	l.callerSkip = from.callerSkip
+	l.stacktrace = from.stacktrace
+	l.stacktraceLevel = from.stacktraceLevel
+	l.hooks = from.hooks
+	l.fields = from.fields
+}
+
+// appendFields 返回新的切片，派生的日志记录器之间不共享底层数组
+func appendFields(base []Field, fields ...Field) []Field {
+	out := make([]Field, 0, len(base)+len(fields))
+	out = append(out, base...)
+	return append(out, fields...)
+}
+
+// Sync 刷新输出和钩子中缓冲的日志，没有实现 Syncer 的输出和钩子直接跳过
+func (l *zerologLogger) Sync() error {
+	l.mu.Lock()
+	out := l.out
+	l.mu.Unlock()
+	hookErr := l.syncHooks()
+	if err := syncWriter(out); err != nil {
+		return err
+	}
+	return hookErr
 }
 
 // setZerologLevel 将内部日志级别转换为 zerolog 级别
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HeaderMethodOverride 覆盖请求方法的请求头
+	HeaderMethodOverride = "X-HTTP-Method-Override"
+	// FormMethodOverride 覆盖请求方法的表单字段
+	FormMethodOverride = "_method"
+)
+
+// overridableMethods 允许通过POST请求覆盖的方法
+var overridableMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// WithMethodOverride 启用请求方法覆盖，POST请求可以通过 X-HTTP-Method-Override 请求头
+// 或 _method 表单字段改写为 PUT、PATCH 或 DELETE，方便HTML表单和只支持GET/POST的旧客户端
+// 覆盖在路由匹配前进行，因此与普通中间件不同，它以服务器选项的形式启用
+func WithMethodOverride() ServerOption {
+	return func(server *HTTPServer) {
+		server.methodOverride = true
+	}
+}
+
+// overrideMethod 根据请求头或表单字段改写POST请求的方法
+func overrideMethod(req *http.Request) {
+	if req.Method != http.MethodPost {
+		return
+	}
+
+	method := req.Header.Get(HeaderMethodOverride)
+	if method == "" && isFormContent(req.Header.Get("Content-Type")) {
+		method = req.PostFormValue(FormMethodOverride)
+	}
+
+	method = strings.ToUpper(method)
+	if overridableMethods[method] {
+		req.Method = method
+	}
+}
+
+// isFormContent 判断请求体是否为表单
+func isFormContent(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded") ||
+		strings.HasPrefix(contentType, "multipart/form-data")
+}
+
+// headResponseWriter 由GET处理函数响应HEAD请求时丢弃响应体
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// setHeadContentLength HEAD请求的响应没有响应体，通过 Content-Length 告知GET响应的长度
+func setHeadContentLength(ctx *Context) {
+	if ctx.Req.Method != http.MethodHead || ctx.Resp.Header().Get("Content-Length") != "" {
+		return
+	}
+	// 1xx、204和304响应不允许携带 Content-Length
+	if ctx.RespStatusCode >= http.StatusOK && ctx.RespStatusCode != http.StatusNoContent &&
+		ctx.RespStatusCode != http.StatusNotModified {
+		ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(ctx.RespData)))
+	}
+}
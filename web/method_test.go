@@ -0,0 +1,163 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_MethodOverride(t *testing.T) {
+	newServer := func(opts ...ServerOption) *HTTPServer {
+		s := NewHTTPServer(opts...)
+		s.Post("/users/:id", func(ctx *Context) { ctx.String(http.StatusOK, "post") })
+		s.Put("/users/:id", func(ctx *Context) { ctx.String(http.StatusOK, "put") })
+		s.Delete("/users/:id", func(ctx *Context) {
+			ctx.String(http.StatusOK, "delete "+ctx.FormValue("name").Value)
+		})
+		return s
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []ServerOption
+		method   string
+		header   string
+		form     string
+		wantBody string
+	}{
+		{
+			name:     "disabled",
+			method:   http.MethodPost,
+			header:   "PUT",
+			wantBody: "post",
+		},
+		{
+			name:     "header",
+			opts:     []ServerOption{WithMethodOverride()},
+			method:   http.MethodPost,
+			header:   "put",
+			wantBody: "put",
+		},
+		{
+			name:     "form field",
+			opts:     []ServerOption{WithMethodOverride()},
+			method:   http.MethodPost,
+			form:     "_method=DELETE&name=tom",
+			wantBody: "delete tom",
+		},
+		{
+			name:     "form field with write timeout",
+			opts:     []ServerOption{WithMethodOverride(), WithWriteTimeout(time.Second)},
+			method:   http.MethodPost,
+			form:     "_method=DELETE&name=abc",
+			wantBody: "delete abc",
+		},
+		{
+			name:     "method not overridable",
+			opts:     []ServerOption{WithMethodOverride()},
+			method:   http.MethodPost,
+			header:   "GET",
+			wantBody: "post",
+		},
+		{
+			name:     "only post is overridden",
+			opts:     []ServerOption{WithMethodOverride()},
+			method:   http.MethodPut,
+			header:   "DELETE",
+			wantBody: "put",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/users/1", strings.NewReader(tc.form))
+			if tc.header != "" {
+				req.Header.Set(HeaderMethodOverride, tc.header)
+			}
+			if tc.form != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			resp := httptest.NewRecorder()
+			newServer(tc.opts...).ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestServer_AutoHead(t *testing.T) {
+	s := NewHTTPServer()
+	var called []string
+	s.Use("GET", "/users", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			called = append(called, "get middleware")
+			next(ctx)
+		}
+	})
+	s.Get("/users", func(ctx *Context) {
+		called = append(called, ctx.Req.Method)
+		ctx.SetHeader("X-Total", "2")
+		ctx.String(http.StatusOK, "tom,jerry")
+	})
+	s.Head("/orders", func(ctx *Context) {
+		ctx.SetHeader("X-Head", "explicit")
+		ctx.RespStatusCode = http.StatusNoContent
+	})
+	s.Get("/orders", func(ctx *Context) {
+		ctx.String(http.StatusOK, "orders")
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodHead, "/users", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, "2", resp.Header().Get("X-Total"))
+	assert.Equal(t, "9", resp.Header().Get("Content-Length"))
+	assert.Equal(t, []string{"get middleware", http.MethodHead}, called)
+
+	// 显式注册的HEAD路由优先
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodHead, "/orders", nil))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "explicit", resp.Header().Get("X-Head"))
+	assert.Empty(t, resp.Header().Get("Content-Length"))
+
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodHead, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestRouteGroup_HeadUsesGroupMiddleware(t *testing.T) {
+	s := NewHTTPServer()
+	files := s.Group("/files")
+	files.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			if ctx.GetHeader("Authorization") == "" {
+				ctx.RespStatusCode = http.StatusUnauthorized
+				return
+			}
+			next(ctx)
+		}
+	})
+	files.Head("/:id", func(ctx *Context) {
+		ctx.SetHeader("Upload-Offset", "42")
+		ctx.RespStatusCode = http.StatusOK
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodHead, "/files/1", nil))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Empty(t, resp.Header().Get("Upload-Offset"))
+
+	req := httptest.NewRequest(http.MethodHead, "/files/1", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "42", resp.Header().Get("Upload-Offset"))
+}
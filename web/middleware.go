@@ -1,8 +1,11 @@
 package web
 
 import (
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/web/router"
 )
 
 // HandlerFunc 定义请求处理函数
@@ -44,6 +47,33 @@ type MiddlewareWithPath struct {
 	Type       MiddlewareType
 	Order      int
 	Source     MiddlewareSource
+	Name       string   // 中间件名称，路由可以通过 SkipMiddleware 按名称跳过
+	Priority   int      // 优先级，数值越大越先执行，默认为0
+	Excepts    []string // 跳过中间件的路径，匹配规则与中间件路径相同
+}
+
+// middlewareOptions 通过中间件管理器注册时的附加选项
+type middlewareOptions struct {
+	name     string
+	priority int
+	excepts  []middlewareExcept
+}
+
+// middlewareExcept 跳过中间件的方法和路径，method为空时对所有方法生效
+type middlewareExcept struct {
+	method string
+	path   string
+}
+
+// exceptPaths 返回对指定方法生效的跳过路径
+func (o middlewareOptions) exceptPaths(method string) []string {
+	var paths []string
+	for _, e := range o.excepts {
+		if e.method == "" || e.method == method {
+			paths = append(paths, e.path)
+		}
+	}
+	return paths
 }
 
 // WithErrorHandling 将中间件转换为带错误处理的中间件
@@ -62,7 +92,7 @@ func WithErrorHandling(m Middleware) ErrorHandlingMiddleware {
 // classifyMiddlewareType 根据路径类型分类中间件
 func classifyMiddlewareType(path string) MiddlewareType {
 	// Static paths don't have special characters
-	if !strings.Contains(path, ":") && !strings.Contains(path, "*") {
+	if !strings.ContainsAny(path, ":*{") {
 		return StaticMiddleware
 	}
 
@@ -71,8 +101,8 @@ func classifyMiddlewareType(path string) MiddlewareType {
 		return RegexMiddleware
 	}
 
-	// 检查参数路由
-	if strings.Contains(path, ":") {
+	// 检查参数路由，包括 {id:int} 形式的约束参数
+	if strings.ContainsAny(path, ":{") {
 		return ParamMiddleware
 	}
 
@@ -97,7 +127,7 @@ func pathMatchesStaticPattern(reqPath, middlewarePath string) bool {
 
 	// 如果中间件路径没有通配符，考虑只匹配一部分
 	// 例如 /users/profile 可以匹配 /users 中间件
-	if !strings.Contains(middlewarePath, "*") && !strings.Contains(middlewarePath, ":") {
+	if !strings.ContainsAny(middlewarePath, ":*{") {
 		return strings.HasPrefix(reqPath, middlewarePath+"/") || reqPath == middlewarePath
 	}
 
@@ -107,7 +137,7 @@ func pathMatchesStaticPattern(reqPath, middlewarePath string) bool {
 // pathMatchesParamPattern 检验参数路径匹配
 func pathMatchesParamPattern(reqPath, patternPath string) bool {
 	// 如果pattern没有参数路径，直接返回
-	if !strings.Contains(patternPath, ":") {
+	if !strings.ContainsAny(patternPath, ":{") {
 		return false
 	}
 
@@ -125,6 +155,14 @@ func pathMatchesParamPattern(reqPath, patternPath string) bool {
 			continue
 		}
 
+		// 约束参数需要满足约束
+		if _, constraint, ok := router.ParseConstraintSegment(segment); ok {
+			if c, found := router.LookupConstraint(constraint); found && !c.Match(reqSegments[i]) {
+				return false
+			}
+			continue
+		}
+
 		// 非参数路径需要匹配
 		if segment != reqSegments[i] {
 			return false
@@ -169,20 +207,7 @@ func collectMatchingMiddlewares(middlewares []MiddlewareWithPath, actualPath str
 	var matchingMiddlewares []MiddlewareWithPath
 
 	for _, mw := range middlewares {
-		var matches bool
-
-		switch mw.Type {
-		case StaticMiddleware:
-			matches = pathMatchesStaticPattern(actualPath, mw.Path)
-		case RegexMiddleware:
-			matches = pathMatchesRegexPattern(actualPath, mw.Path)
-		case ParamMiddleware:
-			matches = pathMatchesParamPattern(actualPath, mw.Path)
-		case WildcardMiddleware:
-			matches = pathMatchesWildcardPattern(actualPath, mw.Path)
-		}
-
-		if matches {
+		if middlewarePathMatches(mw.Type, mw.Path, actualPath) && !mw.excepted(actualPath) {
 			matchingMiddlewares = append(matchingMiddlewares, mw)
 		}
 	}
@@ -190,6 +215,43 @@ func collectMatchingMiddlewares(middlewares []MiddlewareWithPath, actualPath str
 	return matchingMiddlewares
 }
 
+// middlewarePathMatches 判断请求路径是否匹配中间件路径
+func middlewarePathMatches(mwType MiddlewareType, pattern, actualPath string) bool {
+	switch mwType {
+	case StaticMiddleware:
+		return pathMatchesStaticPattern(actualPath, pattern)
+	case RegexMiddleware:
+		return pathMatchesRegexPattern(actualPath, pattern)
+	case ParamMiddleware:
+		return pathMatchesParamPattern(actualPath, pattern)
+	case WildcardMiddleware:
+		return pathMatchesWildcardPattern(actualPath, pattern)
+	}
+	return false
+}
+
+// excepted 判断请求路径是否在中间件的跳过列表中
+func (mw MiddlewareWithPath) excepted(actualPath string) bool {
+	for _, path := range mw.Excepts {
+		if middlewarePathMatches(classifyMiddlewareType(path), path, actualPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutNamed 返回去掉指定名称中间件后的列表
+func withoutNamed(middlewares []MiddlewareWithPath, names []string) []MiddlewareWithPath {
+	result := make([]MiddlewareWithPath, 0, len(middlewares))
+	for _, mw := range middlewares {
+		if mw.Name != "" && slices.Contains(names, mw.Name) {
+			continue
+		}
+		result = append(result, mw)
+	}
+	return result
+}
+
 // calculatePathSpecificity 为路径计算特定性分数
 // 分数越高越具体，越先匹配
 func calculatePathSpecificity(path string) int {
@@ -206,7 +268,7 @@ func calculatePathSpecificity(path string) int {
 
 	// 首先优先考虑连续静态路径
 	for _, segment := range segments {
-		if !strings.Contains(segment, ":") && !strings.Contains(segment, "*") {
+		if !strings.ContainsAny(segment, ":*{") {
 			currentContinuousCount++
 			score += continuousStaticBonus * currentContinuousCount
 		} else {
@@ -218,7 +280,7 @@ func calculatePathSpecificity(path string) int {
 	// 其次考虑路径段位置
 	// 越前面的路径段分数越高
 	for i, segment := range segments {
-		if !strings.Contains(segment, ":") && !strings.Contains(segment, "*") {
+		if !strings.ContainsAny(segment, ":*{") {
 			// 只为不连续的静态路径加分
 			if i >= currentContinuousCount {
 				score += segmentPositionValue / (i + 1)
@@ -238,7 +300,7 @@ func calculatePathSpecificity(path string) int {
 			wildcardCount++
 		} else if strings.Contains(segment, "(") && strings.Contains(segment, ")") {
 			regexCount++
-		} else if strings.Contains(segment, ":") {
+		} else if strings.ContainsAny(segment, ":{") {
 			paramCount++
 		} else {
 			staticCount++
@@ -258,9 +320,10 @@ func calculatePathSpecificity(path string) int {
 }
 
 // sortMiddlewares 基于以下原则排序：
-// 1. 首先按来源类型：GlobalSource最先执行
-// 2. 然后按照具体性分数来排序
-// 3. 最后按照先后顺序排序
+// 1. 首先按优先级：数值越大越先执行
+// 2. 然后按来源类型：GlobalSource最先执行
+// 3. 然后按照具体性分数来排序
+// 4. 最后按照先后顺序排序
 func sortMiddlewares(middlewares []MiddlewareWithPath) []MiddlewareWithPath {
 	// 复制一份，不修改原有的中间件列表
 	result := make([]MiddlewareWithPath, len(middlewares))
@@ -268,6 +331,10 @@ func sortMiddlewares(middlewares []MiddlewareWithPath) []MiddlewareWithPath {
 
 	// 根据前面的优先级顺序进行排序
 	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority > result[j].Priority
+		}
+
 		if result[i].Source != result[j].Source {
 			return result[i].Source < result[j].Source
 		}
@@ -293,10 +360,15 @@ func sortMiddlewares(middlewares []MiddlewareWithPath) []MiddlewareWithPath {
 // BuildChain 构建中间件执行链
 func BuildChain(handler HandlerFunc, actualPath string, middlewares []MiddlewareWithPath) HandlerFunc {
 	matchingMiddlewares := collectMatchingMiddlewares(middlewares, actualPath)
-	sortedMiddlewares := sortMiddlewares(matchingMiddlewares)
+	return composeChain(handler, sortMiddlewares(matchingMiddlewares))
+}
 
+// composeChain 按排好的顺序组合中间件和处理函数
+func composeChain(handler HandlerFunc, sortedMiddlewares []MiddlewareWithPath) HandlerFunc {
+	// 每一层之前检查客户端是否断开，断开后终止处理链
+	handler = clientGuard(handler)
 	for i := len(sortedMiddlewares) - 1; i >= 0; i-- {
-		handler = sortedMiddlewares[i].Middleware(handler)
+		handler = clientGuard(sortedMiddlewares[i].Middleware(handler))
 	}
 
 	return func(ctx *Context) {
@@ -63,17 +63,18 @@ func NewWithConfig(config *Config) web.Middleware {
 			// 计算处理时间
 			duration := time.Since(start)
 
-			// 准备响应字段
+			// 准备响应字段，包括处理函数直接写出的响应
+			status := ctx.ResponseStatus()
 			respFields := append([]logger.Field{
-				logger.Int("status", ctx.RespStatusCode),
+				logger.Int("status", status),
 				logger.Int64("duration_ms", duration.Milliseconds()),
-				logger.Int("resp_size", len(ctx.RespData)),
+				logger.Int("resp_size", ctx.ResponseSize()),
 			}, reqFields...)
 
 			// 根据状态码和响应时间选择日志级别
-			if ctx.RespStatusCode >= 500 {
+			if status >= 500 {
 				ctx.Logger().Error("Request failed with server error", respFields...)
-			} else if ctx.RespStatusCode >= 400 {
+			} else if status >= 400 {
 				ctx.Logger().Warn("Request failed with client error", respFields...)
 			} else if duration > config.SlowThreshold {
 				ctx.Logger().Warn("Slow request completed", respFields...)
@@ -0,0 +1,434 @@
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// ErrCacheMiss 缓存中没有对应的响应
+var ErrCacheMiss = errors.New("httpcache: cache miss")
+
+// tagsKey 处理函数通过 Tag 添加的失效标签
+var tagsKey = web.NewCtxKey[[]string]("httpcache", "tags")
+
+// Entry 缓存的响应。Status 为0的条目是 Vary 索引，只记录需要参与缓存键的请求头
+type Entry struct {
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+	Vary    []string    `json:"vary,omitempty"`
+	Tags    []string    `json:"tags,omitempty"`
+	Created time.Time   `json:"created"`
+}
+
+// Store 响应的存储，Set 需要建立 Entry.Tags 到键的关联，InvalidateTags 删除标签关联的所有键
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// Config 响应缓存中间件配置
+type Config struct {
+	// 响应的存储
+	Store Store
+	// 缓存时间，响应带有 Cache-Control 的 s-maxage 或 max-age 时以响应为准，默认1分钟
+	TTL time.Duration
+	// 始终参与缓存键的请求头，响应的 Vary 头列出的请求头会自动加入
+	Vary []string
+	// 所有响应都带有的失效标签，处理函数可以通过 Tag 添加更多
+	Tags []string
+	// 可以缓存的状态码，默认只缓存200
+	Statuses []int
+	// 可以缓存的最大响应体，默认1MB
+	MaxBodySize int
+	// 生成缓存键，默认为 Host 加请求的 URI，删除这个键会使它的所有 Vary 版本失效
+	KeyFunc func(ctx *web.Context) string
+	// 返回true时跳过缓存
+	Skipper func(ctx *web.Context) bool
+	// 是否缓存带有 Authorization 或 Cookie 头的请求，默认不缓存，避免不同用户共享响应
+	CacheAuthorized bool
+	// 标记缓存是否命中的响应头，为空时不设置
+	StatusHeader string
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		TTL:          time.Minute,
+		Statuses:     []int{http.StatusOK},
+		MaxBodySize:  1 << 20,
+		KeyFunc:      DefaultKey,
+		StatusHeader: "X-Cache",
+	}
+}
+
+// New 创建使用 store 缓存 ttl 时间的响应缓存中间件
+func New(store Store, ttl time.Duration) web.Middleware {
+	config := DefaultConfig()
+	config.Store = store
+	config.TTL = ttl
+	return NewWithConfig(config)
+}
+
+// DefaultKey 默认的缓存键
+func DefaultKey(ctx *web.Context) string {
+	return ctx.Req.Host + ctx.Req.URL.RequestURI()
+}
+
+// Tag 为当前请求的响应添加失效标签，之后通过 Store.InvalidateTags 使这些响应失效：
+//
+//	s.Get("/products/:id", func(ctx *web.Context) {
+//		httpcache.Tag(ctx, "product:"+ctx.PathParam("id").Value)
+//		...
+//	}).Middleware(httpcache.New(store, 5*time.Minute))
+//
+//	// 更新商品后
+//	store.InvalidateTags(ctx.Context, "product:"+id)
+func Tag(ctx *web.Context, tags ...string) {
+	current, _ := web.CtxValue(ctx, tagsKey)
+	web.SetCtxValue(ctx, tagsKey, append(current, tags...))
+}
+
+// NewWithConfig 使用自定义配置创建响应缓存中间件
+// 只缓存GET请求的完整响应，HEAD请求可以使用GET请求的缓存；
+// 响应带有 Set-Cookie、Cache-Control: private/no-store 或者以流的方式写出时不会缓存
+func NewWithConfig(config *Config) web.Middleware {
+	if config.Store == nil {
+		panic("httpcache: store is required")
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if len(config.Statuses) == 0 {
+		config.Statuses = []int{http.StatusOK}
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = DefaultKey
+	}
+	vary := canonicalHeaders(config.Vary)
+
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx *web.Context) {
+			method := ctx.Req.Method
+			if (method != http.MethodGet && method != http.MethodHead) ||
+				(config.Skipper != nil && config.Skipper(ctx)) ||
+				(!config.CacheAuthorized && authorized(ctx.Req)) {
+				next(ctx)
+				return
+			}
+
+			reqDirectives := ctx.Req.Header.Get("Cache-Control")
+			key := config.KeyFunc(ctx)
+			if !hasDirective(reqDirectives, "no-cache") && !hasDirective(reqDirectives, "no-store") {
+				entry, err := lookup(ctx, config.Store, key)
+				if err == nil {
+					serve(ctx, entry, config.StatusHeader)
+					return
+				}
+				if !errors.Is(err, ErrCacheMiss) {
+					ctx.Logger().Warn("Failed to read cached response", logger.String("key", key), logger.FieldError(err))
+				}
+			}
+
+			if config.StatusHeader != "" {
+				ctx.Resp.Header().Set(config.StatusHeader, "MISS")
+			}
+			if method != http.MethodGet || hasDirective(reqDirectives, "no-store") {
+				next(ctx)
+				return
+			}
+
+			cw := &captureWriter{ResponseWriter: ctx.Resp, limit: config.MaxBodySize}
+			ctx.Resp = cw
+			defer func() { ctx.Resp = cw.ResponseWriter }()
+
+			next(ctx)
+
+			entry, ttl, ok := capture(ctx, cw, config)
+			if !ok {
+				return
+			}
+			entry.Vary = mergeVary(vary, entry.Header.Values("Vary"))
+			if containsString(entry.Vary, "*") {
+				return
+			}
+			tags, _ := web.CtxValue(ctx, tagsKey)
+			entry.Tags = append(append([]string(nil), config.Tags...), tags...)
+			if err := store(ctx, config.Store, key, entry, ttl); err != nil {
+				ctx.Logger().Warn("Failed to cache response", logger.String("key", key), logger.FieldError(err))
+			}
+		}
+	}
+}
+
+// lookup 查找缓存的响应，存在 Vary 索引时按请求头查找对应的版本
+func lookup(ctx *web.Context, s Store, key string) (*Entry, error) {
+	entry, err := s.Get(ctx.Context, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != 0 {
+		return entry, nil
+	}
+	return s.Get(ctx.Context, variantKey(key, entry.Vary, ctx.Req.Header))
+}
+
+// store 保存响应，需要 Vary 时先保存索引再保存对应的版本
+func store(ctx *web.Context, s Store, key string, entry *Entry, ttl time.Duration) error {
+	if len(entry.Vary) == 0 {
+		return s.Set(ctx.Context, key, entry, ttl)
+	}
+	index := &Entry{Vary: entry.Vary, Tags: entry.Tags, Created: entry.Created}
+	if err := s.Set(ctx.Context, key, index, ttl); err != nil {
+		return err
+	}
+	return s.Set(ctx.Context, variantKey(key, entry.Vary, ctx.Req.Header), entry, ttl)
+}
+
+// serve 使用缓存的响应，请求的 If-None-Match 与缓存的 ETag 相同时返回304
+func serve(ctx *web.Context, entry *Entry, statusHeader string) {
+	header := ctx.Resp.Header()
+	for k, v := range entry.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	if statusHeader != "" {
+		header.Set(statusHeader, "HIT")
+	}
+	age := int(ctx.Now().Sub(entry.Created).Seconds())
+	if age < 0 {
+		age = 0
+	}
+	header.Set("Age", strconv.Itoa(age))
+
+	if etag := entry.Header.Get("ETag"); etag != "" && matchETag(ctx.Req.Header.Get("If-None-Match"), etag) {
+		header.Del("Content-Length")
+		_ = ctx.Bytes(http.StatusNotModified, nil)
+		return
+	}
+	_ = ctx.Bytes(entry.Status, entry.Body)
+}
+
+// capture 从处理结果中取出可以缓存的响应和缓存时间
+func capture(ctx *web.Context, cw *captureWriter, config *Config) (*Entry, time.Duration, bool) {
+	var (
+		status int
+		body   []byte
+	)
+	switch {
+	case cw.status != 0:
+		// 处理函数直接写出了响应
+		if cw.skip {
+			return nil, 0, false
+		}
+		status, body = cw.status, cw.buf.Bytes()
+	case ctx.Writer().Written():
+		// 通过 ctx.Writer() 绕过了中间件写出响应
+		return nil, 0, false
+	default:
+		// RespData 可能引用池化的缓冲区，需要复制
+		status, body = ctx.ResponseStatus(), append([]byte(nil), ctx.RespData...)
+		if len(body) > config.MaxBodySize {
+			return nil, 0, false
+		}
+	}
+	if !containsInt(config.Statuses, status) {
+		return nil, 0, false
+	}
+
+	header := cw.Header().Clone()
+	if _, ok := header["Set-Cookie"]; ok {
+		return nil, 0, false
+	}
+	directives := header.Get("Cache-Control")
+	if hasDirective(directives, "no-store") || hasDirective(directives, "private") {
+		return nil, 0, false
+	}
+	ttl := config.TTL
+	if maxAge, ok := directiveSeconds(directives, "s-maxage"); ok {
+		ttl = maxAge
+	} else if maxAge, ok := directiveSeconds(directives, "max-age"); ok {
+		ttl = maxAge
+	}
+	if ttl <= 0 {
+		return nil, 0, false
+	}
+	if config.StatusHeader != "" {
+		header.Del(config.StatusHeader)
+	}
+	header.Del("Age")
+
+	return &Entry{Status: status, Header: header, Body: body, Created: ctx.Now()}, ttl, true
+}
+
+// authorized 判断请求是否带有用户凭证，会话 Cookie 和 Authorization 一样会让响应因用户而异
+func authorized(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+// variantKey 根据 Vary 列出的请求头生成缓存键
+func variantKey(key string, vary []string, header http.Header) string {
+	values := make(url.Values, len(vary))
+	for _, name := range vary {
+		values[name] = header.Values(name)
+	}
+	return key + "#" + values.Encode()
+}
+
+// mergeVary 合并配置和响应中的 Vary 请求头
+func mergeVary(vary []string, respVary []string) []string {
+	var names []string
+	for _, v := range respVary {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return vary
+	}
+	return canonicalHeaders(append(append([]string(nil), vary...), names...))
+}
+
+// canonicalHeaders 规范化请求头名称，去重并排序
+func canonicalHeaders(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != "*" {
+			name = http.CanonicalHeaderKey(name)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// hasDirective 判断 Cache-Control 中是否有指定的指令
+func hasDirective(header, directive string) bool {
+	for _, d := range strings.Split(header, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveSeconds 读取 Cache-Control 中以秒为单位的指令
+func directiveSeconds(header, directive string) (time.Duration, bool) {
+	for _, d := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(d), "=")
+		if !ok || !strings.EqualFold(name, directive) {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
+
+// matchETag 判断 If-None-Match 是否包含 etag，比较时忽略弱校验前缀
+func matchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// captureWriter 记录处理函数直接写出的响应，刷新、接管连接或超出大小时不再记录
+type captureWriter struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	status int
+	limit  int
+	skip   bool
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status == 0 && (code < 100 || code > 199) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.skip {
+		if w.buf.Len()+len(b) > w.limit {
+			w.skip = true
+			w.buf = bytes.Buffer{}
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) Flush() {
+	w.skip = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.skip = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
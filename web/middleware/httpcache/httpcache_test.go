@@ -0,0 +1,293 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer 创建使用同一个模拟时间的服务器和存储，calls 记录处理函数的执行次数
+func newTestServer(t *testing.T, config *Config, handler web.HandlerFunc) (*web.HTTPServer, *MemoryStore, *clock.Mock, *int) {
+	t.Helper()
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryStore(WithClock(clk))
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.Store = store
+
+	calls := new(int)
+	s := web.NewHTTPServer(web.WithClock(clk))
+	s.Get("/items", func(ctx *web.Context) {
+		*calls++
+		handler(ctx)
+	}).Middleware(NewWithConfig(config))
+	return s, store, clk, calls
+}
+
+func request(s *web.HTTPServer, method string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/items", nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCache_HitAfterMiss(t *testing.T) {
+	s, _, clk, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.String(http.StatusOK, "items")
+	})
+
+	rec := request(s, http.MethodGet, nil)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "items", rec.Body.String())
+
+	// Age 使用服务器的时间来源计算
+	clk.Advance(30 * time.Second)
+	rec = request(s, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "30", rec.Header().Get("Age"))
+	assert.Equal(t, "items", rec.Body.String())
+	assert.Equal(t, 1, *calls)
+
+	// HEAD 请求使用GET请求的缓存
+	rec = request(s, http.MethodHead, nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 1, *calls)
+
+	// 过期后重新执行处理函数
+	clk.Advance(time.Minute)
+	rec = request(s, http.MethodGet, nil)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCache_Credentials(t *testing.T) {
+	testCases := []struct {
+		name      string
+		header    map[string]string
+		authorize bool
+		wantCalls int
+	}{
+		{name: "authorization", header: map[string]string{"Authorization": "Bearer token"}, wantCalls: 3},
+		{name: "cookie", header: map[string]string{"Cookie": "session=abc"}, wantCalls: 3},
+		{name: "cookie with cache authorized", header: map[string]string{"Cookie": "session=abc"}, authorize: true, wantCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.CacheAuthorized = tc.authorize
+			s, _, _, calls := newTestServer(t, config, func(ctx *web.Context) {
+				ctx.String(http.StatusOK, "items")
+			})
+
+			// 带凭证的请求既不读取也不写入缓存
+			request(s, http.MethodGet, nil)
+			request(s, http.MethodGet, tc.header)
+			request(s, http.MethodGet, tc.header)
+			assert.Equal(t, tc.wantCalls, *calls)
+		})
+	}
+}
+
+func TestCache_Vary(t *testing.T) {
+	s, _, _, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.Resp.Header().Set("Vary", "Accept-Language")
+		ctx.String(http.StatusOK, "lang="+ctx.Req.Header.Get("Accept-Language"))
+	})
+
+	for _, lang := range []string{"en", "zh", "en", "zh"} {
+		rec := request(s, http.MethodGet, map[string]string{"Accept-Language": lang})
+		assert.Equal(t, "lang="+lang, rec.Body.String())
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCache_NotModified(t *testing.T) {
+	s, _, _, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.Resp.Header().Set("ETag", `"v1"`)
+		ctx.String(http.StatusOK, "items")
+	})
+
+	request(s, http.MethodGet, nil)
+	rec := request(s, http.MethodGet, map[string]string{"If-None-Match": `W/"v1"`})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Empty(t, rec.Body.String())
+
+	rec = request(s, http.MethodGet, map[string]string{"If-None-Match": `"v2"`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "items", rec.Body.String())
+	assert.Equal(t, 1, *calls)
+}
+
+func TestCache_Bypass(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler web.HandlerFunc
+		header  map[string]string
+	}{
+		{
+			name: "no-store response",
+			handler: func(ctx *web.Context) {
+				ctx.Resp.Header().Set("Cache-Control", "no-store")
+				ctx.String(http.StatusOK, "items")
+			},
+		},
+		{
+			name: "private response",
+			handler: func(ctx *web.Context) {
+				ctx.Resp.Header().Set("Cache-Control", "private, max-age=60")
+				ctx.String(http.StatusOK, "items")
+			},
+		},
+		{
+			name: "set-cookie",
+			handler: func(ctx *web.Context) {
+				ctx.SetCookie(&http.Cookie{Name: "session", Value: "abc"})
+				ctx.String(http.StatusOK, "items")
+			},
+		},
+		{
+			name: "status not cacheable",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusInternalServerError, "failed")
+			},
+		},
+		{
+			name: "over-size body",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusOK, strings.Repeat("x", 2<<20))
+			},
+		},
+		{
+			name: "over-size direct write",
+			handler: func(ctx *web.Context) {
+				ctx.Resp.WriteHeader(http.StatusOK)
+				_, _ = ctx.Resp.Write([]byte(strings.Repeat("x", 2<<20)))
+			},
+		},
+		{
+			name: "flushed",
+			handler: func(ctx *web.Context) {
+				_, _ = ctx.Resp.Write([]byte("chunk"))
+				_ = http.NewResponseController(ctx.Resp).Flush()
+			},
+		},
+		{
+			name: "no-store request",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusOK, "items")
+			},
+			header: map[string]string{"Cache-Control": "no-store"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, store, _, calls := newTestServer(t, nil, tc.handler)
+			request(s, http.MethodGet, tc.header)
+			rec := request(s, http.MethodGet, tc.header)
+			assert.NotEqual(t, "HIT", rec.Header().Get("X-Cache"))
+			assert.Equal(t, 2, *calls)
+			assert.Equal(t, 0, store.Len())
+		})
+	}
+}
+
+func TestCache_DirectWrite(t *testing.T) {
+	s, _, _, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.Resp.Header().Set("Content-Type", "text/plain")
+		ctx.Resp.WriteHeader(http.StatusOK)
+		_, _ = ctx.Resp.Write([]byte("direct"))
+	})
+
+	request(s, http.MethodGet, nil)
+	rec := request(s, http.MethodGet, nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "direct", rec.Body.String())
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestCache_RequestNoCache(t *testing.T) {
+	s, _, _, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.String(http.StatusOK, "items")
+	})
+
+	request(s, http.MethodGet, nil)
+	// no-cache 跳过缓存并刷新
+	rec := request(s, http.MethodGet, map[string]string{"Cache-Control": "no-cache"})
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	rec = request(s, http.MethodGet, nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCache_ResponseMaxAge(t *testing.T) {
+	s, _, clk, calls := newTestServer(t, nil, func(ctx *web.Context) {
+		ctx.Resp.Header().Set("Cache-Control", "public, max-age=300")
+		ctx.String(http.StatusOK, "items")
+	})
+
+	request(s, http.MethodGet, nil)
+	// 响应的 max-age 比默认的1分钟长
+	clk.Advance(2 * time.Minute)
+	rec := request(s, http.MethodGet, nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "120", rec.Header().Get("Age"))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestCache_InvalidateTags(t *testing.T) {
+	config := DefaultConfig()
+	config.Tags = []string{"items"}
+	s, store, _, calls := newTestServer(t, config, func(ctx *web.Context) {
+		ctx.Resp.Header().Set("Vary", "Accept-Language")
+		Tag(ctx, "item:1")
+		ctx.String(http.StatusOK, "items")
+	})
+
+	request(s, http.MethodGet, nil)
+	request(s, http.MethodGet, nil)
+	require.Equal(t, 1, *calls)
+
+	// 处理函数添加的标签
+	require.NoError(t, store.InvalidateTags(context.Background(), "item:1"))
+	rec := request(s, http.MethodGet, nil)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+
+	// 配置的标签
+	require.NoError(t, store.InvalidateTags(context.Background(), "items"))
+	request(s, http.MethodGet, nil)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, 2, store.Len(), "vary index and variant")
+}
+
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	store := NewMemoryStore(WithMaxEntries(2))
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, store.Set(ctx, key, &Entry{Status: http.StatusOK, Tags: []string{"t"}}, time.Minute))
+	}
+
+	assert.Equal(t, 2, store.Len())
+	_, err := store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	_, err = store.Get(ctx, "c")
+	assert.NoError(t, err)
+}
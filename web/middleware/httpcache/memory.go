@@ -0,0 +1,152 @@
+package httpcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+)
+
+// MemoryOption MemoryStore 的选项
+type MemoryOption func(*MemoryStore)
+
+// WithMaxEntries 设置最多缓存的条目数，超出时淘汰最久未使用的条目，默认为10000
+func WithMaxEntries(n int) MemoryOption {
+	return func(s *MemoryStore) {
+		s.maxEntries = n
+	}
+}
+
+// WithClock 设置判断过期使用的时间来源
+func WithClock(c clock.Clock) MemoryOption {
+	return func(s *MemoryStore) {
+		s.clock = c
+	}
+}
+
+// MemoryStore 进程内的响应存储，适合单实例部署
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List // 最近使用的条目在前
+	tags       map[string]map[string]struct{}
+	maxEntries int
+	clock      clock.Clock
+}
+
+// memoryEntry lru 中的元素
+type memoryEntry struct {
+	key     string
+	entry   *Entry
+	expires time.Time
+}
+
+// NewMemoryStore 创建进程内的响应存储
+func NewMemoryStore(opts ...MemoryOption) *MemoryStore {
+	s := &MemoryStore{
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		tags:       make(map[string]map[string]struct{}),
+		maxEntries: 10000,
+		clock:      clock.System(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get 返回缓存的响应，不存在或已过期时返回 ErrCacheMiss
+func (s *MemoryStore) Get(_ context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	me := elem.Value.(*memoryEntry)
+	if !s.clock.Now().Before(me.expires) {
+		s.remove(elem)
+		return nil, ErrCacheMiss
+	}
+	s.lru.MoveToFront(elem)
+	return me.entry, nil
+}
+
+// Set 保存响应并关联 entry.Tags
+func (s *MemoryStore) Set(_ context.Context, key string, entry *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	me := &memoryEntry{key: key, entry: entry, expires: s.clock.Now().Add(ttl)}
+	s.entries[key] = s.lru.PushFront(me)
+	for _, tag := range entry.Tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// Delete 删除指定的键
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := s.entries[key]; ok {
+			s.remove(elem)
+		}
+	}
+	return nil
+}
+
+// InvalidateTags 删除标签关联的所有键
+func (s *MemoryStore) InvalidateTags(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if elem, ok := s.entries[key]; ok {
+				s.remove(elem)
+			}
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// Len 返回缓存的条目数，包括还没有清理的过期条目
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// remove 删除条目和它的标签关联，调用方需要持有锁
+func (s *MemoryStore) remove(elem *list.Element) {
+	me := s.lru.Remove(elem).(*memoryEntry)
+	delete(s.entries, me.key)
+	for _, tag := range me.entry.Tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, me.key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// setScript 保存响应并加入标签集合，标签集合的过期时间不短于其中的键
+var setScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl <= 0 then
+		redis.call('PERSIST', KEYS[i])
+	else
+		local current = redis.call('PTTL', KEYS[i])
+		if existed == 0 or (current >= 0 and current < ttl) then
+			redis.call('PEXPIRE', KEYS[i], ttl)
+		end
+	end
+end
+return 0
+`)
+
+// invalidateScript 原子地删除标签关联的所有键和标签本身
+var invalidateScript = redis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	local members = redis.call('SMEMBERS', tag)
+	for i = 1, #members, 500 do
+		redis.call('DEL', unpack(members, i, math.min(i + 499, #members)))
+	end
+	redis.call('DEL', tag)
+end
+return 0
+`)
+
+// RedisOption RedisStore 的选项
+type RedisOption func(*RedisStore)
+
+// WithRedisPrefix 设置键的前缀，默认为 "httpcache:"
+func WithRedisPrefix(prefix string) RedisOption {
+	return func(s *RedisStore) {
+		s.prefix = prefix
+	}
+}
+
+// RedisStore 基于 Redis 的响应存储，多个应用实例共享缓存和失效标签。
+// 响应以 JSON 保存，标签使用 Redis 集合保存关联的键，使用 Redis Cluster 时前缀需要包含哈希标签，
+// 例如 "{httpcache}:"，保证标签和键在同一个槽中
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore 使用已有的 Redis 客户端创建响应存储
+func NewRedisStore(client redis.UniversalClient, opts ...RedisOption) *RedisStore {
+	s := &RedisStore{
+		client: client,
+		prefix: "httpcache:",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get 返回缓存的响应，不存在时返回 ErrCacheMiss
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Set 保存响应并关联 entry.Tags
+func (s *RedisStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(entry.Tags)+1)
+	keys = append(keys, s.prefix+key)
+	for _, tag := range entry.Tags {
+		keys = append(keys, s.tagKey(tag))
+	}
+	return setScript.Run(ctx, s.client, keys, data, ttl.Milliseconds()).Err()
+}
+
+// Delete 删除指定的键
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// InvalidateTags 删除标签关联的所有键
+func (s *RedisStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = s.tagKey(tag)
+	}
+	return invalidateScript.Run(ctx, s.client, keys).Err()
+}
+
+func (s *RedisStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
@@ -0,0 +1,129 @@
+package loadshed
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// Config 并发限制中间件配置
+type Config struct {
+	// 同时处理的最大请求数
+	MaxInFlight int
+	// 等待处理的最大请求数，0表示超出并发数时直接拒绝
+	MaxQueue int
+	// 请求在队列中的最长等待时间，默认1秒
+	QueueTimeout time.Duration
+	// 拒绝时返回的 Retry-After，默认1秒
+	RetryAfter time.Duration
+	// 拒绝时返回的状态码，默认503
+	StatusCode int
+	// 拒绝时返回的错误信息
+	Message string
+	// 自定义拒绝响应，设置后忽略StatusCode和Message，Retry-After 仍然会设置
+	OnReject web.HandlerFunc
+	// 返回true时不受并发限制，例如健康检查
+	Skipper func(ctx *web.Context) bool
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		QueueTimeout: time.Second,
+		RetryAfter:   time.Second,
+		StatusCode:   http.StatusServiceUnavailable,
+		Message:      "server is busy",
+	}
+}
+
+// New 创建最多同时处理 maxInFlight 个请求、最多 maxQueue 个请求排队等待的并发限制中间件
+func New(maxInFlight, maxQueue int) web.Middleware {
+	config := DefaultConfig()
+	config.MaxInFlight = maxInFlight
+	config.MaxQueue = maxQueue
+	return NewWithConfig(config)
+}
+
+// NewWithConfig 使用自定义配置创建并发限制中间件
+// 同一个中间件注册到多个路由时共享并发数，注册到路由组即可为该组单独限流：
+//
+//	server.Middleware().Global().Add(loadshed.New(1000, 2000))
+//	api.Use(loadshed.New(50, 100)) // 依赖慢服务的路由组
+//
+// 超出并发数的请求进入队列等待，队列已满、等待超时或请求在等待期间被取消时返回503和 Retry-After
+func NewWithConfig(config *Config) web.Middleware {
+	if config.MaxInFlight <= 0 {
+		panic("loadshed: MaxInFlight must be positive")
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	if config.Message == "" {
+		config.Message = http.StatusText(config.StatusCode)
+	}
+	retryAfter := strconv.Itoa(int((config.RetryAfter + time.Second - 1) / time.Second))
+
+	slots := make(chan struct{}, config.MaxInFlight)
+	var queued atomic.Int64
+
+	reject := func(ctx *web.Context, reason string) {
+		ctx.Logger().Warn("Request rejected by concurrency limit",
+			logger.String("reason", reason),
+			logger.Int("max_in_flight", config.MaxInFlight),
+			logger.Int("max_queue", config.MaxQueue))
+
+		ctx.Resp.Header().Set("Retry-After", retryAfter)
+		if config.OnReject != nil {
+			config.OnReject(ctx)
+			return
+		}
+		ctx.JSON(config.StatusCode, map[string]string{"error": config.Message})
+	}
+
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx *web.Context) {
+			if config.Skipper != nil && config.Skipper(ctx) {
+				next(ctx)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				if queued.Add(1) > int64(config.MaxQueue) {
+					queued.Add(-1)
+					reject(ctx, "queue full")
+					return
+				}
+				timer := time.NewTimer(config.QueueTimeout)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+					queued.Add(-1)
+				case <-timer.C:
+					queued.Add(-1)
+					reject(ctx, "queue timeout")
+					return
+				case <-ctx.Context.Done():
+					timer.Stop()
+					queued.Add(-1)
+					reject(ctx, "request canceled")
+					return
+				}
+			}
+			defer func() { <-slots }()
+
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package loadshed
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer 可以在多个协程中写入的日志输出
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// testServer /slow 阻塞到 release 关闭，进入处理函数时向 entered 发送信号
+type testServer struct {
+	*web.HTTPServer
+	logs    *lockedBuffer
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newTestServer(mw web.Middleware) *testServer {
+	s := &testServer{
+		logs:    &lockedBuffer{},
+		entered: make(chan struct{}, 16),
+		release: make(chan struct{}),
+	}
+	s.HTTPServer = web.NewHTTPServer(web.WithLogger(logger.NewLogger(logger.WithOutput(s.logs))))
+	s.Get("/slow", func(ctx *web.Context) {
+		s.entered <- struct{}{}
+		<-s.release
+		ctx.String(http.StatusOK, "slow")
+	}).Middleware(mw)
+	s.Get("/fast", func(ctx *web.Context) {
+		ctx.String(http.StatusOK, "fast")
+	}).Middleware(mw)
+	s.Get("/panic", func(ctx *web.Context) {
+		panic("boom")
+	}).Middleware(mw)
+	return s
+}
+
+func (s *testServer) serve(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *testServer) get(path string) *httptest.ResponseRecorder {
+	return s.serve(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// hold 发起一个占用并发数的请求，返回接收响应的通道
+func (s *testServer) hold(t *testing.T) <-chan *httptest.ResponseRecorder {
+	t.Helper()
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		done <- s.get("/slow")
+	}()
+	select {
+	case <-s.entered:
+	case <-time.After(time.Second):
+		t.Fatal("request did not enter the handler")
+	}
+	return done
+}
+
+func TestLoadShed_QueueFull(t *testing.T) {
+	s := newTestServer(New(1, 0))
+	done := s.hold(t)
+
+	// 没有排队位置时直接拒绝
+	rec := s.get("/fast")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"server is busy"}`, rec.Body.String())
+	assert.Contains(t, s.logs.String(), `"reason":"queue full"`)
+
+	close(s.release)
+	rec = <-done
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "slow", rec.Body.String())
+}
+
+func TestLoadShed_Queue(t *testing.T) {
+	s := newTestServer(NewWithConfig(&Config{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Second}))
+	done := s.hold(t)
+
+	// 排队的请求在并发数释放后执行
+	queued := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		queued <- s.get("/fast")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(s.release)
+
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+	rec := <-queued
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "fast", rec.Body.String())
+}
+
+func TestLoadShed_QueueTimeout(t *testing.T) {
+	s := newTestServer(NewWithConfig(&Config{
+		MaxInFlight:  1,
+		MaxQueue:     1,
+		QueueTimeout: 20 * time.Millisecond,
+		StatusCode:   http.StatusTooManyRequests,
+		Message:      "slow down",
+	}))
+	done := s.hold(t)
+	defer func() {
+		close(s.release)
+		<-done
+	}()
+
+	start := time.Now()
+	rec := s.get("/fast")
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.JSONEq(t, `{"error":"slow down"}`, rec.Body.String())
+	assert.Contains(t, s.logs.String(), `"reason":"queue timeout"`)
+}
+
+func TestLoadShed_CanceledWhileQueued(t *testing.T) {
+	s := newTestServer(NewWithConfig(&Config{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Hour}))
+	done := s.hold(t)
+	defer func() {
+		close(s.release)
+		<-done
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil).WithContext(ctx)
+	result := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		result <- s.serve(req)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case rec := <-result:
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Contains(t, s.logs.String(), `"reason":"request canceled"`)
+	case <-time.After(time.Second):
+		t.Fatal("canceled request should leave the queue")
+	}
+}
+
+func TestLoadShed_RetryAfter(t *testing.T) {
+	testCases := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{retryAfter: 0, want: "1"},
+		{retryAfter: 500 * time.Millisecond, want: "1"},
+		{retryAfter: time.Second, want: "1"},
+		{retryAfter: 1500 * time.Millisecond, want: "2"},
+		{retryAfter: 3 * time.Second, want: "3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.retryAfter.String(), func(t *testing.T) {
+			s := newTestServer(NewWithConfig(&Config{MaxInFlight: 1, RetryAfter: tc.retryAfter}))
+			done := s.hold(t)
+
+			// 不足一秒的部分向上取整
+			assert.Equal(t, tc.want, s.get("/fast").Header().Get("Retry-After"))
+			close(s.release)
+			<-done
+		})
+	}
+}
+
+func TestLoadShed_OnReject(t *testing.T) {
+	s := newTestServer(NewWithConfig(&Config{
+		MaxInFlight: 1,
+		RetryAfter:  2 * time.Second,
+		OnReject: func(ctx *web.Context) {
+			ctx.String(http.StatusTooManyRequests, "busy")
+		},
+	}))
+	done := s.hold(t)
+	defer func() {
+		close(s.release)
+		<-done
+	}()
+
+	// 自定义拒绝响应时仍然设置 Retry-After
+	rec := s.get("/fast")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "busy", rec.Body.String())
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
+
+func TestLoadShed_Skipper(t *testing.T) {
+	s := newTestServer(NewWithConfig(&Config{
+		MaxInFlight: 1,
+		Skipper: func(ctx *web.Context) bool {
+			return ctx.GetHeader("X-Health") != ""
+		},
+	}))
+	done := s.hold(t)
+	defer func() {
+		close(s.release)
+		<-done
+	}()
+
+	assert.Equal(t, http.StatusServiceUnavailable, s.get("/fast").Code)
+
+	// 跳过的请求不受并发限制
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set("X-Health", "1")
+	rec := s.serve(req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "fast", rec.Body.String())
+}
+
+func TestLoadShed_ReleaseSlot(t *testing.T) {
+	s := newTestServer(New(1, 0))
+
+	// 处理函数返回后释放并发数，后续请求不会被拒绝
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, s.get("/fast").Code)
+	}
+
+	// 处理函数panic时同样释放
+	assert.Panics(t, func() {
+		s.get("/panic")
+	})
+	assert.Equal(t, http.StatusOK, s.get("/fast").Code)
+}
+
+func TestNewWithConfig_InvalidMaxInFlight(t *testing.T) {
+	assert.PanicsWithValue(t, "loadshed: MaxInFlight must be positive", func() {
+		NewWithConfig(&Config{})
+	})
+}
@@ -0,0 +1,99 @@
+package ormweb
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// txKey 请求事务在上下文中的键
+var txKey = web.NewCtxKey[*orm.Tx]("ormweb", "tx")
+
+// Config 事务中间件配置
+type Config struct {
+	// 开启事务的选项，如隔离级别和只读事务
+	TxOptions *sql.TxOptions
+	// 判断请求结束后是否提交事务，默认响应状态码为2xx时提交
+	ShouldCommit func(ctx *web.Context) bool
+	// 判断请求是否需要事务，返回true时跳过，默认所有请求都开启事务
+	Skipper func(ctx *web.Context) bool
+}
+
+// TransactionMiddleware 创建事务中间件，每个请求在独立的事务中执行
+// 响应状态码为2xx时提交事务，其余状态码和panic时回滚，处理函数通过 Tx(ctx) 获取事务
+func TransactionMiddleware(db *orm.DB) web.Middleware {
+	return TransactionMiddlewareWithConfig(db, &Config{})
+}
+
+// TransactionMiddlewareWithConfig 使用自定义配置创建事务中间件
+// 事务在处理函数返回后、响应写出前结束，提交失败时响应替换为错误响应；
+// 处理函数直接写入 ResponseWriter 时响应已经发出，提交失败只能记录日志
+func TransactionMiddlewareWithConfig(db *orm.DB, config *Config) web.Middleware {
+	if config.ShouldCommit == nil {
+		config.ShouldCommit = successStatus
+	}
+
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx *web.Context) {
+			if config.Skipper != nil && config.Skipper(ctx) {
+				next(ctx)
+				return
+			}
+
+			tx, err := db.BeginTx(ctx.Context, config.TxOptions)
+			if err != nil {
+				ctx.Error(web.NewHTTPError(http.StatusInternalServerError, "").WithInternal(err))
+				return
+			}
+			web.SetCtxValue(ctx, txKey, tx)
+
+			// panic时回滚后继续抛出，交给recovery中间件处理
+			defer func() {
+				web.DeleteCtxValue(ctx, txKey)
+				if p := recover(); p != nil {
+					rollback(ctx, tx)
+					panic(p)
+				}
+			}()
+
+			next(ctx)
+
+			if !config.ShouldCommit(ctx) {
+				rollback(ctx, tx)
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				ctx.Logger().Error("Failed to commit request transaction", logger.FieldError(err))
+				ctx.Error(web.NewHTTPError(http.StatusInternalServerError, "").WithInternal(err))
+			}
+		}
+	}
+}
+
+// Tx 返回当前请求的事务，没有使用事务中间件或请求被跳过时返回nil
+//
+//	user, err := orm.RegisterSelector[User](ormweb.Tx(ctx)).Select().Get(ctx.Context)
+func Tx(ctx *web.Context) *orm.Tx {
+	tx, _ := web.CtxValue(ctx, txKey)
+	return tx
+}
+
+// successStatus 响应状态码为2xx时返回true。处理函数直接写出响应时使用 ctx.Writer() 记录的状态码，
+// 否则使用还没有写出的 RespStatusCode
+func successStatus(ctx *web.Context) bool {
+	code := ctx.ResponseStatus()
+	if w := ctx.Writer(); w.Written() {
+		code = w.Status()
+	}
+	return code >= http.StatusOK && code < http.StatusMultipleChoices
+}
+
+// rollback 回滚事务，失败时只记录日志
+func rollback(ctx *web.Context, tx *orm.Tx) {
+	if err := tx.RollBack(); err != nil {
+		ctx.Logger().Error("Failed to rollback request transaction", logger.FieldError(err))
+	}
+}
@@ -0,0 +1,159 @@
+package ormweb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) (*orm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mockDB.Close() })
+	db, err := orm.Open(mockDB, "mysql")
+	require.NoError(t, err)
+	return db, mock
+}
+
+func serve(db *orm.DB, config *Config, handler web.HandlerFunc) *httptest.ResponseRecorder {
+	s := web.NewHTTPServer()
+	s.Post("/orders", handler).Middleware(TransactionMiddlewareWithConfig(db, config))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	return rec
+}
+
+func TestTransactionMiddleware(t *testing.T) {
+	testCases := []struct {
+		name       string
+		handler    web.HandlerFunc
+		commitErr  error
+		wantCommit bool
+		wantCode   int
+	}{
+		{
+			name: "commit on success",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusCreated, "created")
+			},
+			wantCommit: true,
+			wantCode:   http.StatusCreated,
+		},
+		{
+			name: "rollback on error",
+			handler: func(ctx *web.Context) {
+				ctx.Error(web.NewHTTPError(http.StatusBadRequest, "invalid order"))
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "rollback on non-2xx status",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusConflict, "conflict")
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "rollback on direct non-2xx write",
+			handler: func(ctx *web.Context) {
+				ctx.Resp.WriteHeader(http.StatusConflict)
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "commit on direct 2xx write",
+			handler: func(ctx *web.Context) {
+				// 状态码以写出的为准
+				ctx.RespStatusCode = http.StatusBadRequest
+				ctx.Resp.WriteHeader(http.StatusOK)
+			},
+			wantCommit: true,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name: "commit failure",
+			handler: func(ctx *web.Context) {
+				ctx.String(http.StatusOK, "ok")
+			},
+			commitErr:  errors.New("connection lost"),
+			wantCommit: true,
+			wantCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock := newTestDB(t)
+			mock.ExpectBegin()
+			if !tc.wantCommit {
+				mock.ExpectRollback()
+			} else if tc.commitErr != nil {
+				mock.ExpectCommit().WillReturnError(tc.commitErr)
+			} else {
+				mock.ExpectCommit()
+			}
+
+			rec := serve(db, &Config{}, func(ctx *web.Context) {
+				assert.NotNil(t, Tx(ctx))
+				tc.handler(ctx)
+			})
+			assert.Equal(t, tc.wantCode, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionMiddleware_Panic(t *testing.T) {
+	db, mock := newTestDB(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	// 回滚后继续抛出，交给recovery中间件处理
+	assert.PanicsWithValue(t, "boom", func() {
+		serve(db, &Config{}, func(ctx *web.Context) {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionMiddleware_BeginFailure(t *testing.T) {
+	db, mock := newTestDB(t)
+	mock.ExpectBegin().WillReturnError(errors.New("too many connections"))
+
+	called := false
+	rec := serve(db, &Config{}, func(ctx *web.Context) {
+		called = true
+	})
+	assert.False(t, called)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionMiddleware_Config(t *testing.T) {
+	db, mock := newTestDB(t)
+
+	// 跳过的请求不开启事务
+	rec := serve(db, &Config{Skipper: func(ctx *web.Context) bool { return true }}, func(ctx *web.Context) {
+		assert.Nil(t, Tx(ctx))
+		ctx.String(http.StatusOK, "ok")
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// 自定义提交条件
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	rec = serve(db, &Config{ShouldCommit: func(ctx *web.Context) bool { return true }}, func(ctx *web.Context) {
+		ctx.String(http.StatusAccepted, "queued")
+	})
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
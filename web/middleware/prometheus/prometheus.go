@@ -26,7 +26,7 @@ func (m *MiddlewareBuilder) Build() web.Middleware {
 			0.99:  0.001,
 			0.999: 0.0001,
 		},
-	}, []string{"method", "path", "status"})
+	}, []string{"method", "path", "status", "variant"})
 
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(ctx *web.Context) {
@@ -35,7 +35,8 @@ func (m *MiddlewareBuilder) Build() web.Middleware {
 				duration := time.Now().Sub(startTime).Microseconds()
 				vec.WithLabelValues(ctx.Req.Method,
 					ctx.RouteURL,
-					strconv.Itoa(ctx.RespStatusCode)).
+					strconv.Itoa(ctx.ResponseStatus()),
+					ctx.CanaryVariant()).
 					Observe(float64(duration))
 			}()
 
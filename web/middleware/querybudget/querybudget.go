@@ -0,0 +1,78 @@
+package querybudget
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/orm"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// Config 查询预算中间件配置
+type Config struct {
+	// 单个请求最多执行的查询次数，0表示不限制
+	MaxQueries int
+	// 单个请求累计的最长数据库耗时，0表示不限制
+	MaxDuration time.Duration
+	// 严格模式下超出预算后的查询直接失败，请求以500结束
+	Strict bool
+	// 超出预算时的回调，设置后替代默认的警告日志
+	OnExceeded func(ctx *web.Context, budget *orm.QueryBudget)
+}
+
+// New 创建查询预算中间件，超出预算时只记录警告日志
+func New(maxQueries int, maxDuration time.Duration) web.Middleware {
+	return NewWithConfig(&Config{
+		MaxQueries:  maxQueries,
+		MaxDuration: maxDuration,
+	})
+}
+
+// Strict 创建严格模式的查询预算中间件
+func Strict(maxQueries int, maxDuration time.Duration) web.Middleware {
+	return NewWithConfig(&Config{
+		MaxQueries:  maxQueries,
+		MaxDuration: maxDuration,
+		Strict:      true,
+	})
+}
+
+// NewWithConfig 使用自定义配置创建查询预算中间件
+// 预算通过请求的context传递给ORM，处理函数需要把 ctx.Context 传给查询才能被统计；
+// 通过 Router.Middleware 注册到具体路由即可为不同路由设置不同的预算
+func NewWithConfig(config *Config) web.Middleware {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx *web.Context) {
+			budget := orm.NewQueryBudget(config.MaxQueries, config.MaxDuration)
+			budget.Strict = config.Strict
+
+			reqCtx := orm.WithQueryBudget(ctx.Context, budget)
+			ctx.Context = reqCtx
+			ctx.Req = ctx.Req.WithContext(reqCtx)
+
+			next(ctx)
+
+			if !budget.Exceeded() {
+				return
+			}
+
+			if config.OnExceeded != nil {
+				config.OnExceeded(ctx, budget)
+			} else {
+				ctx.Logger().Warn("Query budget exceeded",
+					logger.String("method", ctx.Req.Method),
+					logger.String("path", ctx.Req.URL.Path),
+					logger.Int("queries", budget.Queries()),
+					logger.Int("max_queries", config.MaxQueries),
+					logger.String("duration", budget.Duration().String()),
+					logger.String("max_duration", config.MaxDuration.String()))
+			}
+
+			if config.Strict {
+				ctx.Error(web.NewHTTPError(http.StatusInternalServerError, "query budget exceeded").
+					WithInternal(orm.ErrQueryBudgetExceeded))
+			}
+		}
+	}
+}
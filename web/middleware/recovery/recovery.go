@@ -1,40 +1,135 @@
 package recovery
 
 import (
+    "errors"
     "fmt"
+    "github.com/fyerfyer/fyer-webframe/idgen"
     "github.com/fyerfyer/fyer-webframe/web"
     "github.com/fyerfyer/fyer-webframe/web/logger"
+    "net/http"
     "runtime"
     "strings"
     "time"
 )
 
+// Event 描述一次被恢复的panic，会传递给所有的Reporter
+type Event struct {
+    ErrorID  string    // 错误ID，会返回给客户端以便关联日志
+    Err      error     // panic值转换得到的错误
+    Value    any       // 原始panic值
+    Stack    string    // 堆栈跟踪信息
+    Method   string    // 请求方法
+    Path     string    // 请求路径
+    Route    string    // 匹配的路由
+    ClientIP string    // 客户端IP
+    Time     time.Time // 发生时间
+}
+
+// Reporter 错误上报接口，可用于接入Sentry、Bugsnag等错误追踪服务
+type Reporter interface {
+    Report(ctx *web.Context, event *Event)
+}
+
+// ReporterFunc 函数形式的Reporter
+type ReporterFunc func(ctx *web.Context, event *Event)
+
+// Report 实现Reporter接口
+func (f ReporterFunc) Report(ctx *web.Context, event *Event) {
+    f(ctx, event)
+}
+
+// Config 恢复中间件配置
+type Config struct {
+    // 开发模式下响应中会包含panic信息和堆栈跟踪
+    Development bool
+    // 堆栈跟踪保留的最大行数，默认20
+    StackLines int
+    // 错误上报器，按顺序调用
+    Reporters []Reporter
+    // 自定义错误响应，设置后忽略默认的500响应
+    ErrorHandler func(ctx *web.Context, event *Event)
+    // 错误ID生成器，默认生成随机UUID，测试中可以使用 idgen.Sequence 得到固定的错误ID
+    IDGenerator idgen.IDGenerator
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+    return &Config{
+        StackLines: 20,
+    }
+}
+
 // Recovery 返回一个恢复panic并将其转换为HTTP 500错误的中间件
 func Recovery() web.Middleware {
+    return NewWithConfig(DefaultConfig())
+}
+
+// New 创建带有错误上报器的恢复中间件
+func New(reporters ...Reporter) web.Middleware {
+    config := DefaultConfig()
+    config.Reporters = reporters
+    return NewWithConfig(config)
+}
+
+// NewWithConfig 使用自定义配置创建恢复中间件
+func NewWithConfig(config *Config) web.Middleware {
+    if config.StackLines <= 0 {
+        config.StackLines = 20
+    }
+    ids := idgen.OrUUID(config.IDGenerator)
+
     return func(next web.HandlerFunc) web.HandlerFunc {
         return func(ctx *web.Context) {
             defer func() {
-                if err := recover(); err != nil {
-                    // 获取堆栈跟踪信息
-                    stackTrace := getStackTrace(3) // 跳过前3个堆栈帧，获取更相关的信息
-
-                    // 准备结构化日志字段
-                    fields := []logger.Field{
-                        logger.FieldError(fmt.Errorf("%v", err)),
-                        logger.String("stack_trace", stackTrace),
-                        logger.String("method", ctx.Req.Method),
-                        logger.String("path", ctx.Req.URL.Path),
-                        logger.String("client_ip", ctx.ClientIP()),
-                    }
-
-                    // 记录错误日志
-                    ctx.Logger().Error("Panic recovered", fields...)
-
-                    // 返回500错误给客户端
-                    // 可以生成一个唯一ID，方便用户报告问题时关联日志
-                    errorID := fmt.Sprintf("%d", time.Now().UnixNano())
-                    ctx.InternalServerError(fmt.Sprintf("Internal server error occurred. Error ID: %s", errorID))
+                p := recover()
+                if p == nil {
+                    return
                 }
+                // http.ErrAbortHandler用于主动中止响应，交给net/http处理
+                if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+                    panic(p)
+                }
+                // 通过panic抛出的HTTPError属于业务错误，交给错误处理器
+                var he *web.HTTPError
+                if err, ok := p.(error); ok && errors.As(err, &he) {
+                    ctx.Error(err)
+                    return
+                }
+
+                event := newEvent(ctx, ids.NewID(), p, getStackTrace(3, config.StackLines)) // 跳过前3个堆栈帧，获取更相关的信息
+
+                // 记录错误日志
+                ctx.Logger().Error("Panic recovered",
+                    logger.FieldError(event.Err),
+                    logger.String("error_id", event.ErrorID),
+                    logger.String("stack_trace", event.Stack),
+                    logger.String("method", event.Method),
+                    logger.String("path", event.Path),
+                    logger.String("route", event.Route),
+                    logger.String("client_ip", event.ClientIP),
+                )
+
+                for _, reporter := range config.Reporters {
+                    report(ctx, reporter, event)
+                }
+
+                if config.ErrorHandler != nil {
+                    config.ErrorHandler(ctx, event)
+                    return
+                }
+
+                // 返回500错误给客户端，错误ID方便用户报告问题时关联日志
+                message := fmt.Sprintf("Internal server error occurred. Error ID: %s", event.ErrorID)
+                if !config.Development {
+                    ctx.InternalServerError(message)
+                    return
+                }
+                ctx.JSON(http.StatusInternalServerError, map[string]any{
+                    "error":    message,
+                    "error_id": event.ErrorID,
+                    "panic":    event.Err.Error(),
+                    "stack":    strings.Split(event.Stack, "\n"),
+                })
             }()
 
             // 执行下一个处理器
@@ -43,8 +138,41 @@ func Recovery() web.Middleware {
     }
 }
 
+// newEvent 根据panic值构建事件
+func newEvent(ctx *web.Context, id string, p any, stack string) *Event {
+    err, ok := p.(error)
+    if !ok {
+        err = fmt.Errorf("%v", p)
+    }
+
+    return &Event{
+        ErrorID:  id,
+        Err:      err,
+        Value:    p,
+        Stack:    stack,
+        Method:   ctx.Req.Method,
+        Path:     ctx.Req.URL.Path,
+        Route:    ctx.RouteURL,
+        ClientIP: ctx.ClientIP(),
+        Time:     ctx.Now(),
+    }
+}
+
+// report 调用上报器，上报器自身的panic不会影响响应
+func report(ctx *web.Context, reporter Reporter, event *Event) {
+    defer func() {
+        if p := recover(); p != nil {
+            ctx.Logger().Error("Panic in recovery reporter",
+                logger.FieldError(fmt.Errorf("%v", p)),
+                logger.String("error_id", event.ErrorID),
+            )
+        }
+    }()
+    reporter.Report(ctx, event)
+}
+
 // getStackTrace 生成格式化的堆栈跟踪信息
-func getStackTrace(skip int) string {
+func getStackTrace(skip, maxLines int) string {
     // 分配缓冲区获取堆栈信息
     buf := make([]byte, 4096)
     n := runtime.Stack(buf, false)
@@ -59,8 +187,8 @@ func getStackTrace(skip int) string {
     // 保留关键堆栈帧
     relevantLines := lines[skip*2:]
     // 限制堆栈大小，避免日志过长
-    if len(relevantLines) > 20 {
-        relevantLines = relevantLines[:20]
+    if len(relevantLines) > maxLines {
+        relevantLines = relevantLines[:maxLines]
         relevantLines = append(relevantLines, "...stack trace truncated...")
     }
 
@@ -0,0 +1,141 @@
+package recovery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/idgen"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(mw web.Middleware, handler web.HandlerFunc, opts ...web.ServerOption) *httptest.ResponseRecorder {
+	opts = append([]web.ServerOption{web.WithLogger(logger.NewLogger(logger.WithOutput(io.Discard)))}, opts...)
+	s := web.NewHTTPServer(opts...)
+	s.Get("/users/:id", handler).Middleware(mw)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	return rec
+}
+
+func TestRecovery(t *testing.T) {
+	rec := serve(Recovery(), func(ctx *web.Context) {
+		panic("boom")
+	})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Internal server error occurred. Error ID: ")
+	assert.NotContains(t, rec.Body.String(), "boom")
+}
+
+func TestRecovery_Reporters(t *testing.T) {
+	var events []*Event
+	record := ReporterFunc(func(ctx *web.Context, event *Event) {
+		events = append(events, event)
+	})
+	// 上报器自身的panic不影响其他上报器和响应
+	broken := ReporterFunc(func(ctx *web.Context, event *Event) {
+		panic("reporter down")
+	})
+
+	rec := serve(New(broken, record), func(ctx *web.Context) {
+		panic("boom")
+	})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	assert.EqualError(t, event.Err, "boom")
+	assert.Equal(t, "boom", event.Value)
+	assert.Equal(t, http.MethodGet, event.Method)
+	assert.Equal(t, "/users/1", event.Path)
+	assert.Equal(t, "/users/:id", event.Route)
+	assert.NotEmpty(t, event.Stack)
+	assert.Contains(t, rec.Body.String(), event.ErrorID)
+}
+
+func TestRecovery_IDGeneratorAndClock(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []*Event
+	mw := NewWithConfig(&Config{
+		IDGenerator: idgen.Sequence("err"),
+		Reporters: []Reporter{ReporterFunc(func(ctx *web.Context, event *Event) {
+			events = append(events, event)
+		})},
+	})
+
+	// 错误ID由 IDGenerator 生成，同一时刻发生的panic也不会重复，时间取自注入的时间来源
+	for i := 0; i < 2; i++ {
+		rec := serve(mw, func(ctx *web.Context) {
+			panic("boom")
+		}, web.WithClock(clock.NewMock(now)))
+		assert.Contains(t, rec.Body.String(), events[i].ErrorID)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "err-1", events[0].ErrorID)
+	assert.Equal(t, "err-2", events[1].ErrorID)
+	assert.Equal(t, now, events[0].Time)
+	assert.Equal(t, now, events[1].Time)
+}
+
+func TestRecovery_Development(t *testing.T) {
+	rec := serve(NewWithConfig(&Config{Development: true, StackLines: 4}), func(ctx *web.Context) {
+		panic("boom")
+	})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var body struct {
+		Error   string   `json:"error"`
+		ErrorID string   `json:"error_id"`
+		Panic   string   `json:"panic"`
+		Stack   []string `json:"stack"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "boom", body.Panic)
+	assert.Contains(t, body.Error, body.ErrorID)
+	// 超出的堆栈被截断，最后一行是截断提示
+	assert.LessOrEqual(t, len(body.Stack), 5)
+}
+
+func TestRecovery_ErrorHandler(t *testing.T) {
+	var reported bool
+	rec := serve(NewWithConfig(&Config{
+		Reporters: []Reporter{ReporterFunc(func(ctx *web.Context, event *Event) { reported = true })},
+		ErrorHandler: func(ctx *web.Context, event *Event) {
+			ctx.String(http.StatusServiceUnavailable, "try again later: "+event.Err.Error())
+		},
+	}), func(ctx *web.Context) {
+		panic("boom")
+	})
+	assert.True(t, reported)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "try again later: boom", rec.Body.String())
+}
+
+func TestRecovery_HTTPError(t *testing.T) {
+	var reported bool
+	rec := serve(New(ReporterFunc(func(ctx *web.Context, event *Event) { reported = true })), func(ctx *web.Context) {
+		panic(web.NewHTTPError(http.StatusNotFound, "user not found"))
+	})
+	// HTTPError 交给错误处理器，不作为panic上报
+	assert.False(t, reported)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "user not found")
+}
+
+func TestRecovery_AbortHandler(t *testing.T) {
+	var reported bool
+	mw := New(ReporterFunc(func(ctx *web.Context, event *Event) { reported = true }))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(mw, func(ctx *web.Context) {
+			panic(http.ErrAbortHandler)
+		})
+	})
+	assert.False(t, reported)
+}
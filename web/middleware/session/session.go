@@ -1,9 +1,9 @@
 package session
 
 import (
+	"github.com/fyerfyer/fyer-webframe/idgen"
 	"github.com/fyerfyer/fyer-webframe/web"
 	"github.com/fyerfyer/fyer-webframe/web/session"
-	"github.com/google/uuid"
 )
 
 // Middleware 用于初始化和处理会话的中间件
@@ -13,6 +13,8 @@ type Middleware struct {
 	AutoCreate     bool
 	// 会话初始化器，用于初始化新会话
 	Initializer    SessionInitializer
+	// 新会话的ID生成器，为 nil 时使用随机的 UUID
+	IDGenerator    idgen.IDGenerator
 }
 
 // SessionInitializer 初始化最初的会话值
@@ -27,7 +29,7 @@ func (m *Middleware) Build() web.Middleware {
 
 			// 如果会话不存在且自动创建为真，则创建一个新会话
 			if err != nil && m.AutoCreate {
-				id := uuid.New().String()
+				id := idgen.OrUUID(m.IDGenerator).NewID()
 				sess, err = m.SessionManager.InitSession(ctx, id)
 				if err == nil && m.Initializer != nil {
 					// Initialize the session with defaults
@@ -58,4 +60,10 @@ func NewSessionMiddleware(manager *session.Manager, autoCreate bool) *Middleware
 func (m *Middleware) WithInitializer(init SessionInitializer) *Middleware {
 	m.Initializer = init
 	return m
+}
+
+// WithIDGenerator 设置新会话的ID生成器，测试中可以使用 idgen.Sequence 得到固定的会话ID
+func (m *Middleware) WithIDGenerator(gen idgen.IDGenerator) *Middleware {
+	m.IDGenerator = gen
+	return m
 }
\ No newline at end of file
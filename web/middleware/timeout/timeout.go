@@ -0,0 +1,198 @@
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// Config 超时中间件配置
+type Config struct {
+	// 处理函数的最长执行时间
+	Timeout time.Duration
+	// 超时后返回的状态码，默认503
+	StatusCode int
+	// 超时后返回的错误信息
+	Message string
+	// 自定义超时响应，设置后忽略StatusCode和Message
+	OnTimeout web.HandlerFunc
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:    10 * time.Second,
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    "request timeout",
+	}
+}
+
+// New 创建一个指定超时时间的超时中间件
+func New(timeout time.Duration) web.Middleware {
+	config := DefaultConfig()
+	config.Timeout = timeout
+	return NewWithConfig(config)
+}
+
+// NewWithConfig 使用自定义配置创建超时中间件
+// 处理函数在独立的goroutine中运行于Context副本之上，超时后副本上的迟到写入会被丢弃，
+// 副本在处理函数真正结束后才归还对象池，因此不会与下一个请求共享同一个Context
+func NewWithConfig(config *Config) web.Middleware {
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	if config.Message == "" {
+		config.Message = http.StatusText(config.StatusCode)
+	}
+
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx *web.Context) {
+			timeoutCtx, cancel := context.WithTimeout(ctx.Context, config.Timeout)
+			defer cancel()
+
+			// 在副本上运行处理函数，响应先写入缓冲写入器
+			tw := newTimeoutWriter()
+			child := ctx.Clone(tw)
+			child.Context = timeoutCtx
+			child.Req = ctx.Req.WithContext(timeoutCtx)
+
+			// 处理函数结束时通过finished/timedOut标记交接副本的所有权：
+			// 未超时由中间件合并结果后归还，已超时由goroutine自行归还
+			result := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					p := recover()
+
+					tw.mu.Lock()
+					if tw.timedOut {
+						tw.mu.Unlock()
+						if p != nil {
+							child.Logger().Error("Panic in timed out handler",
+								logger.FieldError(fmt.Errorf("%v", p)))
+						}
+						web.ReleaseContext(child)
+						return
+					}
+					tw.finished = true
+					tw.mu.Unlock()
+
+					result <- p
+				}()
+
+				next(child)
+			}()
+
+			select {
+			case p := <-result:
+				finish(ctx, child, tw, p)
+			case <-timeoutCtx.Done():
+				tw.mu.Lock()
+				if tw.finished {
+					// 处理函数恰好在超时前结束
+					tw.mu.Unlock()
+					finish(ctx, child, tw, <-result)
+					return
+				}
+				tw.timedOut = true
+				tw.mu.Unlock()
+
+				ctx.Logger().Warn("Request handler timed out",
+					logger.String("path", ctx.Req.URL.Path),
+					logger.Int64("timeout_ms", config.Timeout.Milliseconds()))
+
+				if config.OnTimeout != nil {
+					config.OnTimeout(ctx)
+					return
+				}
+				ctx.JSON(config.StatusCode, map[string]string{"error": config.Message})
+			}
+		}
+	}
+}
+
+// finish 处理在超时前结束的处理函数：合并结果并归还副本
+func finish(ctx *web.Context, child *web.Context, tw *timeoutWriter, p any) {
+	defer web.ReleaseContext(child)
+
+	// 在当前goroutine中重新抛出，交给recovery中间件处理
+	if p != nil {
+		panic(p)
+	}
+
+	ctx.MergeResponse(child)
+	tw.flushTo(ctx.Resp)
+}
+
+// timeoutWriter 缓存处理函数直接写入的响应，超时后拒绝继续写入
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool // 已超时，之后的写入被丢弃
+	finished    bool // 处理函数已在超时前结束
+}
+
+func newTimeoutWriter() *timeoutWriter {
+	return &timeoutWriter{header: make(http.Header)}
+}
+
+// Header 返回缓存的响应头
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write 缓存响应体，超时后返回http.ErrHandlerTimeout
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+// WriteHeader 缓存状态码
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if code < 100 || code > 999 {
+		panic(fmt.Sprintf("invalid WriteHeader code %v", code))
+	}
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+// flushTo 将缓存的响应头和直接写入的内容输出到真实的写入器，只能在处理函数结束后调用
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	dst := w.Header()
+	for k, vv := range tw.header {
+		dst[k] = vv
+	}
+
+	if !tw.wroteHeader {
+		return
+	}
+	w.WriteHeader(tw.code)
+	w.Write(tw.buf.Bytes())
+}
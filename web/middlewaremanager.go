@@ -10,11 +10,24 @@ type MiddlewareManager interface {
 
 	// 条件中间件
 	When(condition func(c *Context) bool) MiddlewareRegister
+
+	// 针对带有任一标签的路由的中间件，路由通过 RouteRegister.Tags 添加标签
+	ForTag(tags ...string) MiddlewareRegister
 }
 
 // MiddlewareRegister 中间件注册器
+// Priority、Except 和 Named 只对之后通过 Add 添加的中间件生效
 type MiddlewareRegister interface {
 	Add(middleware ...Middleware) MiddlewareRegister
+
+	// Priority 设置优先级，数值越大越先执行，相同优先级按原有规则排序
+	Priority(n int) MiddlewareRegister
+
+	// Except 对指定方法和路径跳过中间件，method为空时对所有方法生效
+	Except(method string, path string) MiddlewareRegister
+
+	// Named 为中间件命名，路由可以通过 RouteRegister.SkipMiddleware 按名称跳过
+	Named(name string) MiddlewareRegister
 }
 
 // middlewareManager 实现中间件管理器接口
@@ -64,12 +77,39 @@ func (m *middlewareManager) When(condition func(c *Context) bool) MiddlewareRegi
 	}
 }
 
+// ForTag 注册针对带标签路由的中间件
+// 标签在请求时按匹配到的路由判断，因此中间件和标签的注册顺序不影响结果
+func (m *middlewareManager) ForTag(tags ...string) MiddlewareRegister {
+	return m.When(func(c *Context) bool {
+		return m.server.hasRouteTag(c, tags...)
+	})
+}
+
 // middlewareRegister 实现中间件注册接口
 type middlewareRegister struct {
 	server    *HTTPServer
 	method    string
 	path      string
 	allMethod bool
+	opts      middlewareOptions
+}
+
+// Priority 设置中间件优先级
+func (r *middlewareRegister) Priority(n int) MiddlewareRegister {
+	r.opts.priority = n
+	return r
+}
+
+// Except 对指定方法和路径跳过中间件
+func (r *middlewareRegister) Except(method string, path string) MiddlewareRegister {
+	r.opts.excepts = append(r.opts.excepts, middlewareExcept{method: method, path: path})
+	return r
+}
+
+// Named 为中间件命名
+func (r *middlewareRegister) Named(name string) MiddlewareRegister {
+	r.opts.name = name
+	return r
 }
 
 // Add 添加中间件
@@ -79,7 +119,7 @@ func (r *middlewareRegister) Add(middleware ...Middleware) MiddlewareRegister {
 		methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
 		for _, method := range methods {
 			for _, mw := range middleware {
-				r.server.Use(method, r.path, mw)
+				r.server.useMiddleware(method, r.path, mw, r.opts)
 			}
 		}
 		return r
@@ -87,7 +127,7 @@ func (r *middlewareRegister) Add(middleware ...Middleware) MiddlewareRegister {
 
 	// Handle single HTTP method case
 	for _, mw := range middleware {
-		r.server.Use(r.method, r.path, mw)
+		r.server.useMiddleware(r.method, r.path, mw, r.opts)
 	}
 	return r
 }
@@ -97,6 +137,25 @@ func (r *middlewareRegister) Add(middleware ...Middleware) MiddlewareRegister {
 type conditionalRegister struct {
 	server    *HTTPServer
 	condition func(c *Context) bool
+	opts      middlewareOptions
+}
+
+// Priority 设置中间件优先级
+func (r *conditionalRegister) Priority(n int) MiddlewareRegister {
+	r.opts.priority = n
+	return r
+}
+
+// Except 对指定方法和路径跳过中间件
+func (r *conditionalRegister) Except(method string, path string) MiddlewareRegister {
+	r.opts.excepts = append(r.opts.excepts, middlewareExcept{method: method, path: path})
+	return r
+}
+
+// Named 为中间件命名
+func (r *conditionalRegister) Named(name string) MiddlewareRegister {
+	r.opts.name = name
+	return r
 }
 
 // Add 添加条件中间件
@@ -104,9 +163,10 @@ func (r *conditionalRegister) Add(middlewares ...Middleware) MiddlewareRegister
 	for _, mw := range middlewares {
 		// Create a wrapped middleware that only executes when condition is true
 		wrapped := func(next HandlerFunc) HandlerFunc {
+			handler := mw(next)
 			return func(ctx *Context) {
 				if r.condition(ctx) {
-					mw(next)(ctx)
+					handler(ctx)
 				} else {
 					next(ctx)
 				}
@@ -125,7 +185,7 @@ func (r *conditionalRegister) Add(middlewares ...Middleware) MiddlewareRegister
 		// Apply to all HTTP methods with global wildcard path
 		methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
 		for _, method := range methods {
-			r.server.Use(method, "/*", conditionalMw)
+			r.server.useMiddleware(method, "/*", conditionalMw, r.opts)
 		}
 	}
 	return r
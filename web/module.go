@@ -0,0 +1,188 @@
+package web
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+// Module 可以挂载到服务器任意路径下的子应用，将路由、中间件、模板和静态资源打包在一起，
+// 便于把登录页面、管理后台等功能做成可复用的包。模块只记录注册的内容，
+// 调用 Mount 时才注册到服务器，同一个模块可以挂载到多个路径
+type Module struct {
+	name        string
+	routes      []moduleRoute
+	middlewares []Middleware
+	tplEngine   TemplateEngine
+	assets      []moduleAsset
+	modules     []mountedModule
+	notFound    HandlerFunc
+	onError     ErrorHandler
+}
+
+// moduleRoute 模块中的路由，configure 在路由注册后调用
+type moduleRoute struct {
+	method    string
+	path      string
+	handler   HandlerFunc
+	configure []func(RouteRegister)
+}
+
+// moduleAsset 模块中的静态资源目录
+type moduleAsset struct {
+	path string
+	fsys fs.FS
+}
+
+// mountedModule 挂载在模块中的子模块
+type mountedModule struct {
+	prefix string
+	module *Module
+}
+
+// NewModule 创建模块，name 用于日志
+func NewModule(name string) *Module {
+	return &Module{name: name}
+}
+
+// Name 返回模块名称
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Handle 注册路由，path 相对于挂载路径，configure 用于设置路由的文档、标签等
+func (m *Module) Handle(method, path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	m.routes = append(m.routes, moduleRoute{method: method, path: path, handler: handler, configure: configure})
+	return m
+}
+
+// Get 注册GET路由
+func (m *Module) Get(path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	return m.Handle(http.MethodGet, path, handler, configure...)
+}
+
+// Post 注册POST路由
+func (m *Module) Post(path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	return m.Handle(http.MethodPost, path, handler, configure...)
+}
+
+// Put 注册PUT路由
+func (m *Module) Put(path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	return m.Handle(http.MethodPut, path, handler, configure...)
+}
+
+// Delete 注册DELETE路由
+func (m *Module) Delete(path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	return m.Handle(http.MethodDelete, path, handler, configure...)
+}
+
+// Patch 注册PATCH路由
+func (m *Module) Patch(path string, handler HandlerFunc, configure ...func(RouteRegister)) *Module {
+	return m.Handle(http.MethodPatch, path, handler, configure...)
+}
+
+// Use 添加模块中间件，只作用于模块的路由和静态资源，不影响应用的其他路由
+func (m *Module) Use(middleware ...Middleware) *Module {
+	m.middlewares = append(m.middlewares, middleware...)
+	return m
+}
+
+// Templates 设置模块的模板引擎，挂载路径下的请求渲染模板时使用该引擎，
+// 与路由组的404处理器一样内层模块优先，未设置时使用外层模块或服务器的模板引擎
+func (m *Module) Templates(tpl TemplateEngine) *Module {
+	m.tplEngine = tpl
+	return m
+}
+
+// Static 将文件系统 fsys 作为静态资源挂载到 path 下，例如 Static("/assets", assets)
+func (m *Module) Static(path string, fsys fs.FS) *Module {
+	m.assets = append(m.assets, moduleAsset{path: path, fsys: fsys})
+	return m
+}
+
+// Mount 将子模块挂载到模块的 prefix 下，子模块同时使用外层模块的中间件，
+// 子模块的中间件在外层模块的中间件之前执行
+func (m *Module) Mount(prefix string, child *Module) *Module {
+	m.modules = append(m.modules, mountedModule{prefix: prefix, module: child})
+	return m
+}
+
+// NotFound 设置挂载路径下未匹配到路由时的404处理器
+func (m *Module) NotFound(handler HandlerFunc) *Module {
+	m.notFound = handler
+	return m
+}
+
+// OnError 设置模块内路由调用 ctx.Error 时使用的错误处理器
+func (m *Module) OnError(handler ErrorHandler) *Module {
+	m.onError = handler
+	return m
+}
+
+// Mount 将模块挂载到 prefix 下，模块的路由、中间件和静态资源都以 prefix 为前缀。
+// 模块中间件直接包装模块的处理函数，在服务器、路由组和路由的中间件之后执行，
+// 只作用于模块的路由和静态资源，挂载到根路径时也不会影响应用的其他路由
+func (s *HTTPServer) Mount(prefix string, m *Module) {
+	s.mount(prefix, m, nil)
+}
+
+// mount 挂载模块，outer 是外层模块的中间件
+func (s *HTTPServer) mount(prefix string, m *Module, outer []Middleware) {
+	// 挂载到根路径时前缀为空，避免路径变成 //
+	g := newRouteGroup(s, strings.TrimRight(prefix, "/"))
+	middlewares := append(append([]Middleware(nil), m.middlewares...), outer...)
+
+	if m.tplEngine != nil {
+		s.groupHandlersFor(g.basePath).tplEngine = m.tplEngine
+	}
+	if m.notFound != nil {
+		g.NotFound(m.notFound)
+	}
+	if m.onError != nil {
+		g.OnError(m.onError)
+	}
+
+	for _, r := range m.routes {
+		fullPath := g.normalizePath(r.path)
+		s.Router.addHandler(r.method, fullPath, wrapModuleHandler(r.handler, middlewares))
+		register := newRouteRegister(s, r.method, fullPath)
+		for _, configure := range r.configure {
+			configure(register)
+		}
+	}
+
+	for _, asset := range m.assets {
+		g.Get(strings.TrimRight(asset.path, "/")+"/*", wrapModuleHandler(s.serveModuleAsset(asset.fsys), middlewares))
+	}
+
+	for _, child := range m.modules {
+		s.mount(g.normalizePath(child.prefix), child.module, middlewares)
+	}
+
+	s.logger.Info("Module mounted", logger.String("module", m.name), logger.String("prefix", g.basePath))
+}
+
+// wrapModuleHandler 用模块中间件包装处理函数，排在前面的中间件先执行。
+// 与 composeChain 一样每一层之前检查客户端是否断开
+func wrapModuleHandler(handler HandlerFunc, middlewares []Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = clientGuard(middlewares[i](handler))
+	}
+	return handler
+}
+
+// serveModuleAsset 返回通配符参数对应的静态文件，文件不存在或是目录时使用挂载路径的404处理器
+func (s *HTTPServer) serveModuleAsset(fsys fs.FS) HandlerFunc {
+	return func(ctx *Context) {
+		name := path.Clean("/" + ctx.PathParam("*").Value)[1:]
+		if info, err := fs.Stat(fsys, name); err != nil || info.IsDir() {
+			s.notFoundHandler(ctx.Req.URL.Path)(ctx)
+			return
+		}
+		http.ServeFileFS(ctx.Resp, ctx.Req, fsys, name)
+		ctx.unhandled = false
+	}
+}
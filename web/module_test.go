@@ -0,0 +1,136 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nameTemplate 把引擎名称和模板名称作为渲染结果的模板引擎
+type nameTemplate string
+
+func (t nameTemplate) Render(_ *Context, tplName string, _ any) ([]byte, error) {
+	return []byte(string(t) + ":" + tplName), nil
+}
+
+// headerMiddleware 在响应头 X-Trace 中追加名称
+func headerMiddleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.Resp.Header().Add("X-Trace", name)
+			next(ctx)
+		}
+	}
+}
+
+func TestServer_Mount(t *testing.T) {
+	users := NewModule("users").
+		Use(headerMiddleware("users")).
+		Templates(nameTemplate("users")).
+		Get("/", func(ctx *Context) { _ = ctx.Template("list", nil) }).
+		Get("/:id", func(ctx *Context) { ctx.String(http.StatusOK, "user "+ctx.PathParam("id").Value) })
+
+	admin := NewModule("admin").
+		Use(headerMiddleware("admin")).
+		Templates(nameTemplate("admin")).
+		Get("/", func(ctx *Context) { _ = ctx.Template("dashboard", nil) }).
+		Post("/settings", func(ctx *Context) { ctx.String(http.StatusCreated, "saved") }).
+		Static("/assets", fstest.MapFS{
+			"css/site.css": {Data: []byte("body{}")},
+		}).
+		NotFound(func(ctx *Context) { ctx.String(http.StatusNotFound, "admin not found") }).
+		Mount("/users", users)
+
+	s := NewHTTPServer(WithObjectPool(8), WithTemplate(nameTemplate("app")))
+	s.Get("/", func(ctx *Context) { _ = ctx.Template("home", nil) })
+	s.Mount("/admin", admin)
+	s.Group("/v2").Mount("/admin", admin)
+
+	testCases := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantBody  string
+		wantTrace []string
+	}{
+		{http.MethodGet, "/admin", http.StatusOK, "admin:dashboard", []string{"admin"}},
+		{http.MethodPost, "/admin/settings", http.StatusCreated, "saved", []string{"admin"}},
+		{http.MethodGet, "/admin/users", http.StatusOK, "users:list", []string{"users", "admin"}},
+		{http.MethodGet, "/admin/users/7", http.StatusOK, "user 7", []string{"users", "admin"}},
+		{http.MethodGet, "/admin/assets/css/site.css", http.StatusOK, "body{}", []string{"admin"}},
+		{http.MethodGet, "/admin/assets/css", http.StatusNotFound, "admin not found", []string{"admin"}},
+		{http.MethodGet, "/admin/missing", http.StatusNotFound, "admin not found", nil},
+		{http.MethodGet, "/v2/admin/users/8", http.StatusOK, "user 8", []string{"users", "admin"}},
+		// 模块的中间件和模板引擎不影响应用的其他路由
+		{http.MethodGet, "/", http.StatusOK, "app:home", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+			assert.Equal(t, tc.wantTrace, resp.Header().Values("X-Trace"))
+		})
+	}
+}
+
+func TestServer_MountRoot(t *testing.T) {
+	m := NewModule("docs").
+		Use(headerMiddleware("docs")).
+		Get("/", func(ctx *Context) { ctx.String(http.StatusOK, "home") }).
+		Get("/docs", func(ctx *Context) { ctx.String(http.StatusOK, "docs") }).
+		Static("/assets", fstest.MapFS{
+			"site.css": {Data: []byte("body{}")},
+		})
+
+	s := NewHTTPServer()
+	s.Get("/health", func(ctx *Context) { ctx.String(http.StatusOK, "ok") })
+	s.Mount("/", m)
+	s.Post("/orders", func(ctx *Context) { ctx.String(http.StatusCreated, "created") })
+
+	testCases := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantTrace []string
+	}{
+		{http.MethodGet, "/", http.StatusOK, []string{"docs"}},
+		{http.MethodGet, "/docs", http.StatusOK, []string{"docs"}},
+		{http.MethodGet, "/assets/site.css", http.StatusOK, []string{"docs"}},
+		{http.MethodHead, "/docs", http.StatusOK, []string{"docs"}},
+		// 挂载到根路径的模块中间件不会变成全局中间件
+		{http.MethodGet, "/health", http.StatusOK, nil},
+		{http.MethodPost, "/orders", http.StatusCreated, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantTrace, resp.Header().Values("X-Trace"))
+		})
+	}
+}
+
+func TestServer_MountConfigure(t *testing.T) {
+	m := NewModule("docs").Get("/health", func(ctx *Context) {
+		ctx.String(http.StatusOK, "ok")
+	}, func(r RouteRegister) {
+		r.Tags("internal")
+	})
+
+	s := NewHTTPServer()
+	s.Mount("/", m)
+
+	routes := s.Routes()
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, "/health", routes[0].Path)
+		assert.Equal(t, []string{"internal"}, routes[0].Tags)
+	}
+}
@@ -0,0 +1,217 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+)
+
+var (
+	// ErrPartTooLarge multipart 的某个部分超过大小限制
+	ErrPartTooLarge = errors.New("multipart part too large")
+	// ErrTooManyParts multipart 的部分数量超过限制
+	ErrTooManyParts = errors.New("too many multipart parts")
+)
+
+// Part 流式解析 multipart 请求时的一个部分，读取超过大小限制时返回状态码为413的 HTTPError
+type Part struct {
+	FormName string               // 表单字段名
+	FileName string               // 文件名，不是文件时为空
+	Header   textproto.MIMEHeader // 该部分的头部
+	reader   io.Reader
+}
+
+// Read 读取该部分的内容
+func (p Part) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// IsFile 判断该部分是否为文件
+func (p Part) IsFile() bool {
+	return p.FileName != ""
+}
+
+// ContentType 返回该部分的 Content-Type
+func (p Part) ContentType() string {
+	return p.Header.Get("Content-Type")
+}
+
+// Value 读取普通字段的值
+func (p Part) Value() (string, error) {
+	data, err := io.ReadAll(p.reader)
+	return string(data), err
+}
+
+// SaveTo 将该部分的内容写入文件，失败时删除已写入的文件
+func (p Part) SaveTo(path string) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, p.reader)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return n, err
+	}
+	return n, nil
+}
+
+// MultipartOption StreamMultipart 的选项
+type MultipartOption func(*multipartConfig)
+
+type multipartConfig struct {
+	maxFileSize  int64
+	maxFieldSize int64
+	maxParts     int
+	maxBodySize  int64
+}
+
+// WithMaxPartSize 设置单个文件的最大字节数，默认为32MB，小于等于0表示不限制
+func WithMaxPartSize(size int64) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxFileSize = size
+	}
+}
+
+// WithMaxFieldSize 设置单个普通字段的最大字节数，默认为1MB，小于等于0表示不限制
+func WithMaxFieldSize(size int64) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxFieldSize = size
+	}
+}
+
+// WithMaxParts 设置最多的部分数量，默认为1000
+func WithMaxParts(n int) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxParts = n
+	}
+}
+
+// WithMaxBodySize 设置整个请求体的最大字节数，默认不限制
+func WithMaxBodySize(size int64) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxBodySize = size
+	}
+}
+
+// StreamMultipart 按到达顺序逐个处理 multipart 请求的各个部分，文件内容不会整体读入内存或写入临时文件，
+// 适合大文件上传。fn 返回错误时停止解析并返回该错误，未读完的部分会被跳过：
+//
+//	err := ctx.StreamMultipart(func(part web.Part) error {
+//		if !part.IsFile() {
+//			return nil
+//		}
+//		_, err := part.SaveTo(filepath.Join(dir, part.FileName))
+//		return err
+//	}, web.WithMaxPartSize(1<<30))
+//	if err != nil {
+//		ctx.Error(err)
+//		return
+//	}
+//
+// 超出大小或数量限制时返回的 HTTPError 状态码为413，请求不是 multipart 时为415，格式错误时为400
+func (c *Context) StreamMultipart(fn func(part Part) error, opts ...MultipartOption) error {
+	config := multipartConfig{
+		maxFileSize:  defaultMaxSize,
+		maxFieldSize: 1 << 20,
+		maxParts:     1000,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.maxBodySize > 0 {
+		c.Req.Body = http.MaxBytesReader(c.Resp, c.Req.Body, config.maxBodySize)
+	}
+	reader, err := c.Req.MultipartReader()
+	if err != nil {
+		return NewHTTPError(http.StatusUnsupportedMediaType, "").WithInternal(err)
+	}
+
+	for count := 0; ; count++ {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return multipartError(err)
+		}
+		if config.maxParts > 0 && count >= config.maxParts {
+			_ = p.Close()
+			return NewHTTPError(http.StatusRequestEntityTooLarge, "").WithInternal(ErrTooManyParts)
+		}
+
+		limit := config.maxFieldSize
+		if p.FileName() != "" {
+			limit = config.maxFileSize
+		}
+		part := Part{
+			FormName: p.FormName(),
+			FileName: p.FileName(),
+			Header:   p.Header,
+			reader:   p,
+		}
+		if limit > 0 {
+			part.reader = &partReader{r: p, name: part.FormName, limit: limit, remaining: limit}
+		}
+
+		err = fn(part)
+		_ = p.Close()
+		if err != nil {
+			return multipartError(err)
+		}
+	}
+}
+
+// multipartError 将读取请求体的错误转换为对应状态码的 HTTPError，其他错误原样返回
+func multipartError(err error) error {
+	var he *HTTPError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &he):
+		return err
+	case errors.As(err, &maxBytesErr):
+		return NewHTTPError(http.StatusRequestEntityTooLarge, "").WithInternal(err)
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, multipart.ErrMessageTooLarge):
+		return NewHTTPError(http.StatusBadRequest, "malformed multipart body").WithInternal(err)
+	}
+	var protoErr textproto.ProtocolError
+	if errors.As(err, &protoErr) {
+		return NewHTTPError(http.StatusBadRequest, "malformed multipart body").WithInternal(err)
+	}
+	return err
+}
+
+// partReader 限制单个部分的大小，超出时返回 ErrPartTooLarge
+type partReader struct {
+	r         io.Reader
+	name      string
+	limit     int64
+	remaining int64
+}
+
+func (r *partReader) Read(b []byte) (int, error) {
+	if r.remaining <= 0 {
+		// 已经读满限制，还有数据说明超出了大小
+		var probe [1]byte
+		n, err := r.r.Read(probe[:])
+		if n > 0 {
+			return 0, NewHTTPError(http.StatusRequestEntityTooLarge, "").
+				WithInternal(fmt.Errorf("%w: %s exceeds %d bytes", ErrPartTooLarge, r.name, r.limit))
+		}
+		return 0, err
+	}
+	if int64(len(b)) > r.remaining {
+		b = b[:r.remaining]
+	}
+	n, err := r.r.Read(b)
+	r.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartRequest 构造包含普通字段和文件的 multipart 请求
+func multipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	for name, content := range files {
+		fw, err := w.CreateFormFile(name, name+".txt")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestContext_StreamMultipart(t *testing.T) {
+	dir := t.TempDir()
+	req := multipartRequest(t, map[string]string{"title": "report"}, map[string]string{"doc": "hello world"})
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+	fields := map[string]string{}
+	err := ctx.StreamMultipart(func(part Part) error {
+		if !part.IsFile() {
+			value, err := part.Value()
+			fields[part.FormName] = value
+			return err
+		}
+		assert.Equal(t, "doc.txt", part.FileName)
+		assert.Equal(t, "application/octet-stream", part.ContentType())
+		_, err := part.SaveTo(filepath.Join(dir, part.FileName))
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"title": "report"}, fields)
+
+	data, err := os.ReadFile(filepath.Join(dir, "doc.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestContext_StreamMultipartLimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		req      func(t *testing.T) *http.Request
+		opts     []MultipartOption
+		wantCode int
+		wantErr  error
+	}{
+		{
+			name: "file too large",
+			req: func(t *testing.T) *http.Request {
+				return multipartRequest(t, nil, map[string]string{"doc": strings.Repeat("a", 100)})
+			},
+			opts:     []MultipartOption{WithMaxPartSize(10)},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantErr:  ErrPartTooLarge,
+		},
+		{
+			name: "field too large",
+			req: func(t *testing.T) *http.Request {
+				return multipartRequest(t, map[string]string{"note": strings.Repeat("a", 100)}, nil)
+			},
+			opts:     []MultipartOption{WithMaxFieldSize(10), WithMaxPartSize(1000)},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantErr:  ErrPartTooLarge,
+		},
+		{
+			name: "too many parts",
+			req: func(t *testing.T) *http.Request {
+				return multipartRequest(t, map[string]string{"a": "1", "b": "2", "c": "3"}, nil)
+			},
+			opts:     []MultipartOption{WithMaxParts(2)},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantErr:  ErrTooManyParts,
+		},
+		{
+			name: "body too large",
+			req: func(t *testing.T) *http.Request {
+				return multipartRequest(t, nil, map[string]string{"doc": strings.Repeat("a", 1000)})
+			},
+			opts:     []MultipartOption{WithMaxBodySize(100)},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "not multipart",
+			req: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("{}"))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &Context{Req: tc.req(t), Resp: httptest.NewRecorder()}
+			err := ctx.StreamMultipart(func(part Part) error {
+				_, err := io.Copy(io.Discard, part)
+				return err
+			}, tc.opts...)
+
+			var he *HTTPError
+			require.True(t, errors.As(err, &he), "got %v", err)
+			assert.Equal(t, tc.wantCode, he.Code)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestContext_StreamMultipartCallbackError(t *testing.T) {
+	req := multipartRequest(t, map[string]string{"a": "1", "b": "2"}, nil)
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := ctx.StreamMultipart(func(part Part) error {
+		calls++
+		return errStop
+	})
+	assert.Same(t, errStop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPart_SaveToRemovesPartialFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	req := multipartRequest(t, nil, map[string]string{"doc": strings.Repeat("a", 100)})
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+	err := ctx.StreamMultipart(func(part Part) error {
+		_, err := part.SaveTo(path)
+		return err
+	}, WithMaxPartSize(10))
+	assert.ErrorIs(t, err, ErrPartTooLarge)
+	assert.NoFileExists(t, path)
+}
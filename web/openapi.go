@@ -0,0 +1,468 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web/router"
+)
+
+// OpenAPIVersion 生成的文档遵循的 OpenAPI 版本
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIInfo 文档的基本信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIDocument OpenAPI 3 文档，只包含根据路由生成的部分
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIComponents 文档中可以复用的定义
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
+
+// OpenAPIOperation 一个路由对应的操作
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter 路径参数或查询参数
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody 请求体
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse 响应
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType 请求体或响应的内容
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema JSON Schema 的子集
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// routeDoc 通过 RouteRegister.Doc 设置的路由文档
+type routeDoc struct {
+	summary  string
+	request  reflect.Type
+	response reflect.Type
+}
+
+// Doc 设置路由的文档，request 和 response 为请求和响应的示例值，例如 CreateUserReq{}，
+// 只使用它们的类型生成结构定义，传入 nil 表示没有请求体或响应体
+func (r *routeRegister) Doc(summary string, request, response any) RouteRegister {
+	doc := &routeDoc{summary: summary}
+	if request != nil {
+		doc.request = reflect.TypeOf(request)
+	}
+	if response != nil {
+		doc.response = reflect.TypeOf(response)
+	}
+	r.server.routeDocs[r.method+" "+r.path] = doc
+	return r
+}
+
+// WithOpenAPI 在 path 注册返回 OpenAPI 文档的端点，文档在每次请求时根据已注册的路由生成
+func WithOpenAPI(path string, info OpenAPIInfo) ServerOption {
+	return func(server *HTTPServer) {
+		server.openAPIInfo = info
+		server.openAPISkip = append(server.openAPISkip, path)
+		server.Get(path, server.openAPIHandler)
+	}
+}
+
+// WithSwaggerUI 在 path 注册 Swagger UI 页面，specURL 为 OpenAPI 文档的地址，通常与 WithOpenAPI 一起使用
+// 页面从 unpkg.com 加载 Swagger UI 的脚本和样式，只建议在开发和测试环境启用
+func WithSwaggerUI(path, specURL string) ServerOption {
+	return func(server *HTTPServer) {
+		server.openAPISkip = append(server.openAPISkip, path)
+		server.Get(path, func(ctx *Context) {
+			ctx.HTML(http.StatusOK, fmt.Sprintf(swaggerUIPage, specURL))
+		})
+	}
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>API Docs</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head><body><div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>window.ui=SwaggerUIBundle({url:%q,dom_id:"#swagger-ui"});</script>
+</body></html>`
+
+// openAPIHandler 返回 OpenAPI 文档
+func (s *HTTPServer) openAPIHandler(ctx *Context) {
+	ctx.JSON(http.StatusOK, s.OpenAPI())
+}
+
+// OpenAPI 根据已注册的路由生成 OpenAPI 3 文档。路径参数和正则约束来自路由模式，
+// 摘要、请求体和响应体来自 Doc，路由标签作为操作的标签。文档端点和 Swagger UI 页面本身不会出现在文档中
+func (s *HTTPServer) OpenAPI() *OpenAPIDocument {
+	info := s.openAPIInfo
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	gen := &schemaGenerator{schemas: make(map[string]*OpenAPISchema), names: make(map[reflect.Type]string)}
+
+	s.radixRouter.Walk(func(method, route string, _ interface{}) {
+		for _, skip := range s.openAPISkip {
+			if route == skip {
+				return
+			}
+		}
+		path, params := openAPIPath(s.fullPath(route))
+		op := &OpenAPIOperation{
+			OperationID: operationID(method, route),
+			Tags:        s.routeTags[method+" "+route],
+			Parameters:  params,
+			Responses:   map[string]*OpenAPIResponse{},
+		}
+
+		rd := s.routeDocs[method+" "+route]
+		if rd != nil {
+			op.Summary = rd.summary
+			if rd.request != nil {
+				if hasRequestBody(method) {
+					op.RequestBody = &OpenAPIRequestBody{
+						Required: true,
+						Content:  jsonContent(gen.schema(rd.request)),
+					}
+				} else {
+					op.Parameters = append(op.Parameters, gen.queryParams(rd.request)...)
+				}
+			}
+		}
+		if rd != nil && rd.response != nil {
+			op.Responses["200"] = &OpenAPIResponse{Description: "OK", Content: jsonContent(gen.schema(rd.response))}
+		} else {
+			op.Responses["200"] = &OpenAPIResponse{Description: "OK"}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+	})
+
+	if len(gen.schemas) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: gen.schemas}
+	}
+	return doc
+}
+
+// openAPIPath 将路由模式转换为 OpenAPI 的路径模板：:id 转换为 {id}，
+// :id([0-9]+) 的正则作为参数的 pattern，{id:int} 的约束转换为参数的类型，通配符 * 转换为 {wildcard}
+func openAPIPath(route string) (string, []*OpenAPIParameter) {
+	segments := strings.Split(route, "/")
+	var params []*OpenAPIParameter
+	for i, seg := range segments {
+		switch {
+		case seg == "*":
+			segments[i] = "{wildcard}"
+			params = append(params, &OpenAPIParameter{
+				Name:        "wildcard",
+				In:          "path",
+				Description: "剩余的路径，通过 PathParam(\"*\") 获取",
+				Required:    true,
+				Schema:      &OpenAPISchema{Type: "string"},
+			})
+		case strings.HasPrefix(seg, "{"):
+			name, constraint, ok := router.ParseConstraintSegment(seg)
+			if !ok {
+				continue
+			}
+			segments[i] = "{" + name + "}"
+			params = append(params, &OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   constraintSchema(constraint),
+			})
+		case strings.HasPrefix(seg, ":"):
+			name, pattern := seg[1:], ""
+			if idx := strings.Index(name, "("); idx >= 0 && strings.HasSuffix(name, ")") {
+				name, pattern = name[:idx], "^"+name[idx+1:len(name)-1]+"$"
+			}
+			segments[i] = "{" + name + "}"
+			params = append(params, &OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &OpenAPISchema{Type: "string", Pattern: pattern},
+			})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// constraintSchema 返回约束参数的结构定义
+func constraintSchema(name string) *OpenAPISchema {
+	switch name {
+	case "int", "uint":
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case "uuid":
+		return &OpenAPISchema{Type: "string", Format: "uuid"}
+	}
+	schema := &OpenAPISchema{Type: "string"}
+	if c, ok := router.LookupConstraint(name); ok && c.Pattern != "" {
+		schema.Pattern = "^" + c.Pattern + "$"
+	}
+	return schema
+}
+
+// operationID 根据方法和路由生成操作ID，例如 GET /users/:id 生成 get_users_id
+func operationID(method, route string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(route, "/") {
+		seg = strings.TrimPrefix(seg, ":")
+		if name, _, ok := router.ParseConstraintSegment(seg); ok {
+			seg = name
+		}
+		if idx := strings.Index(seg, "("); idx >= 0 {
+			seg = seg[:idx]
+		}
+		if seg == "*" {
+			seg = "wildcard"
+		}
+		if seg == "" {
+			continue
+		}
+		sb.WriteByte('_')
+		for _, r := range seg {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteByte('_')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// hasRequestBody 判断方法是否使用请求体，其余方法的请求参数作为查询参数
+func hasRequestBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func jsonContent(schema *OpenAPISchema) map[string]*OpenAPIMediaType {
+	return map[string]*OpenAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// schemaGenerator 根据 Go 类型生成结构定义，具名的结构体放在 components 中通过 $ref 引用
+type schemaGenerator struct {
+	schemas map[string]*OpenAPISchema
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	byteSliceType = reflect.TypeOf([]byte{})
+)
+
+// schema 返回类型的结构定义
+func (g *schemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *OpenAPISchema
+	switch {
+	case t == timeType:
+		s = &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		s = &OpenAPISchema{}
+	case t == byteSliceType:
+		s = &OpenAPISchema{Type: "string", Format: "byte"}
+	default:
+		s = g.kindSchema(t)
+	}
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (g *schemaGenerator) kindSchema(t reflect.Type) *OpenAPISchema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + g.register(t)}
+	}
+	// 接口等无法确定的类型允许任意值
+	return &OpenAPISchema{}
+}
+
+// register 将具名结构体加入 components，返回使用的名称，不同包中的同名结构体使用包名区分
+func (g *schemaGenerator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.names[t] = name
+	// 先占位，递归引用自身的结构体不会无限展开
+	g.schemas[name] = &OpenAPISchema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+// structSchema 按 json 标签生成结构体的属性，没有 omitempty 且不是指针的字段为必填
+func (g *schemaGenerator) structSchema(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, ok := jsonFieldName(f)
+		if !ok {
+			continue
+		}
+		// 没有 json 标签的匿名结构体字段展开到外层，与 encoding/json 一致
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		s.Properties[name] = g.schema(f.Type)
+		if !omitempty && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// queryParams 将结构体的字段转换为查询参数，参数名依次取 query 标签和 json 标签，
+// 带有 path 标签的字段来自路径参数，不会重复列出
+func (g *schemaGenerator) queryParams(t reflect.Type) []*OpenAPIParameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []*OpenAPIParameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("path"); ok {
+			continue
+		}
+		name, omitempty, ok := jsonFieldName(f)
+		if q, _, _ := strings.Cut(f.Tag.Get("query"), ","); q != "" {
+			name, ok = q, q != "-"
+		}
+		if !ok {
+			continue
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:     name,
+			In:       "query",
+			Required: !omitempty && f.Type.Kind() != reflect.Pointer,
+			Schema:   g.schema(f.Type),
+		})
+	}
+	return params
+}
+
+// jsonFieldName 返回字段在 JSON 中的名称，未导出和标签为 - 的字段返回 false
+func jsonFieldName(f reflect.StructField) (name string, omitempty bool, ok bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false, false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(","+opts+",", ",omitempty,"), true
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIAddress struct {
+	City string `json:"city"`
+}
+
+type openAPIUser struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Email     *string           `json:"email"`
+	Tags      []string          `json:"tags,omitempty"`
+	Address   openAPIAddress    `json:"address"`
+	Friends   []*openAPIUser    `json:"friends,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	password  string
+	Ignored   string `json:"-"`
+}
+
+type openAPICreateUser struct {
+	Name string `json:"name"`
+}
+
+type openAPIListUsers struct {
+	Page  int    `query:"page"`
+	Query string `json:"q,omitempty"`
+	Org   string `path:"org"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	s := NewHTTPServer(
+		WithBasePath("/api"),
+		WithOpenAPI("/openapi.json", OpenAPIInfo{Title: "Users", Version: "2.0.0"}),
+		WithSwaggerUI("/docs", "/api/openapi.json"),
+	)
+	noop := func(ctx *Context) {}
+	s.Get("/orgs/:org/users", noop).Doc("List users", openAPIListUsers{}, []openAPIUser{}).Tags("users")
+	s.Post("/users", noop).Doc("Create user", openAPICreateUser{}, openAPIUser{})
+	s.Get("/users/:id([0-9]+)", noop).Doc("Get user", nil, &openAPIUser{})
+	s.Group("/files").Get("/*", noop)
+
+	doc := s.OpenAPI()
+	assert.Equal(t, OpenAPIVersion, doc.OpenAPI)
+	assert.Equal(t, OpenAPIInfo{Title: "Users", Version: "2.0.0"}, doc.Info)
+	assert.Len(t, doc.Paths, 4, "documentation endpoints are not listed")
+
+	list := doc.Paths["/api/orgs/{org}/users"]["get"]
+	require.NotNil(t, list)
+	assert.Equal(t, "List users", list.Summary)
+	assert.Equal(t, "get_orgs_org_users", list.OperationID)
+	assert.Equal(t, []string{"users"}, list.Tags)
+	assert.Nil(t, list.RequestBody)
+	assert.Equal(t, []*OpenAPIParameter{
+		{Name: "org", In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "page", In: "query", Required: true, Schema: &OpenAPISchema{Type: "integer", Format: "int64"}},
+		{Name: "q", In: "query", Schema: &OpenAPISchema{Type: "string"}},
+	}, list.Parameters)
+	assert.Equal(t, &OpenAPISchema{Type: "array", Items: &OpenAPISchema{Ref: "#/components/schemas/openAPIUser"}},
+		list.Responses["200"].Content["application/json"].Schema)
+
+	create := doc.Paths["/api/users"]["post"]
+	require.NotNil(t, create)
+	require.NotNil(t, create.RequestBody)
+	assert.Equal(t, "#/components/schemas/openAPICreateUser", create.RequestBody.Content["application/json"].Schema.Ref)
+
+	get := doc.Paths["/api/users/{id}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "^[0-9]+$", get.Parameters[0].Schema.Pattern)
+	assert.Equal(t, "#/components/schemas/openAPIUser", get.Responses["200"].Content["application/json"].Schema.Ref)
+
+	files := doc.Paths["/api/files/{wildcard}"]["get"]
+	require.NotNil(t, files)
+	assert.Equal(t, "wildcard", files.Parameters[0].Name)
+	assert.Equal(t, "OK", files.Responses["200"].Description)
+
+	user := doc.Components.Schemas["openAPIUser"]
+	require.NotNil(t, user)
+	assert.Equal(t, []string{"address", "created_at", "id", "name"}, user.Required)
+	assert.Equal(t, &OpenAPISchema{Type: "string", Nullable: true}, user.Properties["email"])
+	assert.Equal(t, &OpenAPISchema{Type: "string", Format: "date-time"}, user.Properties["created_at"])
+	assert.Equal(t, "#/components/schemas/openAPIAddress", user.Properties["address"].Ref)
+	assert.Equal(t, "#/components/schemas/openAPIUser", user.Properties["friends"].Items.Ref)
+	assert.Equal(t, &OpenAPISchema{Type: "string"}, user.Properties["meta"].AdditionalProperties)
+	assert.NotContains(t, user.Properties, "password")
+	assert.NotContains(t, user.Properties, "Ignored")
+
+	// 文档端点和 Swagger UI 页面
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var served map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, OpenAPIVersion, served["openapi"])
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `url:"/api/openapi.json"`)
+}
+
+func TestOpenAPI_Constraint(t *testing.T) {
+	s := NewHTTPServer()
+	noop := func(ctx *Context) {}
+	s.Get("/users/{id:int}/files/{file:uuid}", noop)
+	s.Get("/tags/{name:alpha}", noop)
+
+	doc := s.OpenAPI()
+	get := doc.Paths["/users/{id}/files/{file}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "get_users_id_files_file", get.OperationID)
+	assert.Equal(t, []*OpenAPIParameter{
+		{Name: "id", In: "path", Required: true, Schema: &OpenAPISchema{Type: "integer", Format: "int64"}},
+		{Name: "file", In: "path", Required: true, Schema: &OpenAPISchema{Type: "string", Format: "uuid"}},
+	}, get.Parameters)
+
+	tags := doc.Paths["/tags/{name}"]["get"]
+	require.NotNil(t, tags)
+	assert.Equal(t, "^[a-zA-Z]+$", tags.Parameters[0].Schema.Pattern)
+}
@@ -75,7 +75,6 @@ func BenchmarkContextCreate(b *testing.B) {
 		_ = &Context{
 			Req:        req,
 			Resp:       resp,
-			Param:      make(map[string]string, 8),
 			Context:    req.Context(),
 			unhandled:  true,
 			UserValues: make(map[string]any, 8),
@@ -128,7 +127,6 @@ func BenchmarkResponseJSONWithoutPool(b *testing.B) {
 		ctx := &Context{
 			Req:        req,
 			Resp:       resp,
-			Param:      make(map[string]string),
 			Context:    req.Context(),
 			unhandled:  true,
 			UserValues: make(map[string]any),
@@ -183,7 +181,6 @@ func BenchmarkResponseXMLWithoutPool(b *testing.B) {
 		ctx := &Context{
 			Req:        req,
 			Resp:       resp,
-			Param:      make(map[string]string),
 			Context:    req.Context(),
 			unhandled:  true,
 			UserValues: make(map[string]any),
@@ -227,7 +224,6 @@ func BenchmarkResponseStringWithoutPool(b *testing.B) {
 		ctx := &Context{
 			Req:        req,
 			Resp:       resp,
-			Param:      make(map[string]string),
 			Context:    req.Context(),
 			unhandled:  true,
 			UserValues: make(map[string]any),
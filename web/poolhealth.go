@@ -0,0 +1,281 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+const defaultPoolCheckTimeout = 5 * time.Second
+
+// errConnNotAlive 健康检查发现连接失效，归还时让连接池关闭该连接
+var errConnNotAlive = errors.New("web: pooled connection is not alive")
+
+// PoolHealth 连接池的健康状态
+type PoolHealth struct {
+	Name      string     `json:"name"`
+	Healthy   bool       `json:"healthy"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt time.Time  `json:"checked_at"`
+	Stats     pool.Stats `json:"stats"`
+}
+
+// poolMaintenance 连接池的预热和健康检查配置
+type poolMaintenance struct {
+	minIdle        int
+	healthInterval time.Duration
+	checkTimeout   time.Duration
+	healthPath     string
+
+	mu     sync.RWMutex
+	health map[string]PoolHealth
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// PoolOption 连接池集成的配置选项
+type PoolOption func(*poolMaintenance)
+
+// WithPoolWarmUp 服务器启动时为每个连接池预先创建连接，使空闲连接数不少于 minIdle
+// 数据库等依赖不可用时在启动阶段就会记录错误，而不是等到第一个请求
+func WithPoolWarmUp(minIdle int) PoolOption {
+	return func(m *poolMaintenance) {
+		m.minIdle = minIdle
+	}
+}
+
+// WithPoolHealthCheck 服务器运行期间每隔 interval 检查一次连接池
+// 检查时取出空闲连接调用 IsAlive，失效的连接会被关闭，获取连接失败时连接池标记为不健康
+func WithPoolHealthCheck(interval time.Duration) PoolOption {
+	return func(m *poolMaintenance) {
+		m.healthInterval = interval
+	}
+}
+
+// WithPoolCheckTimeout 设置预热和单次健康检查的超时时间，默认5秒
+func WithPoolCheckTimeout(timeout time.Duration) PoolOption {
+	return func(m *poolMaintenance) {
+		m.checkTimeout = timeout
+	}
+}
+
+// WithPoolHealthEndpoint 在 path 注册连接池健康检查端点，返回各连接池的状态和统计信息，
+// 存在不健康的连接池时返回503
+func WithPoolHealthEndpoint(path string) PoolOption {
+	return func(m *poolMaintenance) {
+		m.healthPath = path
+	}
+}
+
+// WarmUpPools 为每个连接池预先创建连接，使空闲连接数不少于 WithPoolWarmUp 设置的数量
+// 服务器启动时会自动调用，返回各连接池预热失败的错误
+func (s *HTTPServer) WarmUpPools(ctx context.Context) error {
+	if s.poolManager == nil || s.poolMaint == nil || s.poolMaint.minIdle <= 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, name := range s.poolNames() {
+		p, err := s.poolManager.Get(name)
+		if err == nil {
+			err = warmUpPool(ctx, p, s.poolMaint.minIdle)
+		}
+		if err != nil {
+			s.logger.Error("Failed to warm up pool", logger.String("pool", name), logger.FieldError(err))
+			errs = append(errs, fmt.Errorf("pool %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckPools 立即检查所有连接池并记录结果，按连接池名称排序返回
+func (s *HTTPServer) CheckPools(ctx context.Context) []PoolHealth {
+	if s.poolManager == nil {
+		return nil
+	}
+
+	names := s.poolNames()
+	results := make([]PoolHealth, 0, len(names))
+	for _, name := range names {
+		health := PoolHealth{Name: name, Healthy: true, CheckedAt: time.Now()}
+		p, err := s.poolManager.Get(name)
+		if err == nil {
+			err = checkPool(ctx, p)
+			health.Stats = p.Stats()
+		}
+		if err != nil {
+			health.Healthy = false
+			health.Error = err.Error()
+			s.logger.Warn("Pool health check failed", logger.String("pool", name), logger.FieldError(err))
+		}
+		results = append(results, health)
+	}
+
+	if m := s.poolMaint; m != nil {
+		m.mu.Lock()
+		m.health = make(map[string]PoolHealth, len(results))
+		for _, h := range results {
+			m.health[h.Name] = h
+		}
+		m.mu.Unlock()
+	}
+	return results
+}
+
+// PoolHealth 返回连接池的健康状态，统计信息为当前值
+// 启用了 WithPoolHealthCheck 时返回最近一次定期检查的结果，否则立即检查
+func (s *HTTPServer) PoolHealth(ctx context.Context) []PoolHealth {
+	m := s.poolMaint
+	if s.poolManager == nil || m == nil || m.healthInterval <= 0 {
+		return s.CheckPools(ctx)
+	}
+
+	// CheckPools 每次替换整个map，读取引用后不需要继续持有锁
+	m.mu.RLock()
+	checked := m.health
+	m.mu.RUnlock()
+	if checked == nil {
+		return s.CheckPools(ctx)
+	}
+
+	stats := s.poolManager.Stats()
+	results := make([]PoolHealth, 0, len(stats))
+	for _, name := range s.poolNames() {
+		health, ok := checked[name]
+		if !ok {
+			// 上次检查之后注册的连接池
+			health = PoolHealth{Name: name, Healthy: true}
+		}
+		health.Stats = stats[name]
+		results = append(results, health)
+	}
+	return results
+}
+
+// poolHealthHandler 连接池健康检查端点
+func (s *HTTPServer) poolHealthHandler(ctx *Context) {
+	pools := s.PoolHealth(ctx.Context)
+
+	status, code := "ok", http.StatusOK
+	for _, p := range pools {
+		if !p.Healthy {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+			break
+		}
+	}
+	ctx.JSON(code, map[string]any{"status": status, "pools": pools})
+}
+
+// startPoolMaintenance 预热连接池并启动定期健康检查
+func (s *HTTPServer) startPoolMaintenance() {
+	m := s.poolMaint
+	if s.poolManager == nil || m == nil {
+		return
+	}
+
+	if m.minIdle > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), m.checkTimeout)
+		s.WarmUpPools(ctx)
+		cancel()
+	}
+
+	if m.healthInterval <= 0 || m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(m.healthInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), m.checkTimeout)
+				s.CheckPools(ctx)
+				cancel()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopPoolMaintenance 停止定期健康检查
+func (s *HTTPServer) stopPoolMaintenance() {
+	m := s.poolMaint
+	if m == nil || m.stop == nil {
+		return
+	}
+	m.once.Do(func() {
+		close(m.stop)
+	})
+}
+
+// poolNames 返回所有连接池的名称，按名称排序
+func (s *HTTPServer) poolNames() []string {
+	stats := s.poolManager.Stats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// warmUpPool 同时持有连接直到空闲连接数达到 minIdle，再全部归还
+func warmUpPool(ctx context.Context, p pool.Pool, minIdle int) error {
+	need := minIdle - p.Stats().Idle
+	conns := make([]pool.Connection, 0, max(need, 0))
+	defer func() {
+		for _, conn := range conns {
+			p.Put(conn, nil)
+		}
+	}()
+
+	for i := 0; i < need; i++ {
+		conn, err := p.Get(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+	}
+	return nil
+}
+
+// checkPool 取出所有空闲连接检查是否可用，没有空闲连接时获取一个连接以确认依赖可以连接
+// 失效的连接归还时会被连接池关闭
+func checkPool(ctx context.Context, p pool.Pool) error {
+	n := max(p.Stats().Idle, 1)
+	conns := make([]pool.Connection, 0, n)
+	dead := make(map[pool.Connection]bool, n)
+	defer func() {
+		for _, conn := range conns {
+			if dead[conn] {
+				p.Put(conn, errConnNotAlive)
+			} else {
+				p.Put(conn, nil)
+			}
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		conn, err := p.Get(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if !conn.IsAlive() {
+			dead[conn] = true
+		}
+	}
+	if len(dead) == len(conns) {
+		return errConnNotAlive
+	}
+	return nil
+}
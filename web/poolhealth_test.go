@@ -0,0 +1,139 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthTestConn 可以切换存活状态的连接
+type healthTestConn struct {
+	alive *atomic.Bool
+}
+
+func (c *healthTestConn) Close() error      { return nil }
+func (c *healthTestConn) Raw() interface{}  { return c }
+func (c *healthTestConn) IsAlive() bool     { return c.alive.Load() }
+func (c *healthTestConn) ResetState() error { return nil }
+
+// healthTestFactory 记录创建次数，可以模拟依赖不可用
+type healthTestFactory struct {
+	alive   atomic.Bool
+	fail    atomic.Bool
+	created atomic.Int32
+}
+
+func (f *healthTestFactory) Create(ctx context.Context) (pool.Connection, error) {
+	if f.fail.Load() {
+		return nil, errors.New("dial failed")
+	}
+	f.created.Add(1)
+	return &healthTestConn{alive: &f.alive}, nil
+}
+
+func newHealthTestPool(t *testing.T) (*healthTestFactory, pool.Pool) {
+	factory := &healthTestFactory{}
+	factory.alive.Store(true)
+	p := pool.NewPool(factory,
+		pool.WithTestOnBorrow(false),
+		pool.WithMaxRetries(0),
+		pool.WithIdleCheckFrequency(0),
+	)
+	t.Cleanup(func() {
+		p.Shutdown(context.Background())
+	})
+	return factory, p
+}
+
+func TestServer_PoolMaintenance(t *testing.T) {
+	manager := NewMockPoolManager()
+	dbFactory, dbPool := newHealthTestPool(t)
+	cacheFactory, cachePool := newHealthTestPool(t)
+	require.NoError(t, manager.Register("db", dbPool))
+	require.NoError(t, manager.Register("cache", cachePool))
+
+	s := NewHTTPServer(WithPoolManager(manager,
+		WithPoolWarmUp(3),
+		WithPoolHealthEndpoint("/health/pools"),
+	))
+
+	// 预热后空闲连接数达到最小值
+	require.NoError(t, s.WarmUpPools(context.Background()))
+	assert.Equal(t, 3, dbPool.Stats().Idle)
+	assert.Equal(t, int32(3), dbFactory.created.Load())
+	assert.Equal(t, 3, cachePool.Stats().Idle)
+
+	// 再次预热不会创建多余的连接
+	require.NoError(t, s.WarmUpPools(context.Background()))
+	assert.Equal(t, int32(3), dbFactory.created.Load())
+
+	health := s.CheckPools(context.Background())
+	require.Len(t, health, 2)
+	assert.Equal(t, "cache", health[0].Name)
+	assert.True(t, health[0].Healthy)
+	assert.Equal(t, 3, health[1].Stats.Idle)
+
+	// 失效的连接被关闭，依赖不可用时连接池不健康
+	cacheFactory.alive.Store(false)
+	cacheFactory.fail.Store(true)
+	health = s.CheckPools(context.Background())
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, errConnNotAlive.Error(), health[0].Error)
+	assert.Equal(t, 0, cachePool.Stats().Idle)
+
+	health = s.CheckPools(context.Background())
+	assert.False(t, health[0].Healthy)
+	assert.Contains(t, health[0].Error, "dial failed")
+	assert.True(t, health[1].Healthy)
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health/pools", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	var body struct {
+		Status string       `json:"status"`
+		Pools  []PoolHealth `json:"pools"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "unhealthy", body.Status)
+	require.Len(t, body.Pools, 2)
+	assert.Equal(t, 3, body.Pools[1].Stats.Idle)
+
+	// 依赖恢复后重新预热
+	cacheFactory.alive.Store(true)
+	cacheFactory.fail.Store(false)
+	require.NoError(t, s.WarmUpPools(context.Background()))
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health/pools", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestServer_PoolHealthCheckLoop(t *testing.T) {
+	manager := NewMockPoolManager()
+	factory, p := newHealthTestPool(t)
+	require.NoError(t, manager.Register("db", p))
+
+	s := NewHTTPServer(WithPoolManager(manager,
+		WithPoolWarmUp(2),
+		WithPoolHealthCheck(10*time.Millisecond),
+	))
+	s.startPoolMaintenance()
+	defer s.stopPoolMaintenance()
+	assert.Equal(t, 2, p.Stats().Idle)
+
+	factory.alive.Store(false)
+	factory.fail.Store(true)
+	assert.Eventually(t, func() bool {
+		health := s.PoolHealth(context.Background())
+		return len(health) == 1 && !health[0].Healthy
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, p.Stats().Idle)
+}
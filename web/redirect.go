@@ -0,0 +1,142 @@
+package web
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/fyerfyer/fyer-webframe/web/router"
+)
+
+// WithRedirectTrailingSlash 带尾部斜杠的请求重定向到不带斜杠的路由，如 /users/ 重定向到 /users
+// 启用后注册路由时的尾部斜杠会被去掉，而不是panic
+func WithRedirectTrailingSlash(enabled bool) ServerOption {
+	return func(server *HTTPServer) {
+		server.redirectTrailingSlash = enabled
+	}
+}
+
+// WithCaseInsensitiveRouting 大小写不匹配的请求重定向到注册的路由，如 /Users 重定向到 /users
+// 路径参数的值保持原样，只修正静态路径段
+func WithCaseInsensitiveRouting(enabled bool) ServerOption {
+	return func(server *HTTPServer) {
+		server.caseInsensitiveRouting = enabled
+	}
+}
+
+// redirectFixedPath 尝试修正请求路径，修正后的路径存在路由时写入重定向响应并返回true
+// GET和HEAD请求使用301，其余方法使用308以保留请求方法和请求体
+func (s *HTTPServer) redirectFixedPath(ctx *Context, method, path string) bool {
+	fixed := path
+	if s.redirectTrailingSlash && len(fixed) > 1 {
+		fixed = strings.TrimRight(fixed, "/")
+		if fixed == "" {
+			fixed = "/"
+		}
+	}
+
+	if s.caseInsensitiveRouting && !s.hasRoute(method, fixed) {
+		if p, ok := s.fixPathCase(method, fixed); ok {
+			fixed = p
+		}
+	}
+
+	// 合并开头和中间重复的斜杠，否则 //evil.com 这样的路径会成为指向其他站点的 Location
+	fixed = cleanRedirectPath(fixed)
+
+	if fixed == path || !s.hasRoute(method, fixed) {
+		return false
+	}
+
+	location := s.fullPath(fixed)
+	if ctx.Req.URL.RawQuery != "" {
+		location += "?" + ctx.Req.URL.RawQuery
+	}
+
+	code := http.StatusMovedPermanently
+	if method != http.MethodGet && method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+	ctx.Redirect(code, location)
+	ctx.RespStatusCode = code
+	return true
+}
+
+// cleanRedirectPath 规范化重定向的目标路径，去掉重复的斜杠和 . 、.. 路径段，保留尾部斜杠
+func cleanRedirectPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if len(cleaned) > 1 && strings.HasSuffix(p, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// hasRoute 判断路径是否能匹配到路由
+func (s *HTTPServer) hasRoute(method, path string) bool {
+	params := router.AcquireParams()
+	defer router.ReleaseParams(params)
+	if _, ok := s.radixRouter.Find(method, path, params); ok {
+		return true
+	}
+	if method == http.MethodHead {
+		_, ok := s.radixRouter.Find(http.MethodGet, path, params)
+		return ok
+	}
+	return false
+}
+
+// fixPathCase 按大小写不敏感的方式将路径与注册的路由逐段比较，返回修正后的路径
+// 只在路由未命中时调用，因此遍历全部路由的开销可以接受
+func (s *HTTPServer) fixPathCase(method, path string) (string, bool) {
+	var (
+		fixed string
+		found bool
+	)
+	// 其他方法的路由同样可以作为候选，修正后的路径最终由 hasRoute 按请求方法校验
+	s.radixRouter.Walk(func(_, route string, _ interface{}) {
+		if found {
+			return
+		}
+		if p, ok := matchRouteFold(route, path); ok && s.hasRoute(method, p) {
+			fixed, found = p, true
+		}
+	})
+	return fixed, found
+}
+
+// matchRouteFold 大小写不敏感地匹配路由模式，静态段替换为注册时的写法，参数段保持原值
+func matchRouteFold(route, path string) (string, bool) {
+	if route == "/" {
+		return "/", path == "/"
+	}
+
+	routeSegs := strings.Split(strings.Trim(route, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+
+	fixed := make([]string, 0, len(pathSegs))
+	for i, seg := range routeSegs {
+		if seg == "*" {
+			if i >= len(pathSegs) {
+				return "", false
+			}
+			fixed = append(fixed, pathSegs[i:]...)
+			return "/" + strings.Join(fixed, "/"), true
+		}
+		if i >= len(pathSegs) {
+			return "", false
+		}
+		switch {
+		case seg[0] == ':' || seg[0] == '{':
+			fixed = append(fixed, pathSegs[i])
+		case strings.EqualFold(seg, pathSegs[i]):
+			fixed = append(fixed, seg)
+		default:
+			return "", false
+		}
+	}
+
+	if len(routeSegs) != len(pathSegs) {
+		return "", false
+	}
+	return "/" + strings.Join(fixed, "/"), true
+}
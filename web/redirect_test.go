@@ -0,0 +1,153 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_RedirectFixedPath(t *testing.T) {
+	newServer := func(opts ...ServerOption) *HTTPServer {
+		s := NewHTTPServer(opts...)
+		s.Get("/users", func(ctx *Context) { ctx.String(http.StatusOK, "users") })
+		s.Get("/users/:name/Posts", func(ctx *Context) { ctx.String(http.StatusOK, ctx.PathParam("name").Value) })
+		s.Get("/static/*", func(ctx *Context) { ctx.String(http.StatusOK, "static") })
+		s.Post("/users", func(ctx *Context) { ctx.String(http.StatusCreated, "created") })
+		return s
+	}
+
+	testCases := []struct {
+		name         string
+		opts         []ServerOption
+		method       string
+		path         string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:     "trailing slash matches without option",
+			method:   http.MethodGet,
+			path:     "/users/",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "case mismatch without option",
+			method:   http.MethodGet,
+			path:     "/Users",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "trailing slash",
+			opts:         []ServerOption{WithRedirectTrailingSlash(true)},
+			method:       http.MethodGet,
+			path:         "/users/?page=2",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/users?page=2",
+		},
+		{
+			name:         "trailing slash keeps method",
+			opts:         []ServerOption{WithRedirectTrailingSlash(true)},
+			method:       http.MethodPost,
+			path:         "/users/",
+			wantCode:     http.StatusPermanentRedirect,
+			wantLocation: "/users",
+		},
+		{
+			name:     "case mismatch only trailing slash enabled",
+			opts:     []ServerOption{WithRedirectTrailingSlash(true)},
+			method:   http.MethodGet,
+			path:     "/Users/",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "case insensitive and trailing slash",
+			opts:         []ServerOption{WithRedirectTrailingSlash(true), WithCaseInsensitiveRouting(true)},
+			method:       http.MethodGet,
+			path:         "/Users/",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/users",
+		},
+		{
+			name:         "param value keeps case",
+			opts:         []ServerOption{WithCaseInsensitiveRouting(true)},
+			method:       http.MethodGet,
+			path:         "/USERS/Tom/posts",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/users/Tom/Posts",
+		},
+		{
+			name:         "wildcard",
+			opts:         []ServerOption{WithCaseInsensitiveRouting(true)},
+			method:       http.MethodGet,
+			path:         "/Static/CSS/app.css",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/static/CSS/app.css",
+		},
+		{
+			name:     "exact match not redirected",
+			opts:     []ServerOption{WithRedirectTrailingSlash(true), WithCaseInsensitiveRouting(true)},
+			method:   http.MethodGet,
+			path:     "/users",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "no matching route",
+			opts:     []ServerOption{WithRedirectTrailingSlash(true), WithCaseInsensitiveRouting(true)},
+			method:   http.MethodGet,
+			path:     "/orders/",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "base path",
+			opts:         []ServerOption{WithBasePath("/api"), WithCaseInsensitiveRouting(true)},
+			method:       http.MethodGet,
+			path:         "/api/USERS",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/api/users",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newServer(tc.opts...)
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantLocation, resp.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRouter_TrailingSlashRegistration(t *testing.T) {
+	assert.Panics(t, func() {
+		NewHTTPServer().Get("/users/", func(ctx *Context) {})
+	})
+
+	s := NewHTTPServer(WithRedirectTrailingSlash(true))
+	s.Get("/users/", func(ctx *Context) { ctx.String(http.StatusOK, "users") })
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestServer_RedirectFixedPath_NoOpenRedirect(t *testing.T) {
+	s := NewHTTPServer(WithRedirectTrailingSlash(true), WithCaseInsensitiveRouting(true))
+	s.Get("/*", func(ctx *Context) { ctx.String(http.StatusOK, "catch all") })
+
+	for _, path := range []string{"//evil.com/", "//evil.com//", "///evil.com/x/", "/a/../../evil.com/"} {
+		t.Run(path, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+
+			location := resp.Header().Get("Location")
+			assert.Equal(t, http.StatusMovedPermanently, resp.Code)
+			assert.True(t, strings.HasPrefix(location, "/"), location)
+			assert.False(t, strings.HasPrefix(location, "//"), location)
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package web
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
+)
+
+var (
+	// ErrNotAcceptable 表示没有可以满足Accept头部的渲染器
+	ErrNotAcceptable = errors.New("no acceptable renderer for request")
+	// ErrUnsupportedData 渲染器无法处理给定数据时返回，协商会继续尝试下一个渲染器
+	ErrUnsupportedData = errors.New("renderer does not support data type")
+	// ErrInvalidRedirectCode 重定向使用了非 3xx 的状态码
+	ErrInvalidRedirectCode = errors.New("invalid redirect status code")
+)
+
+// Renderer 定义响应渲染器接口
+// 实现该接口并通过 RegisterRenderer 注册即可支持自定义格式（如msgpack、protobuf）
+type Renderer interface {
+	// Render 将数据渲染为响应体
+	Render(ctx *Context, data any) ([]byte, error)
+}
+
+// RendererFunc 函数形式的渲染器
+type RendererFunc func(ctx *Context, data any) ([]byte, error)
+
+// Render 实现Renderer接口
+func (f RendererFunc) Render(ctx *Context, data any) ([]byte, error) {
+	return f(ctx, data)
+}
+
+// View 描述一次HTML模板渲染，用于内容协商时选择HTML格式
+type View struct {
+	Name  string // 模板名称
+	Block string // 只渲染模板中的命名块，为空时渲染完整模板
+	Data  any    // 模板数据
+}
+
+// rendererEntry 存储渲染器及其内容类型
+type rendererEntry struct {
+	mediaType   string // 不带参数的媒体类型，用于匹配
+	contentType string // 写入响应头的完整内容类型
+	renderer    Renderer
+}
+
+// rendererRegistry 渲染器注册表，按注册顺序决定协商时的优先级
+type rendererRegistry struct {
+	mu      sync.RWMutex
+	entries []rendererEntry
+}
+
+// defaultRenderers 默认的渲染器注册表
+var defaultRenderers = newRendererRegistry()
+
+func newRendererRegistry() *rendererRegistry {
+	r := &rendererRegistry{}
+	r.register(ContentTypeJSON, RendererFunc(renderJSON))
+	r.register(ContentTypeXML, RendererFunc(renderXML))
+	r.register("text/xml; charset=utf-8", RendererFunc(renderXML))
+	r.register(ContentTypeHTML, RendererFunc(renderHTML))
+	r.register(ContentTypePlain, RendererFunc(renderPlain))
+	r.register(ContentTypeMsgpack, RendererFunc(renderMsgpack))
+	r.register("application/x-msgpack", RendererFunc(renderMsgpack))
+	r.register(ContentTypeProtobuf, RendererFunc(renderProtobuf))
+	r.register("application/protobuf", RendererFunc(renderProtobuf))
+	return r
+}
+
+// register 注册或替换渲染器
+func (r *rendererRegistry) register(contentType string, renderer Renderer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType := parseMediaType(contentType)
+	for i, e := range r.entries {
+		if e.mediaType == mediaType {
+			r.entries[i] = rendererEntry{mediaType: mediaType, contentType: contentType, renderer: renderer}
+			return
+		}
+	}
+	r.entries = append(r.entries, rendererEntry{mediaType: mediaType, contentType: contentType, renderer: renderer})
+}
+
+// lookup 根据媒体类型查找渲染器
+func (r *rendererRegistry) lookup(mediaType string) (rendererEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mediaType = parseMediaType(mediaType)
+	for _, e := range r.entries {
+		if e.mediaType == mediaType {
+			return e, true
+		}
+	}
+	return rendererEntry{}, false
+}
+
+// negotiate 根据Accept头部返回按优先级排序的候选渲染器
+func (r *rendererRegistry) negotiate(accept string) []rendererEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// 没有Accept头部时按注册顺序尝试所有渲染器
+	if strings.TrimSpace(accept) == "" {
+		candidates := make([]rendererEntry, len(r.entries))
+		copy(candidates, r.entries)
+		return candidates
+	}
+
+	var candidates []rendererEntry
+	seen := make(map[string]bool, len(r.entries))
+	for _, spec := range parseAccept(accept) {
+		if spec.q <= 0 {
+			continue
+		}
+		for _, e := range r.entries {
+			if !seen[e.mediaType] && spec.matches(e.mediaType) {
+				seen[e.mediaType] = true
+				candidates = append(candidates, e)
+			}
+		}
+	}
+	return candidates
+}
+
+// RegisterRenderer 为指定的内容类型注册渲染器
+// 已存在的同类型渲染器会被替换
+func RegisterRenderer(contentType string, renderer Renderer) {
+	defaultRenderers.register(contentType, renderer)
+}
+
+// acceptSpec Accept头部中的单个媒体范围
+type acceptSpec struct {
+	mediaType string
+	q         float64
+}
+
+// specificity 返回媒体范围的具体程度，越具体越优先
+func (s acceptSpec) specificity() int {
+	switch {
+	case s.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(s.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches 判断媒体范围是否匹配给定类型
+func (s acceptSpec) matches(mediaType string) bool {
+	if s.mediaType == "*/*" || s.mediaType == mediaType {
+		return true
+	}
+	if strings.HasSuffix(s.mediaType, "/*") {
+		return strings.HasPrefix(mediaType, s.mediaType[:len(s.mediaType)-1])
+	}
+	return false
+}
+
+// parseAccept 解析Accept头部并按q值和具体程度排序
+func parseAccept(accept string) []acceptSpec {
+	parts := strings.Split(accept, ",")
+	specs := make([]acceptSpec, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		spec := acceptSpec{q: 1}
+		mediaType, params, found := strings.Cut(part, ";")
+		spec.mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if found {
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.TrimSpace(key) != "q" {
+					continue
+				}
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					spec.q = q
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+
+	sort.SliceStable(specs, func(i, j int) bool {
+		if specs[i].q != specs[j].q {
+			return specs[i].q > specs[j].q
+		}
+		return specs[i].specificity() > specs[j].specificity()
+	})
+	return specs
+}
+
+// parseMediaType 去除内容类型中的参数部分
+func parseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// Negotiate 根据请求的Accept头部选择渲染器并返回响应
+// 没有匹配的渲染器时返回 406 Not Acceptable
+func (c *Context) Negotiate(code int, data any) error {
+	for _, entry := range defaultRenderers.negotiate(c.GetHeader("Accept")) {
+		err := c.render(code, entry, data)
+		if errors.Is(err, ErrUnsupportedData) {
+			continue
+		}
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", ContentTypePlain)
+	c.RespStatusCode = http.StatusNotAcceptable
+	c.RespData = []byte(http.StatusText(http.StatusNotAcceptable))
+	c.unhandled = true
+	return ErrNotAcceptable
+}
+
+// Render 使用指定内容类型的渲染器返回响应
+func (c *Context) Render(code int, contentType string, data any) error {
+	entry, ok := defaultRenderers.lookup(contentType)
+	if !ok {
+		return fmt.Errorf("renderer for content type %s not registered", contentType)
+	}
+	return c.render(code, entry, data)
+}
+
+// render 执行渲染并设置响应
+func (c *Context) render(code int, entry rendererEntry, data any) error {
+	body, err := entry.renderer.Render(c, data)
+	if err != nil {
+		return err
+	}
+
+	c.Resp.Header().Set("Content-Type", entry.contentType)
+	c.Resp.Header().Add("Vary", "Accept")
+	c.RespStatusCode = code
+	c.RespData = body
+	c.unhandled = true
+	return nil
+}
+
+// unwrapView 非HTML渲染器只渲染View中的数据部分
+func unwrapView(data any) any {
+	switch v := data.(type) {
+	case View:
+		return v.Data
+	case *View:
+		return v.Data
+	default:
+		return data
+	}
+}
+
+// renderJSON JSON渲染器
+func renderJSON(_ *Context, data any) ([]byte, error) {
+	data = unwrapView(data)
+	buf := objPool.AcquireBuffer()
+	defer objPool.ReleaseBuffer(buf)
+
+	if err := json.NewEncoder(buf.Buffer).Encode(data); err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, buf.Buffer.Len())
+	copy(result, buf.Buffer.Bytes())
+	return result, nil
+}
+
+// renderXML XML渲染器
+func renderXML(_ *Context, data any) ([]byte, error) {
+	data = unwrapView(data)
+	buf := objPool.AcquireBuffer()
+	defer objPool.ReleaseBuffer(buf)
+
+	buf.Buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(buf.Buffer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, buf.Buffer.Len())
+	copy(result, buf.Buffer.Bytes())
+	return result, nil
+}
+
+// renderHTML HTML渲染器，支持View模板渲染和原始HTML字符串
+func renderHTML(ctx *Context, data any) ([]byte, error) {
+	switch v := data.(type) {
+	case View:
+		return renderView(ctx, &v)
+	case *View:
+		return renderView(ctx, v)
+	case *HTTPError:
+		return renderHTTPErrorHTML(v), nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot render %T as html: %w", data, ErrUnsupportedData)
+	}
+}
+
+// renderView 使用上下文的模板引擎渲染View
+func renderView(ctx *Context, v *View) ([]byte, error) {
+	if v.Block != "" {
+		return ctx.renderFragment(v.Name, v.Block, v.Data)
+	}
+	if ctx.tplEngine == nil {
+		return nil, errors.New("template engine not set")
+	}
+	result, err := ctx.tplEngine.Render(ctx, v.Name, v.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return result, nil
+}
+
+// renderPlain 纯文本渲染器
+func renderPlain(_ *Context, data any) ([]byte, error) {
+	switch v := unwrapView(data).(type) {
+	case *HTTPError:
+		return []byte(v.Message), nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	default:
+		return []byte(fmt.Sprint(v)), nil
+	}
+}
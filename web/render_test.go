@@ -0,0 +1,141 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccept(t *testing.T) {
+	specs := parseAccept("text/html;q=0.8, application/json, */*;q=0.1, text/*")
+	require.Len(t, specs, 4)
+	assert.Equal(t, "application/json", specs[0].mediaType)
+	assert.Equal(t, "text/*", specs[1].mediaType)
+	assert.Equal(t, "text/html", specs[2].mediaType)
+	assert.Equal(t, "*/*", specs[3].mediaType)
+}
+
+func TestContextNegotiate(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	testCases := []struct {
+		name     string
+		accept   string
+		data     any
+		wantCode int
+		wantType string
+		wantBody string
+		wantErr  error
+	}{
+		{
+			name:     "no accept header defaults to json",
+			data:     payload{Name: "tom"},
+			wantCode: http.StatusOK,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"name\":\"tom\"}\n",
+		},
+		{
+			name:     "xml preferred",
+			accept:   "application/xml, application/json;q=0.5",
+			data:     payload{Name: "tom"},
+			wantCode: http.StatusOK,
+			wantType: ContentTypeXML,
+			wantBody: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<payload>\n  <name>tom</name>\n</payload>",
+		},
+		{
+			name:     "plain text",
+			accept:   "text/plain",
+			data:     "hello",
+			wantCode: http.StatusOK,
+			wantType: ContentTypePlain,
+			wantBody: "hello",
+		},
+		{
+			name:     "browser falls back when html unsupported",
+			accept:   "text/html,application/xhtml+xml,*/*;q=0.8",
+			data:     payload{Name: "tom"},
+			wantCode: http.StatusOK,
+			wantType: ContentTypeJSON,
+			wantBody: "{\"name\":\"tom\"}\n",
+		},
+		{
+			name:     "raw html string",
+			accept:   "text/html",
+			data:     "<p>hi</p>",
+			wantCode: http.StatusOK,
+			wantType: ContentTypeHTML,
+			wantBody: "<p>hi</p>",
+		},
+		{
+			name:     "not acceptable",
+			accept:   "image/png",
+			data:     payload{Name: "tom"},
+			wantCode: http.StatusNotAcceptable,
+			wantType: ContentTypePlain,
+			wantBody: "Not Acceptable",
+			wantErr:  ErrNotAcceptable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			ctx := &Context{Req: req, Resp: httptest.NewRecorder()}
+
+			err := ctx.Negotiate(http.StatusOK, tc.data)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCode, ctx.RespStatusCode)
+			assert.Equal(t, tc.wantType, ctx.Resp.Header().Get("Content-Type"))
+			assert.Equal(t, tc.wantBody, string(ctx.RespData))
+		})
+	}
+}
+
+func TestNegotiateCustomRenderer(t *testing.T) {
+	RegisterRenderer("application/x-custom", RendererFunc(func(ctx *Context, data any) ([]byte, error) {
+		return []byte("custom"), nil
+	}))
+
+	s := NewHTTPServer()
+	s.Get("/data", func(ctx *Context) {
+		ctx.Negotiate(http.StatusOK, map[string]string{"a": "b"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/x-custom")
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-custom", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "custom", resp.Body.String())
+}
+
+func TestNegotiateTemplateView(t *testing.T) {
+	tpl := NewGoTemplate()
+	_, err := tpl.tpl.New("hello").Parse("<h1>{{.}}</h1>")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html")
+	ctx := &Context{Req: req, Resp: httptest.NewRecorder(), tplEngine: tpl}
+
+	err = ctx.Negotiate(http.StatusOK, View{Name: "hello", Data: "world"})
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeHTML, ctx.Resp.Header().Get("Content-Type"))
+	assert.Equal(t, "<h1>world</h1>", string(ctx.RespData))
+
+	// 非HTML格式只渲染View的数据部分
+	req.Header.Set("Accept", "application/json")
+	err = ctx.Negotiate(http.StatusOK, View{Name: "hello", Data: "world"})
+	require.NoError(t, err)
+	assert.Equal(t, "\"world\"\n", string(ctx.RespData))
+}
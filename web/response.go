@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"unsafe"
 
 	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
+	"google.golang.org/protobuf/proto"
 )
 
 // ContentType 常用的内容类型常量
@@ -25,6 +27,8 @@ const (
 	ContentTypeYAML          = "application/yaml; charset=utf-8"
 	ContentTypeProblemJSON   = "application/problem+json"
 	ContentTypeProblemXML    = "application/problem+xml"
+	ContentTypeProtobuf      = "application/x-protobuf"
+	ContentTypeMsgpack       = "application/msgpack"
 )
 
 // ResponseHelper 为 Context 添加响应帮助方法
@@ -38,6 +42,24 @@ type ResponseHelper interface {
 	// String 返回纯文本响应
 	String(code int, format string, values ...any) error
 
+	// StringNoCopy 返回纯文本响应，不复制字符串
+	StringNoCopy(code int, s string) error
+
+	// Bytes 返回原始字节响应，不复制数据
+	Bytes(code int, data []byte) error
+
+	// Blob 返回指定内容类型的字节响应
+	Blob(code int, contentType string, data []byte) error
+
+	// JSONPretty 返回带缩进的 JSON 响应
+	JSONPretty(code int, data any) error
+
+	// Protobuf 返回 Protocol Buffers 格式的响应
+	Protobuf(code int, msg proto.Message) error
+
+	// Msgpack 返回 MessagePack 格式的响应
+	Msgpack(code int, data any) error
+
 	// HTML 返回 HTML 响应
 	HTML(code int, html string) error
 
@@ -53,6 +75,15 @@ type ResponseHelper interface {
 	// Template 渲染模板
 	Template(name string, data any) error
 
+	// View 使用自动填充的视图模型渲染模板
+	View(name string, data any) error
+
+	// RenderFragment 只渲染模板中的命名块
+	RenderFragment(tplName, block string, data any) error
+
+	// RenderHTMX HTMX请求只渲染命名块，其余请求渲染完整模板
+	RenderHTMX(tplName, block string, data any) error
+
 	// Created 返回 201 Created 响应
 	Created(uri string, data any) error
 
@@ -82,6 +113,12 @@ type ResponseHelper interface {
 
 	// Problem 返回 RFC7807 问题详情
 	Problem(code int, problem *ProblemDetails) error
+
+	// Negotiate 根据 Accept 头部选择渲染格式
+	Negotiate(code int, data any) error
+
+	// Render 使用指定内容类型的渲染器返回响应
+	Render(code int, contentType string, data any) error
 }
 
 // ProblemDetails RFC7807 问题详情
@@ -100,32 +137,48 @@ func (c *Context) JSON(code int, data any) error {
 	c.Resp.Header().Set("Content-Type", ContentTypeJSON)
 	c.RespStatusCode = code
 
-	// 获取一个响应缓冲区
+	// 获取一个响应缓冲区，响应写出后再归还
 	buf := objPool.AcquireBuffer()
-	defer objPool.ReleaseBuffer(buf)
 
 	// 将数据编码到缓冲区
 	err := json.NewEncoder(buf.Buffer).Encode(data)
 	if err != nil {
+		objPool.ReleaseBuffer(buf)
 		return err
 	}
 
-	// 复制缓冲区内容到响应数据
-	c.RespData = make([]byte, buf.Buffer.Len())
-	copy(c.RespData, buf.Buffer.Bytes())
+	// 直接引用缓冲区内容，避免复制
+	c.setRespBuffer(buf)
 
 	c.unhandled = true
 	return nil
 }
 
+// JSONPretty 返回带缩进的 JSON 响应，便于调试和直接在浏览器中查看
+func (c *Context) JSONPretty(code int, data any) error {
+	c.Resp.Header().Set("Content-Type", ContentTypeJSON)
+	c.RespStatusCode = code
+
+	buf := objPool.AcquireBuffer()
+	encoder := json.NewEncoder(buf.Buffer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		objPool.ReleaseBuffer(buf)
+		return err
+	}
+
+	c.setRespBuffer(buf)
+	c.unhandled = true
+	return nil
+}
+
 // XML 返回 XML 格式的响应
 func (c *Context) XML(code int, data any) error {
 	c.Resp.Header().Set("Content-Type", ContentTypeXML)
 	c.RespStatusCode = code
 
-	// 获取一个响应缓冲区
+	// 获取一个响应缓冲区，响应写出后再归还
 	buf := objPool.AcquireBuffer()
-	defer objPool.ReleaseBuffer(buf)
 
 	// 先写入XML头部
 	buf.Buffer.WriteString(xml.Header)
@@ -134,12 +187,12 @@ func (c *Context) XML(code int, data any) error {
 	encoder := xml.NewEncoder(buf.Buffer)
 	encoder.Indent("", "  ")
 	if err := encoder.Encode(data); err != nil {
+		objPool.ReleaseBuffer(buf)
 		return err
 	}
 
-	// 复制缓冲区内容到响应数据
-	c.RespData = make([]byte, buf.Buffer.Len())
-	copy(c.RespData, buf.Buffer.Bytes())
+	// 直接引用缓冲区内容，避免复制
+	c.setRespBuffer(buf)
 
 	c.unhandled = true
 	return nil
@@ -152,19 +205,72 @@ func (c *Context) String(code int, format string, values ...any) error {
 
 	// 获取一个响应缓冲区用于格式化字符串
 	buf := objPool.AcquireBuffer()
-	defer objPool.ReleaseBuffer(buf)
 
-	// 格式化字符串到缓冲区
-	fmt.Fprintf(buf.Buffer, format, values...)
+	// 没有格式化参数时直接写入，避免经过fmt
+	if len(values) == 0 {
+		buf.Buffer.WriteString(format)
+	} else {
+		fmt.Fprintf(buf.Buffer, format, values...)
+	}
+
+	// 直接引用缓冲区内容，避免复制
+	c.setRespBuffer(buf)
+
+	c.unhandled = true
+	return nil
+}
 
-	// 复制缓冲区内容到响应数据
-	c.RespData = make([]byte, buf.Buffer.Len())
-	copy(c.RespData, buf.Buffer.Bytes())
+// StringNoCopy 返回纯文本响应，直接引用字符串的底层内存而不复制
+// 调用方需保证s在响应写出前不会被修改（Go字符串本身是不可变的）
+func (c *Context) StringNoCopy(code int, s string) error {
+	c.Resp.Header().Set("Content-Type", ContentTypePlain)
+	c.RespStatusCode = code
+	c.releaseRespBuffer()
+	c.RespData = unsafe.Slice(unsafe.StringData(s), len(s))
+	c.unhandled = true
+	return nil
+}
 
+// Bytes 返回原始字节响应，不复制数据
+// 如果尚未设置Content-Type，则使用application/octet-stream
+func (c *Context) Bytes(code int, data []byte) error {
+	if c.Resp.Header().Get("Content-Type") == "" {
+		c.Resp.Header().Set("Content-Type", ContentTypeOctetStream)
+	}
+	c.RespStatusCode = code
+	c.releaseRespBuffer()
+	c.RespData = data
 	c.unhandled = true
 	return nil
 }
 
+// Blob 返回指定内容类型的字节响应，不复制数据
+func (c *Context) Blob(code int, contentType string, data []byte) error {
+	c.Resp.Header().Set("Content-Type", contentType)
+	c.RespStatusCode = code
+	c.releaseRespBuffer()
+	c.RespData = data
+	c.unhandled = true
+	return nil
+}
+
+// setRespBuffer 将池化缓冲区作为响应数据，之前持有的缓冲区会被归还
+func (c *Context) setRespBuffer(buf *objPool.ResponseBuffer) {
+	c.releaseRespBuffer()
+	c.respBuf = buf
+	c.RespData = buf.Buffer.Bytes()
+}
+
+// releaseRespBuffer 归还响应缓冲区
+// 如果RespData仍引用该缓冲区，归还后其内容不再有效，因此只能在响应写出后或替换RespData时调用
+func (c *Context) releaseRespBuffer() {
+	if c.respBuf == nil {
+		return
+	}
+	objPool.ReleaseBuffer(c.respBuf)
+	c.respBuf = nil
+}
+
 // HTML 返回 HTML 响应
 func (c *Context) HTML(code int, html string) error {
 	c.Resp.Header().Set("Content-Type", ContentTypeHTML)
@@ -226,9 +332,11 @@ func (c *Context) Created(uri string, data any) error {
 	return c.JSON(http.StatusCreated, data)
 }
 
-// NoContent 返回 204 No Content 响应
+// NoContent 返回 204 No Content 响应，之前设置的响应数据会被丢弃
 func (c *Context) NoContent() error {
 	c.RespStatusCode = http.StatusNoContent
+	c.releaseRespBuffer()
+	c.RespData = nil
 	c.unhandled = true
 	return nil
 }
@@ -264,8 +372,11 @@ func (c *Context) InternalServerError(message string) error {
 	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
 }
 
-// Redirect 重定向到指定的 URL
+// Redirect 重定向到指定的 URL，code 必须是 3xx 重定向状态码
 func (c *Context) Redirect(code int, url string) error {
+	if code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect {
+		return ErrInvalidRedirectCode
+	}
 	http.Redirect(c.Resp, c.Req, url, code)
 	c.unhandled = false
 	return nil
@@ -332,9 +443,8 @@ func (c *Context) Problem(code int, problem *ProblemDetails) error {
 	// 设置状态码
 	problem.Status = code
 
-	// 获取一个响应缓冲区
+	// 获取一个响应缓冲区，响应写出后再归还
 	buf := objPool.AcquireBuffer()
-	defer objPool.ReleaseBuffer(buf)
 
 	// 根据请求的 Accept 头部选择响应格式
 	accept := c.Req.Header.Get("Accept")
@@ -345,12 +455,11 @@ func (c *Context) Problem(code int, problem *ProblemDetails) error {
 		encoder := xml.NewEncoder(buf.Buffer)
 		encoder.Indent("", "  ")
 		if err := encoder.Encode(problem); err != nil {
+			objPool.ReleaseBuffer(buf)
 			return err
 		}
 
-		// 复制缓冲区内容到响应数据
-		c.RespData = make([]byte, buf.Buffer.Len())
-		copy(c.RespData, buf.Buffer.Bytes())
+		c.setRespBuffer(buf)
 		c.RespStatusCode = code
 		c.unhandled = true
 		return nil
@@ -359,12 +468,11 @@ func (c *Context) Problem(code int, problem *ProblemDetails) error {
 	// 默认数据类型为JSON
 	c.Resp.Header().Set("Content-Type", ContentTypeProblemJSON)
 	if err := json.NewEncoder(buf.Buffer).Encode(problem); err != nil {
+		objPool.ReleaseBuffer(buf)
 		return err
 	}
 
-	// 复制缓冲区内容到响应数据
-	c.RespData = make([]byte, buf.Buffer.Len())
-	copy(c.RespData, buf.Buffer.Bytes())
+	c.setRespBuffer(buf)
 	c.RespStatusCode = code
 	c.unhandled = true
 	return nil
@@ -112,7 +112,6 @@ func TestContextResponseMethods(t *testing.T) {
 		ctx := &Context{
 			Req:         req,
 			Resp:        resp,
-			Param:       make(map[string]string),
 			UserValues:  make(map[string]any),
 			unhandled:   true,
 		}
@@ -160,7 +159,6 @@ func TestContextResponseMethods(t *testing.T) {
 		ctx := &Context{
 			Req:         req,
 			Resp:        resp,
-			Param:       make(map[string]string),
 			UserValues:  make(map[string]any),
 			unhandled:   true,
 		}
@@ -214,7 +212,6 @@ func TestContextResponseMethods(t *testing.T) {
 		ctx := &Context{
 			Req:         req,
 			Resp:        resp,
-			Param:       make(map[string]string),
 			UserValues:  make(map[string]any),
 			unhandled:   true,
 		}
@@ -248,7 +245,6 @@ func TestContextResponseMethods(t *testing.T) {
 		ctx := &Context{
 			Req:         req,
 			Resp:        resp,
-			Param:       make(map[string]string),
 			UserValues:  make(map[string]any),
 			unhandled:   true,
 		}
@@ -271,4 +267,58 @@ func TestContextResponseMethods(t *testing.T) {
 			t.Errorf("Expected final index 9, got %d", result["index"])
 		}
 	})
-}
\ No newline at end of file
+}
+func TestContextZeroCopyResponses(t *testing.T) {
+	t.Run("Bytes", func(t *testing.T) {
+		ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: httptest.NewRecorder()}
+		data := []byte("raw")
+
+		err := ctx.Bytes(http.StatusOK, data)
+		if err != nil {
+			t.Fatalf("Failed to create bytes response: %v", err)
+		}
+		if &ctx.RespData[0] != &data[0] {
+			t.Error("Expected RespData to share memory with input")
+		}
+		if ct := ctx.Resp.Header().Get("Content-Type"); ct != ContentTypeOctetStream {
+			t.Errorf("Expected Content-Type %s, got %s", ContentTypeOctetStream, ct)
+		}
+	})
+
+	t.Run("StringNoCopy", func(t *testing.T) {
+		ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: httptest.NewRecorder()}
+
+		err := ctx.StringNoCopy(http.StatusOK, "hello")
+		if err != nil {
+			t.Fatalf("Failed to create string response: %v", err)
+		}
+		if string(ctx.RespData) != "hello" {
+			t.Errorf("Expected 'hello', got '%s'", string(ctx.RespData))
+		}
+		if ct := ctx.Resp.Header().Get("Content-Type"); ct != ContentTypePlain {
+			t.Errorf("Expected Content-Type %s, got %s", ContentTypePlain, ct)
+		}
+	})
+
+	t.Run("BufferReleasedAfterResponse", func(t *testing.T) {
+		s := NewHTTPServer()
+		var captured *Context
+		s.Get("/json", func(ctx *Context) {
+			captured = ctx
+			ctx.JSON(http.StatusOK, map[string]string{"a": "b"})
+			if ctx.respBuf == nil {
+				t.Error("Expected JSON response to hold a pooled buffer")
+			}
+		})
+
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/json", nil))
+
+		if resp.Body.String() != "{\"a\":\"b\"}\n" {
+			t.Errorf("Unexpected body: %s", resp.Body.String())
+		}
+		if captured.respBuf != nil || captured.RespData != nil {
+			t.Error("Expected pooled buffer to be released after response")
+		}
+	})
+}
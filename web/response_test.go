@@ -0,0 +1,109 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseHelpers(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/pretty", func(ctx *Context) {
+		ctx.JSONPretty(http.StatusOK, map[string]any{"name": "tom", "tags": []string{"a"}})
+	})
+	s.Get("/blob", func(ctx *Context) {
+		ctx.Blob(http.StatusOK, "image/png", []byte{0x89, 'P', 'N', 'G'})
+	})
+	s.Get("/no-content", func(ctx *Context) {
+		ctx.JSON(http.StatusOK, "discarded")
+		ctx.NoContent()
+	})
+	s.Post("/created", func(ctx *Context) {
+		ctx.Created("/users/1", map[string]int{"id": 1})
+	})
+	s.Get("/redirect", func(ctx *Context) {
+		ctx.Redirect(http.StatusSeeOther, "/target")
+	})
+	s.Get("/bad-redirect", func(ctx *Context) {
+		if err := ctx.Redirect(http.StatusOK, "/target"); err != nil {
+			ctx.String(http.StatusInternalServerError, err.Error())
+		}
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		code        int
+		contentType string
+		body        string
+		header      map[string]string
+	}{
+		{
+			name:        "json pretty",
+			method:      http.MethodGet,
+			path:        "/pretty",
+			code:        http.StatusOK,
+			contentType: ContentTypeJSON,
+			body:        "{\n  \"name\": \"tom\",\n  \"tags\": [\n    \"a\"\n  ]\n}\n",
+		},
+		{
+			name:        "blob",
+			method:      http.MethodGet,
+			path:        "/blob",
+			code:        http.StatusOK,
+			contentType: "image/png",
+			body:        "\x89PNG",
+		},
+		{
+			name:   "no content discards body",
+			method: http.MethodGet,
+			path:   "/no-content",
+			code:   http.StatusNoContent,
+		},
+		{
+			name:        "created",
+			method:      http.MethodPost,
+			path:        "/created",
+			code:        http.StatusCreated,
+			contentType: ContentTypeJSON,
+			body:        "{\"id\":1}\n",
+			header:      map[string]string{"Location": "/users/1"},
+		},
+		{
+			name:   "redirect",
+			method: http.MethodGet,
+			path:   "/redirect",
+			code:   http.StatusSeeOther,
+			header: map[string]string{"Location": "/target"},
+		},
+		{
+			name:   "invalid redirect code",
+			method: http.MethodGet,
+			path:   "/bad-redirect",
+			code:   http.StatusInternalServerError,
+			body:   ErrInvalidRedirectCode.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tt.method, tt.path, nil))
+
+			require.Equal(t, tt.code, resp.Code)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, resp.Header().Get("Content-Type"))
+			}
+			if tt.body != "" || tt.code == http.StatusNoContent {
+				assert.Equal(t, tt.body, resp.Body.String())
+			}
+			for k, v := range tt.header {
+				assert.Equal(t, v, resp.Header().Get(k))
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter 框架使用的响应写入器，记录写出的状态码和字节数，
+// 并转发底层写入器的 http.Flusher、http.Hijacker 和 http.Pusher。
+// 底层写入器不支持 Hijack 或 Push 时返回 http.ErrNotSupported
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+
+	// Status 返回写出的状态码，还没有写出时返回0
+	Status() int
+	// Size 返回写出的响应体字节数
+	Size() int
+	// Written 返回响应头是否已经写出，连接被接管时也返回 true
+	Written() bool
+	// Unwrap 返回底层的写入器，供 http.ResponseController 使用
+	Unwrap() http.ResponseWriter
+}
+
+// NewResponseWriter 包装 w，w 已经是 ResponseWriter 时直接返回
+func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
+	if rw, ok := w.(ResponseWriter); ok {
+		return rw
+	}
+	rw := &responseWriter{}
+	rw.reset(w)
+	return rw
+}
+
+// responseWriter ResponseWriter 的实现，作为值嵌入 Context 以避免每个请求的分配
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	size     int
+	hijacked bool
+}
+
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	*w = responseWriter{ResponseWriter: rw}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	// 1xx 响应之后还会写出最终的状态码
+	if w.status == 0 && (code < 100 || code > 199 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.status != 0 || w.hijacked
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush 刷新底层写入器，没有写出状态码时与标准库一样按200处理
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack 接管底层连接，之后框架不会再写出响应
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.hijacked = true
+	return conn, buf, nil
+}
+
+// Push 发起 HTTP/2 服务器推送
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return fmt.Errorf("web: push %s: %w", target, http.ErrNotSupported)
+}
+
+// Writer 返回当前请求的 ResponseWriter，中间件可以通过它得到处理函数直接写出的状态码和字节数
+func (c *Context) Writer() ResponseWriter {
+	if c.writer.ResponseWriter == nil && c.Resp != nil {
+		c.wrapResponse()
+	}
+	return &c.writer
+}
+
+// wrapResponse 用 ResponseWriter 包装 Resp
+func (c *Context) wrapResponse() {
+	if rw, ok := c.Resp.(*responseWriter); ok && rw == &c.writer {
+		return
+	}
+	c.writer.reset(c.Resp)
+	c.Resp = &c.writer
+}
+
+// ResponseStatus 返回响应的状态码。响应已经写出时返回写出的状态码，
+// 否则返回处理链结束后将要写出的 RespStatusCode，适合在中间件中调用 next 之后读取
+func (c *Context) ResponseStatus() int {
+	if c.writer.Written() {
+		return c.writer.Status()
+	}
+	if c.RespStatusCode > 0 {
+		return c.RespStatusCode
+	}
+	return http.StatusOK
+}
+
+// ResponseSize 返回响应体的字节数，与 ResponseStatus 一样包括还没有写出的 RespData
+func (c *Context) ResponseSize() int {
+	if c.writer.Written() || !c.unhandled {
+		return c.writer.Size()
+	}
+	return len(c.RespData)
+}
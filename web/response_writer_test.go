@@ -0,0 +1,144 @@
+package web
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hijackRecorder 支持 Hijack 的测试写入器
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
+
+func TestResponseWriter_Observe(t *testing.T) {
+	type observed struct {
+		status, size int
+		written      bool
+	}
+	var got observed
+	s := NewHTTPServer()
+	s.Use("GET", "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			got = observed{ctx.ResponseStatus(), ctx.ResponseSize(), ctx.Writer().Written()}
+		}
+	})
+	s.Get("/direct", func(ctx *Context) {
+		ctx.Resp.WriteHeader(http.StatusAccepted)
+		_, _ = ctx.Resp.Write([]byte("hello"))
+	})
+	s.Get("/buffered", func(ctx *Context) {
+		ctx.String(http.StatusCreated, "buffered")
+	})
+	s.Get("/empty", func(ctx *Context) {})
+
+	testCases := []struct {
+		name string
+		path string
+		want observed
+	}{
+		{name: "direct write", path: "/direct", want: observed{http.StatusAccepted, 5, true}},
+		{name: "buffered response", path: "/buffered", want: observed{http.StatusCreated, 8, false}},
+		{name: "empty response", path: "/empty", want: observed{http.StatusOK, 0, false}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.want.status, rec.Code)
+			assert.Equal(t, tc.want.size, rec.Body.Len())
+		})
+	}
+}
+
+func TestResponseWriter_DirectWriteNotOverwritten(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/", func(ctx *Context) {
+		ctx.RespStatusCode = http.StatusInternalServerError
+		ctx.RespData = []byte("buffered")
+		ctx.Resp.WriteHeader(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestResponseWriter_Interfaces(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewResponseWriter(rec)
+	assert.Same(t, w, NewResponseWriter(w))
+	assert.Same(t, rec, w.Unwrap())
+	assert.False(t, w.Written())
+
+	w.Flush()
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusOK, w.Status())
+
+	_, _, err := w.Hijack()
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+	assert.ErrorIs(t, w.Push("/app.js", nil), http.ErrNotSupported)
+
+	// http.ResponseController 通过 Unwrap 找到底层写入器
+	assert.ErrorIs(t, http.NewResponseController(w).EnableFullDuplex(), http.ErrNotSupported)
+}
+
+func TestResponseWriter_Informational(t *testing.T) {
+	w := NewResponseWriter(httptest.NewRecorder())
+	w.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, w.Written())
+	w.WriteHeader(http.StatusNoContent)
+	assert.Equal(t, http.StatusNoContent, w.Status())
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	s := NewHTTPServer()
+	s.Get("/ws", func(ctx *Context) {
+		conn, _, err := ctx.Writer().Hijack()
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		ctx.String(http.StatusOK, "ignored")
+	})
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.False(t, rec.ResponseRecorder.Flushed)
+	assert.Empty(t, rec.Body.String(), "hijacked connections are not written to")
+}
+
+func TestResponseWriter_Head(t *testing.T) {
+	var status, size int
+	s := NewHTTPServer()
+	s.Use("GET", "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			status, size = ctx.Writer().Status(), ctx.Writer().Size()
+		}
+	})
+	s.Get("/", func(ctx *Context) {
+		ctx.Resp.WriteHeader(http.StatusAccepted)
+		_, _ = ctx.Resp.Write([]byte("body"))
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Zero(t, size, "HEAD responses discard the body")
+}
@@ -0,0 +1,280 @@
+package web
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/fyerfyer/fyer-webframe/web/router"
+)
+
+// routeEntry 注册到路由树中的路由，缓存组合好的中间件处理链，
+// 避免每个请求都重新筛选、排序中间件并组合处理链
+type routeEntry struct {
+	route    string
+	handler  HandlerFunc
+	run      HandlerFunc // 处理链最内层实际执行的处理函数，配置灰度版本后在各版本间分流
+	segments []routeSegment
+	dynamic  bool // 路由是否包含参数或通配符
+	chain    atomic.Pointer[routeChain]
+}
+
+// routeChain 某个中间件版本下路由的处理链
+type routeChain struct {
+	version uint64
+	// handler 组合好的处理链，路由匹配的所有请求执行相同的中间件时不为nil
+	handler HandlerFunc
+	// candidates 排好序的可能作用于该路由的中间件，handler 为nil时按请求路径筛选后组合处理链
+	candidates []MiddlewareWithPath
+}
+
+type segmentKind uint8
+
+const (
+	staticSegment segmentKind = iota
+	paramSegment
+	wildcardSegment
+)
+
+// routeSegment 路由模式中的一个路径段
+type routeSegment struct {
+	kind       segmentKind
+	value      string
+	constraint *router.Constraint
+}
+
+// matchResult 中间件是否作用于路由匹配的请求
+type matchResult uint8
+
+const (
+	matchNever  matchResult = iota // 不作用于任何请求
+	matchAlways                    // 作用于所有请求
+	matchMaybe                     // 取决于请求路径，例如为 /users/1 注册的中间件和路由 /users/:id
+)
+
+// newRouteEntry 创建路由并解析路由模式
+func newRouteEntry(route string, handler HandlerFunc) *routeEntry {
+	e := &routeEntry{route: route, handler: handler, run: handler}
+	if route == "/" {
+		return e
+	}
+	for _, seg := range splitPath(route) {
+		rs := routeSegment{kind: staticSegment, value: seg}
+		switch {
+		case seg == "*":
+			rs.kind = wildcardSegment
+		case seg[0] == ':':
+			rs.kind = paramSegment
+		case seg[0] == '{':
+			rs.kind = paramSegment
+			if _, constraint, ok := router.ParseConstraintSegment(seg); ok && constraint != "" {
+				rs.constraint, _ = router.LookupConstraint(constraint)
+			}
+		}
+		e.dynamic = e.dynamic || rs.kind != staticSegment
+		e.segments = append(e.segments, rs)
+	}
+	return e
+}
+
+// handlerFor 返回处理请求路径 path 的处理链，中间件变化后重新组合
+func (s *HTTPServer) handlerFor(method string, e *routeEntry, path string) HandlerFunc {
+	chain := s.routeChain(method, e)
+	if !isCanonicalPath(path) {
+		// 带有空路径段或尾部斜杠的请求和路由模式的路径段对应不上，按请求路径重新匹配
+		return BuildChain(e.run, path, s.routeMiddlewares(method, e.route))
+	}
+	if chain.handler != nil {
+		return chain.handler
+	}
+	return BuildChain(e.run, path, chain.candidates)
+}
+
+// routeChain 返回当前中间件版本下路由的处理链
+func (s *HTTPServer) routeChain(method string, e *routeEntry) *routeChain {
+	version := s.chainVersion.Load()
+	chain := e.chain.Load()
+	if chain != nil && chain.version == version {
+		return chain
+	}
+
+	chain = &routeChain{version: version}
+	dynamic := false
+	// 排好序的列表中筛选出的子列表仍然是有序的
+	for _, mw := range sortMiddlewares(s.routeMiddlewares(method, e.route)) {
+		switch e.matchMiddleware(mw) {
+		case matchNever:
+			continue
+		case matchMaybe:
+			dynamic = true
+		}
+		chain.candidates = append(chain.candidates, mw)
+	}
+	if !dynamic {
+		chain.handler = composeChain(e.run, chain.candidates)
+	}
+	e.chain.Store(chain)
+	return chain
+}
+
+// routeMiddlewares 返回路由可以使用的中间件，去掉路由跳过的中间件
+func (s *HTTPServer) routeMiddlewares(method, route string) []MiddlewareWithPath {
+	middlewares := s.Router.middlewares[method]
+	if skips := s.routeSkips[method+" "+route]; len(skips) > 0 {
+		middlewares = withoutNamed(middlewares, skips)
+	}
+	return middlewares
+}
+
+// warmRouteChains 在开始接收请求前组合所有路由的处理链
+func (s *HTTPServer) warmRouteChains() {
+	s.radixRouter.Walk(func(method, _ string, handler interface{}) {
+		if e, ok := handler.(*routeEntry); ok {
+			s.routeChain(method, e)
+		}
+	})
+}
+
+// invalidateChains 使所有路由缓存的处理链失效，下一个请求时重新组合
+func (r *Router) invalidateChains() {
+	r.chainVersion.Add(1)
+}
+
+// matchMiddleware 判断中间件是否作用于路由匹配的请求
+func (e *routeEntry) matchMiddleware(mw MiddlewareWithPath) matchResult {
+	result := e.matchPath(mw.Type, mw.Path)
+	if result == matchNever {
+		return matchNever
+	}
+	for _, except := range mw.Excepts {
+		switch e.matchPath(classifyMiddlewareType(except), except) {
+		case matchAlways:
+			return matchNever
+		case matchMaybe:
+			result = matchMaybe
+		}
+	}
+	return result
+}
+
+// matchPath 判断中间件路径是否匹配路由匹配的请求路径，规则与 middlewarePathMatches 一致
+func (e *routeEntry) matchPath(mwType MiddlewareType, pattern string) matchResult {
+	// 静态路由只匹配与路由模式相同的请求路径
+	if !e.dynamic {
+		if middlewarePathMatches(mwType, pattern, e.route) {
+			return matchAlways
+		}
+		return matchNever
+	}
+	if !isCanonicalPath(pattern) {
+		return matchMaybe
+	}
+
+	switch mwType {
+	case StaticMiddleware:
+		if pattern == "/" {
+			return matchAlways
+		}
+		return e.matchPrefix(pattern)
+	case WildcardMiddleware:
+		if pattern == "/*" {
+			return matchAlways
+		}
+		if base, ok := strings.CutSuffix(pattern, "/*"); ok {
+			return e.matchPrefix(base)
+		}
+		return matchAlways
+	case ParamMiddleware, RegexMiddleware:
+		return e.matchSegments(pattern)
+	}
+	return matchNever
+}
+
+// matchPrefix 判断请求路径是否等于 prefix 或以 prefix/ 开头
+func (e *routeEntry) matchPrefix(prefix string) matchResult {
+	result := matchAlways
+	for i, seg := range splitPath(prefix) {
+		if i >= len(e.segments) {
+			return matchNever
+		}
+		rs := e.segments[i]
+		switch rs.kind {
+		case wildcardSegment:
+			return matchMaybe
+		case staticSegment:
+			if rs.value != seg {
+				return matchNever
+			}
+		case paramSegment:
+			if rs.constraint != nil && !rs.constraint.Match(seg) {
+				return matchNever
+			}
+			result = matchMaybe
+		}
+	}
+	return result
+}
+
+// matchSegments 判断请求路径是否与参数中间件路径逐段匹配
+func (e *routeEntry) matchSegments(pattern string) matchResult {
+	segs := splitPath(pattern)
+	n := len(e.segments)
+	wildcard := e.segments[n-1].kind == wildcardSegment
+	if wildcard {
+		// 通配符可以匹配任意数量的路径段
+		if len(segs) < n-1 {
+			return matchNever
+		}
+		n--
+	} else if len(segs) != n {
+		return matchNever
+	}
+
+	result := matchAlways
+	for i, seg := range segs[:n] {
+		rs := e.segments[i]
+		if seg[0] == ':' {
+			continue
+		}
+		if _, constraint, ok := router.ParseConstraintSegment(seg); ok {
+			c, found := router.LookupConstraint(constraint)
+			if !found {
+				continue
+			}
+			if rs.kind == staticSegment && !c.Match(rs.value) {
+				return matchNever
+			}
+			if rs.kind == paramSegment && rs.constraint != c {
+				result = matchMaybe
+			}
+			continue
+		}
+		switch rs.kind {
+		case staticSegment:
+			if rs.value != seg {
+				return matchNever
+			}
+		case paramSegment:
+			if rs.constraint != nil && !rs.constraint.Match(seg) {
+				return matchNever
+			}
+			result = matchMaybe
+		}
+	}
+	if wildcard {
+		return matchMaybe
+	}
+	return result
+}
+
+// splitPath 按 / 切分路径
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// isCanonicalPath 路径不包含空路径段和尾部斜杠
+func isCanonicalPath(path string) bool {
+	if path == "/" {
+		return true
+	}
+	return path != "" && path[len(path)-1] != '/' && !strings.Contains(path, "//")
+}
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+)
+
+// newChainBenchmarkServer 创建带有全局、路径、参数和通配符中间件的服务器
+func newChainBenchmarkServer() *HTTPServer {
+	s := NewHTTPServer()
+	passthrough := func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+		}
+	}
+	s.Middleware().Global().Add(passthrough, passthrough)
+	s.Use(http.MethodGet, "/api/*", passthrough)
+	s.Use(http.MethodGet, "/api/users", passthrough)
+	s.Use(http.MethodGet, "/api/users/:id", passthrough)
+	s.Use(http.MethodGet, "/api/orders/:id", passthrough)
+	s.Use(http.MethodGet, "/admin/*", passthrough)
+	s.Get("/api/users/:id", func(ctx *Context) {})
+	return s
+}
+
+// BenchmarkRouteChain 对比每个请求重新组合处理链和使用路由缓存的处理链，
+// 使用 -benchmem 查看两者的内存分配
+func BenchmarkRouteChain(b *testing.B) {
+	const path = "/api/users/123"
+	ctx := &Context{}
+
+	b.Run("per-request", func(b *testing.B) {
+		s := newChainBenchmarkServer()
+		n, _ := s.findHandler(http.MethodGet, path, ctx)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			BuildChain(n.handler, path, s.middlewares[http.MethodGet])(ctx)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		s := newChainBenchmarkServer()
+		n, _ := s.findHandler(http.MethodGet, path, ctx)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.handlerFor(http.MethodGet, n.entry, path)(ctx)
+		}
+	})
+}
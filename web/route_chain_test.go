@@ -0,0 +1,174 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordMiddleware 返回把名称记录到 ctx.UserValues["mw"] 的中间件
+func recordMiddleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			names, _ := ctx.UserValues["mw"].([]string)
+			ctx.UserValues["mw"] = append(names, name)
+			next(ctx)
+		}
+	}
+}
+
+func TestRouteChain_MatchesPerRequestChain(t *testing.T) {
+	s := NewHTTPServer()
+	routes := []string{
+		"/",
+		"/users",
+		"/users/:id",
+		"/users/:id/posts",
+		"/accounts/{id:int}/files/{file:uuid}",
+		"/orders/:id([0-9]+)",
+		"/static/*",
+		"/api/v1/items/:item",
+	}
+	for _, route := range routes {
+		s.Get(route, func(ctx *Context) {})
+	}
+
+	middlewares := []string{
+		"/*",
+		"/",
+		"/users",
+		"/users/42",
+		"/users/:id",
+		"/users/:name/posts",
+		"/users/{id:int}",
+		"/accounts/{id:int}",
+		"/accounts/{id:int}/files/{file:uuid}",
+		"/orders/:id([0-9]+)",
+		"/static/*",
+		"/static/css",
+		"/api/*",
+		"/api/v1/items/{item:alpha}",
+		"/users/",
+	}
+	for _, path := range middlewares {
+		s.Use(http.MethodGet, path, recordMiddleware(path))
+	}
+	s.Middleware().For(http.MethodGet, "/users/*").Except(http.MethodGet, "/users/7").Add(recordMiddleware("except"))
+	s.Middleware().Global().Priority(10).Add(recordMiddleware("priority"))
+
+	paths := []string{
+		"/",
+		"/users",
+		"/users/42",
+		"/users/7",
+		"/users/alice",
+		"/users/42/posts",
+		"/accounts/42/files/6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"/accounts/42/files/6ba7b810-9dad-11d1-80b4-00c04fd430c8/",
+		"/orders/12",
+		"/static/css/site.css",
+		"/static/js/app.js",
+		"/static",
+		"/api/v1/items/book",
+		"/api/v1/items/42",
+		"/users/42/",
+		"/users//42",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			ctx := &Context{UserValues: make(map[string]any)}
+			n, ok := s.findHandler(http.MethodGet, path, ctx)
+			require.True(t, ok)
+
+			var want []string
+			for _, mw := range sortMiddlewares(collectMatchingMiddlewares(s.middlewares[http.MethodGet], path)) {
+				want = append(want, recordName(mw))
+			}
+
+			ctx.UserValues = make(map[string]any)
+			s.handlerFor(http.MethodGet, n.entry, path)(ctx)
+			got, _ := ctx.UserValues["mw"].([]string)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+// recordName 执行单个中间件得到它记录的名称
+func recordName(mw MiddlewareWithPath) string {
+	ctx := &Context{UserValues: make(map[string]any)}
+	mw.Middleware(func(*Context) {})(ctx)
+	return ctx.UserValues["mw"].([]string)[0]
+}
+
+func TestRouteChain_Cached(t *testing.T) {
+	s := NewHTTPServer()
+	var calls []string
+	s.Get("/users/:id", func(ctx *Context) {
+		calls = append(calls, "handler")
+	})
+	s.Use(http.MethodGet, "/users/:id", func(next HandlerFunc) HandlerFunc {
+		calls = append(calls, "build")
+		return func(ctx *Context) {
+			calls = append(calls, "users")
+			next(ctx)
+		}
+	})
+
+	serve := func(path string) {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	serve("/users/1")
+	serve("/users/2")
+	assert.Equal(t, []string{"build", "users", "handler", "users", "handler"}, calls,
+		"the chain is composed once and reused")
+
+	// 添加中间件后重新组合处理链
+	calls = nil
+	s.Middleware().Global().Named("global").Add(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			calls = append(calls, "global")
+			next(ctx)
+		}
+	})
+	serve("/users/3")
+	assert.Equal(t, []string{"build", "global", "users", "handler"}, calls)
+
+	// 跳过中间件同样使缓存失效
+	calls = nil
+	s.Get("/health", func(ctx *Context) {
+		calls = append(calls, "health")
+	}).SkipMiddleware("global")
+	serve("/health")
+	assert.Equal(t, []string{"health"}, calls)
+}
+
+func TestRouteEntry_MatchPath(t *testing.T) {
+	testCases := []struct {
+		route   string
+		pattern string
+		want    matchResult
+	}{
+		{"/users/:id", "/*", matchAlways},
+		{"/users/:id", "/users", matchAlways},
+		{"/users/:id", "/admin", matchNever},
+		{"/users/:id", "/users/42", matchMaybe},
+		{"/users/{id:int}", "/users/bob", matchNever},
+		{"/users/:id", "/users/:name", matchAlways},
+		{"/users/:id", "/users/:id/posts", matchNever},
+		{"/users/{id:int}", "/users/{id:int}", matchAlways},
+		{"/users/:id", "/users/{id:int}", matchMaybe},
+		{"/files/*", "/files/*", matchAlways},
+		{"/files/*", "/files/css", matchMaybe},
+		{"/files/*", "/static/*", matchNever},
+		{"/a/:b/c", "/a/:x/d", matchNever},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.route+" "+tc.pattern, func(t *testing.T) {
+			e := newRouteEntry(tc.route, nil)
+			assert.Equal(t, tc.want, e.matchPath(classifyMiddlewareType(tc.pattern), tc.pattern))
+		})
+	}
+}
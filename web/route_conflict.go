@@ -0,0 +1,71 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+	"github.com/fyerfyer/fyer-webframe/web/router"
+)
+
+// RouteConflict 关闭严格路由时注册失败的路由
+type RouteConflict struct {
+	Method string
+	// Route 注册失败的路由模式
+	Route string
+	// Existing 与之冲突的已注册路由模式，路由模式无效时为空
+	Existing string
+	// Err 失败原因，可以使用 errors.Is 与 router.ErrDuplicateRoute、
+	// router.ErrConflictingRoute 和 router.ErrInvalidRoute 比较
+	Err error
+}
+
+func (c RouteConflict) String() string {
+	if c.Existing == "" {
+		return fmt.Sprintf("%s %s: %v", c.Method, c.Route, c.Err)
+	}
+	return fmt.Sprintf("%s %s conflicts with %s: %v", c.Method, c.Route, c.Existing, c.Err)
+}
+
+// WithStrictRouting 设置注册重复、冲突或无效的路由时是否panic，默认为true。
+// 关闭后这些路由不会被注册，通过 Validate 获取，服务器启动时记录警告日志，
+// 适用于由多个插件组合路由的应用
+func WithStrictRouting(strict bool) ServerOption {
+	return func(server *HTTPServer) {
+		server.collectConflicts = !strict
+	}
+}
+
+// Validate 返回关闭严格路由后注册失败的路由，按注册顺序排列
+func (r *Router) Validate() []RouteConflict {
+	return append([]RouteConflict(nil), r.conflicts...)
+}
+
+// rejectRoute 处理注册失败的路由，严格路由下panic，否则记录冲突
+func (r *Router) rejectRoute(method, path string, err error) {
+	if !r.collectConflicts {
+		panic(err.Error())
+	}
+	conflict := RouteConflict{Method: method, Route: path, Err: err}
+	var routeErr *router.RouteError
+	if errors.As(err, &routeErr) {
+		conflict.Existing = routeErr.Existing
+	}
+	r.conflicts = append(r.conflicts, conflict)
+}
+
+// invalidRoutePath 返回路由路径格式错误
+func invalidRoutePath(path, reason string) error {
+	return &router.RouteError{Route: path, Reason: reason, Err: router.ErrInvalidRoute}
+}
+
+// logRouteConflicts 启动时记录注册失败的路由
+func (s *HTTPServer) logRouteConflicts() {
+	for _, conflict := range s.Validate() {
+		s.logger.Warn("Route not registered",
+			logger.String("method", conflict.Method),
+			logger.String("route", conflict.Route),
+			logger.String("existing", conflict.Existing),
+			logger.FieldError(conflict.Err))
+	}
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/web/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StrictRouting(t *testing.T) {
+	s := NewHTTPServer()
+	s.Get("/users/:id", func(ctx *Context) {})
+
+	assert.Panics(t, func() {
+		s.Get("/users/:id", func(ctx *Context) {})
+	})
+	assert.Panics(t, func() {
+		s.Get("users", func(ctx *Context) {})
+	})
+	assert.Empty(t, s.Validate())
+}
+
+func TestServer_Validate(t *testing.T) {
+	s := NewHTTPServer(WithStrictRouting(false))
+	s.Get("/users/:id", func(ctx *Context) { ctx.String(http.StatusOK, "first") })
+	s.Get("/users/:id", func(ctx *Context) { ctx.String(http.StatusOK, "second") })
+	s.Get("/users/:name", func(ctx *Context) {})
+	s.Get("/files/*/meta", func(ctx *Context) {})
+	s.Post("//users", func(ctx *Context) {})
+	s.Get("/users/:id/posts", func(ctx *Context) { ctx.String(http.StatusOK, "posts") })
+
+	conflicts := s.Validate()
+	require.Len(t, conflicts, 4)
+
+	assert.Equal(t, "/users/:id", conflicts[0].Route)
+	assert.Equal(t, "/users/:id", conflicts[0].Existing)
+	assert.ErrorIs(t, conflicts[0].Err, router.ErrDuplicateRoute)
+
+	assert.Equal(t, "/users/:name", conflicts[1].Route)
+	assert.Equal(t, "/users/:id", conflicts[1].Existing)
+	assert.ErrorIs(t, conflicts[1].Err, router.ErrConflictingRoute)
+	assert.Equal(t, "GET /users/:name conflicts with /users/:id: conflicting parameter names at same position: 'id' and 'name'",
+		conflicts[1].String())
+
+	assert.ErrorIs(t, conflicts[2].Err, router.ErrInvalidRoute)
+	assert.Equal(t, http.MethodPost, conflicts[3].Method)
+	assert.ErrorIs(t, conflicts[3].Err, router.ErrInvalidRoute)
+	assert.Equal(t, "POST //users: path cannot contain //", conflicts[3].String())
+
+	// 先注册的路由保留，之后的路由照常注册
+	for path, want := range map[string]string{"/users/1": "first", "/users/1/posts": "posts"} {
+		resp := httptest.NewRecorder()
+		s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, resp.Body.String())
+	}
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/files/a/meta", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
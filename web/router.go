@@ -1,9 +1,9 @@
 package web
 
 import (
-	"fmt"
 	"github.com/fyerfyer/fyer-webframe/web/router"
 	"strings"
+	"sync/atomic"
 )
 
 // Router 路由器结构体
@@ -12,6 +12,11 @@ type Router struct {
 	middlewares map[string][]MiddlewareWithPath // 使用http方法作为键值对
 	orderCounter int                 // 用于记录中间件注册顺序
 	radixRouter  *router.Router      // 使用RadixTree实现的新路由器
+	redirectTrailingSlash bool       // 是否重定向带尾部斜杠的请求
+	chainVersion atomic.Uint64       // 中间件版本，中间件变化时递增，使缓存的处理链失效
+	collectConflicts bool            // 注册失败的路由是否记录到 conflicts 而不是panic
+	conflicts    []RouteConflict     // 注册失败的路由
+	routeEntries map[string]*routeEntry // 按"方法 路径"存储的已注册路由
 }
 
 // node 节点结构，用于向后兼容
@@ -20,6 +25,7 @@ type node struct {
 	children            map[string]*node
 	parent              *node
 	handler             HandlerFunc
+	entry               *routeEntry
 	hasStarParam        bool
 	isParam             bool
 	hasParamChild       bool
@@ -40,16 +46,22 @@ func NewRouter() *Router {
 		middlewares: make(map[string][]MiddlewareWithPath, 10),
 		orderCounter: 0,
 		radixRouter: router.New(),
+		routeEntries: make(map[string]*routeEntry),
 	}
 }
 
 // Use 为指定的HTTP方法和路径注册中间件
 func (r *Router) Use(method string, path string, m Middleware) {
+	r.useMiddleware(method, path, m, middlewareOptions{})
+}
+
+// useMiddleware 按注册选项为指定的HTTP方法和路径注册中间件
+func (r *Router) useMiddleware(method string, path string, m Middleware, opts middlewareOptions) {
 	// 如果没有指定方法，则默认注册所有方法
 	if method == "" {
 		methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
 		for _, method := range methods {
-			r.Use(method, path, m)
+			r.useMiddleware(method, path, m, opts)
 		}
 		return
 	}
@@ -78,9 +90,13 @@ func (r *Router) Use(method string, path string, m Middleware) {
 		Type:       mwType,
 		Order:      r.orderCounter,
 		Source:     source,
+		Name:       opts.name,
+		Priority:   opts.priority,
+		Excepts:    opts.exceptPaths(method),
 	}
 
 	r.middlewares[method] = append(r.middlewares[method], mwWithPath)
+	r.invalidateChains()
 }
 
 // findMatchedNodes 查找匹配的节点，用于向后兼容
@@ -125,28 +141,57 @@ func (r *Router) Options(path string, handlerFunc HandlerFunc) {
 	r.addHandler("OPTIONS", path, handlerFunc)
 }
 
+// Head 注册HEAD方法路由，未注册时HEAD请求由对应的GET路由响应
+func (r *Router) Head(path string, handlerFunc HandlerFunc) {
+	r.addHandler("HEAD", path, handlerFunc)
+}
+
+// routePath 返回路由注册时实际使用的路径，启用尾部斜杠重定向时去掉尾部斜杠
+func (r *Router) routePath(path string) string {
+	if !r.redirectTrailingSlash || len(path) <= 1 || path[len(path)-1] != '/' {
+		return path
+	}
+	if path = strings.TrimRight(path, "/"); path == "" {
+		return "/"
+	}
+	return path
+}
+
 // addHandler 注册路由处理函数
 func (r *Router) addHandler(method string, path string, handlerFunc HandlerFunc) {
 	// 路由校验
 	if path == "" {
-		panic("path cannot be empty")
+		r.rejectRoute(method, path, invalidRoutePath(path, "path cannot be empty"))
+		return
 	}
 
 	if path[0] != '/' {
-		panic("path must begin with /")
+		r.rejectRoute(method, path, invalidRoutePath(path, "path must begin with /"))
+		return
 	}
 
 	if len(path) > 1 && path[len(path)-1] == '/' {
-		panic("path must not end with /")
+		if !r.redirectTrailingSlash {
+			r.rejectRoute(method, path, invalidRoutePath(path, "path must not end with /"))
+			return
+		}
+		// 启用尾部斜杠重定向时按不带斜杠的路由注册
+		path = r.routePath(path)
 	}
 
 	// 检查是否包含连续的斜杠
 	if strings.Contains(path, "//") {
-		panic("path cannot contain //")
+		r.rejectRoute(method, path, invalidRoutePath(path, "path cannot contain //"))
+		return
 	}
 
-	// 使用新的RadixTree路由器添加路由
-	r.radixRouter.Handle(method, path, handlerFunc)
+	// 使用新的RadixTree路由器添加路由，失败时路由树保持不变
+	entry := newRouteEntry(path, handlerFunc)
+	if err := r.radixRouter.TryHandle(method, path, entry); err != nil {
+		r.rejectRoute(method, path, err)
+		return
+	}
+	r.routeEntries[method+" "+path] = entry
 
 	// 向后兼容：同时更新旧的路由树结构以保证测试通过
 	if r.routerTrees[method] == nil {
@@ -186,9 +231,12 @@ func (r *Router) addHandler(method string, path string, handlerFunc HandlerFunc)
 			current = current.children["*"]
 			current.handler = handlerFunc
 			break  // 通配符必须是最后一段
-		} else if segment[0] == ':' {
+		} else if segment[0] == ':' || segment[0] == '{' {
 			// 参数处理
 			paramName := segment[1:]
+			if name, _, ok := router.ParseConstraintSegment(segment); ok {
+				paramName = name
+			}
 			isRegex := false
 
 			if strings.Contains(paramName, "(") {
@@ -241,43 +289,39 @@ func (r *Router) addHandler(method string, path string, handlerFunc HandlerFunc)
 
 // findHandler 查找路由处理函数
 func (r *Router) findHandler(method string, path string, ctx *Context) (*node, bool) {
-	if ctx.Param == nil {
-        ctx.Param = make(map[string]string)
-    }
-
-	//fmt.Printf("[DEBUG] Finding handler for %s %s\n", method, path)
-	
-	// 初始化参数映射
-	params := router.AcquireParams()
-	defer router.ReleaseParams(params)
-
-	// 使用新的RadixTree查找路由处理函数
-	handler, ok := r.radixRouter.Find(method, path, params)
+	entry, ok := r.lookupRoute(method, path, ctx)
 	if !ok {
-		fmt.Printf("[DEBUG] No handler found for %s %s\n", method, path)
 		return nil, false
 	}
 
-	//fmt.Printf("[DEBUG] Found handler for %s %s with params: %v\n", method, path, params)
-
-	// 将找到的路径参数复制到上下文中
-	for k, v := range params {
-		ctx.Param[k] = v
-		//fmt.Printf("[DEBUG] Added param to ctx: %s=%s\n", k, v)
-	}
-
 	tempNode := &node{
 		path:    path,
-		handler: handler.(HandlerFunc),
-		Param: 	 make(map[string]string),
+		handler: entry.run,
+		entry:   entry,
+		Param:   ctx.PathParams(),
 	}
 
-	for k, v := range params {
-        tempNode.Param[k] = v
-		//fmt.Printf("[DEBUG] Added param to tempNode: %s=%s\n", k, v)
-    }
-
 	//fmt.Printf("[DEBUG] Returning node with Param: %v\n", tempNode.Param)
 
 	return tempNode, true
+}
+
+// lookupRoute 查找路由，将路由模式和路径参数写入上下文。
+// 参数值追加到上下文复用的切片中，参数名与路由共享，查找过程不分配内存
+func (r *Router) lookupRoute(method string, path string, ctx *Context) (*routeEntry, bool) {
+	//fmt.Printf("[DEBUG] Finding handler for %s %s\n", method, path)
+
+	m, ok := r.radixRouter.Lookup(method, path, ctx.paramValues[:0])
+	if !ok {
+		return nil, false
+	}
+
+	//fmt.Printf("[DEBUG] Found handler for %s %s with params: %v\n", method, path, m.ParamValues)
+
+	// 记录匹配到的路由模式和路径参数
+	ctx.RouteURL = m.Route
+	ctx.paramNames = m.ParamNames
+	ctx.paramValues = m.ParamValues
+
+	return m.Handler.(*routeEntry), true
 }
\ No newline at end of file
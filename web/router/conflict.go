@@ -0,0 +1,57 @@
+package router
+
+import "errors"
+
+var (
+	// ErrDuplicateRoute 相同的路由已经注册
+	ErrDuplicateRoute = errors.New("duplicate route")
+	// ErrConflictingRoute 路由与已注册的路由在同一位置使用了不兼容的参数
+	ErrConflictingRoute = errors.New("conflicting route")
+	// ErrInvalidRoute 路由模式无效
+	ErrInvalidRoute = errors.New("invalid route")
+)
+
+// RouteError 注册路由失败的原因，可以使用 errors.Is 判断错误类型
+type RouteError struct {
+	// Route 注册失败的路由模式
+	Route string
+	// Existing 与之冲突的已注册路由模式，路由模式无效时为空
+	Existing string
+	// Reason 失败原因的描述
+	Reason string
+	// Err 错误类型：ErrDuplicateRoute、ErrConflictingRoute 或 ErrInvalidRoute
+	Err error
+}
+
+func (e *RouteError) Error() string {
+	return e.Reason
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+func duplicateRoute(route, existing, reason string) *RouteError {
+	return &RouteError{Route: route, Existing: existing, Reason: reason, Err: ErrDuplicateRoute}
+}
+
+func conflictingRoute(route, existing, reason string) *RouteError {
+	return &RouteError{Route: route, Existing: existing, Reason: reason, Err: ErrConflictingRoute}
+}
+
+func invalidRoute(route, reason string) *RouteError {
+	return &RouteError{Route: route, Reason: reason, Err: ErrInvalidRoute}
+}
+
+// firstRoute 返回节点及其子树中按字典序最小的路由模式，用于在冲突信息中指出已注册的路由
+func (n *Node) firstRoute() string {
+	var nodes []*Node
+	collectHandlerNodes(n, &nodes)
+	first := ""
+	for _, node := range nodes {
+		if first == "" || node.route < first {
+			first = node.route
+		}
+	}
+	return first
+}
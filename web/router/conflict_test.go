@@ -0,0 +1,75 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadixTree_TryAdd(t *testing.T) {
+	tree := NewRadixTree()
+	require.NoError(t, tree.TryAdd(http.MethodGet, "/", "root"))
+	require.NoError(t, tree.TryAdd(http.MethodGet, "/users/:id", "user"))
+	require.NoError(t, tree.TryAdd(http.MethodGet, "/orders/:id([0-9]+)/items", "items"))
+	require.NoError(t, tree.TryAdd(http.MethodGet, "/files/*", "files"))
+
+	testCases := []struct {
+		path     string
+		kind     error
+		existing string
+	}{
+		{"/", ErrDuplicateRoute, "/"},
+		{"/users/:id", ErrDuplicateRoute, "/users/:id"},
+		{"/users/{id}", ErrDuplicateRoute, "/users/:id"},
+		{"/users/:name", ErrConflictingRoute, "/users/:id"},
+		{"/orders/:id([a-z]+)", ErrConflictingRoute, "/orders/:id([0-9]+)/items"},
+		{"/files/*", ErrDuplicateRoute, "/files/*"},
+		{"/files/*/meta", ErrInvalidRoute, ""},
+		{"/items/{id:number}", ErrInvalidRoute, ""},
+		{"/items/:id([0-9]+", ErrInvalidRoute, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			err := tree.TryAdd(http.MethodGet, tc.path, "conflict")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.kind)
+
+			var routeErr *RouteError
+			require.True(t, errors.As(err, &routeErr))
+			assert.Equal(t, tc.path, routeErr.Route)
+			assert.Equal(t, tc.existing, routeErr.Existing)
+		})
+	}
+	assert.Equal(t, 4, tree.Routes())
+}
+
+func TestRadixTree_TryAdd_Rollback(t *testing.T) {
+	tree := NewRadixTree()
+	tree.Add(http.MethodGet, "/users/:id", "user")
+
+	// 插入失败前创建的静态节点 x 被撤销，否则 /users/x 会匹配到没有处理函数的节点
+	err := tree.TryAdd(http.MethodGet, "/users/x/:name([a-z", "bad")
+	require.ErrorIs(t, err, ErrInvalidRoute)
+
+	handler, found := tree.Find(http.MethodGet, "/users/x", make(map[string]string))
+	assert.True(t, found)
+	assert.Equal(t, "user", handler)
+
+	err = tree.TryAdd(http.MethodGet, "/users/{id:int}/files/*/x", "bad")
+	require.ErrorIs(t, err, ErrInvalidRoute)
+	err = tree.TryAdd(http.MethodGet, "/users/{id:int}/:a(x)/{b:bogus}", "bad")
+	require.ErrorIs(t, err, ErrInvalidRoute)
+
+	handler, found = tree.Find(http.MethodGet, "/users/42", make(map[string]string))
+	assert.True(t, found)
+	assert.Equal(t, "user", handler)
+	assert.Equal(t, 1, tree.Routes())
+
+	assert.PanicsWithValue(t, "duplicate router 'users/:id' registered", func() {
+		tree.Add(http.MethodGet, "/users/:id", "user")
+	})
+}
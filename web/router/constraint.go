@@ -0,0 +1,183 @@
+package router
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Constraint 路径参数的类型约束，在路由中写作 {name:constraint}，例如 /users/{id:int}。
+// 约束使用普通函数匹配路径段，比正则参数快，匹配成功的参数可以通过 Parse 转换为对应的类型
+type Constraint struct {
+	// Name 约束名称
+	Name string
+	// Match 判断路径段是否满足约束
+	Match func(segment string) bool
+	// Parse 将满足约束的路径段转换为类型化的值，为nil时返回原字符串
+	Parse func(segment string) (any, error)
+	// Pattern 与约束等价的正则表达式，用于生成文档
+	Pattern string
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]*Constraint{
+		"int": {
+			Name:    "int",
+			Match:   isInt,
+			Parse:   func(s string) (any, error) { return strconv.ParseInt(s, 10, 64) },
+			Pattern: "-?[0-9]+",
+		},
+		"uint": {
+			Name:    "uint",
+			Match:   isUint,
+			Parse:   func(s string) (any, error) { return strconv.ParseUint(s, 10, 64) },
+			Pattern: "[0-9]+",
+		},
+		"alpha": {
+			Name:    "alpha",
+			Match:   func(s string) bool { return allBytes(s, isAlpha) },
+			Pattern: "[a-zA-Z]+",
+		},
+		"alnum": {
+			Name:    "alnum",
+			Match:   func(s string) bool { return allBytes(s, isAlnum) },
+			Pattern: "[a-zA-Z0-9]+",
+		},
+		"hex": {
+			Name:    "hex",
+			Match:   func(s string) bool { return allBytes(s, isHex) },
+			Pattern: "[0-9a-fA-F]+",
+		},
+		"uuid": {
+			Name:    "uuid",
+			Match:   isUUID,
+			Parse:   func(s string) (any, error) { return uuid.Parse(s) },
+			Pattern: "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}",
+		},
+	}
+)
+
+// RegisterConstraint 注册自定义约束，同名约束会被覆盖，需要在注册使用该约束的路由之前调用
+func RegisterConstraint(c *Constraint) {
+	if c == nil || c.Name == "" || c.Match == nil {
+		panic("constraint must have a name and a match function")
+	}
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	constraints[c.Name] = c
+}
+
+// LookupConstraint 按名称查找约束
+func LookupConstraint(name string) (*Constraint, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+	c, ok := constraints[name]
+	return c, ok
+}
+
+// ParseConstraintSegment 解析 {name:constraint} 或 {name} 形式的路径段，
+// 不是这种形式时 ok 为false
+func ParseConstraintSegment(segment string) (name, constraint string, ok bool) {
+	if len(segment) < 3 || segment[0] != '{' || segment[len(segment)-1] != '}' {
+		return "", "", false
+	}
+	name, constraint, _ = strings.Cut(segment[1:len(segment)-1], ":")
+	if name == "" {
+		return "", "", false
+	}
+	return name, constraint, true
+}
+
+// ParamConstraint 返回路由模式 route 中参数 name 的约束，参数没有约束时 ok 为false
+func ParamConstraint(route, name string) (*Constraint, bool) {
+	for _, segment := range strings.Split(strings.Trim(route, "/"), "/") {
+		paramName, constraint, ok := ParseConstraintSegment(segment)
+		if !ok || paramName != name || constraint == "" {
+			continue
+		}
+		return LookupConstraint(constraint)
+	}
+	return nil, false
+}
+
+// ParseValue 按约束将路径段转换为类型化的值
+func (c *Constraint) ParseValue(segment string) (any, error) {
+	if !c.Match(segment) {
+		return nil, fmt.Errorf("value %q does not match constraint %s", segment, c.Name)
+	}
+	if c.Parse == nil {
+		return segment, nil
+	}
+	return c.Parse(segment)
+}
+
+// isInt 可选的负号加数字，超过18位时检查是否溢出
+func isInt(s string) bool {
+	digits := s
+	if digits != "" && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if !allBytes(digits, isDigit) {
+		return false
+	}
+	if len(digits) < 19 {
+		return true
+	}
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isUint 只包含数字，超过18位时检查是否溢出
+func isUint(s string) bool {
+	if !allBytes(s, isDigit) {
+		return false
+	}
+	if len(s) < 19 {
+		return true
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func allBytes(s string, fn func(byte) bool) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !fn(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func isAlpha(b byte) bool { return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' }
+
+func isAlnum(b byte) bool { return isAlpha(b) || isDigit(b) }
+
+func isHex(b byte) bool { return isDigit(b) || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F' }
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadixTree_Find_Constraint(t *testing.T) {
+	tree := NewRadixTree()
+	tree.Add(http.MethodGet, "/users/{id:int}", "user")
+	tree.Add(http.MethodGet, "/users/{slug:alpha}", "slug")
+	tree.Add(http.MethodGet, "/users/me", "me")
+	tree.Add(http.MethodGet, "/files/{id:uuid}/meta", "file")
+	tree.Add(http.MethodGet, "/tags/{name}", "tag")
+	tree.Add(http.MethodGet, "/orders/:id([a-z]+[0-9]+)", "order")
+	tree.Add(http.MethodGet, "/orders/{id:uint}", "order-id")
+
+	testCases := []struct {
+		path       string
+		wantFound  bool
+		wantRoute  string
+		wantParams map[string]string
+	}{
+		{"/users/42", true, "/users/{id:int}", map[string]string{"id": "42"}},
+		{"/users/-42", true, "/users/{id:int}", map[string]string{"id": "-42"}},
+		{"/users/alice", true, "/users/{slug:alpha}", map[string]string{"slug": "alice"}},
+		{"/users/me", true, "/users/me", map[string]string{}},
+		{"/users/alice42", false, "", nil},
+		{"/users/99999999999999999999", false, "", nil},
+		{"/files/6ba7b810-9dad-11d1-80b4-00c04fd430c8/meta", true, "/files/{id:uuid}/meta",
+			map[string]string{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}},
+		{"/files/6ba7b810/meta", false, "", nil},
+		{"/tags/go", true, "/tags/{name}", map[string]string{"name": "go"}},
+		// 约束优先于正则
+		{"/orders/12", true, "/orders/{id:uint}", map[string]string{"id": "12"}},
+		{"/orders/ab12", true, "/orders/:id([a-z]+[0-9]+)", map[string]string{"id": "ab12"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			params := make(map[string]string)
+			_, route, found := tree.FindRoute(http.MethodGet, tc.path, params)
+			require.Equal(t, tc.wantFound, found)
+			if found {
+				assert.Equal(t, tc.wantRoute, route)
+				assert.Equal(t, tc.wantParams, params)
+			}
+		})
+	}
+	assert.Equal(t, 7, tree.Routes())
+}
+
+func TestRadixTree_ConstraintErrors(t *testing.T) {
+	tree := NewRadixTree()
+	tree.Add(http.MethodGet, "/users/{id:int}", "user")
+
+	assert.Panics(t, func() {
+		tree.Add(http.MethodGet, "/users/{id:int}", "user")
+	}, "duplicate constraint route")
+	assert.Panics(t, func() {
+		tree.Add(http.MethodGet, "/items/{id:number}", "item")
+	}, "unknown constraint")
+	assert.Panics(t, func() {
+		tree.Add(http.MethodGet, "/items/{:int}", "item")
+	}, "missing parameter name")
+}
+
+func TestConstraint_ParseValue(t *testing.T) {
+	id := uuid.New()
+	testCases := []struct {
+		constraint string
+		segment    string
+		want       any
+		wantErr    bool
+	}{
+		{"int", "-7", int64(-7), false},
+		{"int", "7a", nil, true},
+		{"uint", "7", uint64(7), false},
+		{"uint", "-7", nil, true},
+		{"alpha", "abc", "abc", false},
+		{"alnum", "abc123", "abc123", false},
+		{"alnum", "abc-123", nil, true},
+		{"hex", "00ff", "00ff", false},
+		{"uuid", id.String(), id, false},
+		{"uuid", "not-a-uuid", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.constraint+"/"+tc.segment, func(t *testing.T) {
+			c, ok := LookupConstraint(tc.constraint)
+			require.True(t, ok)
+			got, err := c.ParseValue(tc.segment)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRegisterConstraint(t *testing.T) {
+	RegisterConstraint(&Constraint{
+		Name:  "lang",
+		Match: func(s string) bool { return s == "en" || s == "zh" },
+	})
+
+	tree := NewRadixTree()
+	tree.Add(http.MethodGet, "/{lang:lang}/docs", "docs")
+
+	params := make(map[string]string)
+	_, found := tree.Find(http.MethodGet, "/zh/docs", params)
+	assert.True(t, found)
+	assert.Equal(t, "zh", params["lang"])
+	_, found = tree.Find(http.MethodGet, "/fr/docs", make(map[string]string))
+	assert.False(t, found)
+
+	c, ok := ParamConstraint("/{lang:lang}/docs", "lang")
+	require.True(t, ok)
+	assert.Equal(t, "lang", c.Name)
+	_, ok = ParamConstraint("/{lang:lang}/docs", "docs")
+	assert.False(t, ok)
+}
@@ -3,6 +3,7 @@ package router
 import (
     "fmt"
     "regexp"
+    "slices"
     "strings"
 )
 
@@ -20,12 +21,21 @@ type Node struct {
     // 正则参数子节点，例如 :id([0-9]+)
     regexChildren []*Node
 
+    // 约束参数子节点，例如 {id:int}
+    constraintChildren []*Node
+
     // 通配符子节点，例如 *
     wildcardChild *Node
 
     // 处理函数
     handler interface{}
 
+    // 注册时的完整路由模式，仅在有处理函数的节点上设置
+    route string
+
+    // 路由中按出现顺序排列的参数名，仅在有处理函数的节点上设置
+    paramNames []string
+
     // 是否是参数节点
     isParam bool
     
@@ -36,6 +46,9 @@ type Node struct {
 
     // 正则表达式
     pattern *regexp.Regexp
+
+    // 参数约束
+    constraint *Constraint
 }
 
 // NewNode 创建并返回一个新的节点
@@ -47,18 +60,28 @@ func NewNode() *Node {
     }
 }
 
-// Insert 将路径和对应的处理函数插入到Radix Tree中
+// Insert 将路径和对应的处理函数插入到Radix Tree中，路由冲突或无效时panic
 func (n *Node) Insert(path string, handler interface{}) {
+    if err := n.TryInsert(path, handler); err != nil {
+        panic(err.Error())
+    }
+}
+
+// TryInsert 将路径和对应的处理函数插入到Radix Tree中，路由冲突或无效时返回 *RouteError，
+// 插入失败时撤销已经创建的节点，路由树保持不变
+func (n *Node) TryInsert(path string, handler interface{}) (err error) {
     // 处理根路径特殊情况
     if path == "/" {
         if n.handler != nil {
-            panic(fmt.Sprintf("duplicate route '%s' registered", path))
+            return duplicateRoute(path, n.route, fmt.Sprintf("duplicate route '%s' registered", path))
         }
         n.handler = handler
-        return
+        n.route = path
+        return nil
     }
 
     // 标准化路径格式
+    route := path
     path = strings.Trim(path, "/")
     segments := strings.Split(path, "/")
 
@@ -73,20 +96,36 @@ func (n *Node) Insert(path string, handler interface{}) {
 
     // 只允许一个通配符段
     if wildcardCount > 1 {
-        panic("only one wildcard segment is allowed in path")
+        return invalidRoute(route, "only one wildcard segment is allowed in path")
     }
 
     if wildcardIndex >= 0 && wildcardIndex < len(segments)-1 {
-        panic("wildcard segment must be the last segment in path")
+        return invalidRoute(route, "wildcard segment must be the last segment in path")
     }
 
+    // 记录新建节点的撤销操作，插入失败时按相反顺序执行
+    var undo []func()
+    defer func() {
+        if err != nil {
+            for i := len(undo) - 1; i >= 0; i-- {
+                undo[i]()
+            }
+        }
+    }()
+
     current := n
+    var paramNames []string
     for i, segment := range segments {
         // 跳过空段
         if segment == "" {
             continue
         }
 
+        // 没有约束的 {name} 等价于 :name
+        if name, constraint, ok := ParseConstraintSegment(segment); ok && constraint == "" {
+            segment = ":" + name
+        }
+
         // 根据段类型处理节点
         if segment == "*" {
             // 通配符节点
@@ -97,10 +136,51 @@ func (n *Node) Insert(path string, handler interface{}) {
                     paramChildren: make(map[string]*Node),
                     regexChildren: make([]*Node, 0),
                 }
+                parent := current
+                undo = append(undo, func() { parent.wildcardChild = nil })
             } else if i == len(segments) - 1 && current.wildcardChild.handler != nil {
-                panic(fmt.Sprintf("duplicate router '%s' registered", path))
+                return duplicateRoute(route, current.wildcardChild.route, fmt.Sprintf("duplicate router '%s' registered", path))
             }
             current = current.wildcardChild
+            paramNames = append(paramNames, "*")
+        } else if segment[0] == '{' {
+            // 约束参数节点
+            paramName, constraintName, ok := ParseConstraintSegment(segment)
+            if !ok {
+                return invalidRoute(route, fmt.Sprintf("invalid parameter segment '%s'", segment))
+            }
+            constraint, ok := LookupConstraint(constraintName)
+            if !ok {
+                return invalidRoute(route, fmt.Sprintf("unknown constraint '%s' in '%s'", constraintName, segment))
+            }
+
+            // 相同参数名和约束的节点可以复用，同名参数使用不同约束时按注册顺序匹配
+            var matchingNode *Node
+            for _, constraintNode := range current.constraintChildren {
+                if constraintNode.paramName == paramName && constraintNode.constraint == constraint {
+                    matchingNode = constraintNode
+                    break
+                }
+            }
+
+            if matchingNode == nil {
+                matchingNode = &Node{
+                    path: segment,
+                    children: make(map[string]*Node),
+                    paramChildren: make(map[string]*Node),
+                    regexChildren: make([]*Node, 0),
+                    isParam: true,
+                    paramName: paramName,
+                    constraint: constraint,
+                }
+                parent, siblings := current, current.constraintChildren
+                undo = append(undo, func() { parent.constraintChildren = siblings })
+                current.constraintChildren = append(current.constraintChildren, matchingNode)
+            } else if i == len(segments) - 1 && matchingNode.handler != nil {
+                return duplicateRoute(route, matchingNode.route, fmt.Sprintf("duplicate router '%s' registered", path))
+            }
+            current = matchingNode
+            paramNames = append(paramNames, paramName)
         } else if segment[0] == ':' {
             // 参数节点或正则节点
             paramName := segment[1:]
@@ -112,13 +192,13 @@ func (n *Node) Insert(path string, handler interface{}) {
                 regexStart := strings.Index(paramName, "(")
 
                 if !strings.Contains(paramName, ")") {
-                    panic(fmt.Sprintf("invalid regex pattern in '%s': missing closing parenthesis", segment))
+                    return invalidRoute(route, fmt.Sprintf("invalid regex pattern in '%s': missing closing parenthesis", segment))
                 }
 
                 regexEnd := strings.LastIndex(paramName, ")")
 
                 if regexEnd <= regexStart {
-                    panic(fmt.Sprintf("invalid regex pattern in '%s': misplaced parentheses", segment))
+                    return invalidRoute(route, fmt.Sprintf("invalid regex pattern in '%s': misplaced parentheses", segment))
                 }
 
                 regexStr := paramName[regexStart + 1:regexEnd]
@@ -127,14 +207,15 @@ func (n *Node) Insert(path string, handler interface{}) {
                 // 检查是否有相同参数名的正则节点
                 for _, regexNode := range current.regexChildren {
                     if regexNode.paramName == paramName && regexNode.pattern.String() != "^"+regexStr+"$" {
-                        panic(fmt.Sprintf("conflicting parameter name '%s' with different regex patterns", paramName))
+                        return conflictingRoute(route, regexNode.firstRoute(),
+                            fmt.Sprintf("conflicting parameter name '%s' with different regex patterns", paramName))
                     }
                 }
 
                 var err error
                 pattern, err = regexp.Compile("^" + regexStr + "$")
                 if err != nil {
-                    panic(fmt.Sprintf("invalid regex pattern: %s - %s", regexStr, err))
+                    return invalidRoute(route, fmt.Sprintf("invalid regex pattern: %s - %s", regexStr, err))
                 }
                 isRegex = true
             }
@@ -164,11 +245,14 @@ func (n *Node) Insert(path string, handler interface{}) {
                         paramName: paramName,
                         pattern: pattern,
                     }
+                    parent, siblings := current, current.regexChildren
+                    undo = append(undo, func() { parent.regexChildren = siblings })
                     current.regexChildren = append(current.regexChildren, matchingNode)
                 } else if i == len(segments) - 1 && matchingNode.handler != nil {
-                    panic(fmt.Sprintf("duplicate router '%s' registered", path))
+                    return duplicateRoute(route, matchingNode.route, fmt.Sprintf("duplicate router '%s' registered", path))
                 }
                 current = matchingNode
+                paramNames = append(paramNames, paramName)
             } else {
                 // 普通参数节点 - 使用参数名称作为键
                 
@@ -182,7 +266,8 @@ func (n *Node) Insert(path string, handler interface{}) {
                     for existingParamName, existingParamNode := range current.paramChildren {
                         // 如果参数名不同，且已有节点是终止节点（有处理函数）
                         if existingParamName != paramName && existingParamNode.handler != nil {
-                            panic(fmt.Sprintf("conflicting parameter names at same position: '%s' and '%s'", 
+                            return conflictingRoute(route, existingParamNode.route,
+                                fmt.Sprintf("conflicting parameter names at same position: '%s' and '%s'",
                                 existingParamName, paramName))
                         }
                     }
@@ -198,13 +283,17 @@ func (n *Node) Insert(path string, handler interface{}) {
                         isParam: true,
                         paramName: paramName,
                     }
+                    parent := current
+                    undo = append(undo, func() { delete(parent.paramChildren, paramName) })
                 } else if i == len(segments) - 1 && current.paramChildren[paramName].handler != nil &&
                           len(current.paramChildren[paramName].children) == 0 {
                     // 只在没有子节点的情况下不允许重复注册
-                    panic(fmt.Sprintf("duplicate router '%s' registered", path))
+                    return duplicateRoute(route, current.paramChildren[paramName].route,
+                        fmt.Sprintf("duplicate router '%s' registered", path))
                 }
                 // 移动到对应参数名的节点
                 current = current.paramChildren[paramName]
+                paramNames = append(paramNames, paramName)
             }
         } else {
             // 静态节点
@@ -217,8 +306,10 @@ func (n *Node) Insert(path string, handler interface{}) {
                     regexChildren: make([]*Node, 0),
                 }
                 current.children[segment] = child
+                parent := current
+                undo = append(undo, func() { delete(parent.children, segment) })
             } else if i == len(segments) - 1 && child.handler != nil {
-                panic(fmt.Sprintf("duplicate router '%s' registered", path))
+                return duplicateRoute(route, child.route, fmt.Sprintf("duplicate router '%s' registered", path))
             }
             current = child
         }
@@ -226,47 +317,129 @@ func (n *Node) Insert(path string, handler interface{}) {
         // 如果是最后一个段，设置处理函数
         if i == len(segments) - 1 {
             current.handler = handler
+            current.route = route
+            // 去掉多余的容量，调用方向返回的切片追加元素时不会修改节点中的参数名
+            current.paramNames = slices.Clip(paramNames)
         }
     }
+    return nil
+}
+
+// Match 路由匹配的结果
+type Match struct {
+    // Handler 处理函数
+    Handler interface{}
+    // Route 注册时的路由模式
+    Route string
+    // ParamNames 路由中按出现顺序排列的参数名，所有请求共享，不能修改
+    ParamNames []string
+    // ParamValues 与 ParamNames 一一对应的参数值
+    ParamValues []string
 }
 
 // Find 在Radix Tree中查找匹配的处理函数（迭代实现）
 func (n *Node) Find(path string, params map[string]string) (interface{}, bool) {
+    m, ok := n.Lookup(path, nil)
+    if !ok {
+        return nil, false
+    }
+    m.copyParams(params)
+    return m.Handler, true
+}
+
+// FindRoute 查找匹配的处理函数，同时返回注册时的路由模式
+func (n *Node) FindRoute(path string, params map[string]string) (interface{}, string, bool) {
+    m, ok := n.Lookup(path, nil)
+    if !ok {
+        return nil, "", false
+    }
+    m.copyParams(params)
+    return m.Handler, m.Route, true
+}
+
+// Lookup 查找匹配的处理函数，参数值按路由中出现的顺序追加到 values 中，
+// 传入上一次返回的 ParamValues[:0] 可以复用底层数组，查找过程不分配内存
+func (n *Node) Lookup(path string, values []string) (Match, bool) {
+    matched, values := n.findNode(path, values)
+    if matched == nil {
+        return Match{ParamValues: values[:0]}, false
+    }
+    return Match{
+        Handler:     matched.handler,
+        Route:       matched.route,
+        ParamNames:  matched.paramNames,
+        ParamValues: values,
+    }, true
+}
+
+// copyParams 将参数写入映射
+func (m Match) copyParams(params map[string]string) {
+    for i, name := range m.ParamNames {
+        if i < len(m.ParamValues) {
+            params[name] = m.ParamValues[i]
+        }
+    }
+}
+
+// findNode 查找匹配且带有处理函数的节点，没有匹配时返回nil
+// 按 / 逐段扫描路径而不是切分路径，避免分配内存
+func (n *Node) findNode(path string, values []string) (*Node, []string) {
     // 处理根路径
     if path == "/" {
-        return n.handler, n.handler != nil
+        if n.handler == nil {
+            return nil, values
+        }
+        return n, values
     }
 
     // 标准化路径格式
     path = strings.Trim(path, "/")
-    segments := strings.Split(path, "/")
-
-    //fmt.Printf("[DEBUG] Finding path in RadixTree: %s\n", path)
 
-    // 使用迭代而非递归进行查找
+    // 使用迭代而非递归进行查找，start 为当前段的起始位置
     current := n
-    for i := 0; i < len(segments); {
-        segment := segments[i]
-        //fmt.Printf("[DEBUG] Processing segment: '%s' at index %d\n", segment, i)
+    for start := 0; start <= len(path); {
+        end := strings.IndexByte(path[start:], '/')
+        if end < 0 {
+            end = len(path)
+        } else {
+            end += start
+        }
+        segment := path[start:end]
+        next := end + 1
         if segment == "" {
-            i++
+            start = next
             continue
         }
 
         // 1. 静态匹配 (最高优先级)
         if child, ok := current.children[segment]; ok {
             current = child
-            i++
+            start = next
             continue
         }
 
-        // 2. 正则匹配 (次高优先级)
+        // 2. 约束匹配，约束比正则更具体也更快
+        constraintMatched := false
+        for _, constraintChild := range current.constraintChildren {
+            if constraintChild.constraint.Match(segment) {
+                values = append(values, segment)
+                current = constraintChild
+                start = next
+                constraintMatched = true
+                break
+            }
+        }
+        if constraintMatched {
+            continue
+        }
+
+        // 3. 正则匹配
         regexMatched := false
         for _, regexChild := range current.regexChildren {
             if regexChild.pattern.MatchString(segment) {
-                params[regexChild.paramName] = segment
+                values = append(values, segment)
                 current = regexChild
-                i++
+                start = next
                 regexMatched = true
                 break
             }
@@ -275,17 +448,20 @@ func (n *Node) Find(path string, params map[string]string) (interface{}, bool) {
             continue
         }
 
-        // 3. 参数匹配 (第三优先级)
+        // 4. 参数匹配
         // 尝试所有可能的参数匹配，先检查当前节点路径下是否有可以继续匹配的
         paramMatched := false
         if len(current.paramChildren) > 0 {
-            for paramName, paramNode := range current.paramChildren {
+            for _, paramNode := range current.paramChildren {
                 // 检查此参数路径是否能匹配后续段
                 canMatchLater := true
 
-                if i < len(segments)-1 {
+                if end < len(path) {
                     // 还有更多段需要匹配
-                    nextSegment := segments[i+1]
+                    nextSegment := path[next:]
+                    if idx := strings.IndexByte(nextSegment, '/'); idx >= 0 {
+                        nextSegment = nextSegment[:idx]
+                    }
 
                     // 检查参数节点的子节点是否能匹配下一段
                     nextSegmentCanMatch := false
@@ -295,6 +471,16 @@ func (n *Node) Find(path string, params map[string]string) (interface{}, bool) {
                         nextSegmentCanMatch = true
                     }
 
+                    // 检查约束子节点
+                    if !nextSegmentCanMatch {
+                        for _, constraintChild := range paramNode.constraintChildren {
+                            if constraintChild.constraint.Match(nextSegment) {
+                                nextSegmentCanMatch = true
+                                break
+                            }
+                        }
+                    }
+
                     // 检查正则子节点
                     if !nextSegmentCanMatch {
                         for _, regexChild := range paramNode.regexChildren {
@@ -320,10 +506,9 @@ func (n *Node) Find(path string, params map[string]string) (interface{}, bool) {
 
                 if canMatchLater {
                     // 这个参数节点可以匹配当前段并且可能能匹配后续段
-                    params[paramName] = segment
-                    //fmt.Printf("[DEBUG] Matched parameter: %s = %s\n", paramName, segment)
+                    values = append(values, segment)
                     current = paramNode
-                    i++
+                    start = next
                     paramMatched = true
                     break
                 }
@@ -335,31 +520,36 @@ func (n *Node) Find(path string, params map[string]string) (interface{}, bool) {
             }
         }
 
-        // 4. 通配符匹配 (最低优先级)
+        // 5. 通配符匹配 (最低优先级)
         if current.wildcardChild != nil {
             // 通配符匹配剩余所有路径
-            remainingPath := strings.Join(segments[i:], "/")
-            params["*"] = remainingPath
-            return current.wildcardChild.handler, current.wildcardChild.handler != nil
+            values = append(values, path[start:])
+            return handlerNode(current.wildcardChild), values
         }
 
         // 没有匹配
-        return nil, false
+        return nil, values
     }
 
     // 完成所有段的匹配后，检查是否有处理函数
     if current.handler != nil {
-        //fmt.Printf("[DEBUG] Final matched params: %v\n", params)
-        return current.handler, true
+        return current, values
     }
 
     // 如果当前节点无处理函数但有通配符子节点，返回通配符子节点的处理函数
     if current.wildcardChild != nil {
-        params["*"] = ""
-        //fmt.Printf("[DEBUG] Final matched params: %v\n", params)
-        return current.wildcardChild.handler, current.wildcardChild.handler != nil
+        values = append(values, "")
+        return handlerNode(current.wildcardChild), values
     }
 
     // 没有匹配的处理函数
-    return nil, false
+    return nil, values
+}
+
+// handlerNode 节点有处理函数时返回节点本身，否则返回nil
+func handlerNode(n *Node) *Node {
+    if n.handler == nil {
+        return nil
+    }
+    return n
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -36,6 +37,18 @@ func (r *RadixTree) Add(method, path string, handler interface{}) {
 	r.trees[method].Insert(path, handler)
 }
 
+// TryAdd 添加一个新的路由，路由冲突或无效时返回 *RouteError 而不是panic，路由树保持不变
+func (r *RadixTree) TryAdd(method, path string, handler interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trees[method]; !ok {
+		r.trees[method] = NewNode()
+	}
+
+	return r.trees[method].TryInsert(path, handler)
+}
+
 // Find 查找给定路径的处理函数
 func (r *RadixTree) Find(method, path string, params map[string]string) (interface{}, bool) {
 	r.mu.RLock()
@@ -51,6 +64,32 @@ func (r *RadixTree) Find(method, path string, params map[string]string) (interfa
 	return root.Find(path, params)
 }
 
+// FindRoute 查找给定路径的处理函数，同时返回匹配的路由模式
+func (r *RadixTree) FindRoute(method, path string, params map[string]string) (interface{}, string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	root, ok := r.trees[method]
+	if !ok {
+		return nil, "", false
+	}
+
+	return root.FindRoute(path, params)
+}
+
+// Lookup 查找给定路径的处理函数，参数值追加到 values 中，不分配内存
+func (r *RadixTree) Lookup(method, path string, values []string) (Match, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	root, ok := r.trees[method]
+	if !ok {
+		return Match{ParamValues: values[:0]}, false
+	}
+
+	return root.Lookup(path, values)
+}
+
 // 为常用HTTP方法提供便捷方法
 
 // GET 注册一个GET方法的路由
@@ -119,6 +158,9 @@ func printNode(sb *strings.Builder, n *Node, level int) {
 	if n.isRegex {
 		sb.WriteString(fmt.Sprintf(" [Regex: %s]", n.pattern.String()))
 	}
+	if n.constraint != nil {
+		sb.WriteString(fmt.Sprintf(" [Constraint: %s]", n.constraint.Name))
+	}
 	sb.WriteString("\n")
 
 	// 打印子节点
@@ -136,6 +178,11 @@ func printNode(sb *strings.Builder, n *Node, level int) {
 		printNode(sb, regexChild, level+1)
 	}
 
+	// 打印约束子节点
+	for _, constraintChild := range n.constraintChildren {
+		printNode(sb, constraintChild, level+1)
+	}
+
 	// 打印通配符子节点
 	if n.wildcardChild != nil {
 		printNode(sb, n.wildcardChild, level+1)
@@ -154,6 +201,52 @@ func (r *RadixTree) Routes() int {
 	return count
 }
 
+// Walk 按HTTP方法和路由模式的字典序遍历所有注册的路由
+func (r *RadixTree) Walk(fn func(method, route string, handler interface{})) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]string, 0, len(r.trees))
+	for method := range r.trees {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		var nodes []*Node
+		collectHandlerNodes(r.trees[method], &nodes)
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].route < nodes[j].route
+		})
+		for _, n := range nodes {
+			fn(method, n.route, n.handler)
+		}
+	}
+}
+
+// collectHandlerNodes 收集所有带有处理函数的节点
+func collectHandlerNodes(n *Node, nodes *[]*Node) {
+	if n == nil {
+		return
+	}
+	if n.handler != nil {
+		*nodes = append(*nodes, n)
+	}
+	for _, child := range n.children {
+		collectHandlerNodes(child, nodes)
+	}
+	for _, paramChild := range n.paramChildren {
+		collectHandlerNodes(paramChild, nodes)
+	}
+	for _, regexChild := range n.regexChildren {
+		collectHandlerNodes(regexChild, nodes)
+	}
+	for _, constraintChild := range n.constraintChildren {
+		collectHandlerNodes(constraintChild, nodes)
+	}
+	collectHandlerNodes(n.wildcardChild, nodes)
+}
+
 // countHandlers 统计节点中的处理函数数量
 func countHandlers(n *Node) int {
 	if n == nil {
@@ -180,6 +273,11 @@ func countHandlers(n *Node) int {
 		count += countHandlers(regexChild)
 	}
 
+	// 统计约束子节点
+	for _, constraintChild := range n.constraintChildren {
+		count += countHandlers(constraintChild)
+	}
+
 	// 统计通配符子节点
 	if n.wildcardChild != nil {
 		count += countHandlers(n.wildcardChild)
@@ -525,4 +525,101 @@ func TestRadixTree_DuplicateRoutes(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestRadixTree_FindRoute(t *testing.T) {
+	tree := NewRadixTree()
+	handler := func() {}
+
+	tree.Add(http.MethodGet, "/", handler)
+	tree.Add(http.MethodGet, "/users/:id", handler)
+	tree.Add(http.MethodGet, "/files/*", handler)
+	tree.Add(http.MethodGet, "/posts/:id([0-9]+)", handler)
+
+	testCases := []struct {
+		path  string
+		route string
+	}{
+		{"/", "/"},
+		{"/users/42", "/users/:id"},
+		{"/files/a/b.txt", "/files/*"},
+		{"/posts/7", "/posts/:id([0-9]+)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			params := make(map[string]string)
+			_, route, found := tree.FindRoute(http.MethodGet, tc.path, params)
+			assert.True(t, found)
+			assert.Equal(t, tc.route, route)
+		})
+	}
+
+	_, _, found := tree.FindRoute(http.MethodGet, "/missing", make(map[string]string))
+	assert.False(t, found)
+}
+
+func TestRadixTree_Walk(t *testing.T) {
+	tree := NewRadixTree()
+	handler := func() {}
+
+	tree.Add(http.MethodPost, "/users", handler)
+	tree.Add(http.MethodGet, "/users/:id", handler)
+	tree.Add(http.MethodGet, "/", handler)
+	tree.Add(http.MethodGet, "/files/*", handler)
+	tree.Add(http.MethodGet, "/posts/:id([0-9]+)", handler)
+
+	var routes []string
+	tree.Walk(func(method, route string, h interface{}) {
+		assert.NotNil(t, h)
+		routes = append(routes, method+" "+route)
+	})
+
+	assert.Equal(t, []string{
+		"GET /",
+		"GET /files/*",
+		"GET /posts/:id([0-9]+)",
+		"GET /users/:id",
+		"POST /users",
+	}, routes)
+}
+
+func TestRadixTree_Lookup(t *testing.T) {
+	tree := NewRadixTree()
+	handler := func() {}
+
+	tree.Add(http.MethodGet, "/orgs/:org/users/{id:int}", handler)
+	tree.Add(http.MethodGet, "/orgs/:org/files/*", handler)
+	tree.Add(http.MethodGet, "/posts/:id([0-9]+)", handler)
+
+	testCases := []struct {
+		path       string
+		wantNames  []string
+		wantValues []string
+	}{
+		{"/orgs/acme/users/42", []string{"org", "id"}, []string{"acme", "42"}},
+		{"/orgs/acme/files/a/b.txt", []string{"org", "*"}, []string{"acme", "a/b.txt"}},
+		{"/orgs/acme/files", []string{"org", "*"}, []string{"acme", ""}},
+		{"/posts/7", []string{"id"}, []string{"7"}},
+	}
+
+	values := make([]string, 0, 4)
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			m, found := tree.Lookup(http.MethodGet, tc.path, values[:0])
+			require.True(t, found)
+			assert.Equal(t, tc.wantNames, m.ParamNames)
+			assert.Equal(t, tc.wantValues, m.ParamValues)
+		})
+	}
+
+	m, found := tree.Lookup(http.MethodGet, "/orgs/acme/users/bob", values[:0])
+	assert.False(t, found)
+	assert.Empty(t, m.ParamValues)
+
+	// 复用参数值切片时查找不分配内存
+	allocs := testing.AllocsPerRun(100, func() {
+		m, _ := tree.Lookup(http.MethodGet, "/orgs/acme/files/a/b.txt", values[:0])
+		values = m.ParamValues
+	})
+	assert.Zero(t, allocs)
+}
@@ -53,16 +53,37 @@ func (r *Router) Handle(method, path string, handler interface{}) {
 	r.tree.Add(method, path, handler)
 }
 
+// TryHandle 注册路由，路由冲突或无效时返回 *RouteError 而不是panic
+func (r *Router) TryHandle(method, path string, handler interface{}) error {
+	return r.tree.TryAdd(method, path, handler)
+}
+
 // Find 根据HTTP方法和路径查找处理函数
 func (r *Router) Find(method, path string, params map[string]string) (interface{}, bool) {
 	return r.tree.Find(method, path, params)
 }
 
+// FindRoute 根据HTTP方法和路径查找处理函数，同时返回匹配的路由模式
+func (r *Router) FindRoute(method, path string, params map[string]string) (interface{}, string, bool) {
+	return r.tree.FindRoute(method, path, params)
+}
+
+// Lookup 根据HTTP方法和路径查找处理函数，参数值按路由中出现的顺序追加到 values 中，
+// 复用上一次返回的 ParamValues 可以避免每次查找分配内存
+func (r *Router) Lookup(method, path string, values []string) (Match, bool) {
+	return r.tree.Lookup(method, path, values)
+}
+
 // Routes 返回路由器中注册的路由数量
 func (r *Router) Routes() int {
 	return r.tree.Routes()
 }
 
+// Walk 遍历所有注册的路由
+func (r *Router) Walk(fn func(method, route string, handler interface{})) {
+	r.tree.Walk(fn)
+}
+
 // PrintRoutes 返回路由树的字符串表示，用于调试
 func (r *Router) PrintRoutes() string {
 	return r.tree.PrintTree()
@@ -24,7 +24,7 @@ func BenchmarkRouter_StaticRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -45,7 +45,7 @@ func BenchmarkRouter_ParamRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/123", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -65,7 +65,7 @@ func BenchmarkRouter_WildcardRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/files/images/avatar.jpg", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -85,7 +85,7 @@ func BenchmarkRouter_RegexRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/123", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -113,7 +113,7 @@ func BenchmarkRouter_MixedRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/products/electronics/123", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -137,7 +137,7 @@ func BenchmarkRouter_MultipleMethods(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodPut, "/api/users/123", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -154,7 +154,7 @@ func BenchmarkRouter_LongPath(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/organizations/123/departments/456/employees/789/profile", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -179,7 +179,7 @@ func BenchmarkRouter_Middleware(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -204,7 +204,7 @@ func BenchmarkRouter_LargeNumberOfRoutes(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/products/499", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -231,7 +231,7 @@ func BenchmarkRouter_WorstCaseScenario(b *testing.B) {
 
 	// 创建测试上下文
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/9999/profile", nil)
-	ctx := &Context{Req: req, Param: make(map[string]string)}
+	ctx := &Context{Req: req}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
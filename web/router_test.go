@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"regexp"
 	"strings"
@@ -141,9 +142,7 @@ func TestWildcardRoute(t *testing.T) {
 
 func TestParamRoute(t *testing.T) {
 	r := NewRouter()
-	mockHandlerFunc := func(ctx *Context) {
-		ctx.Param = make(map[string]string)
-	}
+	mockHandlerFunc := func(ctx *Context) {}
 	testRoutes := []struct {
 		method string
 		path   string
@@ -236,9 +235,7 @@ func TestStaticRouteFound(t *testing.T) {
 
 func TestWildcardRouteFound(t *testing.T) {
 	r := NewRouter()
-	testCtx := &Context{
-		Param: make(map[string]string),
-	}
+	testCtx := &Context{}
 	mockHandlerFunc1 := func(ctx *Context) {}
 	mockHandlerFunc2 := func(ctx *Context) {}
 
@@ -283,9 +280,7 @@ func TestWildcardRouteFound(t *testing.T) {
 
 func TestParamFound(t *testing.T) {
 	r := NewRouter()
-	mockHandlerFunc := func(ctx *Context) {
-		ctx.Param = make(map[string]string)
-	}
+	mockHandlerFunc := func(ctx *Context) {}
 
 	testRoutes := []struct {
 		method string
@@ -363,28 +358,28 @@ func TestRegexRouteFound(t *testing.T) {
 	}
 
 	// Test matching regex path
-	ctx1 := &Context{Param: make(map[string]string)}
+	ctx1 := &Context{}
 	_, ok := r.findHandler(http.MethodGet, "/user/123", ctx1)
 	assert.True(t, ok, "/user/:id([0-9]+) path not found")
-	id, ok := ctx1.Param["id"]
+	id, ok := ctx1.PathParams()["id"]
 	assert.True(t, ok, "param id not found")
 	assert.Equal(t, "123", id, "param id not equal")
 
 	// Test matching another regex path
-	ctx2 := &Context{Param: make(map[string]string)}
+	ctx2 := &Context{}
 	_, ok = r.findHandler(http.MethodGet, "/user/abc/profile", ctx2)
 	assert.True(t, ok, "/user/:name([a-z]+)/profile path not found")
-	name, ok := ctx2.Param["name"]
+	name, ok := ctx2.PathParams()["name"]
 	assert.True(t, ok, "param name not found")
 	assert.Equal(t, "abc", name, "param name not equal")
 
 	// Test non-matching regex
-	ctx3 := &Context{Param: make(map[string]string)}
+	ctx3 := &Context{}
 	_, ok = r.findHandler(http.MethodGet, "/user/abc", ctx3)
 	assert.False(t, ok, "should not match non-numeric id")
 
 	// Test another non-matching regex
-	ctx4 := &Context{Param: make(map[string]string)}
+	ctx4 := &Context{}
 	_, ok = r.findHandler(http.MethodGet, "/user/123/profile", ctx4)
 	assert.False(t, ok, "should not match numeric name")
 }
@@ -484,4 +479,50 @@ func nodeEqual(a, b *node) (string, bool) {
 	}
 
 	return "", true
-}
\ No newline at end of file
+}
+func TestConstraintRoute(t *testing.T) {
+	s := NewHTTPServer()
+	var mwPaths []string
+	s.Use("GET", "/users/{id:int}", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			mwPaths = append(mwPaths, ctx.Req.URL.Path)
+			next(ctx)
+		}
+	})
+	s.Get("/users/{id:int}", func(ctx *Context) {
+		id, err := PathAs[int64](ctx, "id")
+		if err != nil {
+			ctx.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		ctx.String(http.StatusOK, fmt.Sprintf("user %d", id))
+	})
+	s.Get("/users/{name:alpha}", func(ctx *Context) {
+		val, err := ctx.PathValue("name")
+		assert.NoError(t, err)
+		_, err = PathAs[int64](ctx, "name")
+		assert.Error(t, err, "alpha parameter is a string")
+		ctx.String(http.StatusOK, fmt.Sprintf("name %v", val))
+	})
+
+	testCases := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{path: "/users/42", wantCode: http.StatusOK, wantBody: "user 42"},
+		{path: "/users/bob", wantCode: http.StatusOK, wantBody: "name bob"},
+		{path: "/users/bob42", wantCode: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.wantCode, rec.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, rec.Body.String())
+			}
+		})
+	}
+	assert.Equal(t, []string{"/users/42"}, mwPaths, "middleware only runs for paths matching the constraint")
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteInfo 描述一条注册的路由
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares,omitempty"` // 按执行顺序排列的中间件
+	Variants    []string `json:"variants,omitempty"`    // 灰度版本名称
+	Tags        []string `json:"tags,omitempty"`        // 路由标签
+}
+
+// Routes 返回所有注册的路由，按方法和路径排序
+// 中间件链按路由模式推断，条件中间件等运行时才能决定是否执行的中间件同样会列出
+func (s *HTTPServer) Routes() []RouteInfo {
+	var routes []RouteInfo
+	s.radixRouter.Walk(func(method, route string, handler interface{}) {
+		e := handler.(*routeEntry)
+		info := RouteInfo{
+			Method:  method,
+			Path:    s.fullPath(route),
+			Handler: funcName(e.handler),
+		}
+
+		// 与实际执行的处理链一致，去掉路由跳过和排除了该路由的中间件
+		for _, mw := range s.routeChain(method, e).candidates {
+			info.Middlewares = append(info.Middlewares, funcName(mw.Middleware))
+		}
+
+		if cr, ok := s.canaries[method+" "+route]; ok {
+			for _, v := range cr.variants {
+				info.Variants = append(info.Variants, v.name)
+			}
+		}
+
+		info.Tags = s.routeTags[method+" "+route]
+		routes = append(routes, info)
+	})
+	return routes
+}
+
+// fullPath 返回带基础路径前缀的完整路由
+func (s *HTTPServer) fullPath(route string) string {
+	if s.baseRoute == "" {
+		return route
+	}
+	if route == "/" {
+		return s.baseRoute
+	}
+	return strings.TrimSuffix(s.baseRoute, "/") + route
+}
+
+// funcName 返回函数的完整名称
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Sprintf("%T", fn)
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return v.Type().String()
+	}
+	return strings.TrimSuffix(f.Name(), "-fm")
+}
+
+// WithDebugRoutes 注册查看路由列表的调试端点，浏览器访问返回HTML表格，其余请求返回JSON
+// 端点会暴露应用的内部结构，只建议在开发环境启用
+func WithDebugRoutes(path string) ServerOption {
+	return func(server *HTTPServer) {
+		server.Get(path, server.debugRoutesHandler)
+	}
+}
+
+// debugRoutesHandler 路由列表调试端点
+func (s *HTTPServer) debugRoutesHandler(ctx *Context) {
+	routes := s.Routes()
+
+	specs := parseAccept(ctx.GetHeader("Accept"))
+	if ctx.QueryParam("format").Value != "json" && len(specs) > 0 && specs[0].mediaType == "text/html" {
+		ctx.HTML(http.StatusOK, renderRoutesHTML(routes))
+		return
+	}
+	ctx.JSON(http.StatusOK, routes)
+}
+
+// renderRoutesHTML 将路由列表渲染为HTML表格
+func renderRoutesHTML(routes []RouteInfo) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html><head><title>Routes</title></head><body>")
+	sb.WriteString(fmt.Sprintf("<h1>Routes (%d)</h1>", len(routes)))
+	sb.WriteString("<table border=\"1\" cellpadding=\"4\"><tr><th>Method</th><th>Path</th><th>Handler</th><th>Middlewares</th><th>Variants</th><th>Tags</th></tr>")
+	for _, r := range routes {
+		sb.WriteString("<tr><td>")
+		sb.WriteString(html.EscapeString(r.Method))
+		sb.WriteString("</td><td>")
+		sb.WriteString(html.EscapeString(r.Path))
+		sb.WriteString("</td><td>")
+		sb.WriteString(html.EscapeString(r.Handler))
+		sb.WriteString("</td><td>")
+		sb.WriteString(html.EscapeString(strings.Join(r.Middlewares, " → ")))
+		sb.WriteString("</td><td>")
+		sb.WriteString(html.EscapeString(strings.Join(r.Variants, ", ")))
+		sb.WriteString("</td><td>")
+		sb.WriteString(html.EscapeString(strings.Join(r.Tags, ", ")))
+		sb.WriteString("</td></tr>")
+	}
+	sb.WriteString("</table></body></html>")
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listUsers(ctx *Context) {}
+
+func authMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+func TestHTTPServer_Routes(t *testing.T) {
+	s := NewHTTPServer(WithBasePath("/api"))
+	s.Use("GET", "/users", authMiddleware)
+	s.Get("/users", listUsers).Canary("v2", listUsers, CanaryWeight(10))
+	s.Get("/users/:id", listUsers)
+	s.Post("/orders", listUsers).Tags("write")
+	s.Get("/", listUsers)
+
+	routes := s.Routes()
+	require.Len(t, routes, 4)
+
+	assert.Equal(t, "GET", routes[0].Method)
+	assert.Equal(t, "/api", routes[0].Path)
+	assert.Empty(t, routes[0].Middlewares)
+
+	assert.Equal(t, "/api/users", routes[1].Path)
+	assert.Equal(t, "github.com/fyerfyer/fyer-webframe/web.listUsers", routes[1].Handler)
+	require.Len(t, routes[1].Middlewares, 1)
+	assert.Equal(t, "github.com/fyerfyer/fyer-webframe/web.authMiddleware", routes[1].Middlewares[0])
+	assert.Equal(t, []string{"v2"}, routes[1].Variants)
+
+	// 静态路径中间件同样作用于子路径
+	assert.Equal(t, "/api/users/:id", routes[2].Path)
+	assert.Equal(t, []string{"github.com/fyerfyer/fyer-webframe/web.authMiddleware"}, routes[2].Middlewares)
+
+	assert.Equal(t, "POST", routes[3].Method)
+	assert.Equal(t, "/api/orders", routes[3].Path)
+	assert.Equal(t, []string{"write"}, routes[3].Tags)
+}
+
+func TestHTTPServer_RoutesSkipAndExcept(t *testing.T) {
+	s := NewHTTPServer()
+	s.Middleware().Global().Named("auth").Add(authMiddleware)
+	s.Middleware().Global().Except("", "/health").Add(authMiddleware)
+	s.Get("/users", listUsers)
+	s.Get("/health", listUsers)
+	s.Get("/webhook/:id", listUsers).SkipMiddleware("auth")
+
+	routes := s.Routes()
+	require.Len(t, routes, 3)
+	middlewares := make(map[string][]string, len(routes))
+	for _, r := range routes {
+		middlewares[r.Path] = r.Middlewares
+	}
+
+	auth := "github.com/fyerfyer/fyer-webframe/web.authMiddleware"
+	assert.Equal(t, []string{auth, auth}, middlewares["/users"])
+	// Except 排除的路由不列出该中间件
+	assert.Equal(t, []string{auth}, middlewares["/health"])
+	// SkipMiddleware 跳过的中间件不列出
+	assert.Equal(t, []string{auth}, middlewares["/webhook/:id"])
+}
+
+func TestWithDebugRoutes(t *testing.T) {
+	s := NewHTTPServer(WithDebugRoutes("/_debug/routes"))
+	s.Get("/users", listUsers)
+
+	req := httptest.NewRequest(http.MethodGet, "/_debug/routes", nil)
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var routes []RouteInfo
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &routes))
+	assert.Len(t, routes, 2)
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, req)
+
+	assert.Equal(t, ContentTypeHTML, resp.Header().Get("Content-Type"))
+	assert.True(t, strings.Contains(resp.Body.String(), "<td>/users</td>"))
+}
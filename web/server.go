@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/idgen"
 	"github.com/fyerfyer/fyer-webframe/web/logger"
 	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
 )
@@ -26,14 +28,17 @@ type Server interface {
 	Delete(path string, handler HandlerFunc) RouteRegister
 	Patch(path string, handler HandlerFunc) RouteRegister
 	Options(path string, handler HandlerFunc) RouteRegister
+	Head(path string, handler HandlerFunc) RouteRegister
 
 	// 路由组和中间件
 	Group(prefix string) RouteGroup
 	Middleware() MiddlewareManager
+	// Mount 将模块挂载到 prefix 下
+	Mount(prefix string, m *Module)
 
 	// 模板引擎
-	UseTemplate(tpl Template) Server
-	GetTemplateEngine() Template
+	UseTemplate(tpl TemplateEngine) Server
+	GetTemplateEngine() TemplateEngine
 
 	// 日志记录器
 	Logger() logger.Logger
@@ -43,21 +48,69 @@ type Server interface {
 type RouteRegister interface {
 	// Middleware 为特定路由添加中间件
 	Middleware(middleware ...Middleware) RouteRegister
+	// Canary 为路由注册灰度版本，按权重或请求头/Cookie分流
+	Canary(variant string, handler HandlerFunc, opts ...CanaryOption) RouteRegister
+	// Tags 为路由添加标签，配合 Middleware().ForTag() 按标签应用中间件
+	Tags(tags ...string) RouteRegister
+	// SkipMiddleware 跳过通过 Middleware().Named() 命名的中间件
+	SkipMiddleware(names ...string) RouteRegister
+	// OnError 为路由设置错误处理器
+	OnError(handler ErrorHandler) RouteRegister
+	// Doc 设置路由在 OpenAPI 文档中的摘要、请求和响应类型
+	Doc(summary string, request, response any) RouteRegister
 }
 
 // HTTPServer 结构体
 type HTTPServer struct {
 	*Router     // 继承Router
 	start       bool
-	noRouter    HandlerFunc      // 404处理器
-	server      *http.Server     // 底层的http server
-	baseRoute   string           // 基础路由前缀
-	tplEngine   Template         // 模板引擎
-	poolManager pool.PoolManager // 连接池管理器
-	useObjPool  bool             // 是否使用对象池
-	paramCap    int              // 参数映射的初始容量
-	logger      logger.Logger    // 日志记录器
-}
+	noRouter    HandlerFunc              // 404处理器
+	server      *http.Server             // 底层的http server
+	baseRoute   string                   // 基础路由前缀
+	tplEngine   TemplateEngine           // 模板引擎
+	poolManager pool.PoolManager         // 连接池管理器
+	poolMaint   *poolMaintenance         // 连接池预热和健康检查配置
+	useObjPool  bool                     // 是否使用对象池
+	paramCap    int                      // 参数映射的初始容量
+	logger      logger.Logger            // 日志记录器
+	canaries    map[string]*canaryRouter // 按"方法 路径"存储的灰度路由
+	errHandler  ErrorHandler             // 错误处理器
+	routeTags   map[string][]string      // 按"方法 路径"存储的路由标签
+	routeSkips  map[string][]string      // 按"方法 路径"存储的路由跳过的中间件名称
+	routeDocs   map[string]*routeDoc     // 按"方法 路径"存储的路由文档
+
+	groupHandlers    []*groupHandlers        // 路由组的404和错误处理器，按前缀长度倒序
+	routeErrHandlers map[string]ErrorHandler // 按"方法 路径"存储的路由错误处理器
+
+	caseInsensitiveRouting bool // 是否重定向大小写不匹配的请求
+	methodOverride         bool // 是否允许覆盖请求方法
+
+	tcpKeepAlive time.Duration // TCP keep-alive 探测间隔
+
+	liveReload *LiveReload // 开发环境的浏览器自动刷新，关闭服务器时停止
+
+	openAPIInfo OpenAPIInfo // OpenAPI 文档的基本信息
+	openAPISkip []string    // 不出现在 OpenAPI 文档中的路由，即文档端点本身
+
+	startHooks    []LifecycleHook // 开始接收请求前依次调用
+	shutdownHooks []LifecycleHook // 关闭连接池之前按注册的相反顺序调用
+
+	clock clock.Clock       // 时间来源，nil 时使用系统时间
+	idGen idgen.IDGenerator // 请求ID生成器，nil 时使用请求路径加当前时间
+}
+
+// LifecycleHook 服务器启动或关闭时调用的函数，例如启动和停止后台任务
+type LifecycleHook func(ctx context.Context) error
+
+// 未设置时使用的连接参数默认值
+const (
+	// DefaultReadHeaderTimeout 读取请求头的默认超时时间，防止慢速请求头攻击
+	DefaultReadHeaderTimeout = 10 * time.Second
+	// DefaultIdleTimeout keep-alive 连接的默认空闲超时时间
+	DefaultIdleTimeout = 120 * time.Second
+	// DefaultMaxHeaderBytes 请求头的默认最大字节数
+	DefaultMaxHeaderBytes = 1 << 20
+)
 
 // ServerOption 定义服务器选项
 type ServerOption func(*HTTPServer)
@@ -76,8 +129,46 @@ func WithWriteTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithReadHeaderTimeout 设置读取请求头的超时时间，默认为 DefaultReadHeaderTimeout，传入负数表示不限制
+func WithReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(server *HTTPServer) {
+		server.server.ReadHeaderTimeout = timeout
+	}
+}
+
+// WithIdleTimeout 设置 keep-alive 连接等待下一个请求的空闲超时时间，
+// 默认为 DefaultIdleTimeout，传入负数表示不限制
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(server *HTTPServer) {
+		server.server.IdleTimeout = timeout
+	}
+}
+
+// WithMaxHeaderBytes 设置请求头的最大字节数，默认为 DefaultMaxHeaderBytes
+func WithMaxHeaderBytes(n int) ServerOption {
+	return func(server *HTTPServer) {
+		server.server.MaxHeaderBytes = n
+	}
+}
+
+// WithKeepAlive 设置是否启用HTTP keep-alive，默认启用
+// 关闭后每个请求处理完都会关闭连接，适合需要频繁重新均衡连接的负载均衡场景
+func WithKeepAlive(enabled bool) ServerOption {
+	return func(server *HTTPServer) {
+		server.server.SetKeepAlivesEnabled(enabled)
+	}
+}
+
+// WithTCPKeepAlive 设置TCP keep-alive 探测间隔，默认为15秒，传入负数表示关闭探测
+// 用于及时发现对端已经断开但没有正常关闭的连接
+func WithTCPKeepAlive(period time.Duration) ServerOption {
+	return func(server *HTTPServer) {
+		server.tcpKeepAlive = period
+	}
+}
+
 // WithTemplate 设置模板引擎
-func WithTemplate(tpl Template) ServerOption {
+func WithTemplate(tpl TemplateEngine) ServerOption {
 	return func(server *HTTPServer) {
 		server.tplEngine = tpl
 	}
@@ -98,9 +189,17 @@ func WithBasePath(basePath string) ServerOption {
 }
 
 // WithPoolManager 设置连接池管理器
-func WithPoolManager(manager pool.PoolManager) ServerOption {
+// 通过 opts 配置启动时的连接预热、定期健康检查以及健康检查端点
+func WithPoolManager(manager pool.PoolManager, opts ...PoolOption) ServerOption {
 	return func(server *HTTPServer) {
 		server.poolManager = manager
+		server.poolMaint = &poolMaintenance{checkTimeout: defaultPoolCheckTimeout}
+		for _, opt := range opts {
+			opt(server.poolMaint)
+		}
+		if server.poolMaint.healthPath != "" {
+			server.Get(server.poolMaint.healthPath, server.poolHealthHandler)
+		}
 	}
 }
 
@@ -121,6 +220,13 @@ func WithLogger(log logger.Logger) ServerOption {
 	}
 }
 
+// WithErrorHandler 设置错误处理器，ctx.Error 和 HandleError 返回的错误都由它转换为响应
+func WithErrorHandler(handler ErrorHandler) ServerOption {
+	return func(server *HTTPServer) {
+		server.errHandler = handler
+	}
+}
+
 // NewHTTPServer 创建HTTP服务器实例
 func NewHTTPServer(opts ...ServerOption) *HTTPServer {
 	server := &HTTPServer{
@@ -130,8 +236,14 @@ func NewHTTPServer(opts ...ServerOption) *HTTPServer {
 			ctx.Resp.WriteHeader(http.StatusNotFound)
 			ctx.Resp.Write([]byte("404 Not Found"))
 		},
-		paramCap: 8,                     // 默认参数容量
-		logger:   logger.GetDefaultLogger(), // 使用默认日志记录器
+		paramCap:   8,                         // 默认参数容量
+		logger:     logger.GetDefaultLogger(), // 使用默认日志记录器
+		canaries:   make(map[string]*canaryRouter),
+		routeTags:  make(map[string][]string),
+		routeSkips: make(map[string][]string),
+		routeDocs:  make(map[string]*routeDoc),
+
+		routeErrHandlers: make(map[string]ErrorHandler),
 	}
 
 	// 应用所有选项
@@ -141,9 +253,27 @@ func NewHTTPServer(opts ...ServerOption) *HTTPServer {
 
 	// 设置 http.Server 的处理器为当前实例
 	server.server.Handler = server
+	server.applyConnDefaults()
 	return server
 }
 
+// applyConnDefaults 为未设置的连接参数使用默认值
+func (s *HTTPServer) applyConnDefaults() {
+	if s.server.ReadHeaderTimeout == 0 {
+		// 读取请求头的时间不应超过读取整个请求的时间
+		s.server.ReadHeaderTimeout = DefaultReadHeaderTimeout
+		if s.server.ReadTimeout > 0 && s.server.ReadTimeout < DefaultReadHeaderTimeout {
+			s.server.ReadHeaderTimeout = s.server.ReadTimeout
+		}
+	}
+	if s.server.IdleTimeout == 0 {
+		s.server.IdleTimeout = DefaultIdleTimeout
+	}
+	if s.server.MaxHeaderBytes <= 0 {
+		s.server.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+}
+
 // Logger 返回服务器的日志记录器
 func (s *HTTPServer) Logger() logger.Logger {
 	return s.logger
@@ -164,41 +294,62 @@ func (s *HTTPServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	// 记录请求开始
 	reqID := req.Header.Get("X-Request-ID")
 	if reqID == "" {
-		reqID = req.URL.Path + "-" + time.Now().Format(time.RFC3339Nano)
+		reqID = s.requestID(req.URL.Path)
 	}
 
 	requestLog := s.logger.WithField("request_id", reqID).
 		WithField("method", req.Method).
 		WithField("path", req.URL.Path).
-		WithField("client_ip", req.RemoteAddr)
+		WithField("client_ip", clientIP(req))
 
 	requestLog.Info("Request started")
-	startTime := time.Now()
+	startTime := s.now()
 
 	var ctx *Context
 	// 使用对象池创建上下文
 	if s.useObjPool && objPool.DefaultContextPool != nil {
 		ctx = AcquireContext(req, res)
 		ctx.SetLogger(requestLog) // 设置请求级别日志记录器
+		ctx.errorHandler = s.errHandler
+		ctx.clock = s.clock
 	} else {
 		// 不使用对象池时，直接创建
 		ctx = &Context{
-			Req:         req,
-			Resp:        res,
-			Param:       make(map[string]string, s.paramCap),
-			tplEngine:   s.tplEngine,
-			Context:     req.Context(),
-			unhandled:   true,
-			UserValues:  make(map[string]any, s.paramCap),
-			poolManager: s.poolManager,
-			logger:      requestLog, // 设置请求级别日志记录器
+			Req:          req,
+			Resp:         res,
+			paramValues:  make([]string, 0, s.paramCap),
+			tplEngine:    s.tplEngine,
+			Context:      req.Context(),
+			unhandled:    true,
+			UserValues:   make(map[string]any, s.paramCap),
+			poolManager:  s.poolManager,
+			logger:       requestLog, // 设置请求级别日志记录器
+			errorHandler: s.errHandler,
+			clock:        s.clock,
 		}
 	}
 
+	// 记录处理函数直接写出的状态码和字节数
+	ctx.wrapResponse()
+	ctx.clientCtx = req.Context()
+
 	// 在函数返回时释放对象（如果使用了对象池）
 	if s.useObjPool && objPool.DefaultContextPool != nil {
 		defer ReleaseContext(ctx)
 	}
+	// 请求结束时取消派生的上下文，需要在归还对象池之前执行
+	defer ctx.cancelContexts()
+
+	// 请求方法覆盖需要在路由匹配前进行，并且要在派生 ctx.Req 之前，
+	// 否则解析出的表单和覆盖后的方法不会出现在处理函数看到的请求上
+	if s.methodOverride {
+		overrideMethod(req)
+	}
+
+	// 处理函数的上下文带上服务器的写超时，超时后的响应本来也无法写出
+	if s.server.WriteTimeout > 0 {
+		ctx.WithTimeout(s.server.WriteTimeout)
+	}
 
 	// 如果设置了基础路径，需要处理路径前缀
 	originalPath := req.URL.Path
@@ -222,30 +373,58 @@ func (s *HTTPServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	// 查找路由
-	node, ok := s.findHandler(req.Method, path, ctx)
+	// 路由组的错误处理器和模块的模板引擎按请求路径选择
+	if len(s.groupHandlers) > 0 {
+		ctx.errorHandler = s.errorHandlerFor(path)
+		ctx.tplEngine = s.templateFor(path)
+	}
+
+	// 带尾部斜杠的请求当前也能匹配到路由，需要在查找前重定向
+	if s.redirectTrailingSlash && len(path) > 1 && path[len(path)-1] == '/' && s.redirectFixedPath(ctx, req.Method, path) {
+		requestLog.Info("Redirect to fixed path", logger.String("path", path))
+		s.logRequestCompletion(requestLog, startTime, ctx.RespStatusCode)
+		return
+	}
+
+	// 查找路由，没有注册HEAD路由时使用GET路由响应HEAD请求
+	routeMethod := req.Method
+	entry, ok := s.lookupRoute(routeMethod, path, ctx)
+	if !ok && routeMethod == http.MethodHead {
+		if entry, ok = s.lookupRoute(http.MethodGet, path, ctx); ok {
+			routeMethod = http.MethodGet
+			ctx.Resp = &headResponseWriter{ResponseWriter: ctx.Resp}
+		}
+	}
 	if !ok {
+		if s.caseInsensitiveRouting && s.redirectFixedPath(ctx, req.Method, path) {
+			requestLog.Info("Redirect to fixed path", logger.String("path", path))
+			s.logRequestCompletion(requestLog, startTime, ctx.RespStatusCode)
+			return
+		}
 		requestLog.Info("Route not found", logger.String("method", req.Method), logger.String("path", path))
-		s.noRouter(ctx)
+		s.notFoundHandler(path)(ctx)
 		s.handleResponse(ctx)
 		s.logRequestCompletion(requestLog, startTime, http.StatusNotFound)
 		return
 	}
 
-	// 构建并执行处理链
-	handler := BuildChain(node.handler, path, s.Router.middlewares[req.Method])
-	handler(ctx)
+	if h, ok := s.routeErrHandlers[routeMethod+" "+ctx.RouteURL]; ok {
+		ctx.errorHandler = h
+	}
+
+	// 执行路由缓存的处理链
+	s.handlerFor(routeMethod, entry, path)(ctx)
 
 	// 处理响应
 	s.handleResponse(ctx)
 
-	// 记录请求完成
-	s.logRequestCompletion(requestLog, startTime, ctx.RespStatusCode)
+	// 记录请求完成，带上路由、当前用户和中间件添加的字段
+	s.logRequestCompletion(ctx.Logger(), startTime, ctx.ResponseStatus())
 }
 
 // logRequestCompletion 记录请求完成的日志
 func (s *HTTPServer) logRequestCompletion(requestLog logger.Logger, startTime time.Time, statusCode int) {
-	duration := time.Since(startTime)
+	duration := s.now().Sub(startTime)
 
 	// 根据状态码选择日志级别
 	if statusCode >= 500 {
@@ -265,8 +444,16 @@ func (s *HTTPServer) logRequestCompletion(requestLog logger.Logger, startTime ti
 
 // handleResponse 统一处理响应
 func (s *HTTPServer) handleResponse(ctx *Context) {
+	// 响应写出后归还池化的响应缓冲区，RespData不能继续引用已归还的内存
+	defer func() {
+		if ctx.respBuf != nil {
+			ctx.RespData = nil
+			ctx.releaseRespBuffer()
+		}
+	}()
+
 	// 如果已经直接操作了ResponseWriter，就不再进行处理
-	if !ctx.unhandled {
+	if !ctx.unhandled || ctx.writer.Written() {
 		return
 	}
 
@@ -275,6 +462,8 @@ func (s *HTTPServer) handleResponse(ctx *Context) {
 		ctx.RespStatusCode = http.StatusOK
 	}
 
+	setHeadContentLength(ctx)
+
 	// 设置状态码
 	ctx.Resp.WriteHeader(ctx.RespStatusCode)
 
@@ -300,12 +489,26 @@ func (s *HTTPServer) Start(addr string) error {
 
 	s.logger.Info("Starting HTTP server", logger.String("address", addr))
 
-	listen, err := net.Listen("tcp", addr)
+	lc := net.ListenConfig{KeepAlive: s.tcpKeepAlive}
+	listen, err := lc.Listen(context.Background(), "tcp", addr)
 	if err != nil {
 		s.logger.Error("Failed to create listener", logger.FieldError(err))
 		return err
 	}
 
+	// 开始接收请求前预热连接池并启动健康检查
+	s.startPoolMaintenance()
+	s.warmRouteChains()
+	s.logRouteConflicts()
+
+	for _, hook := range s.startHooks {
+		if err := hook(context.Background()); err != nil {
+			s.logger.Error("Start hook failed", logger.FieldError(err))
+			_ = listen.Close()
+			return err
+		}
+	}
+
 	s.start = true
 	s.server.Addr = addr
 	s.logger.Info("HTTP server listening", logger.String("address", addr))
@@ -315,9 +518,24 @@ func (s *HTTPServer) Start(addr string) error {
 // Shutdown 优雅关闭
 func (s *HTTPServer) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down HTTP server")
+	// 最后写入异步输出中缓冲的日志，刷新失败时已经无法记录日志
+	defer func() { _ = logger.Sync(s.logger) }()
 	s.start = false
 
+	// 断开自动刷新的连接，否则 WebSocket 连接会阻塞关闭
+	if s.liveReload != nil {
+		s.liveReload.Close()
+	}
+
+	// 后台任务等依赖连接池，需要在关闭连接池之前停止
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		if err := s.shutdownHooks[i](ctx); err != nil {
+			s.logger.Error("Shutdown hook failed", logger.FieldError(err))
+		}
+	}
+
 	// 关闭连接池管理器
+	s.stopPoolMaintenance()
 	if s.poolManager != nil {
 		s.logger.Info("Shutting down pool manager")
 		if err := s.poolManager.Shutdown(ctx); err != nil {
@@ -337,6 +555,17 @@ func (s *HTTPServer) Shutdown(ctx context.Context) error {
 	return err
 }
 
+// OnStart 注册服务器开始接收请求前调用的函数，任何一个返回错误时 Start 返回该错误
+func (s *HTTPServer) OnStart(hook LifecycleHook) {
+	s.startHooks = append(s.startHooks, hook)
+}
+
+// OnShutdown 注册 Shutdown 时调用的函数，按注册的相反顺序在关闭连接池之前调用，
+// ctx 为传给 Shutdown 的上下文，返回的错误只记录日志，不会中断关闭
+func (s *HTTPServer) OnShutdown(hook LifecycleHook) {
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
 // Get 注册GET路由
 func (s *HTTPServer) Get(path string, handler HandlerFunc) RouteRegister {
 	s.Router.Get(path, handler)
@@ -373,6 +602,12 @@ func (s *HTTPServer) Options(path string, handler HandlerFunc) RouteRegister {
 	return newRouteRegister(s, "OPTIONS", path)
 }
 
+// Head 注册HEAD路由
+func (s *HTTPServer) Head(path string, handler HandlerFunc) RouteRegister {
+	s.Router.Head(path, handler)
+	return newRouteRegister(s, "HEAD", path)
+}
+
 // Group 创建路由组
 func (s *HTTPServer) Group(prefix string) RouteGroup {
 	return newRouteGroup(s, prefix)
@@ -384,13 +619,13 @@ func (s *HTTPServer) Middleware() MiddlewareManager {
 }
 
 // UseTemplate 设置模板引擎
-func (s *HTTPServer) UseTemplate(tpl Template) Server {
+func (s *HTTPServer) UseTemplate(tpl TemplateEngine) Server {
 	s.tplEngine = tpl
 	return s
 }
 
 // GetTemplateEngine 返回服务器使用的模板引擎
-func (s *HTTPServer) GetTemplateEngine() Template {
+func (s *HTTPServer) GetTemplateEngine() TemplateEngine {
 	return s.tplEngine
 }
 
@@ -428,10 +663,76 @@ func newRouteRegister(server *HTTPServer, method, path string) *routeRegister {
 	}
 }
 
+// key 返回路由在 routeEntries 等表中的键，与注册路由时一样处理尾部斜杠
+func (r *routeRegister) key() string {
+	return r.method + " " + r.server.routePath(r.path)
+}
+
 // Middleware 为特定路由添加中间件
 func (r *routeRegister) Middleware(middleware ...Middleware) RouteRegister {
 	for _, m := range middleware {
 		r.server.Use(r.method, r.path, m)
 	}
 	return r
-}
\ No newline at end of file
+}
+
+// Tags 为路由添加标签
+func (r *routeRegister) Tags(tags ...string) RouteRegister {
+	key := r.key()
+	r.server.routeTags[key] = append(r.server.routeTags[key], tags...)
+	return r
+}
+
+// SkipMiddleware 跳过指定名称的中间件
+func (r *routeRegister) SkipMiddleware(names ...string) RouteRegister {
+	key := r.key()
+	r.server.routeSkips[key] = append(r.server.routeSkips[key], names...)
+	r.server.invalidateChains()
+	return r
+}
+
+// RouteTags 返回路由的标签，path为注册时的路由模式
+func (s *HTTPServer) RouteTags(method, path string) []string {
+	return s.routeTags[method+" "+s.routePath(path)]
+}
+
+// hasRouteTag 判断当前请求匹配的路由是否带有任一标签
+func (s *HTTPServer) hasRouteTag(ctx *Context, tags ...string) bool {
+	routeTags, ok := s.routeTags[ctx.Req.Method+" "+ctx.RouteURL]
+	if !ok && ctx.Req.Method == http.MethodHead {
+		routeTags = s.routeTags[http.MethodGet+" "+ctx.RouteURL]
+	}
+	for _, tag := range routeTags {
+		for _, t := range tags {
+			if tag == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Canary 为路由注册灰度版本
+// 同一路由注册多个灰度版本时共享流量分配，权重之和不应超过100
+func (r *routeRegister) Canary(variant string, handler HandlerFunc, opts ...CanaryOption) RouteRegister {
+	v := &canaryVariant{name: variant, handler: handler}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	key := r.key()
+	cr, ok := r.server.canaries[key]
+	if !ok {
+		entry, found := r.server.routeEntries[key]
+		if !found {
+			// 路由没有注册成功，例如关闭严格路由后被记录为冲突
+			return r
+		}
+		cr = newCanaryRouter()
+		r.server.canaries[key] = cr
+		entry.run = cr.handler(entry.handler)
+		r.server.invalidateChains()
+	}
+	cr.variants = append(cr.variants, v)
+	return r
+}
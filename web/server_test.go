@@ -3,6 +3,7 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/fyerfyer/fyer-kit/pool"
 	"io"
@@ -313,6 +314,163 @@ func TestMiddlewareManager(t *testing.T) {
 	})
 }
 
+func TestMiddlewareManager_ForTag(t *testing.T) {
+	s := NewHTTPServer()
+	var calls []string
+
+	s.Middleware().ForTag("public", "open").Add(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			calls = append(calls, "public")
+			next(ctx)
+		}
+	})
+
+	handler := func(ctx *Context) {
+		calls = append(calls, "handler")
+		ctx.String(http.StatusOK, "ok")
+	}
+	s.Get("/api/health", handler).Tags("public")
+	s.Get("/docs/:page", handler).Tags("internal", "open")
+	s.Post("/api/health", handler)
+	s.Get("/api/users", handler)
+
+	assert.Equal(t, []string{"internal", "open"}, s.RouteTags("GET", "/docs/:page"))
+
+	testCases := []struct {
+		method    string
+		path      string
+		wantCalls []string
+	}{
+		{method: http.MethodGet, path: "/api/health", wantCalls: []string{"public", "handler"}},
+		{method: http.MethodGet, path: "/docs/intro", wantCalls: []string{"public", "handler"}},
+		{method: http.MethodPost, path: "/api/health", wantCalls: []string{"handler"}},
+		{method: http.MethodGet, path: "/api/users", wantCalls: []string{"handler"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			calls = nil
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+func TestMiddlewareManager_ForTagTrailingSlash(t *testing.T) {
+	s := NewHTTPServer(WithRedirectTrailingSlash(true))
+	var calls []string
+	s.Middleware().ForTag("public").Add(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			calls = append(calls, "public")
+			next(ctx)
+		}
+	})
+	s.Get("/api/health/", func(ctx *Context) {
+		calls = append(calls, "handler")
+		ctx.String(http.StatusOK, "ok")
+	}).Tags("public")
+
+	// 路由按去掉尾部斜杠的路径注册，标签同样生效
+	assert.Equal(t, []string{"public"}, s.RouteTags("GET", "/api/health/"))
+	assert.Equal(t, []string{"public"}, s.RouteTags("GET", "/api/health"))
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"public", "handler"}, calls)
+}
+
+func TestMiddlewareManager_PriorityExceptNamed(t *testing.T) {
+	s := NewHTTPServer()
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx *Context) {
+				calls = append(calls, name)
+				next(ctx)
+			}
+		}
+	}
+
+	// 注册顺序与执行顺序相反，由优先级决定执行顺序
+	s.Middleware().Global().Add(record("logger"))
+	s.Middleware().For("GET", "/api/*").Priority(10).Add(record("api"))
+	s.Middleware().Global().Named("cors").Priority(100).Add(record("cors"))
+	s.Middleware().Global().Except("", "/health").Except(http.MethodPost, "/api/*").Add(record("auth"))
+	s.Middleware().When(func(c *Context) bool { return true }).Named("trace").Priority(-1).Add(record("trace"))
+
+	handler := func(ctx *Context) {
+		calls = append(calls, "handler")
+		ctx.String(http.StatusOK, "ok")
+	}
+	s.Get("/api/users", handler)
+	s.Post("/api/users", handler)
+	s.Get("/health", handler).SkipMiddleware("cors", "trace")
+	s.Get("/webhook/:id", handler).SkipMiddleware("cors")
+
+	testCases := []struct {
+		method    string
+		path      string
+		wantCalls []string
+	}{
+		{
+			method:    http.MethodGet,
+			path:      "/api/users",
+			wantCalls: []string{"cors", "api", "logger", "auth", "trace", "handler"},
+		},
+		{
+			method:    http.MethodPost,
+			path:      "/api/users",
+			wantCalls: []string{"cors", "logger", "trace", "handler"},
+		},
+		{
+			method:    http.MethodGet,
+			path:      "/health",
+			wantCalls: []string{"logger", "handler"},
+		},
+		{
+			method:    http.MethodGet,
+			path:      "/webhook/1",
+			wantCalls: []string{"logger", "auth", "trace", "handler"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			calls = nil
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+func TestRouteRegister_SkipMiddlewareTrailingSlash(t *testing.T) {
+	s := NewHTTPServer(WithRedirectTrailingSlash(true))
+	var calls []string
+	s.Middleware().Global().Named("auth").Add(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			calls = append(calls, "auth")
+			next(ctx)
+		}
+	})
+	s.Get("/health/", func(ctx *Context) {
+		calls = append(calls, "handler")
+		ctx.String(http.StatusOK, "ok")
+	}).SkipMiddleware("auth")
+
+	// 路由按去掉尾部斜杠的路径注册，跳过的中间件同样生效
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"handler"}, calls)
+}
+
 func TestServerOptions(t *testing.T) {
 	// 测试各种服务器选项
 	customHandler := func(ctx *Context) {
@@ -352,6 +510,47 @@ func TestServerOptions(t *testing.T) {
 	})
 }
 
+func TestServerConnOptions(t *testing.T) {
+	// 未设置时使用默认值
+	s := NewHTTPServer()
+	assert.Equal(t, DefaultReadHeaderTimeout, s.server.ReadHeaderTimeout)
+	assert.Equal(t, DefaultIdleTimeout, s.server.IdleTimeout)
+	assert.Equal(t, DefaultMaxHeaderBytes, s.server.MaxHeaderBytes)
+
+	// 读取请求头的默认超时不超过读取超时
+	s = NewHTTPServer(WithReadTimeout(3 * time.Second))
+	assert.Equal(t, 3*time.Second, s.server.ReadHeaderTimeout)
+
+	s = NewHTTPServer(
+		WithReadHeaderTimeout(2*time.Second),
+		WithIdleTimeout(-1),
+		WithMaxHeaderBytes(4096),
+		WithTCPKeepAlive(30*time.Second),
+	)
+	assert.Equal(t, 2*time.Second, s.server.ReadHeaderTimeout)
+	assert.Equal(t, time.Duration(-1), s.server.IdleTimeout)
+	assert.Equal(t, 4096, s.server.MaxHeaderBytes)
+	assert.Equal(t, 30*time.Second, s.tcpKeepAlive)
+}
+
+func TestServerKeepAlive(t *testing.T) {
+	s := NewHTTPServer(WithKeepAlive(false))
+	s.Get("/", func(ctx *Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	ts := httptest.NewUnstartedServer(s)
+	ts.Config = s.server
+	ts.Start()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	// 关闭keep-alive后响应会要求关闭连接
+	assert.True(t, resp.Close)
+}
+
 func TestCombinedFeatures(t *testing.T) {
 	s := NewHTTPServer()
 	logs := []string{}
@@ -627,6 +826,42 @@ func TestServerGracefulShutdown(t *testing.T) {
 	assert.NoError(t, clientErr, "Client request should complete successfully despite shutdown")
 }
 
+func TestServerLifecycleHooks(t *testing.T) {
+	s := NewHTTPServer()
+	var calls []string
+	hooked := make(chan struct{})
+	s.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start")
+		close(hooked)
+		return nil
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "shutdown 1")
+		return nil
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "shutdown 2")
+		return errors.New("ignored")
+	})
+
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start("127.0.0.1:0")
+	}()
+	<-hooked
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-started, http.ErrServerClosed)
+	// 关闭时按注册的相反顺序调用，返回的错误不会中断关闭
+	assert.Equal(t, []string{"start", "shutdown 2", "shutdown 1"}, calls)
+
+	// 启动钩子返回错误时 Start 直接返回该错误
+	failErr := errors.New("start failed")
+	s = NewHTTPServer()
+	s.OnStart(func(ctx context.Context) error { return failErr })
+	assert.ErrorIs(t, s.Start("127.0.0.1:0"), failErr)
+}
+
 func assertJSONResponse(t *testing.T, resp *httptest.ResponseRecorder, expected interface{}) {
 	t.Helper()
 	var actual interface{}
@@ -794,4 +1029,59 @@ func TestServerPoolManagement(t *testing.T) {
 //         manager:     manager,
 //         connections: make([]pool.Connection, 0),
 //     }
-// }
\ No newline at end of file
+// }
+func TestRouteGroup_NotFoundAndOnError(t *testing.T) {
+	s := NewHTTPServer(WithErrorHandler(func(ctx *Context, err error) {
+		ctx.String(http.StatusInternalServerError, "global: "+err.Error())
+	}))
+
+	api := s.Group("/api").
+		NotFound(func(ctx *Context) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}).
+		OnError(func(ctx *Context, err error) {
+			ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		})
+	api.Get("/fail", HandleError(func(ctx *Context) error {
+		return errors.New("api failed")
+	}))
+	api.Get("/custom", HandleError(func(ctx *Context) error {
+		return errors.New("custom failed")
+	})).OnError(func(ctx *Context, err error) {
+		ctx.String(http.StatusTeapot, "route: "+err.Error())
+	})
+
+	// 嵌套路由组只覆盖404处理器，错误处理器沿用外层路由组
+	api.Group("/v2").NotFound(func(ctx *Context) {
+		ctx.String(http.StatusNotFound, "v2 not found")
+	})
+
+	s.Get("/fail", HandleError(func(ctx *Context) error {
+		return errors.New("root failed")
+	}))
+
+	tests := []struct {
+		name string
+		path string
+		code int
+		body string
+	}{
+		{name: "group not found", path: "/api/missing", code: http.StatusNotFound, body: "{\"error\":\"not found\"}\n"},
+		{name: "group prefix not found", path: "/api", code: http.StatusNotFound, body: "{\"error\":\"not found\"}\n"},
+		{name: "nested group not found", path: "/api/v2/missing", code: http.StatusNotFound, body: "v2 not found"},
+		{name: "similar prefix uses global", path: "/apix", code: http.StatusNotFound, body: "404 Not Found"},
+		{name: "root not found", path: "/missing", code: http.StatusNotFound, body: "404 Not Found"},
+		{name: "group error", path: "/api/fail", code: http.StatusBadRequest, body: "{\"error\":\"api failed\"}\n"},
+		{name: "route error", path: "/api/custom", code: http.StatusTeapot, body: "route: custom failed"},
+		{name: "root error", path: "/fail", code: http.StatusInternalServerError, body: "global: root failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.code, resp.Code)
+			assert.Equal(t, tt.body, resp.Body.String())
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/fyerfyer/fyer-kit/pool"
+	"github.com/fyerfyer/fyer-webframe/clock"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -18,6 +19,7 @@ type RedisStorage struct {
 	sessions        sync.Map // 添加session缓存池
 	cleanupInterval time.Duration
 	stopCleanup     context.CancelFunc
+	clock           clock.Clock // 记录会话创建时间使用的时间来源
 }
 
 var defaultExpireTime = time.Duration(3600) * time.Second
@@ -38,6 +40,13 @@ func WithPrefix(prefix string) RedisStorageOption {
 	}
 }
 
+// WithClock 设置记录会话创建时间使用的时间来源，默认为系统时间
+func WithClock(c clock.Clock) RedisStorageOption {
+	return func(rs *RedisStorage) {
+		rs.clock = clock.OrSystem(c)
+	}
+}
+
 func WithCleanupInterval(interval time.Duration) RedisStorageOption {
 	return func(rs *RedisStorage) {
 		rs.cleanupInterval = interval
@@ -54,6 +63,7 @@ func NewRedisStorage(redisPool pool.Pool, opts ...RedisStorageOption) *RedisStor
 		prefix:          defaultPrefix,
 		cleanupInterval: defaultCleanupInterval,
 		stopCleanup:     cancel,
+		clock:           clock.System(),
 	}
 
 	for _, opt := range opts {
@@ -137,7 +147,7 @@ func (r *RedisStorage) Create(ctx context.Context, id string) (session.Session,
 	key := r.prefix + id
 
 	// 创建session hash并设置过期时间
-	err = client.HSet(ctx, key, "_created", time.Now().Unix()).Err()
+	err = client.HSet(ctx, key, "_created", r.clock.Now().Unix()).Err()
 	if err != nil {
 		return nil, r.redisPool.Put(conn, err)
 	}
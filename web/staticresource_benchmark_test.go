@@ -202,9 +202,8 @@ func setupBenchmarkRequest(sr *StaticResource, filePath string) (*StaticResource
 	ctx := &Context{
 		Req:  req,
 		Resp: w,
-		Param: map[string]string{
-			"file": filepath.Base(filePath),
-		},
+		paramNames:  []string{"file"},
+		paramValues: []string{filepath.Base(filePath)},
 	}
 
 	return sr, w, ctx
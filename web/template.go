@@ -12,8 +12,20 @@ import (
 	"time"
 )
 
-type Template interface {
+// TemplateEngine 模板引擎的基础接口，WithTemplate 接受任何实现了该接口的模板引擎
+// 引擎可以按需实现 FragmentRenderer、TemplateReloader 等可选接口以提供更多能力
+type TemplateEngine interface {
 	Render(ctx *Context, tplName string, data any) ([]byte, error)
+}
+
+// TemplateReloader 支持重新加载模板的模板引擎
+type TemplateReloader interface {
+	Reload() error
+}
+
+// Template 基于模板文件的模板引擎，支持从文件加载和重新加载
+type Template interface {
+	TemplateEngine
 	LoadFromGlob(pattern string) error
 	LoadFromFiles(files ...string) error
 	Reload() error
@@ -27,6 +39,19 @@ type GoTemplate struct {
 	funcMap     template.FuncMap   // 自定义模板函数
 	autoReload  bool               // 是否启用自动重载
 	lastChecked time.Time          // 最后检查时间
+
+	layoutPattern string                        // 布局和公共片段文件匹配模式
+	defaultLayout string                        // 默认布局名称
+	pages         map[string]*template.Template // 每个页面与布局组合而成的独立模板集合
+	translator    Translator                    // ViewData使用的翻译函数
+
+	viewsDir  string             // 视图目录
+	viewExt   string             // 视图文件扩展名
+	injectors []ViewDataInjector // 渲染前向ViewData注入请求级数据的函数
+
+	fsys       fs.FS    // 模板所在的文件系统
+	fsPatterns []string // 文件系统中的模板匹配模式
+	loadErr    error    // 最近一次加载模板的错误
 }
 
 type GoTemplateOption func(*GoTemplate)
@@ -52,14 +77,50 @@ func WithFuncMap(funcMap template.FuncMap) GoTemplateOption {
 	}
 }
 
-// WithAutoReload 设置是否启用自动重载
+// WithLayouts 设置布局和公共片段文件的匹配模式
+// 设置后每个页面会与布局文件组合成独立的模板集合，
+// 不同页面可以定义同名的块（如 {{define "content"}}、{{define "scripts"}}）而不会互相覆盖。
+// 与 WithFS 一起使用时 pattern 是文件系统中的路径
+func WithLayouts(pattern string) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.layoutPattern = pattern
+	}
+}
+
+// WithDefaultLayout 设置默认布局，ViewData.Layout 可以覆盖该设置
+func WithDefaultLayout(name string) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.defaultLayout = name
+	}
+}
+
+// WithTranslator 设置ViewData使用的翻译函数
+func WithTranslator(translator Translator) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.translator = translator
+	}
+}
+
+// WithViewDataInjector 添加渲染前向ViewData注入请求级数据的函数，按添加顺序执行
+func WithViewDataInjector(injectors ...ViewDataInjector) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.injectors = append(t.injectors, injectors...)
+	}
+}
+
+// WithAutoReload 设置是否启用自动重载，从 WithFS 加载的模板不会变化，因此不会重载
 func WithAutoReload(auto bool) GoTemplateOption {
 	return func(t *GoTemplate) {
 		t.autoReload = auto
-		if auto {
-			// 启动后台监控
-			go t.watchTemplates()
-		}
+	}
+}
+
+// WithFS 从文件系统加载模板，与 embed.FS 一起使用时二进制文件不再依赖工作目录下的模板文件
+// 未指定匹配模式时按 WithViewsDir 的规则加载整个文件系统，可以先用 fs.Sub 取出模板所在的子目录
+func WithFS(fsys fs.FS, patterns ...string) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.fsys = fsys
+		t.fsPatterns = patterns
 	}
 }
 
@@ -74,22 +135,33 @@ func NewGoTemplate(opts ...GoTemplateOption) *GoTemplate {
 		opt(t)
 	}
 
-	// 初始化模板函数
+	// 初始化模板函数，共享模板函数的优先级低于引擎自己的模板函数
+	t.funcMap = mergeSharedFuncs(t.funcMap)
 	t.tpl = t.tpl.Funcs(t.funcMap)
 
 	// 初始化时如果有模板，则尝试加载
 	var err error
-	if t.tplPattern != "" {
+	if t.fsys != nil {
+		err = t.LoadFromFS(t.fsys, t.fsPatterns...)
+	} else if t.tplPattern != "" {
 		err = t.LoadFromGlob(t.tplPattern)
 	} else if len(t.tplFiles) > 0 {
 		err = t.LoadFromFiles(t.tplFiles...)
+	} else if t.viewsDir != "" {
+		err = t.LoadFromDir(t.viewsDir)
 	}
 
-	// 如果加载失败，记录错误但不panic
+	// 如果加载失败，记录错误但不panic，Precompile 会返回该错误
 	if err != nil {
+		t.loadErr = err
 		fmt.Printf("Warning: Failed to load templates: %v\n", err)
 	}
 
+	// 文件系统中的模板不会变化，不需要监控
+	if t.autoReload && t.fsys == nil {
+		go t.watchTemplates()
+	}
+
 	return t
 }
 
@@ -115,10 +187,15 @@ func (g *GoTemplate) LoadFromGlob(pattern string) error {
 		return fmt.Errorf("failed to parse glob: %w", err)
 	}
 
+	if err := g.buildPages(nil, matches); err != nil {
+		return err
+	}
+
 	// 记录模板信息
 	g.tpl = temp
 	g.tplPattern = pattern
 	g.tplFiles = matches
+	g.loadErr = nil
 	return nil
 }
 
@@ -145,14 +222,22 @@ func (g *GoTemplate) LoadFromFiles(files ...string) error {
 		return fmt.Errorf("failed to parse files: %w", err)
 	}
 
+	if err := g.buildPages(nil, files); err != nil {
+		return err
+	}
+
 	// 记录模板信息
 	g.tpl = temp
 	g.tplFiles = files
+	g.loadErr = nil
 	return nil
 }
 
 // Reload 重新加载模板
 func (g *GoTemplate) Reload() error {
+	if g.fsys != nil {
+		return g.LoadFromFS(g.fsys, g.fsPatterns...)
+	}
 	if g.tplPattern != "" {
 		err := g.LoadFromGlob(g.tplPattern)
 		if err == nil {
@@ -169,6 +254,14 @@ func (g *GoTemplate) Reload() error {
 		}
 		return err
 	}
+	if g.viewsDir != "" {
+		err := g.LoadFromDir(g.viewsDir)
+		if err == nil {
+			g.lastChecked = time.Now()
+			fmt.Println("Templates reloaded from views dir:", g.viewsDir)
+		}
+		return err
+	}
 	return errors.New("no template source defined")
 }
 
@@ -188,6 +281,116 @@ func (g *GoTemplate) Reload() error {
 //	return names
 //}
 
+// buildPages 将每个页面文件与布局文件组合成独立的模板集合
+// fsys 不为nil时页面和布局都从 fsys 中读取，布局匹配模式是 fsys 中的路径
+func (g *GoTemplate) buildPages(fsys fs.FS, files []string) error {
+	if g.layoutPattern == "" {
+		g.pages = nil
+		return nil
+	}
+
+	glob := filepath.Glob
+	parse := func(t *template.Template, files ...string) (*template.Template, error) {
+		return t.ParseFiles(files...)
+	}
+	if fsys != nil {
+		glob = func(pattern string) ([]string, error) { return fs.Glob(fsys, pattern) }
+		parse = func(t *template.Template, files ...string) (*template.Template, error) {
+			return t.ParseFS(fsys, files...)
+		}
+	}
+
+	layoutFiles, err := glob(g.layoutPattern)
+	if err != nil {
+		return fmt.Errorf("failed to glob layouts %s: %w", g.layoutPattern, err)
+	}
+	if len(layoutFiles) == 0 {
+		return fmt.Errorf("no layout files match pattern %s", g.layoutPattern)
+	}
+
+	layouts, err := parse(template.New("").Funcs(g.funcMap), layoutFiles...)
+	if err != nil {
+		return fmt.Errorf("failed to parse layouts: %w", err)
+	}
+
+	isLayout := make(map[string]bool, len(layoutFiles))
+	for _, file := range layoutFiles {
+		isLayout[filepath.Clean(file)] = true
+	}
+
+	pages := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		if isLayout[filepath.Clean(file)] {
+			continue
+		}
+		page, err := layouts.Clone()
+		if err != nil {
+			return fmt.Errorf("failed to clone layouts: %w", err)
+		}
+		if _, err = parse(page, file); err != nil {
+			return fmt.Errorf("failed to parse page %s: %w", file, err)
+		}
+		pages[filepath.Base(file)] = page
+	}
+
+	g.pages = pages
+	return nil
+}
+
+// lookupSet 返回模板所在的模板集合，布局模式下每个页面有独立的集合
+func (g *GoTemplate) lookupSet(tplName string) *template.Template {
+	if page, ok := g.pages[tplName]; ok {
+		return page
+	}
+	return g.tpl
+}
+
+// prepareViewData 为ViewData设置翻译函数并注入请求级数据
+func (g *GoTemplate) prepareViewData(ctx *Context, data any) {
+	vd, ok := data.(*ViewData)
+	if !ok {
+		return
+	}
+	if vd.translator == nil {
+		vd.translator = g.translator
+	}
+	if ctx == nil {
+		return
+	}
+	for _, inject := range g.injectors {
+		inject(ctx, vd)
+	}
+}
+
+// renderPage 使用布局渲染页面
+func (g *GoTemplate) renderPage(page *template.Template, tplName string, data any) ([]byte, error) {
+	layout := g.defaultLayout
+	switch vd := data.(type) {
+	case *ViewData:
+		if vd.Layout != "" {
+			layout = vd.Layout
+		}
+	case ViewData:
+		if vd.Layout != "" {
+			layout = vd.Layout
+		}
+	}
+
+	// 没有布局时直接渲染页面本身
+	if layout == "" {
+		layout = tplName
+	}
+	if page.Lookup(layout) == nil {
+		return nil, fmt.Errorf("layout %s not found", layout)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := page.ExecuteTemplate(buf, layout, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // Render 渲染模板
 func (g *GoTemplate) Render(ctx *Context, tplName string, data any) ([]byte, error) {
 	g.RLock()
@@ -205,9 +408,9 @@ func (g *GoTemplate) Render(ctx *Context, tplName string, data any) ([]byte, err
 	//templateNames := g.DebugTemplateNames()
 	//fmt.Printf("DEBUG Render: Available templates: %v\n", templateNames)
 
-	// 检查模板是否存在
-	tmpl := g.tpl.Lookup(tplName)
-	if tmpl == nil {
+	// 检查模板是否存在，视图目录中的页面只存在于各自的模板集合中
+	_, isPage := g.pages[tplName]
+	if !isPage && g.tpl.Lookup(tplName) == nil {
 		//fmt.Printf("DEBUG Render: Template '%s' not found\n", tplName)
 		return nil, fmt.Errorf("template %s not found", tplName)
 	}
@@ -219,6 +422,13 @@ func (g *GoTemplate) Render(ctx *Context, tplName string, data any) ([]byte, err
 		return nil, errors.New("template data cannot be nil")
 	}
 
+	g.prepareViewData(ctx, data)
+
+	// 布局模式下页面使用独立的模板集合渲染
+	if page, ok := g.pages[tplName]; ok {
+		return g.renderPage(page, tplName, data)
+	}
+
 	//fmt.Printf("DEBUG Render: Executing template '%s'\n", tplName)
 
 	// 使用ExecuteTemplate确保正确处理嵌套模板
@@ -235,24 +445,66 @@ func (g *GoTemplate) Render(ctx *Context, tplName string, data any) ([]byte, err
 	return result, nil
 }
 
-// LoadFromFS 从文件系统加载模板
+// LoadFromFS 从文件系统加载模板，未指定匹配模式时按视图目录的规则加载整个文件系统
 func (g *GoTemplate) LoadFromFS(fsys fs.FS, patterns ...string) error {
 	g.Lock()
 	defer g.Unlock()
 
 	if len(patterns) == 0 {
-		return errors.New("no patterns provided")
-	}
+		if err := g.loadViews(fsys); err != nil {
+			return err
+		}
+	} else {
+		// 创建新模板
+		temp := template.New("").Funcs(g.funcMap)
+		temp, err := temp.ParseFS(fsys, patterns...)
+		if err != nil {
+			return fmt.Errorf("failed to parse fs: %w", err)
+		}
 
-	// 创建新模板
-	temp := template.New("").Funcs(g.funcMap)
-	temp, err := temp.ParseFS(fsys, patterns...)
-	if err != nil {
-		return fmt.Errorf("failed to parse fs: %w", err)
+		// 设置了 WithLayouts 时与 LoadFromGlob 一样为每个页面组合布局
+		var files []string
+		for _, pattern := range patterns {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
+			}
+			files = append(files, matches...)
+		}
+		if err := g.buildPages(fsys, files); err != nil {
+			return err
+		}
+		g.tpl = temp
 	}
 
 	// 记录模板信息
-	g.tpl = temp
+	g.fsys = fsys
+	g.fsPatterns = patterns
+	g.loadErr = nil
+	return nil
+}
+
+// Precompile 检查模板是否全部加载成功，以及每个页面能否找到默认布局
+// 模板在创建引擎时已经解析完成，生产环境建议在启动时调用该方法，
+// 使模板错误在启动时暴露，而不是在第一次请求时才失败
+func (g *GoTemplate) Precompile() error {
+	g.RLock()
+	defer g.RUnlock()
+
+	if g.loadErr != nil {
+		return g.loadErr
+	}
+	if len(g.pages) == 0 && (g.tpl == nil || len(g.tpl.Templates()) == 0) {
+		return errors.New("no templates loaded")
+	}
+	if g.defaultLayout == "" {
+		return nil
+	}
+	for name, page := range g.pages {
+		if page.Lookup(g.defaultLayout) == nil {
+			return fmt.Errorf("layout %s not found for page %s", g.defaultLayout, name)
+		}
+	}
 	return nil
 }
 
@@ -277,11 +529,22 @@ func (g *GoTemplate) checkNeedsReload() bool {
 	g.RLock()
 	defer g.RUnlock()
 
+	// 文件系统中的模板不会变化
+	if g.fsys != nil {
+		return false
+	}
+
 	// 如果没有模板文件模式，无法检查
-	if g.tplPattern == "" && len(g.tplFiles) == 0 {
+	if g.tplPattern == "" && len(g.tplFiles) == 0 && g.viewsDir == "" {
 		return false
 	}
 
+	// 检查视图目录
+	if g.viewsDir != "" && viewsModifiedSince(g.viewsDir, g.lastChecked) {
+		g.lastChecked = time.Now()
+		return true
+	}
+
 	// 检查基于模式匹配的模板
 	if g.tplPattern != "" {
 		matches, err := filepath.Glob(g.tplPattern)
@@ -301,6 +564,21 @@ func (g *GoTemplate) checkNeedsReload() bool {
 		}
 	}
 
+	// 检查布局文件
+	if g.layoutPattern != "" {
+		matches, _ := filepath.Glob(g.layoutPattern)
+		for _, file := range matches {
+			info, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+			if info.ModTime().After(g.lastChecked) {
+				g.lastChecked = time.Now()
+				return true
+			}
+		}
+	}
+
 	// 检查基于文件列表的模板
 	for _, file := range g.tplFiles {
 		info, err := os.Stat(file)
@@ -0,0 +1,200 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	objPool "github.com/fyerfyer/fyer-webframe/web/pool"
+)
+
+// TemplateEngineFunc 将函数适配为模板引擎
+type TemplateEngineFunc func(ctx *Context, tplName string, data any) ([]byte, error)
+
+func (f TemplateEngineFunc) Render(ctx *Context, tplName string, data any) ([]byte, error) {
+	return f(ctx, tplName, data)
+}
+
+// ExecuteFunc 执行已编译的模板，vars为模板变量
+type ExecuteFunc func(w io.Writer, vars map[string]any) error
+
+// LoaderEngine 适配按名称加载模板、以键值对作为模板变量的第三方模板引擎，如 pongo2、Jet
+// 编译后的模板会被缓存，Reload 会清空缓存
+type LoaderEngine struct {
+	mu      sync.RWMutex
+	load    func(name string) (ExecuteFunc, error)
+	cache   map[string]ExecuteFunc
+	noCache bool
+}
+
+// LoaderEngineOption LoaderEngine的配置选项
+type LoaderEngineOption func(*LoaderEngine)
+
+// WithLoaderNoCache 每次渲染都重新加载模板，用于开发环境
+func WithLoaderNoCache() LoaderEngineOption {
+	return func(e *LoaderEngine) {
+		e.noCache = true
+	}
+}
+
+// NewLoaderEngine 创建第三方模板引擎的适配器，load根据模板名称加载并编译模板
+func NewLoaderEngine(load func(name string) (ExecuteFunc, error), opts ...LoaderEngineOption) *LoaderEngine {
+	e := &LoaderEngine{
+		load:  load,
+		cache: make(map[string]ExecuteFunc),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Render 渲染模板，*ViewData 会展开为同名的模板变量，其他数据通过 Data 变量访问
+func (e *LoaderEngine) Render(ctx *Context, tplName string, data any) ([]byte, error) {
+	execute, err := e.lookup(tplName)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars map[string]any
+	switch v := data.(type) {
+	case *ViewData:
+		vars = v.Map()
+	case map[string]any:
+		vars = v
+	default:
+		vars = map[string]any{"Data": data}
+	}
+
+	buf := &bytes.Buffer{}
+	if err = execute(buf, vars); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", tplName, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Reload 清空已编译模板的缓存
+func (e *LoaderEngine) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache = make(map[string]ExecuteFunc)
+	return nil
+}
+
+// lookup 从缓存中获取模板，不存在时加载
+func (e *LoaderEngine) lookup(name string) (ExecuteFunc, error) {
+	if !e.noCache {
+		e.mu.RLock()
+		execute, ok := e.cache[name]
+		e.mu.RUnlock()
+		if ok {
+			return execute, nil
+		}
+	}
+
+	execute, err := e.load(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", name, err)
+	}
+
+	if !e.noCache {
+		e.mu.Lock()
+		e.cache[name] = execute
+		e.mu.Unlock()
+	}
+	return execute, nil
+}
+
+// Component 代码生成的模板组件，与 templ.Component 的方法集一致，
+// templ 生成的组件可以直接使用
+type Component interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+// Component 渲染模板组件
+func (c *Context) Component(code int, component Component) error {
+	// 获取一个响应缓冲区，响应写出后再归还
+	buf := objPool.AcquireBuffer()
+	if err := component.Render(c.stdContext(), buf.Buffer); err != nil {
+		objPool.ReleaseBuffer(buf)
+		return fmt.Errorf("failed to render component: %w", err)
+	}
+
+	c.Resp.Header().Set("Content-Type", ContentTypeHTML)
+	c.RespStatusCode = code
+	c.setRespBuffer(buf)
+	c.unhandled = true
+	return nil
+}
+
+// stdContext 返回渲染组件使用的标准上下文
+func (c *Context) stdContext() context.Context {
+	if c.Context != nil {
+		return c.Context
+	}
+	if c.Req != nil {
+		return c.Req.Context()
+	}
+	return context.Background()
+}
+
+// ComponentEngine 按名称注册模板组件的模板引擎，
+// 使代码生成的组件也可以通过 ctx.Template、ctx.View 和内容协商渲染
+type ComponentEngine struct {
+	mu         sync.RWMutex
+	components map[string]func(data any) (Component, error)
+}
+
+// NewComponentEngine 创建模板组件引擎
+func NewComponentEngine() *ComponentEngine {
+	return &ComponentEngine{
+		components: make(map[string]func(data any) (Component, error)),
+	}
+}
+
+// RegisterComponent 注册模板组件，渲染时将数据转换为组件参数的类型
+// 通过 ctx.View 渲染时使用 ViewData.Data 作为组件参数
+func RegisterComponent[T any](e *ComponentEngine, name string, fn func(data T) Component) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.components[name] = func(data any) (Component, error) {
+		if vd, ok := data.(*ViewData); ok {
+			if _, isViewData := any(vd).(T); !isViewData {
+				data = vd.Data
+			}
+		}
+		if data == nil {
+			var zero T
+			return fn(zero), nil
+		}
+		arg, ok := data.(T)
+		if !ok {
+			var zero T
+			return nil, fmt.Errorf("component %s expects %T, got %T", name, zero, data)
+		}
+		return fn(arg), nil
+	}
+}
+
+// Render 渲染已注册的模板组件
+func (e *ComponentEngine) Render(ctx *Context, tplName string, data any) ([]byte, error) {
+	e.mu.RLock()
+	build, ok := e.components[tplName]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("component %s not found", tplName)
+	}
+
+	component, err := build(data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err = component.Render(ctx.stdContext(), buf); err != nil {
+		return nil, fmt.Errorf("failed to render component %s: %w", tplName, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,149 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helloComponent 模拟 templ 生成的组件
+type helloComponent struct {
+	name string
+}
+
+func (h helloComponent) Render(_ context.Context, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "<p>hello %s</p>", h.name)
+	return err
+}
+
+func TestLoaderEngine(t *testing.T) {
+	sources := map[string]string{
+		"page": `{{.Title}}|{{.Data}}|{{.CSRFToken}}`,
+		"raw":  `{{.Data}}`,
+	}
+	loads := 0
+	engine := NewLoaderEngine(func(name string) (ExecuteFunc, error) {
+		loads++
+		src, ok := sources[name]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		tpl, err := template.New(name).Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		return func(w io.Writer, vars map[string]any) error {
+			return tpl.Execute(w, vars)
+		}, nil
+	})
+
+	result, err := engine.Render(&Context{}, "page", &ViewData{Title: "t", Data: "d", CSRFToken: "c"})
+	require.NoError(t, err)
+	assert.Equal(t, "t|d|c", string(result))
+
+	result, err = engine.Render(&Context{}, "raw", 42)
+	require.NoError(t, err)
+	assert.Equal(t, "42", string(result))
+
+	// 已编译的模板会被缓存
+	_, err = engine.Render(&Context{}, "page", &ViewData{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads)
+
+	require.NoError(t, engine.Reload())
+	_, err = engine.Render(&Context{}, "page", &ViewData{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, loads)
+
+	_, err = engine.Render(&Context{}, "missing", nil)
+	assert.Error(t, err)
+}
+
+func TestComponentEngine(t *testing.T) {
+	engine := NewComponentEngine()
+	RegisterComponent(engine, "hello", func(name string) Component {
+		return helloComponent{name: name}
+	})
+
+	s := NewHTTPServer(WithTemplate(engine))
+	s.Get("/view", func(ctx *Context) {
+		ctx.View("hello", "tom")
+	})
+	s.Get("/component", func(ctx *Context) {
+		ctx.Component(http.StatusCreated, helloComponent{name: "jerry"})
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/view", nil))
+	assert.Equal(t, "<p>hello tom</p>", resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/component", nil))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "<p>hello jerry</p>", resp.Body.String())
+
+	_, err := engine.Render(&Context{}, "hello", 1)
+	assert.ErrorContains(t, err, "expects string, got int")
+
+	_, err = engine.Render(&Context{}, "missing", nil)
+	assert.Error(t, err)
+}
+
+// failingComponent 渲染时写入部分内容后失败
+type failingComponent struct{}
+
+func (failingComponent) Render(_ context.Context, w io.Writer) error {
+	_, _ = io.WriteString(w, "<p>partial")
+	return errors.New("boom")
+}
+
+func TestContext_Component(t *testing.T) {
+	ctx := &Context{Req: httptest.NewRequest(http.MethodGet, "/", nil), Resp: httptest.NewRecorder()}
+	require.NoError(t, ctx.String(http.StatusOK, "old"))
+	old := ctx.respBuf
+	ctx.unhandled = false
+
+	// 与其他响应方法一样标记需要写出响应，并替换之前持有的缓冲区
+	require.NoError(t, ctx.Component(http.StatusCreated, helloComponent{name: "tom"}))
+	assert.True(t, ctx.unhandled)
+	assert.NotNil(t, ctx.respBuf)
+	assert.NotSame(t, old, ctx.respBuf)
+	assert.Equal(t, http.StatusCreated, ctx.RespStatusCode)
+	assert.Equal(t, "<p>hello tom</p>", string(ctx.RespData))
+
+	// 渲染失败时保持之前的响应
+	assert.ErrorContains(t, ctx.Component(http.StatusOK, failingComponent{}), "boom")
+	assert.Equal(t, http.StatusCreated, ctx.RespStatusCode)
+	assert.Equal(t, "<p>hello tom</p>", string(ctx.RespData))
+
+	s := NewHTTPServer()
+	s.Get("/", func(ctx *Context) {
+		ctx.String(http.StatusOK, "old")
+		ctx.Component(http.StatusOK, helloComponent{name: "jerry"})
+	})
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "<p>hello jerry</p>", resp.Body.String())
+	assert.Equal(t, ContentTypeHTML, resp.Header().Get("Content-Type"))
+}
+
+func TestTemplateEngineFunc(t *testing.T) {
+	s := NewHTTPServer(WithTemplate(TemplateEngineFunc(func(ctx *Context, name string, data any) ([]byte, error) {
+		return []byte(name + ":" + fmt.Sprint(data)), nil
+	})))
+	s.Get("/", func(ctx *Context) {
+		ctx.Template("index", "data")
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "index:data", resp.Body.String())
+}
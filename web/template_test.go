@@ -3,11 +3,13 @@ package web
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 )
 
 func TestGoTemplate(t *testing.T) {
@@ -175,4 +177,65 @@ func TestTemplateWithHTTPServer(t *testing.T) {
 	assert.Contains(t, html, "<p>欢迎访问</p>")
 	assert.Contains(t, html, "<header>测试项目</header>")
 	assert.Contains(t, html, "<footer>2025</footer>")
-}
\ No newline at end of file
+}
+func TestGoTemplate_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layouts/base.html": {Data: []byte(`<main>{{block "content" .}}{{end}}</main>`)},
+		"views/users/show.html":   {Data: []byte(`{{define "content"}}{{.Data}}{{end}}`)},
+		"mail/welcome.html":       {Data: []byte(`{{define "welcome.html"}}hi {{.}}{{end}}`)},
+	}
+
+	t.Run("views", func(t *testing.T) {
+		views, err := fs.Sub(fsys, "views")
+		require.NoError(t, err)
+		tpl := NewGoTemplate(WithFS(views), WithDefaultLayout("layouts/base"), WithAutoReload(true))
+		require.NoError(t, tpl.Precompile())
+		// 文件系统中的模板不会触发重载
+		assert.False(t, tpl.checkNeedsReload())
+
+		result, err := tpl.Render(&Context{}, "users/show", &ViewData{Data: "tom"})
+		require.NoError(t, err)
+		assert.Equal(t, "<main>tom</main>", string(result))
+		require.NoError(t, tpl.Reload())
+	})
+
+	t.Run("patterns", func(t *testing.T) {
+		tpl := NewGoTemplate(WithFS(fsys, "mail/*.html"))
+		require.NoError(t, tpl.Precompile())
+
+		result, err := tpl.Render(&Context{}, "welcome.html", "tom")
+		require.NoError(t, err)
+		assert.Equal(t, "hi tom", string(result))
+	})
+
+	t.Run("patterns with layouts", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"layouts/main.html": {Data: []byte(`<main>{{block "content" .}}{{end}}</main>`)},
+			"pages/a.html":      {Data: []byte(`{{define "content"}}A {{.}}{{end}}`)},
+			"pages/b.html":      {Data: []byte(`{{define "content"}}B {{.}}{{end}}`)},
+		}
+		tpl := NewGoTemplate(WithFS(fsys, "pages/*.html"), WithLayouts("layouts/*.html"), WithDefaultLayout("main.html"))
+		require.NoError(t, tpl.Precompile())
+
+		// 每个页面有独立的模板集合，同名的块不会互相覆盖
+		result, err := tpl.Render(&Context{}, "a.html", "tom")
+		require.NoError(t, err)
+		assert.Equal(t, "<main>A tom</main>", string(result))
+		result, err = tpl.Render(&Context{}, "b.html", "tom")
+		require.NoError(t, err)
+		assert.Equal(t, "<main>B tom</main>", string(result))
+
+		tpl = NewGoTemplate(WithFS(fsys, "pages/*.html"), WithLayouts("missing/*.html"))
+		assert.ErrorContains(t, tpl.Precompile(), "no layout files match pattern missing/*.html")
+	})
+
+	t.Run("precompile errors", func(t *testing.T) {
+		tpl := NewGoTemplate(WithFS(fsys, "missing/*.html"))
+		assert.Error(t, tpl.Precompile())
+
+		views, err := fs.Sub(fsys, "views")
+		require.NoError(t, err)
+		tpl = NewGoTemplate(WithFS(views), WithDefaultLayout("layouts/missing"))
+		assert.ErrorContains(t, tpl.Precompile(), "layout layouts/missing not found")
+	})
+}
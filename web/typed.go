@@ -0,0 +1,294 @@
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator 请求类型实现 Validate 时，H 在绑定请求之后调用它，返回的错误作为400响应返回给客户端，
+// 返回 *HTTPError 时按其状态码返回
+type Validator interface {
+	Validate() error
+}
+
+// StatusCoder 响应类型实现 StatusCode 时，H 使用它作为响应的状态码，例如创建资源时返回201
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Empty 没有请求参数或响应体时使用的类型，作为响应类型时返回204且没有响应体
+type Empty struct{}
+
+// StatusCode 实现 StatusCoder 接口
+func (Empty) StatusCode() int {
+	return http.StatusNoContent
+}
+
+// TypedHandlerFunc 接收绑定好的请求、返回响应的处理函数
+type TypedHandlerFunc[TReq, TResp any] func(ctx *Context, req TReq) (TResp, error)
+
+// H 将类型化的处理函数转换为 HandlerFunc：
+//
+//	server.Post("/orgs/:org/users", web.H(func(ctx *web.Context, req CreateUserReq) (User, error) {
+//		return users.Create(ctx.Context, req)
+//	}))
+//
+// 请求按以下顺序绑定到 TReq，后绑定的值覆盖先绑定的值：
+//   - POST、PUT、PATCH 等方法的请求体，Content-Type 为 XML 时按 XML 解析，其余按 JSON 解析
+//   - 带有 query 标签的字段绑定查询参数，切片字段可以接收重复的参数
+//   - 带有 header 标签的字段绑定请求头
+//   - 带有 path 标签的字段绑定路径参数
+//
+// 绑定失败时返回400，TReq 实现 Validator 时绑定后进行校验。处理函数返回的错误交给 ctx.Error 处理，
+// 响应根据 Accept 头部协商格式，默认为JSON，状态码为200，TResp 实现 StatusCoder 时使用其返回值
+func H[TReq, TResp any](fn TypedHandlerFunc[TReq, TResp]) HandlerFunc {
+	return func(ctx *Context) {
+		var req TReq
+		if err := bindRequest(ctx, &req); err != nil {
+			ctx.Error(err)
+			return
+		}
+		if err := validateRequest(&req); err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		writeTypedResponse(ctx, resp)
+	}
+}
+
+// PathAs 获取按路由约束转换后的路径参数，T 需要与约束的类型一致：
+//
+//	server.Get("/users/{id:int}", func(ctx *web.Context) {
+//		id, err := web.PathAs[int64](ctx, "id")
+//	})
+func PathAs[T any](ctx *Context, key string) (T, error) {
+	var zero T
+	val, err := ctx.PathValue(key)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := val.(T)
+	if !ok {
+		return zero, fmt.Errorf("path parameter %s is %T, not %T", key, val, zero)
+	}
+	return v, nil
+}
+
+// validateRequest 调用请求的 Validate 方法，值接收者和指针接收者都可以
+func validateRequest(req any) error {
+	v, ok := req.(Validator)
+	if !ok {
+		v, ok = reflect.ValueOf(req).Elem().Interface().(Validator)
+	}
+	if !ok {
+		return nil
+	}
+	err := v.Validate()
+	if err == nil {
+		return nil
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return err
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error()).WithInternal(err)
+}
+
+// writeTypedResponse 按状态码和 Accept 头部写出响应
+func writeTypedResponse(ctx *Context, resp any) {
+	code := http.StatusOK
+	if sc, ok := resp.(StatusCoder); ok {
+		code = sc.StatusCode()
+	}
+	if _, ok := resp.(Empty); ok || code == http.StatusNoContent {
+		ctx.Status(code)
+		ctx.RespData = nil
+		return
+	}
+	if err := ctx.Negotiate(code, resp); err != nil {
+		ctx.JSON(code, resp)
+	}
+}
+
+// bindRequest 将请求体、查询参数、请求头和路径参数绑定到 ptr 指向的值
+func bindRequest(ctx *Context, ptr any) error {
+	if _, ok := ptr.(*Empty); ok {
+		return nil
+	}
+
+	if hasRequestBody(ctx.Req.Method) && ctx.Req.Body != nil && ctx.Req.Body != http.NoBody {
+		var err error
+		if isXMLRequest(ctx) {
+			err = xml.NewDecoder(ctx.Req.Body).Decode(ptr)
+		} else {
+			err = json.NewDecoder(ctx.Req.Body).Decode(ptr)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return NewHTTPError(http.StatusBadRequest, "invalid request body").WithInternal(err)
+		}
+	}
+
+	v := reflect.ValueOf(ptr).Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	query := ctx.Req.URL.Query()
+	return bindFields(v, func(f reflect.StructField) ([]string, string, bool) {
+		if name := tagName(f, "query"); name != "" {
+			vals, ok := query[name]
+			return vals, "query parameter " + name, ok
+		}
+		if name := tagName(f, "header"); name != "" {
+			vals, ok := ctx.Req.Header[http.CanonicalHeaderKey(name)]
+			return vals, "header " + name, ok
+		}
+		if name := tagName(f, "path"); name != "" {
+			val, ok := ctx.pathParam(name)
+			return []string{val}, "path parameter " + name, ok
+		}
+		return nil, "", false
+	})
+}
+
+// isXMLRequest 请求体是否为 XML，Content-Type 可以带有 charset 等参数
+func isXMLRequest(ctx *Context) bool {
+	mediaType, _, err := mime.ParseMediaType(ctx.ContentType())
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// bindFields 为结构体中有值的字段赋值，匿名结构体字段递归处理
+func bindFields(v reflect.Value, lookup func(f reflect.StructField) ([]string, string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		// 未导出的匿名结构体中导出的字段仍然可以赋值，与 encoding/json 一致
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			if err := bindFields(fv, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		vals, source, ok := lookup(f)
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setField(fv, vals); err != nil {
+			return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid %s", source)).WithInternal(err)
+		}
+	}
+	return nil
+}
+
+// tagName 返回标签中的名称，标签不存在或为 - 时返回空字符串
+func tagName(f reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setField 将字符串转换为字段的类型，切片字段接收所有的值，其余字段使用第一个值
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setValue(fv, vals[0])
+}
+
+// setValue 将字符串转换为基本类型、time.Duration、time.Time 或实现了 encoding.TextUnmarshaler 的类型
+func setValue(fv reflect.Value, s string) error {
+	if fv.Kind() == reflect.Pointer {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
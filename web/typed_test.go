@@ -0,0 +1,182 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type typedPage struct {
+	Page int `query:"page"`
+	Size int `query:"size"`
+}
+
+type typedCreateUser struct {
+	typedPage
+	Org     string        `path:"org"`
+	Name    string        `json:"name" xml:"name"`
+	Tags    []string      `query:"tag"`
+	Admin   *bool         `query:"admin"`
+	Timeout time.Duration `query:"timeout"`
+	Trace   string        `header:"X-Trace-ID"`
+}
+
+func (r typedCreateUser) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Name == "root" {
+		return NewHTTPError(http.StatusConflict, "name is taken")
+	}
+	return nil
+}
+
+type typedUser struct {
+	Org  string `json:"org"`
+	Name string `json:"name"`
+}
+
+type typedCreated struct {
+	typedUser
+}
+
+func (typedCreated) StatusCode() int {
+	return http.StatusCreated
+}
+
+func TestH(t *testing.T) {
+	var got typedCreateUser
+	s := NewHTTPServer()
+	s.Post("/orgs/:org/users", H(func(ctx *Context, req typedCreateUser) (typedCreated, error) {
+		got = req
+		if req.Name == "fail" {
+			return typedCreated{}, NewHTTPError(http.StatusServiceUnavailable, "try again later")
+		}
+		return typedCreated{typedUser{Org: req.Org, Name: req.Name}}, nil
+	}))
+	s.Delete("/users/:id", H(func(ctx *Context, req *struct {
+		ID int64 `path:"id"`
+	}) (Empty, error) {
+		if req.ID == 0 {
+			return Empty{}, errors.New("unreachable")
+		}
+		return Empty{}, nil
+	}))
+
+	testCases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:     "json body with query, path and header",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users?page=2&size=10&tag=a&tag=b&admin=true&timeout=1m30s",
+			body:     `{"name":"tom"}`,
+			wantCode: http.StatusCreated,
+			wantBody: "{\"org\":\"acme\",\"name\":\"tom\"}\n",
+		},
+		{
+			name:        "xml body",
+			method:      http.MethodPost,
+			target:      "/orgs/acme/users",
+			contentType: "application/xml",
+			body:        `<typedCreateUser><name>tom</name></typedCreateUser>`,
+			wantCode:    http.StatusCreated,
+			wantBody:    "{\"org\":\"acme\",\"name\":\"tom\"}\n",
+		},
+		{
+			name:     "malformed body",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users",
+			body:     `{"name":`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid request body\"}\n",
+		},
+		{
+			name:     "invalid query parameter",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users?page=two",
+			body:     `{"name":"tom"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid query parameter page\"}\n",
+		},
+		{
+			name:     "validation error",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"name is required\"}\n",
+		},
+		{
+			name:     "validation http error",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users",
+			body:     `{"name":"root"}`,
+			wantCode: http.StatusConflict,
+			wantBody: "{\"error\":\"name is taken\"}\n",
+		},
+		{
+			name:     "handler error",
+			method:   http.MethodPost,
+			target:   "/orgs/acme/users",
+			body:     `{"name":"fail"}`,
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "{\"error\":\"try again later\"}\n",
+		},
+		{
+			name:     "empty response",
+			method:   http.MethodDelete,
+			target:   "/users/42",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "invalid path parameter",
+			method:   http.MethodDelete,
+			target:   "/users/abc",
+			wantCode: http.StatusBadRequest,
+			wantBody: "{\"error\":\"invalid path parameter id\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			req.Header.Set("X-Trace-ID", "trace-1")
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+			assert.Equal(t, tc.wantBody, rec.Body.String())
+		})
+	}
+
+	t.Run("bound fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/users?page=2&size=10&tag=a&tag=b&admin=true&timeout=1m30s", strings.NewReader(`{"name":"tom"}`))
+		req.Header.Set("X-Trace-ID", "trace-1")
+		s.ServeHTTP(httptest.NewRecorder(), req)
+
+		admin := true
+		assert.Equal(t, typedCreateUser{
+			typedPage: typedPage{Page: 2, Size: 10},
+			Org:       "acme",
+			Name:      "tom",
+			Tags:      []string{"a", "b"},
+			Admin:     &admin,
+			Timeout:   90 * time.Second,
+			Trace:     "trace-1",
+		}, got)
+	})
+}
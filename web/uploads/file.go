@@ -0,0 +1,173 @@
+package uploads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore 将上传保存在本地目录中，每个上传对应数据文件 <id> 和状态文件 <id>.info
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore 创建保存在 dir 中的存储，目录不存在时自动创建
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Path 返回上传的数据文件路径，上传完成后可以在 WithOnComplete 中移动该文件
+func (s *FileStore) Path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// Create 保存新的上传并创建空的数据文件
+func (s *FileStore) Create(_ context.Context, upload *Upload) error {
+	if !validID(upload.ID) {
+		return fmt.Errorf("uploads: invalid upload id %q", upload.ID)
+	}
+	f, err := os.OpenFile(s.Path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.writeInfo(upload)
+}
+
+// Get 读取上传的状态
+func (s *FileStore) Get(_ context.Context, id string) (*Upload, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return s.readInfo(s.infoPath(id))
+}
+
+// WriteChunk 在 upload.Offset 处写入 r 的内容，写入中断时保存已经写入的部分
+func (s *FileStore) WriteChunk(_ context.Context, upload *Upload, r io.Reader) (int64, error) {
+	if !validID(upload.ID) {
+		return 0, ErrNotFound
+	}
+	f, err := os.OpenFile(s.Path(upload.ID), os.O_WRONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	// 丢弃上一次中断时可能写入但没有记录的数据
+	if err := f.Truncate(upload.Offset); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if _, err := f.Seek(upload.Offset, io.SeekStart); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+
+	n, copyErr := io.Copy(f, r)
+	if err := f.Sync(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	upload.Offset += n
+	if err := s.writeInfo(upload); err != nil {
+		return n, err
+	}
+	return n, copyErr
+}
+
+// Delete 删除上传的数据和状态
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	if err := os.Remove(s.infoPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.Path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired 删除 now 之前过期的上传
+func (s *FileStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	infos, err := filepath.Glob(filepath.Join(s.dir, "*.info"))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, path := range infos {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		upload, err := s.readInfo(path)
+		if err != nil {
+			continue
+		}
+		if upload.ExpiresAt.IsZero() || now.Before(upload.ExpiresAt) {
+			continue
+		}
+		if err := s.Delete(ctx, upload.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+func (s *FileStore) readInfo(path string) (*Upload, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// writeInfo 先写入临时文件再重命名，避免中断时留下不完整的状态文件
+func (s *FileStore) writeInfo(upload *Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(upload.ID))
+}
+
+// validID 上传ID只能包含字母、数字、- 和 _，防止路径穿越
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) < 0
+}
@@ -0,0 +1,95 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// MemoryStore 将上传保存在内存中，适合测试和开发环境
+type MemoryStore struct {
+	mu      sync.Mutex
+	uploads map[string]*memoryUpload
+}
+
+type memoryUpload struct {
+	upload Upload
+	data   bytes.Buffer
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uploads: make(map[string]*memoryUpload)}
+}
+
+// Create 保存新的上传
+func (s *MemoryStore) Create(_ context.Context, upload *Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads[upload.ID] = &memoryUpload{upload: *upload}
+	return nil
+}
+
+// Get 返回上传状态的副本
+func (s *MemoryStore) Get(_ context.Context, id string) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mu, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	upload := mu.upload
+	return &upload, nil
+}
+
+// WriteChunk 在 upload.Offset 处追加 r 的内容
+func (s *MemoryStore) WriteChunk(_ context.Context, upload *Upload, r io.Reader) (int64, error) {
+	data, readErr := io.ReadAll(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mu, ok := s.uploads[upload.ID]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	mu.data.Truncate(int(upload.Offset))
+	mu.data.Write(data)
+	upload.Offset += int64(len(data))
+	mu.upload = *upload
+	return int64(len(data)), readErr
+}
+
+// Delete 删除上传
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.uploads, id)
+	return nil
+}
+
+// DeleteExpired 删除 now 之前过期的上传
+func (s *MemoryStore) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, mu := range s.uploads {
+		if !mu.upload.ExpiresAt.IsZero() && !now.Before(mu.upload.ExpiresAt) {
+			delete(s.uploads, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Data 返回上传的内容，上传不存在时返回 ErrNotFound
+func (s *MemoryStore) Data(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mu, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(mu.data.Bytes()), nil
+}
@@ -0,0 +1,414 @@
+// Package uploads 实现 tus 1.0 协议的断点续传上传，客户端可以在网络中断后从已上传的位置继续，
+// 适合在不稳定的网络上传大文件。支持 tus 的 creation、expiration 和 termination 扩展：
+//
+//	POST   /files          创建上传，Upload-Length 为文件大小，返回 Location
+//	HEAD   /files/:id      查询已上传的字节数 Upload-Offset
+//	PATCH  /files/:id      从 Upload-Offset 处继续上传，Content-Type 为 application/offset+octet-stream
+//	DELETE /files/:id      取消上传
+//	OPTIONS /files         查询服务器支持的协议版本和扩展
+//
+// 上传的数据和状态保存在 Store 中，内置 FileStore 和 MemoryStore
+package uploads
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/fyerfyer/fyer-webframe/web/logger"
+)
+
+const (
+	// TusVersion 支持的 tus 协议版本
+	TusVersion = "1.0.0"
+	// tusExtensions 支持的 tus 扩展
+	tusExtensions = "creation,expiration,termination"
+	// offsetContentType PATCH 请求的 Content-Type
+	offsetContentType = "application/offset+octet-stream"
+)
+
+// ErrNotFound 上传不存在
+var ErrNotFound = errors.New("uploads: upload not found")
+
+// Upload 一次上传的状态
+type Upload struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Done 判断上传是否已经完成
+func (u *Upload) Done() bool {
+	return u.Offset >= u.Size
+}
+
+// Store 上传数据和状态的存储
+type Store interface {
+	// Create 保存新的上传
+	Create(ctx context.Context, upload *Upload) error
+	// Get 返回上传的状态，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Upload, error)
+	// WriteChunk 在 upload.Offset 处写入 r 的内容，返回写入的字节数。
+	// 读取 r 失败时也需要保存已经写入的部分，并将 upload.Offset 更新为写入后的位置
+	WriteChunk(ctx context.Context, upload *Upload, r io.Reader) (int64, error)
+	// Delete 删除上传的数据和状态
+	Delete(ctx context.Context, id string) error
+	// DeleteExpired 删除 now 之前过期的上传，返回删除的数量
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// Option Handler 的选项
+type Option func(*Handler)
+
+// WithMaxSize 设置单个上传的最大字节数，默认不限制
+func WithMaxSize(size int64) Option {
+	return func(h *Handler) {
+		h.maxSize = size
+	}
+}
+
+// WithExpiration 设置上传的有效期，每次上传数据后重新计算，默认为24小时
+func WithExpiration(d time.Duration) Option {
+	return func(h *Handler) {
+		h.expiration = d
+	}
+}
+
+// WithOnComplete 设置上传完成后的回调，通常用于把文件移动到最终位置或者记录到数据库，
+// 返回错误时 PATCH 请求以该错误结束，客户端会重试最后一次请求
+func WithOnComplete(fn func(ctx *web.Context, upload *Upload) error) Option {
+	return func(h *Handler) {
+		h.onComplete = fn
+	}
+}
+
+// Routes 可以注册上传路由的服务器或路由组
+type Routes interface {
+	Post(path string, handler web.HandlerFunc) web.RouteRegister
+	Head(path string, handler web.HandlerFunc) web.RouteRegister
+	Patch(path string, handler web.HandlerFunc) web.RouteRegister
+	Delete(path string, handler web.HandlerFunc) web.RouteRegister
+	Options(path string, handler web.HandlerFunc) web.RouteRegister
+}
+
+// Handler 处理 tus 协议的请求
+type Handler struct {
+	store      Store
+	maxSize    int64
+	expiration time.Duration
+	onComplete func(ctx *web.Context, upload *Upload) error
+
+	mu     sync.Mutex
+	active map[string]struct{} // 正在写入的上传，同一个上传同时只能有一个 PATCH 请求
+}
+
+// New 创建使用 store 保存上传的 Handler
+func New(store Store, opts ...Option) *Handler {
+	h := &Handler{
+		store:      store,
+		expiration: 24 * time.Hour,
+		active:     make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Mount 在 prefix 下注册上传路由：
+//
+//	store, err := uploads.NewFileStore("./data/uploads")
+//	h := uploads.New(store, uploads.WithMaxSize(10<<30))
+//	h.Mount(server, "/files")
+func (h *Handler) Mount(r Routes, prefix string) {
+	prefix = strings.TrimRight(prefix, "/")
+	r.Options(prefix, h.handleOptions)
+	r.Post(prefix, h.handleCreate)
+	r.Head(prefix+"/:id", h.handleHead)
+	r.Patch(prefix+"/:id", h.handlePatch)
+	r.Delete(prefix+"/:id", h.handleDelete)
+}
+
+// handleOptions 返回服务器支持的协议版本和扩展
+func (h *Handler) handleOptions(ctx *web.Context) {
+	header := ctx.Resp.Header()
+	header.Set("Tus-Resumable", TusVersion)
+	header.Set("Tus-Version", TusVersion)
+	header.Set("Tus-Extension", tusExtensions)
+	if h.maxSize > 0 {
+		header.Set("Tus-Max-Size", strconv.FormatInt(h.maxSize, 10))
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// handleCreate 创建上传
+func (h *Handler) handleCreate(ctx *web.Context) {
+	if !h.checkVersion(ctx) {
+		return
+	}
+	size, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		ctx.Error(web.NewHTTPError(http.StatusBadRequest, "invalid Upload-Length"))
+		return
+	}
+	if h.maxSize > 0 && size > h.maxSize {
+		ctx.Error(web.NewHTTPError(http.StatusRequestEntityTooLarge, "upload exceeds Tus-Max-Size"))
+		return
+	}
+	metadata, err := ParseMetadata(ctx.GetHeader("Upload-Metadata"))
+	if err != nil {
+		ctx.Error(web.NewHTTPError(http.StatusBadRequest, "invalid Upload-Metadata").WithInternal(err))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	now := ctx.Now()
+	upload := &Upload{
+		ID:        id,
+		Size:      size,
+		Metadata:  metadata,
+		CreatedAt: now,
+		ExpiresAt: now.Add(h.expiration),
+	}
+	if err := h.store.Create(ctx.Context, upload); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Logger().Info("Upload created", logger.String("upload_id", id), logger.Int64("size", size))
+
+	// 大小为0的上传创建后即完成
+	if upload.Done() && !h.complete(ctx, upload) {
+		return
+	}
+	ctx.Resp.Header().Set("Location", strings.TrimRight(ctx.Req.URL.Path, "/")+"/"+id)
+	setExpires(ctx, upload)
+	ctx.Status(http.StatusCreated)
+}
+
+// handleHead 返回已上传的字节数
+func (h *Handler) handleHead(ctx *web.Context) {
+	if !h.checkVersion(ctx) {
+		return
+	}
+	upload, ok := h.load(ctx)
+	if !ok {
+		return
+	}
+	header := ctx.Resp.Header()
+	header.Set("Cache-Control", "no-store")
+	header.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	if len(upload.Metadata) > 0 {
+		header.Set("Upload-Metadata", FormatMetadata(upload.Metadata))
+	}
+	setExpires(ctx, upload)
+	ctx.Status(http.StatusOK)
+}
+
+// handlePatch 从 Upload-Offset 处继续上传
+func (h *Handler) handlePatch(ctx *web.Context) {
+	if !h.checkVersion(ctx) {
+		return
+	}
+	if ctx.ContentType() != offsetContentType {
+		ctx.Error(web.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be "+offsetContentType))
+		return
+	}
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.Error(web.NewHTTPError(http.StatusBadRequest, "invalid Upload-Offset"))
+		return
+	}
+
+	id := ctx.PathParam("id").Value
+	if !h.lock(id) {
+		ctx.Error(web.NewHTTPError(http.StatusLocked, "upload is in progress"))
+		return
+	}
+	defer h.unlock(id)
+
+	upload, ok := h.load(ctx)
+	if !ok {
+		return
+	}
+	if offset != upload.Offset {
+		ctx.Error(web.NewHTTPError(http.StatusConflict, "Upload-Offset does not match"))
+		return
+	}
+	remaining := upload.Size - upload.Offset
+	if ctx.Req.ContentLength > remaining {
+		ctx.Error(web.NewHTTPError(http.StatusRequestEntityTooLarge, "chunk exceeds Upload-Length"))
+		return
+	}
+
+	upload.ExpiresAt = ctx.Now().Add(h.expiration)
+	n, err := h.store.WriteChunk(ctx.Context, upload, io.LimitReader(ctx.Req.Body, remaining))
+	if err != nil {
+		if ctx.IsClientGone() {
+			// 已经写入的部分已保存，客户端重新连接后通过 HEAD 请求继续
+			ctx.Logger().Info("Upload interrupted", logger.String("upload_id", id), logger.Int64("offset", upload.Offset))
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+	ctx.Logger().Debug("Upload chunk written", logger.String("upload_id", id), logger.Int64("bytes", n),
+		logger.Int64("offset", upload.Offset))
+
+	if upload.Done() && !h.complete(ctx, upload) {
+		return
+	}
+	ctx.Resp.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	setExpires(ctx, upload)
+	ctx.Status(http.StatusNoContent)
+}
+
+// handleDelete 取消上传
+func (h *Handler) handleDelete(ctx *web.Context) {
+	if !h.checkVersion(ctx) {
+		return
+	}
+	id := ctx.PathParam("id").Value
+	if !h.lock(id) {
+		ctx.Error(web.NewHTTPError(http.StatusLocked, "upload is in progress"))
+		return
+	}
+	defer h.unlock(id)
+
+	if _, ok := h.load(ctx); !ok {
+		return
+	}
+	if err := h.store.Delete(ctx.Context, id); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// checkVersion 检查请求的 Tus-Resumable，不支持的版本返回412
+func (h *Handler) checkVersion(ctx *web.Context) bool {
+	ctx.Resp.Header().Set("Tus-Resumable", TusVersion)
+	if ctx.GetHeader("Tus-Resumable") == TusVersion {
+		return true
+	}
+	ctx.Resp.Header().Set("Tus-Version", TusVersion)
+	ctx.Error(web.NewHTTPError(http.StatusPreconditionFailed, "unsupported Tus-Resumable version"))
+	return false
+}
+
+// load 读取路径参数对应的上传，不存在返回404，过期返回410
+func (h *Handler) load(ctx *web.Context) (*Upload, bool) {
+	upload, err := h.store.Get(ctx.Context, ctx.PathParam("id").Value)
+	if errors.Is(err, ErrNotFound) {
+		ctx.Error(web.NewHTTPError(http.StatusNotFound, "upload not found"))
+		return nil, false
+	}
+	if err != nil {
+		ctx.Error(err)
+		return nil, false
+	}
+	if !upload.ExpiresAt.IsZero() && !ctx.Now().Before(upload.ExpiresAt) {
+		ctx.Error(web.NewHTTPError(http.StatusGone, "upload expired"))
+		return nil, false
+	}
+	return upload, true
+}
+
+// complete 调用上传完成的回调，失败时返回 false
+func (h *Handler) complete(ctx *web.Context, upload *Upload) bool {
+	ctx.Logger().Info("Upload completed", logger.String("upload_id", upload.ID), logger.Int64("size", upload.Size))
+	if h.onComplete == nil {
+		return true
+	}
+	if err := h.onComplete(ctx, upload); err != nil {
+		ctx.Error(err)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) lock(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.active[id]; ok {
+		return false
+	}
+	h.active[id] = struct{}{}
+	return true
+}
+
+func (h *Handler) unlock(id string) {
+	h.mu.Lock()
+	delete(h.active, id)
+	h.mu.Unlock()
+}
+
+// setExpires 设置 Upload-Expires 响应头
+func setExpires(ctx *web.Context, upload *Upload) {
+	if !upload.ExpiresAt.IsZero() {
+		ctx.Resp.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// newID 生成上传的ID
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// ParseMetadata 解析 Upload-Metadata 头，格式为逗号分隔的键和 base64 编码的值
+func ParseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("uploads: empty metadata key")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+// FormatMetadata 将元数据编码为 Upload-Metadata 头
+func FormatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if v := metadata[k]; v != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
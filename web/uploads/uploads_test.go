@@ -0,0 +1,255 @@
+package uploads
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fyerfyer/fyer-webframe/clock"
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tusRequest 构造带有 Tus-Resumable 头的请求
+func tusRequest(method, target string, body io.Reader, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Tus-Resumable", TusVersion)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func serve(s *web.HTTPServer, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+// create 创建上传并返回 Location
+func create(t *testing.T, s *web.HTTPServer, size int, metadata string) string {
+	t.Helper()
+	rec := serve(s, tusRequest(http.MethodPost, "/files", nil, map[string]string{
+		"Upload-Length":   strconv.Itoa(size),
+		"Upload-Metadata": metadata,
+	}))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	location := rec.Header().Get("Location")
+	require.True(t, strings.HasPrefix(location, "/files/"), location)
+	return location
+}
+
+// patch 从 offset 处上传 chunk
+func patch(s *web.HTTPServer, location string, offset int, chunk string) *httptest.ResponseRecorder {
+	return serve(s, tusRequest(http.MethodPatch, location, strings.NewReader(chunk), map[string]string{
+		"Content-Type":  offsetContentType,
+		"Upload-Offset": strconv.Itoa(offset),
+	}))
+}
+
+func TestHandler_Resumable(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"file": func(t *testing.T) Store {
+			store, err := NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			var completed *Upload
+			s := web.NewHTTPServer()
+			New(store, WithOnComplete(func(ctx *web.Context, upload *Upload) error {
+				completed = upload
+				return nil
+			})).Mount(s, "/files")
+
+			location := create(t, s, 11, FormatMetadata(map[string]string{"filename": "hello.txt"}))
+			id := strings.TrimPrefix(location, "/files/")
+
+			rec := patch(s, location, 0, "hello")
+			require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+			assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+			assert.Equal(t, TusVersion, rec.Header().Get("Tus-Resumable"))
+			assert.NotEmpty(t, rec.Header().Get("Upload-Expires"))
+			assert.Nil(t, completed)
+
+			// 中断后通过 HEAD 查询偏移量继续上传
+			rec = serve(s, tusRequest(http.MethodHead, location, nil, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+			assert.Equal(t, "11", rec.Header().Get("Upload-Length"))
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			metadata, err := ParseMetadata(rec.Header().Get("Upload-Metadata"))
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"filename": "hello.txt"}, metadata)
+
+			rec = patch(s, location, 0, "hello")
+			assert.Equal(t, http.StatusConflict, rec.Code, "offset mismatch")
+
+			rec = patch(s, location, 5, " world")
+			require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+			assert.Equal(t, "11", rec.Header().Get("Upload-Offset"))
+			require.NotNil(t, completed)
+			assert.Equal(t, id, completed.ID)
+			assert.True(t, completed.Done())
+
+			switch st := store.(type) {
+			case *MemoryStore:
+				data, err := st.Data(id)
+				require.NoError(t, err)
+				assert.Equal(t, "hello world", string(data))
+			case *FileStore:
+				data, err := os.ReadFile(st.Path(id))
+				require.NoError(t, err)
+				assert.Equal(t, "hello world", string(data))
+			}
+
+			rec = serve(s, tusRequest(http.MethodDelete, location, nil, nil))
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			rec = serve(s, tusRequest(http.MethodHead, location, nil, nil))
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		})
+	}
+}
+
+func TestHandler_Validation(t *testing.T) {
+	s := web.NewHTTPServer()
+	New(NewMemoryStore(), WithMaxSize(10)).Mount(s, "/files")
+	location := create(t, s, 4, "")
+
+	testCases := []struct {
+		name     string
+		req      *http.Request
+		wantCode int
+	}{
+		{
+			name:     "missing version",
+			req:      httptest.NewRequest(http.MethodHead, location, nil),
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "exceeds max size",
+			req:      tusRequest(http.MethodPost, "/files", nil, map[string]string{"Upload-Length": "11"}),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "missing length",
+			req:      tusRequest(http.MethodPost, "/files", nil, nil),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid metadata",
+			req:      tusRequest(http.MethodPost, "/files", nil, map[string]string{"Upload-Length": "1", "Upload-Metadata": "name !!!"}),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "wrong content type",
+			req: tusRequest(http.MethodPatch, location, strings.NewReader("ab"), map[string]string{
+				"Content-Type":  "application/octet-stream",
+				"Upload-Offset": "0",
+			}),
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "chunk exceeds length",
+			req: tusRequest(http.MethodPatch, location, strings.NewReader("abcdef"), map[string]string{
+				"Content-Type":  offsetContentType,
+				"Upload-Offset": "0",
+			}),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "unknown upload",
+			req:      tusRequest(http.MethodHead, "/files/missing", nil, nil),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "path traversal",
+			req:      tusRequest(http.MethodHead, "/files/..%2Fsecret", nil, nil),
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(s, tc.req)
+			assert.Equal(t, tc.wantCode, rec.Code)
+		})
+	}
+
+	rec := serve(s, httptest.NewRequest(http.MethodOptions, "/files", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, TusVersion, rec.Header().Get("Tus-Version"))
+	assert.Equal(t, "creation,expiration,termination", rec.Header().Get("Tus-Extension"))
+	assert.Equal(t, "10", rec.Header().Get("Tus-Max-Size"))
+}
+
+func TestHandler_Expiration(t *testing.T) {
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryStore()
+	s := web.NewHTTPServer(web.WithClock(clk))
+	New(store, WithExpiration(time.Hour)).Mount(s, "/files")
+
+	location := create(t, s, 4, "")
+	clk.Advance(30 * time.Minute)
+	require.Equal(t, http.StatusNoContent, patch(s, location, 0, "ab").Code)
+
+	// 上传数据后重新计算有效期
+	clk.Advance(45 * time.Minute)
+	rec := serve(s, tusRequest(http.MethodHead, location, nil, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	clk.Advance(time.Hour)
+	rec = serve(s, tusRequest(http.MethodHead, location, nil, nil))
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	n, err := store.DeleteExpired(context.Background(), clk.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestFileStore_InterruptedChunk(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+	upload := &Upload{ID: "abc", Size: 10}
+	require.NoError(t, store.Create(ctx, upload))
+
+	// 读取请求体中途失败时保存已经写入的部分
+	r := io.MultiReader(strings.NewReader("abcd"), &failingReader{})
+	n, err := store.WriteChunk(ctx, upload, r)
+	assert.Error(t, err)
+	assert.EqualValues(t, 4, n)
+
+	saved, err := store.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, saved.Offset)
+
+	_, err = store.WriteChunk(ctx, saved, strings.NewReader("efghij"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(store.Path("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "abcdefghij", string(data))
+}
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestMetadata(t *testing.T) {
+	metadata, err := ParseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "world_domination_plan.pdf", "is_confidential": ""}, metadata)
+	assert.Equal(t, "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential", FormatMetadata(metadata))
+}
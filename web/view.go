@@ -0,0 +1,125 @@
+package web
+
+import (
+	"fmt"
+)
+
+const (
+	// CurrentUserKey UserValues中记录当前用户的键
+	CurrentUserKey = "current_user"
+	// CSRFTokenKey UserValues中记录CSRF令牌的键，CSRF中间件应写入该键
+	CSRFTokenKey = "csrf_token"
+	// FlashKey UserValues中记录闪现消息的键
+	FlashKey = "flash_messages"
+	// LocaleKey UserValues中记录当前语言的键，i18n中间件应写入该键
+	LocaleKey = "locale"
+)
+
+// Flash 闪现消息
+type Flash struct {
+	Level   string // 消息级别，如 success、error、info
+	Message string
+}
+
+// Translator 翻译函数，根据语言和键返回翻译后的文本
+type Translator func(locale, key string, args ...any) string
+
+// ViewDataInjector 渲染前向视图模型注入请求级数据，如导航菜单、站点配置等
+type ViewDataInjector func(ctx *Context, vd *ViewData)
+
+// ViewData 服务端渲染页面的视图模型
+// 通过 ctx.View 渲染时会自动填充闪现消息、CSRF令牌、当前用户和语言，
+// 模板中可以直接使用 {{.User}}、{{.CSRFToken}}、{{range .Flashes}} 和 {{.T "key"}}
+type ViewData struct {
+	Layout    string         // 使用的布局，为空时使用模板引擎的默认布局
+	Title     string         // 页面标题
+	Data      any            // 页面数据
+	Flashes   []Flash        // 闪现消息
+	CSRFToken string         // CSRF令牌
+	User      any            // 当前用户
+	Locale    string         // 当前语言
+	Values    map[string]any // 注入的其他数据，模板中通过 {{.Values.key}} 使用
+
+	translator Translator
+}
+
+// T 翻译文本，未配置翻译函数时原样返回键
+func (v *ViewData) T(key string, args ...any) string {
+	if v.translator == nil {
+		if len(args) > 0 {
+			return fmt.Sprintf(key, args...)
+		}
+		return key
+	}
+	return v.translator(v.Locale, key, args...)
+}
+
+// Set 设置注入的数据
+func (v *ViewData) Set(key string, value any) {
+	if v.Values == nil {
+		v.Values = make(map[string]any)
+	}
+	v.Values[key] = value
+}
+
+// Map 将视图模型展开为键值对，供 pongo2、Jet 等以键值对作为模板变量的模板引擎使用
+func (v *ViewData) Map() map[string]any {
+	return map[string]any{
+		"Title":     v.Title,
+		"Data":      v.Data,
+		"Flashes":   v.Flashes,
+		"CSRFToken": v.CSRFToken,
+		"User":      v.User,
+		"Locale":    v.Locale,
+		"Values":    v.Values,
+		"T":         v.T,
+	}
+}
+
+// NewViewData 使用请求上下文中的闪现消息、CSRF令牌、当前用户和语言创建视图模型
+func (c *Context) NewViewData(data any) *ViewData {
+	vd := &ViewData{Data: data}
+	vd.Flashes, _ = c.UserValues[FlashKey].([]Flash)
+	vd.CSRFToken, _ = c.UserValues[CSRFTokenKey].(string)
+	vd.User = c.UserValues[CurrentUserKey]
+	vd.Locale, _ = c.UserValues[LocaleKey].(string)
+	return vd
+}
+
+// View 使用视图模型渲染模板，data可以是 *ViewData 或任意页面数据
+func (c *Context) View(name string, data any) error {
+	vd, ok := data.(*ViewData)
+	if !ok {
+		vd = c.NewViewData(data)
+	}
+	return c.Template(name, vd)
+}
+
+// AddFlash 添加一条闪现消息
+// 闪现消息只在当前请求内有效，需要跨重定向保留时可以由会话中间件读取后再次写入
+func (c *Context) AddFlash(level, message string) {
+	if c.UserValues == nil {
+		c.UserValues = make(map[string]any)
+	}
+	flashes, _ := c.UserValues[FlashKey].([]Flash)
+	c.UserValues[FlashKey] = append(flashes, Flash{Level: level, Message: message})
+}
+
+// Flashes 返回当前请求的闪现消息
+func (c *Context) Flashes() []Flash {
+	flashes, _ := c.UserValues[FlashKey].([]Flash)
+	return flashes
+}
+
+// SetCurrentUser 设置当前用户，通常由认证中间件调用
+func (c *Context) SetCurrentUser(user any) {
+	if c.UserValues == nil {
+		c.UserValues = make(map[string]any)
+	}
+	c.UserValues[CurrentUserKey] = user
+}
+
+// CurrentUser 返回当前用户
+func (c *Context) CurrentUser() any {
+	return c.UserValues[CurrentUserKey]
+}
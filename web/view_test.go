@@ -0,0 +1,161 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLayoutTemplate(t *testing.T, opts ...GoTemplateOption) *GoTemplate {
+	dir := t.TempDir()
+	layoutDir := filepath.Join(dir, "layouts")
+	pageDir := filepath.Join(dir, "pages")
+	require.NoError(t, os.MkdirAll(layoutDir, 0755))
+	require.NoError(t, os.MkdirAll(pageDir, 0755))
+
+	files := map[string]string{
+		filepath.Join(layoutDir, "base.html"): `{{define "base.html"}}<title>{{.Title}}</title>` +
+			`{{range .Flashes}}<p class="{{.Level}}">{{.Message}}</p>{{end}}` +
+			`<main>{{block "content" .}}{{end}}</main>{{block "scripts" .}}{{end}}{{end}}`,
+		filepath.Join(layoutDir, "plain.html"): `{{define "plain.html"}}[{{block "content" .}}{{end}}]{{end}}`,
+		filepath.Join(pageDir, "home.html"): `{{define "content"}}<h1>{{.T "welcome"}} {{.User}}</h1>` +
+			`<input type="hidden" value="{{.CSRFToken}}">{{end}}` +
+			`{{define "scripts"}}<script src="/home.js"></script>{{end}}`,
+		filepath.Join(pageDir, "about.html"): `{{define "content"}}<h1>About {{.Data}}</h1>{{end}}`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0666))
+	}
+
+	opts = append([]GoTemplateOption{
+		WithLayouts(filepath.Join(layoutDir, "*.html")),
+		WithDefaultLayout("base.html"),
+		WithPattern(filepath.Join(pageDir, "*.html")),
+	}, opts...)
+	return NewGoTemplate(opts...)
+}
+
+func TestGoTemplate_Layouts(t *testing.T) {
+	tpl := newLayoutTemplate(t, WithTranslator(func(locale, key string, args ...any) string {
+		if locale == "zh" && key == "welcome" {
+			return "欢迎"
+		}
+		return key
+	}))
+
+	// 每个页面的同名块互不覆盖
+	result, err := tpl.Render(&Context{}, "about.html", &ViewData{Title: "About", Data: "us"})
+	require.NoError(t, err)
+	assert.Equal(t, "<title>About</title><main><h1>About us</h1></main>", string(result))
+
+	result, err = tpl.Render(&Context{}, "home.html", &ViewData{Title: "Home", User: "tom", Locale: "zh", CSRFToken: "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, `<title>Home</title><main><h1>欢迎 tom</h1><input type="hidden" value="abc"></main><script src="/home.js"></script>`, string(result))
+
+	// ViewData 可以覆盖默认布局
+	result, err = tpl.Render(&Context{}, "about.html", &ViewData{Layout: "plain.html", Data: "us"})
+	require.NoError(t, err)
+	assert.Equal(t, "[<h1>About us</h1>]", string(result))
+
+	_, err = tpl.Render(&Context{}, "about.html", &ViewData{Layout: "missing.html"})
+	assert.Error(t, err)
+
+	// 片段渲染同样使用页面自己的块
+	result, err = tpl.RenderFragment(&Context{}, "about.html", "content", &ViewData{Data: "us"})
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>About us</h1>", string(result))
+}
+
+func TestContextView(t *testing.T) {
+	s := NewHTTPServer(WithTemplate(newLayoutTemplate(t)))
+	s.Use("GET", "/", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.SetCurrentUser("tom")
+			ctx.UserValues[CSRFTokenKey] = "abc"
+			next(ctx)
+		}
+	})
+	s.Get("/home", func(ctx *Context) {
+		ctx.AddFlash("success", "saved")
+		vd := ctx.NewViewData(nil)
+		vd.Title = "Home"
+		ctx.View("home.html", vd)
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/home", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `<title>Home</title><p class="success">saved</p><main><h1>welcome tom</h1><input type="hidden" value="abc"></main><script src="/home.js"></script>`, resp.Body.String())
+}
+
+func TestGoTemplate_ViewsDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layouts/base.html": `<title>{{.Title}}</title>{{template "partials/nav" .}}<main>{{block "content" .}}{{end}}</main>`,
+		"partials/nav.html": `<nav>{{upper .Values.site}}</nav>`,
+		"users/show.html":   `{{define "content"}}<h1>{{.Data}}</h1><i>{{.CSRFToken}}</i>{{end}}`,
+		"users/list.html":   `{{define "content"}}<ul>{{range .Data}}<li>{{.}}</li>{{end}}</ul>{{end}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0666))
+	}
+
+	RegisterTemplateFunc("upper", strings.ToUpper)
+	tpl := NewGoTemplate(
+		WithViewsDir(dir),
+		WithDefaultLayout("layouts/base"),
+		WithViewDataInjector(func(ctx *Context, vd *ViewData) {
+			vd.Set("site", "demo")
+		}),
+	)
+
+	s := NewHTTPServer(WithTemplate(tpl))
+	s.Use("GET", "/*", func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.UserValues[CSRFTokenKey] = "abc"
+			next(ctx)
+		}
+	})
+	s.Get("/users/show", func(ctx *Context) {
+		ctx.View("users/show", "tom")
+	})
+	s.Get("/users", func(ctx *Context) {
+		ctx.View("users/list", []string{"tom", "jerry"})
+	})
+
+	resp := httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/users/show", nil))
+	assert.Equal(t, `<title></title><nav>DEMO</nav><main><h1>tom</h1><i>abc</i></main>`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, `<title></title><nav>DEMO</nav><main><ul><li>tom</li><li>jerry</li></ul></main>`, resp.Body.String())
+
+	// 布局和公共片段不能作为页面直接渲染
+	_, err := tpl.Render(&Context{}, "partials/missing", &ViewData{})
+	assert.Error(t, err)
+
+	// 片段渲染同样会注入数据
+	result, err := tpl.RenderFragment(&Context{}, "users/show", "content", &ViewData{Data: "tom"})
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>tom</h1><i></i>", string(result))
+}
+
+func TestWithFuncs(t *testing.T) {
+	custom := template.FuncMap{"a": func() string { return "a" }}
+	tpl := NewGoTemplate(WithFuncMap(custom), WithFuncs(template.FuncMap{"b": func() string { return "b" }}))
+	assert.Contains(t, tpl.funcMap, "a")
+	assert.Contains(t, tpl.funcMap, "b")
+	// 不修改调用方传入的FuncMap
+	assert.NotContains(t, custom, "b")
+}
@@ -0,0 +1,175 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultViewExt 视图目录中模板文件的默认扩展名
+	defaultViewExt = ".html"
+	// layoutsDir 视图目录中存放布局的子目录
+	layoutsDir = "layouts/"
+	// partialsDir 视图目录中存放公共片段的子目录
+	partialsDir = "partials/"
+)
+
+var (
+	sharedFuncsMu sync.RWMutex
+	sharedFuncs   = make(template.FuncMap)
+)
+
+// RegisterTemplateFunc 注册所有 GoTemplate 共享的模板函数
+// 只对之后创建的模板引擎生效，通常在 init 中调用；与 WithFuncMap 同名时以 WithFuncMap 为准
+func RegisterTemplateFunc(name string, fn any) {
+	sharedFuncsMu.Lock()
+	defer sharedFuncsMu.Unlock()
+	sharedFuncs[name] = fn
+}
+
+// mergeSharedFuncs 将共享模板函数与引擎自己的模板函数合并，不修改调用方传入的FuncMap
+func mergeSharedFuncs(funcMap template.FuncMap) template.FuncMap {
+	sharedFuncsMu.RLock()
+	defer sharedFuncsMu.RUnlock()
+
+	merged := make(template.FuncMap, len(sharedFuncs)+len(funcMap))
+	for name, fn := range sharedFuncs {
+		merged[name] = fn
+	}
+	for name, fn := range funcMap {
+		merged[name] = fn
+	}
+	return merged
+}
+
+// WithFuncs 追加自定义模板函数，与 WithFuncMap 不同，多次调用会合并而不是替换
+func WithFuncs(funcMap template.FuncMap) GoTemplateOption {
+	return func(t *GoTemplate) {
+		merged := make(template.FuncMap, len(t.funcMap)+len(funcMap))
+		for name, fn := range t.funcMap {
+			merged[name] = fn
+		}
+		for name, fn := range funcMap {
+			merged[name] = fn
+		}
+		t.funcMap = merged
+	}
+}
+
+// WithViewsDir 从视图目录加载模板，页面按相对路径去掉扩展名命名，
+// 如 views/users/show.html 通过 ctx.View("users/show", data) 渲染
+// layouts/ 下的布局和 partials/ 下的公共片段会加入每个页面的模板集合，
+// 分别以 {{template "layouts/base" .}} 和 {{template "partials/nav" .}} 的名称引用
+func WithViewsDir(dir string) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.viewsDir = dir
+	}
+}
+
+// WithViewExt 设置视图目录中模板文件的扩展名，默认为 .html
+func WithViewExt(ext string) GoTemplateOption {
+	return func(t *GoTemplate) {
+		t.viewExt = ext
+	}
+}
+
+// LoadFromDir 从视图目录加载模板
+func (g *GoTemplate) LoadFromDir(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("views dir %s: %w", dir, err)
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if err := g.loadViews(os.DirFS(dir)); err != nil {
+		return err
+	}
+	g.viewsDir = dir
+	g.loadErr = nil
+	return nil
+}
+
+// loadViews 解析视图文件系统中的布局、公共片段和页面
+func (g *GoTemplate) loadViews(fsys fs.FS) error {
+	ext := g.viewExt
+	if ext == "" {
+		ext = defaultViewExt
+	}
+
+	shared := template.New("").Funcs(g.funcMap)
+	pageSources := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(file) != ext {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(file, ext)
+		if !isSharedView(name) {
+			pageSources[name] = string(content)
+			return nil
+		}
+		if _, err = shared.New(name).Parse(string(content)); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load views: %w", err)
+	}
+	if len(pageSources) == 0 {
+		return errors.New("no pages found in views")
+	}
+
+	pages := make(map[string]*template.Template, len(pageSources))
+	for name, content := range pageSources {
+		page, err := shared.Clone()
+		if err != nil {
+			return fmt.Errorf("failed to clone layouts: %w", err)
+		}
+		if _, err = page.New(name).Parse(content); err != nil {
+			return fmt.Errorf("failed to parse page %s: %w", name, err)
+		}
+		pages[name] = page
+	}
+
+	g.tpl = shared
+	g.pages = pages
+	return nil
+}
+
+// isSharedView 判断视图是否为布局或公共片段
+func isSharedView(name string) bool {
+	return strings.HasPrefix(name, layoutsDir) || strings.HasPrefix(name, partialsDir)
+}
+
+// viewsModifiedSince 判断视图目录中是否有文件在t之后修改过
+func viewsModifiedSince(dir string, t time.Time) bool {
+	modified := false
+	_ = fs.WalkDir(os.DirFS(dir), ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err == nil && info.ModTime().After(t) {
+			modified = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return modified
+}
@@ -0,0 +1,239 @@
+package webtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response 记录的响应，检查失败时通过 t.Errorf 报告，可以继续检查其他内容
+type Response struct {
+	t    testing.TB
+	name string
+	// Recorder 记录的原始响应
+	Recorder *httptest.ResponseRecorder
+}
+
+// Status 检查状态码
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.errorf("status = %d, want %d\nbody: %s", r.Recorder.Code, code, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Header 检查响应头的值
+func (r *Response) Header(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get(key); got != want {
+		r.errorf("header %s = %q, want %q", key, got, want)
+	}
+	return r
+}
+
+// ContentType 检查 Content-Type 是否以 want 开头，例如 application/json 可以匹配 application/json; charset=utf-8
+func (r *Response) ContentType(want string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, want) {
+		r.errorf("Content-Type = %q, want %q", got, want)
+	}
+	return r
+}
+
+// Cookie 检查响应设置的 Cookie 的值
+func (r *Response) Cookie(name, want string) *Response {
+	r.t.Helper()
+	for _, cookie := range r.Recorder.Result().Cookies() {
+		if cookie.Name == name {
+			if cookie.Value != want {
+				r.errorf("cookie %s = %q, want %q", name, cookie.Value, want)
+			}
+			return r
+		}
+	}
+	r.errorf("cookie %s is not set", name)
+	return r
+}
+
+// Body 检查响应体是否等于 want
+func (r *Response) Body(want string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Body.String(); got != want {
+		r.errorf("body = %q, want %q", got, want)
+	}
+	return r
+}
+
+// BodyContains 检查响应体是否包含 substr
+func (r *Response) BodyContains(substr string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Body.String(); !strings.Contains(got, substr) {
+		r.errorf("body %q does not contain %q", got, substr)
+	}
+	return r
+}
+
+// JSON 检查响应体与 want 编码后的 JSON 是否相等，忽略字段顺序和空白
+func (r *Response) JSON(want any) *Response {
+	r.t.Helper()
+	got, ok := r.decode()
+	if !ok {
+		return r
+	}
+	wantValue, err := normalize(want)
+	if err != nil {
+		r.errorf("encode expected value: %v", err)
+		return r
+	}
+	if !reflect.DeepEqual(got, wantValue) {
+		r.errorf("json body = %s, want %s", r.Recorder.Body.String(), mustMarshal(wantValue))
+	}
+	return r
+}
+
+// JSONPath 检查响应体中 path 处的值，want 按 JSON 编码后比较，因此整数 1 可以匹配 1.0。
+// path 支持 $ 表示根，.name 访问字段，[0] 访问数组元素，例如 $.users[0].name
+func (r *Response) JSONPath(path string, want any) *Response {
+	r.t.Helper()
+	body, ok := r.decode()
+	if !ok {
+		return r
+	}
+	got, err := lookup(body, path)
+	if err != nil {
+		r.errorf("json path %s: %v\nbody: %s", path, err, r.Recorder.Body.String())
+		return r
+	}
+	wantValue, err := normalize(want)
+	if err != nil {
+		r.errorf("encode expected value: %v", err)
+		return r
+	}
+	if !reflect.DeepEqual(got, wantValue) {
+		r.errorf("json path %s = %s, want %s", path, mustMarshal(got), mustMarshal(wantValue))
+	}
+	return r
+}
+
+// Decode 将 JSON 响应体解码到 v，用于需要自行检查的场景
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), v); err != nil {
+		r.errorf("decode json body: %v\nbody: %s", err, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// decode 将响应体解码为通用的 JSON 值
+func (r *Response) decode() (any, bool) {
+	r.t.Helper()
+	var v any
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), &v); err != nil {
+		r.errorf("decode json body: %v\nbody: %s", err, r.Recorder.Body.String())
+		return nil, false
+	}
+	return v, true
+}
+
+func (r *Response) errorf(format string, args ...any) {
+	r.t.Helper()
+	r.t.Errorf("%s: %s", r.name, fmt.Sprintf(format, args...))
+}
+
+// normalize 将 v 编码为 JSON 再解码，使其与解码后的响应体类型一致
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+func mustMarshal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// lookup 按 path 查找 JSON 值
+func lookup(v any, path string) (any, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("empty field name")
+			}
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot access field %q of %s", key, typeName(v))
+			}
+			if v, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("field %q not found", key)
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in %q", rest)
+			}
+			raw := rest[1:end]
+			rest = rest[end+1:]
+			index, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", raw)
+			}
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index %s", typeName(v))
+			}
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("index %s out of range, length is %d", raw, len(arr))
+			}
+			v = arr[index]
+		default:
+			return nil, fmt.Errorf("unexpected %q", rest)
+		}
+	}
+	return v, nil
+}
+
+// typeName 返回 JSON 值的类型名称，用于错误信息
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
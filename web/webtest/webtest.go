@@ -0,0 +1,338 @@
+// Package webtest 提供测试 HTTP 处理器的客户端，省去构造 httptest 请求和检查响应的重复代码：
+//
+//	client := webtest.New(server)
+//	client.POST("/login").WithForm(url.Values{"name": {"tom"}}).Expect(t).Status(http.StatusOK)
+//	client.GET("/users/1").WithHeader("Accept", "application/json").Expect(t).
+//		Status(http.StatusOK).
+//		JSONPath("$.id", 1)
+//
+// 请求直接交给处理器处理，不需要启动服务器。客户端会保存响应设置的 Cookie，并在之后的请求中带上，
+// 因此可以测试登录之后的会话
+package webtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Client 测试客户端，请求交给 handler 处理，通常是 *web.HTTPServer
+type Client struct {
+	handler http.Handler
+	baseURL *url.URL
+	jar     http.CookieJar
+	header  http.Header
+}
+
+// Option 客户端的配置选项
+type Option func(*Client)
+
+// WithBaseURL 设置请求的协议和主机，默认为 http://example.com，影响 Host 头部和 Cookie 的作用域
+func WithBaseURL(rawURL string) Option {
+	return func(c *Client) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			panic(fmt.Sprintf("webtest: invalid base url %q: %v", rawURL, err))
+		}
+		c.baseURL = u
+	}
+}
+
+// WithoutCookies 不保存响应设置的 Cookie
+func WithoutCookies() Option {
+	return func(c *Client) {
+		c.jar = nil
+	}
+}
+
+// WithDefaultHeader 为客户端发出的所有请求设置头部，请求中可以用 WithHeader 覆盖
+func WithDefaultHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// New 创建测试客户端
+func New(handler http.Handler, opts ...Option) *Client {
+	jar, _ := cookiejar.New(nil)
+	c := &Client{
+		handler: handler,
+		baseURL: &url.URL{Scheme: "http", Host: "example.com"},
+		jar:     jar,
+		header:  make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GET 创建 GET 请求
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// POST 创建 POST 请求
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT 创建 PUT 请求
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH 创建 PATCH 请求
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE 创建 DELETE 请求
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// HEAD 创建 HEAD 请求
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+// OPTIONS 创建 OPTIONS 请求
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// Request 创建指定方法的请求，path 可以带有查询参数
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: c.header.Clone(),
+		query:  make(url.Values),
+	}
+}
+
+// Cookie 返回客户端保存的 Cookie，不存在时返回 nil
+func (c *Client) Cookie(name string) *http.Cookie {
+	if c.jar == nil {
+		return nil
+	}
+	for _, cookie := range c.jar.Cookies(c.baseURL) {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// SetCookie 保存 Cookie，之后的请求会带上它
+func (c *Client) SetCookie(cookie *http.Cookie) {
+	if c.jar != nil {
+		c.jar.SetCookies(c.baseURL, []*http.Cookie{cookie})
+	}
+}
+
+// ClearCookies 清除客户端保存的所有 Cookie
+func (c *Client) ClearCookies() {
+	if c.jar != nil {
+		c.jar, _ = cookiejar.New(nil)
+	}
+}
+
+// Request 待发送的请求，通过 With 系列方法设置请求内容，Expect 或 Do 发送请求
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    io.Reader
+	// form 和 files 在发送时编码为请求体，设置了文件时使用 multipart/form-data
+	form  url.Values
+	files []file
+	err   error
+}
+
+// file 待上传的文件
+type file struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+// WithHeader 设置请求头
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery 添加查询参数
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie 为这个请求添加 Cookie，不会保存到客户端
+func (r *Request) WithCookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+	return r
+}
+
+// WithBody 设置请求体和 Content-Type
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = bytes.NewReader(body)
+	return r
+}
+
+// WithJSON 将 v 编码为 JSON 作为请求体
+func (r *Request) WithJSON(v any) *Request {
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.err = fmt.Errorf("encode json body: %w", err)
+		return r
+	}
+	return r.WithBody("application/json", data)
+}
+
+// WithXML 将 v 编码为 XML 作为请求体
+func (r *Request) WithXML(v any) *Request {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		r.err = fmt.Errorf("encode xml body: %w", err)
+		return r
+	}
+	return r.WithBody("application/xml", data)
+}
+
+// WithForm 添加表单字段，没有文件时请求体为 application/x-www-form-urlencoded
+func (r *Request) WithForm(values url.Values) *Request {
+	if r.form == nil {
+		r.form = make(url.Values)
+	}
+	for key, vals := range values {
+		r.form[key] = append(r.form[key], vals...)
+	}
+	return r
+}
+
+// WithFormValue 添加一个表单字段
+func (r *Request) WithFormValue(key, value string) *Request {
+	return r.WithForm(url.Values{key: {value}})
+}
+
+// WithFile 添加上传的文件，请求体为 multipart/form-data，同时包含 WithForm 添加的字段
+func (r *Request) WithFile(field, filename string, content []byte) *Request {
+	r.files = append(r.files, file{field: field, filename: filename, content: content})
+	return r
+}
+
+// Build 构造 *http.Request，可以用于需要自己处理请求的场景
+func (r *Request) Build() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	u, err := r.client.baseURL.Parse(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("parse path %q: %w", r.path, err)
+	}
+	if len(r.query) > 0 {
+		q := u.Query()
+		for key, vals := range r.query {
+			q[key] = append(q[key], vals...)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	body := r.body
+	header := r.header.Clone()
+	switch {
+	case len(r.files) > 0:
+		data, contentType, err := r.multipartBody()
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", contentType)
+	case r.form != nil:
+		body = strings.NewReader(r.form.Encode())
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	req := httptest.NewRequest(r.method, u.String(), body)
+	for key, vals := range header {
+		req.Header[key] = vals
+	}
+	if r.client.jar != nil {
+		for _, cookie := range r.client.jar.Cookies(u) {
+			req.AddCookie(cookie)
+		}
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	return req, nil
+}
+
+// multipartBody 将表单字段和文件编码为 multipart/form-data
+func (r *Request) multipartBody() ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for key, vals := range r.form {
+		for _, val := range vals {
+			if err := w.WriteField(key, val); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for _, f := range r.files {
+		part, err := w.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(f.content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// Do 发送请求，保存响应设置的 Cookie，返回记录的响应
+func (r *Request) Do() (*httptest.ResponseRecorder, error) {
+	req, err := r.Build()
+	if err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(rec, req)
+	if r.client.jar != nil {
+		if cookies := rec.Result().Cookies(); len(cookies) > 0 {
+			r.client.jar.SetCookies(req.URL, cookies)
+		}
+	}
+	return rec, nil
+}
+
+// Expect 发送请求，返回用于检查响应的 Response，请求无法构造时测试立即失败
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+	rec, err := r.Do()
+	if err != nil {
+		t.Fatalf("webtest: %s %s: %v", r.method, r.path, err)
+	}
+	return &Response{t: t, name: r.method + " " + r.path, Recorder: rec}
+}
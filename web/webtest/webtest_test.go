@@ -0,0 +1,150 @@
+package webtest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fyerfyer/fyer-webframe/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB 记录检查失败的信息，而不是让测试失败
+type recordingTB struct {
+	*testing.T
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newTestServer() *web.HTTPServer {
+	s := web.NewHTTPServer()
+	s.Post("/login", func(ctx *web.Context) {
+		ctx.SetCookie(&http.Cookie{Name: "session", Value: ctx.FormValue("name").Value, Path: "/"})
+		ctx.String(http.StatusOK, "welcome")
+	})
+	s.Get("/me", func(ctx *web.Context) {
+		cookie, err := ctx.GetCookie("session")
+		if err != nil {
+			ctx.String(http.StatusUnauthorized, "login required")
+			return
+		}
+		ctx.JSON(http.StatusOK, map[string]any{"name": cookie.Value, "lang": ctx.GetHeader("Accept-Language")})
+	})
+	s.Get("/users/:id", func(ctx *web.Context) {
+		ctx.JSON(http.StatusOK, map[string]any{
+			"id":    ctx.PathParam("id").Value,
+			"page":  ctx.QueryParam("page").Value,
+			"roles": []map[string]string{{"name": "admin"}, {"name": "editor"}},
+		})
+	})
+	s.Post("/echo", func(ctx *web.Context) {
+		body, _ := io.ReadAll(ctx.Req.Body)
+		ctx.SetHeader("X-Request-Content-Type", ctx.ContentType())
+		ctx.String(http.StatusOK, string(body))
+	})
+	s.Post("/upload", func(ctx *web.Context) {
+		fh, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		f, _ := fh.Open()
+		defer f.Close()
+		content, _ := io.ReadAll(f)
+		ctx.String(http.StatusOK, "%s %s %s", ctx.FormValue("title").Value, fh.Filename, content)
+	})
+	return s
+}
+
+func TestClient(t *testing.T) {
+	client := New(newTestServer(), WithDefaultHeader("Accept-Language", "zh-CN"))
+
+	client.GET("/me").Expect(t).Status(http.StatusUnauthorized)
+	client.POST("/login").WithFormValue("name", "tom").Expect(t).
+		Status(http.StatusOK).
+		Cookie("session", "tom").
+		Body("welcome")
+	require.NotNil(t, client.Cookie("session"))
+	assert.Equal(t, "tom", client.Cookie("session").Value)
+
+	client.GET("/me").Expect(t).
+		Status(http.StatusOK).
+		ContentType("application/json").
+		JSON(map[string]string{"name": "tom", "lang": "zh-CN"})
+	client.GET("/me").WithHeader("Accept-Language", "en").WithCookie("session", "jerry").Expect(t).
+		JSONPath("$.lang", "en")
+
+	client.ClearCookies()
+	assert.Nil(t, client.Cookie("session"))
+	client.GET("/me").Expect(t).Status(http.StatusUnauthorized)
+
+	client.GET("/users/1").WithQuery("page", "2").Expect(t).
+		Status(http.StatusOK).
+		JSONPath("$.id", "1").
+		JSONPath("$.page", "2").
+		JSONPath("$.roles[1].name", "editor").
+		JSONPath("$.roles[-1]", map[string]string{"name": "editor"})
+
+	client.POST("/echo").WithJSON(map[string]int{"id": 1}).Expect(t).
+		Header("X-Request-Content-Type", "application/json").
+		Body(`{"id":1}`)
+
+	var decoded struct {
+		ID int `json:"id"`
+	}
+	client.POST("/echo").WithBody("application/json", []byte(`{"id":7}`)).Expect(t).Decode(&decoded)
+	assert.Equal(t, 7, decoded.ID)
+
+	client.POST("/upload").
+		WithFormValue("title", "report").
+		WithFile("file", "report.txt", []byte("hello")).
+		Expect(t).
+		Status(http.StatusOK).
+		Body("report report.txt hello")
+}
+
+func TestClient_WithoutCookies(t *testing.T) {
+	client := New(newTestServer(), WithoutCookies())
+	client.POST("/login").WithForm(url.Values{"name": {"tom"}}).Expect(t).Status(http.StatusOK)
+	assert.Nil(t, client.Cookie("session"))
+	client.GET("/me").Expect(t).Status(http.StatusUnauthorized)
+}
+
+func TestResponse_Failures(t *testing.T) {
+	client := New(newTestServer())
+	tb := &recordingTB{T: t}
+
+	client.GET("/users/1").Expect(tb).
+		Status(http.StatusCreated).
+		Header("X-Missing", "yes").
+		BodyContains("nobody").
+		Cookie("session", "tom").
+		JSONPath("$.id", 1).
+		JSONPath("$.roles[5]", nil).
+		JSONPath("$.id.name", nil).
+		JSONPath("id", nil)
+	client.GET("/me").Expect(tb).JSON(map[string]string{})
+
+	want := []string{
+		"GET /users/1: status = 200, want 201",
+		`GET /users/1: header X-Missing = "", want "yes"`,
+		"does not contain \"nobody\"",
+		"GET /users/1: cookie session is not set",
+		`GET /users/1: json path $.id = "1", want 1`,
+		"index 5 out of range, length is 2",
+		`cannot access field "name" of string`,
+		"path must start with $",
+		"GET /me: decode json body",
+	}
+	require.Len(t, tb.errors, len(want))
+	for i, w := range want {
+		assert.True(t, strings.Contains(tb.errors[i], w), "error %q should contain %q", tb.errors[i], w)
+	}
+}
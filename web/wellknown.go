@@ -0,0 +1,177 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultRobotsTxt 默认的robots.txt，允许爬取全部内容
+const defaultRobotsTxt = "User-agent: *\nDisallow:\n"
+
+// SecurityTxt security.txt 的内容，字段含义见 RFC 9116
+type SecurityTxt struct {
+	Contact            []string  // 联系方式，如 mailto:security@example.com，至少一个
+	Expires            time.Time // 过期时间，为零值时默认一年后过期
+	Encryption         string    // 加密公钥的地址
+	Acknowledgments    string    // 致谢页面的地址
+	Policy             string    // 漏洞披露政策的地址
+	Hiring             string    // 安全岗位招聘页面的地址
+	Canonical          string    // security.txt 的规范地址
+	PreferredLanguages string    // 首选语言，如 "zh, en"
+}
+
+// String 按 RFC 9116 格式输出
+func (s SecurityTxt) String() string {
+	expires := s.Expires
+	if expires.IsZero() {
+		expires = time.Now().AddDate(1, 0, 0)
+	}
+
+	var sb strings.Builder
+	for _, c := range s.Contact {
+		sb.WriteString("Contact: " + c + "\n")
+	}
+	sb.WriteString("Expires: " + expires.UTC().Format(time.RFC3339) + "\n")
+	fields := []struct{ key, value string }{
+		{"Encryption", s.Encryption},
+		{"Acknowledgments", s.Acknowledgments},
+		{"Policy", s.Policy},
+		{"Hiring", s.Hiring},
+		{"Canonical", s.Canonical},
+		{"Preferred-Languages", s.PreferredLanguages},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			sb.WriteString(f.key + ": " + f.value + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// wellKnownConfig 常用固定路径的配置
+type wellKnownConfig struct {
+	faviconFile       string
+	robots            string
+	robotsFile        string
+	securityTxt       string
+	securityTxtFile   string
+	changePasswordURL string
+	files             map[string]string
+	handlers          map[string]HandlerFunc
+}
+
+// WellKnownOption 常用固定路径的配置选项
+type WellKnownOption func(*wellKnownConfig)
+
+// WithFavicon 使用文件响应 /favicon.ico，未设置时返回204以避免404日志
+func WithFavicon(file string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.faviconFile = file
+	}
+}
+
+// WithRobotsTxt 设置 /robots.txt 的内容，默认允许爬取全部内容
+func WithRobotsTxt(content string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.robots = content
+	}
+}
+
+// WithRobotsFile 使用文件响应 /robots.txt
+func WithRobotsFile(file string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.robotsFile = file
+	}
+}
+
+// WithSecurityTxt 设置 /.well-known/security.txt 的内容
+// 过期时间在注册时确定，未设置过期时间的服务需要定期重启或显式设置 Expires
+func WithSecurityTxt(txt SecurityTxt) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.securityTxt = txt.String()
+	}
+}
+
+// WithSecurityTxtFile 使用文件响应 /.well-known/security.txt
+func WithSecurityTxtFile(file string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.securityTxtFile = file
+	}
+}
+
+// WithChangePasswordURL 设置 /.well-known/change-password 重定向到的修改密码页面
+func WithChangePasswordURL(url string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.changePasswordURL = url
+	}
+}
+
+// WithWellKnownFile 使用文件响应 /.well-known/{name}
+func WithWellKnownFile(name, file string) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.files[name] = file
+	}
+}
+
+// WithWellKnownHandler 使用处理函数响应 /.well-known/{name}
+func WithWellKnownHandler(name string, handler HandlerFunc) WellKnownOption {
+	return func(c *wellKnownConfig) {
+		c.handlers[name] = handler
+	}
+}
+
+// WellKnown 注册 /favicon.ico、/robots.txt 以及按配置注册 /.well-known/ 下的路由
+// 省去每个应用重复编写的小处理函数，也避免浏览器和爬虫的请求在日志中产生404
+func (s *HTTPServer) WellKnown(opts ...WellKnownOption) {
+	config := &wellKnownConfig{
+		robots:   defaultRobotsTxt,
+		files:    make(map[string]string),
+		handlers: make(map[string]HandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	s.Get("/favicon.ico", func(ctx *Context) {
+		if config.faviconFile == "" {
+			ctx.NoContent()
+			return
+		}
+		ctx.Resp.Header().Set("Cache-Control", "public, max-age=86400")
+		ctx.File(config.faviconFile)
+	})
+
+	s.Get("/robots.txt", textOrFile(config.robots, config.robotsFile))
+
+	if config.securityTxt != "" || config.securityTxtFile != "" {
+		s.Get("/.well-known/security.txt", textOrFile(config.securityTxt, config.securityTxtFile))
+	}
+
+	if config.changePasswordURL != "" {
+		s.Get("/.well-known/change-password", func(ctx *Context) {
+			ctx.Redirect(http.StatusFound, config.changePasswordURL)
+		})
+	}
+
+	for name, file := range config.files {
+		s.Get("/.well-known/"+name, func(ctx *Context) {
+			ctx.File(file)
+		})
+	}
+	for name, handler := range config.handlers {
+		s.Get("/.well-known/"+name, handler)
+	}
+}
+
+// textOrFile 设置了文件时返回文件内容，否则返回纯文本内容
+func textOrFile(content, file string) HandlerFunc {
+	if file != "" {
+		return func(ctx *Context) {
+			ctx.File(file)
+		}
+	}
+	return func(ctx *Context) {
+		ctx.StringNoCopy(http.StatusOK, content)
+	}
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_WellKnown(t *testing.T) {
+	dir := t.TempDir()
+	favicon := filepath.Join(dir, "favicon.ico")
+	require.NoError(t, os.WriteFile(favicon, []byte("icon"), 0666))
+	assetlinks := filepath.Join(dir, "assetlinks.json")
+	require.NoError(t, os.WriteFile(assetlinks, []byte(`[]`), 0666))
+
+	testCases := []struct {
+		name         string
+		opts         []WellKnownOption
+		path         string
+		wantCode     int
+		wantBody     string
+		wantLocation string
+	}{
+		{
+			name:     "default favicon",
+			path:     "/favicon.ico",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "favicon file",
+			opts:     []WellKnownOption{WithFavicon(favicon)},
+			path:     "/favicon.ico",
+			wantCode: http.StatusOK,
+			wantBody: "icon",
+		},
+		{
+			name:     "default robots",
+			path:     "/robots.txt",
+			wantCode: http.StatusOK,
+			wantBody: "User-agent: *\nDisallow:\n",
+		},
+		{
+			name:     "custom robots",
+			opts:     []WellKnownOption{WithRobotsTxt("User-agent: *\nDisallow: /\n")},
+			path:     "/robots.txt",
+			wantCode: http.StatusOK,
+			wantBody: "User-agent: *\nDisallow: /\n",
+		},
+		{
+			name:     "security txt not configured",
+			path:     "/.well-known/security.txt",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "security txt",
+			opts: []WellKnownOption{WithSecurityTxt(SecurityTxt{
+				Contact:            []string{"mailto:security@example.com"},
+				Expires:            time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				PreferredLanguages: "zh, en",
+			})},
+			path:     "/.well-known/security.txt",
+			wantCode: http.StatusOK,
+			wantBody: "Contact: mailto:security@example.com\nExpires: 2030-01-01T00:00:00Z\nPreferred-Languages: zh, en\n",
+		},
+		{
+			name:         "change password",
+			opts:         []WellKnownOption{WithChangePasswordURL("/account/password")},
+			path:         "/.well-known/change-password",
+			wantCode:     http.StatusFound,
+			wantLocation: "/account/password",
+		},
+		{
+			name:     "custom file",
+			opts:     []WellKnownOption{WithWellKnownFile("assetlinks.json", assetlinks)},
+			path:     "/.well-known/assetlinks.json",
+			wantCode: http.StatusOK,
+			wantBody: "[]",
+		},
+		{
+			name: "custom handler",
+			opts: []WellKnownOption{WithWellKnownHandler("openid-configuration", func(ctx *Context) {
+				ctx.String(http.StatusOK, "oidc")
+			})},
+			path:     "/.well-known/openid-configuration",
+			wantCode: http.StatusOK,
+			wantBody: "oidc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewHTTPServer()
+			s.WellKnown(tc.opts...)
+
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantCode != http.StatusNotFound && tc.wantLocation == "" {
+				assert.Equal(t, tc.wantBody, resp.Body.String())
+			}
+			assert.Equal(t, tc.wantLocation, resp.Header().Get("Location"))
+		})
+	}
+}